@@ -36,6 +36,7 @@ func main() {
 		injection.ParseAndGetRESTConfigOrDie(),
 		certificates.NewController,
 		newValidationAdmissionController,
+		newConversionController,
 	)
 }
 
@@ -57,3 +58,8 @@ func newValidationAdmissionController(ctx context.Context, _ configmap.Watcher)
 		true,
 	)
 }
+
+func newConversionController(ctx context.Context, _ configmap.Watcher) *controller.Impl {
+	// The path on which to serve the conversion of the Repositories.
+	return validationWebhook.NewConversionController(ctx, "/convert")
+}
@@ -24,7 +24,8 @@ export KUBECONFIG=${HOME}/.kube/config.${KIND_CLUSTER_NAME}
 export TARGET=kubernetes
 export DOMAIN_NAME=paac-127-0-0-1.nip.io
 
-if [ -z "${TEST_GITEA_SMEEURL}" ]; then
+# the hermetic e2e tests don't need gitea and smee, they use the provider simulator
+if [ -z "${TEST_GITEA_SMEEURL:-}" ] && [ -z "${DISABLE_GITEA:-}" ]; then
 	echo "You should forward the URL via smee, create a URL in there by going to https://hook.pipelinesascode.com"
 	echo "set it up as environement variable in the 'TEST_GITEA_SMEEURL=https://hook.pipelinesascode.com/XXXXXXXX' variable"
 	exit 1
@@ -39,7 +40,7 @@ if ! builtin type -p ko &>/dev/null; then
 	exit 1
 fi
 ko=$(type -p ko)
-if ! builtin type -p gosmee &>/dev/null; then
+if [ -z "${DISABLE_GITEA:-}" ] && ! builtin type -p gosmee &>/dev/null; then
 	echo "Install gosmee. https://github.com/chmouel/gosmee?tab=readme-ov-file#install"
 	exit 1
 fi
@@ -54,6 +55,7 @@ PAC_DIR=${PAC_DIR:-""}
 DISABLE_GITEA=${DISABLE_GITEA:-""}
 GITEA_HOST=${GITEA_HOST:-"localhost:3000"}
 NO_REINSTALL_KIND=${NO_REINSTALL_KIND:-""}
+INSTALL_SIMULATOR=${INSTALL_SIMULATOR:-""}
 
 [[ $(uname -s) == "Darwin" ]] && {
 	SUDO=
@@ -210,7 +212,7 @@ function configure_pac() {
 
 	echo "Set Active Namespace to pipelines-as-code"
 	kubectl config set-context --current --namespace=pipelines-as-code >/dev/null
-	echo "Run: gosmee client --saveDir /tmp/replays ${TEST_GITEA_SMEEURL} http://controller.${DOMAIN_NAME}"
+	echo "Run: gosmee client --saveDir /tmp/replays ${TEST_GITEA_SMEEURL:-} http://controller.${DOMAIN_NAME}"
 }
 
 function install_gitea() {
@@ -218,6 +220,23 @@ function install_gitea() {
 		GITEA_PASSWORD="pac" GITEA_REPO_NAME="pac-e2e" ./gitea/deploy.py
 }
 
+function install_simulator() {
+	service_name=pipelines-as-code-controller
+	kubectl get service -n pipelines-as-code -o name |
+		sed 's/.*\///' |
+		grep -q el-pipelines-as-code-interceptor &&
+		service_name=el-pipelines-as-code-interceptor
+
+	echo "Deploying the provider simulator"
+	oldPwd=${PWD}
+	sed -e "s,%DOMAIN_NAME%,${DOMAIN_NAME}," -e "s,%SERVICE_NAME%,${service_name}," provider-simulator.yaml >${TMPD}/provider-simulator.yaml
+	cd ${PAC_DIR:-$(git rev-parse --show-toplevel)}
+	env KO_DOCKER_REPO=localhost:5000 $ko apply -f ${TMPD}/provider-simulator.yaml --sbom=none -B >/dev/null
+	cd ${oldPwd}
+	kubectl rollout status -n pac-simulator deployment/provider-simulator --timeout=180s
+	echo "provider simulator: http://simulator.${DOMAIN_NAME}"
+}
+
 main() {
 	if [[ -z ${NO_REINSTALL_KIND} ]]; then
 		start_registry
@@ -229,6 +248,7 @@ main() {
 	install_tekton
 	install_pac
 	[[ -z ${DISABLE_GITEA} ]] && install_gitea
+	[[ -n ${INSTALL_SIMULATOR} ]] && install_simulator
 	echo "And we are done :): "
 }
 
@@ -243,13 +263,15 @@ Options:
   -p          Install only PAC
   -g          Install only Gitea
   -G          Disable Gitea when installing
+  -s          Install the provider simulator of the hermetic e2e tests
+  -S          Install only the provider simulator
   -r          Install from release instead of local checkout with ko
   -R          Restart the PAC pods
   -O          Don't reinstall kind or registry and continue the whole install of tekton/dashboard/nginx/pac
 EOF
 }
 
-while getopts "ROGhgpcrb" o; do
+while getopts "ROGhgpcrbsS" o; do
 	case "${o}" in
 	h)
 		usage
@@ -288,6 +310,13 @@ while getopts "ROGhgpcrb" o; do
 	G)
 		DISABLE_GITEA=yes
 		;;
+	s)
+		INSTALL_SIMULATOR=yes
+		;;
+	S)
+		install_simulator
+		exit
+		;;
 
 	*)
 		echo "Invalid option"
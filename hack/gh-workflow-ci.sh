@@ -108,6 +108,14 @@ run_e2e_tests() {
 	make test-e2e
 }
 
+run_hermetic_e2e_tests() {
+	# no git provider account is needed, the webhooks and the API of the git
+	# provider are simulated by the provider simulator deployed in the cluster
+	export GO_TEST_FLAGS="-v -race -failfast"
+	export TEST_PROVIDER_SIMULATOR_URL="http://simulator.paac-127-0-0-1.nip.io"
+	make test-e2e-hermetic
+}
+
 collect_logs() {
 	mkdir -p /tmp/logs
 	kind export logs /tmp/logs
@@ -117,6 +125,7 @@ collect_logs() {
 	kubectl get repositories.pipelinesascode.tekton.dev -A -o yaml >/tmp/logs/pac-repositories.yaml
 	kubectl get configmap -n pipelines-as-code -o yaml >/tmp/logs/pac-configmap
 	kubectl get events -A >/tmp/logs/events
+	kubectl logs -n pac-simulator deployment/provider-simulator >/tmp/logs/provider-simulator.log 2>/dev/null || true
 }
 
 help() {
@@ -134,6 +143,9 @@ help() {
   run_e2e_tests <bitbucket_cloud_token> <webhook_secret> <test_gitea_smeeurl> <installation_id> <gh_apps_token> <test_github_second_token> <gitlab_token>
     Run the e2e tests
 
+  run_hermetic_e2e_tests
+    Run the hermetic e2e tests against the provider simulator
+
   collect_logs
     Collect logs from the cluster
 EOF
@@ -149,6 +161,9 @@ create_second_github_app_controller_on_ghe)
 run_e2e_tests)
 	run_e2e_tests "${2}" "${3}" "${4}" "${5}" "${6}" "${7}" "${8}"
 	;;
+run_hermetic_e2e_tests)
+	run_hermetic_e2e_tests
+	;;
 collect_logs)
 	collect_logs
 	;;
@@ -0,0 +1,90 @@
+package acl
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+const (
+	// MembershipCacheTTL is how long a positive membership lookup is cached.
+	MembershipCacheTTL = 5 * time.Minute
+	// MembershipNegativeCacheTTL is how long a negative membership lookup is
+	// cached, it is kept short so a user just added to a team or as a
+	// collaborator doesn't have to wait long to be allowed.
+	MembershipNegativeCacheTTL = 30 * time.Second
+)
+
+type membershipEntry struct {
+	member  bool
+	expires time.Time
+}
+
+// MembershipCache caches the membership lookups (org member, collaborator,
+// team member) done against the provider API for a short time, negative
+// answers included, so the comments on a pull request don't redo the same
+// lookups.
+type MembershipCache struct {
+	mu          sync.Mutex
+	clock       clockwork.Clock
+	ttl         time.Duration
+	negativeTTL time.Duration
+	entries     map[string]membershipEntry
+}
+
+func NewMembershipCache(clock clockwork.Clock, ttl, negativeTTL time.Duration) *MembershipCache {
+	return &MembershipCache{
+		clock:       clock,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		entries:     map[string]membershipEntry{},
+	}
+}
+
+// MembershipKey builds a cache key from its parts, ie: the api url, the kind
+// of lookup, the org and the user.
+func MembershipKey(parts ...string) string {
+	return strings.Join(parts, "|")
+}
+
+// Get returns the cached membership and true if the key has a cached entry
+// which has not expired. A nil cache never has any entry.
+func (c *MembershipCache) Get(key string) (bool, bool) {
+	if c == nil {
+		return false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return false, false
+	}
+	if !c.clock.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		return false, false
+	}
+	return entry.member, true
+}
+
+// Set caches the membership of the key, it does nothing on a nil cache.
+func (c *MembershipCache) Set(key string, member bool) {
+	if c == nil {
+		return
+	}
+	ttl := c.ttl
+	if !member {
+		ttl = c.negativeTTL
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	// drop the expired entries so the cache doesn't grow forever
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = membershipEntry{member: member, expires: now.Add(ttl)}
+}
@@ -0,0 +1,48 @@
+package acl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"gotest.tools/v3/assert"
+)
+
+func TestMembershipCache(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	cache := NewMembershipCache(clock, time.Minute, 10*time.Second)
+
+	member := MembershipKey("https://api.github.com", "org-member", "org", "member")
+	notMember := MembershipKey("https://api.github.com", "org-member", "org", "stranger")
+
+	_, ok := cache.Get(member)
+	assert.Assert(t, !ok)
+
+	cache.Set(member, true)
+	cache.Set(notMember, false)
+
+	got, ok := cache.Get(member)
+	assert.Assert(t, ok)
+	assert.Assert(t, got)
+	got, ok = cache.Get(notMember)
+	assert.Assert(t, ok)
+	assert.Assert(t, !got)
+
+	// negative entries expire first
+	clock.Advance(10 * time.Second)
+	_, ok = cache.Get(notMember)
+	assert.Assert(t, !ok)
+	_, ok = cache.Get(member)
+	assert.Assert(t, ok)
+
+	clock.Advance(50 * time.Second)
+	_, ok = cache.Get(member)
+	assert.Assert(t, !ok)
+}
+
+func TestMembershipCacheNil(t *testing.T) {
+	var cache *MembershipCache
+	cache.Set("key", true)
+	_, ok := cache.Get("key")
+	assert.Assert(t, !ok)
+}
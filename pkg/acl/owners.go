@@ -1,6 +1,8 @@
 package acl
 
 import (
+	"sort"
+
 	"sigs.k8s.io/yaml"
 )
 
@@ -80,3 +82,23 @@ func expandAliases(owners []string, aliases aliases) []string {
 	}
 	return expanded
 }
+
+// SenderTeams parses the OWNERS_ALIASES file and returns the sorted aliases,
+// the teams, the sender belongs to.
+func SenderTeams(ownersAliasesContent, sender string) ([]string, error) {
+	ac := aliasesConfig{}
+	if err := yaml.Unmarshal([]byte(ownersAliasesContent), &ac); err != nil {
+		return nil, err
+	}
+	teams := []string{}
+	for alias, members := range ac.Aliases {
+		for _, member := range members {
+			if member == sender {
+				teams = append(teams, alias)
+				break
+			}
+		}
+	}
+	sort.Strings(teams)
+	return teams, nil
+}
@@ -206,3 +206,24 @@ func TestExpandAliases(t *testing.T) {
 		})
 	}
 }
+
+func TestSenderTeams(t *testing.T) {
+	aliases := "aliases:\n  backend:\n    - alice\n    - bob\n  admins:\n    - alice\n  frontend:\n    - carol\n"
+	teams, err := SenderTeams(aliases, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(teams, []string{"admins", "backend"}) {
+		t.Errorf("SenderTeams() = %v, want [admins backend]", teams)
+	}
+	teams, err = SenderTeams(aliases, "dave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(teams) != 0 {
+		t.Errorf("SenderTeams() = %v, want none", teams)
+	}
+	if _, err := SenderTeams("aliases: [", "alice"); err == nil {
+		t.Error("SenderTeams() with invalid yaml should fail")
+	}
+}
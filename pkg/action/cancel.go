@@ -0,0 +1,55 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"go.uber.org/zap"
+)
+
+// CancelMergePatch cancels a PipelineRun, its finally tasks still run.
+var CancelMergePatch = map[string]interface{}{
+	"spec": map[string]interface{}{
+		"status": tektonv1.PipelineRunSpecStatusCancelledRunFinally,
+	},
+}
+
+// CancelPipelineRuns cancels the PipelineRuns in parallel, the ones done or
+// already cancelled are skipped. It returns the PipelineRuns cancelled and the
+// errors of the ones which could not be.
+func CancelPipelineRuns(ctx context.Context, logger *zap.SugaredLogger, tekton versioned.Interface, prs []tektonv1.PipelineRun) ([]*tektonv1.PipelineRun, []error) {
+	var (
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		cancelled []*tektonv1.PipelineRun
+		errs      []error
+	)
+	for _, pr := range prs {
+		if pr.IsDone() {
+			logger.Infof("pipelinerun %v/%v is done, skipping cancellation", pr.GetNamespace(), pr.GetName())
+			continue
+		}
+		if pr.IsCancelled() || pr.IsGracefullyCancelled() || pr.IsGracefullyStopped() {
+			logger.Infof("pipelinerun %v/%v is already in %v state", pr.GetNamespace(), pr.GetName(), pr.Spec.Status)
+			continue
+		}
+
+		wg.Add(1)
+		go func(ctx context.Context, pr tektonv1.PipelineRun) {
+			defer wg.Done()
+			patched, err := PatchPipelineRun(ctx, logger, "cancel patch", tekton, &pr, CancelMergePatch)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to cancel pipelineRun %s/%s: %s", pr.GetNamespace(), pr.GetName(), err.Error()))
+				return
+			}
+			cancelled = append(cancelled, patched)
+		}(ctx, pr)
+	}
+	wg.Wait()
+	return cancelled, errs
+}
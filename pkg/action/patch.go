@@ -3,32 +3,65 @@ package action
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/retry"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
+// PatchBackoff is the backoff between the attempts of a patch conflicting with
+// another update of the PipelineRun, the jitter spreads the attempts of the
+// controller and the watcher patching the same PipelineRun at the same time.
+// see https://issues.redhat.com/browse/SRVKP-3134
+var PatchBackoff = wait.Backoff{
+	Steps:    10,
+	Duration: 20 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.5,
+	Cap:      5 * time.Second,
+}
+
+// RetryOnConflict runs fn until it does not return a conflict error, with
+// at most the steps of the backoff. Each conflict is counted in the patch
+// conflict metric.
+func RetryOnConflict(ctx context.Context, backoff wait.Backoff, whatPatching string, fn func() error) error {
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func() (bool, error) {
+		lastErr = fn()
+		switch {
+		case lastErr == nil:
+			return true, nil
+		case k8serrors.IsConflict(lastErr):
+			metrics.CountPatchConflict(whatPatching)
+			return false, nil
+		default:
+			return false, lastErr
+		}
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return lastErr
+	}
+	return err
+}
+
 func PatchPipelineRun(ctx context.Context, logger *zap.SugaredLogger, whatPatching string, tekton versioned.Interface, pr *tektonv1.PipelineRun, mergePatch map[string]interface{}) (*tektonv1.PipelineRun, error) {
 	if pr == nil {
 		return nil, nil
 	}
+	patch, err := json.Marshal(mergePatch)
+	if err != nil {
+		return pr, fmt.Errorf("failed to patch pipelinerun %v/%v with %v: %w", pr.Namespace, pr.Name, whatPatching, err)
+	}
 	var patchedPR *tektonv1.PipelineRun
-	// double the retry; see https://issues.redhat.com/browse/SRVKP-3134
-	doubleRetry := retry.DefaultRetry
-	doubleRetry.Steps *= 2
-	doubleRetry.Duration *= 2
-	doubleRetry.Factor *= 2
-	doubleRetry.Jitter *= 2
-	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		patch, err := json.Marshal(mergePatch)
-		if err != nil {
-			return err
-		}
+	err = RetryOnConflict(ctx, PatchBackoff, whatPatching, func() error {
 		patchedPR, err = tekton.TektonV1().PipelineRuns(pr.GetNamespace()).Patch(ctx, pr.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
 		if err != nil {
 			logger.Infof("could not patch Pipelinerun with %v, retrying %v/%v: %v", whatPatching, pr.GetNamespace(), pr.GetName(), err)
@@ -39,7 +72,7 @@ func PatchPipelineRun(ctx context.Context, logger *zap.SugaredLogger, whatPatchi
 	})
 	if err != nil {
 		// return the original PipelineRun, let the caller decide what to do with it after the error is processed
-		return pr, fmt.Errorf("failed to patch pipelinerun %v/%v with %v: %w", pr.Namespace, whatPatching, pr.Name, err)
+		return pr, fmt.Errorf("failed to patch pipelinerun %v/%v with %v: %w", pr.Namespace, pr.Name, whatPatching, err)
 	}
 	return patchedPR, nil
 }
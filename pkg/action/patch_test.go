@@ -1,8 +1,10 @@
 package action
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
+	"time"
 
 	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
@@ -14,6 +16,9 @@ import (
 	"go.uber.org/zap"
 	zapobserver "go.uber.org/zap/zaptest/observer"
 	"gotest.tools/v3/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktesting "k8s.io/client-go/testing"
 	rtesting "knative.dev/pkg/reconciler/testing"
 )
 
@@ -43,6 +48,59 @@ func TestPatchPipelineRun(t *testing.T) {
 	assert.Equal(t, patchedPR.Annotations[filepath.Join(apipac.GroupName, "log-url")], "https://localhost.console/#/namespaces/namespace/pipelineruns/force-me")
 }
 
+func TestPatchPipelineRunConflicts(t *testing.T) {
+	backoff := PatchBackoff
+	defer func() { PatchBackoff = backoff }()
+	PatchBackoff.Duration = time.Millisecond
+	tests := []struct {
+		name      string
+		conflicts int
+		wantErr   string
+	}{
+		{
+			name:      "patched after some conflicts",
+			conflicts: 3,
+		},
+		{
+			name:      "conflicts for all the attempts",
+			conflicts: PatchBackoff.Steps,
+			wantErr:   "the object has been modified",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			testPR := tektontest.MakePRStatus("namespace", "force-me", nil, nil)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: []*pipelinev1.PipelineRun{testPR}})
+
+			attempts := 0
+			stdata.Pipeline.PrependReactor("patch", "pipelineruns", func(_ ktesting.Action) (bool, runtime.Object, error) {
+				attempts++
+				if attempts <= tt.conflicts {
+					return true, nil, k8serrors.NewConflict(pipelinev1.Resource("pipelineruns"), testPR.GetName(), fmt.Errorf("the object has been modified"))
+				}
+				return false, nil, nil
+			})
+
+			patchedPR, err := PatchPipelineRun(ctx, logger, "state", stdata.Pipeline, testPR, map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{keys.State: "completed"},
+				},
+			})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Equal(t, attempts, PatchBackoff.Steps)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, attempts, tt.conflicts+1)
+			assert.Equal(t, patchedPR.GetAnnotations()[keys.State], "completed")
+		})
+	}
+}
+
 func getLogURLMergePatch(clients clients.Clients, pr *pipelinev1.PipelineRun) map[string]interface{} {
 	return map[string]interface{}{
 		"metadata": map[string]interface{}{
@@ -13,9 +13,11 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/version"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketserver"
@@ -39,10 +41,12 @@ func NewEnvConfig() adapter.EnvConfigAccessor {
 }
 
 type listener struct {
-	run    *params.Run
-	kint   kubeinteraction.Interface
-	logger *zap.SugaredLogger
-	event  *info.Event
+	run     *params.Run
+	kint    kubeinteraction.Interface
+	logger  *zap.SugaredLogger
+	event   *info.Event
+	buffer  *eventBuffer
+	latency *latencyTracker
 }
 
 type Response struct {
@@ -55,9 +59,11 @@ var _ adapter.Adapter = (*listener)(nil)
 func New(run *params.Run, k *kubeinteraction.Interaction) adapter.AdapterConstructor {
 	return func(ctx context.Context, _ adapter.EnvConfigAccessor, _ cloudevents.Client) adapter.Adapter {
 		return &listener{
-			logger: logging.FromContext(ctx),
-			run:    run,
-			kint:   k,
+			logger:  logging.FromContext(ctx),
+			run:     run,
+			kint:    k,
+			buffer:  &eventBuffer{pending: true},
+			latency: &latencyTracker{},
 		}
 	}
 }
@@ -72,6 +78,17 @@ func (l *listener) Start(ctx context.Context) error {
 	// Start pac config syncer
 	go params.StartConfigSync(ctx, l.run)
 
+	// replay the events buffered during the maintenance once it is over
+	go l.watchMaintenance(ctx)
+
+	// delete the expired event deduplication leases
+	go l.cleanupDedupLeases(ctx)
+
+	// exported with the metrics of the controller on its own metrics port
+	if err := metrics.RegisterEventViews(); err != nil {
+		l.logger.Errorf("cannot register the metrics of the events: %v", err)
+	}
+
 	l.logger.Infof("Starting Pipelines as Code version: %s", strings.TrimSpace(version.Version))
 	mux := http.NewServeMux()
 
@@ -109,6 +126,7 @@ func (l listener) handleEvent(ctx context.Context) http.HandlerFunc {
 			l.writeResponse(response, http.StatusOK, "ok")
 			return
 		}
+		receivedAt := time.Now()
 
 		// event body
 		payload, err := io.ReadAll(request.Body)
@@ -127,78 +145,112 @@ func (l listener) handleEvent(ctx context.Context) http.HandlerFunc {
 			}
 		}
 
-		var gitProvider provider.Interface
-		var logger *zap.SugaredLogger
-
-		l.event = info.NewEvent()
-		pacInfo := l.run.Info.GetPacOpts()
-
-		globalRepo, err := l.run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(l.run.Info.Kube.Namespace).Get(
-			ctx, l.run.Info.Controller.GlobalRepository, metav1.GetOptions{},
-		)
-		if err == nil && globalRepo != nil {
-			l.logger.Infof("detected global repository settings named %s in namespace %s", l.run.Info.Controller.GlobalRepository, l.run.Info.Kube.Namespace)
-		} else {
-			globalRepo = &v1alpha1.Repository{}
-		}
-
-		detected, configuring, err := github.ConfigureRepository(ctx, l.run, request, string(payload), &pacInfo, l.logger)
-		if detected {
-			if configuring && err == nil {
-				l.writeResponse(response, http.StatusCreated, "configured")
+		if l.buffering() {
+			if err := l.validateEvent(ctx, request, payload); err != nil {
+				l.logger.Errorf("controller in maintenance, refusing the event: %v", err)
+				l.writeResponse(response, http.StatusOK, err.Error())
 				return
 			}
-			if configuring && err != nil {
-				l.logger.Errorf("repository auto-configure has failed, err: %v", err)
-				l.writeResponse(response, http.StatusOK, "failed to configure")
-				return
-			}
-			l.writeResponse(response, http.StatusOK, "skipped event")
-			return
 		}
-
-		isIncoming, targettedRepo, err := l.detectIncoming(ctx, request, payload)
+		buffered, err := l.bufferEvent(ctx, request, payload)
 		if err != nil {
-			l.logger.Errorf("error processing incoming webhook: %v", err)
+			l.logger.Errorf("controller in maintenance, cannot buffer the event: %v", err)
+			l.writeResponse(response, http.StatusServiceUnavailable, "in maintenance")
 			return
 		}
-
-		if isIncoming {
-			gitProvider, logger, err = l.processIncoming(targettedRepo)
-		} else {
-			gitProvider, logger, err = l.detectProvider(request, string(payload))
+		if buffered {
+			l.writeResponse(response, http.StatusAccepted, "buffered")
+			return
 		}
 
-		// figure out which provider request coming from
-		if err != nil || gitProvider == nil {
-			l.writeResponse(response, http.StatusOK, err.Error())
+		l.processRequest(ctx, response, request, payload, receivedAt)
+	}
+}
+
+// processRequest processes the event received, or replayed after a
+// maintenance, from the payload read from the request. receivedAt is when the
+// controller has received it, to record its latency.
+func (l listener) processRequest(ctx context.Context, response http.ResponseWriter, request *http.Request, payload []byte, receivedAt time.Time) {
+	var gitProvider provider.Interface
+	var logger *zap.SugaredLogger
+
+	l.event = info.NewEvent()
+	pacInfo := l.run.Info.GetPacOpts()
+
+	globalRepo := l.globalRepository(ctx)
+
+	detected, configuring, err := github.ConfigureRepository(ctx, l.run, request, string(payload), &pacInfo, l.logger)
+	if detected {
+		if configuring && err == nil {
+			l.writeResponse(response, http.StatusCreated, "configured")
 			return
 		}
-		gitProvider.SetPacInfo(&pacInfo)
-
-		s := sinker{
-			run:        l.run,
-			vcx:        gitProvider,
-			kint:       l.kint,
-			event:      l.event,
-			logger:     logger,
-			payload:    payload,
-			pacInfo:    &pacInfo,
-			globalRepo: globalRepo,
+		if configuring && err != nil {
+			l.logger.Errorf("repository auto-configure has failed, err: %v", err)
+			l.writeResponse(response, http.StatusOK, "failed to configure")
+			return
 		}
+		l.writeResponse(response, http.StatusOK, "skipped event")
+		return
+	}
 
-		// clone the request to use it further
-		localRequest := request.Clone(request.Context())
+	isIncoming, targettedRepo, err := l.detectIncoming(ctx, request, payload)
+	if err != nil {
+		l.logger.Errorf("error processing incoming webhook: %v", err)
+		return
+	}
 
-		go func() {
-			err := s.processEvent(ctx, localRequest)
-			if err != nil {
-				logger.Errorf("an error occurred: %v", err)
-			}
-		}()
+	if isIncoming {
+		gitProvider, logger, err = l.processIncoming(targettedRepo)
+	} else {
+		gitProvider, logger, err = l.detectProvider(request, string(payload), &pacInfo)
+	}
+
+	// figure out which provider request coming from
+	if err != nil || gitProvider == nil {
+		l.writeResponse(response, http.StatusOK, err.Error())
+		return
+	}
+	gitProvider.SetPacInfo(&pacInfo)
+
+	s := sinker{
+		run:        l.run,
+		vcx:        gitProvider,
+		kint:       l.kint,
+		event:      l.event,
+		logger:     logger,
+		payload:    payload,
+		pacInfo:    &pacInfo,
+		globalRepo: globalRepo,
+	}
+
+	// clone the request to use it further
+	localRequest := request.Clone(request.Context())
 
-		l.writeResponse(response, http.StatusAccepted, "accepted")
+	timing := newEventTiming(request, payload, receivedAt)
+	go func() {
+		timing.startedAt = time.Now()
+		err := s.processEvent(provider.WithAPITime(ctx, &timing.apiTime), localRequest)
+		if err != nil {
+			logger.Errorf("an error occurred: %v", err)
+		}
+		l.recordLatency(timing, gitProvider.GetConfig().Name, logger)
+	}()
+
+	l.writeResponse(response, http.StatusAccepted, "accepted")
+}
+
+// globalRepository returns the global repository settings, an empty
+// Repository when there is none.
+func (l listener) globalRepository(ctx context.Context) *v1alpha1.Repository {
+	globalRepo, err := l.run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(l.run.Info.Kube.Namespace).Get(
+		ctx, l.run.Info.Controller.GlobalRepository, metav1.GetOptions{},
+	)
+	if err != nil || globalRepo == nil {
+		return &v1alpha1.Repository{}
 	}
+	l.logger.Infof("detected global repository settings named %s in namespace %s", l.run.Info.Controller.GlobalRepository, l.run.Info.Kube.Namespace)
+	return globalRepo
 }
 
 func (l listener) processRes(processEvent bool, provider provider.Interface, logger *zap.SugaredLogger, skipReason string, err error) (provider.Interface, *zap.SugaredLogger, error) {
@@ -218,7 +270,7 @@ func (l listener) processRes(processEvent bool, provider provider.Interface, log
 	return nil, logger, fmt.Errorf("skipping non supported event")
 }
 
-func (l listener) detectProvider(req *http.Request, reqBody string) (provider.Interface, *zap.SugaredLogger, error) {
+func (l listener) detectProvider(req *http.Request, reqBody string, pacInfo *info.PacOpts) (provider.Interface, *zap.SugaredLogger, error) {
 	log := *l.logger
 
 	// payload validation
@@ -229,6 +281,7 @@ func (l listener) detectProvider(req *http.Request, reqBody string) (provider.In
 
 	gitHub := github.New()
 	gitHub.Run = l.run
+	gitHub.SetPacInfo(pacInfo)
 	isGH, processReq, logger, reason, err := gitHub.Detect(req, reqBody, &log)
 	if isGH {
 		return l.processRes(processReq, gitHub, logger, reason, err)
@@ -247,6 +300,7 @@ func (l listener) detectProvider(req *http.Request, reqBody string) (provider.In
 	}
 
 	gitLab := &gitlab.Provider{}
+	gitLab.SetPacInfo(pacInfo)
 	isGitlab, processReq, logger, reason, err := gitLab.Detect(req, reqBody, &log)
 	if isGitlab {
 		return l.processRes(processReq, gitLab, logger, reason, err)
@@ -273,3 +327,18 @@ func (l listener) writeResponse(response http.ResponseWriter, statusCode int, me
 		l.logger.Errorf("failed to write back sink response: %v", err)
 	}
 }
+
+// cleanupDedupLeases periodically deletes the expired event deduplication
+// leases.
+func (l listener) cleanupDedupLeases(ctx context.Context) {
+	ticker := time.NewTicker(pipelineascode.DedupCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pipelineascode.CleanupDedupLeases(ctx, l.run, l.logger)
+	}
+}
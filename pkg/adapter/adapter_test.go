@@ -111,6 +111,7 @@ func TestHandleEvent(t *testing.T) {
 			},
 		},
 		logger: logger,
+		buffer: &eventBuffer{},
 	}
 	l.run.Clients.InitClients()
 	l.run.Info.InitInfo()
@@ -252,7 +253,7 @@ func TestWhichProvider(t *testing.T) {
 				Header: tt.header,
 			}
 
-			_, _, err = l.detectProvider(req, string(jeez))
+			_, _, err = l.detectProvider(req, string(jeez), &info.PacOpts{})
 			if tt.wantErrString != "" {
 				assert.ErrorContains(t, err, tt.wantErrString)
 				return
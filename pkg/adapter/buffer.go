@@ -0,0 +1,345 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/random"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	bufferedEventPrefix = "pac-buffered-event-"
+
+	bufferedEventURLKey     = "url"
+	bufferedEventHeadersKey = "headers.json"
+	bufferedEventPayloadKey = "payload"
+
+	// drainInterval is how often the maintenance-mode setting is checked
+	// to drain the buffered events once the maintenance is over.
+	drainInterval = 10 * time.Second
+
+	// maxBufferedEventSize is the maximum size of an event to buffer, under
+	// the 1MiB limit of a Secret.
+	maxBufferedEventSize = 900 * 1024
+
+	// bufferedEventClaimTimeout is after how long the claim of a buffered
+	// event by a replica is stale, the replica having stopped before
+	// replaying it.
+	bufferedEventClaimTimeout = 2 * time.Minute
+
+	// maxBufferedEventReplays is how many times the replay of a buffered
+	// event is attempted before dropping it.
+	maxBufferedEventReplays = 3
+)
+
+// eventBuffer holds the events received during the maintenance of the
+// controller. They are stored as Secrets in the controller namespace, since
+// the headers and the URL of the events carry their signature or their
+// secret, and replayed in the order they were received once the maintenance
+// is over. The new events are buffered too while draining, to keep the order.
+type eventBuffer struct {
+	mu       sync.Mutex
+	draining bool
+	// pending is set when there may be events to drain: at startup, since
+	// the controller may have been restarted during a maintenance, and
+	// after a maintenance.
+	pending bool
+	// buffered counts the events buffered by the replica, for the draining
+	// to know if events have been buffered while it was listing them.
+	buffered int
+}
+
+// buffering returns true when the events are buffered, during the
+// maintenance or while draining the buffered events.
+func (l listener) buffering() bool {
+	l.buffer.mu.Lock()
+	defer l.buffer.mu.Unlock()
+	return l.run.Info.GetPacOpts().MaintenanceMode || l.buffer.draining
+}
+
+// validateEvent checks that the event comes from a Repository and is signed
+// with its webhook secret before buffering it, as it would be checked when
+// processed, so only the events of the Git providers are stored.
+func (l listener) validateEvent(ctx context.Context, request *http.Request, payload []byte) error {
+	pacInfo := l.run.Info.GetPacOpts()
+	// the secret of the incoming webhooks is checked on detection
+	isIncoming, _, err := l.detectIncoming(ctx, request, payload)
+	if err != nil || isIncoming {
+		return err
+	}
+	gitProvider, logger, err := l.detectProvider(request, string(payload), &pacInfo)
+	if err != nil {
+		return err
+	}
+	gitProvider.SetPacInfo(&pacInfo)
+
+	globalRepo := l.globalRepository(ctx)
+	s := sinker{
+		run:        l.run,
+		vcx:        gitProvider,
+		kint:       l.kint,
+		event:      info.NewEvent(),
+		logger:     logger,
+		payload:    payload,
+		pacInfo:    &pacInfo,
+		globalRepo: globalRepo,
+	}
+	if err := s.processEventPayload(ctx, request); err != nil {
+		return err
+	}
+	p := pipelineascode.NewPacs(s.event, s.vcx, s.run, s.pacInfo, s.kint, s.logger, globalRepo)
+	return p.ValidateEvent(ctx)
+}
+
+// bufferEvent stores the event when the controller is in maintenance or
+// draining the buffered events, it returns false when the event has to be
+// processed right away. The event is refused when it is too large or when the
+// buffer is full, for the Git provider to redeliver it later.
+func (l listener) bufferEvent(ctx context.Context, request *http.Request, payload []byte) (bool, error) {
+	if !l.buffering() {
+		return false, nil
+	}
+
+	headers, err := json.Marshal(request.Header)
+	if err != nil {
+		return true, err
+	}
+	uri := request.URL.RequestURI()
+	if size := len(uri) + len(headers) + len(payload); size > maxBufferedEventSize {
+		return true, fmt.Errorf("the event of %d bytes is larger than the %d bytes that can be buffered", size, maxBufferedEventSize)
+	}
+	secrets := l.run.Clients.Kube.CoreV1().Secrets(l.run.Info.Kube.Namespace)
+	maxEvents := l.run.Info.GetPacOpts().MaintenanceMaxBufferedEvents
+	list, err := secrets.List(ctx, metav1.ListOptions{LabelSelector: keys.BufferedEvent + "=true", Limit: int64(maxEvents)})
+	if err != nil {
+		return true, err
+	}
+	if len(list.Items) >= maxEvents {
+		return true, fmt.Errorf("the %d events of the maintenance-max-buffered-events setting are already buffered", maxEvents)
+	}
+
+	l.buffer.mu.Lock()
+	defer l.buffer.mu.Unlock()
+	if !l.run.Info.GetPacOpts().MaintenanceMode && !l.buffer.draining {
+		return false, nil
+	}
+	l.buffer.pending = true
+
+	// the name orders the events by their reception time
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s%019d-%s", bufferedEventPrefix, time.Now().UnixNano(), strings.ToLower(random.AlphaString(4))),
+			Namespace: l.run.Info.Kube.Namespace,
+			Labels: map[string]string{
+				keys.BufferedEvent:          "true",
+				"app.kubernetes.io/part-of": "pipelines-as-code",
+			},
+		},
+		Data: map[string][]byte{
+			bufferedEventURLKey:     []byte(uri),
+			bufferedEventHeadersKey: headers,
+			bufferedEventPayloadKey: payload,
+		},
+	}
+	if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return true, err
+	}
+	l.buffer.buffered++
+	l.logger.Infof("controller in maintenance, buffered the event as %s", secret.GetName())
+	return true, nil
+}
+
+// watchMaintenance drains the buffered events when the controller is not in
+// maintenance anymore.
+func (l listener) watchMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		l.buffer.mu.Lock()
+		if l.run.Info.GetPacOpts().MaintenanceMode {
+			l.buffer.pending = true
+			l.buffer.mu.Unlock()
+			continue
+		}
+		pending := l.buffer.pending
+		l.buffer.mu.Unlock()
+		if pending {
+			l.drainEvents(ctx)
+		}
+	}
+}
+
+// drainEvents replays the buffered events in order until there are none left.
+// The events are claimed before being replayed, so each of them is replayed by
+// one replica only, and their Secret is deleted once replayed. The draining
+// stops on a failed replay, to be retried on the next check.
+func (l listener) drainEvents(ctx context.Context) {
+	secrets := l.run.Clients.Kube.CoreV1().Secrets(l.run.Info.Kube.Namespace)
+	for {
+		l.buffer.mu.Lock()
+		if l.run.Info.GetPacOpts().MaintenanceMode {
+			l.buffer.draining = false
+			l.buffer.mu.Unlock()
+			return
+		}
+		// the events received from now on are buffered after the listed ones
+		l.buffer.draining = true
+		buffered := l.buffer.buffered
+		l.buffer.mu.Unlock()
+
+		list, err := secrets.List(ctx, metav1.ListOptions{LabelSelector: keys.BufferedEvent + "=true"})
+		if err != nil {
+			l.logger.Errorf("cannot list the buffered events: %v", err)
+			return
+		}
+		if len(list.Items) == 0 {
+			l.buffer.mu.Lock()
+			// no event has been buffered while listing, the new ones can be
+			// processed right away
+			if l.buffer.buffered == buffered {
+				l.buffer.draining = false
+				l.buffer.pending = false
+				l.buffer.mu.Unlock()
+				return
+			}
+			l.buffer.mu.Unlock()
+			continue
+		}
+
+		sort.Slice(list.Items, func(i, j int) bool {
+			return list.Items[i].GetName() < list.Items[j].GetName()
+		})
+		l.logger.Infof("replaying %d events buffered during the maintenance", len(list.Items))
+		replayed := 0
+		for i := range list.Items {
+			secret := &list.Items[i]
+			claimed, err := l.claimEvent(ctx, secret)
+			if err != nil {
+				l.logger.Errorf("cannot claim the buffered event %s: %v", secret.GetName(), err)
+				return
+			}
+			if !claimed {
+				// replayed by another replica
+				continue
+			}
+			if err := l.replayEvent(ctx, secret); err != nil {
+				l.logger.Errorf("cannot replay the buffered event %s: %v", secret.GetName(), err)
+				l.releaseEvent(ctx, secret)
+				return
+			}
+			uid := secret.GetUID()
+			if err := secrets.Delete(ctx, secret.GetName(), metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}}); err != nil && !errors.IsNotFound(err) {
+				l.logger.Errorf("cannot delete the replayed buffered event %s: %v", secret.GetName(), err)
+			}
+			replayed++
+		}
+		// the events left are replayed by other replicas, they are listed
+		// again on the next check
+		if replayed == 0 {
+			return
+		}
+	}
+}
+
+// claimEvent marks the buffered event as being replayed by the replica, the
+// update conflicts when another replica has claimed it first. A claim older
+// than bufferedEventClaimTimeout is stale and can be taken over.
+func (l listener) claimEvent(ctx context.Context, secret *corev1.Secret) (bool, error) {
+	if claimedAt, err := time.Parse(time.RFC3339, secret.GetAnnotations()[keys.BufferedEventClaimed]); err == nil &&
+		time.Since(claimedAt) < bufferedEventClaimTimeout {
+		return false, nil
+	}
+	claimed := secret.DeepCopy()
+	if claimed.Annotations == nil {
+		claimed.Annotations = map[string]string{}
+	}
+	claimed.Annotations[keys.BufferedEventClaimed] = time.Now().UTC().Format(time.RFC3339)
+	updated, err := l.run.Clients.Kube.CoreV1().Secrets(secret.GetNamespace()).Update(ctx, claimed, metav1.UpdateOptions{})
+	if errors.IsConflict(err) || errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	*secret = *updated
+	return true, nil
+}
+
+// releaseEvent releases the claim of a buffered event whose replay has
+// failed, for it to be replayed again, and drops it after
+// maxBufferedEventReplays attempts.
+func (l listener) releaseEvent(ctx context.Context, secret *corev1.Secret) {
+	secrets := l.run.Clients.Kube.CoreV1().Secrets(secret.GetNamespace())
+	attempts, _ := strconv.Atoi(secret.GetAnnotations()[keys.BufferedEventReplays])
+	attempts++
+	if attempts >= maxBufferedEventReplays {
+		l.logger.Errorf("dropping the buffered event %s after %d failed replays", secret.GetName(), attempts)
+		if err := secrets.Delete(ctx, secret.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			l.logger.Errorf("cannot delete the buffered event %s: %v", secret.GetName(), err)
+		}
+		return
+	}
+	released := secret.DeepCopy()
+	delete(released.Annotations, keys.BufferedEventClaimed)
+	released.Annotations[keys.BufferedEventReplays] = strconv.Itoa(attempts)
+	if _, err := secrets.Update(ctx, released, metav1.UpdateOptions{}); err != nil {
+		l.logger.Errorf("cannot release the buffered event %s: %v", secret.GetName(), err)
+	}
+}
+
+// replayEvent processes a buffered event as if it had just been received, it
+// fails when the event cannot be rebuilt or when the controller is stopping.
+func (l listener) replayEvent(ctx context.Context, secret *corev1.Secret) error {
+	header := http.Header{}
+	if err := json.Unmarshal(secret.Data[bufferedEventHeadersKey], &header); err != nil {
+		return fmt.Errorf("invalid headers: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, string(secret.Data[bufferedEventURLKey]),
+		bytes.NewReader(secret.Data[bufferedEventPayloadKey]))
+	if err != nil {
+		return err
+	}
+	request.Header = header
+
+	recorder := httptest.NewRecorder()
+	l.processRequest(ctx, recorder, request, secret.Data[bufferedEventPayloadKey], bufferedEventReceivedAt(secret))
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if recorder.Code >= http.StatusInternalServerError {
+		return fmt.Errorf("%d %s", recorder.Code, strings.TrimSpace(recorder.Body.String()))
+	}
+	l.logger.Infof("replayed the buffered event %s: %d %s", secret.GetName(), recorder.Code, strings.TrimSpace(recorder.Body.String()))
+	return nil
+}
+
+// bufferedEventReceivedAt returns when a buffered event has been received,
+// from the name of its Secret, its time spent in the buffer is counted in the
+// queue latency.
+func bufferedEventReceivedAt(secret *corev1.Secret) time.Time {
+	nanos, _, _ := strings.Cut(strings.TrimPrefix(secret.GetName(), bufferedEventPrefix), "-")
+	if n, err := strconv.ParseInt(nanos, 10, 64); err == nil {
+		return time.Unix(0, n)
+	}
+	return secret.GetCreationTimestamp().Time
+}
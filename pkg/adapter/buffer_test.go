@@ -0,0 +1,174 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	testnewrepo "github.com/openshift-pipelines/pipelines-as-code/pkg/test/repository"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestMaintenanceBuffering(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	repo := testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+		Name:              "repo",
+		URL:               "https://github.com/owner/repo",
+		InstallNamespace:  "ns",
+		SecretName:        "token",
+		WebhookSecretName: "webhook",
+	})
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{Repositories: []*v1alpha1.Repository{repo}})
+	log, logCatcher := logger.GetLogger()
+	l := listener{
+		run: &params.Run{
+			Clients: clients.Clients{
+				PipelineAsCode: stdata.PipelineAsCode,
+				Log:            log,
+				Kube:           stdata.Kube,
+			},
+			Info: info.Info{
+				Pac: &info.PacOpts{Settings: settings.Settings{
+					MaintenanceMode:              true,
+					MaintenanceMaxBufferedEvents: 2,
+				}},
+				Controller: &info.ControllerInfo{GlobalRepository: info.DefaultGlobalRepoName},
+				Kube:       &info.KubeOpts{Namespace: "pipelines-as-code"},
+			},
+		},
+		kint: &kubernetestint.KinterfaceTest{
+			GetSecretResult: map[string]string{"token": "token", "webhook": "secret"},
+		},
+		logger: log,
+		buffer: &eventBuffer{},
+	}
+	secrets := stdata.Kube.CoreV1().Secrets("pipelines-as-code")
+	send := func(sha, webhookSecret string) int {
+		body := fmt.Sprintf(`{"ref": "refs/heads/main", "after": "%s", "head_commit": {"id": "%s"}, "pusher": {"name": "user"},
+"sender": {"login": "user"}, "repository": {"html_url": "https://github.com/owner/repo", "name": "repo", "owner": {"login": "owner"}}}`, sha, sha)
+		mac := hmac.New(sha256.New, []byte(webhookSecret))
+		mac.Write([]byte(body))
+		req := httptest.NewRequest(http.MethodPost, "/?from=test", bytes.NewReader([]byte(body)))
+		req.Header.Set("X-Github-Event", "push")
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		recorder := httptest.NewRecorder()
+		l.handleEvent(ctx)(recorder, req)
+		return recorder.Code
+	}
+	listBuffered := func() []corev1.Secret {
+		list, err := secrets.List(ctx, metav1.ListOptions{LabelSelector: keys.BufferedEvent + "=true"})
+		assert.NilError(t, err)
+		return list.Items
+	}
+
+	// the events not signed with the webhook secret of their Repository are
+	// not buffered
+	assert.Equal(t, send("first", "wrong"), http.StatusOK)
+	assert.Equal(t, len(listBuffered()), 0)
+
+	assert.Equal(t, send("first", "secret"), http.StatusAccepted)
+	assert.Equal(t, send("second", "secret"), http.StatusAccepted)
+	// the buffer is full, the Git provider redelivers the event later
+	assert.Equal(t, send("third", "secret"), http.StatusServiceUnavailable)
+	buffered := listBuffered()
+	assert.Equal(t, len(buffered), 2)
+	assert.Equal(t, string(buffered[0].Data[bufferedEventURLKey]), "/?from=test")
+	// the time spent in the buffer is counted in the latency of the events
+	receivedAt := bufferedEventReceivedAt(&buffered[0])
+	assert.Assert(t, time.Since(receivedAt) < time.Minute, receivedAt)
+	assert.Assert(t, l.buffer.pending)
+
+	// nothing is drained during the maintenance
+	l.drainEvents(ctx)
+	assert.Equal(t, len(listBuffered()), 2)
+
+	// an event failing to be replayed is kept until its last attempt, the
+	// events buffered after it wait for it
+	_, err := secrets.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   bufferedEventPrefix + "0000000000000000001-abcd",
+			Labels: map[string]string{keys.BufferedEvent: "true"},
+		},
+		Data: map[string][]byte{bufferedEventHeadersKey: []byte("invalid")},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	// the Repository is gone, the replayed events are not processed further
+	assert.NilError(t, stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("ns").Delete(ctx, "repo", metav1.DeleteOptions{}))
+	l.run.Info.Pac.MaintenanceMode = false
+	for attempt := 1; attempt < maxBufferedEventReplays; attempt++ {
+		l.drainEvents(ctx)
+		buffered = listBuffered()
+		assert.Equal(t, len(buffered), 3)
+		assert.Equal(t, buffered[0].GetAnnotations()[keys.BufferedEventReplays], fmt.Sprint(attempt))
+		assert.Equal(t, buffered[0].GetAnnotations()[keys.BufferedEventClaimed], "")
+	}
+	l.drainEvents(ctx)
+	assert.Equal(t, len(listBuffered()), 2)
+	assert.Equal(t, logCatcher.FilterMessageSnippet("dropping the buffered event").Len(), 1)
+
+	l.drainEvents(ctx)
+	assert.Equal(t, len(listBuffered()), 0)
+	assert.Assert(t, !l.buffer.pending)
+	assert.Assert(t, !l.buffer.draining)
+	assert.Equal(t, logCatcher.FilterMessageSnippet("replayed the buffered event").Len(), 2)
+
+	// the events are processed right away once drained
+	buffered2, err := l.bufferEvent(context.Background(), httptest.NewRequest(http.MethodPost, "/", nil), nil)
+	assert.NilError(t, err)
+	assert.Assert(t, !buffered2)
+}
+
+func TestClaimBufferedEvent(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+	log, _ := logger.GetLogger()
+	l := listener{
+		run: &params.Run{
+			Clients: clients.Clients{Kube: stdata.Kube, Log: log},
+			Info:    info.Info{Kube: &info.KubeOpts{Namespace: "pipelines-as-code"}},
+		},
+		logger: log,
+		buffer: &eventBuffer{},
+	}
+	secret, err := stdata.Kube.CoreV1().Secrets("pipelines-as-code").Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        bufferedEventPrefix + "0000000000000000001-abcd",
+			Namespace:   "pipelines-as-code",
+			Annotations: map[string]string{keys.BufferedEventClaimed: time.Now().UTC().Format(time.RFC3339)},
+		},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+
+	// claimed by another replica
+	claimed, err := l.claimEvent(ctx, secret.DeepCopy())
+	assert.NilError(t, err)
+	assert.Assert(t, !claimed)
+
+	// the replica claiming it has stopped before replaying it
+	secret.Annotations[keys.BufferedEventClaimed] = time.Now().Add(-bufferedEventClaimTimeout).UTC().Format(time.RFC3339)
+	claimed, err = l.claimEvent(ctx, secret)
+	assert.NilError(t, err)
+	assert.Assert(t, claimed)
+	claimedAt, err := time.Parse(time.RFC3339, secret.GetAnnotations()[keys.BufferedEventClaimed])
+	assert.NilError(t, err)
+	assert.Assert(t, time.Since(claimedAt) < time.Minute)
+}
@@ -0,0 +1,230 @@
+package adapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+)
+
+// the stages of the latency of an event, recorded by the event latency metric
+const (
+	latencyStageDelivery    = "delivery"
+	latencyStageQueue       = "queue"
+	latencyStageProviderAPI = "provider-api"
+	latencyStageProcessing  = "processing"
+	latencyStageEndToEnd    = "end-to-end"
+)
+
+// latencyWindow is the number of the last events the 95th percentile of the
+// latency is computed on, to check it against the event-latency-slo setting.
+const latencyWindow = 100
+
+// eventTiming follows an event from its sending by the git provider to the
+// end of its processing.
+type eventTiming struct {
+	// sentAt is when the git provider has sent the event, from its Date
+	// header or the timestamps of its payload, zero when it has none.
+	sentAt time.Time
+	// receivedAt is when the controller has received the event, before it is
+	// buffered during a maintenance.
+	receivedAt time.Time
+	// startedAt is when the processing of the event has started.
+	startedAt time.Time
+	// apiTime is the time spent in the requests to the git provider API
+	// during the processing.
+	apiTime provider.APITime
+}
+
+// payloadTimeLayouts are the layouts of the timestamps of the payloads,
+// GitLab sends some of them without the T and Bitbucket Data Center without
+// the colon in the zone.
+var payloadTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05 MST", "2006-01-02T15:04:05-0700"}
+
+// payloadTimestamps are the timestamps of the payloads of the git providers
+// closest to the sending of the event, the update of the comment, of the
+// pull request or of the merge request, the date of the event of Bitbucket
+// Data Center and the time of the push of GitHub.
+type payloadTimestamps struct {
+	Comment *struct {
+		UpdatedAt string `json:"updated_at"`
+	} `json:"comment"`
+	ObjectAttributes *struct {
+		UpdatedAt string `json:"updated_at"`
+	} `json:"object_attributes"`
+	PullRequest *struct {
+		UpdatedAt string `json:"updated_at"`
+	} `json:"pull_request"`
+	BitbucketPullRequest *struct {
+		UpdatedOn string `json:"updated_on"`
+	} `json:"pullrequest"`
+	Date       string `json:"date"`
+	Repository *struct {
+		// PushedAt is a unix time on the push events only, the other events
+		// have the time of the last push
+		PushedAt json.RawMessage `json:"pushed_at"`
+	} `json:"repository"`
+}
+
+// sentAt returns when the git provider has sent the event from the
+// timestamps of its payload, zero when there is none.
+func (p payloadTimestamps) sentAt() time.Time {
+	candidates := []string{}
+	if p.Comment != nil {
+		candidates = append(candidates, p.Comment.UpdatedAt)
+	}
+	if p.ObjectAttributes != nil {
+		candidates = append(candidates, p.ObjectAttributes.UpdatedAt)
+	}
+	if p.PullRequest != nil {
+		candidates = append(candidates, p.PullRequest.UpdatedAt)
+	}
+	if p.BitbucketPullRequest != nil {
+		candidates = append(candidates, p.BitbucketPullRequest.UpdatedOn)
+	}
+	candidates = append(candidates, p.Date)
+	for _, candidate := range candidates {
+		for _, layout := range payloadTimeLayouts {
+			if sentAt, err := time.Parse(layout, candidate); err == nil {
+				return sentAt
+			}
+		}
+	}
+	if p.Repository != nil {
+		if pushedAt, err := strconv.ParseInt(string(p.Repository.PushedAt), 10, 64); err == nil {
+			return time.Unix(pushedAt, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// newEventTiming starts the timing of an event received at receivedAt, sent
+// at the time of the Date header of the request, or of the timestamps of the
+// payload for the git providers not sending the header.
+func newEventTiming(request *http.Request, payload []byte, receivedAt time.Time) *eventTiming {
+	timing := &eventTiming{receivedAt: receivedAt}
+	if date := request.Header.Get("Date"); date != "" {
+		if sentAt, err := http.ParseTime(date); err == nil {
+			timing.sentAt = sentAt
+			return timing
+		}
+	}
+	timestamps := payloadTimestamps{}
+	if err := json.Unmarshal(payload, &timestamps); err == nil {
+		timing.sentAt = timestamps.sentAt()
+	}
+	return timing
+}
+
+// stages returns the latency of each stage of the event completed at
+// completedAt. The processing includes the requests to the git provider API.
+// The clocks of the git provider and of the controller may drift, a negative
+// delivery is counted as none.
+func (t *eventTiming) stages(completedAt time.Time) map[string]time.Duration {
+	positive := func(d time.Duration) time.Duration {
+		if d < 0 {
+			return 0
+		}
+		return d
+	}
+	stages := map[string]time.Duration{
+		latencyStageQueue:       positive(t.startedAt.Sub(t.receivedAt)),
+		latencyStageProviderAPI: t.apiTime.Duration(),
+		latencyStageProcessing:  positive(completedAt.Sub(t.startedAt)),
+		latencyStageEndToEnd:    positive(completedAt.Sub(t.receivedAt)),
+	}
+	if !t.sentAt.IsZero() {
+		stages[latencyStageDelivery] = positive(t.receivedAt.Sub(t.sentAt))
+		stages[latencyStageEndToEnd] += stages[latencyStageDelivery]
+	}
+	return stages
+}
+
+// latencyTracker keeps the latency of the last events to warn when the 95th
+// percentile of their end-to-end latency exceeds the event-latency-slo
+// setting, with the 95th percentile of each stage to tell whether the
+// slowness comes from the network, the queue or the git provider API.
+type latencyTracker struct {
+	mu       sync.Mutex
+	events   []map[string]time.Duration
+	next     int
+	breached bool
+}
+
+// record records the latency of the stages of an event and checks the 95th
+// percentile of the last events against the objective, 0 disables the
+// check.
+func (lt *latencyTracker) record(stages map[string]time.Duration, slo time.Duration, logger *zap.SugaredLogger) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	if len(lt.events) < latencyWindow {
+		lt.events = append(lt.events, stages)
+	} else {
+		lt.events[lt.next] = stages
+		lt.next = (lt.next + 1) % latencyWindow
+	}
+
+	breached := slo > 0 && lt.percentile(latencyStageEndToEnd, 95) > slo
+	if breached == lt.breached {
+		return
+	}
+	lt.breached = breached
+	metrics.RecordEventLatencySLOBreach(breached)
+	if breached {
+		logger.Warnf("the 95th percentile of the end-to-end latency of the last %d events exceeds the event-latency-slo of %s: %s",
+			len(lt.events), slo, lt.breakdown())
+		return
+	}
+	logger.Infof("the 95th percentile of the end-to-end latency of the last %d events is back under the event-latency-slo of %s: %s",
+		len(lt.events), slo, lt.breakdown())
+}
+
+// percentile returns the nth percentile of the latency of a stage over the
+// last events, with the nearest-rank method.
+func (lt *latencyTracker) percentile(stage string, n int) time.Duration {
+	values := make([]time.Duration, 0, len(lt.events))
+	for _, stages := range lt.events {
+		if d, ok := stages[stage]; ok {
+			values = append(values, d)
+		}
+	}
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	rank := (n*len(values) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1]
+}
+
+// breakdown describes the 95th percentile of each stage of the last events.
+func (lt *latencyTracker) breakdown() string {
+	parts := []string{}
+	for _, stage := range []string{latencyStageEndToEnd, latencyStageDelivery, latencyStageQueue, latencyStageProcessing, latencyStageProviderAPI} {
+		parts = append(parts, stage+"="+lt.percentile(stage, 95).Round(time.Millisecond).String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// recordLatency records the latency of the stages of a processed event, by
+// git provider.
+func (l listener) recordLatency(timing *eventTiming, providerName string, logger *zap.SugaredLogger) {
+	stages := timing.stages(time.Now())
+	for stage, latency := range stages {
+		metrics.RecordEventLatency(providerName, stage, latency)
+	}
+	if l.latency == nil {
+		return
+	}
+	pacOpts := l.run.Info.GetPacOpts()
+	l.latency.record(stages, pacOpts.EventLatencySLODuration(), logger)
+}
@@ -0,0 +1,144 @@
+package adapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	"gotest.tools/v3/assert"
+)
+
+func TestEventTimingStages(t *testing.T) {
+	receivedAt := time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Date", receivedAt.Add(-2*time.Second).Format(http.TimeFormat))
+	timing := newEventTiming(req, nil, receivedAt)
+	timing.startedAt = receivedAt.Add(time.Second)
+
+	stages := timing.stages(receivedAt.Add(4 * time.Second))
+	assert.Equal(t, stages[latencyStageDelivery], 2*time.Second)
+	assert.Equal(t, stages[latencyStageQueue], time.Second)
+	assert.Equal(t, stages[latencyStageProcessing], 3*time.Second)
+	assert.Equal(t, stages[latencyStageProviderAPI], time.Duration(0))
+	assert.Equal(t, stages[latencyStageEndToEnd], 6*time.Second)
+
+	// the clock of the git provider is ahead of the one of the controller
+	req.Header.Set("Date", receivedAt.Add(time.Minute).Format(http.TimeFormat))
+	timing = newEventTiming(req, nil, receivedAt)
+	timing.startedAt = receivedAt
+	stages = timing.stages(receivedAt.Add(time.Second))
+	assert.Equal(t, stages[latencyStageDelivery], time.Duration(0))
+	assert.Equal(t, stages[latencyStageEndToEnd], time.Second)
+
+	// without a Date header the end-to-end latency starts at the reception
+	timing = newEventTiming(httptest.NewRequest(http.MethodPost, "/", nil), nil, receivedAt)
+	timing.startedAt = receivedAt
+	stages = timing.stages(receivedAt.Add(time.Second))
+	_, ok := stages[latencyStageDelivery]
+	assert.Assert(t, !ok)
+	assert.Equal(t, stages[latencyStageEndToEnd], time.Second)
+}
+
+func TestEventTimingPayload(t *testing.T) {
+	receivedAt := time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+	tests := []struct {
+		name       string
+		payload    string
+		want       time.Duration
+		noDelivery bool
+	}{
+		{
+			name:    "github pull request",
+			payload: `{"action": "synchronize", "pull_request": {"updated_at": "2024-01-01T12:00:07Z"}, "repository": {"pushed_at": "2023-12-31T12:00:00Z"}}`,
+			want:    3 * time.Second,
+		},
+		{
+			name:    "github push",
+			payload: `{"ref": "refs/heads/main", "repository": {"pushed_at": 1704110405}}`,
+			want:    5 * time.Second,
+		},
+		{
+			name:    "github comment on a pull request",
+			payload: `{"action": "created", "comment": {"updated_at": "2024-01-01T12:00:09Z"}, "issue": {"updated_at": "2024-01-01T11:00:00Z"}}`,
+			want:    time.Second,
+		},
+		{
+			name:    "gitlab merge request",
+			payload: `{"object_kind": "merge_request", "object_attributes": {"updated_at": "2024-01-01 12:00:06 UTC"}}`,
+			want:    4 * time.Second,
+		},
+		{
+			name:    "bitbucket cloud pull request",
+			payload: `{"pullrequest": {"updated_on": "2024-01-01T12:00:08.123456+00:00"}}`,
+			want:    1876544 * time.Microsecond,
+		},
+		{
+			name:    "bitbucket data center",
+			payload: `{"eventKey": "pr:opened", "date": "2024-01-01T22:00:08+1000"}`,
+			want:    2 * time.Second,
+		},
+		{
+			name:       "gitlab push",
+			payload:    `{"object_kind": "push", "commits": [{"timestamp": "2024-01-01T11:00:00Z"}]}`,
+			noDelivery: true,
+		},
+		{
+			name:       "invalid payload",
+			payload:    `not json`,
+			noDelivery: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timing := newEventTiming(httptest.NewRequest(http.MethodPost, "/", nil), []byte(tt.payload), receivedAt)
+			timing.startedAt = receivedAt
+			stages := timing.stages(receivedAt)
+			delivery, ok := stages[latencyStageDelivery]
+			assert.Equal(t, ok, !tt.noDelivery)
+			assert.Equal(t, delivery, tt.want)
+		})
+	}
+}
+
+func TestLatencyTracker(t *testing.T) {
+	log, logCatcher := logger.GetLogger()
+	lt := &latencyTracker{}
+	event := func(endToEnd time.Duration) map[string]time.Duration {
+		return map[string]time.Duration{
+			latencyStageEndToEnd:    endToEnd,
+			latencyStageQueue:       endToEnd / 2,
+			latencyStageProcessing:  endToEnd / 2,
+			latencyStageProviderAPI: endToEnd / 4,
+		}
+	}
+
+	for i := 0; i < 90; i++ {
+		lt.record(event(time.Second), 10*time.Second, log)
+	}
+	assert.Assert(t, !lt.breached)
+	assert.Equal(t, lt.percentile(latencyStageEndToEnd, 95), time.Second)
+
+	// more than 5% of the events are over the objective
+	for i := 0; i < 10; i++ {
+		lt.record(event(20*time.Second), 10*time.Second, log)
+	}
+	assert.Assert(t, lt.breached)
+	assert.Equal(t, len(lt.events), latencyWindow)
+	assert.Equal(t, logCatcher.FilterMessageSnippet("exceeds the event-latency-slo of 10s").Len(), 1)
+	assert.Equal(t, lt.breakdown(), "end-to-end=20s delivery=0s queue=10s processing=10s provider-api=5s")
+
+	// the oldest events are replaced by the new ones
+	for i := 0; i < latencyWindow; i++ {
+		lt.record(event(time.Second), 10*time.Second, log)
+	}
+	assert.Assert(t, !lt.breached)
+	assert.Equal(t, len(lt.events), latencyWindow)
+	assert.Equal(t, logCatcher.FilterMessageSnippet("is back under the event-latency-slo").Len(), 1)
+
+	// no objective
+	lt = &latencyTracker{}
+	lt.record(event(time.Hour), 0, log)
+	assert.Assert(t, !lt.breached)
+}
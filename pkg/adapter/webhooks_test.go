@@ -0,0 +1,162 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// webhook is a payload of the corpus in testdata/webhooks with the headers
+// sent by the Git provider.
+type webhook struct {
+	Headers map[string]string `json:"headers"`
+	Payload json.RawMessage   `json:"payload"`
+}
+
+func readWebhooks(tb testing.TB) map[string]webhook {
+	tb.Helper()
+	files, err := filepath.Glob(filepath.Join("testdata", "webhooks", "*.json"))
+	assert.NilError(tb, err)
+	webhooks := map[string]webhook{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		assert.NilError(tb, err)
+		var w webhook
+		assert.NilError(tb, json.Unmarshal(data, &w))
+		webhooks[strings.TrimSuffix(filepath.Base(file), ".json")] = w
+	}
+	return webhooks
+}
+
+// parseWebhook detects the Git provider of the webhook and parses its payload
+// like the adapter does. With withAppSecret the GitHub App secret is set, its
+// token and API calls go to the PAC_GIT_PROVIDER_TOKEN_APIURL test server.
+func parseWebhook(ctx context.Context, headers http.Header, payload []byte, withAppSecret bool) (*info.Event, error) {
+	kube := kubefake.NewSimpleClientset()
+	if withAppSecret {
+		kube = kubefake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: info.DefaultPipelinesAscodeSecretName, Namespace: "pipelines-as-code"},
+			Data: map[string][]byte{
+				keys.GithubApplicationID: []byte("12345"),
+				keys.GithubPrivateKey:    []byte(fakePrivateKey),
+			},
+		})
+		ctx = info.StoreNS(ctx, "pipelines-as-code")
+	}
+	l := listener{
+		logger: zap.NewNop().Sugar(),
+		run: &params.Run{
+			Clients: clients.Clients{Kube: kube},
+			Info:    info.NewInfo(),
+		},
+	}
+	pacInfo := l.run.Info.Pac
+	pacInfo.BitbucketCloudCheckSourceIP = false
+	pacInfo.PreviewEnvironments = true
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = headers
+	gitProvider, _, err := l.detectProvider(req, string(payload), pacInfo)
+	if err != nil {
+		return nil, err
+	}
+	gitProvider.SetPacInfo(pacInfo)
+	return gitProvider.ParsePayload(ctx, l.run, req, string(payload))
+}
+
+func TestWebhookNormalization(t *testing.T) {
+	// the GitHub API of the events of the GitHub App
+	_, mux, serverURL, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+	t.Setenv("PAC_GIT_PROVIDER_TOKEN_APIURL", serverURL+"/api/v3")
+	mux.HandleFunc("/app/installations/12/access_tokens", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"token": "app-token"}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/pulls/42", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{
+			"number": 42,
+			"title": "Add a feature",
+			"html_url": "https://github.com/owner/repo/pull/42",
+			"head": {"ref": "feature", "sha": "abc123", "repo": {"html_url": "https://github.com/owner/repo"}},
+			"base": {"ref": "main", "sha": "def456", "repo": {"html_url": "https://github.com/owner/repo", "default_branch": "main"}},
+			"user": {"login": "linda"}
+		}`)
+	})
+
+	for name, w := range readWebhooks(t) {
+		if name == "incoming" {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			headers := http.Header{}
+			for key, value := range w.Headers {
+				headers.Set(key, value)
+			}
+			// the errors of the events rejected by the parsing are recorded
+			out := []byte{}
+			event, err := parseWebhook(context.Background(), headers, w.Payload, true)
+			if err != nil {
+				out = []byte("error: " + err.Error())
+			} else {
+				// the provider payload and the request are not normalized
+				event.Event, event.Request = nil, nil
+				out, err = json.MarshalIndent(event, "", "  ")
+				assert.NilError(t, err)
+			}
+			golden.Assert(t, string(out)+"\n", filepath.Join("webhooks", name+".golden"))
+		})
+	}
+}
+
+// FuzzParseWebhook feeds the mutated payloads and event types of the corpus
+// to the provider detection and the payload parsing, a malformed webhook
+// should be rejected with an error and never make the adapter panic.
+func FuzzParseWebhook(f *testing.F) {
+	for _, w := range readWebhooks(f) {
+		for key, value := range w.Headers {
+			if key == "Content-Type" || strings.HasSuffix(key, "-Delivery") || strings.HasSuffix(key, "-Id") {
+				continue
+			}
+			f.Add(key, value, []byte(w.Payload))
+		}
+	}
+	f.Fuzz(func(_ *testing.T, header, eventType string, payload []byte) {
+		headers := http.Header{}
+		headers.Set(header, eventType)
+		_, _ = parseWebhook(context.Background(), headers, payload, false)
+	})
+}
+
+// FuzzIncomingPayload feeds the mutated incoming webhook payloads to their
+// parsing.
+func FuzzIncomingPayload(f *testing.F) {
+	f.Add([]byte(readWebhooks(f)["incoming"].Payload))
+	f.Fuzz(func(_ *testing.T, payload []byte) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/incoming", bytes.NewReader(payload))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		_, _ = applyIncomingParams(req, payload, []string{"image"})
+	})
+}
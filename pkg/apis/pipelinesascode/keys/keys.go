@@ -32,6 +32,7 @@ const (
 	Sender          = pipelinesascode.GroupName + "/sender"
 	EventType       = pipelinesascode.GroupName + "/event-type"
 	Branch          = pipelinesascode.GroupName + "/branch"
+	SourceBranch    = pipelinesascode.GroupName + "/source-branch"
 	Repository      = pipelinesascode.GroupName + "/repository"
 	GitProvider     = pipelinesascode.GroupName + "/git-provider"
 	State           = pipelinesascode.GroupName + "/state"
@@ -54,6 +55,88 @@ const (
 	MaxKeepRuns     = pipelinesascode.GroupName + "/max-keep-runs"
 	LogURL          = pipelinesascode.GroupName + "/log-url"
 	ExecutionOrder  = pipelinesascode.GroupName + "/execution-order"
+	// OnIgnoredSender lets a PipelineRun run for the senders ignored by the ignored-senders and ignore-bot-prs settings
+	OnIgnoredSender = pipelinesascode.GroupName + "/on-ignored-sender"
+	// SkipCILabel is the pull request label skipping the CI, when allowed by the skip_ci setting
+	SkipCILabel = pipelinesascode.GroupName + "/skip"
+	// ValidateToken asks the admission webhook to check the git_provider token against the provider API
+	ValidateToken = pipelinesascode.GroupName + "/validate-token"
+	// OrgDefaults labels the Repositories of the controller namespace holding the defaults of all the Repositories under their URL
+	OrgDefaults = pipelinesascode.GroupName + "/org-defaults"
+	// OrgDefaultsNamespaces lists the namespace globs of the Repositories an org defaults Repository applies to
+	OrgDefaultsNamespaces = pipelinesascode.GroupName + "/org-defaults-namespaces"
+	// EventDedup labels the leases recording the events already started, to skip the same event delivered again
+	EventDedup = pipelinesascode.GroupName + "/event-dedup"
+	// RegistrySecretSource is the namespace/name of the registry secret cloned by secret-auto-create-registry-secrets
+	RegistrySecretSource = pipelinesascode.GroupName + "/registry-secret-source"
+	// RegistrySecrets set to "true" on a ServiceAccount lets secret-auto-create-registry-secrets add the registry secrets to it
+	RegistrySecrets = pipelinesascode.GroupName + "/registry-secrets"
+	// EventPayload is the ConfigMap holding the redacted event of a PipelineRun, stored with the event-payload-ttl setting
+	EventPayload = pipelinesascode.GroupName + "/event-payload"
+	// EventPayloadExpires is the time after which the ConfigMap of an event payload is garbage collected
+	EventPayloadExpires = pipelinesascode.GroupName + "/event-payload-expires"
+	// BufferedEvent labels the Secrets holding the events received during the maintenance-mode of the controller
+	BufferedEvent = pipelinesascode.GroupName + "/buffered-event"
+	// BufferedEventClaimed is when a buffered event has been claimed by a replica to replay it
+	BufferedEventClaimed = pipelinesascode.GroupName + "/buffered-event-claimed"
+	// BufferedEventReplays counts the failed replays of a buffered event
+	BufferedEventReplays = pipelinesascode.GroupName + "/buffered-event-replays"
+	// StatusReporter is the watcher replica claiming the report of the final status of a PipelineRun
+	StatusReporter = pipelinesascode.GroupName + "/status-reporter"
+	// TaskOutcomes records the outcome and the failure signature of the tasks of a finished PipelineRun, to detect the flaky tasks
+	TaskOutcomes = pipelinesascode.GroupName + "/task-outcomes"
+	// RetryOf is the PipelineRun re-run by an automatic retry after an infrastructure failure
+	RetryOf = pipelinesascode.GroupName + "/retry-of"
+	// RetryCount is the number of automatic retries leading to a PipelineRun
+	RetryCount = pipelinesascode.GroupName + "/retry-count"
+	// RetriedBy is the PipelineRun created by the automatic retry of a failed PipelineRun
+	RetriedBy = pipelinesascode.GroupName + "/retried-by"
+	// FrozenUntil is the end of the freeze window holding a PipelineRun
+	FrozenUntil = pipelinesascode.GroupName + "/frozen-until"
+	// MinApprovals is the number of approvals a pull request needs before running a PipelineRun
+	MinApprovals = pipelinesascode.GroupName + "/min-approvals"
+	// OnSuccessTrigger lists the PipelineRuns of other repositories to trigger with an incoming webhook on success
+	OnSuccessTrigger = pipelinesascode.GroupName + "/on-success-trigger"
+	// OnSuccessTriggerSecret is the Secret holding the incoming webhook secret of the repositories to trigger
+	OnSuccessTriggerSecret = pipelinesascode.GroupName + "/on-success-trigger-secret"
+	// FanOutChain lists the repositories whose PipelineRuns have triggered the PipelineRun on success
+	FanOutChain = pipelinesascode.GroupName + "/fan-out-chain"
+	// StatusURL is the URL of the status of the PipelineRun on the git provider
+	StatusURL = pipelinesascode.GroupName + "/status-url"
+	// OnDeploymentEnvironment lists the environments whose deployments are verified by the PipelineRun
+	OnDeploymentEnvironment = pipelinesascode.GroupName + "/on-deployment-environment"
+	// DeploymentEnvironment is the environment of the deployment verified by the PipelineRun
+	DeploymentEnvironment = pipelinesascode.GroupName + "/deployment-environment"
+	// DeploymentCallbackURL is the URL approving or rejecting the deployment verified by the PipelineRun
+	DeploymentCallbackURL = pipelinesascode.GroupName + "/deployment-callback-url"
+	// Stage is the stage of the PipelineRun, like build, test or deploy, grouping and ordering its check run
+	Stage = pipelinesascode.GroupName + "/stage"
+	// Timeout is the timeout of the PipelineRun, clamped to the max-pipelinerun-timeout setting
+	Timeout = pipelinesascode.GroupName + "/timeout"
+	// Priority is the priority of the PipelineRun in the queue of its Repository, only honoured for the push events
+	Priority = pipelinesascode.GroupName + "/priority"
+	// PreemptedBy is the higher-priority PipelineRun which has cancelled the queued PipelineRun
+	PreemptedBy = pipelinesascode.GroupName + "/preempted-by"
+	// CloudCredentialsSecret is the Secret holding the short-lived cloud credentials of the PipelineRun, deleted once it is done
+	CloudCredentialsSecret = pipelinesascode.GroupName + "/cloud-credentials-secret"
+	// CloudCredentials set to "true" on a ServiceAccount lets the cloud_credentials setting of the Repositories exchange its tokens
+	CloudCredentials = pipelinesascode.GroupName + "/cloud-credentials"
+	// SignedEventSecret is the Secret holding the metadata of the event signed by the controller for the tasks of the PipelineRun
+	SignedEventSecret = pipelinesascode.GroupName + "/signed-event-secret"
+	// ServiceAccount chooses the service account of the PipelineRun, unless the service_accounts setting of the Repository forces one
+	ServiceAccount = pipelinesascode.GroupName + "/service-account"
+	// RunID is the unique ID of the PipelineRun, the external ID of its check run and the run-id field of its logs
+	RunID = pipelinesascode.GroupName + "/run-id"
+	// IssueKeys are the comma separated keys of the issues the PipelineRun is linked to once done
+	IssueKeys = pipelinesascode.GroupName + "/issue-keys"
+	// RunAttempt is the attempt counter of the PipelineRun named by the deterministic pipelinerun-naming setting
+	RunAttempt = pipelinesascode.GroupName + "/run-attempt"
+	// Snapshot is the ConfigMap holding the resolved PipelineRun as it has been created, re-run by /retest --exact
+	Snapshot = pipelinesascode.GroupName + "/snapshot"
+	// ExactRetestOf is the PipelineRun whose snapshot has been re-run by /retest --exact
+	ExactRetestOf = pipelinesascode.GroupName + "/exact-retest-of"
+	// FanOutChainHeader carries the fan-out chain to the incoming webhook
+	FanOutChainHeader = "X-PAC-Fan-Out-Chain"
 	// PublicGithubAPIURL default is "https://api.github.com" but it can be overridden by X-GitHub-Enterprise-Host header.
 	PublicGithubAPIURL = "https://api.github.com"
 	// InstallationURL gives us the Installation ID for the GitHub Application.
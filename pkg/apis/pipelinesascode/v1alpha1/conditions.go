@@ -0,0 +1,89 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RepositoryConditionReady is true when all the other conditions of
+	// the Repository are true, `kubectl wait --for=condition=Ready` gates
+	// on it.
+	RepositoryConditionReady = "Ready"
+	// RepositoryConditionWebhookConfigured is true when the webhook secret
+	// validating the payloads of the git provider is available.
+	RepositoryConditionWebhookConfigured = "WebhookConfigured"
+	// RepositoryConditionSecretValid is true when the secret of the git
+	// provider token exists and has its key.
+	RepositoryConditionSecretValid = "SecretValid"
+	// RepositoryConditionQueueHealthy is false when the queued PipelineRuns
+	// of the Repository cannot be started.
+	RepositoryConditionQueueHealthy = "QueueHealthy"
+)
+
+// RepositoryDependentConditions are the conditions the Ready condition is
+// computed from.
+var RepositoryDependentConditions = []string{
+	RepositoryConditionWebhookConfigured,
+	RepositoryConditionSecretValid,
+	RepositoryConditionQueueHealthy,
+}
+
+// GetCondition returns the condition of the type or nil.
+func (r *Repository) GetCondition(conditionType string) *metav1.Condition {
+	return meta.FindStatusCondition(r.RepositoryStatus.Conditions, conditionType)
+}
+
+// SetConditions sets the conditions and computes the Ready condition from
+// them, it returns true when a condition changed and the status has to be
+// updated.
+func (r *Repository) SetConditions(conditions ...metav1.Condition) bool {
+	changed := false
+	for _, condition := range conditions {
+		if r.setCondition(condition) {
+			changed = true
+		}
+	}
+
+	ready := metav1.Condition{
+		Type:   RepositoryConditionReady,
+		Status: metav1.ConditionTrue,
+		Reason: "Ready",
+	}
+	for _, conditionType := range RepositoryDependentConditions {
+		condition := r.GetCondition(conditionType)
+		switch {
+		case condition == nil || condition.Status == metav1.ConditionUnknown:
+			if ready.Status == metav1.ConditionTrue {
+				ready.Status = metav1.ConditionUnknown
+				ready.Reason = conditionType + "Unknown"
+				ready.Message = "the " + conditionType + " condition is not known yet"
+			}
+		case condition.Status == metav1.ConditionFalse:
+			ready.Status = metav1.ConditionFalse
+			ready.Reason = condition.Reason
+			ready.Message = condition.Message
+		}
+		if ready.Status == metav1.ConditionFalse {
+			break
+		}
+	}
+	if r.setCondition(ready) {
+		changed = true
+	}
+	if changed {
+		r.RepositoryStatus.ObservedGeneration = r.GetGeneration()
+	}
+	return changed
+}
+
+// setCondition sets the condition for the current generation of the
+// Repository, it returns true when it changed.
+func (r *Repository) setCondition(condition metav1.Condition) bool {
+	condition.ObservedGeneration = r.GetGeneration()
+	old := r.GetCondition(condition.Type)
+	changed := old == nil || old.Status != condition.Status || old.Reason != condition.Reason ||
+		old.Message != condition.Message || old.ObservedGeneration != condition.ObservedGeneration
+	meta.SetStatusCondition(&r.RepositoryStatus.Conditions, condition)
+	return changed
+}
@@ -0,0 +1,79 @@
+package v1alpha1
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetConditions(t *testing.T) {
+	condition := func(conditionType string, status metav1.ConditionStatus, reason string) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: status, Reason: reason, Message: reason}
+	}
+	tests := []struct {
+		name        string
+		conditions  []metav1.Condition
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+		wantChanged bool
+	}{
+		{
+			name: "all true",
+			conditions: []metav1.Condition{
+				condition(RepositoryConditionWebhookConfigured, metav1.ConditionTrue, "GitHubApp"),
+				condition(RepositoryConditionSecretValid, metav1.ConditionTrue, "GitHubApp"),
+				condition(RepositoryConditionQueueHealthy, metav1.ConditionTrue, "QueueRunning"),
+			},
+			wantStatus:  metav1.ConditionTrue,
+			wantReason:  "Ready",
+			wantChanged: true,
+		},
+		{
+			name: "missing condition",
+			conditions: []metav1.Condition{
+				condition(RepositoryConditionWebhookConfigured, metav1.ConditionTrue, "GitHubApp"),
+				condition(RepositoryConditionSecretValid, metav1.ConditionTrue, "GitHubApp"),
+			},
+			wantStatus:  metav1.ConditionUnknown,
+			wantReason:  "QueueHealthyUnknown",
+			wantChanged: true,
+		},
+		{
+			name: "false wins over unknown",
+			conditions: []metav1.Condition{
+				condition(RepositoryConditionWebhookConfigured, metav1.ConditionUnknown, "SecretError"),
+				condition(RepositoryConditionSecretValid, metav1.ConditionFalse, "SecretNotFound"),
+			},
+			wantStatus:  metav1.ConditionFalse,
+			wantReason:  "SecretNotFound",
+			wantChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &Repository{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+			assert.Equal(t, repo.SetConditions(tt.conditions...), tt.wantChanged)
+			ready := repo.GetCondition(RepositoryConditionReady)
+			assert.Assert(t, ready != nil)
+			assert.Equal(t, ready.Status, tt.wantStatus)
+			assert.Equal(t, ready.Reason, tt.wantReason)
+			assert.Equal(t, ready.ObservedGeneration, int64(2))
+			assert.Equal(t, repo.RepositoryStatus.ObservedGeneration, int64(2))
+
+			// setting the same conditions again changes nothing
+			assert.Assert(t, !repo.SetConditions(tt.conditions...))
+		})
+	}
+}
+
+func TestSetConditionsNewGeneration(t *testing.T) {
+	repo := &Repository{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	queue := metav1.Condition{Type: RepositoryConditionQueueHealthy, Status: metav1.ConditionFalse, Reason: "QueueFailed", Message: errors.New("boom").Error()}
+	assert.Assert(t, repo.SetConditions(queue))
+	repo.Generation = 2
+	assert.Assert(t, repo.SetConditions(queue))
+	assert.Equal(t, repo.GetCondition(RepositoryConditionQueueHealthy).ObservedGeneration, int64(2))
+	assert.Equal(t, repo.GetCondition(RepositoryConditionReady).Message, "boom")
+}
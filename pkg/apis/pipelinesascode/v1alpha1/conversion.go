@@ -0,0 +1,22 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"knative.dev/pkg/apis"
+)
+
+var _ apis.Convertible = (*Repository)(nil)
+
+// ConvertTo implements apis.Convertible, v1alpha1 is the storage version
+// the other versions are converted to.
+func (r *Repository) ConvertTo(_ context.Context, to apis.Convertible) error {
+	return fmt.Errorf("v1alpha1 is the storage version, cannot convert to %T", to)
+}
+
+// ConvertFrom implements apis.Convertible, v1alpha1 is the storage version
+// the other versions are converted from.
+func (r *Repository) ConvertFrom(_ context.Context, from apis.Convertible) error {
+	return fmt.Errorf("v1alpha1 is the storage version, cannot convert from %T", from)
+}
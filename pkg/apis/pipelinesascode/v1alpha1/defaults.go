@@ -0,0 +1,84 @@
+package v1alpha1
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+)
+
+// OrgDefaultsFor returns the org defaults Repositories applying to the
+// Repository URL in the namespace, the ones with the longest URL, the most
+// specific, first. An org defaults Repository applies to all the Repositories
+// with a URL under its own, https://github.com/org applies to
+// https://github.com/org/repo but not to https://github.com/org2/repo, in the
+// namespaces listed by the admin in its org-defaults-namespaces annotation.
+func OrgDefaultsFor(repoURL, namespace string, orgDefaults []*Repository) []*Repository {
+	repoURL = strings.TrimSuffix(repoURL, "/")
+	ret := []*Repository{}
+	for _, defaults := range orgDefaults {
+		prefix := strings.TrimSuffix(defaults.Spec.URL, "/")
+		if prefix == "" || !strings.HasPrefix(repoURL, prefix+"/") {
+			continue
+		}
+		if !orgDefaultsNamespaceAllowed(defaults, namespace) {
+			continue
+		}
+		ret = append(ret, defaults)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return len(strings.TrimSuffix(ret[i].Spec.URL, "/")) > len(strings.TrimSuffix(ret[j].Spec.URL, "/"))
+	})
+	return ret
+}
+
+// orgDefaultsNamespaceAllowed returns true if the namespace matches one of the
+// comma separated namespace globs of the org-defaults-namespaces annotation,
+// an org defaults Repository without it applies to no namespace so its git
+// provider secret is not shared with any namespace claiming a URL under it.
+func orgDefaultsNamespaceAllowed(defaults *Repository, namespace string) bool {
+	for _, pattern := range strings.Split(defaults.GetAnnotations()[keys.OrgDefaultsNamespaces], ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeDefaults merges the defaults Repositories into the Repository in order,
+// a setting is taken from the first one defining it when the Repository
+// doesn't. The Pipelines-as-Code annotations of the defaults are added when the
+// Repository doesn't have them. It returns the namespace where the git
+// provider secret is, the one of the default providing it or the namespace of
+// the Repository.
+func (r *Repository) MergeDefaults(defaults ...*Repository) string {
+	secretNS := r.GetNamespace()
+	for _, d := range defaults {
+		if d == nil {
+			continue
+		}
+		if r.Spec.GitProvider != nil && r.Spec.GitProvider.Secret == nil && d.Spec.GitProvider != nil && d.Spec.GitProvider.Secret != nil {
+			secretNS = d.GetNamespace()
+		}
+		r.Spec.Merge(d.Spec)
+		for k, v := range d.GetAnnotations() {
+			if !strings.HasPrefix(k, pipelinesascode.GroupName+"/") || k == keys.OrgDefaultsNamespaces {
+				continue
+			}
+			if _, ok := r.GetAnnotations()[k]; ok {
+				continue
+			}
+			if r.Annotations == nil {
+				r.Annotations = map[string]string{}
+			}
+			r.Annotations[k] = v
+		}
+	}
+	return secretNS
+}
@@ -0,0 +1,94 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOrgDefaultsFor(t *testing.T) {
+	namespaces := map[string]string{"pipelinesascode.tekton.dev/org-defaults-namespaces": "team-a, team-a-*"}
+	org := &Repository{ObjectMeta: metav1.ObjectMeta{Name: "org", Annotations: namespaces}, Spec: RepositorySpec{URL: "https://gitlab.com/group/"}}
+	subgroup := &Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "subgroup", Annotations: map[string]string{"pipelinesascode.tekton.dev/org-defaults-namespaces": "team-a-ci"}},
+		Spec:       RepositorySpec{URL: "https://gitlab.com/group/subgroup"},
+	}
+	other := &Repository{ObjectMeta: metav1.ObjectMeta{Name: "other", Annotations: namespaces}, Spec: RepositorySpec{URL: "https://gitlab.com/group2"}}
+	empty := &Repository{ObjectMeta: metav1.ObjectMeta{Name: "empty", Annotations: namespaces}}
+	noNamespaces := &Repository{ObjectMeta: metav1.ObjectMeta{Name: "no-namespaces"}, Spec: RepositorySpec{URL: "https://gitlab.com/group"}}
+	orgDefaults := []*Repository{org, other, subgroup, empty, noNamespaces}
+
+	got := OrgDefaultsFor("https://gitlab.com/group/subgroup/repo", "team-a-ci", orgDefaults)
+	assert.DeepEqual(t, got, []*Repository{subgroup, org})
+
+	got = OrgDefaultsFor("https://gitlab.com/group/subgroup/repo", "team-a", orgDefaults)
+	assert.DeepEqual(t, got, []*Repository{org})
+
+	got = OrgDefaultsFor("https://gitlab.com/group/repo/", "team-a", orgDefaults)
+	assert.DeepEqual(t, got, []*Repository{org})
+
+	got = OrgDefaultsFor("https://gitlab.com/group/repo", "team-b", orgDefaults)
+	assert.Equal(t, len(got), 0)
+
+	got = OrgDefaultsFor("https://gitlab.com/group2repo", "team-a", orgDefaults)
+	assert.Equal(t, len(got), 0)
+}
+
+func TestMergeDefaults(t *testing.T) {
+	two, three := 2, 3
+	repo := &Repository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "repo",
+			Namespace:   "user",
+			Annotations: map[string]string{"pipelinesascode.tekton.dev/validate-token": "false"},
+		},
+		Spec: RepositorySpec{
+			URL:         "https://gitlab.com/group/subgroup/repo",
+			GitProvider: &GitProvider{Type: "gitlab"},
+		},
+	}
+	subgroup := &Repository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "subgroup",
+			Namespace: "pipelines-as-code",
+			Annotations: map[string]string{
+				"pipelinesascode.tekton.dev/validate-token":          "true",
+				"pipelinesascode.tekton.dev/other":                   "value",
+				"pipelinesascode.tekton.dev/org-defaults-namespaces": "user",
+				"example.com/not-inherited":                          "value",
+			},
+		},
+		Spec: RepositorySpec{
+			ConcurrencyLimit: &two,
+			Settings:         &Settings{PipelineRunProvenance: "default_branch"},
+		},
+	}
+	org := &Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "org", Namespace: "pipelines-as-code"},
+		Spec: RepositorySpec{
+			ConcurrencyLimit: &three,
+			Params:           &[]Params{{Name: "org", Value: "value"}},
+			GitProvider:      &GitProvider{Type: "gitlab", Secret: &Secret{Name: "org-token"}},
+			Settings: &Settings{
+				PipelineRunProvenance: "source",
+				Policy:                &Policy{PullRequest: []string{"org-admins"}},
+			},
+		},
+	}
+
+	secretNS := repo.MergeDefaults(subgroup, nil, org)
+	assert.Equal(t, secretNS, "pipelines-as-code")
+	assert.Equal(t, *repo.Spec.ConcurrencyLimit, 2)
+	assert.Equal(t, repo.Spec.Settings.PipelineRunProvenance, "default_branch")
+	assert.DeepEqual(t, repo.Spec.Settings.Policy, &Policy{PullRequest: []string{"org-admins"}})
+	assert.DeepEqual(t, *repo.Spec.Params, []Params{{Name: "org", Value: "value"}})
+	assert.Equal(t, repo.Spec.GitProvider.Secret.Name, "org-token")
+	assert.DeepEqual(t, repo.GetAnnotations(), map[string]string{
+		"pipelinesascode.tekton.dev/validate-token": "false",
+		"pipelinesascode.tekton.dev/other":          "value",
+	})
+
+	noDefaults := &Repository{ObjectMeta: metav1.ObjectMeta{Namespace: "user"}}
+	assert.Equal(t, noDefaults.MergeDefaults(), "user")
+}
@@ -15,6 +15,23 @@ type Repository struct {
 
 	Spec   RepositorySpec        `json:"spec"`
 	Status []RepositoryRunStatus `json:"pipelinerun_status,omitempty"`
+
+	// RepositoryStatus is the status of the Repository itself, the last
+	// PipelineRuns are in Status.
+	RepositoryStatus RepositoryStatus `json:"status,omitempty"`
+}
+
+// RepositoryStatus holds the conditions of the Repository.
+type RepositoryStatus struct {
+	// ObservedGeneration is the generation of the Repository the conditions
+	// were computed for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the Ready, WebhookConfigured, SecretValid and
+	// QueueHealthy conditions of the Repository.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type RepositoryRunStatus struct {
@@ -56,6 +73,11 @@ type RepositoryRunStatus struct {
 	// +optional
 	EventType *string `json:"event_type,omitempty"`
 
+	// OriginalPipelineRunName is the name of the PipelineRun in the .tekton
+	// directory of that run
+	// +optional
+	OriginalPipelineRunName *string `json:"original_pipelinerun_name,omitempty"`
+
 	// CollectedTaskInfos is the information about tasks
 	CollectedTaskInfos *map[string]TaskInfos `json:"failure_reason,omitempty"`
 }
@@ -84,6 +106,9 @@ func (r *RepositorySpec) Merge(newRepo RepositorySpec) {
 		r.ConcurrencyLimit = newRepo.ConcurrencyLimit
 	}
 	if newRepo.Settings != nil {
+		if r.Settings == nil {
+			r.Settings = &Settings{}
+		}
 		r.Settings.Merge(newRepo.Settings)
 	}
 	if r.GitProvider != nil && newRepo.GitProvider != nil {
@@ -104,23 +129,352 @@ type Settings struct {
 	GithubAppTokenScopeRepos []string `json:"github_app_token_scope_repos,omitempty"`
 	PipelineRunProvenance    string   `json:"pipelinerun_provenance,omitempty"`
 	Policy                   *Policy  `json:"policy,omitempty"`
+	CheckRunNameTemplate     string   `json:"check_run_name_template,omitempty"`
+	// ApplicationName overrides the application name of the controller in
+	// the check runs, statuses and comments of the Repository.
+	ApplicationName string           `json:"application_name,omitempty"`
+	StatusTemplates *StatusTemplates `json:"status_templates,omitempty"`
+	// Formatting overrides the formatting of the statuses and comments,
+	// chosen by default from the git provider of the Repository.
+	Formatting *Formatting `json:"formatting,omitempty"`
+	// Locale is the locale of the statuses and comments, English by default.
+	Locale string `json:"locale,omitempty"`
+	// CommentStrategy set to update edits the status comment of a PipelineRun
+	// on a SHA instead of posting a new one on each run, on the providers
+	// reporting the statuses as comments (GitLab and Bitbucket Cloud).
+	CommentStrategy string `json:"comment_strategy,omitempty"`
+	// SkipCI controls the [skip ci] directives in commit messages and the
+	// skip label on pull requests.
+	SkipCI *SkipCI `json:"skip_ci,omitempty"`
+	// PipelineRunDirs are the directories of the repository, relative to its
+	// root, where the PipelineRuns are defined. Defaults to .tekton.
+	PipelineRunDirs []string `json:"pipelinerun_dirs,omitempty"`
+	// PipelineRunExtensions are the extensions of the files read as
+	// PipelineRuns in these directories. Defaults to .yaml and .yml.
+	PipelineRunExtensions []string `json:"pipelinerun_extensions,omitempty"`
+	// PipelineRunLabels are the labels added to the PipelineRuns created
+	// for the Repository, the values can use the placeholders of the
+	// PipelineRuns templates.
+	PipelineRunLabels map[string]string `json:"pipelinerun_labels,omitempty"`
+	// PipelineRunAnnotations are the annotations added to the PipelineRuns
+	// created for the Repository, the values can use the placeholders of the
+	// PipelineRuns templates.
+	PipelineRunAnnotations map[string]string `json:"pipelinerun_annotations,omitempty"`
+	// QueueWeight is the share of the global concurrency limit of the
+	// Repository compared to the others, 1 by default.
+	QueueWeight int `json:"queue_weight,omitempty"`
+	// QueuePreemption cancels the queued PipelineRuns of the Repository when
+	// a PipelineRun with a higher priority is queued.
+	QueuePreemption *QueuePreemption `json:"queue_preemption,omitempty"`
+	// SkipPipelineRunEnv are the names of the environment variables of the
+	// pipelinerun-env setting of the controller not injected in the
+	// PipelineRuns of the Repository, * skips all of them.
+	SkipPipelineRunEnv []string `json:"skip_pipelinerun_env,omitempty"`
+	// AutoRetry re-runs the PipelineRuns of the Repository failed by an
+	// infrastructure error, like an image pull failure or a node eviction.
+	AutoRetry *AutoRetry `json:"auto_retry,omitempty"`
+	// GitClone are the hints for the git-clone task of the PipelineRuns,
+	// passed as the git_clone_depth, git_clone_submodules and git_clone_lfs
+	// dynamic variables.
+	GitClone *GitClone `json:"git_clone,omitempty"`
+	// CloudCredentials exchanges a token of a service account of the
+	// namespace for short-lived cloud credentials, stored in a Secret
+	// living as long as the PipelineRun.
+	CloudCredentials *CloudCredentials `json:"cloud_credentials,omitempty"`
+	// ServiceAccounts are the service accounts the PipelineRuns of the pull
+	// requests and of the pushes run with, whatever they ask for.
+	ServiceAccounts *ServiceAccounts `json:"service_accounts,omitempty"`
+	// RebuildOnBaseUpdate re-runs some PipelineRuns of the open pull
+	// requests when a push updates the branch they target.
+	RebuildOnBaseUpdate *RebuildOnBaseUpdate `json:"rebuild_on_base_update,omitempty"`
+	// FreezeWindows are the periods, like the weekends or a release freeze,
+	// during which the pushes to some branches don't run the PipelineRuns
+	// right away.
+	FreezeWindows []FreezeWindow `json:"freeze_windows,omitempty"`
+	// IssueTracker links the PipelineRuns to the issues of a tracker, like
+	// Jira, whose keys are in the branch name, the commit title or the pull
+	// request title of the event.
+	IssueTracker *IssueTracker `json:"issue_tracker,omitempty"`
+	// FreezeOverride lifts the freeze windows, set by an admin with the
+	// /unfreeze and /freeze GitOps commands. It is not inherited from the
+	// defaults.
+	FreezeOverride bool `json:"freeze_override,omitempty"`
+	// Paused acknowledges the events of the Repository without running any
+	// PipelineRun, set by an admin with the /pause and /resume GitOps
+	// commands. It is not inherited from the defaults.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// QueuePreemption lets the high-priority PipelineRuns, like a hotfix on the
+// main branch, evict the lower-priority PipelineRuns waiting in the queue. The
+// running PipelineRuns are never preempted.
+type QueuePreemption struct {
+	// MinPriority is the priority from which a queued PipelineRun cancels
+	// the queued PipelineRuns with a lower priority, the priority of a
+	// PipelineRun is set by Priorities and is 0 by default.
+	MinPriority int `json:"min_priority"`
+	// Priorities are the priorities of the PipelineRuns by target branch and
+	// event, the highest of the matching ones is used. The priority
+	// annotation of a PipelineRun is only honoured for the push events when
+	// none matches.
+	Priorities []QueuePriority `json:"priorities,omitempty"`
+}
+
+// QueuePriority is the priority of the PipelineRuns of some target branches
+// and events.
+type QueuePriority struct {
+	// Priority is the priority of the matching PipelineRuns.
+	Priority int `json:"priority"`
+	// Branches are the globs of the target branches, all of them when
+	// empty.
+	Branches []string `json:"branches,omitempty"`
+	// EventTypes are the events, push or pull_request, all of them when
+	// empty.
+	EventTypes []string `json:"event_types,omitempty"`
+}
+
+// AutoRetry is the policy of the automatic retries of the PipelineRuns failed
+// by an infrastructure error.
+type AutoRetry struct {
+	// MaxRetries is the number of times a PipelineRun is re-run, 0 disables
+	// the retries.
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// GitClone are the fetch strategy hints of the git-clone task, the defaults
+// are the ones of the git-clone task of the Tekton catalog.
+type GitClone struct {
+	// Depth is the number of commits fetched, 0 fetches the full history.
+	// Defaults to 1.
+	Depth *int `json:"depth,omitempty"`
+	// Submodules fetches the submodules, true by default.
+	Submodules *bool `json:"submodules,omitempty"`
+	// LFS fetches the Git LFS objects, false by default.
+	LFS *bool `json:"lfs,omitempty"`
+}
+
+// CloudCredentials is the OAuth 2.0 token exchange (RFC 8693) of a token of a
+// service account of the namespace for a short-lived cloud access token, like
+// with the Google Cloud Security Token Service or a workload identity broker.
+type CloudCredentials struct {
+	// TokenURL is the token exchange endpoint, like
+	// https://sts.googleapis.com/v1/token.
+	TokenURL string `json:"token_url"`
+	// Audience is the audience of the service account token and of the
+	// exchange, like the workload identity provider of the cloud.
+	Audience string `json:"audience"`
+	// ServiceAccount is the service account of the namespace whose token is
+	// exchanged, the service account of the PipelineRun by default.
+	ServiceAccount string `json:"service_account,omitempty"`
+	// Scope is the space separated scopes requested to the exchange.
+	Scope string `json:"scope,omitempty"`
+	// ExpirationSeconds is the lifetime of the service account token, 3600 by
+	// default.
+	ExpirationSeconds int64 `json:"expiration_seconds,omitempty"`
+}
+
+// ServiceAccounts forces the service account of the PipelineRuns by event,
+// for example a restricted one for the untrusted pull requests and one
+// deploying for the pushes. They have to be allowed by the
+// service-account-allowlist setting of the admin.
+type ServiceAccounts struct {
+	// PullRequest is the service account of the PipelineRuns of the pull
+	// requests and of their GitOps commands.
+	PullRequest string `json:"pull_request,omitempty"`
+	// Push is the service account of the PipelineRuns of the pushes.
+	Push string `json:"push,omitempty"`
+}
+
+// RebuildOnBaseUpdate re-runs the PipelineRuns of the open pull requests
+// targeting a branch when it is updated by a push, to check they still pass
+// on top of it before they get merged.
+type RebuildOnBaseUpdate struct {
+	// PipelineRuns are the names of the PipelineRuns re-run on the pull
+	// requests, as in the /test GitOps command.
+	PipelineRuns []string `json:"pipelineruns"`
+	// Branches are the base branches whose updates re-run the pull
+	// requests, globs are supported. Defaults to the default branch.
+	Branches []string `json:"branches,omitempty"`
+	// MaxPullRequests is the number of the most recently updated pull
+	// requests re-run on each push, 10 by default.
+	MaxPullRequests int `json:"max_pull_requests,omitempty"`
+	// BatchSize is the number of pull requests re-run before pausing for
+	// the batch interval, 5 by default.
+	BatchSize int `json:"batch_size,omitempty"`
+	// BatchInterval is the pause between two batches of pull requests, like
+	// 2m. 30s by default.
+	BatchInterval string `json:"batch_interval,omitempty"`
+}
+
+// IssueTracker posts the links to the finished PipelineRuns on the issues
+// whose keys are found in their event, as remote links or comments.
+type IssueTracker struct {
+	// Type is the issue tracker, only jira is supported.
+	Type string `json:"type"`
+	// URL is the URL of the issue tracker, like https://example.atlassian.net.
+	URL string `json:"url"`
+	// User is the user of the API token, the email of the account on Jira
+	// Cloud. Without it the token is sent as a bearer token, like the
+	// personal access tokens of Jira Data Center.
+	User string `json:"user,omitempty"`
+	// Secret is the Secret of the namespace holding the API token.
+	Secret Secret `json:"secret"`
+	// KeyPattern is the regexp of the issue keys, the Jira keys like
+	// PROJ-123 by default.
+	KeyPattern string `json:"key_pattern,omitempty"`
+	// LinkType is how the PipelineRuns are linked to the issues:
+	// remote_link, the default, or comment.
+	LinkType string `json:"link_type,omitempty"`
+}
+
+// FreezeWindow is a recurring period during which the push events to some
+// branches are skipped or queued until its end.
+type FreezeWindow struct {
+	// Name of the window, shown in the status of the frozen commits.
+	Name string `json:"name"`
+	// Schedule is the cron expression of the starts of the window, like
+	// "0 18 * * 5" for every Friday at 18:00.
+	Schedule string `json:"schedule"`
+	// Duration of the window from each start, like 62h.
+	Duration string `json:"duration"`
+	// TimeZone of the schedule, UTC by default.
+	TimeZone string `json:"timezone,omitempty"`
+	// Branches frozen during the window, globs are supported. All the
+	// branches are frozen when empty.
+	Branches []string `json:"branches,omitempty"`
+	// Action on the push events during the window, skip to report a neutral
+	// status without running the PipelineRuns or queue to run them once the
+	// window ends. Defaults to skip.
+	Action string `json:"action,omitempty"`
+}
+
+// SkipCI is the policy for the skip CI directives, they are honored on all
+// branches by default.
+type SkipCI struct {
+	// Policy is allow to honor the directives or deny to ignore them.
+	Policy string `json:"policy,omitempty"`
+	// Branches restricts the directives to the events targeting these
+	// branches, globs are supported.
+	Branches []string `json:"branches,omitempty"`
+}
+
+// StatusTemplates overrides the text of the statuses and comments posted on
+// the git provider, they use the same syntax as the builtin templates and the
+// text of the builtin template is available as {{ .Default }}.
+type StatusTemplates struct {
+	Starting string `json:"starting,omitempty"`
+	Queued   string `json:"queued,omitempty"`
+	Finished string `json:"finished,omitempty"`
+}
+
+// Formatting overrides the formatting profile of the git provider, the
+// fields left unset keep the value of the profile.
+type Formatting struct {
+	// Profile is the builtin profile used instead of the one of the git
+	// provider: github, gitlab, gitea, bitbucket-cloud, bitbucket-server or
+	// plain.
+	Profile string `json:"profile,omitempty"`
+	// Tables shows the statuses of the tasks as a table instead of a list.
+	Tables *bool `json:"tables,omitempty"`
+	// Emoji shows the statuses of the tasks with an emoji.
+	Emoji *bool `json:"emoji,omitempty"`
+	// Collapsible folds the long sections of the final status, on the
+	// profiles rendering HTML.
+	Collapsible *bool `json:"collapsible,omitempty"`
 }
 
 func (s *Settings) Merge(newSettings *Settings) {
 	if newSettings.PipelineRunProvenance != "" && s.PipelineRunProvenance == "" {
 		s.PipelineRunProvenance = newSettings.PipelineRunProvenance
 	}
+	if newSettings.CheckRunNameTemplate != "" && s.CheckRunNameTemplate == "" {
+		s.CheckRunNameTemplate = newSettings.CheckRunNameTemplate
+	}
+	if newSettings.ApplicationName != "" && s.ApplicationName == "" {
+		s.ApplicationName = newSettings.ApplicationName
+	}
+	if newSettings.StatusTemplates != nil && s.StatusTemplates == nil {
+		s.StatusTemplates = newSettings.StatusTemplates
+	}
+	if newSettings.Formatting != nil && s.Formatting == nil {
+		s.Formatting = newSettings.Formatting
+	}
+	if newSettings.Locale != "" && s.Locale == "" {
+		s.Locale = newSettings.Locale
+	}
+	if newSettings.CommentStrategy != "" && s.CommentStrategy == "" {
+		s.CommentStrategy = newSettings.CommentStrategy
+	}
+	if newSettings.SkipCI != nil && s.SkipCI == nil {
+		s.SkipCI = newSettings.SkipCI
+	}
 	if newSettings.Policy != nil && s.Policy == nil {
 		s.Policy = newSettings.Policy
 	}
 	if newSettings.GithubAppTokenScopeRepos != nil && s.GithubAppTokenScopeRepos == nil {
 		s.GithubAppTokenScopeRepos = newSettings.GithubAppTokenScopeRepos
 	}
+	if newSettings.PipelineRunDirs != nil && s.PipelineRunDirs == nil {
+		s.PipelineRunDirs = newSettings.PipelineRunDirs
+	}
+	if newSettings.PipelineRunExtensions != nil && s.PipelineRunExtensions == nil {
+		s.PipelineRunExtensions = newSettings.PipelineRunExtensions
+	}
+	if newSettings.PipelineRunLabels != nil && s.PipelineRunLabels == nil {
+		s.PipelineRunLabels = newSettings.PipelineRunLabels
+	}
+	if newSettings.PipelineRunAnnotations != nil && s.PipelineRunAnnotations == nil {
+		s.PipelineRunAnnotations = newSettings.PipelineRunAnnotations
+	}
+	if newSettings.QueueWeight != 0 && s.QueueWeight == 0 {
+		s.QueueWeight = newSettings.QueueWeight
+	}
+	if newSettings.QueuePreemption != nil && s.QueuePreemption == nil {
+		s.QueuePreemption = newSettings.QueuePreemption
+	}
+	if newSettings.SkipPipelineRunEnv != nil && s.SkipPipelineRunEnv == nil {
+		s.SkipPipelineRunEnv = newSettings.SkipPipelineRunEnv
+	}
+	if newSettings.AutoRetry != nil && s.AutoRetry == nil {
+		s.AutoRetry = newSettings.AutoRetry
+	}
+	if newSettings.GitClone != nil && s.GitClone == nil {
+		s.GitClone = newSettings.GitClone
+	}
+	if newSettings.CloudCredentials != nil && s.CloudCredentials == nil {
+		s.CloudCredentials = newSettings.CloudCredentials
+	}
+	if newSettings.ServiceAccounts != nil && s.ServiceAccounts == nil {
+		s.ServiceAccounts = newSettings.ServiceAccounts
+	}
+	if newSettings.RebuildOnBaseUpdate != nil && s.RebuildOnBaseUpdate == nil {
+		s.RebuildOnBaseUpdate = newSettings.RebuildOnBaseUpdate
+	}
+	if newSettings.FreezeWindows != nil && s.FreezeWindows == nil {
+		s.FreezeWindows = newSettings.FreezeWindows
+	}
+	if newSettings.IssueTracker != nil && s.IssueTracker == nil {
+		s.IssueTracker = newSettings.IssueTracker
+	}
 }
 
 type Policy struct {
 	OkToTest    []string `json:"ok_to_test,omitempty"`
 	PullRequest []string `json:"pull_request,omitempty"`
+	// TrustedBots are the logins of the bots, like dependabot[bot], whose pull
+	// requests run the CI without an /ok-to-test
+	TrustedBots []string `json:"trusted_bots,omitempty"`
+	// AutoMergeTrustedBots merges the pull requests of the trusted bots
+	// updating a dependency to a patch or minor version once all their
+	// PipelineRuns have succeeded
+	AutoMergeTrustedBots bool `json:"auto_merge_trusted_bots,omitempty"`
+	// Admins are the teams allowed to /pause and /resume the Repository,
+	// beside the approvers of the OWNERS file
+	Admins []string `json:"admins,omitempty"`
+	// RequireSignedCommits are the branches, or globs, where the pushed
+	// commits must have a signature verified by the git provider to run the
+	// PipelineRuns
+	RequireSignedCommits []string `json:"require_signed_commits,omitempty"`
 }
 
 type Params struct {
@@ -143,6 +497,10 @@ type GitProvider struct {
 	Secret        *Secret `json:"secret,omitempty"`
 	WebhookSecret *Secret `json:"webhook_secret,omitempty"`
 	Type          string  `json:"type,omitempty"`
+	// AuthType is how the secret authenticates on Bitbucket Cloud, an
+	// app-password with the user (the default), a workspace-token or the
+	// oauth secret of the consumer whose key is the user.
+	AuthType string `json:"auth_type,omitempty"`
 }
 
 func (g *GitProvider) Merge(newGitProvider *GitProvider) {
@@ -159,6 +517,9 @@ func (g *GitProvider) Merge(newGitProvider *GitProvider) {
 	if newGitProvider.Type != "" && g.Type == "" {
 		g.Type = newGitProvider.Type
 	}
+	if newGitProvider.AuthType != "" && g.AuthType == "" {
+		g.AuthType = newGitProvider.AuthType
+	}
 	if newGitProvider.Secret != nil && g.Secret == nil {
 		g.Secret = newGitProvider.Secret
 	}
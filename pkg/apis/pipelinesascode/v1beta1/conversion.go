@@ -0,0 +1,241 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"knative.dev/pkg/apis"
+)
+
+var _ apis.Convertible = (*Repository)(nil)
+
+// ConvertTo implements apis.Convertible, converting the Repository to the
+// v1alpha1 storage version.
+func (r *Repository) ConvertTo(_ context.Context, to apis.Convertible) error {
+	sink, ok := to.(*v1alpha1.Repository)
+	if !ok {
+		return fmt.Errorf("unknown version, got: %T", to)
+	}
+	sink.ObjectMeta = r.ObjectMeta
+	sink.Status = r.Status
+	sink.RepositoryStatus = r.RepositoryStatus
+	sink.Spec = v1alpha1.RepositorySpec{
+		URL:       r.Spec.URL,
+		Incomings: r.Spec.Incomings,
+	}
+	if r.Spec.GitProvider != nil {
+		sink.Spec.GitProvider = &v1alpha1.GitProvider{
+			URL:           r.Spec.GitProvider.URL,
+			User:          r.Spec.GitProvider.User,
+			Secret:        r.Spec.GitProvider.Secret,
+			WebhookSecret: r.Spec.GitProvider.WebhookSecret,
+			Type:          r.Spec.GitProvider.Type,
+			AuthType:      r.Spec.GitProvider.AuthType,
+		}
+	}
+	if r.Spec.Params != nil {
+		params := make([]v1alpha1.Params, 0, len(*r.Spec.Params))
+		for _, param := range *r.Spec.Params {
+			params = append(params, v1alpha1.Params{
+				Name:      param.Name,
+				Value:     param.Value,
+				SecretRef: param.SecretRef,
+				Filter:    param.Filter,
+			})
+		}
+		sink.Spec.Params = &params
+	}
+
+	var settings v1alpha1.Settings
+	if s := r.Spec.Settings; s != nil {
+		settings = v1alpha1.Settings{
+			GithubAppTokenScopeRepos: s.GithubAppTokenScopeRepos,
+			PipelineRunProvenance:    s.PipelineRunProvenance,
+			CheckRunNameTemplate:     s.CheckRunNameTemplate,
+			ApplicationName:          s.ApplicationName,
+			StatusTemplates:          s.StatusTemplates,
+			Formatting:               s.Formatting,
+			Locale:                   s.Locale,
+			CommentStrategy:          s.CommentStrategy,
+			SkipCI:                   s.SkipCI,
+			PipelineRunDirs:          s.PipelineRunDirs,
+			PipelineRunExtensions:    s.PipelineRunExtensions,
+			PipelineRunLabels:        s.PipelineRunLabels,
+			PipelineRunAnnotations:   s.PipelineRunAnnotations,
+			SkipPipelineRunEnv:       s.SkipPipelineRunEnv,
+			GitClone:                 s.GitClone,
+			FreezeOverride:           s.FreezeOverride,
+			Paused:                   s.Paused,
+		}
+		if s.AutoRetry != nil {
+			settings.AutoRetry = &v1alpha1.AutoRetry{MaxRetries: s.AutoRetry.MaxRetries}
+		}
+		if s.CloudCredentials != nil {
+			cc := v1alpha1.CloudCredentials(*s.CloudCredentials)
+			settings.CloudCredentials = &cc
+		}
+		if s.ServiceAccounts != nil {
+			sa := v1alpha1.ServiceAccounts(*s.ServiceAccounts)
+			settings.ServiceAccounts = &sa
+		}
+		if s.RebuildOnBaseUpdate != nil {
+			rb := v1alpha1.RebuildOnBaseUpdate(*s.RebuildOnBaseUpdate)
+			settings.RebuildOnBaseUpdate = &rb
+		}
+		for _, w := range s.FreezeWindows {
+			settings.FreezeWindows = append(settings.FreezeWindows, v1alpha1.FreezeWindow(w))
+		}
+		if s.IssueTracker != nil {
+			it := v1alpha1.IssueTracker(*s.IssueTracker)
+			settings.IssueTracker = &it
+		}
+	}
+	if p := r.Spec.Policies; p != nil {
+		settings.Policy = &v1alpha1.Policy{
+			OkToTest:             p.OkToTest,
+			PullRequest:          p.PullRequest,
+			TrustedBots:          p.TrustedBots,
+			AutoMergeTrustedBots: p.AutoMergeTrustedBots,
+			Admins:               p.Admins,
+			RequireSignedCommits: p.RequireSignedCommits,
+		}
+	}
+	if c := r.Spec.Concurrency; c != nil {
+		sink.Spec.ConcurrencyLimit = c.Limit
+		settings.QueueWeight = c.QueueWeight
+		if c.Preemption != nil {
+			settings.QueuePreemption = &v1alpha1.QueuePreemption{MinPriority: c.Preemption.MinPriority}
+			for _, p := range c.Preemption.Priorities {
+				settings.QueuePreemption.Priorities = append(settings.QueuePreemption.Priorities,
+					v1alpha1.QueuePriority{Priority: p.Priority, Branches: p.Branches, EventTypes: p.EventTypes})
+			}
+		}
+	}
+	// the settings, the policies, the queue weight and the preemption all
+	// live in the settings of v1alpha1
+	if r.Spec.Settings != nil || r.Spec.Policies != nil || (r.Spec.Concurrency != nil && (r.Spec.Concurrency.QueueWeight != 0 || r.Spec.Concurrency.Preemption != nil)) {
+		sink.Spec.Settings = &settings
+	}
+	return nil
+}
+
+// ConvertFrom implements apis.Convertible, converting the Repository from the
+// v1alpha1 storage version.
+func (r *Repository) ConvertFrom(_ context.Context, from apis.Convertible) error {
+	source, ok := from.(*v1alpha1.Repository)
+	if !ok {
+		return fmt.Errorf("unknown version, got: %T", from)
+	}
+	r.ObjectMeta = source.ObjectMeta
+	r.Status = source.Status
+	r.RepositoryStatus = source.RepositoryStatus
+	r.Spec = RepositorySpec{
+		URL:       source.Spec.URL,
+		Incomings: source.Spec.Incomings,
+	}
+	if source.Spec.GitProvider != nil {
+		r.Spec.GitProvider = &GitProvider{
+			URL:           source.Spec.GitProvider.URL,
+			User:          source.Spec.GitProvider.User,
+			Secret:        source.Spec.GitProvider.Secret,
+			WebhookSecret: source.Spec.GitProvider.WebhookSecret,
+			Type:          source.Spec.GitProvider.Type,
+			AuthType:      source.Spec.GitProvider.AuthType,
+		}
+	}
+	if source.Spec.Params != nil {
+		params := make([]Params, 0, len(*source.Spec.Params))
+		for _, param := range *source.Spec.Params {
+			params = append(params, Params{
+				Name:      param.Name,
+				Value:     param.Value,
+				SecretRef: param.SecretRef,
+				Filter:    param.Filter,
+			})
+		}
+		r.Spec.Params = &params
+	}
+	if source.Spec.ConcurrencyLimit != nil {
+		r.Spec.Concurrency = &Concurrency{Limit: source.Spec.ConcurrencyLimit}
+	}
+
+	s := source.Spec.Settings
+	if s == nil {
+		return nil
+	}
+	if s.QueueWeight != 0 {
+		if r.Spec.Concurrency == nil {
+			r.Spec.Concurrency = &Concurrency{}
+		}
+		r.Spec.Concurrency.QueueWeight = s.QueueWeight
+	}
+	if s.QueuePreemption != nil {
+		if r.Spec.Concurrency == nil {
+			r.Spec.Concurrency = &Concurrency{}
+		}
+		r.Spec.Concurrency.Preemption = &Preemption{MinPriority: s.QueuePreemption.MinPriority}
+		for _, p := range s.QueuePreemption.Priorities {
+			r.Spec.Concurrency.Preemption.Priorities = append(r.Spec.Concurrency.Preemption.Priorities,
+				PreemptionPriority{Priority: p.Priority, Branches: p.Branches, EventTypes: p.EventTypes})
+		}
+	}
+	if p := s.Policy; p != nil {
+		r.Spec.Policies = &Policies{
+			OkToTest:             p.OkToTest,
+			PullRequest:          p.PullRequest,
+			TrustedBots:          p.TrustedBots,
+			AutoMergeTrustedBots: p.AutoMergeTrustedBots,
+			Admins:               p.Admins,
+			RequireSignedCommits: p.RequireSignedCommits,
+		}
+	}
+	settings := Settings{
+		GithubAppTokenScopeRepos: s.GithubAppTokenScopeRepos,
+		PipelineRunProvenance:    s.PipelineRunProvenance,
+		CheckRunNameTemplate:     s.CheckRunNameTemplate,
+		ApplicationName:          s.ApplicationName,
+		StatusTemplates:          s.StatusTemplates,
+		Formatting:               s.Formatting,
+		Locale:                   s.Locale,
+		CommentStrategy:          s.CommentStrategy,
+		SkipCI:                   s.SkipCI,
+		PipelineRunDirs:          s.PipelineRunDirs,
+		PipelineRunExtensions:    s.PipelineRunExtensions,
+		PipelineRunLabels:        s.PipelineRunLabels,
+		PipelineRunAnnotations:   s.PipelineRunAnnotations,
+		SkipPipelineRunEnv:       s.SkipPipelineRunEnv,
+		GitClone:                 s.GitClone,
+		FreezeOverride:           s.FreezeOverride,
+		Paused:                   s.Paused,
+	}
+	if s.AutoRetry != nil {
+		settings.AutoRetry = &AutoRetry{MaxRetries: s.AutoRetry.MaxRetries}
+	}
+	if s.CloudCredentials != nil {
+		cc := CloudCredentials(*s.CloudCredentials)
+		settings.CloudCredentials = &cc
+	}
+	if s.ServiceAccounts != nil {
+		sa := ServiceAccounts(*s.ServiceAccounts)
+		settings.ServiceAccounts = &sa
+	}
+	if s.RebuildOnBaseUpdate != nil {
+		rb := RebuildOnBaseUpdate(*s.RebuildOnBaseUpdate)
+		settings.RebuildOnBaseUpdate = &rb
+	}
+	for _, w := range s.FreezeWindows {
+		settings.FreezeWindows = append(settings.FreezeWindows, FreezeWindow(w))
+	}
+	if s.IssueTracker != nil {
+		it := IssueTracker(*s.IssueTracker)
+		settings.IssueTracker = &it
+	}
+	// the v1alpha1 settings holding only a policy, a queue weight or a
+	// preemption have no v1beta1 settings
+	if !reflect.DeepEqual(settings, Settings{}) {
+		r.Spec.Settings = &settings
+	}
+	return nil
+}
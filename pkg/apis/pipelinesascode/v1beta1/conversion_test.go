@@ -0,0 +1,135 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConversionRoundTrip(t *testing.T) {
+	limit := 2
+	depth, lfs := 0, true
+	tests := []struct {
+		name string
+		repo *v1alpha1.Repository
+	}{
+		{
+			name: "minimal",
+			repo: &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec:       v1alpha1.RepositorySpec{URL: "https://github.com/owner/repo"},
+			},
+		},
+		{
+			name: "only a policy and a queue weight in the settings",
+			repo: &v1alpha1.Repository{
+				Spec: v1alpha1.RepositorySpec{
+					URL: "https://github.com/owner/repo",
+					Settings: &v1alpha1.Settings{
+						Policy:      &v1alpha1.Policy{OkToTest: []string{"admins"}},
+						QueueWeight: 3,
+						QueuePreemption: &v1alpha1.QueuePreemption{MinPriority: 100, Priorities: []v1alpha1.QueuePriority{
+							{Priority: 100, Branches: []string{"main"}, EventTypes: []string{"push"}},
+						}},
+					},
+				},
+			},
+		},
+		{
+			name: "all the fields",
+			repo: &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec: v1alpha1.RepositorySpec{
+					ConcurrencyLimit: &limit,
+					URL:              "https://gitlab.com/owner/repo",
+					GitProvider: &v1alpha1.GitProvider{
+						URL:           "https://gitlab.com",
+						User:          "user",
+						Secret:        &v1alpha1.Secret{Name: "token", Key: "provider.token"},
+						WebhookSecret: &v1alpha1.Secret{Name: "token", Key: "webhook.secret"},
+						Type:          "gitlab",
+						AuthType:      "oauth",
+					},
+					Incomings: &[]v1alpha1.Incoming{{Type: "webhook-url", Secret: v1alpha1.Secret{Name: "incoming"}, Targets: []string{"main"}}},
+					Params:    &[]v1alpha1.Params{{Name: "param", SecretRef: &v1alpha1.Secret{Name: "secret", Key: "key"}, Filter: "pac.event_type == \"push\""}},
+					Settings: &v1alpha1.Settings{
+						GithubAppTokenScopeRepos: []string{"owner/other"},
+						PipelineRunProvenance:    "default_branch",
+						Policy: &v1alpha1.Policy{
+							OkToTest:             []string{"admins"},
+							PullRequest:          []string{"users"},
+							TrustedBots:          []string{"dependabot[bot]"},
+							AutoMergeTrustedBots: true,
+							Admins:               []string{"ops"},
+							RequireSignedCommits: []string{"main"},
+						},
+						CheckRunNameTemplate:   "{{ .PipelineRunName }}",
+						ApplicationName:        "CI",
+						StatusTemplates:        &v1alpha1.StatusTemplates{Starting: "starting"},
+						Formatting:             &v1alpha1.Formatting{Profile: "plain", Tables: &lfs},
+						Locale:                 "fr",
+						CommentStrategy:        "update",
+						SkipCI:                 &v1alpha1.SkipCI{Policy: "deny"},
+						PipelineRunDirs:        []string{".ci"},
+						PipelineRunExtensions:  []string{".yaml"},
+						PipelineRunLabels:      map[string]string{"team": "a"},
+						PipelineRunAnnotations: map[string]string{"owner": "b"},
+						AutoRetry:              &v1alpha1.AutoRetry{MaxRetries: 2},
+						GitClone:               &v1alpha1.GitClone{Depth: &depth, LFS: &lfs},
+						CloudCredentials: &v1alpha1.CloudCredentials{
+							TokenURL: "https://sts.googleapis.com/v1/token", Audience: "//iam.googleapis.com/pool",
+							ServiceAccount: "builder", Scope: "cloud-platform", ExpirationSeconds: 600,
+						},
+						ServiceAccounts: &v1alpha1.ServiceAccounts{PullRequest: "restricted", Push: "deploy"},
+						RebuildOnBaseUpdate: &v1alpha1.RebuildOnBaseUpdate{
+							PipelineRuns: []string{"e2e"}, Branches: []string{"main"}, MaxPullRequests: 20, BatchSize: 2, BatchInterval: "1m",
+						},
+						FreezeWindows: []v1alpha1.FreezeWindow{{
+							Name: "weekend", Schedule: "0 18 * * 5", Duration: "62h", TimeZone: "Europe/Paris",
+							Branches: []string{"main"}, Action: "queue",
+						}},
+						IssueTracker: &v1alpha1.IssueTracker{
+							Type: "jira", URL: "https://example.atlassian.net", User: "ci@example.com",
+							Secret: v1alpha1.Secret{Name: "jira", Key: "token"}, KeyPattern: "PROJ-[0-9]+", LinkType: "comment",
+						},
+						FreezeOverride: true,
+						QueueWeight:    2,
+						Paused:         true,
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			beta := &Repository{}
+			assert.NilError(t, beta.ConvertFrom(ctx, tt.repo))
+			got := &v1alpha1.Repository{}
+			assert.NilError(t, beta.ConvertTo(ctx, got))
+			assert.DeepEqual(t, got, tt.repo)
+		})
+	}
+}
+
+func TestConversionFields(t *testing.T) {
+	limit := 1
+	beta := &Repository{}
+	assert.NilError(t, beta.ConvertFrom(context.Background(), &v1alpha1.Repository{
+		Spec: v1alpha1.RepositorySpec{
+			ConcurrencyLimit: &limit,
+			Settings: &v1alpha1.Settings{
+				QueueWeight: 2, QueuePreemption: &v1alpha1.QueuePreemption{MinPriority: 10},
+				Policy: &v1alpha1.Policy{Admins: []string{"ops"}},
+			},
+		},
+	}))
+	assert.DeepEqual(t, beta.Spec.Concurrency, &Concurrency{Limit: &limit, QueueWeight: 2, Preemption: &Preemption{MinPriority: 10}})
+	assert.DeepEqual(t, beta.Spec.Policies, &Policies{Admins: []string{"ops"}})
+	assert.Assert(t, beta.Spec.Settings == nil)
+
+	assert.ErrorContains(t, beta.ConvertTo(context.Background(), &Repository{}), "unknown version")
+}
@@ -0,0 +1,175 @@
+package v1beta1
+
+import (
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Repository is the representation of a repo. It is served alongside the
+// v1alpha1 version, the storage version, and converted by the webhook.
+type Repository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositorySpec                 `json:"spec"`
+	Status []v1alpha1.RepositoryRunStatus `json:"pipelinerun_status,omitempty"`
+
+	// RepositoryStatus holds the conditions of the Repository.
+	RepositoryStatus v1alpha1.RepositoryStatus `json:"status,omitempty"`
+}
+
+// RepositorySpec is the spec of a repo.
+type RepositorySpec struct {
+	URL         string               `json:"url"`
+	GitProvider *GitProvider         `json:"gitProvider,omitempty"`
+	Incomings   *[]v1alpha1.Incoming `json:"incoming,omitempty"`
+	Params      *[]Params            `json:"params,omitempty"`
+	Concurrency *Concurrency         `json:"concurrency,omitempty"`
+	Settings    *Settings            `json:"settings,omitempty"`
+	Policies    *Policies            `json:"policies,omitempty"`
+}
+
+// Concurrency limits the PipelineRuns of the Repository running at once.
+type Concurrency struct {
+	// Limit is the maximum number of PipelineRuns running at once.
+	Limit *int `json:"limit,omitempty"`
+	// QueueWeight is the share of the global concurrency limit of the
+	// Repository compared to the others, 1 by default.
+	QueueWeight int `json:"queueWeight,omitempty"`
+	// Preemption cancels the queued PipelineRuns when a PipelineRun with a
+	// higher priority is queued.
+	Preemption *Preemption `json:"preemption,omitempty"`
+}
+
+// Preemption lets the high-priority PipelineRuns evict the lower-priority
+// PipelineRuns waiting in the queue.
+type Preemption struct {
+	MinPriority int                  `json:"minPriority"`
+	Priorities  []PreemptionPriority `json:"priorities,omitempty"`
+}
+
+// PreemptionPriority is the priority of the PipelineRuns of some target
+// branches and events.
+type PreemptionPriority struct {
+	Priority   int      `json:"priority"`
+	Branches   []string `json:"branches,omitempty"`
+	EventTypes []string `json:"eventTypes,omitempty"`
+}
+
+// Settings are the settings of the Repository, see the v1alpha1 settings
+// for their meaning.
+type Settings struct {
+	GithubAppTokenScopeRepos []string                  `json:"githubAppTokenScopeRepos,omitempty"`
+	PipelineRunProvenance    string                    `json:"pipelineRunProvenance,omitempty"`
+	CheckRunNameTemplate     string                    `json:"checkRunNameTemplate,omitempty"`
+	ApplicationName          string                    `json:"applicationName,omitempty"`
+	StatusTemplates          *v1alpha1.StatusTemplates `json:"statusTemplates,omitempty"`
+	Formatting               *v1alpha1.Formatting      `json:"formatting,omitempty"`
+	Locale                   string                    `json:"locale,omitempty"`
+	CommentStrategy          string                    `json:"commentStrategy,omitempty"`
+	SkipCI                   *v1alpha1.SkipCI          `json:"skipCI,omitempty"`
+	PipelineRunDirs          []string                  `json:"pipelineRunDirs,omitempty"`
+	PipelineRunExtensions    []string                  `json:"pipelineRunExtensions,omitempty"`
+	PipelineRunLabels        map[string]string         `json:"pipelineRunLabels,omitempty"`
+	PipelineRunAnnotations   map[string]string         `json:"pipelineRunAnnotations,omitempty"`
+	SkipPipelineRunEnv       []string                  `json:"skipPipelineRunEnv,omitempty"`
+	AutoRetry                *AutoRetry                `json:"autoRetry,omitempty"`
+	GitClone                 *v1alpha1.GitClone        `json:"gitClone,omitempty"`
+	CloudCredentials         *CloudCredentials         `json:"cloudCredentials,omitempty"`
+	ServiceAccounts          *ServiceAccounts          `json:"serviceAccounts,omitempty"`
+	RebuildOnBaseUpdate      *RebuildOnBaseUpdate      `json:"rebuildOnBaseUpdate,omitempty"`
+	FreezeWindows            []FreezeWindow            `json:"freezeWindows,omitempty"`
+	IssueTracker             *IssueTracker             `json:"issueTracker,omitempty"`
+	FreezeOverride           bool                      `json:"freezeOverride,omitempty"`
+	Paused                   bool                      `json:"paused,omitempty"`
+}
+
+// AutoRetry re-runs the PipelineRuns failed by an infrastructure error.
+type AutoRetry struct {
+	MaxRetries int `json:"maxRetries,omitempty"`
+}
+
+// CloudCredentials is the token exchange of a service account token for
+// short-lived cloud credentials.
+type CloudCredentials struct {
+	TokenURL          string `json:"tokenURL"`
+	Audience          string `json:"audience"`
+	ServiceAccount    string `json:"serviceAccount,omitempty"`
+	Scope             string `json:"scope,omitempty"`
+	ExpirationSeconds int64  `json:"expirationSeconds,omitempty"`
+}
+
+// ServiceAccounts forces the service account of the PipelineRuns by event.
+type ServiceAccounts struct {
+	PullRequest string `json:"pullRequest,omitempty"`
+	Push        string `json:"push,omitempty"`
+}
+
+// RebuildOnBaseUpdate re-runs the PipelineRuns of the open pull requests
+// when their base branch is updated.
+type RebuildOnBaseUpdate struct {
+	PipelineRuns    []string `json:"pipelineRuns"`
+	Branches        []string `json:"branches,omitempty"`
+	MaxPullRequests int      `json:"maxPullRequests,omitempty"`
+	BatchSize       int      `json:"batchSize,omitempty"`
+	BatchInterval   string   `json:"batchInterval,omitempty"`
+}
+
+// IssueTracker links the finished PipelineRuns to the issues of a tracker.
+type IssueTracker struct {
+	Type       string          `json:"type"`
+	URL        string          `json:"url"`
+	User       string          `json:"user,omitempty"`
+	Secret     v1alpha1.Secret `json:"secret"`
+	KeyPattern string          `json:"keyPattern,omitempty"`
+	LinkType   string          `json:"linkType,omitempty"`
+}
+
+// FreezeWindow is a recurring period during which the push events to some
+// branches are skipped or queued until its end.
+type FreezeWindow struct {
+	Name     string   `json:"name"`
+	Schedule string   `json:"schedule"`
+	Duration string   `json:"duration"`
+	TimeZone string   `json:"timeZone,omitempty"`
+	Branches []string `json:"branches,omitempty"`
+	Action   string   `json:"action,omitempty"`
+}
+
+// Policies restrict the actions on the Repository to some teams.
+type Policies struct {
+	OkToTest             []string `json:"okToTest,omitempty"`
+	PullRequest          []string `json:"pullRequest,omitempty"`
+	TrustedBots          []string `json:"trustedBots,omitempty"`
+	AutoMergeTrustedBots bool     `json:"autoMergeTrustedBots,omitempty"`
+	Admins               []string `json:"admins,omitempty"`
+	RequireSignedCommits []string `json:"requireSignedCommits,omitempty"`
+}
+
+type Params struct {
+	Name      string           `json:"name"`
+	Value     string           `json:"value,omitempty"`
+	SecretRef *v1alpha1.Secret `json:"secretRef,omitempty"`
+	Filter    string           `json:"filter,omitempty"`
+}
+
+type GitProvider struct {
+	URL           string           `json:"url,omitempty"`
+	User          string           `json:"user,omitempty"`
+	Secret        *v1alpha1.Secret `json:"secret,omitempty"`
+	WebhookSecret *v1alpha1.Secret `json:"webhookSecret,omitempty"`
+	Type          string           `json:"type,omitempty"`
+	AuthType      string           `json:"authType,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RepositoryList is the list of Repositories.
+type RepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Repository `json:"items"`
+}
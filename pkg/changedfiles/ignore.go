@@ -0,0 +1,134 @@
+package changedfiles
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// IgnoreFile is the file of the PipelineRun directories listing the patterns
+// of the files excluded from the path-change matching of all the
+// PipelineRuns, like the generated files or the vendored directories.
+const IgnoreFile = "pac-ignore"
+
+// ParseIgnoreFile returns the patterns of a pac-ignore file, in the syntax of
+// a .gitignore file: one pattern per line, the empty lines and the lines
+// starting with # are skipped. The invalid patterns are dropped and returned
+// in the error.
+func ParseIgnoreFile(content string) ([]string, error) {
+	patterns := []string{}
+	var errs []error
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := compileIgnorePattern(line); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, errors.Join(errs...)
+}
+
+type ignorePattern struct {
+	glob glob.Glob
+	// negate re-includes the files excluded by a previous pattern.
+	negate bool
+	// dirOnly only matches the directories, the pattern ends with a /.
+	dirOnly bool
+	// basename matches the pattern against the name of the file or of a
+	// directory at any depth, the pattern has no / but a trailing one.
+	basename bool
+}
+
+func compileIgnorePattern(pattern string) (ignorePattern, error) {
+	p := ignorePattern{}
+	raw := pattern
+	if strings.HasPrefix(pattern, "!") {
+		p.negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		p.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	// **/name matches the name at any depth, at the root too
+	if rest := strings.TrimPrefix(pattern, "**/"); !strings.Contains(rest, "/") {
+		pattern = rest
+	}
+	p.basename = !strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return p, fmt.Errorf("invalid pattern %q", raw)
+	}
+	g, err := glob.Compile(pattern, '/')
+	if err != nil {
+		return p, fmt.Errorf("invalid pattern %q: %w", raw, err)
+	}
+	p.glob = g
+	return p, nil
+}
+
+// match returns whether the pattern matches the file or one of its parent
+// directories, ignoring a directory ignores all its files.
+func (p ignorePattern) match(file string) bool {
+	dir := false
+	for candidate := file; candidate != "." && candidate != "/" && candidate != ""; candidate = path.Dir(candidate) {
+		if !p.dirOnly || dir {
+			name := candidate
+			if p.basename {
+				name = path.Base(candidate)
+			}
+			if p.glob.Match(name) {
+				return true
+			}
+		}
+		dir = true
+	}
+	return false
+}
+
+// Exclude returns the changed files without the ones matching the patterns
+// of a pac-ignore file, the last pattern matching a file wins like in a
+// .gitignore file.
+func (c ChangedFiles) Exclude(patterns []string) ChangedFiles {
+	if len(patterns) == 0 {
+		return c
+	}
+	compiled := make([]ignorePattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		if p, err := compileIgnorePattern(pattern); err == nil {
+			compiled = append(compiled, p)
+		}
+	}
+	exclude := func(files []string) []string {
+		if files == nil {
+			return nil
+		}
+		kept := []string{}
+		for _, file := range files {
+			ignored := false
+			for _, p := range compiled {
+				if p.negate == ignored && p.match(file) {
+					ignored = !p.negate
+				}
+			}
+			if !ignored {
+				kept = append(kept, file)
+			}
+		}
+		return kept
+	}
+	return ChangedFiles{
+		All:      exclude(c.All),
+		Added:    exclude(c.Added),
+		Deleted:  exclude(c.Deleted),
+		Modified: exclude(c.Modified),
+		Renamed:  exclude(c.Renamed),
+	}
+}
@@ -0,0 +1,67 @@
+package changedfiles
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseIgnoreFile(t *testing.T) {
+	patterns, err := ParseIgnoreFile("# generated files\n*.pb.go\n\n  vendor/  \n[\n/docs/**\n")
+	assert.ErrorContains(t, err, `invalid pattern "["`)
+	assert.DeepEqual(t, patterns, []string{"*.pb.go", "vendor/", "/docs/**"})
+}
+
+func TestExclude(t *testing.T) {
+	files := []string{
+		"main.go",
+		"api/api.pb.go",
+		"vendor/github.com/foo/foo.go",
+		"pkg/vendor/bar.go",
+		"pkg/vendor",
+		"docs/index.md",
+		"docs/guide/keep.md",
+		"pkg/docs/index.md",
+		"zz_generated.go",
+		"pkg/gen/zz_generated.go",
+	}
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{
+			name:     "no patterns",
+			patterns: nil,
+			want:     files,
+		},
+		{
+			name:     "basename at any depth",
+			patterns: []string{"*.pb.go", "zz_generated.*"},
+			want:     []string{"main.go", "vendor/github.com/foo/foo.go", "pkg/vendor/bar.go", "pkg/vendor", "docs/index.md", "docs/guide/keep.md", "pkg/docs/index.md"},
+		},
+		{
+			name:     "directories only",
+			patterns: []string{"vendor/"},
+			want:     []string{"main.go", "api/api.pb.go", "pkg/vendor", "docs/index.md", "docs/guide/keep.md", "pkg/docs/index.md", "zz_generated.go", "pkg/gen/zz_generated.go"},
+		},
+		{
+			name:     "anchored at the root",
+			patterns: []string{"/docs", "**/gen/"},
+			want:     []string{"main.go", "api/api.pb.go", "vendor/github.com/foo/foo.go", "pkg/vendor/bar.go", "pkg/vendor", "pkg/docs/index.md", "zz_generated.go"},
+		},
+		{
+			name:     "negation",
+			patterns: []string{"docs/**", "!docs/guide/*.md", "*.go", "!main.go"},
+			want:     []string{"main.go", "pkg/vendor", "docs/guide/keep.md", "pkg/docs/index.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChangedFiles{All: files, Modified: files}.Exclude(tt.patterns)
+			assert.DeepEqual(t, got.All, tt.want)
+			assert.DeepEqual(t, got.Modified, tt.want)
+			assert.Assert(t, got.Added == nil)
+		})
+	}
+}
@@ -16,6 +16,7 @@ type PacCliOpts struct {
 	UseRealTime   bool
 	AskOpts       survey.AskOpt
 	NoHeaders     bool
+	Output        string
 }
 
 func NewAskopts(opt *survey.AskOptions) error {
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// ValidateOutputFormat checks that the format passed to the --output flag is
+// one we know how to print, empty means the human readable output.
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case "", OutputJSON, OutputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output format %s, supported formats are: %s, %s", format, OutputJSON, OutputYAML)
+}
+
+// PrintObject prints obj as json or yaml to out so it can be consumed by
+// scripts.
+func PrintObject(out io.Writer, obj interface{}, format string) error {
+	var b []byte
+	var err error
+	switch format {
+	case OutputJSON:
+		b, err = json.MarshalIndent(obj, "", "  ")
+		b = append(b, '\n')
+	case OutputYAML:
+		b, err = yaml.Marshal(obj)
+	default:
+		return ValidateOutputFormat(format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(b)
+	return err
+}
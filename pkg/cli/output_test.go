@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPrintObject(t *testing.T) {
+	obj := struct {
+		Name string `json:"name"`
+	}{Name: "hello"}
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "json",
+			format: OutputJSON,
+			want:   "{\n  \"name\": \"hello\"\n}\n",
+		},
+		{
+			name:   "yaml",
+			format: OutputYAML,
+			want:   "name: hello\n",
+		},
+		{
+			name:    "unknown",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := PrintObject(out, obj, tt.format)
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, out.String(), tt.want)
+		})
+	}
+}
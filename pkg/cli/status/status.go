@@ -39,18 +39,25 @@ func RepositoryRunStatusRemoveSameSHA(rs []pacv1alpha1.RepositoryRunStatus, live
 func convertPrStatusToRepositoryStatus(ctx context.Context, cs *params.Run, pr tektonv1.PipelineRun, logurl string) pacv1alpha1.RepositoryRunStatus {
 	kinteract, _ := kubeinteraction.NewKubernetesInteraction(cs)
 	failurereasons := kstatus.CollectFailedTasksLogSnippet(ctx, cs, kinteract, &pr, defaultNumLinesOfLogsInContainersToGrabForErr)
-	prSHA := pr.GetAnnotations()[keys.SHA]
+	status := PipelineRunToRepositoryStatus(pr, logurl)
+	status.CollectedTaskInfos = &failurereasons
+	return status
+}
+
+// PipelineRunToRepositoryStatus converts a PipelineRun to a status of the
+// Repository without looking at the logs of its pods, used for the
+// PipelineRuns not on the cluster anymore.
+func PipelineRunToRepositoryStatus(pr tektonv1.PipelineRun, logurl string) pacv1alpha1.RepositoryRunStatus {
 	return pacv1alpha1.RepositoryRunStatus{
-		Status:             pr.Status.Status,
-		LogURL:             &logurl,
-		PipelineRunName:    pr.GetName(),
-		CollectedTaskInfos: &failurereasons,
-		StartTime:          pr.Status.StartTime,
-		SHA:                github.String(prSHA),
-		SHAURL:             github.String(pr.GetAnnotations()[keys.ShaURL]),
-		Title:              github.String(pr.GetAnnotations()[keys.ShaTitle]),
-		TargetBranch:       github.String(pr.GetAnnotations()[keys.Branch]),
-		EventType:          github.String(pr.GetAnnotations()[keys.EventType]),
+		Status:          pr.Status.Status,
+		LogURL:          &logurl,
+		PipelineRunName: pr.GetName(),
+		StartTime:       pr.Status.StartTime,
+		SHA:             github.String(pr.GetAnnotations()[keys.SHA]),
+		SHAURL:          github.String(pr.GetAnnotations()[keys.ShaURL]),
+		Title:           github.String(pr.GetAnnotations()[keys.ShaTitle]),
+		TargetBranch:    github.String(pr.GetAnnotations()[keys.Branch]),
+		EventType:       github.String(pr.GetAnnotations()[keys.EventType]),
 	}
 }
 
@@ -137,6 +137,7 @@ func (bb *bitbucketCloudConfig) create() error {
 		Active:   true,
 		Events: []string{
 			"repo:push",
+			"repo:commit_comment_created",
 			"pullrequest:created",
 			"pullrequest:updated",
 			"pullrequest:comment_created",
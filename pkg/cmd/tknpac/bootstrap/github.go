@@ -24,6 +24,7 @@ func generateManifest(opts *bootstrapOpts) ([]byte, error) {
 			"issue_comment",
 			"commit_comment",
 			triggertype.PullRequest.String(),
+			"pull_request_review",
 			"push",
 		},
 		DefaultPermissions: &github.InstallationPermissions{
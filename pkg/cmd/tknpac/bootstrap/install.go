@@ -47,6 +47,25 @@ func getLatestRelease(ctx context.Context, k8release string) (string, string, er
 		nil
 }
 
+// ReleaseURL returns the version and the url of the release yaml of a
+// version, the latest release when version is empty or the nightly build when
+// it is "nightly".
+func ReleaseURL(ctx context.Context, version string, openshift bool) (string, string, error) {
+	releaseYaml, k8Ext := openshiftReleaseYaml, ""
+	if !openshift {
+		releaseYaml, k8Ext = k8ReleaseYaml, ".k8s"
+	}
+	switch version {
+	case "":
+		return getLatestRelease(ctx, k8Ext)
+	case "nightly":
+		return version, fmt.Sprintf("%s/%s/%s/nightly/%s",
+			rawGHURL, pacGHRepoOwner, pacGHRepoName, releaseYaml), nil
+	}
+	return version, fmt.Sprintf("%s/%s/%s/release-%s/release%s.yaml",
+		rawGHURL, pacGHRepoOwner, pacGHRepoName, version, k8Ext), nil
+}
+
 // kubectlApply get kubectl binary and apply a yaml file.
 func kubectlApply(uri string) error {
 	path, err := exec.LookPath("kubectl")
@@ -137,27 +156,14 @@ func installGosmeeForwarder(opts *bootstrapOpts) error {
 }
 
 func installPac(ctx context.Context, run *params.Run, opts *bootstrapOpts) error {
-	var latestVersion, latestReleaseYaml, nightlyReleaseYaml string
-	k8Ext := ""
-
 	isOpenShift, _ := checkOpenshiftRoute(run)
-	if isOpenShift {
-		nightlyReleaseYaml = openshiftReleaseYaml
-	} else {
-		nightlyReleaseYaml = k8ReleaseYaml
-		k8Ext = ".k8s"
-	}
-
+	version := ""
 	if opts.installNightly {
-		latestReleaseYaml = fmt.Sprintf("%s/%s/%s/nightly/%s",
-			rawGHURL, pacGHRepoOwner, pacGHRepoName, nightlyReleaseYaml)
-		latestVersion = "nightly"
-	} else {
-		var err error
-		latestVersion, latestReleaseYaml, err = getLatestRelease(ctx, k8Ext)
-		if err != nil {
-			return err
-		}
+		version = "nightly"
+	}
+	latestVersion, latestReleaseYaml, err := ReleaseURL(ctx, version, isOpenShift)
+	if err != nil {
+		return err
 	}
 
 	if !opts.forceInstall {
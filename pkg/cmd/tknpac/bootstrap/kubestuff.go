@@ -42,6 +42,11 @@ func checkPipelinesInstalled(run *params.Run) (bool, error) {
 	return checkGroupInstalled(run, "tekton.dev")
 }
 
+// IsOpenShift returns true if the cluster serves the OpenShift routes.
+func IsOpenShift(run *params.Run) (bool, error) {
+	return checkOpenshiftRoute(run)
+}
+
 func checkOpenshiftRoute(run *params.Run) (bool, error) {
 	return checkGroupInstalled(run, openShiftRouteGroup)
 }
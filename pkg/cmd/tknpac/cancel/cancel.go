@@ -0,0 +1,178 @@
+package cancel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/completion"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/spf13/cobra"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const longhelp = `
+
+cancel - cancel the running and queued PipelineRuns of a Repository
+
+tkn pac cancel cancels the PipelineRuns of a Repository for a commit SHA, like
+the /cancel comment on a pull request, without needing to comment on it.
+
+The SHA of the commit is given with --sha, its short form is accepted, or all
+the PipelineRuns of the Repository are cancelled with --all. The cancellation
+can be restricted to a PipelineRun of the .tekton directory with
+--pipelinerun and to the queued PipelineRuns with --queued-only.
+
+eg:
+	tkn pac cancel my-repo --sha 1a2b3c4
+	tkn pac cancel my-repo --all --queued-only`
+
+const (
+	namespaceFlag   = "namespace"
+	shaFlag         = "sha"
+	pipelineRunFlag = "pipelinerun"
+	allFlag         = "all"
+	queuedOnlyFlag  = "queued-only"
+)
+
+type cancelOption struct {
+	cs          *params.Run
+	opts        *cli.PacCliOpts
+	ioStreams   *cli.IOStreams
+	repoName    string
+	sha         string
+	pipelineRun string
+	all         bool
+	queuedOnly  bool
+}
+
+func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
+	copts := &cancelOption{cs: run, ioStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "cancel",
+		Long:  longhelp,
+		Short: "Cancel the running and queued PipelineRuns of a Repository",
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+		ValidArgsFunction: completion.ParentCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			copts.opts = cli.NewCliOptions()
+			copts.opts.Namespace, err = cmd.Flags().GetString(namespaceFlag)
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				copts.repoName = args[0]
+			}
+			if copts.all == (copts.sha != "") {
+				return fmt.Errorf("either --%s or --%s is needed", shaFlag, allFlag)
+			}
+
+			ctx := context.Background()
+			if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+				return err
+			}
+			return cancel(ctx, copts)
+		},
+	}
+
+	cmd.Flags().StringP(
+		namespaceFlag, "n", "", "If present, the namespace scope for this CLI request")
+	_ = cmd.RegisterFlagCompletionFunc(namespaceFlag,
+		func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.BaseCompletion(namespaceFlag, args)
+		},
+	)
+
+	cmd.Flags().StringVarP(
+		&copts.sha, shaFlag, "s", "", "cancel the PipelineRuns of this commit SHA")
+
+	cmd.Flags().StringVarP(
+		&copts.pipelineRun, pipelineRunFlag, "p", "", "only cancel this PipelineRun of the .tekton directory")
+
+	cmd.Flags().BoolVarP(
+		&copts.all, allFlag, "A", false, "cancel the PipelineRuns of all the commits")
+
+	cmd.Flags().BoolVarP(
+		&copts.queuedOnly, queuedOnlyFlag, "q", false, "only cancel the queued PipelineRuns")
+
+	return cmd
+}
+
+// selectPipelineRuns returns the PipelineRuns of the Repository to cancel, the
+// ones done or already cancelled are skipped when cancelling.
+func selectPipelineRuns(ctx context.Context, co *cancelOption, repoName string) ([]tektonv1.PipelineRun, error) {
+	set := labels.Set{keys.Repository: formatting.CleanValueKubernetes(repoName)}
+	if co.pipelineRun != "" {
+		set[keys.OriginalPRName] = formatting.CleanValueKubernetes(co.pipelineRun)
+	}
+	if co.queuedOnly {
+		set[keys.State] = kubeinteraction.StateQueued
+	}
+	runs, err := co.cs.Clients.Tekton.TektonV1().PipelineRuns(co.cs.Info.Kube.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(set).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := []tektonv1.PipelineRun{}
+	for _, pr := range runs.Items {
+		// the short SHA of the commit is accepted
+		if co.sha != "" && !strings.HasPrefix(pr.GetAnnotations()[keys.SHA], co.sha) {
+			continue
+		}
+		ret = append(ret, pr)
+	}
+	return ret, nil
+}
+
+func cancel(ctx context.Context, co *cancelOption) error {
+	var repository *v1alpha1.Repository
+	var err error
+
+	if co.opts.Namespace != "" {
+		co.cs.Info.Kube.Namespace = co.opts.Namespace
+	}
+
+	if co.repoName != "" {
+		repository, err = co.cs.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(co.cs.Info.Kube.Namespace).Get(ctx,
+			co.repoName, metav1.GetOptions{})
+	} else {
+		repository, err = prompt.SelectRepo(ctx, co.cs, co.cs.Info.Kube.Namespace)
+	}
+	if err != nil {
+		return err
+	}
+
+	prs, err := selectPipelineRuns(ctx, co, repository.GetName())
+	if err != nil {
+		return err
+	}
+
+	cancelled, errs := action.CancelPipelineRuns(ctx, zap.NewNop().Sugar(), co.cs.Clients.Tekton, prs)
+	for _, pr := range cancelled {
+		fmt.Fprintf(co.ioStreams.Out, "PipelineRun %s has been cancelled\n", pr.GetName())
+	}
+	for _, err := range errs {
+		fmt.Fprintln(co.ioStreams.ErrOut, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("cannot cancel %d PipelineRuns of the repository %s", len(errs), repository.GetName())
+	}
+	if len(cancelled) == 0 {
+		fmt.Fprintf(co.ioStreams.Out, "no running or queued PipelineRun to cancel in the repository %s\n", repository.GetName())
+	}
+	return nil
+}
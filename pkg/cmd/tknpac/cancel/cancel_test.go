@@ -0,0 +1,134 @@
+package cancel
+
+import (
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	tcli "github.com/openshift-pipelines/pipelines-as-code/pkg/test/cli"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektontest "github.com/openshift-pipelines/pipelines-as-code/pkg/test/tekton"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func makePR(name, sha, prName, state string, spec tektonv1.PipelineRunSpecStatus) *tektonv1.PipelineRun {
+	return &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Labels: map[string]string{
+				keys.Repository:     "test",
+				keys.SHA:            sha,
+				keys.OriginalPRName: prName,
+				keys.State:          state,
+			},
+			Annotations: map[string]string{keys.SHA: sha},
+		},
+		Spec: tektonv1.PipelineRunSpec{Status: spec},
+	}
+}
+
+func TestCancel(t *testing.T) {
+	tests := []struct {
+		name          string
+		sha           string
+		pipelineRun   string
+		all           bool
+		queuedOnly    bool
+		wantCancelled []string
+	}{
+		{
+			name:          "running and queued of a short sha",
+			sha:           "abcdef1",
+			wantCancelled: []string{"build-1", "build-2", "test-1"},
+		},
+		{
+			name:          "queued only",
+			sha:           "abcdef1",
+			queuedOnly:    true,
+			wantCancelled: []string{"build-2"},
+		},
+		{
+			name:          "a pipelinerun",
+			sha:           "abcdef1",
+			pipelineRun:   "test",
+			wantCancelled: []string{"test-1"},
+		},
+		{
+			name:          "all the commits",
+			all:           true,
+			wantCancelled: []string{"build-1", "build-2", "test-1", "other-1"},
+		},
+		{
+			name: "nothing to cancel",
+			sha:  "0000000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sha := "abcdef1234567890"
+			done := tektontest.MakePRCompletion(clockwork.NewFakeClock(), "build-0", "ns", "", nil, map[string]string{
+				keys.Repository: "test", keys.SHA: sha, keys.OriginalPRName: "build",
+			}, 30)
+			tdata := testclient.Data{
+				PipelineRuns: []*tektonv1.PipelineRun{
+					done,
+					makePR("build-1", sha, "build", kubeinteraction.StateStarted, ""),
+					makePR("build-2", sha, "build", kubeinteraction.StateQueued, tektonv1.PipelineRunSpecStatusPending),
+					makePR("test-1", sha, "test", kubeinteraction.StateStarted, ""),
+					makePR("cancelled-1", sha, "test", kubeinteraction.StateStarted, tektonv1.PipelineRunSpecStatusCancelledRunFinally),
+					makePR("other-1", "1234567890abcdef", "build", kubeinteraction.StateStarted, ""),
+				},
+				Repositories: []*v1alpha1.Repository{
+					{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "ns"}},
+				},
+			}
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, tdata)
+			cs := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: stdata.PipelineAsCode,
+					Tekton:         stdata.Pipeline,
+				},
+				Info: info.Info{Kube: &info.KubeOpts{Namespace: "ns"}},
+			}
+			io, out := tcli.NewIOStream()
+			err := cancel(ctx, &cancelOption{
+				cs:          cs,
+				opts:        &cli.PacCliOpts{},
+				ioStreams:   io,
+				repoName:    "test",
+				sha:         tt.sha,
+				pipelineRun: tt.pipelineRun,
+				all:         tt.all,
+				queuedOnly:  tt.queuedOnly,
+			})
+			assert.NilError(t, err)
+
+			runs, err := stdata.Pipeline.TektonV1().PipelineRuns("ns").List(ctx, metav1.ListOptions{})
+			assert.NilError(t, err)
+			cancelled := map[string]bool{}
+			for _, pr := range runs.Items {
+				if pr.GetName() != "cancelled-1" && pr.Spec.Status == tektonv1.PipelineRunSpecStatusCancelledRunFinally {
+					cancelled[pr.GetName()] = true
+				}
+			}
+			assert.Equal(t, len(cancelled), len(tt.wantCancelled), out.String())
+			for _, name := range tt.wantCancelled {
+				assert.Assert(t, cancelled[name], "%s has not been cancelled", name)
+			}
+			if len(tt.wantCancelled) == 0 {
+				assert.Equal(t, out.String(), "no running or queued PipelineRun to cancel in the repository test\n")
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package cel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketserver"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const longHelp = `
+cel - evaluate an on-cel-expression locally
+
+tkn pac cel evaluates the expression of a pipelinesascode.tekton.dev/on-cel-expression
+annotation and prints its result or the evaluation error.
+
+The event is either parsed from a recorded payload file, with the headers of
+the webhook to detect the git provider, or synthesized from the flags. The flags
+override the fields parsed from the payload. The changed files are never
+fetched from the git provider, they are given with the --file flags.
+
+eg:
+	tkn pac cel -e 'event == "pull_request" && target_branch == "main"' \
+		--event-type pull_request --target-branch main
+
+	tkn pac cel -e '"docs/*.md".pathChanged()' --file docs/README.md
+
+	tkn pac cel -e 'body.action == "opened"' --payload payload.json \
+		-H 'X-GitHub-Event: pull_request'
+	`
+
+type celOpts struct {
+	ioStreams    *cli.IOStreams
+	expression   string
+	payloadFile  string
+	headers      []string
+	eventType    string
+	targetBranch string
+	sourceBranch string
+	title        string
+	files        []string
+}
+
+func Command(ioStreams *cli.IOStreams) *cobra.Command {
+	opts := &celOpts{ioStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "cel",
+		Short: "Evaluate an on-cel-expression against a payload or an event",
+		Long:  longHelp,
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return evaluate(context.Background(), opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.expression, "expression", "e", "", "The CEL expression to evaluate")
+	cmd.Flags().StringVarP(&opts.payloadFile, "payload", "p", "", "A file with the JSON payload of a webhook event")
+	cmd.Flags().StringArrayVarP(&opts.headers, "header", "H", []string{}, "A header of the webhook event, as 'Name: value', to detect the git provider of the payload")
+	cmd.Flags().StringVar(&opts.eventType, "event-type", "", "The event type: pull_request or push")
+	cmd.Flags().StringVar(&opts.targetBranch, "target-branch", "", "The target branch of the event")
+	cmd.Flags().StringVar(&opts.sourceBranch, "source-branch", "", "The source branch of the event")
+	cmd.Flags().StringVar(&opts.title, "title", "", "The title of the pull request or of the commit")
+	cmd.Flags().StringArrayVarP(&opts.files, "file", "f", []string{}, "A file changed by the event")
+	_ = cmd.MarkFlagRequired("expression")
+	return cmd
+}
+
+func evaluate(ctx context.Context, opts *celOpts) error {
+	event, err := makeEvent(ctx, opts)
+	if err != nil {
+		return err
+	}
+	files := changedfiles.ChangedFiles{All: opts.files, Modified: opts.files}
+	out, err := matcher.CelEvaluateWithFiles(opts.expression, event, files)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.ioStreams.Out, "%v\n", out.Value())
+	return nil
+}
+
+// makeEvent parses the payload file, when there is one, and overrides the
+// fields of the event with the flags.
+func makeEvent(ctx context.Context, opts *celOpts) (*info.Event, error) {
+	event := info.NewEvent()
+	event.Event = map[string]any{}
+	header := http.Header{}
+	for _, h := range opts.headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q, needs to be of format 'Name: value'", h)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	if opts.payloadFile != "" {
+		payload, err := os.ReadFile(opts.payloadFile)
+		if err != nil {
+			return nil, err
+		}
+		if event, err = parsePayload(ctx, header, payload); err != nil {
+			return nil, fmt.Errorf("cannot parse the payload of %s: %w", opts.payloadFile, err)
+		}
+	}
+	event.Request = &info.Request{Header: header}
+
+	if opts.eventType != "" {
+		event.TriggerTarget = triggertype.StringToType(opts.eventType)
+		if event.TriggerTarget == "" {
+			return nil, fmt.Errorf("unknown event type %q", opts.eventType)
+		}
+	}
+	if opts.targetBranch != "" {
+		event.BaseBranch = opts.targetBranch
+	}
+	if opts.sourceBranch != "" {
+		event.HeadBranch = opts.sourceBranch
+	}
+	if opts.title != "" {
+		event.PullRequestTitle = opts.title
+		event.SHATitle = opts.title
+	}
+	return event, nil
+}
+
+// parsePayload parses the payload with the git provider detected from the
+// headers, like the controller does.
+func parsePayload(ctx context.Context, header http.Header, payload []byte) (*info.Event, error) {
+	var body map[string]any
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid event body format: %w", err)
+	}
+	// the GitHub App token is not needed to evaluate an expression
+	if _, ok := body["installation"]; ok && header.Get("X-GitHub-Event") != "" {
+		delete(body, "installation")
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	run := params.New()
+	logger := zap.NewNop().Sugar()
+	req := &http.Request{Header: header}
+	for _, vcx := range []provider.Interface{github.New(), &gitea.Provider{}, &bitbucketserver.Provider{}, &gitlab.Provider{}, &bitbucketcloud.Provider{}} {
+		vcx.SetPacInfo(&info.PacOpts{})
+		detected, processReq, _, reason, err := vcx.Detect(req, string(payload), logger)
+		if !detected {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !processReq {
+			return nil, fmt.Errorf("the event is skipped by Pipelines-as-Code: %s", reason)
+		}
+		return vcx.ParsePayload(ctx, run, req, string(payload))
+	}
+	return nil, fmt.Errorf("no supported Git provider has been detected, add the headers of the webhook with --header")
+}
@@ -0,0 +1,136 @@
+package cel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"gotest.tools/v3/assert"
+)
+
+const pullRequestPayload = `{
+	"action": "opened",
+	"installation": {"id": 1234},
+	"pull_request": {
+		"title": "Add a feature",
+		"head": {"ref": "feature", "sha": "abc", "repo": {"html_url": "https://github.com/owner/repo"}},
+		"base": {"ref": "main", "repo": {"html_url": "https://github.com/owner/repo", "name": "repo", "owner": {"login": "owner"}}},
+		"user": {"login": "user"},
+		"number": 1
+	},
+	"repository": {"name": "repo", "owner": {"login": "owner"}, "html_url": "https://github.com/owner/repo"}
+}`
+
+func TestEvaluate(t *testing.T) {
+	payloadFile := filepath.Join(t.TempDir(), "payload.json")
+	assert.NilError(t, os.WriteFile(payloadFile, []byte(pullRequestPayload), 0o600))
+
+	tests := []struct {
+		name    string
+		opts    celOpts
+		want    string
+		wantErr string
+	}{
+		{
+			name: "synthesized event",
+			opts: celOpts{
+				expression:   `event == "pull_request" && target_branch == "main" && source_branch == "feature"`,
+				eventType:    "pull_request",
+				targetBranch: "main",
+				sourceBranch: "feature",
+			},
+			want: "true\n",
+		},
+		{
+			name: "changed files",
+			opts: celOpts{
+				expression: `"docs/*.md".pathChanged() && files.all.size() == 2`,
+				files:      []string{"docs/README.md", "main.go"},
+			},
+			want: "true\n",
+		},
+		{
+			name: "files not changed",
+			opts: celOpts{
+				expression: `"docs/*.md".pathChanged()`,
+				files:      []string{"main.go"},
+			},
+			want: "false\n",
+		},
+		{
+			name: "github payload",
+			opts: celOpts{
+				expression:  `event == "pull_request" && target_branch == "main" && event_title == "Add a feature" && body.action == "opened"`,
+				payloadFile: payloadFile,
+				headers:     []string{"X-GitHub-Event: pull_request"},
+			},
+			want: "true\n",
+		},
+		{
+			name: "flags override the payload",
+			opts: celOpts{
+				expression:   `target_branch`,
+				payloadFile:  payloadFile,
+				headers:      []string{"X-GitHub-Event: pull_request"},
+				targetBranch: "release",
+			},
+			want: "release\n",
+		},
+		{
+			name: "headers of the payload",
+			opts: celOpts{
+				expression: `headers["x-github-event"]`,
+				headers:    []string{"X-GitHub-Event: push"},
+			},
+			want: "push\n",
+		},
+		{
+			name: "no provider detected",
+			opts: celOpts{
+				expression:  `event == "push"`,
+				payloadFile: payloadFile,
+			},
+			wantErr: "no supported Git provider has been detected",
+		},
+		{
+			name: "invalid expression",
+			opts: celOpts{
+				expression: `event ==`,
+			},
+			wantErr: "failed to parse expression",
+		},
+		{
+			name: "unknown event type",
+			opts: celOpts{
+				expression: `event == "push"`,
+				eventType:  "nope",
+			},
+			wantErr: `unknown event type "nope"`,
+		},
+		{
+			name: "invalid header",
+			opts: celOpts{
+				expression: `event == "push"`,
+				headers:    []string{"X-GitHub-Event"},
+			},
+			wantErr: "invalid header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			tt.opts.ioStreams = &cli.IOStreams{In: io.NopCloser(&bytes.Buffer{}), Out: out, ErrOut: &bytes.Buffer{}}
+			err := evaluate(context.Background(), &tt.opts)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, out.String(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package checktoken
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/completion"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
+	ghprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const namespaceFlag = "namespace"
+
+const longHelp = `
+check-token - check the git provider token of a Repository
+
+tkn pac check-token detects the type of the token referenced by the
+git_provider section of a Repository and checks it has the scopes, or the
+permissions for a fine-grained token, needed by Pipelines-as-Code.
+
+Only GitHub tokens used in webhook mode are supported.
+
+eg:
+	tkn pac check-token <repository-name> -n namespace
+	`
+
+type checkOpts struct {
+	run       *params.Run
+	ioStreams *cli.IOStreams
+	namespace string
+	repoName  string
+	// client is the GitHub client to use, it is created from the Repository
+	// token when not set.
+	client *github.Client
+}
+
+func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-token",
+		Short: "Check the git provider token of a Repository has the needed permissions",
+		Long:  longHelp,
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.BaseCompletion("repositories", args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			opts := &checkOpts{run: run, ioStreams: ioStreams}
+			opts.namespace, err = cmd.Flags().GetString(namespaceFlag)
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				opts.repoName = args[0]
+			}
+
+			ctx := context.Background()
+			if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+				return err
+			}
+			return checkToken(ctx, opts)
+		},
+	}
+
+	cmd.Flags().StringP(
+		namespaceFlag, "n", "", "If present, the namespace scope for this CLI request")
+	_ = cmd.RegisterFlagCompletionFunc(namespaceFlag,
+		func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.BaseCompletion(namespaceFlag, args)
+		},
+	)
+	return cmd
+}
+
+func checkToken(ctx context.Context, opts *checkOpts) error {
+	var (
+		err  error
+		repo *v1alpha1.Repository
+	)
+	ns := opts.namespace
+	if ns == "" {
+		ns = opts.run.Info.Kube.Namespace
+	}
+	if opts.repoName != "" {
+		repo, err = opts.run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(ns).Get(ctx, opts.repoName, metav1.GetOptions{})
+	} else {
+		repo, err = prompt.SelectRepo(ctx, opts.run, ns)
+	}
+	if err != nil {
+		return err
+	}
+
+	if repo.Spec.GitProvider == nil || repo.Spec.GitProvider.Secret == nil {
+		return fmt.Errorf("repository %s has no git_provider secret, the token is only used in webhook mode", repo.GetName())
+	}
+	if repo.Spec.GitProvider.Type != "" && repo.Spec.GitProvider.Type != "github" {
+		return fmt.Errorf("checking the token of a %s repository is not supported, only GitHub is", repo.Spec.GitProvider.Type)
+	}
+	owner, repoName, err := formatting.GetRepoOwnerSplitted(repo.Spec.URL)
+	if err != nil {
+		return err
+	}
+
+	key := repo.Spec.GitProvider.Secret.Key
+	if key == "" {
+		key = pipelineascode.DefaultGitProviderSecretKey
+	}
+	secret, err := opts.run.Clients.Kube.CoreV1().Secrets(repo.GetNamespace()).Get(ctx, repo.Spec.GitProvider.Secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	token := strings.TrimSpace(string(secret.Data[key]))
+	if token == "" {
+		return fmt.Errorf("secret %s has no token in the %s key", repo.Spec.GitProvider.Secret.Name, key)
+	}
+
+	client := opts.client
+	if client == nil {
+		gh := ghprovider.New()
+		event := info.NewEvent()
+		event.Provider.URL = repo.Spec.GitProvider.URL
+		event.Provider.Token = token
+		if err := gh.SetClient(ctx, opts.run, event, repo, nil); err != nil {
+			return err
+		}
+		client = gh.Client
+	}
+
+	report, err := ghprovider.CheckToken(ctx, client, token, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("cannot check the token: %w", err)
+	}
+	return printReport(opts.ioStreams, repo, report)
+}
+
+func printReport(ioStreams *cli.IOStreams, repo *v1alpha1.Repository, report *ghprovider.TokenReport) error {
+	cs := ioStreams.ColorScheme()
+	fmt.Fprintf(ioStreams.Out, "%s Repository %s/%s uses a %s\n", cs.InfoIcon(), repo.GetNamespace(), repo.GetName(), report.Type)
+	if len(report.Scopes) > 0 {
+		fmt.Fprintf(ioStreams.Out, "%s Token scopes: %s\n", cs.InfoIcon(), strings.Join(report.Scopes, ", "))
+	}
+	if len(report.Unverified) > 0 {
+		fmt.Fprintf(ioStreams.Out, "%s Cannot be checked without writing to the repository, make sure the token has: %s\n",
+			cs.WarningIcon(), strings.Join(report.Unverified, ", "))
+	}
+	if len(report.Missing) > 0 {
+		fmt.Fprintf(ioStreams.Out, "%s The token is missing: %s\n", cs.FailureIcon(), strings.Join(report.Missing, ", "))
+		return fmt.Errorf("the token of repository %s is missing %d scopes or permissions", repo.GetName(), len(report.Missing))
+	}
+	fmt.Fprintf(ioStreams.Out, "%s The token has the needed scopes and permissions\n", cs.SuccessIcon())
+	return nil
+}
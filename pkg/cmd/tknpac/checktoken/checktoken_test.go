@@ -0,0 +1,104 @@
+package checktoken
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestCheckToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		gitProvider *v1alpha1.GitProvider
+		token       string
+		scopes      string
+		wantErr     string
+		wantOut     string
+	}{
+		{
+			name:        "token with the needed scopes",
+			gitProvider: &v1alpha1.GitProvider{Secret: &v1alpha1.Secret{Name: "secret"}},
+			token:       "ghp_token",
+			scopes:      "repo",
+			wantOut: "ℹ Repository ns/repo uses a classic personal access token\n" +
+				"ℹ Token scopes: repo\n" +
+				"✓ The token has the needed scopes and permissions\n",
+		},
+		{
+			name:        "token missing scopes",
+			gitProvider: &v1alpha1.GitProvider{Secret: &v1alpha1.Secret{Name: "secret"}},
+			token:       "ghp_token",
+			scopes:      "read:org",
+			wantErr:     "the token of repository repo is missing 1 scopes or permissions",
+			wantOut: "ℹ Repository ns/repo uses a classic personal access token\n" +
+				"ℹ Token scopes: read:org\n" +
+				"X The token is missing: repo\n",
+		},
+		{
+			name:    "no git provider",
+			wantErr: "repository repo has no git_provider secret, the token is only used in webhook mode",
+		},
+		{
+			name:        "not github",
+			gitProvider: &v1alpha1.GitProvider{Type: "gitlab", Secret: &v1alpha1.Secret{Name: "secret"}},
+			wantErr:     "checking the token of a gitlab repository is not supported, only GitHub is",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			mux.HandleFunc("/user", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-OAuth-Scopes", tt.scopes)
+				fmt.Fprint(w, `{"login": "user"}`)
+			})
+
+			tdata := testclient.Data{
+				Repositories: []*v1alpha1.Repository{{
+					ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+					Spec: v1alpha1.RepositorySpec{
+						URL:         "https://github.com/owner/repo",
+						GitProvider: tt.gitProvider,
+					},
+				}},
+				Secret: []*corev1.Secret{{
+					ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+					Data:       map[string][]byte{"provider.token": []byte(tt.token)},
+				}},
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, tdata)
+			run := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: stdata.PipelineAsCode,
+					Kube:           stdata.Kube,
+				},
+				Info: info.Info{Kube: &info.KubeOpts{Namespace: "ns"}},
+			}
+			out := &bytes.Buffer{}
+			ioStreams := &cli.IOStreams{In: io.NopCloser(&bytes.Buffer{}), Out: out, ErrOut: &bytes.Buffer{}}
+
+			err := checkToken(ctx, &checkOpts{run: run, ioStreams: ioStreams, repoName: "repo", client: fakeclient})
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			assert.Equal(t, out.String(), tt.wantOut)
+		})
+	}
+}
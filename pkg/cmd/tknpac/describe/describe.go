@@ -3,9 +3,12 @@ package describe
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 	"text/tabwriter"
 	"text/template"
 
@@ -21,9 +24,12 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/sort"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/tektonresults"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -33,6 +39,9 @@ var (
 	targetPRFlag      = "target-pipelinerun"
 	useRealTimeFlag   = "use-realtime"
 	showEventflag     = "show-events"
+	outputFlag        = "output"
+	exitStatusFlag    = "exit-status"
+	eventPayloadFlag  = "event"
 	creationTimestamp = "{.metadata.creationTimestamp}"
 	maxEventLimit     = 50
 )
@@ -62,6 +71,30 @@ type describeOpts struct {
 	cli.PacCliOpts
 	TargetPipelineRun string
 	ShowEvents        bool
+	ExitStatus        bool
+	ShowEventPayload  bool
+	ResultsURL        string
+	ResultsToken      string
+}
+
+// describeOutput is the machine readable output of the describe command.
+type describeOutput struct {
+	Name               string                         `json:"name"`
+	Namespace          string                         `json:"namespace"`
+	URL                string                         `json:"url"`
+	ConcurrencyLimit   *int                           `json:"concurrency_limit,omitempty"`
+	QueuedPipelineRuns []string                       `json:"queued_pipelineruns,omitempty"`
+	Statuses           []v1alpha1.RepositoryRunStatus `json:"statuses"`
+	Events             []corev1.Event                 `json:"events,omitempty"`
+}
+
+// eventPayloadOutput is the machine readable output of the event of a
+// PipelineRun.
+type eventPayloadOutput struct {
+	PipelineRun string          `json:"pipelinerun"`
+	Event       json.RawMessage `json:"event,omitempty"`
+	Headers     json.RawMessage `json:"headers,omitempty"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
 }
 
 func newDescribeOptions(_ *cobra.Command) *describeOpts {
@@ -107,6 +140,24 @@ func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 				return err
 			}
 
+			opts.Output, err = cmd.Flags().GetString(outputFlag)
+			if err != nil {
+				return err
+			}
+			if err := cli.ValidateOutputFormat(opts.Output); err != nil {
+				return err
+			}
+
+			opts.ExitStatus, err = cmd.Flags().GetBool(exitStatusFlag)
+			if err != nil {
+				return err
+			}
+
+			opts.ShowEventPayload, err = cmd.Flags().GetBool(eventPayloadFlag)
+			if err != nil {
+				return err
+			}
+
 			if len(args) > 0 {
 				repoName = args[0]
 			}
@@ -122,6 +173,9 @@ func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 			if os.Getenv("TEKTON_DASHBOARD_URL") != "" {
 				run.Clients.SetConsoleUI(&consoleui.TektonDashboard{BaseURL: os.Getenv("TEKTON_DASHBOARD_URL")})
 			}
+			// same for Tekton Results, used to get the PipelineRuns already pruned from the cluster
+			opts.ResultsURL = os.Getenv("TEKTON_RESULTS_URL")
+			opts.ResultsToken = os.Getenv("TEKTON_RESULTS_TOKEN")
 
 			return describe(ctx, run, clock, opts, ioStreams, repoName)
 		},
@@ -147,6 +201,12 @@ func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 		showEventflag, "", false, "show kubernetes events associated with this repository, useful if you have an error that cannot be reported on the git provider interface")
 	cmd.PersistentFlags().BoolVarP(&useRealTime, useRealTimeFlag, "", false,
 		"display the time as RFC3339 instead of a relative time")
+	cmd.Flags().StringP(
+		outputFlag, "o", "", "output format, one of: json, yaml")
+	cmd.Flags().BoolP(
+		exitStatusFlag, "", false, "exit with a non zero status if the last PipelineRun has failed")
+	cmd.Flags().BoolP(
+		eventPayloadFlag, "", false, "show the event stored for the last or the target PipelineRun, needs the event-payload-ttl setting")
 	return cmd
 }
 
@@ -241,11 +301,42 @@ func describe(ctx context.Context, cs *params.Run, clock clockwork.Clock, opts *
 
 	if opts.TargetPipelineRun != "" {
 		statuses = filterOnlyToPipelineRun(opts, statuses)
+		if len(statuses) == 0 && opts.ResultsURL != "" {
+			statuses, err = getArchivedPipelineRun(ctx, cs, opts, repository)
+			if err != nil {
+				return err
+			}
+		}
 		if len(statuses) == 0 {
 			return fmt.Errorf("cannot find target pipelinerun %s", opts.TargetPipelineRun)
 		}
 	}
 
+	if opts.ShowEventPayload {
+		if len(statuses) == 0 {
+			return fmt.Errorf("no PipelineRun found for the repository %s", repository.GetName())
+		}
+		return printEventPayload(ctx, cs, opts, ioStreams, repository.GetNamespace(), statuses[0].PipelineRunName)
+	}
+
+	if opts.Output != "" {
+		output := describeOutput{
+			Name:             repository.GetName(),
+			Namespace:        repository.GetNamespace(),
+			URL:              repository.Spec.URL,
+			ConcurrencyLimit: repository.Spec.ConcurrencyLimit,
+			Statuses:         statuses,
+			Events:           eventList,
+		}
+		if output.QueuedPipelineRuns, err = getQueuedPipelineRuns(ctx, cs, repository); err != nil {
+			return err
+		}
+		if err := cli.PrintObject(ioStreams.Out, output, opts.Output); err != nil {
+			return err
+		}
+		return lastRunExitStatus(opts, statuses)
+	}
+
 	data := struct {
 		Repository  *v1alpha1.Repository
 		Statuses    []v1alpha1.RepositoryRunStatus
@@ -268,5 +359,97 @@ func describe(ctx context.Context, cs *params.Run, clock clockwork.Clock, opts *
 		return err
 	}
 
-	return w.Flush()
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return lastRunExitStatus(opts, statuses)
+}
+
+// getArchivedPipelineRun returns the status of the target PipelineRun of the
+// repository from Tekton Results when it has been pruned from the cluster.
+func getArchivedPipelineRun(ctx context.Context, cs *params.Run, opts *describeOpts, repository *v1alpha1.Repository) ([]v1alpha1.RepositoryRunStatus, error) {
+	client := &tektonresults.Client{URL: strings.TrimSuffix(opts.ResultsURL, "/"), Token: opts.ResultsToken, HTTP: &cs.Clients.HTTP}
+	pr, err := client.GetPipelineRun(ctx, repository.GetNamespace(), opts.TargetPipelineRun)
+	if errors.Is(err, tektonresults.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the pipelinerun %s from tekton results: %w", opts.TargetPipelineRun, err)
+	}
+	if pr.GetLabels()[keys.Repository] != repository.GetName() {
+		return nil, nil
+	}
+	rs := status.PipelineRunToRepositoryStatus(*pr, pr.GetAnnotations()[keys.LogURL])
+	rs.CompletionTime = pr.Status.CompletionTime
+	return []v1alpha1.RepositoryRunStatus{rs}, nil
+}
+
+// getQueuedPipelineRuns returns the name of the PipelineRuns waiting in the
+// concurrency queue of the repository.
+func getQueuedPipelineRuns(ctx context.Context, cs *params.Run, repository *v1alpha1.Repository) ([]string, error) {
+	label := fmt.Sprintf("%s=%s,%s=%s", keys.Repository, formatting.CleanValueKubernetes(repository.GetName()),
+		keys.State, kubeinteraction.StateQueued)
+	prs, err := cs.Clients.Tekton.TektonV1().PipelineRuns(repository.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: label,
+	})
+	if err != nil {
+		return nil, err
+	}
+	queued := []string{}
+	for _, pr := range prs.Items {
+		queued = append(queued, pr.GetName())
+	}
+	return queued, nil
+}
+
+// lastRunExitStatus returns an error when asked to exit with the status of
+// the last run and that run has failed, useful for scripts and git hooks.
+func lastRunExitStatus(opts *describeOpts, statuses []v1alpha1.RepositoryRunStatus) error {
+	if !opts.ExitStatus || len(statuses) == 0 || len(statuses[0].Status.Conditions) == 0 {
+		return nil
+	}
+	if statuses[0].Status.Conditions[0].Status == corev1.ConditionFalse {
+		return fmt.Errorf("last PipelineRun %s has failed: %s", statuses[0].PipelineRunName, statuses[0].Status.Conditions[0].Reason)
+	}
+	return nil
+}
+
+// printEventPayload prints the redacted event stored for the PipelineRun when
+// the event-payload-ttl setting is set.
+func printEventPayload(ctx context.Context, cs *params.Run, opts *describeOpts, ioStreams *cli.IOStreams, ns, prName string) error {
+	pr, err := cs.Clients.Tekton.TektonV1().PipelineRuns(ns).Get(ctx, prName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot get the PipelineRun %s: %w", prName, err)
+	}
+	name := pr.GetAnnotations()[keys.EventPayload]
+	if name == "" {
+		return fmt.Errorf("no event stored for the PipelineRun %s, the event-payload-ttl setting may not be set", prName)
+	}
+	cm, err := cs.Clients.Kube.CoreV1().ConfigMaps(ns).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return fmt.Errorf("the event of the PipelineRun %s has expired", prName)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.Output != "" {
+		return cli.PrintObject(ioStreams.Out, eventPayloadOutput{
+			PipelineRun: prName,
+			Event:       json.RawMessage(cm.Data[pipelineascode.EventPayloadEventKey]),
+			Headers:     json.RawMessage(cm.Data[pipelineascode.EventPayloadHeadersKey]),
+			Payload:     json.RawMessage(cm.Data[pipelineascode.EventPayloadPayloadKey]),
+		}, opts.Output)
+	}
+	for _, section := range []struct{ title, key string }{
+		{"Event", pipelineascode.EventPayloadEventKey},
+		{"Headers", pipelineascode.EventPayloadHeadersKey},
+		{"Payload", pipelineascode.EventPayloadPayloadKey},
+	} {
+		if cm.Data[section.key] == "" {
+			continue
+		}
+		fmt.Fprintf(ioStreams.Out, "%s:\n%s\n\n", ioStreams.ColorScheme().Bold(section.title), cm.Data[section.key])
+	}
+	return nil
 }
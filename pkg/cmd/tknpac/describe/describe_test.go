@@ -1,7 +1,10 @@
 package describe
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -15,10 +18,12 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
 	tcli "github.com/openshift-pipelines/pipelines-as-code/pkg/test/cli"
 	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
 	tektontest "github.com/openshift-pipelines/pipelines-as-code/pkg/test/tekton"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
 	"gotest.tools/v3/golden"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -500,3 +505,266 @@ func TestDescribe(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribeOutputAndExitStatus(t *testing.T) {
+	t1 := time.Date(1999, time.February, 3, 4, 5, 6, 7, time.UTC)
+	cw := clockwork.NewFakeClockAt(t1)
+	ns := "ns"
+	failedStatus := v1alpha1.RepositoryRunStatus{
+		Status: knativeduckv1.Status{
+			Conditions: []knativeapis.Condition{
+				{
+					Reason: "Failed",
+					Status: corev1.ConditionFalse,
+				},
+			},
+		},
+		PipelineRunName: "pipelinerun1",
+		LogURL:          github.String("https://everywhere.anwywhere"),
+		StartTime:       &metav1.Time{Time: cw.Now().Add(-16 * time.Minute)},
+		CompletionTime:  &metav1.Time{Time: cw.Now().Add(-15 * time.Minute)},
+		SHA:             github.String("SHA"),
+		SHAURL:          github.String("https://anurl.com/commit/SHA"),
+		Title:           github.String("A title"),
+		TargetBranch:    github.String("TargetBranch"),
+		EventType:       github.String("pull_request"),
+	}
+	tests := []struct {
+		name       string
+		opts       *describeOpts
+		wantErr    bool
+		wantOutput string
+	}{
+		{
+			name:       "json output",
+			opts:       &describeOpts{PacCliOpts: cli.PacCliOpts{Output: cli.OutputJSON}},
+			wantOutput: `"pipelineRunName": "pipelinerun1"`,
+		},
+		{
+			name:       "yaml output",
+			opts:       &describeOpts{PacCliOpts: cli.PacCliOpts{Output: cli.OutputYAML}},
+			wantOutput: "pipelineRunName: pipelinerun1",
+		},
+		{
+			name:       "json output with exit status on failure",
+			opts:       &describeOpts{PacCliOpts: cli.PacCliOpts{Output: cli.OutputJSON}, ExitStatus: true},
+			wantOutput: `"name": "test-run"`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tdata := testclient.Data{
+				Namespaces: []*corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: ns}}},
+				Repositories: []*v1alpha1.Repository{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-run", Namespace: ns},
+						Spec:       v1alpha1.RepositorySpec{URL: "https://anurl.com"},
+						Status:     []v1alpha1.RepositoryRunStatus{failedStatus},
+					},
+				},
+			}
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, tdata)
+			cs := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: stdata.PipelineAsCode,
+					Tekton:         stdata.Pipeline,
+					Kube:           stdata.Kube,
+				},
+				Info: info.Info{Kube: &info.KubeOpts{Namespace: ns}},
+			}
+			cs.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+
+			io, out := tcli.NewIOStream()
+			err := describe(ctx, cs, cw, tt.opts, io, "test-run")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("describe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantOutput) {
+				t.Errorf("describe() output %s does not contain %s", out.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestDescribeEventPayload(t *testing.T) {
+	ns := "ns"
+	cw := clockwork.NewFakeClock()
+	newPR := func(name, payload string) *tektonv1.PipelineRun {
+		pr := tektontest.MakePRCompletion(cw, name, ns, "Succeeded", map[string]string{keys.SHA: name}, map[string]string{keys.Repository: "test-run"}, 30)
+		if payload != "" {
+			pr.Annotations[keys.EventPayload] = payload
+		}
+		return pr
+	}
+	tests := []struct {
+		name       string
+		target     string
+		output     string
+		wantErr    string
+		wantOutput string
+	}{
+		{
+			name:       "stored event",
+			target:     "stored",
+			wantOutput: "Payload:\n{\"ref\": \"main\"}",
+		},
+		{
+			name:       "stored event as json",
+			target:     "stored",
+			output:     cli.OutputJSON,
+			wantOutput: `"pipelinerun": "stored"`,
+		},
+		{
+			name:    "no event stored",
+			target:  "notstored",
+			wantErr: "no event stored for the PipelineRun notstored",
+		},
+		{
+			name:    "expired event",
+			target:  "expired",
+			wantErr: "the event of the PipelineRun expired has expired",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tdata := testclient.Data{
+				Namespaces: []*corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: ns}}},
+				Repositories: []*v1alpha1.Repository{
+					{ObjectMeta: metav1.ObjectMeta{Name: "test-run", Namespace: ns}, Spec: v1alpha1.RepositorySpec{URL: "https://anurl.com"}},
+				},
+				PipelineRuns: []*tektonv1.PipelineRun{
+					newPR("stored", "pac-event-abcd"),
+					newPR("notstored", ""),
+					newPR("expired", "pac-event-gone"),
+				},
+				ConfigMap: []*corev1.ConfigMap{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "pac-event-abcd", Namespace: ns},
+						Data: map[string]string{
+							pipelineascode.EventPayloadEventKey:   `{"SHA": "sha"}`,
+							pipelineascode.EventPayloadPayloadKey: `{"ref": "main"}`,
+						},
+					},
+				},
+			}
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, tdata)
+			cs := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: stdata.PipelineAsCode,
+					Tekton:         stdata.Pipeline,
+					Kube:           stdata.Kube,
+				},
+				Info: info.Info{Kube: &info.KubeOpts{Namespace: ns}},
+			}
+			cs.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+			opts := &describeOpts{
+				PacCliOpts:        cli.PacCliOpts{Output: tt.output},
+				TargetPipelineRun: tt.target,
+				ShowEventPayload:  true,
+			}
+
+			io, out := tcli.NewIOStream()
+			err := describe(ctx, cs, cw, opts, io, "test-run")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, strings.Contains(out.String(), tt.wantOutput), out.String())
+		})
+	}
+}
+
+func TestDescribeArchivedPipelineRun(t *testing.T) {
+	ns := "ns"
+	cw := clockwork.NewFakeClock()
+	archived := tektontest.MakePRCompletion(cw, "archived", ns, "Succeeded",
+		map[string]string{keys.SHA: "archivedsha", keys.EventType: "push", keys.Branch: "main", keys.LogURL: "https://console/archived"},
+		map[string]string{keys.Repository: "test-run"}, 30)
+	other := tektontest.MakePRCompletion(cw, "other", ns, "Succeeded", map[string]string{}, map[string]string{keys.Repository: "another-repo"}, 30)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("Authorization"), "Bearer token")
+		records := []map[string]any{}
+		for _, pr := range []*tektonv1.PipelineRun{archived, other} {
+			if strings.Contains(r.URL.Query().Get("filter"), fmt.Sprintf("%q", pr.GetName())) {
+				value, _ := json.Marshal(pr)
+				records = append(records, map[string]any{"name": "record", "data": map[string]any{"type": "tekton.dev/v1.PipelineRun", "value": value}})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"records": records})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		target     string
+		resultsURL string
+		wantErr    string
+		wantOutput string
+	}{
+		{
+			name:       "archived in tekton results",
+			target:     "archived",
+			resultsURL: server.URL,
+			wantOutput: `"pipelineRunName": "archived"`,
+		},
+		{
+			name:       "archived for another repository",
+			target:     "other",
+			resultsURL: server.URL,
+			wantErr:    "cannot find target pipelinerun other",
+		},
+		{
+			name:       "not archived",
+			target:     "missing",
+			resultsURL: server.URL,
+			wantErr:    "cannot find target pipelinerun missing",
+		},
+		{
+			name:    "no tekton results",
+			target:  "archived",
+			wantErr: "cannot find target pipelinerun archived",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tdata := testclient.Data{
+				Namespaces: []*corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: ns}}},
+				Repositories: []*v1alpha1.Repository{
+					{ObjectMeta: metav1.ObjectMeta{Name: "test-run", Namespace: ns}, Spec: v1alpha1.RepositorySpec{URL: "https://anurl.com"}},
+				},
+			}
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, tdata)
+			cs := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: stdata.PipelineAsCode,
+					Tekton:         stdata.Pipeline,
+					Kube:           stdata.Kube,
+					HTTP:           *server.Client(),
+				},
+				Info: info.Info{Kube: &info.KubeOpts{Namespace: ns}},
+			}
+			cs.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+			opts := &describeOpts{
+				PacCliOpts:        cli.PacCliOpts{Output: cli.OutputJSON},
+				TargetPipelineRun: tt.target,
+				ResultsURL:        tt.resultsURL,
+				ResultsToken:      "token",
+			}
+
+			io, out := tcli.NewIOStream()
+			err := describe(ctx, cs, cw, opts, io, "test-run")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, strings.Contains(out.String(), tt.wantOutput), out.String())
+			assert.Assert(t, strings.Contains(out.String(), "https://console/archived"), out.String())
+		})
+	}
+}
@@ -0,0 +1,515 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/completion"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	paramsinfo "github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	ghprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/templates"
+	"github.com/spf13/cobra"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	namespaceFlag = "namespace"
+	tektonDirFlag = "tekton-dir"
+	shaFlag       = "sha"
+	branchFlag    = "branch"
+
+	controllerTimeout = 10 * time.Second
+)
+
+const longHelp = `
+doctor - check a Pipelines-as-Code setup end to end
+
+tkn pac doctor runs the checks of the full loop of a Repository and reports
+the result of each of them:
+
+* the Pipelines-as-Code installation and the reachability of its controller
+* the Repository and the secrets of its git_provider
+* the scopes of the token of a GitHub Repository in webhook mode
+* the parsing and the resolution of the local PipelineRuns of the directories
+  of the Repository, for an event on the --branch and --sha
+* the creation of the PipelineRuns, as a server side dry run
+* the access to the --sha commit of a GitHub Repository
+
+The checks are read-only, nothing is created on the cluster or on the git
+provider.
+
+eg:
+	tkn pac doctor <repository-name> -n namespace --branch main --sha <sha>
+	`
+
+type checkStatus int
+
+const (
+	statusPass checkStatus = iota
+	statusWarn
+	statusFail
+	statusSkip
+)
+
+type result struct {
+	name    string
+	status  checkStatus
+	message string
+}
+
+type doctorOpts struct {
+	run       *params.Run
+	ioStreams *cli.IOStreams
+	namespace string
+	repoName  string
+	tektonDir string
+	branch    string
+	sha       string
+	// client is the GitHub client to use, it is created from the Repository
+	// token when not set.
+	client *github.Client
+}
+
+// doctor holds the state shared by the checks, a check can skip the next ones
+// when what they need could not be found.
+type doctor struct {
+	opts         *doctorOpts
+	installNS    string
+	repo         *v1alpha1.Repository
+	token        string
+	pipelineRuns []*tektonv1.PipelineRun
+	results      []result
+}
+
+func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check a Pipelines-as-Code setup end to end for a Repository",
+		Long:  longHelp,
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.BaseCompletion("repositories", args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			opts := &doctorOpts{run: run, ioStreams: ioStreams}
+			if opts.namespace, err = cmd.Flags().GetString(namespaceFlag); err != nil {
+				return err
+			}
+			if opts.tektonDir, err = cmd.Flags().GetString(tektonDirFlag); err != nil {
+				return err
+			}
+			if opts.sha, err = cmd.Flags().GetString(shaFlag); err != nil {
+				return err
+			}
+			if opts.branch, err = cmd.Flags().GetString(branchFlag); err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				opts.repoName = args[0]
+			}
+
+			ctx := context.Background()
+			if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+				return err
+			}
+			// only report the errors of the resolution in the report
+			zaplog, err := zap.NewProduction(zap.IncreaseLevel(zap.FatalLevel))
+			if err != nil {
+				return err
+			}
+			run.Clients.Log = zaplog.Sugar()
+			return runDoctor(ctx, opts)
+		},
+	}
+
+	cmd.Flags().StringP(namespaceFlag, "n", "", "If present, the namespace scope for this CLI request")
+	_ = cmd.RegisterFlagCompletionFunc(namespaceFlag,
+		func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return completion.BaseCompletion(namespaceFlag, args)
+		},
+	)
+	cmd.Flags().String(tektonDirFlag, "", "the local directory of the PipelineRuns to resolve, the pipelinerun_dirs of the Repository when empty")
+	cmd.Flags().String(branchFlag, "main", "the branch of the event the PipelineRuns are resolved for")
+	cmd.Flags().String(shaFlag, "", "the commit of the event the PipelineRuns are resolved for, the head of the branch when empty")
+	return cmd
+}
+
+func runDoctor(ctx context.Context, opts *doctorOpts) error {
+	d := &doctor{opts: opts}
+	ns := opts.namespace
+	if ns == "" {
+		ns = opts.run.Info.Kube.Namespace
+	}
+
+	d.checkInstallation(ctx)
+	d.checkController(ctx)
+	d.checkRepository(ctx, ns)
+	d.checkSecrets(ctx)
+	d.checkToken(ctx)
+	d.checkTemplates(ctx)
+	d.checkPipelineRunCreation(ctx)
+	d.checkCommit(ctx)
+	return d.report()
+}
+
+func (d *doctor) add(name string, status checkStatus, format string, args ...any) {
+	d.results = append(d.results, result{name: name, status: status, message: fmt.Sprintf(format, args...)})
+}
+
+func (d *doctor) checkInstallation(ctx context.Context) {
+	ns, version, err := params.GetInstallLocation(ctx, d.opts.run)
+	if err != nil {
+		d.add("Installation", statusFail, "%s", err.Error())
+		return
+	}
+	d.installNS = ns
+	d.add("Installation", statusPass, "version %s installed in the %s namespace", version, ns)
+}
+
+// checkController checks the controller answers on the URL set in the info
+// configmap, any http answer means the webhooks can reach it.
+func (d *doctor) checkController(ctx context.Context) {
+	const name = "Controller"
+	if d.installNS == "" {
+		d.add(name, statusSkip, "no installation found")
+		return
+	}
+	pacInfo, err := info.GetPACInfo(ctx, d.opts.run, d.installNS)
+	if err != nil || pacInfo.ControllerURL == "" {
+		d.add(name, statusWarn, "no controller-url in the pipelines-as-code-info configmap, cannot check the controller is reachable")
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, controllerTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pacInfo.ControllerURL, nil)
+	if err != nil {
+		d.add(name, statusFail, "invalid controller url %s: %s", pacInfo.ControllerURL, err.Error())
+		return
+	}
+	resp, err := d.opts.run.Clients.HTTP.Do(req)
+	if err != nil {
+		d.add(name, statusFail, "cannot reach the controller at %s: %s", pacInfo.ControllerURL, err.Error())
+		return
+	}
+	resp.Body.Close()
+	d.add(name, statusPass, "the controller at %s is reachable", pacInfo.ControllerURL)
+}
+
+func (d *doctor) checkRepository(ctx context.Context, ns string) {
+	var err error
+	if d.opts.repoName != "" {
+		d.repo, err = d.opts.run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(ns).Get(ctx, d.opts.repoName, metav1.GetOptions{})
+	} else {
+		d.repo, err = prompt.SelectRepo(ctx, d.opts.run, ns)
+	}
+	if err != nil {
+		d.repo = nil
+		d.add("Repository", statusFail, "%s", err.Error())
+		return
+	}
+	if _, _, err := formatting.GetRepoOwnerSplitted(d.repo.Spec.URL); err != nil {
+		d.add("Repository", statusFail, "repository %s has an invalid url: %s", d.repo.GetName(), err.Error())
+		d.repo = nil
+		return
+	}
+	d.add("Repository", statusPass, "repository %s/%s for %s", d.repo.GetNamespace(), d.repo.GetName(), d.repo.Spec.URL)
+}
+
+// checkSecrets checks the git_provider secrets of a Repository in webhook
+// mode or the GitHub App secret of the installation.
+func (d *doctor) checkSecrets(ctx context.Context) {
+	const name = "Secrets"
+	if d.repo == nil {
+		d.add(name, statusSkip, "no repository")
+		return
+	}
+	if d.repo.Spec.GitProvider == nil || d.repo.Spec.GitProvider.Secret == nil {
+		if d.installNS != "" && info.IsGithubAppInstalled(ctx, d.opts.run, d.installNS) {
+			d.add(name, statusPass, "the repository uses the GitHub App secret %s", paramsinfo.DefaultPipelinesAscodeSecretName)
+			return
+		}
+		d.add(name, statusFail, "the repository has no git_provider secret and no GitHub App is configured")
+		return
+	}
+
+	token, err := d.secretValue(ctx, d.repo.Spec.GitProvider.Secret, pipelineascode.DefaultGitProviderSecretKey)
+	if err != nil {
+		d.add(name, statusFail, "%s", err.Error())
+		return
+	}
+	d.token = token
+	if d.repo.Spec.GitProvider.WebhookSecret == nil {
+		d.add(name, statusWarn, "the token is set but there is no webhook secret, the payloads are not authenticated")
+		return
+	}
+	if _, err := d.secretValue(ctx, d.repo.Spec.GitProvider.WebhookSecret, pipelineascode.DefaultGitProviderWebhookSecretKey); err != nil {
+		d.add(name, statusFail, "%s", err.Error())
+		return
+	}
+	d.add(name, statusPass, "the token and the webhook secret are set")
+}
+
+func (d *doctor) secretValue(ctx context.Context, ref *v1alpha1.Secret, defaultKey string) (string, error) {
+	key := ref.Key
+	if key == "" {
+		key = defaultKey
+	}
+	secret, err := d.opts.run.Clients.Kube.CoreV1().Secrets(d.repo.GetNamespace()).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(secret.Data[key]))
+	if value == "" {
+		return "", fmt.Errorf("secret %s has no value in the %s key", ref.Name, key)
+	}
+	return value, nil
+}
+
+func (d *doctor) isGitHubWebhook() bool {
+	return d.token != "" && (d.repo.Spec.GitProvider.Type == "" || d.repo.Spec.GitProvider.Type == "github")
+}
+
+func (d *doctor) githubClient(ctx context.Context) (*github.Client, error) {
+	if d.opts.client != nil {
+		return d.opts.client, nil
+	}
+	gh := ghprovider.New()
+	if err := gh.SetClient(ctx, d.opts.run, d.event(), d.repo, nil); err != nil {
+		return nil, err
+	}
+	d.opts.client = gh.Client
+	return gh.Client, nil
+}
+
+func (d *doctor) checkToken(ctx context.Context) {
+	const name = "Token"
+	if d.repo == nil || !d.isGitHubWebhook() {
+		d.add(name, statusSkip, "only the tokens of the GitHub repositories in webhook mode are checked")
+		return
+	}
+	owner, repoName, _ := formatting.GetRepoOwnerSplitted(d.repo.Spec.URL)
+	client, err := d.githubClient(ctx)
+	if err != nil {
+		d.add(name, statusFail, "%s", err.Error())
+		return
+	}
+	report, err := ghprovider.CheckToken(ctx, client, d.token, owner, repoName)
+	if err != nil {
+		d.add(name, statusFail, "cannot check the token: %s", err.Error())
+		return
+	}
+	if len(report.Missing) > 0 {
+		d.add(name, statusFail, "the %s is missing: %s", report.Type, strings.Join(report.Missing, ", "))
+		return
+	}
+	d.add(name, statusPass, "the %s has the needed scopes and permissions", report.Type)
+}
+
+// event returns the push event of the inspected branch and commit, the one
+// the PipelineRuns are resolved for.
+func (d *doctor) event() *paramsinfo.Event {
+	owner, repoName, _ := formatting.GetRepoOwnerSplitted(d.repo.Spec.URL)
+	event := paramsinfo.NewEvent()
+	event.EventType = triggertype.Push.String()
+	event.TriggerTarget = triggertype.Push
+	event.URL = d.repo.Spec.URL
+	event.Organization = owner
+	event.Repository = repoName
+	event.BaseBranch = d.opts.branch
+	event.HeadBranch = d.opts.branch
+	event.SHA = d.opts.sha
+	if event.SHA == "" {
+		event.SHA = d.opts.branch
+	}
+	if d.repo.Spec.GitProvider != nil {
+		event.Provider.URL = d.repo.Spec.GitProvider.URL
+	}
+	event.Provider.Token = d.token
+	return event
+}
+
+// tektonDirs returns the local directories of the PipelineRuns, the --tekton-dir
+// flag or the directories of the Repository.
+func (d *doctor) tektonDirs() []string {
+	if d.opts.tektonDir != "" {
+		return []string{d.opts.tektonDir}
+	}
+	return provider.PipelineRunDirs(d.repo)
+}
+
+// readTektonDir reads the PipelineRun files of the directory and of its sub
+// directories like the controller does.
+func (d *doctor) readTektonDir(dir string) ([]string, error) {
+	var docs []string
+	err := filepath.WalkDir(dir, func(fpath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !provider.IsPipelineRunFile(d.repo, entry.Name()) {
+			return nil
+		}
+		data, err := os.ReadFile(fpath)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, string(data))
+		return nil
+	})
+	return docs, err
+}
+
+// checkTemplates parses and resolves the PipelineRuns of the tekton
+// directories like the controller does, with the standard parameters of an
+// event of the Repository on the inspected branch and commit.
+func (d *doctor) checkTemplates(ctx context.Context) {
+	const name = "PipelineRuns"
+	if d.repo == nil {
+		d.add(name, statusSkip, "no repository")
+		return
+	}
+	dirs := d.tektonDirs()
+	var docs []string
+	for _, dir := range dirs {
+		dirDocs, err := d.readTektonDir(dir)
+		if err != nil {
+			d.add(name, statusFail, "cannot read the tekton directory: %s", err.Error())
+			return
+		}
+		docs = append(docs, dirDocs...)
+	}
+	if len(docs) == 0 {
+		d.add(name, statusFail, "no PipelineRun file in %s", strings.Join(dirs, ", "))
+		return
+	}
+
+	event := d.event()
+	allTemplates := templates.ReplacePlaceHoldersVariables(strings.Join(docs, "\n---\n"), map[string]string{
+		"revision":         event.SHA,
+		"repo_url":         event.URL,
+		"repo_owner":       strings.ToLower(event.Organization),
+		"repo_name":        strings.ToLower(event.Repository),
+		"target_branch":    event.BaseBranch,
+		"source_branch":    event.HeadBranch,
+		"target_namespace": d.repo.GetNamespace(),
+		"event_type":       event.EventType,
+	}, nil, http.Header{}, map[string]any{})
+
+	types, err := resolve.ReadTektonTypes(ctx, d.opts.run.Clients.Log, allTemplates)
+	if err != nil {
+		d.add(name, statusFail, "%s", err.Error())
+		return
+	}
+	if len(types.ValidationErrors) > 0 {
+		problems := []string{}
+		for prName, verr := range types.ValidationErrors {
+			problems = append(problems, fmt.Sprintf("%s: %s", prName, verr))
+		}
+		sort.Strings(problems)
+		d.add(name, statusFail, "tekton validation errors: %s", strings.Join(problems, ", "))
+		return
+	}
+	if len(types.PipelineRuns) == 0 {
+		d.add(name, statusFail, "no PipelineRun found in %s", strings.Join(dirs, ", "))
+		return
+	}
+	pipelineRuns, err := resolve.Resolve(ctx, d.opts.run, d.opts.run.Clients.Log, ghprovider.New(), types, event, &resolve.Opts{
+		GenerateName: true,
+		RemoteTasks:  true,
+	})
+	if err != nil {
+		d.add(name, statusFail, "cannot resolve the PipelineRuns: %s", err.Error())
+		return
+	}
+	d.pipelineRuns = pipelineRuns
+	d.add(name, statusPass, "%d PipelineRuns resolved from %s for %s", len(pipelineRuns), strings.Join(dirs, ", "), event.SHA)
+}
+
+// checkPipelineRunCreation creates the resolved PipelineRuns as a server side
+// dry run, the admission webhooks and the permissions are checked but nothing
+// runs.
+func (d *doctor) checkPipelineRunCreation(ctx context.Context) {
+	const name = "PipelineRun creation"
+	if len(d.pipelineRuns) == 0 {
+		d.add(name, statusSkip, "no resolved PipelineRun")
+		return
+	}
+	for _, pr := range d.pipelineRuns {
+		if _, err := d.opts.run.Clients.Tekton.TektonV1().PipelineRuns(d.repo.GetNamespace()).Create(ctx, pr,
+			metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+			d.add(name, statusFail, "cannot create %s in %s: %s", pr.GetGenerateName(), d.repo.GetNamespace(), err.Error())
+			return
+		}
+	}
+	d.add(name, statusPass, "the PipelineRuns can be created in the %s namespace", d.repo.GetNamespace())
+}
+
+// checkCommit checks the token can read the inspected commit, nothing is
+// written on the repository.
+func (d *doctor) checkCommit(ctx context.Context) {
+	const name = "Commit"
+	if d.opts.sha == "" {
+		d.add(name, statusSkip, "no --sha to check")
+		return
+	}
+	if d.repo == nil || !d.isGitHubWebhook() {
+		d.add(name, statusSkip, "only the commits of the GitHub repositories in webhook mode are checked")
+		return
+	}
+	owner, repoName, _ := formatting.GetRepoOwnerSplitted(d.repo.Spec.URL)
+	client, err := d.githubClient(ctx)
+	if err != nil {
+		d.add(name, statusFail, "%s", err.Error())
+		return
+	}
+	if _, _, err := client.Repositories.GetCommit(ctx, owner, repoName, d.opts.sha, nil); err != nil {
+		d.add(name, statusFail, "cannot read the commit %s: %s", d.opts.sha, err.Error())
+		return
+	}
+	d.add(name, statusPass, "the commit %s can be read with the token", d.opts.sha)
+}
+
+func (d *doctor) report() error {
+	cs := d.opts.ioStreams.ColorScheme()
+	failed := 0
+	for _, r := range d.results {
+		icon := cs.SuccessIcon()
+		switch r.status {
+		case statusWarn:
+			icon = cs.WarningIcon()
+		case statusFail:
+			icon = cs.FailureIcon()
+			failed++
+		case statusSkip:
+			icon = cs.InfoIcon()
+		case statusPass:
+		}
+		fmt.Fprintf(d.opts.ioStreams.Out, "%s %s: %s\n", icon, r.name, r.message)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of the %d checks failed", failed, len(d.results))
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package doctor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestRunDoctor(t *testing.T) {
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer controller.Close()
+
+	tests := []struct {
+		name      string
+		repoName  string
+		tektonDir string
+		dirs      []string
+		sha       string
+		scopes    string
+		wantErr   string
+		wantOut   string
+	}{
+		{
+			name:      "all checks pass",
+			repoName:  "repo",
+			tektonDir: "testdata/tekton",
+			sha:       "abcd",
+			scopes:    "repo",
+			wantOut: "✓ Installation: version v1.0.0 installed in the pipelines-as-code namespace\n" +
+				fmt.Sprintf("✓ Controller: the controller at %s is reachable\n", controller.URL) +
+				"✓ Repository: repository ns/repo for https://github.com/owner/repo\n" +
+				"✓ Secrets: the token and the webhook secret are set\n" +
+				"✓ Token: the classic personal access token has the needed scopes and permissions\n" +
+				"✓ PipelineRuns: 1 PipelineRuns resolved from testdata/tekton for abcd\n" +
+				"✓ PipelineRun creation: the PipelineRuns can be created in the ns namespace\n" +
+				"✓ Commit: the commit abcd can be read with the token\n",
+		},
+		{
+			name:     "pipelinerun dirs of the repository",
+			repoName: "repo",
+			dirs:     []string{"testdata/missing", "testdata/tekton"},
+			scopes:   "repo",
+			wantOut: "✓ Installation: version v1.0.0 installed in the pipelines-as-code namespace\n" +
+				fmt.Sprintf("✓ Controller: the controller at %s is reachable\n", controller.URL) +
+				"✓ Repository: repository ns/repo for https://github.com/owner/repo\n" +
+				"✓ Secrets: the token and the webhook secret are set\n" +
+				"✓ Token: the classic personal access token has the needed scopes and permissions\n" +
+				"X PipelineRuns: cannot read the tekton directory: lstat testdata/missing: no such file or directory\n" +
+				"ℹ PipelineRun creation: no resolved PipelineRun\n" +
+				"ℹ Commit: no --sha to check\n",
+			wantErr: "1 of the 8 checks failed",
+		},
+		{
+			name:     "default branch",
+			repoName: "repo",
+			dirs:     []string{"testdata/tekton"},
+			scopes:   "repo",
+			wantOut: "✓ Installation: version v1.0.0 installed in the pipelines-as-code namespace\n" +
+				fmt.Sprintf("✓ Controller: the controller at %s is reachable\n", controller.URL) +
+				"✓ Repository: repository ns/repo for https://github.com/owner/repo\n" +
+				"✓ Secrets: the token and the webhook secret are set\n" +
+				"✓ Token: the classic personal access token has the needed scopes and permissions\n" +
+				"✓ PipelineRuns: 1 PipelineRuns resolved from testdata/tekton for main\n" +
+				"✓ PipelineRun creation: the PipelineRuns can be created in the ns namespace\n" +
+				"ℹ Commit: no --sha to check\n",
+		},
+		{
+			name:      "failing checks",
+			repoName:  "repo",
+			tektonDir: "testdata/missing",
+			scopes:    "read:org",
+			wantErr:   "2 of the 8 checks failed",
+			wantOut: "✓ Installation: version v1.0.0 installed in the pipelines-as-code namespace\n" +
+				fmt.Sprintf("✓ Controller: the controller at %s is reachable\n", controller.URL) +
+				"✓ Repository: repository ns/repo for https://github.com/owner/repo\n" +
+				"✓ Secrets: the token and the webhook secret are set\n" +
+				"X Token: the classic personal access token is missing: repo\n" +
+				"X PipelineRuns: cannot read the tekton directory: lstat testdata/missing: no such file or directory\n" +
+				"ℹ PipelineRun creation: no resolved PipelineRun\n" +
+				"ℹ Commit: no --sha to check\n",
+		},
+		{
+			name:     "no repository",
+			repoName: "missing",
+			wantErr:  "1 of the 8 checks failed",
+			wantOut: "✓ Installation: version v1.0.0 installed in the pipelines-as-code namespace\n" +
+				fmt.Sprintf("✓ Controller: the controller at %s is reachable\n", controller.URL) +
+				"X Repository: repositories.pipelinesascode.tekton.dev \"missing\" not found\n" +
+				"ℹ Secrets: no repository\n" +
+				"ℹ Token: only the tokens of the GitHub repositories in webhook mode are checked\n" +
+				"ℹ PipelineRuns: no repository\n" +
+				"ℹ PipelineRun creation: no resolved PipelineRun\n" +
+				"ℹ Commit: no --sha to check\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			mux.HandleFunc("/user", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-OAuth-Scopes", tt.scopes)
+				fmt.Fprint(w, `{"login": "user"}`)
+			})
+			mux.HandleFunc("/repos/owner/repo/commits/abcd", func(w http.ResponseWriter, r *http.Request) {
+				// the checks are read-only
+				assert.Equal(t, r.Method, http.MethodGet)
+				fmt.Fprint(w, `{"sha": "abcd"}`)
+			})
+
+			tdata := testclient.Data{
+				Deployments: []*appsv1.Deployment{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pipelines-as-code-controller",
+						Namespace: "pipelines-as-code",
+						Labels:    map[string]string{"app.kubernetes.io/version": "v1.0.0"},
+					},
+				}},
+				ConfigMap: []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: "pipelines-as-code-info", Namespace: "pipelines-as-code"},
+					Data:       map[string]string{"controller-url": controller.URL},
+				}},
+				Repositories: []*v1alpha1.Repository{{
+					ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+					Spec: v1alpha1.RepositorySpec{
+						URL:      "https://github.com/owner/repo",
+						Settings: &v1alpha1.Settings{PipelineRunDirs: tt.dirs},
+						GitProvider: &v1alpha1.GitProvider{
+							Secret:        &v1alpha1.Secret{Name: "secret"},
+							WebhookSecret: &v1alpha1.Secret{Name: "secret"},
+						},
+					},
+				}},
+				Secret: []*corev1.Secret{{
+					ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+					Data: map[string][]byte{
+						"provider.token": []byte("ghp_token"),
+						"webhook.secret": []byte("secret"),
+					},
+				}},
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, tdata)
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			run := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: stdata.PipelineAsCode,
+					Kube:           stdata.Kube,
+					Tekton:         stdata.Pipeline,
+					Log:            zap.New(observer).Sugar(),
+				},
+				Info: info.Info{Kube: &info.KubeOpts{Namespace: "ns"}},
+			}
+			out := &bytes.Buffer{}
+			ioStreams := &cli.IOStreams{In: io.NopCloser(&bytes.Buffer{}), Out: out, ErrOut: &bytes.Buffer{}}
+
+			err := runDoctor(ctx, &doctorOpts{
+				run:       run,
+				ioStreams: ioStreams,
+				repoName:  tt.repoName,
+				tektonDir: tt.tektonDir,
+				branch:    "main",
+				sha:       tt.sha,
+				client:    fakeclient,
+			})
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			assert.Equal(t, out.String(), tt.wantOut)
+		})
+	}
+}
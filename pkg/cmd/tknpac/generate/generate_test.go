@@ -128,6 +128,47 @@ func TestGenerateTemplate(t *testing.T) {
 			},
 			regenerateTemplate: true,
 		},
+		{
+			name: "pull request docker",
+			askStubs: func(as *prompt.AskStubber) {
+				as.StubOneDefault() // pull_request
+				as.StubOne("")      // default as main
+			},
+			addExtraFilesInRepo: map[string]string{
+				"Dockerfile": "FROM scratch",
+			},
+			checkGeneratedFile: ".tekton/pull-request.yaml",
+			checkRegInGeneratedFile: []*regexp.Regexp{
+				regexp.MustCompile("name: container-pull-request"),
+				regexp.MustCompile("- name: build-image"),
+				regexp.MustCompile("name: buildah"),
+			},
+			gitinfo: git.Info{
+				URL: "https://hello/container",
+			},
+			regenerateTemplate: true,
+		},
+		{
+			name: "pull request multiple languages detected",
+			askStubs: func(as *prompt.AskStubber) {
+				as.StubOneDefault()  // pull_request
+				as.StubOne("")       // default as main
+				as.StubOne("docker") // choose the docker template over go
+			},
+			addExtraFilesInRepo: map[string]string{
+				"go.mod":     "random string",
+				"Dockerfile": "FROM scratch",
+			},
+			checkGeneratedFile: ".tekton/pull-request.yaml",
+			checkRegInGeneratedFile: []*regexp.Regexp{
+				regexp.MustCompile("name: multi-pull-request"),
+				regexp.MustCompile("name: buildah"),
+			},
+			gitinfo: git.Info{
+				URL: "https://hello/multi",
+			},
+			regenerateTemplate: true,
+		},
 		{
 			name: "pull request already exist don't regenerate sample template",
 			askStubs: func(as *prompt.AskStubber) {
@@ -10,32 +10,41 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli/prompt"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
 type langOpts struct {
-	detectionFile string
+	detectionFiles []string
 }
 
 // I hate this part of the code so much.. but we are waiting for UBI images
 // having >1.6 golang for integrated templates.
 var languageDetection = map[string]langOpts{
 	"go": {
-		detectionFile: "go.mod",
+		detectionFiles: []string{"go.mod"},
 	},
 	"python": {
-		detectionFile: "setup.py",
+		detectionFiles: []string{"setup.py", "pyproject.toml", "requirements.txt"},
 	},
 	"nodejs": {
-		detectionFile: "package.json",
+		detectionFiles: []string{"package.json"},
 	},
 	"java": {
-		detectionFile: "pom.xml",
+		detectionFiles: []string{"pom.xml"},
+	},
+	"docker": {
+		detectionFiles: []string{"Dockerfile"},
 	},
 	"generic": {},
 }
 
+// languageOrder is the order in which we detect the languages, the first one
+// detected is the default choice when multiple languages are detected.
+var languageOrder = []string{"go", "python", "nodejs", "java", "docker"}
+
 //go:embed templates
 var resource embed.FS
 
@@ -48,20 +57,46 @@ func (o *Opts) detectLanguage() (string, error) {
 	}
 
 	cs := o.IOStreams.ColorScheme()
-	for t, v := range languageDetection {
-		if v.detectionFile == "" {
-			continue
-		}
-		fpath := filepath.Join(o.GitInfo.TopLevelPath, v.detectionFile)
-		if _, err := os.Stat(fpath); !os.IsNotExist(err) {
-			fmt.Fprintf(o.IOStreams.Out, "%s We have detected your repository using the programming language %s.\n",
-				cs.SuccessIcon(),
-				cs.Bold(cases.Title(language.Und, cases.NoLower).String(t)),
-			)
-			return t, nil
+	detected := o.detectedLanguages()
+	switch len(detected) {
+	case 0:
+		return "generic", nil
+	case 1:
+		fmt.Fprintf(o.IOStreams.Out, "%s We have detected your repository using the programming language %s.\n",
+			cs.SuccessIcon(),
+			cs.Bold(cases.Title(language.Und, cases.NoLower).String(detected[0])),
+		)
+		return detected[0], nil
+	}
+
+	var choice string
+	if err := prompt.SurveyAskOne(&survey.Select{
+		Message: "We have detected multiple languages in your repository, which template would you like to use?",
+		Options: append(detected, "generic"),
+		Default: detected[0],
+	}, &choice); err != nil {
+		return "", err
+	}
+	if choice == "" {
+		choice = detected[0]
+	}
+	return choice, nil
+}
+
+// detectedLanguages returns all the languages we have detected in the
+// repository, in the languageOrder order.
+func (o *Opts) detectedLanguages() []string {
+	detected := []string{}
+	for _, lang := range languageOrder {
+		for _, detectionFile := range languageDetection[lang].detectionFiles {
+			fpath := filepath.Join(o.GitInfo.TopLevelPath, detectionFile)
+			if _, err := os.Stat(fpath); !os.IsNotExist(err) {
+				detected = append(detected, lang)
+				break
+			}
 		}
 	}
-	return "generic", nil
+	return detected
 }
 
 func (o *Opts) genTmpl() (*bytes.Buffer, error) {
@@ -0,0 +1,268 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/bootstrap"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const installLongHelp = `
+install - install Pipelines-as-Code from its release manifests
+
+tkn pac install applies the release manifests of a version, the latest release
+by default, with the changes set by the flags: the namespace, the number of
+replicas of the controller, the host and the TLS termination of the OpenShift
+route or an Ingress for the other clusters. The controller url of the
+pipelines-as-code-info configmap is set from the route or the ingress host.
+
+The manifests are applied with kubectl, --dry-run shows the difference with
+the cluster without applying them.
+
+eg:
+	tkn pac install --namespace pipelines-as-code --ingress-host pac.example.com --tls-secret pac-tls
+	`
+
+const upgradeLongHelp = `
+upgrade - upgrade Pipelines-as-Code to a new release
+
+tkn pac upgrade applies the release manifests of a version, the latest release
+by default, on the namespace of the current installation with the same flags as
+tkn pac install. The Repositories are migrated to the storage version of the
+new CRD once the manifests are applied.
+
+eg:
+	tkn pac upgrade --version v0.25.0 --dry-run
+	`
+
+type installOpts struct {
+	run       *params.Run
+	ioStreams *cli.IOStreams
+	manifestOpts
+	version       string
+	filename      string
+	dryRun        bool
+	force         bool
+	skipMigration bool
+	// kubectl runs kubectl with the manifests as stdin.
+	kubectl func(stdin string, args ...string) (string, error)
+}
+
+func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
+	opts := &installOpts{run: run, ioStreams: ioStreams, kubectl: kubectl}
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install Pipelines-as-Code from its release manifests",
+		Long:  installLongHelp,
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+				return err
+			}
+			return install(ctx, opts)
+		},
+	}
+	addFlags(cmd, opts)
+	cmd.Flags().StringVar(&opts.namespace, "namespace", releaseNamespace, "the namespace to install Pipelines-as-Code in")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "install even if Pipelines-as-Code is already installed")
+	return cmd
+}
+
+func UpgradeCommand(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
+	opts := &installOpts{run: run, ioStreams: ioStreams, kubectl: kubectl}
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade Pipelines-as-Code to a new release",
+		Long:  upgradeLongHelp,
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+				return err
+			}
+			return upgrade(ctx, opts)
+		},
+	}
+	addFlags(cmd, opts)
+	cmd.Flags().StringVar(&opts.namespace, "namespace", "", "the namespace of the installation, detected when empty")
+	cmd.Flags().BoolVar(&opts.skipMigration, "skip-migration", false, "do not migrate the Repositories to the new CRD version")
+	return cmd
+}
+
+func addFlags(cmd *cobra.Command, opts *installOpts) {
+	cmd.Flags().StringVar(&opts.version, "version", "", "the version to install, the latest release when empty or nightly")
+	cmd.Flags().StringVarP(&opts.filename, "filename", "f", "", "the release manifests to apply, a file or an url, instead of the ones of the version")
+	cmd.Flags().IntVar(&opts.controllerReplicas, "controller-replicas", 0, "the number of replicas of the controller, the one of the release when 0")
+	cmd.Flags().StringVar(&opts.routeHost, "route-host", "", "the host of the OpenShift route of the controller")
+	cmd.Flags().StringVar(&opts.tlsTermination, "route-tls-termination", "", "the TLS termination of the OpenShift route of the controller: edge, reencrypt or passthrough")
+	cmd.Flags().StringVar(&opts.ingressHost, "ingress-host", "", "create an Ingress exposing the controller on this host")
+	cmd.Flags().StringVar(&opts.ingressClass, "ingress-class", "", "the ingress class of the Ingress of the controller")
+	cmd.Flags().StringVar(&opts.tlsSecret, "tls-secret", "", "the secret with the TLS certificate of the Ingress of the controller")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "show the difference with the cluster without applying the manifests")
+}
+
+func install(ctx context.Context, opts *installOpts) error {
+	if !opts.force {
+		if ns, version, err := installLocation(ctx, opts.run, opts.namespace); err == nil {
+			return fmt.Errorf("pipelines-as-code %s is already installed in the %s namespace, use tkn pac upgrade or --force", version, ns)
+		}
+	}
+	version, err := apply(ctx, opts)
+	if err != nil || opts.dryRun {
+		return err
+	}
+	fmt.Fprintf(opts.ioStreams.Out, "%s Pipelines-as-Code %s has been installed in the %s namespace\n",
+		opts.ioStreams.ColorScheme().SuccessIcon(), version, opts.namespace)
+	return nil
+}
+
+func upgrade(ctx context.Context, opts *installOpts) error {
+	ns, installed, err := installLocation(ctx, opts.run, opts.namespace)
+	if err != nil {
+		return fmt.Errorf("%w, use tkn pac install", err)
+	}
+	opts.namespace = ns
+	version, err := apply(ctx, opts)
+	if err != nil || opts.dryRun {
+		return err
+	}
+	cs := opts.ioStreams.ColorScheme()
+	fmt.Fprintf(opts.ioStreams.Out, "%s Pipelines-as-Code has been upgraded from %s to %s in the %s namespace\n",
+		cs.SuccessIcon(), installed, version, ns)
+	if opts.skipMigration {
+		return nil
+	}
+	migrated, err := migrateRepositories(ctx, opts.run)
+	if err != nil {
+		return fmt.Errorf("cannot migrate the repositories to the new CRD version: %w", err)
+	}
+	fmt.Fprintf(opts.ioStreams.Out, "%s %d Repositories have been migrated to the new CRD version\n", cs.SuccessIcon(), migrated)
+	return nil
+}
+
+// installLocation returns the namespace and the version of the installation in
+// the namespace, or in the default namespaces when empty.
+func installLocation(ctx context.Context, run *params.Run, namespace string) (string, string, error) {
+	if namespace == "" {
+		return params.GetInstallLocation(ctx, run)
+	}
+	deployment, err := run.Clients.Kube.AppsV1().Deployments(namespace).Get(ctx, controllerName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("cannot find your pipelines-as-code installation in the %s namespace: %w", namespace, err)
+	}
+	version, ok := deployment.GetLabels()["app.kubernetes.io/version"]
+	if !ok {
+		version = "unknown"
+	}
+	return namespace, version, nil
+}
+
+// apply fetches and transforms the release manifests then applies them, or
+// shows the difference with the cluster on a dry run. It returns the version
+// applied.
+func apply(ctx context.Context, opts *installOpts) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", err
+	}
+	version, uri := opts.version, opts.filename
+	if uri == "" {
+		isOpenShift, _ := bootstrap.IsOpenShift(opts.run)
+		var err error
+		if version, uri, err = bootstrap.ReleaseURL(ctx, opts.version, isOpenShift); err != nil {
+			return "", fmt.Errorf("cannot get the release: %w", err)
+		}
+	}
+	if version == "" {
+		version = "custom"
+	}
+	manifests, err := readManifests(ctx, opts.run, uri)
+	if err != nil {
+		return "", err
+	}
+	transformed, err := transformManifests(manifests, opts.manifestOpts)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.dryRun {
+		out, err := opts.kubectl(transformed, "diff", "-f", "-")
+		if err != nil {
+			return "", err
+		}
+		if out == "" {
+			out = "no change\n"
+		}
+		fmt.Fprint(opts.ioStreams.Out, out)
+		return version, nil
+	}
+	if _, err := opts.kubectl(transformed, "apply", "-f", "-"); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+func readManifests(ctx context.Context, run *params.Run, uri string) (string, error) {
+	if strings.HasPrefix(uri, "https://") || strings.HasPrefix(uri, "http://") {
+		data, err := run.Clients.GetURL(ctx, uri)
+		if err != nil {
+			return "", fmt.Errorf("cannot fetch the release manifests %s: %w", uri, err)
+		}
+		return string(data), nil
+	}
+	data, err := os.ReadFile(uri)
+	if err != nil {
+		return "", fmt.Errorf("cannot read the release manifests: %w", err)
+	}
+	return string(data), nil
+}
+
+// migrateRepositories updates all the Repositories without changing them so
+// they are stored with the storage version of the CRD.
+func migrateRepositories(ctx context.Context, run *params.Run) (int, error) {
+	repos, err := run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	for i := range repos.Items {
+		repo := &repos.Items[i]
+		if _, err := run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(repo.GetNamespace()).Update(ctx, repo, metav1.UpdateOptions{}); err != nil {
+			return i, fmt.Errorf("cannot migrate repository %s/%s: %w", repo.GetNamespace(), repo.GetName(), err)
+		}
+	}
+	return len(repos.Items), nil
+}
+
+// kubectl runs kubectl with stdin, kubectl diff exits with 1 when there is a
+// difference which is not an error.
+func kubectl(stdin string, args ...string) (string, error) {
+	path, err := exec.LookPath("kubectl")
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if args[0] == "diff" && errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return stdout.String(), nil
+		}
+		return "", fmt.Errorf("kubectl %s: %w\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,129 @@
+package install
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestInstallAndUpgrade(t *testing.T) {
+	tests := []struct {
+		name        string
+		upgrade     bool
+		installed   bool
+		dryRun      bool
+		force       bool
+		kubectlOut  string
+		wantErr     string
+		wantArgs    []string
+		wantOut     string
+		wantNoApply bool
+	}{
+		{
+			name:     "install",
+			wantArgs: []string{"apply", "-f", "-"},
+			wantOut:  "✓ Pipelines-as-Code custom has been installed in the ci namespace\n",
+		},
+		{
+			name:        "already installed",
+			installed:   true,
+			wantErr:     "pipelines-as-code v0.24.0 is already installed in the ci namespace, use tkn pac upgrade or --force",
+			wantNoApply: true,
+		},
+		{
+			name:      "forced install",
+			installed: true,
+			force:     true,
+			wantArgs:  []string{"apply", "-f", "-"},
+			wantOut:   "✓ Pipelines-as-Code custom has been installed in the ci namespace\n",
+		},
+		{
+			name:       "dry run",
+			dryRun:     true,
+			kubectlOut: "diff -u -N /tmp/LIVE/apps.v1.Deployment\n",
+			wantArgs:   []string{"diff", "-f", "-"},
+			wantOut:    "diff -u -N /tmp/LIVE/apps.v1.Deployment\n",
+		},
+		{
+			name:      "upgrade",
+			upgrade:   true,
+			installed: true,
+			wantArgs:  []string{"apply", "-f", "-"},
+			wantOut: "✓ Pipelines-as-Code has been upgraded from v0.24.0 to custom in the ci namespace\n" +
+				"✓ 2 Repositories have been migrated to the new CRD version\n",
+		},
+		{
+			name:        "upgrade without installation",
+			upgrade:     true,
+			wantErr:     "cannot find your pipelines-as-code installation in the ci namespace: deployments.apps \"pipelines-as-code-controller\" not found, use tkn pac install",
+			wantNoApply: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			tdata := testclient.Data{
+				Repositories: []*v1alpha1.Repository{
+					{ObjectMeta: metav1.ObjectMeta{Name: "repo1", Namespace: "ns1"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "repo2", Namespace: "ns2"}},
+				},
+			}
+			if tt.installed {
+				tdata.Deployments = []*appsv1.Deployment{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      controllerName,
+						Namespace: "ci",
+						Labels:    map[string]string{"app.kubernetes.io/version": "v0.24.0"},
+					},
+				}}
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, tdata)
+			run := &params.Run{Clients: clients.Clients{PipelineAsCode: stdata.PipelineAsCode, Kube: stdata.Kube}}
+			out := &bytes.Buffer{}
+
+			var gotArgs []string
+			var gotStdin string
+			opts := &installOpts{
+				run:          run,
+				ioStreams:    &cli.IOStreams{In: io.NopCloser(&bytes.Buffer{}), Out: out, ErrOut: &bytes.Buffer{}},
+				manifestOpts: manifestOpts{namespace: "ci"},
+				filename:     "testdata/release.yaml",
+				dryRun:       tt.dryRun,
+				force:        tt.force,
+				kubectl: func(stdin string, args ...string) (string, error) {
+					gotArgs, gotStdin = args, stdin
+					return tt.kubectlOut, nil
+				},
+			}
+			var err error
+			if tt.upgrade {
+				err = upgrade(ctx, opts)
+			} else {
+				err = install(ctx, opts)
+			}
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			if tt.wantNoApply {
+				assert.Assert(t, gotArgs == nil)
+				return
+			}
+			assert.DeepEqual(t, gotArgs, tt.wantArgs)
+			assert.Assert(t, strings.Contains(gotStdin, "name: "+opts.namespace+"\n"), gotStdin)
+			assert.Equal(t, out.String(), tt.wantOut)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package install
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	releaseNamespace = "pipelines-as-code"
+	controllerName   = "pipelines-as-code-controller"
+	infoConfigMap    = "pipelines-as-code-info"
+	controllerPort   = "http-listener"
+)
+
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+var tlsTerminations = map[string]bool{"edge": true, "reencrypt": true, "passthrough": true}
+
+// manifestOpts are the changes to apply on the release manifests.
+type manifestOpts struct {
+	namespace          string
+	controllerReplicas int
+	routeHost          string
+	tlsTermination     string
+	ingressHost        string
+	ingressClass       string
+	tlsSecret          string
+}
+
+func (m manifestOpts) validate() error {
+	if m.tlsTermination != "" && !tlsTerminations[m.tlsTermination] {
+		return fmt.Errorf("invalid route tls termination %s, must be one of edge, reencrypt or passthrough", m.tlsTermination)
+	}
+	if m.tlsSecret != "" && m.ingressHost == "" {
+		return fmt.Errorf("a tls secret needs an ingress host")
+	}
+	if m.controllerReplicas < 0 {
+		return fmt.Errorf("the number of controller replicas cannot be negative")
+	}
+	return nil
+}
+
+// controllerURL returns the public url of the controller when it is known
+// from the options.
+func (m manifestOpts) controllerURL() string {
+	switch {
+	case m.routeHost != "":
+		return "https://" + m.routeHost
+	case m.ingressHost != "" && m.tlsSecret != "":
+		return "https://" + m.ingressHost
+	case m.ingressHost != "":
+		return "http://" + m.ingressHost
+	}
+	return ""
+}
+
+// transformManifests applies the options on the release manifests and returns
+// the documents to apply.
+func transformManifests(manifests string, opts manifestOpts) (string, error) {
+	objects := []*unstructured.Unstructured{}
+	for _, doc := range documentSeparator.Split(manifests, -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		obj := map[string]any{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return "", fmt.Errorf("cannot parse the release manifests: %w", err)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: obj})
+	}
+	if len(objects) == 0 {
+		return "", fmt.Errorf("no object found in the release manifests")
+	}
+
+	if opts.ingressHost != "" {
+		objects = append(objects, ingress(opts))
+	}
+	for _, obj := range objects {
+		if err := transformObject(obj, opts); err != nil {
+			return "", fmt.Errorf("cannot update %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	docs := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return "", err
+		}
+		docs = append(docs, "---\n"+string(data))
+	}
+	return strings.Join(docs, ""), nil
+}
+
+func transformObject(obj *unstructured.Unstructured, opts manifestOpts) error {
+	if opts.namespace != "" && opts.namespace != releaseNamespace {
+		if obj.GetKind() == "Namespace" && obj.GetName() == releaseNamespace {
+			obj.SetName(opts.namespace)
+		}
+		replaceNamespace(obj.Object, releaseNamespace, opts.namespace)
+	}
+
+	switch {
+	case obj.GetKind() == "Deployment" && obj.GetName() == controllerName && opts.controllerReplicas > 0:
+		return unstructured.SetNestedField(obj.Object, int64(opts.controllerReplicas), "spec", "replicas")
+	case obj.GetKind() == "Route" && obj.GetName() == controllerName:
+		if opts.routeHost != "" {
+			if err := unstructured.SetNestedField(obj.Object, opts.routeHost, "spec", "host"); err != nil {
+				return err
+			}
+		}
+		if opts.tlsTermination != "" {
+			return unstructured.SetNestedField(obj.Object, opts.tlsTermination, "spec", "tls", "termination")
+		}
+	case obj.GetKind() == "ConfigMap" && obj.GetName() == infoConfigMap:
+		if controllerURL := opts.controllerURL(); controllerURL != "" {
+			return unstructured.SetNestedField(obj.Object, controllerURL, "data", "controller-url")
+		}
+	}
+	return nil
+}
+
+// replaceNamespace replaces the namespace in the namespace fields, like the
+// metadata or the subjects of the bindings, and in the matchNames of the
+// namespace selectors.
+func replaceNamespace(obj any, from, to string) {
+	switch v := obj.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch {
+			case key == "namespace" && value == from:
+				v[key] = to
+			case key == "matchNames":
+				if names, ok := value.([]any); ok {
+					for i, name := range names {
+						if name == from {
+							names[i] = to
+						}
+					}
+				}
+			default:
+				replaceNamespace(value, from, to)
+			}
+		}
+	case []any:
+		for _, value := range v {
+			replaceNamespace(value, from, to)
+		}
+	}
+}
+
+// ingress returns an Ingress exposing the controller on the ingress host, the
+// clusters without the OpenShift routes have none in the release.
+func ingress(opts manifestOpts) *unstructured.Unstructured {
+	spec := map[string]any{
+		"rules": []any{
+			map[string]any{
+				"host": opts.ingressHost,
+				"http": map[string]any{
+					"paths": []any{
+						map[string]any{
+							"path":     "/",
+							"pathType": "Prefix",
+							"backend": map[string]any{
+								"service": map[string]any{
+									"name": controllerName,
+									"port": map[string]any{"name": controllerPort},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if opts.ingressClass != "" {
+		spec["ingressClassName"] = opts.ingressClass
+	}
+	if opts.tlsSecret != "" {
+		spec["tls"] = []any{
+			map[string]any{
+				"hosts":      []any{opts.ingressHost},
+				"secretName": opts.tlsSecret,
+			},
+		}
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata": map[string]any{
+			"name":      controllerName,
+			"namespace": releaseNamespace,
+			"labels": map[string]any{
+				"app.kubernetes.io/part-of": "pipelines-as-code",
+			},
+		},
+		"spec": spec,
+	}}
+}
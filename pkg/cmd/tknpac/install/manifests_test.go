@@ -0,0 +1,93 @@
+package install
+
+import (
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func parseManifests(t *testing.T, manifests string) map[string]*unstructured.Unstructured {
+	t.Helper()
+	objects := map[string]*unstructured.Unstructured{}
+	for _, doc := range documentSeparator.Split(manifests, -1) {
+		obj := map[string]any{}
+		assert.NilError(t, yaml.Unmarshal([]byte(doc), &obj))
+		if len(obj) == 0 {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: obj}
+		objects[u.GetKind()] = u
+	}
+	return objects
+}
+
+func TestTransformManifests(t *testing.T) {
+	release, err := os.ReadFile("testdata/release.yaml")
+	assert.NilError(t, err)
+
+	out, err := transformManifests(string(release), manifestOpts{
+		namespace:          "ci",
+		controllerReplicas: 3,
+		routeHost:          "pac.apps.example.com",
+		tlsTermination:     "reencrypt",
+	})
+	assert.NilError(t, err)
+	objects := parseManifests(t, out)
+	assert.Equal(t, len(objects), 6)
+
+	assert.Equal(t, objects["Namespace"].GetName(), "ci")
+	assert.Equal(t, objects["Deployment"].GetNamespace(), "ci")
+	replicas, _, _ := unstructured.NestedFieldNoCopy(objects["Deployment"].Object, "spec", "replicas")
+	assert.Equal(t, replicas, float64(3))
+	subjects, _, _ := unstructured.NestedSlice(objects["ClusterRoleBinding"].Object, "subjects")
+	assert.Equal(t, subjects[0].(map[string]any)["namespace"], "ci")
+	matchNames, _, _ := unstructured.NestedStringSlice(objects["ServiceMonitor"].Object, "spec", "namespaceSelector", "matchNames")
+	assert.DeepEqual(t, matchNames, []string{"ci"})
+	host, _, _ := unstructured.NestedString(objects["Route"].Object, "spec", "host")
+	assert.Equal(t, host, "pac.apps.example.com")
+	termination, _, _ := unstructured.NestedString(objects["Route"].Object, "spec", "tls", "termination")
+	assert.Equal(t, termination, "reencrypt")
+	controllerURL, _, _ := unstructured.NestedString(objects["ConfigMap"].Object, "data", "controller-url")
+	assert.Equal(t, controllerURL, "https://pac.apps.example.com")
+}
+
+func TestTransformManifestsIngress(t *testing.T) {
+	release, err := os.ReadFile("testdata/release.yaml")
+	assert.NilError(t, err)
+
+	out, err := transformManifests(string(release), manifestOpts{
+		namespace:    releaseNamespace,
+		ingressHost:  "pac.example.com",
+		ingressClass: "nginx",
+		tlsSecret:    "pac-tls",
+	})
+	assert.NilError(t, err)
+	objects := parseManifests(t, out)
+	ing := objects["Ingress"]
+	assert.Assert(t, ing != nil)
+	assert.Equal(t, ing.GetNamespace(), releaseNamespace)
+	class, _, _ := unstructured.NestedString(ing.Object, "spec", "ingressClassName")
+	assert.Equal(t, class, "nginx")
+	rules, _, _ := unstructured.NestedSlice(ing.Object, "spec", "rules")
+	assert.Equal(t, rules[0].(map[string]any)["host"], "pac.example.com")
+	tls, _, _ := unstructured.NestedSlice(ing.Object, "spec", "tls")
+	assert.Equal(t, tls[0].(map[string]any)["secretName"], "pac-tls")
+	controllerURL, _, _ := unstructured.NestedString(objects["ConfigMap"].Object, "data", "controller-url")
+	assert.Equal(t, controllerURL, "https://pac.example.com")
+	replicas, _, _ := unstructured.NestedFieldNoCopy(objects["Deployment"].Object, "spec", "replicas")
+	assert.Equal(t, replicas, float64(1))
+}
+
+func TestManifestOptsValidate(t *testing.T) {
+	assert.NilError(t, manifestOpts{tlsTermination: "edge"}.validate())
+	assert.Error(t, manifestOpts{tlsTermination: "none"}.validate(),
+		"invalid route tls termination none, must be one of edge, reencrypt or passthrough")
+	assert.Error(t, manifestOpts{tlsSecret: "secret"}.validate(), "a tls secret needs an ingress host")
+	assert.Error(t, manifestOpts{controllerReplicas: -1}.validate(), "the number of controller replicas cannot be negative")
+
+	_, err := transformManifests("---\n", manifestOpts{})
+	assert.Error(t, err, "no object found in the release manifests")
+}
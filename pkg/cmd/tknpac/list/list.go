@@ -27,8 +27,18 @@ var (
 	namespaceFlag     = "namespace"
 	useRealTimeFlag   = "use-realtime"
 	noHeadersFlag     = "no-headers"
+	outputFlag        = "output"
 )
 
+// listOutput is the machine readable output of a repository in the list
+// command.
+type listOutput struct {
+	Name      string                        `json:"name"`
+	Namespace string                        `json:"namespace"`
+	URL       string                        `json:"url"`
+	LastRun   *v1alpha1.RepositoryRunStatus `json:"last_run,omitempty"`
+}
+
 func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 	var noheaders, useRealTime, allNamespaces bool
 	var selectors string
@@ -64,6 +74,14 @@ func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 			if err != nil {
 				return err
 			}
+
+			opts.Output, err = cmd.Flags().GetString(outputFlag)
+			if err != nil {
+				return err
+			}
+			if err := cli.ValidateOutputFormat(opts.Output); err != nil {
+				return err
+			}
 			ctx := context.Background()
 			err = run.Clients.NewClients(ctx, &run.Info)
 			if err != nil {
@@ -92,6 +110,9 @@ func Root(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 	cmd.Flags().BoolVar(
 		&noheaders, noHeadersFlag, false, "don't print headers.")
 
+	cmd.Flags().StringP(
+		outputFlag, "o", "", "output format, one of: json, yaml")
+
 	cmd.Flags().StringVarP(&selectors, "selectors", "l",
 		"", "Selector (label query) to filter on, "+
 			"supports '=', "+
@@ -163,6 +184,14 @@ func list(ctx context.Context, cs *params.Run, opts *cli.PacCliOpts, ioStreams *
 		repoStatuses = append(repoStatuses, rs)
 	}
 
+	if opts.Output != "" {
+		output := []listOutput{}
+		for _, rs := range repoStatuses {
+			output = append(output, listOutput{Name: rs.Name, Namespace: rs.Namespace, URL: rs.URL, LastRun: rs.Status})
+		}
+		return cli.PrintObject(ioStreams.Out, output, opts.Output)
+	}
+
 	w := ansiterm.NewTabWriter(ioStreams.Out, 0, 5, 3, ' ', tabwriter.TabIndent)
 	colorScheme := ioStreams.ColorScheme()
 	data := struct {
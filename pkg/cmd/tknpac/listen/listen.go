@@ -0,0 +1,307 @@
+package listen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/git"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+)
+
+const longhelp = `
+
+listen - forward webhook events to a local Pipelines as Code controller
+
+tkn pac listen receives webhook events and forwards them to a controller
+running locally (ie: in kind or minikube with a port-forward), without having
+to expose the controller on the internet or use a service like smee.io.
+
+There are two ways to receive the events:
+
+- With --github-hook-id, the deliveries of an existing GitHub webhook are
+  polled with the GitHub API and every new delivery is replayed to the
+  controller with its original headers and payload. The webhook on GitHub can
+  point to any URL, the deliveries are recorded even when they fail.
+
+- Otherwise a local HTTP server is started on --port and every request it
+  receives is forwarded to the controller, useful when you already have a
+  tunnel to your machine.`
+
+const (
+	defaultControllerURL = "http://localhost:8080"
+	defaultPort          = 8888
+	defaultInterval      = 5 * time.Second
+
+	deliveriesPerPage  = 100
+	maxDeliveriesPages = 10
+)
+
+type listenOpts struct {
+	ioStreams     *cli.IOStreams
+	ghClient      *github.Client
+	httpClient    *http.Client
+	controllerURL string
+	repoOwner     string
+	repoName      string
+	hookID        int64
+	interval      time.Duration
+	port          int
+
+	seenDeliveries map[int64]bool
+}
+
+func Command(ioStreams *cli.IOStreams) *cobra.Command {
+	var repoURL, token, apiURL string
+	lopts := &listenOpts{
+		ioStreams:      ioStreams,
+		httpClient:     http.DefaultClient,
+		seenDeliveries: map[int64]bool{},
+	}
+	cmd := &cobra.Command{
+		Use:   "listen",
+		Long:  longhelp,
+		Short: "Forward webhook events to a local Pipelines as Code controller",
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			if lopts.hookID == 0 {
+				return lopts.serve(ctx)
+			}
+
+			if repoURL == "" {
+				repoURL = git.GetGitInfo("").URL
+			}
+			ownerRepo, err := formatting.GetRepoOwnerFromURL(repoURL)
+			if err != nil {
+				return fmt.Errorf("cannot detect the repository, use the --repository-url flag: %w", err)
+			}
+			split := strings.Split(strings.TrimSuffix(ownerRepo, "/"), "/")
+			if len(split) != 2 {
+				return fmt.Errorf("invalid repository %s, needs to be of format 'org-name/repo-name'", ownerRepo)
+			}
+			lopts.repoOwner, lopts.repoName = split[0], split[1]
+
+			if token == "" {
+				token = os.Getenv("GITHUB_TOKEN")
+			}
+			if token == "" {
+				return fmt.Errorf("a GitHub token is needed to poll the webhook deliveries, use the --github-token flag or the GITHUB_TOKEN environment variable")
+			}
+			if lopts.ghClient, err = newGHClient(ctx, token, apiURL); err != nil {
+				return err
+			}
+			return lopts.poll(ctx)
+		},
+	}
+	cmd.Flags().StringVarP(&lopts.controllerURL, "controller-url", "c", defaultControllerURL,
+		"the URL of the Pipelines as Code controller where to forward the events")
+	cmd.Flags().IntVarP(&lopts.port, "port", "p", defaultPort,
+		"the port to listen to for webhook events when not polling GitHub deliveries")
+	cmd.Flags().Int64Var(&lopts.hookID, "github-hook-id", 0,
+		"the id of the GitHub webhook to poll the deliveries from")
+	cmd.Flags().DurationVar(&lopts.interval, "interval", defaultInterval,
+		"the interval between two polls of the GitHub webhook deliveries")
+	cmd.Flags().StringVar(&repoURL, "repository-url", "",
+		"the URL of the GitHub repository, detected from the current git checkout if not specified")
+	cmd.Flags().StringVar(&token, "github-token", "",
+		"the GitHub token to use to poll the deliveries (default to the GITHUB_TOKEN environment variable)")
+	cmd.Flags().StringVar(&apiURL, "github-api-url", keys.PublicGithubAPIURL,
+		"the GitHub API URL, for GitHub Enterprise")
+	return cmd
+}
+
+func newGHClient(ctx context.Context, token, apiURL string) (*github.Client, error) {
+	client := github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})))
+	if apiURL == "" || apiURL == keys.PublicGithubAPIURL {
+		return client, nil
+	}
+	return client.WithEnterpriseURLs(apiURL, apiURL)
+}
+
+// forward sends the event with its headers to the controller.
+func (l *listenOpts) forward(ctx context.Context, headers http.Header, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.controllerURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot forward event to %s: %w", l.controllerURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("cannot forward event %s to %s: %s", eventName(headers), l.controllerURL, resp.Status)
+	}
+	fmt.Fprintf(l.ioStreams.Out, "%s forwarded event %s to %s: %s\n",
+		time.Now().Format(time.RFC3339), eventName(headers), l.controllerURL, resp.Status)
+	return nil
+}
+
+// eventName returns the event type from the headers sent by the providers.
+func eventName(headers http.Header) string {
+	for _, header := range []string{"X-GitHub-Event", "X-Gitlab-Event", "X-Event-Key", "X-Gitea-Event"} {
+		if value := headers.Get(header); value != "" {
+			return value
+		}
+	}
+	return "unknown"
+}
+
+// serve starts a local http server and forward everything it receives to the controller.
+func (l *listenOpts) serve(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", l.port),
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			payload, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := l.forward(r.Context(), r.Header, payload); err != nil {
+				fmt.Fprintf(l.ioStreams.ErrOut, "%s\n", err.Error())
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}),
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+	fmt.Fprintf(l.ioStreams.Out, "listening for webhook events on port %d, forwarding them to %s\n", l.port, l.controllerURL)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// poll polls the webhook deliveries until the context is done, deliveries
+// that were already there when we started are not forwarded.
+func (l *listenOpts) poll(ctx context.Context) error {
+	if _, err := l.forwardNewDeliveries(ctx, true); err != nil {
+		return err
+	}
+	fmt.Fprintf(l.ioStreams.Out, "polling deliveries of webhook %d on %s/%s every %s, forwarding them to %s\n",
+		l.hookID, l.repoOwner, l.repoName, l.interval, l.controllerURL)
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := l.forwardNewDeliveries(ctx, false); err != nil {
+				fmt.Fprintf(l.ioStreams.ErrOut, "%s\n", err.Error())
+			}
+		}
+	}
+}
+
+// forwardNewDeliveries forwards the deliveries we haven't seen yet, oldest
+// first, and returns how many has been forwarded. When skip is set the
+// deliveries are only marked as seen.
+func (l *listenOpts) forwardNewDeliveries(ctx context.Context, skip bool) (int, error) {
+	deliveries, err := l.listNewDeliveries(ctx, skip)
+	if err != nil {
+		return 0, err
+	}
+	defer l.forgetOldDeliveries(deliveries)
+	forwarded := 0
+	// deliveries are returned newest first
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		id := deliveries[i].GetID()
+		if l.seenDeliveries[id] {
+			continue
+		}
+		if skip {
+			l.seenDeliveries[id] = true
+			continue
+		}
+		// a delivery is only marked as seen once it has been forwarded, a
+		// failure is retried on the next poll
+		delivery, _, err := l.ghClient.Repositories.GetHookDelivery(ctx, l.repoOwner, l.repoName, l.hookID, id)
+		if err != nil {
+			return forwarded, fmt.Errorf("cannot get delivery %d: %w", id, err)
+		}
+		if delivery.Request == nil || delivery.Request.RawPayload == nil {
+			l.seenDeliveries[id] = true
+			continue
+		}
+		headers := http.Header{}
+		for key, value := range delivery.Request.Headers {
+			headers.Set(key, value)
+		}
+		if err := l.forward(ctx, headers, *delivery.Request.RawPayload); err != nil {
+			return forwarded, err
+		}
+		l.seenDeliveries[id] = true
+		forwarded++
+	}
+	return forwarded, nil
+}
+
+// forgetOldDeliveries forgets the seen deliveries older than the listed ones,
+// they are never listed again since the listing stops at the first page with a
+// seen delivery. This keeps the seen deliveries to at most maxDeliveriesPages
+// pages however long we are listening.
+func (l *listenOpts) forgetOldDeliveries(listed []*github.HookDelivery) {
+	ids := make(map[int64]bool, len(listed))
+	for _, delivery := range listed {
+		ids[delivery.GetID()] = true
+	}
+	for id := range l.seenDeliveries {
+		if !ids[id] {
+			delete(l.seenDeliveries, id)
+		}
+	}
+}
+
+// listNewDeliveries lists the deliveries of the webhook, newest first,
+// following the pages until the last delivery seen so none is missed when
+// more deliveries than a page have been received since the last poll. When
+// skip is set only the first page is listed, the older deliveries are never
+// listed again.
+func (l *listenOpts) listNewDeliveries(ctx context.Context, skip bool) ([]*github.HookDelivery, error) {
+	deliveries := []*github.HookDelivery{}
+	opts := &github.ListCursorOptions{PerPage: deliveriesPerPage}
+	for page := 0; page < maxDeliveriesPages; page++ {
+		list, resp, err := l.ghClient.Repositories.ListHookDeliveries(ctx, l.repoOwner, l.repoName, l.hookID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list deliveries of webhook %d: %w", l.hookID, err)
+		}
+		deliveries = append(deliveries, list...)
+		seen := slices.ContainsFunc(list, func(delivery *github.HookDelivery) bool {
+			return l.seenDeliveries[delivery.GetID()]
+		})
+		if skip || seen || resp.Cursor == "" {
+			break
+		}
+		opts.Cursor = resp.Cursor
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,186 @@
+package listen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+
+	tcli "github.com/openshift-pipelines/pipelines-as-code/pkg/test/cli"
+)
+
+func TestEventName(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers http.Header
+		want    string
+	}{
+		{name: "github", headers: http.Header{"X-Github-Event": []string{"push"}}, want: "push"},
+		{name: "gitlab", headers: http.Header{"X-Gitlab-Event": []string{"Merge Request Hook"}}, want: "Merge Request Hook"},
+		{name: "bitbucket", headers: http.Header{"X-Event-Key": []string{"repo:push"}}, want: "repo:push"},
+		{name: "unknown", headers: http.Header{}, want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, eventName(tt.headers), tt.want)
+		})
+	}
+}
+
+func TestForwardNewDeliveries(t *testing.T) {
+	ctx := context.Background()
+	ghClient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+
+	deliveries := `[{"id": 2}, {"id": 1}]`
+	mux.HandleFunc("/repos/owner/repo/hooks/42/deliveries", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, deliveries)
+	})
+	failing := true
+	mux.HandleFunc("/repos/owner/repo/hooks/42/deliveries/4", func(w http.ResponseWriter, _ *http.Request) {
+		if failing {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"id": 4, "request": {"headers": {"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=4"}, "payload": {"delivery": 4}}}`)
+	})
+	for _, id := range []int{1, 2, 3} {
+		mux.HandleFunc(fmt.Sprintf("/repos/owner/repo/hooks/42/deliveries/%d", id), func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(w, `{"id": %d, "request": {"headers": {"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=%d"}, "payload": {"delivery": %d}}}`, id, id, id)
+		})
+	}
+
+	received := []string{}
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, r.Header.Get("X-GitHub-Event"), "push")
+		received = append(received, fmt.Sprintf("%s %s", r.Header.Get("X-Hub-Signature-256"), string(body)))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer controller.Close()
+
+	ioStreams, out := tcli.NewIOStream()
+	lopts := &listenOpts{
+		ioStreams:      ioStreams,
+		ghClient:       ghClient,
+		httpClient:     controller.Client(),
+		controllerURL:  controller.URL,
+		repoOwner:      "owner",
+		repoName:       "repo",
+		hookID:         42,
+		seenDeliveries: map[int64]bool{},
+	}
+
+	// existing deliveries are skipped on startup
+	forwarded, err := lopts.forwardNewDeliveries(ctx, true)
+	assert.NilError(t, err)
+	assert.Equal(t, forwarded, 0)
+	assert.Equal(t, len(received), 0)
+
+	deliveries = `[{"id": 3}, {"id": 2}, {"id": 1}]`
+	forwarded, err = lopts.forwardNewDeliveries(ctx, false)
+	assert.NilError(t, err)
+	assert.Equal(t, forwarded, 1)
+	assert.DeepEqual(t, received, []string{`sha256=3 {"delivery": 3}`})
+	assert.Assert(t, out.String() != "")
+
+	// nothing new
+	forwarded, err = lopts.forwardNewDeliveries(ctx, false)
+	assert.NilError(t, err)
+	assert.Equal(t, forwarded, 0)
+
+	// a delivery which cannot be fetched is retried on the next poll
+	deliveries = `[{"id": 4}, {"id": 3}, {"id": 2}, {"id": 1}]`
+	_, err = lopts.forwardNewDeliveries(ctx, false)
+	assert.ErrorContains(t, err, "cannot get delivery 4")
+	failing = false
+	forwarded, err = lopts.forwardNewDeliveries(ctx, false)
+	assert.NilError(t, err)
+	assert.Equal(t, forwarded, 1)
+	assert.DeepEqual(t, received, []string{`sha256=3 {"delivery": 3}`, `sha256=4 {"delivery": 4}`})
+}
+
+func TestForwardNewDeliveriesPages(t *testing.T) {
+	ctx := context.Background()
+	ghClient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+
+	deliveries := `[{"id": 1}]`
+	mux.HandleFunc("/repos/owner/repo/hooks/42/deliveries", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "older" {
+			fmt.Fprint(w, `[{"id": 2}, {"id": 1}]`)
+			return
+		}
+		if deliveries != `[{"id": 1}]` {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?cursor=older>; rel="next"`, r.URL.Path))
+		}
+		fmt.Fprint(w, deliveries)
+	})
+	for _, id := range []int{1, 2, 3, 4, 5} {
+		id := id
+		mux.HandleFunc(fmt.Sprintf("/repos/owner/repo/hooks/42/deliveries/%d", id), func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(w, `{"id": %d, "request": {"headers": {"X-GitHub-Event": "push"}, "payload": {"delivery": %d}}}`, id, id)
+		})
+	}
+
+	received := []string{}
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer controller.Close()
+
+	ioStreams, _ := tcli.NewIOStream()
+	lopts := &listenOpts{
+		ioStreams:      ioStreams,
+		ghClient:       ghClient,
+		httpClient:     controller.Client(),
+		controllerURL:  controller.URL,
+		repoOwner:      "owner",
+		repoName:       "repo",
+		hookID:         42,
+		seenDeliveries: map[int64]bool{},
+	}
+	_, err := lopts.forwardNewDeliveries(ctx, true)
+	assert.NilError(t, err)
+
+	// the deliveries received since the last poll span two pages
+	deliveries = `[{"id": 4}, {"id": 3}]`
+	forwarded, err := lopts.forwardNewDeliveries(ctx, false)
+	assert.NilError(t, err)
+	assert.Equal(t, forwarded, 3)
+	assert.DeepEqual(t, received, []string{`{"delivery": 2}`, `{"delivery": 3}`, `{"delivery": 4}`})
+
+	// the deliveries older than the listed page are forgotten
+	deliveries = `[{"id": 5}, {"id": 4}]`
+	forwarded, err = lopts.forwardNewDeliveries(ctx, false)
+	assert.NilError(t, err)
+	assert.Equal(t, forwarded, 1)
+	assert.DeepEqual(t, lopts.seenDeliveries, map[int64]bool{4: true, 5: true})
+}
+
+func TestForwardError(t *testing.T) {
+	ioStreams, _ := tcli.NewIOStream()
+	lopts := &listenOpts{
+		ioStreams:     ioStreams,
+		httpClient:    http.DefaultClient,
+		controllerURL: "http://127.0.0.1:0",
+	}
+	err := lopts.forward(context.Background(), http.Header{}, []byte("{}"))
+	assert.ErrorContains(t, err, "cannot forward event to http://127.0.0.1:0")
+
+	// an event refused by the controller is an error
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer controller.Close()
+	lopts.httpClient, lopts.controllerURL = controller.Client(), controller.URL
+	err = lopts.forward(context.Background(), http.Header{"X-Github-Event": []string{"push"}}, []byte("{}"))
+	assert.ErrorContains(t, err, "cannot forward event push to "+controller.URL+": 500 Internal Server Error")
+}
@@ -19,8 +19,10 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/completion"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/git"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/sort"
 	"github.com/spf13/cobra"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
@@ -33,7 +35,14 @@ logs - show the PipelineRun logs attached to a Repository
 
 tkn pac logs will get the logs of a PipelineRun belonging to a Repository.
 
-the PipelineRun needs to exist on the kubernetes cluster to be able to display the logs.`
+the PipelineRun needs to exist on the kubernetes cluster to be able to display the logs.
+
+With --current-branch the Repository and the latest PipelineRun are detected
+from the local git checkout (the commit SHA or the branch of the current HEAD).
+
+Logs can be restricted to some tasks with --task and to some steps with --step,
+the logs are streamed while the PipelineRun is running unless --follow=false
+is specified.`
 
 const (
 	namespaceFlag          = "namespace"
@@ -42,6 +51,10 @@ const (
 	defaultLimit           = -1
 	openWebBrowserFlag     = "web"
 	useLastPipelineRunFlag = "last"
+	followFlag             = "follow"
+	taskFlag               = "task"
+	stepFlag               = "step"
+	currentBranchFlag      = "current-branch"
 )
 
 type logOption struct {
@@ -54,6 +67,10 @@ type logOption struct {
 	limit      int
 	webBrowser bool
 	useLastPR  bool
+	follow     bool
+	tasks      []string
+	steps      []string
+	gitInfo    *git.Info
 }
 
 func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
@@ -100,6 +117,36 @@ func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 				return err
 			}
 
+			follow, err := cmd.Flags().GetBool(followFlag)
+			if err != nil {
+				return err
+			}
+
+			tasks, err := cmd.Flags().GetStringSlice(taskFlag)
+			if err != nil {
+				return err
+			}
+
+			steps, err := cmd.Flags().GetStringSlice(stepFlag)
+			if err != nil {
+				return err
+			}
+			if len(steps) > 0 && len(tasks) != 1 {
+				return fmt.Errorf("--%s needs exactly one --%s to be specified", stepFlag, taskFlag)
+			}
+
+			currentBranch, err := cmd.Flags().GetBool(currentBranchFlag)
+			if err != nil {
+				return err
+			}
+			var gitInfo *git.Info
+			if currentBranch {
+				gitInfo = git.GetGitInfo("")
+				if gitInfo.SHA == "" {
+					return fmt.Errorf("cannot detect a git checkout in the current directory")
+				}
+			}
+
 			tknPath, err := cmd.Flags().GetString(tknPathFlag)
 			if err != nil {
 				return err
@@ -124,6 +171,10 @@ func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 				webBrowser: webBrowser,
 				tknPath:    tknPath,
 				useLastPR:  useLastPR,
+				follow:     follow,
+				tasks:      tasks,
+				steps:      steps,
+				gitInfo:    gitInfo,
 			}
 			return log(ctx, lopts)
 		},
@@ -149,6 +200,18 @@ func Command(run *params.Run, ioStreams *cli.IOStreams) *cobra.Command {
 	cmd.Flags().IntP(
 		limitFlag, "", defaultLimit, "Limit the number of PipelineRun to show (-1 is unlimited)")
 
+	cmd.Flags().BoolP(
+		followFlag, "f", true, "stream the logs while the PipelineRun is running, --follow=false only shows the logs so far")
+
+	cmd.Flags().StringSliceP(
+		taskFlag, "t", []string{}, "only show the logs of these tasks (pipeline task names)")
+
+	cmd.Flags().StringSliceP(
+		stepFlag, "s", []string{}, "only show the logs of these steps, needs a single --task")
+
+	cmd.Flags().BoolP(
+		currentBranchFlag, "B", false, "select the Repository and the latest PipelineRun from the current git checkout")
+
 	return cmd
 }
 
@@ -171,6 +234,9 @@ func getPipelineRunsToRepo(ctx context.Context, lopt *logOption, repoName string
 	if err != nil {
 		return []string{}, err
 	}
+	if lopt.gitInfo != nil {
+		runs.Items = filterPipelineRunsOnGitInfo(runs.Items, lopt.gitInfo)
+	}
 	runslen := len(runs.Items)
 	if runslen > 1 {
 		sort.PipelineRunSortByStartTime(runs.Items)
@@ -196,6 +262,49 @@ func getPipelineRunsToRepo(ctx context.Context, lopt *logOption, repoName string
 	return ret, nil
 }
 
+// filterPipelineRunsOnGitInfo keeps the PipelineRuns that has been run for the
+// SHA of the local checkout or for the local branch, the source branch of the
+// pull request or the pushed branch.
+func filterPipelineRunsOnGitInfo(runs []tektonv1.PipelineRun, gitInfo *git.Info) []tektonv1.PipelineRun {
+	ret := []tektonv1.PipelineRun{}
+	for _, run := range runs {
+		if run.GetLabels()[keys.SHA] == gitInfo.SHA ||
+			formatting.SanitizeBranch(sourceBranch(&run)) == gitInfo.Branch {
+			ret = append(ret, run)
+		}
+	}
+	return ret
+}
+
+// sourceBranch returns the source branch of the PipelineRun, the PipelineRuns
+// created before the source branch annotation only have the pushed branch.
+func sourceBranch(run *tektonv1.PipelineRun) string {
+	annotations := run.GetAnnotations()
+	if branch, ok := annotations[keys.SourceBranch]; ok {
+		return branch
+	}
+	if strings.EqualFold(annotations[keys.EventType], triggertype.Push.String()) {
+		return annotations[keys.Branch]
+	}
+	return ""
+}
+
+// getRepoFromGitInfo returns the Repository matching the url of the local git
+// checkout, nil if there is none.
+func getRepoFromGitInfo(ctx context.Context, lo *logOption) (*v1alpha1.Repository, error) {
+	repositories, err := lo.cs.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(lo.cs.Info.Kube.Namespace).List(ctx,
+		metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range repositories.Items {
+		if strings.TrimSuffix(repositories.Items[i].Spec.URL, "/") == strings.TrimSuffix(lo.gitInfo.URL, "/") {
+			return &repositories.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
 func log(ctx context.Context, lo *logOption) error {
 	var repository *v1alpha1.Repository
 	var err error
@@ -204,13 +313,19 @@ func log(ctx context.Context, lo *logOption) error {
 		lo.cs.Info.Kube.Namespace = lo.opts.Namespace
 	}
 
-	if lo.repoName != "" {
+	if lo.repoName == "" && lo.gitInfo != nil {
+		if repository, err = getRepoFromGitInfo(ctx, lo); err != nil {
+			return err
+		}
+	}
+
+	if repository == nil && lo.repoName != "" {
 		repository, err = lo.cs.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(lo.cs.Info.Kube.Namespace).Get(ctx,
 			lo.repoName, metav1.GetOptions{})
 		if err != nil {
 			return err
 		}
-	} else {
+	} else if repository == nil {
 		repository, err = prompt.SelectRepo(ctx, lo.cs, lo.cs.Info.Kube.Namespace)
 		if err != nil {
 			return err
@@ -222,10 +337,14 @@ func log(ctx context.Context, lo *logOption) error {
 		return err
 	}
 	if len(allprs) == 0 {
+		if lo.gitInfo != nil {
+			return fmt.Errorf("cannot detect pipelineruns belonging to repository: %s for branch %s and SHA %s",
+				repository.GetName(), lo.gitInfo.Branch, formatting.ShortSHA(lo.gitInfo.SHA))
+		}
 		return fmt.Errorf("cannot detect pipelineruns belonging to repository: %s", repository.GetName())
 	}
 	var replyString string
-	if lo.useLastPR || len(allprs) == 1 {
+	if lo.useLastPR || lo.gitInfo != nil || len(allprs) == 1 {
 		replyString = allprs[0]
 	} else {
 		if err := prompt.SurveyAskOne(&survey.Select{
@@ -240,7 +359,46 @@ func log(ctx context.Context, lo *logOption) error {
 	if lo.webBrowser {
 		return showLogsWithWebConsole(lo, replyName)
 	}
-	return showlogswithtkn(lo.tknPath, replyName, lo.cs.Info.Kube.Namespace)
+	args, err := tknLogsArgs(ctx, lo, replyName)
+	if err != nil {
+		return err
+	}
+	return showlogswithtkn(lo.tknPath, args)
+}
+
+// tknLogsArgs returns the arguments to pass to tkn to show the logs, when
+// filtering on steps we need to target directly the TaskRun of the task.
+func tknLogsArgs(ctx context.Context, lo *logOption, prName string) ([]string, error) {
+	ns := lo.cs.Info.Kube.Namespace
+	args := []string{"pr", "logs", "-n", ns, prName}
+	if len(lo.steps) > 0 {
+		pr, err := lo.cs.Clients.Tekton.TektonV1().PipelineRuns(ns).Get(ctx, prName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		trName := ""
+		for _, child := range pr.Status.ChildReferences {
+			if child.PipelineTaskName == lo.tasks[0] {
+				trName = child.Name
+				break
+			}
+		}
+		if trName == "" {
+			return nil, fmt.Errorf("cannot find task %s in pipelinerun %s", lo.tasks[0], prName)
+		}
+		args = []string{"tr", "logs", "-n", ns, trName}
+		for _, step := range lo.steps {
+			args = append(args, "-s", step)
+		}
+	} else {
+		for _, task := range lo.tasks {
+			args = append(args, "-t", task)
+		}
+	}
+	if lo.follow {
+		args = append(args, "-f")
+	}
+	return args, nil
 }
 
 func showLogsWithWebConsole(lo *logOption, pr string) error {
@@ -257,9 +415,9 @@ func showLogsWithWebConsole(lo *logOption, pr string) error {
 	return browser.OpenWebBrowser(lo.cs.Clients.ConsoleUI().DetailURL(prObj))
 }
 
-func showlogswithtkn(tknPath, pr, ns string) error {
+func showlogswithtkn(tknPath string, args []string) error {
 	//nolint: gosec
-	if err := syscall.Exec(tknPath, []string{tknPath, "pr", "logs", "-f", "-n", ns, pr}, os.Environ()); err != nil {
+	if err := syscall.Exec(tknPath, append([]string{tknPath}, args...), os.Environ()); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Command finished with error: %v", err)
 		os.Exit(127)
 	}
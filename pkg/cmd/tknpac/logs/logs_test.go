@@ -9,6 +9,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/git"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
@@ -144,3 +145,108 @@ func TestLogs(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterPipelineRunsOnGitInfo(t *testing.T) {
+	runs := []tektonv1.PipelineRun{
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "pr-sha",
+			Labels:      map[string]string{keys.SHA: "abcd"},
+			Annotations: map[string]string{keys.Branch: "main", keys.SourceBranch: "other"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "pr-source-branch",
+			Labels:      map[string]string{keys.SHA: "efgh"},
+			Annotations: map[string]string{keys.Branch: "main", keys.SourceBranch: "refs/heads/feature"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "pr-target-branch",
+			Labels:      map[string]string{keys.SHA: "ijkl"},
+			Annotations: map[string]string{keys.Branch: "feature", keys.SourceBranch: "other"},
+		}},
+		// created before the source branch annotation
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "push-without-source-branch",
+			Labels:      map[string]string{keys.SHA: "mnop"},
+			Annotations: map[string]string{keys.Branch: "refs/heads/feature", keys.EventType: "push"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "pull-request-without-source-branch",
+			Labels:      map[string]string{keys.SHA: "qrst"},
+			Annotations: map[string]string{keys.Branch: "feature", keys.EventType: "pull_request"},
+		}},
+	}
+	got := filterPipelineRunsOnGitInfo(runs, &git.Info{SHA: "abcd", Branch: "feature"})
+	assert.Equal(t, len(got), 3)
+	assert.Equal(t, got[0].GetName(), "pr-sha")
+	assert.Equal(t, got[1].GetName(), "pr-source-branch")
+	assert.Equal(t, got[2].GetName(), "push-without-source-branch")
+}
+
+func TestTknLogsArgs(t *testing.T) {
+	ns := "ns"
+	prWithChild := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: ns},
+		Status: tektonv1.PipelineRunStatus{
+			PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+				ChildReferences: []tektonv1.ChildStatusReference{
+					{Name: "pr-build", PipelineTaskName: "build"},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		follow  bool
+		tasks   []string
+		steps   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: []string{"pr", "logs", "-n", ns, "pr"},
+		},
+		{
+			name:   "follow and tasks",
+			follow: true,
+			tasks:  []string{"build", "test"},
+			want:   []string{"pr", "logs", "-n", ns, "pr", "-t", "build", "-t", "test", "-f"},
+		},
+		{
+			name:  "steps",
+			tasks: []string{"build"},
+			steps: []string{"compile"},
+			want:  []string{"tr", "logs", "-n", ns, "pr-build", "-s", "compile"},
+		},
+		{
+			name:    "steps unknown task",
+			tasks:   []string{"deploy"},
+			steps:   []string{"compile"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+				PipelineRuns: []*tektonv1.PipelineRun{prWithChild},
+			})
+			lopts := &logOption{
+				cs: &params.Run{
+					Clients: clients.Clients{Tekton: stdata.Pipeline},
+					Info:    info.Info{Kube: &info.KubeOpts{Namespace: ns}},
+				},
+				follow: tt.follow,
+				tasks:  tt.tasks,
+				steps:  tt.steps,
+			}
+			got, err := tknLogsArgs(ctx, lopts, "pr")
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
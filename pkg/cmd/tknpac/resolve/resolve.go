@@ -16,6 +16,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/templates"
@@ -58,7 +59,7 @@ You can specify multiple template files to combine :
 
 %s pac resolve -f .tekton/pull-request.yaml -f task/referenced.yaml
 
-or a directory where it will get all the files ending by .yaml  :
+or a directory where it will get all the files ending by .yaml or .yml :
 
 %s pac resolve -f .tekton/
 
@@ -269,7 +270,7 @@ func listAllYamls(paths []string) []string {
 			continue
 		}
 		err := filepath.Walk(path, func(fname string, _ os.FileInfo, _ error) error {
-			if filepath.Ext(fname) == ".yaml" {
+			if provider.IsPipelineRunFile(nil, fname) {
 				ret = append(ret, fname)
 			}
 			return nil
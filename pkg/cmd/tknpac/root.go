@@ -3,13 +3,19 @@ package tknpac
 import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/bootstrap"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/cancel"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/cel"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/checktoken"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/completion"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/create"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/deleterepo"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/describe"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/doctor"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/generate"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/install"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/list"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/listen"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/logs"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/resolve"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/cmd/tknpac/version"
@@ -33,16 +39,24 @@ func Root(clients *params.Run) *cobra.Command {
 	ioStreams := cli.NewIOStreams()
 
 	cmd.AddCommand(version.Command(ioStreams))
+	cmd.AddCommand(version.SelfUpdateCommand(ioStreams))
 	cmd.AddCommand(info.Root(clients, ioStreams))
 	cmd.AddCommand(create.Root(clients, ioStreams))
 	cmd.AddCommand(list.Root(clients, ioStreams))
 	cmd.AddCommand(deleterepo.Root(clients, ioStreams))
 	cmd.AddCommand(describe.Root(clients, ioStreams))
 	cmd.AddCommand(logs.Command(clients, ioStreams))
+	cmd.AddCommand(cancel.Command(clients, ioStreams))
 	cmd.AddCommand(resolve.Command(clients, ioStreams))
 	cmd.AddCommand(completion.Command())
 	cmd.AddCommand(bootstrap.Command(clients, ioStreams))
+	cmd.AddCommand(install.Command(clients, ioStreams))
+	cmd.AddCommand(install.UpgradeCommand(clients, ioStreams))
 	cmd.AddCommand(generate.Command(clients, ioStreams))
 	cmd.AddCommand(webhook.Root(clients, ioStreams))
+	cmd.AddCommand(checktoken.Command(clients, ioStreams))
+	cmd.AddCommand(doctor.Command(clients, ioStreams))
+	cmd.AddCommand(listen.Command(ioStreams))
+	cmd.AddCommand(cel.Command(ioStreams))
 	return cmd
 }
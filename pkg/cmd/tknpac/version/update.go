@@ -0,0 +1,346 @@
+package version
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/spf13/cobra"
+)
+
+// PublicKey is the base64 encoded ed25519 public key verifying the signature
+// of the checksums of the releases, it is set at build time by the release.
+var PublicKey = ""
+
+const (
+	defaultAPIURL      = "https://api.github.com/repos/openshift-pipelines/pipelines-as-code"
+	defaultDownloadURL = "https://github.com/openshift-pipelines/pipelines-as-code/releases/download"
+	checksumsFile      = "checksums.txt"
+	signatureFile      = "checksums.txt.sig"
+	binaryName         = "tkn-pac"
+	httpTimeout        = 5 * time.Minute
+	maxBinarySize      = 512 << 20
+)
+
+type updateOpts struct {
+	ioStreams   *cli.IOStreams
+	version     string
+	apiURL      string
+	downloadURL string
+	publicKey   string
+	executable  string
+	goos        string
+	goarch      string
+	client      *http.Client
+}
+
+func newUpdateOpts(ioStreams *cli.IOStreams) *updateOpts {
+	return &updateOpts{
+		ioStreams:   ioStreams,
+		apiURL:      defaultAPIURL,
+		downloadURL: defaultDownloadURL,
+		publicKey:   PublicKey,
+		goos:        runtime.GOOS,
+		goarch:      runtime.GOARCH,
+		client:      &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func SelfUpdateCommand(ioStreams *cli.IOStreams) *cobra.Command {
+	opts := newUpdateOpts(ioStreams)
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: fmt.Sprintf("Update %s pac to the latest or to a given release", settings.TknBinaryName),
+		Long: `Download a release of tkn-pac for the current operating system and architecture,
+verify the signature of the checksums of the release and the checksum of the
+archive and replace the running binary with the one of the archive.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return selfUpdate(context.Background(), opts)
+		},
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+	}
+	cmd.Flags().StringVar(&opts.version, "version", "", "The release to update to, the latest one by default")
+	return cmd
+}
+
+// latestRelease returns the version of the latest release, without its v
+// prefix.
+func latestRelease(ctx context.Context, opts *updateOpts) (string, error) {
+	body, err := download(ctx, opts.client, opts.apiURL+"/releases/latest")
+	if err != nil {
+		return "", fmt.Errorf("cannot get the latest release: %w", err)
+	}
+	release := struct {
+		TagName string `json:"tag_name"`
+	}{}
+	if err := json.Unmarshal(body, &release); err != nil {
+		return "", fmt.Errorf("cannot parse the latest release: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("the latest release has no tag")
+	}
+	return strings.TrimPrefix(release.TagName, "v"), nil
+}
+
+// checkVersion prints whether a release newer than the current version is
+// available.
+func checkVersion(ctx context.Context, opts *updateOpts, current string) error {
+	latest, err := latestRelease(ctx, opts)
+	if err != nil {
+		return err
+	}
+	current = strings.TrimPrefix(strings.TrimSpace(current), "v")
+	if !isRelease(current) {
+		fmt.Fprintf(opts.ioStreams.Out, "%s is a development build, the latest release is %s\n", current, latest)
+		return nil
+	}
+	if compareVersions(latest, current) > 0 {
+		fmt.Fprintf(opts.ioStreams.Out, "A new version %s is available, you are running %s, update with \"%s pac self-update\"\n",
+			latest, current, settings.TknBinaryName)
+		return nil
+	}
+	fmt.Fprintf(opts.ioStreams.Out, "%s is the latest version\n", current)
+	return nil
+}
+
+func selfUpdate(ctx context.Context, opts *updateOpts) error {
+	if opts.publicKey == "" {
+		return fmt.Errorf("this build of %s has no key to verify the releases, update it with the tool it has been installed with", binaryName)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(opts.publicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key to verify the releases")
+	}
+
+	target := strings.TrimPrefix(opts.version, "v")
+	if target == "" {
+		if target, err = latestRelease(ctx, opts); err != nil {
+			return err
+		}
+	}
+	baseURL := fmt.Sprintf("%s/v%s", opts.downloadURL, target)
+
+	checksums, err := download(ctx, opts.client, baseURL+"/"+checksumsFile)
+	if err != nil {
+		return fmt.Errorf("cannot download the checksums of %s: %w", target, err)
+	}
+	signature, err := download(ctx, opts.client, baseURL+"/"+signatureFile)
+	if err != nil {
+		return fmt.Errorf("cannot download the signature of the checksums of %s: %w", target, err)
+	}
+	if !ed25519.Verify(publicKey, checksums, signature) {
+		return fmt.Errorf("the signature of the checksums of %s is invalid", target)
+	}
+
+	archive := archiveName(target, opts.goos, opts.goarch)
+	sum, err := findChecksum(checksums, archive)
+	if err != nil {
+		return err
+	}
+	data, err := download(ctx, opts.client, baseURL+"/"+archive)
+	if err != nil {
+		return fmt.Errorf("cannot download %s: %w", archive, err)
+	}
+	if got := sha256.Sum256(data); hex.EncodeToString(got[:]) != sum {
+		return fmt.Errorf("the checksum of %s does not match the signed checksums", archive)
+	}
+
+	binary, err := extractBinary(archive, data, opts.goos)
+	if err != nil {
+		return err
+	}
+	executable := opts.executable
+	if executable == "" {
+		if executable, err = os.Executable(); err != nil {
+			return err
+		}
+		if executable, err = filepath.EvalSymlinks(executable); err != nil {
+			return err
+		}
+	}
+	if err := replaceBinary(executable, binary, opts.goos); err != nil {
+		return fmt.Errorf("cannot replace %s: %w", executable, err)
+	}
+	fmt.Fprintf(opts.ioStreams.Out, "%s has been updated to %s\n", executable, target)
+	return nil
+}
+
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBinarySize))
+}
+
+// archiveName returns the name of the archive of a release, as named by
+// goreleaser: darwin has a single universal binary, x86_64 is the name of
+// amd64 and windows uses zip.
+func archiveName(target, goos, goarch string) string {
+	arch := goarch
+	switch {
+	case goos == "darwin":
+		arch = "all"
+	case goarch == "amd64":
+		arch = "x86_64"
+	}
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("%s_%s_%s_%s.%s", binaryName, target, goos, arch, ext)
+}
+
+func findChecksum(checksums []byte, archive string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == archive {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("there is no release of %s for this platform", archive)
+}
+
+func extractBinary(archive string, data []byte, goos string) ([]byte, error) {
+	name := binaryName
+	if goos == "windows" {
+		name += ".exe"
+	}
+	if strings.HasSuffix(archive, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if filepath.Base(f.Name) != name {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(io.LimitReader(rc, maxBinarySize))
+		}
+		return nil, fmt.Errorf("%s has no %s binary", archive, name)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s has no %s binary", archive, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == name {
+			return io.ReadAll(io.LimitReader(tr, maxBinarySize))
+		}
+	}
+}
+
+// replaceBinary writes the new binary next to the executable and renames it
+// over the executable, a running executable cannot be overwritten on windows
+// so it is moved away first.
+func replaceBinary(executable string, binary []byte, goos string) error {
+	info, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(executable), "."+filepath.Base(executable)+".new-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if goos == "windows" {
+		old := executable + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(executable, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), executable); err != nil {
+			_ = os.Rename(old, executable)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmp.Name(), executable)
+}
+
+// isRelease returns true when the version is a dotted version number and not
+// a development build like nightly.
+func isRelease(v string) bool {
+	for _, n := range strings.Split(v, ".") {
+		if _, err := strconv.Atoi(n); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// compareVersions compares two dotted versions number by number.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
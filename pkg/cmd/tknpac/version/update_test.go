@@ -0,0 +1,224 @@
+package version
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/cli"
+	"gotest.tools/v3/assert"
+)
+
+func makeTarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	assert.NilError(t, tw.WriteHeader(&tar.Header{Name: "LICENSE", Mode: 0o644, Size: 2, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("OK"))
+	assert.NilError(t, err)
+	assert.NilError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write(content)
+	assert.NilError(t, err)
+	assert.NilError(t, tw.Close())
+	assert.NilError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	w, err := zw.Create(name)
+	assert.NilError(t, err)
+	_, err = w.Write(content)
+	assert.NilError(t, err)
+	assert.NilError(t, zw.Close())
+	return buf.Bytes()
+}
+
+type release struct {
+	files map[string][]byte
+}
+
+func newRelease(t *testing.T, privateKey ed25519.PrivateKey, archives map[string][]byte) *release {
+	t.Helper()
+	checksums := &bytes.Buffer{}
+	files := map[string][]byte{}
+	for name, data := range archives {
+		fmt.Fprintf(checksums, "%x  %s\n", sha256.Sum256(data), name)
+		files[name] = data
+	}
+	files[checksumsFile] = checksums.Bytes()
+	files[signatureFile] = ed25519.Sign(privateKey, checksums.Bytes())
+	return &release{files: files}
+}
+
+func serve(t *testing.T, latest string, r *release) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/releases/latest", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": "%s"}`, latest)
+	})
+	mux.HandleFunc("/download/"+latest+"/", func(w http.ResponseWriter, req *http.Request) {
+		data, ok := r.files[filepath.Base(req.URL.Path)]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_, _ = w.Write(data)
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		want    string
+	}{
+		{name: "newer release", current: "0.9.1", want: "A new version 0.10.0 is available, you are running 0.9.1"},
+		{name: "up to date", current: "0.10.0\n", want: "0.10.0 is the latest version"},
+		{name: "development build", current: "nightly", want: "nightly is a development build, the latest release is 0.10.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := serve(t, "v0.10.0", &release{})
+			out := &bytes.Buffer{}
+			opts := newUpdateOpts(&cli.IOStreams{In: io.NopCloser(&bytes.Buffer{}), Out: out, ErrOut: &bytes.Buffer{}})
+			opts.apiURL = ts.URL + "/api"
+			assert.NilError(t, checkVersion(context.Background(), opts, tt.current))
+			assert.Assert(t, bytes.Contains(out.Bytes(), []byte(tt.want)), out.String())
+		})
+	}
+}
+
+func TestSelfUpdate(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+	newBinary := []byte("new binary")
+
+	tests := []struct {
+		name      string
+		goos      string
+		goarch    string
+		publicKey string
+		release   func() *release
+		wantErr   string
+	}{
+		{
+			name:   "linux arm64",
+			goos:   "linux",
+			goarch: "arm64",
+			release: func() *release {
+				return newRelease(t, privateKey, map[string][]byte{"tkn-pac_0.10.0_linux_arm64.tar.gz": makeTarGz(t, "tkn-pac", newBinary)})
+			},
+		},
+		{
+			name:   "windows amd64",
+			goos:   "windows",
+			goarch: "amd64",
+			release: func() *release {
+				return newRelease(t, privateKey, map[string][]byte{"tkn-pac_0.10.0_windows_x86_64.zip": makeZip(t, "tkn-pac.exe", newBinary)})
+			},
+		},
+		{
+			name:   "darwin universal binary",
+			goos:   "darwin",
+			goarch: "arm64",
+			release: func() *release {
+				return newRelease(t, privateKey, map[string][]byte{"tkn-pac_0.10.0_darwin_all.tar.gz": makeTarGz(t, "tkn-pac", newBinary)})
+			},
+		},
+		{
+			name:   "invalid signature",
+			goos:   "linux",
+			goarch: "amd64",
+			release: func() *release {
+				return newRelease(t, otherKey, map[string][]byte{"tkn-pac_0.10.0_linux_x86_64.tar.gz": makeTarGz(t, "tkn-pac", newBinary)})
+			},
+			wantErr: "the signature of the checksums of 0.10.0 is invalid",
+		},
+		{
+			name:   "tampered archive",
+			goos:   "linux",
+			goarch: "amd64",
+			release: func() *release {
+				r := newRelease(t, privateKey, map[string][]byte{"tkn-pac_0.10.0_linux_x86_64.tar.gz": makeTarGz(t, "tkn-pac", newBinary)})
+				r.files["tkn-pac_0.10.0_linux_x86_64.tar.gz"] = makeTarGz(t, "tkn-pac", []byte("evil"))
+				return r
+			},
+			wantErr: "does not match the signed checksums",
+		},
+		{
+			name:   "no release for the platform",
+			goos:   "linux",
+			goarch: "riscv64",
+			release: func() *release {
+				return newRelease(t, privateKey, map[string][]byte{"tkn-pac_0.10.0_linux_x86_64.tar.gz": makeTarGz(t, "tkn-pac", newBinary)})
+			},
+			wantErr: "there is no release of tkn-pac_0.10.0_linux_riscv64.tar.gz",
+		},
+		{
+			name:      "no public key",
+			publicKey: "-",
+			release:   func() *release { return &release{} },
+			wantErr:   "has no key to verify the releases",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := serve(t, "v0.10.0", tt.release())
+			executable := filepath.Join(t.TempDir(), "tkn-pac")
+			assert.NilError(t, os.WriteFile(executable, []byte("old binary"), 0o755))
+
+			out := &bytes.Buffer{}
+			opts := newUpdateOpts(&cli.IOStreams{In: io.NopCloser(&bytes.Buffer{}), Out: out, ErrOut: &bytes.Buffer{}})
+			opts.apiURL = ts.URL + "/api"
+			opts.downloadURL = ts.URL + "/download"
+			opts.publicKey = base64.StdEncoding.EncodeToString(publicKey)
+			if tt.publicKey == "-" {
+				opts.publicKey = ""
+			}
+			opts.executable = executable
+			opts.goos, opts.goarch = tt.goos, tt.goarch
+
+			err := selfUpdate(context.Background(), opts)
+			got, rerr := os.ReadFile(executable)
+			assert.NilError(t, rerr)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Equal(t, string(got), "old binary")
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, string(got), string(newBinary))
+			info, err := os.Stat(executable)
+			assert.NilError(t, err)
+			assert.Equal(t, info.Mode().Perm(), os.FileMode(0o755))
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, compareVersions("0.10.0", "0.9.1"), 1)
+	assert.Equal(t, compareVersions("0.9", "0.9.0"), 0)
+	assert.Equal(t, compareVersions("0.9.0", "0.21.3"), -1)
+}
@@ -1,6 +1,7 @@
 package version
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -11,15 +12,22 @@ import (
 )
 
 func Command(ioStreams *cli.IOStreams) *cobra.Command {
+	var check bool
+	opts := newUpdateOpts(ioStreams)
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: fmt.Sprintf("Print %s pac version", settings.TknBinaryName),
-		Run: func(_ *cobra.Command, _ []string) {
+		RunE: func(_ *cobra.Command, _ []string) error {
 			fmt.Fprintln(ioStreams.Out, strings.TrimSpace(version.Version))
+			if check {
+				return checkVersion(context.Background(), opts, version.Version)
+			}
+			return nil
 		},
 		Annotations: map[string]string{
 			"commandType": "main",
 		},
 	}
+	cmd.Flags().BoolVar(&check, "check", false, "Check if a newer release is available")
 	return cmd
 }
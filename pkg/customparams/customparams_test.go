@@ -116,6 +116,7 @@ func TestProcessTemplates(t *testing.T) {
 	// foo is never set anywhere so will be skipped
 	// hello is a custom params and should be overridden as well with value
 	triggerCommentArgs := `/test foobar event_type=push foo="bar" hello="\"yolo\""`
+	fullHistory, noSubmodules := 0, false
 	tests := []struct {
 		name               string
 		event              *info.Event
@@ -156,12 +157,30 @@ func TestProcessTemplates(t *testing.T) {
 				"target_branch":         "",
 				"target_namespace":      "",
 				"trigger_comment":       "",
+				"tag_message":           "",
+				"signature_status":      "",
+				"signature_key_id":      "",
 			},
 			repository: &v1alpha1.Repository{
 				Spec: v1alpha1.RepositorySpec{},
 			},
 			incomingPayload: `{"params":{"the_best_superhero_is":"superman"}}`,
 		},
+		{
+			name: "params/git clone hints",
+			expected: map[string]string{
+				"git_clone_depth":      "0",
+				"git_clone_submodules": "false",
+				"git_clone_lfs":        "false",
+				"params":               "batman",
+			},
+			repository: &v1alpha1.Repository{
+				Spec: v1alpha1.RepositorySpec{
+					Params:   &[]v1alpha1.Params{{Name: "params", Value: "batman"}},
+					Settings: &v1alpha1.Settings{GitClone: &v1alpha1.GitClone{Depth: &fullHistory, Submodules: &noSubmodules}},
+				},
+			},
+		},
 		{
 			name:     "params/added_from_incoming_webhook_override",
 			expected: map[string]string{"the_best_superhero_is": "you"},
@@ -487,6 +506,12 @@ func TestProcessTemplates(t *testing.T) {
 				assert.NilError(t, err)
 			}
 			if len(tt.expected) > 0 {
+				// the git clone hints are always set
+				for k, v := range makeGitCloneParams(repo.Spec.Settings) {
+					if _, ok := tt.expected[k]; !ok {
+						tt.expected[k] = v
+					}
+				}
 				assert.DeepEqual(t, tt.expected, ret)
 			}
 			if tt.expectedLogSnippet != "" {
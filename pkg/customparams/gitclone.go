@@ -0,0 +1,36 @@
+package customparams
+
+import (
+	"strconv"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+)
+
+// the defaults of the git-clone task of the Tekton catalog.
+const (
+	defaultGitCloneDepth      = 1
+	defaultGitCloneSubmodules = true
+	defaultGitCloneLFS        = false
+)
+
+// makeGitCloneParams returns the git_clone_* standard params from the
+// git_clone settings of the Repository.
+func makeGitCloneParams(settings *v1alpha1.Settings) map[string]string {
+	depth, submodules, lfs := defaultGitCloneDepth, defaultGitCloneSubmodules, defaultGitCloneLFS
+	if settings != nil && settings.GitClone != nil {
+		if settings.GitClone.Depth != nil {
+			depth = *settings.GitClone.Depth
+		}
+		if settings.GitClone.Submodules != nil {
+			submodules = *settings.GitClone.Submodules
+		}
+		if settings.GitClone.LFS != nil {
+			lfs = *settings.GitClone.LFS
+		}
+	}
+	return map[string]string{
+		"git_clone_depth":      strconv.Itoa(depth),
+		"git_clone_submodules": strconv.FormatBool(submodules),
+		"git_clone_lfs":        strconv.FormatBool(lfs),
+	}
+}
@@ -32,6 +32,7 @@ func (p *CustomParams) makeStandardParamsFromEvent(ctx context.Context) (map[str
 	}
 	changedFiles := p.getChangedFiles(ctx)
 	triggerCommentAsSingleLine := strings.ReplaceAll(p.event.TriggerComment, "\n", "\\n")
+	tagMessageAsSingleLine := strings.ReplaceAll(strings.TrimSpace(p.event.TagMessage), "\n", "\\n")
 
 	return map[string]string{
 			"revision":         p.event.SHA,
@@ -45,6 +46,9 @@ func (p *CustomParams) makeStandardParamsFromEvent(ctx context.Context) (map[str
 			"target_namespace": p.repo.GetNamespace(),
 			"event_type":       p.event.EventType,
 			"trigger_comment":  triggerCommentAsSingleLine,
+			"tag_message":      tagMessageAsSingleLine,
+			"signature_status": p.event.SHASignature.Status,
+			"signature_key_id": p.event.SHASignature.KeyID,
 		}, map[string]interface{}{
 			"all":      changedFiles.All,
 			"added":    changedFiles.Added,
@@ -23,6 +23,8 @@ func TestMakeStandardParamsFromEvent(t *testing.T) {
 		URL:            "https://paris.com",
 		HeadURL:        "https://india.com",
 		TriggerComment: "/test me\nHelp me obiwan kenobi",
+		TagMessage:     "Release 1.0\n\nThe first one\n",
+		SHASignature:   info.CommitSignature{Status: info.SignatureVerified, KeyID: "6A0C91B48753C58C"},
 	}
 
 	result := map[string]string{
@@ -37,6 +39,9 @@ func TestMakeStandardParamsFromEvent(t *testing.T) {
 		"target_branch":    "main",
 		"target_namespace": "myns",
 		"trigger_comment":  "/test me\\nHelp me obiwan kenobi",
+		"tag_message":      "Release 1.0\\n\\nThe first one",
+		"signature_status": "verified",
+		"signature_key_id": "6A0C91B48753C58C",
 	}
 
 	repo := &v1alpha1.Repository{
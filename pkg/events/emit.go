@@ -2,18 +2,41 @@ package events
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/google/go-github/v61/github"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/xanzy/go-gitlab"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	// ReasonTokenInvalid is the reason of the events when the git provider
+	// rejected the token.
+	ReasonTokenInvalid = "RepositoryTokenInvalid"
+	// ReasonRateLimited is the reason of the events when the git provider
+	// rate limited the requests.
+	ReasonRateLimited = "RepositoryRateLimited"
+
+	// maxEventObjectName keeps the name of the events under the limit of the
+	// names of the Kubernetes objects.
+	maxEventObjectName = 200
+)
+
 func NewEventEmitter(client kubernetes.Interface, logger *zap.SugaredLogger) *EventEmitter {
 	return &EventEmitter{
 		client: client,
@@ -32,12 +55,21 @@ func (e *EventEmitter) SetLogger(logger *zap.SugaredLogger) {
 
 func (e *EventEmitter) EmitMessage(repo *v1alpha1.Repository, loggerLevel zapcore.Level, reason, message string) {
 	if repo != nil {
-		event := makeEvent(repo, loggerLevel, reason, message)
-		if _, err := e.client.CoreV1().Events(event.Namespace).Create(context.Background(), event, metav1.CreateOptions{}); err != nil {
-			e.logger.Infof("Cannot create event: %s", err.Error())
-		}
+		e.recordEvent(makeEvent(repo, loggerLevel, reason, message))
+	}
+	e.log(loggerLevel, message)
+}
+
+// EmitPipelineRunMessage records the event on the PipelineRun, for the
+// milestones and the failures of a PipelineRun to show up when describing it.
+func (e *EventEmitter) EmitPipelineRunMessage(pr *tektonv1.PipelineRun, loggerLevel zapcore.Level, reason, message string) {
+	if pr != nil {
+		e.recordEvent(makePipelineRunEvent(pr, loggerLevel, reason, message))
 	}
+	e.log(loggerLevel, message)
+}
 
+func (e *EventEmitter) log(loggerLevel zapcore.Level, message string) {
 	//nolint
 	switch loggerLevel {
 	case zapcore.DebugLevel:
@@ -51,35 +83,136 @@ func (e *EventEmitter) EmitMessage(repo *v1alpha1.Repository, loggerLevel zapcor
 	}
 }
 
-func makeEvent(repo *v1alpha1.Repository, loggerLevel zapcore.Level, reason, message string) *v1.Event {
+// recordEvent aggregates the events of an object by reason: the name of the
+// event is derived from the object and the reason, a new occurrence updates
+// the message, the count and the last timestamp of the existing event instead
+// of creating another one.
+func (e *EventEmitter) recordEvent(event *v1.Event) {
+	ctx := context.Background()
+	events := e.client.CoreV1().Events(event.Namespace)
+	existing, err := events.Get(ctx, event.Name, metav1.GetOptions{})
+	if err == nil {
+		existing.Count++
+		existing.Message = event.Message
+		existing.LastTimestamp = event.LastTimestamp
+		if _, err = events.Update(ctx, existing, metav1.UpdateOptions{}); err == nil {
+			return
+		}
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		e.logger.Infof("Cannot record event: %s", err.Error())
+		return
+	}
+	if _, err := events.Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		e.logger.Infof("Cannot create event: %s", err.Error())
+	}
+}
+
+// eventName returns the name of the aggregated event of the reason on the
+// object.
+func eventName(involved v1.ObjectReference, eventType, reason string) string {
+	h := fnv.New64a()
+	for _, s := range []string{involved.Kind, involved.Namespace, involved.Name, string(involved.UID), eventType, reason} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	name := involved.Name
+	if len(name) > maxEventObjectName {
+		name = name[:maxEventObjectName]
+	}
+	return fmt.Sprintf("%s.%x", name, h.Sum64())
+}
+
+func newEvent(involved v1.ObjectReference, repoName string, loggerLevel zapcore.Level, reason, message string) *v1.Event {
+	now := metav1.NewTime(time.Now())
+	eventType := v1.EventTypeWarning
+	if loggerLevel == zap.InfoLevel {
+		eventType = v1.EventTypeNormal
+	}
 	event := &v1.Event{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: repo.Name + "-",
-			Namespace:    repo.Namespace,
-			Labels: map[string]string{
-				keys.Repository: formatting.CleanValueKubernetes(repo.Name),
-			},
-			Annotations: map[string]string{
-				keys.Repository: repo.Name,
-			},
-		},
-		Message: message,
-		Reason:  reason,
-		Type:    v1.EventTypeWarning,
-		InvolvedObject: v1.ObjectReference{
-			APIVersion:      pipelinesascode.V1alpha1Version,
-			Kind:            pipelinesascode.RepositoryKind,
-			Namespace:       repo.Namespace,
-			Name:            repo.Name,
-			UID:             repo.UID,
-			ResourceVersion: repo.ResourceVersion,
+			Name:      eventName(involved, eventType, reason),
+			Namespace: involved.Namespace,
 		},
+		Message:        message,
+		Reason:         reason,
+		Type:           eventType,
+		InvolvedObject: involved,
 		Source: v1.EventSource{
 			Component: "Pipelines As Code",
 		},
+		Count:          1,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
 	}
-	if loggerLevel == zap.InfoLevel {
-		event.Type = v1.EventTypeNormal
+	if repoName != "" {
+		event.Labels = map[string]string{
+			keys.Repository: formatting.CleanValueKubernetes(repoName),
+		}
+		event.Annotations = map[string]string{
+			keys.Repository: repoName,
+		}
 	}
 	return event
 }
+
+func makeEvent(repo *v1alpha1.Repository, loggerLevel zapcore.Level, reason, message string) *v1.Event {
+	return newEvent(v1.ObjectReference{
+		APIVersion:      pipelinesascode.V1alpha1Version,
+		Kind:            pipelinesascode.RepositoryKind,
+		Namespace:       repo.Namespace,
+		Name:            repo.Name,
+		UID:             repo.UID,
+		ResourceVersion: repo.ResourceVersion,
+	}, repo.Name, loggerLevel, reason, message)
+}
+
+func makePipelineRunEvent(pr *tektonv1.PipelineRun, loggerLevel zapcore.Level, reason, message string) *v1.Event {
+	return newEvent(v1.ObjectReference{
+		APIVersion:      tektonv1.SchemeGroupVersion.String(),
+		Kind:            "PipelineRun",
+		Namespace:       pr.Namespace,
+		Name:            pr.Name,
+		UID:             pr.UID,
+		ResourceVersion: pr.ResourceVersion,
+	}, pr.GetAnnotations()[keys.Repository], loggerLevel, reason, message)
+}
+
+// ReasonForError returns the reason of the event of an error of the git
+// provider, a token rejected or a rate limit have their own reason, the other
+// errors have the default one.
+func ReasonForError(err error, defaultReason string) string {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return ReasonRateLimited
+	}
+
+	var statusCode int
+	var githubErr *github.ErrorResponse
+	var gitlabErr *gitlab.ErrorResponse
+	switch {
+	case errors.As(err, &githubErr) && githubErr.Response != nil:
+		statusCode = githubErr.Response.StatusCode
+	case errors.As(err, &gitlabErr) && gitlabErr.Response != nil:
+		statusCode = gitlabErr.Response.StatusCode
+	}
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return ReasonTokenInvalid
+	case statusCode == http.StatusTooManyRequests:
+		return ReasonRateLimited
+	}
+
+	// the other providers only give the error message
+	if err != nil {
+		msg := strings.ToLower(err.Error())
+		switch {
+		case strings.Contains(msg, "rate limit"):
+			return ReasonRateLimited
+		case strings.Contains(msg, "bad credentials"), strings.Contains(msg, "401 unauthorized"):
+			return ReasonTokenInvalid
+		}
+	}
+	return defaultReason
+}
@@ -2,11 +2,18 @@ package events
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/url"
 	"testing"
 
+	"github.com/google/go-github/v61/github"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/xanzy/go-gitlab"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	zapobserver "go.uber.org/zap/zaptest/observer"
@@ -88,3 +95,94 @@ func TestEventEmitter_EmitMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestEventEmitterAggregation(t *testing.T) {
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	fakelogger := zap.New(observer).Sugar()
+	ctx, _ := rtesting.SetupFakeContext(t)
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns", UID: "uid"}}
+
+	emitter := NewEventEmitter(stdata.Kube, fakelogger)
+	emitter.EmitMessage(repo, zap.ErrorLevel, ReasonRateLimited, "first")
+	emitter.EmitMessage(repo, zap.ErrorLevel, ReasonRateLimited, "second")
+	emitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryNoMatch", "no match")
+
+	events, err := stdata.Kube.CoreV1().Events("ns").List(context.Background(), metav1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(events.Items), 2)
+	for _, event := range events.Items {
+		switch event.Reason {
+		case ReasonRateLimited:
+			assert.Equal(t, event.Count, int32(2))
+			assert.Equal(t, event.Message, "second")
+		default:
+			assert.Equal(t, event.Count, int32(1))
+		}
+	}
+}
+
+func TestEventEmitterPipelineRun(t *testing.T) {
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	fakelogger := zap.New(observer).Sugar()
+	ctx, _ := rtesting.SetupFakeContext(t)
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+	pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Name:        "pr",
+		Namespace:   "ns",
+		Annotations: map[string]string{keys.Repository: "repo"},
+	}}
+
+	NewEventEmitter(stdata.Kube, fakelogger).EmitPipelineRunMessage(pr, zap.InfoLevel, "PipelineRunCreated", "created")
+
+	events, err := stdata.Kube.CoreV1().Events("ns").List(context.Background(), metav1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(events.Items), 1)
+	assert.Equal(t, events.Items[0].InvolvedObject.Kind, "PipelineRun")
+	assert.Equal(t, events.Items[0].InvolvedObject.Name, "pr")
+	assert.Equal(t, events.Items[0].Type, v1.EventTypeNormal)
+	assert.Equal(t, events.Items[0].GetLabels()[keys.Repository], "repo")
+}
+
+func TestReasonForError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "github rate limit",
+			err:  fmt.Errorf("cannot get commit: %w", &github.RateLimitError{Message: "API rate limit exceeded"}),
+			want: ReasonRateLimited,
+		},
+		{
+			name: "github bad credentials",
+			err:  &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}, Message: "Bad credentials"},
+			want: ReasonTokenInvalid,
+		},
+		{
+			name: "gitlab too many requests",
+			err:  &gitlab.ErrorResponse{Response: &http.Response{StatusCode: http.StatusTooManyRequests, Request: &http.Request{Method: http.MethodGet, URL: &url.URL{}}}},
+			want: ReasonRateLimited,
+		},
+		{
+			name: "message only",
+			err:  fmt.Errorf("bitbucket: 401 Unauthorized"),
+			want: ReasonTokenInvalid,
+		},
+		{
+			name: "other error",
+			err:  fmt.Errorf("no such file"),
+			want: "Default",
+		},
+		{
+			name: "no error",
+			want: "Default",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, ReasonForError(tt.err, "Default"), tt.want)
+		})
+	}
+}
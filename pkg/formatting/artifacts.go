@@ -0,0 +1,93 @@
+package formatting
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+const (
+	// ArtifactResultPrefix is the prefix of the task or pipeline results
+	// collected as artifacts links in the final status.
+	ArtifactResultPrefix = "artifact-url-"
+	// PreviewURLResult is the name of the task or pipeline result with the
+	// URL of the preview environment of a pull request.
+	PreviewURLResult = "preview-url"
+)
+
+// Artifact is a link to something a PipelineRun has produced, like an image, a
+// coverage report or a preview environment.
+type Artifact struct {
+	Name string
+	URL  string
+}
+
+// CollectArtifacts returns the artifacts links from the pipeline results and
+// the task results named artifact-url-<name>, values that are not http(s) URLs
+// are ignored. Task results are named <pipelinetask>/<name>.
+func CollectArtifacts(pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.PipelineRunTaskRunStatus) []Artifact {
+	artifacts := []Artifact{}
+	seen := map[string]bool{}
+	add := func(name, value string) {
+		value = strings.TrimSpace(value)
+		if !isArtifactURL(value) || seen[value] {
+			return
+		}
+		seen[value] = true
+		artifacts = append(artifacts, Artifact{Name: name, URL: value})
+	}
+
+	for _, result := range pr.Status.Results {
+		if name, ok := strings.CutPrefix(result.Name, ArtifactResultPrefix); ok {
+			add(name, result.Value.StringVal)
+		}
+	}
+
+	taskArtifacts := []Artifact{}
+	for _, taskrun := range trStatus {
+		if taskrun == nil || taskrun.Status == nil {
+			continue
+		}
+		for _, result := range taskrun.Status.Results {
+			if name, ok := strings.CutPrefix(result.Name, ArtifactResultPrefix); ok {
+				taskArtifacts = append(taskArtifacts, Artifact{Name: taskrun.PipelineTaskName + "/" + name, URL: result.Value.StringVal})
+			}
+		}
+	}
+	// trStatus is a map, sort them to always get the same status
+	sort.Slice(taskArtifacts, func(i, j int) bool { return taskArtifacts[i].Name < taskArtifacts[j].Name })
+	for _, artifact := range taskArtifacts {
+		add(artifact.Name, artifact.URL)
+	}
+	return artifacts
+}
+
+func isArtifactURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// PreviewURL returns the URL of the preview environment from the pipeline
+// result named preview-url or otherwise from the first task result with that
+// name, sorted by pipeline task name.
+func PreviewURL(pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.PipelineRunTaskRunStatus) string {
+	for _, result := range pr.Status.Results {
+		if result.Name == PreviewURLResult && isArtifactURL(strings.TrimSpace(result.Value.StringVal)) {
+			return strings.TrimSpace(result.Value.StringVal)
+		}
+	}
+	previewURL, previewTask := "", ""
+	for _, taskrun := range trStatus {
+		if taskrun == nil || taskrun.Status == nil || (previewTask != "" && taskrun.PipelineTaskName > previewTask) {
+			continue
+		}
+		for _, result := range taskrun.Status.Results {
+			if result.Name == PreviewURLResult && isArtifactURL(strings.TrimSpace(result.Value.StringVal)) {
+				previewURL, previewTask = strings.TrimSpace(result.Value.StringVal), taskrun.PipelineTaskName
+			}
+		}
+	}
+	return previewURL
+}
@@ -0,0 +1,88 @@
+package formatting
+
+import (
+	"strings"
+	"testing"
+
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestCollectArtifacts(t *testing.T) {
+	pr := &tektonv1.PipelineRun{
+		Status: tektonv1.PipelineRunStatus{
+			PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+				Results: []tektonv1.PipelineRunResult{
+					{Name: "artifact-url-preview", Value: *tektonv1.NewStructuredValues("https://preview.example.com\n")},
+					{Name: "image-digest", Value: *tektonv1.NewStructuredValues("https://not.an.artifact")},
+					{Name: "artifact-url-bad", Value: *tektonv1.NewStructuredValues("javascript:alert(1)")},
+				},
+			},
+		},
+	}
+	trStatus := map[string]*tektonv1.PipelineRunTaskRunStatus{
+		"pr-tests": {
+			PipelineTaskName: "tests",
+			Status: &tektonv1.TaskRunStatus{
+				TaskRunStatusFields: tektonv1.TaskRunStatusFields{
+					Results: []tektonv1.TaskRunResult{
+						{Name: "artifact-url-coverage", Value: *tektonv1.NewStructuredValues("https://coverage.example.com")},
+						// same url as the pipeline result
+						{Name: "artifact-url-preview", Value: *tektonv1.NewStructuredValues("https://preview.example.com")},
+					},
+				},
+			},
+		},
+		"pr-build": {
+			PipelineTaskName: "build",
+			Status: &tektonv1.TaskRunStatus{
+				TaskRunStatusFields: tektonv1.TaskRunStatusFields{
+					Results: []tektonv1.TaskRunResult{
+						{Name: "artifact-url-image", Value: *tektonv1.NewStructuredValues("https://quay.io/repo/image:tag")},
+					},
+				},
+			},
+		},
+		"pr-nostatus": {PipelineTaskName: "nostatus"},
+	}
+
+	artifacts := CollectArtifacts(pr, trStatus)
+	assert.DeepEqual(t, artifacts, []Artifact{
+		{Name: "preview", URL: "https://preview.example.com"},
+		{Name: "build/image", URL: "https://quay.io/repo/image:tag"},
+		{Name: "tests/coverage", URL: "https://coverage.example.com"},
+	})
+
+	got, err := MessageTemplate{Artifacts: artifacts[:1]}.MakeTemplate(PipelineRunStatusText)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(got, `<h4>Artifacts:</h4>`))
+	assert.Assert(t, strings.Contains(got, `<li><a href="https://preview.example.com">preview</a></li>`))
+
+	got, err = MessageTemplate{}.MakeTemplate(PipelineRunStatusText)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(got, `Artifacts`))
+}
+
+func TestPreviewURL(t *testing.T) {
+	taskResult := func(task, value string) *tektonv1.PipelineRunTaskRunStatus {
+		return &tektonv1.PipelineRunTaskRunStatus{
+			PipelineTaskName: task,
+			Status: &tektonv1.TaskRunStatus{
+				TaskRunStatusFields: tektonv1.TaskRunStatusFields{
+					Results: []tektonv1.TaskRunResult{{Name: PreviewURLResult, Value: *tektonv1.NewStructuredValues(value)}},
+				},
+			},
+		}
+	}
+	trStatus := map[string]*tektonv1.PipelineRunTaskRunStatus{
+		"pr-deploy-b": taskResult("deploy-b", "https://b.example.com"),
+		"pr-deploy-a": taskResult("deploy-a", "https://a.example.com\n"),
+		"pr-deploy-0": taskResult("deploy-0", "not an url"),
+	}
+	pr := &tektonv1.PipelineRun{}
+	assert.Equal(t, PreviewURL(pr, trStatus), "https://a.example.com")
+	assert.Equal(t, PreviewURL(pr, nil), "")
+
+	pr.Status.Results = []tektonv1.PipelineRunResult{{Name: PreviewURLResult, Value: *tektonv1.NewStructuredValues("https://pipeline.example.com")}}
+	assert.Equal(t, PreviewURL(pr, trStatus), "https://pipeline.example.com")
+}
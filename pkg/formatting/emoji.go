@@ -1,9 +1,6 @@
 package formatting
 
 import (
-	"fmt"
-
-	corev1 "k8s.io/api/core/v1"
 	knative1 "knative.dev/pkg/apis/duck/v1"
 )
 
@@ -11,27 +8,7 @@ const nonAttributedStr = "---"
 
 // formatCondition knative formatcondition with emoji or not.
 func formatCondition(c knative1.Conditions, skipemoji bool) string {
-	var status, emoji string
-	if len(c) == 0 {
-		return nonAttributedStr
-	}
-
-	switch c[0].Status {
-	case corev1.ConditionFalse:
-		emoji = "❌"
-		status = "Failed"
-	case corev1.ConditionTrue:
-		emoji = "✅"
-		status = "Succeeded"
-	case corev1.ConditionUnknown:
-		emoji = "🏃"
-		status = "Running"
-	}
-	if !skipemoji {
-		status = fmt.Sprintf("%s %s", emoji, status)
-	}
-
-	return status
+	return MessagesFor(DefaultLocale).Condition(c, !skipemoji)
 }
 
 func ConditionEmoji(c knative1.Conditions) string {
@@ -0,0 +1,49 @@
+package formatting
+
+import (
+	"time"
+
+	"github.com/hako/durafmt"
+)
+
+// HistoryRun is a previous run of a PipelineRun shown in its final status.
+type HistoryRun struct {
+	Name     string
+	URL      string
+	Result   string
+	Duration time.Duration
+}
+
+// FormattedDuration returns the duration of the run in a short human form.
+func (h HistoryRun) FormattedDuration() string {
+	return durafmt.ParseShort(h.Duration).String()
+}
+
+// DurationTrend compares the duration of a run with the average duration of
+// the previous runs in the locale of the messages, empty when there is none.
+func DurationTrend(current time.Duration, history []HistoryRun, messages Messages) string {
+	var total time.Duration
+	count := 0
+	for _, run := range history {
+		if run.Duration > 0 {
+			total += run.Duration
+			count++
+		}
+	}
+	if count == 0 || current <= 0 {
+		return ""
+	}
+	average := total / time.Duration(count)
+	runs := messages.T("trend.last_runs", count)
+	if count == 1 {
+		runs = messages.T("trend.last_run")
+	}
+	percent := int((current - average) * 100 / average)
+	switch {
+	case percent >= 5:
+		return messages.T("trend.slower", percent, runs, messages.Duration(average))
+	case percent <= -5:
+		return messages.T("trend.faster", -percent, runs, messages.Duration(average))
+	}
+	return messages.T("trend.about", runs, messages.Duration(average))
+}
@@ -0,0 +1,64 @@
+package formatting
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDurationTrend(t *testing.T) {
+	history := []HistoryRun{
+		{Name: "pr-1", Duration: 4 * time.Minute},
+		{Name: "pr-2", Duration: 6 * time.Minute},
+		{Name: "pr-3"},
+	}
+	tests := []struct {
+		name    string
+		current time.Duration
+		history []HistoryRun
+		locale  string
+		want    string
+	}{
+		{
+			name:    "slower",
+			current: 6 * time.Minute,
+			history: history,
+			want:    "20% slower than the average of the last 2 runs (5 minutes)",
+		},
+		{
+			name:    "faster",
+			current: 4 * time.Minute,
+			history: history,
+			want:    "20% faster than the average of the last 2 runs (5 minutes)",
+		},
+		{
+			name:    "about the same",
+			current: 5*time.Minute + 10*time.Second,
+			history: history,
+			want:    "about the average duration of the last 2 runs (5 minutes)",
+		},
+		{
+			name:    "a single run",
+			current: 8 * time.Minute,
+			history: history[:1],
+			want:    "100% slower than the average of the last run (4 minutes)",
+		},
+		{
+			name:    "in french",
+			current: 6 * time.Minute,
+			history: history,
+			locale:  "fr",
+			want:    "20% plus lent que la moyenne des 2 derniers runs (5 minutes)",
+		},
+		{
+			name:    "no history",
+			current: 5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, DurationTrend(tt.current, tt.history, MessagesFor(tt.locale)), tt.want)
+		})
+	}
+}
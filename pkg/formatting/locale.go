@@ -0,0 +1,158 @@
+package formatting
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hako/durafmt"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	knative1 "knative.dev/pkg/apis/duck/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultLocale is the locale of the statuses and comments when the
+// Repository doesn't set one, its catalog has all the messages.
+const DefaultLocale = "en"
+
+//go:embed locales/*.yaml
+var localesFS embed.FS
+
+// catalogs are the messages by key of each locale, loaded from the
+// locales/<locale>.yaml files.
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	entries, err := localesFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	loaded := map[string]map[string]string{}
+	for _, entry := range entries {
+		data, err := localesFS.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("cannot parse the message catalog %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".yaml")] = messages
+	}
+	return loaded
+}
+
+// Locales returns the locales with a message catalog, sorted.
+func Locales() []string {
+	locales := []string{}
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// ValidateLocale checks the locale of the settings has a message catalog.
+func ValidateLocale(settings *v1alpha1.Settings) error {
+	if settings == nil || settings.Locale == "" {
+		return nil
+	}
+	if _, ok := catalogs[settings.Locale]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown locale %s, it should be one of: %s", settings.Locale, strings.Join(Locales(), ", "))
+}
+
+// Messages are the messages of the statuses and comments in a locale, the
+// ones missing from its catalog are taken from the English one.
+type Messages struct {
+	locale string
+}
+
+// MessagesFor returns the messages of the locale, the English ones when it
+// has no catalog.
+func MessagesFor(locale string) Messages {
+	if _, ok := catalogs[locale]; !ok {
+		locale = DefaultLocale
+	}
+	return Messages{locale: locale}
+}
+
+// Locale returns the locale of the messages.
+func (m Messages) Locale() string {
+	if m.locale == "" {
+		return DefaultLocale
+	}
+	return m.locale
+}
+
+// T returns the message of the key formatted with the arguments, the key
+// itself when no catalog has it.
+func (m Messages) T(key string, args ...any) string {
+	message, ok := catalogs[m.Locale()][key]
+	if !ok {
+		if message, ok = catalogs[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Duration returns the duration in a short human form with the units of the
+// locale.
+func (m Messages) Duration(d time.Duration) string {
+	units, err := durafmt.DefaultUnitsCoder.Decode(m.T("duration.units"))
+	if err != nil {
+		return durafmt.ParseShort(d).String()
+	}
+	return durafmt.ParseShort(d).Format(units)
+}
+
+// DurationBetween returns the duration between two times, nonAttributedStr
+// when one of them is not set.
+func (m Messages) DurationBetween(t1, t2 *metav1.Time) string {
+	if t1.IsZero() || t2.IsZero() {
+		return nonAttributedStr
+	}
+	return m.Duration(t2.Sub(t1.Time))
+}
+
+// Condition returns the status of the first condition, prefixed with its
+// emoji when emoji is true.
+func (m Messages) Condition(c knative1.Conditions, emoji bool) string {
+	if len(c) == 0 {
+		return nonAttributedStr
+	}
+
+	var icon, status string
+	switch c[0].Status {
+	case corev1.ConditionFalse:
+		icon, status = "❌", m.T("task.failed")
+	case corev1.ConditionTrue:
+		icon, status = "✅", m.T("task.succeeded")
+	case corev1.ConditionUnknown:
+		icon, status = "🏃", m.T("task.running")
+	}
+	if emoji {
+		return fmt.Sprintf("%s %s", icon, status)
+	}
+	return status
+}
+
+// FuncMap returns the template functions translating the texts: t formats a
+// message of the catalog and duration a time.Duration.
+func (m Messages) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"t":        m.T,
+		"duration": m.Duration,
+	}
+}
@@ -0,0 +1,117 @@
+package formatting
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	knative1 "knative.dev/pkg/apis/duck/v1"
+)
+
+var formatVerb = regexp.MustCompile(`%[^%]`)
+
+func TestCatalogs(t *testing.T) {
+	english := catalogs[DefaultLocale]
+	assert.Assert(t, len(Locales()) > 1)
+	for _, locale := range Locales() {
+		for key, message := range catalogs[locale] {
+			reference, ok := english[key]
+			assert.Assert(t, ok, "key %s of the locale %s is not in the English catalog", key, locale)
+			assert.Equal(t, len(formatVerb.FindAllString(message, -1)), len(formatVerb.FindAllString(reference, -1)),
+				"the message %s of the locale %s doesn't have the arguments of the English one", key, locale)
+		}
+	}
+}
+
+func TestMessages(t *testing.T) {
+	catalogs["test"] = map[string]string{"status.history": "Historique"}
+	defer delete(catalogs, "test")
+
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		args   []any
+		want   string
+	}{
+		{
+			name: "default locale",
+			key:  "status.queued",
+			args: []any{"pr", "ns"},
+			want: "PipelineRun pr has been queued in namespace ns",
+		},
+		{
+			name:   "translated",
+			locale: "fr",
+			key:    "status.queued",
+			args:   []any{"pr", "ns"},
+			want:   "Le PipelineRun pr a été mis en file d'attente dans le namespace ns",
+		},
+		{
+			name:   "missing from the locale",
+			locale: "test",
+			key:    "status.task_statuses",
+			want:   "Task Statuses",
+		},
+		{
+			name:   "unknown locale",
+			locale: "xx",
+			key:    "status.history",
+			want:   "History",
+		},
+		{
+			name: "unknown key",
+			key:  "status.unknown",
+			want: "status.unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, MessagesFor(tt.locale).T(tt.key, tt.args...), tt.want)
+		})
+	}
+}
+
+func TestMessagesDuration(t *testing.T) {
+	assert.Equal(t, MessagesFor(DefaultLocale).Duration(2*time.Hour+30*time.Second), "2 hours")
+	assert.Equal(t, MessagesFor("fr").Duration(2*time.Hour+30*time.Second), "2 heures")
+	assert.Equal(t, MessagesFor("fr").Duration(time.Second), "1 seconde")
+}
+
+func TestMessagesCondition(t *testing.T) {
+	failed := knative1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse}}
+	assert.Equal(t, MessagesFor(DefaultLocale).Condition(failed, true), "❌ Failed")
+	assert.Equal(t, MessagesFor("fr").Condition(failed, false), "Échec")
+	assert.Equal(t, MessagesFor("fr").Condition(nil, true), nonAttributedStr)
+}
+
+func TestValidateLocale(t *testing.T) {
+	assert.NilError(t, ValidateLocale(nil))
+	assert.NilError(t, ValidateLocale(&v1alpha1.Settings{Locale: "fr"}))
+	assert.Error(t, ValidateLocale(&v1alpha1.Settings{Locale: "de"}), "unknown locale de, it should be one of: en, fr")
+}
+
+func TestLocalizedTemplates(t *testing.T) {
+	mt := MessageTemplate{
+		PipelineRunName: "pr",
+		Namespace:       "ns",
+		FlakyTasks:      []string{"unit"},
+		History:         []HistoryRun{{Name: "pr-1", Result: "Succeeded", Duration: time.Minute}},
+		FailureSnippet:  "oops",
+		Profile:         ProfileFor("github", &v1alpha1.Settings{Locale: "fr"}),
+	}
+	got, err := mt.MakeTemplate(mt.Profile.FinishedTemplate())
+	assert.NilError(t, err)
+	for _, want := range []string{"<h4>Statuts des tâches:</h4>", "la tâche <b>unit</b> a réussi et échoué sur le même commit", "<summary><b>Historique</b></summary>", "<li>pr-1 : Succeeded en 1 minute</li>"} {
+		assert.Assert(t, strings.Contains(got, want), "%s not in %s", want, got)
+	}
+
+	got, err = mt.MakeCustomTemplate(`{{ t "status.history" }} {{ duration 120000000000 }}`, mt.Profile.StartingTemplate())
+	assert.NilError(t, err)
+	assert.Equal(t, got, "Historique 2 minutes")
+}
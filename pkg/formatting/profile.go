@@ -0,0 +1,145 @@
+package formatting
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+)
+
+//go:embed templates/starting.md.tmpl
+var StartingPipelineRunMarkdownText string
+
+//go:embed templates/pipelinerunstatus.md.tmpl
+var PipelineRunStatusMarkdownText string
+
+//go:embed templates/taskstatus.html.tmpl
+var TaskStatusHTMLTableText string
+
+//go:embed templates/taskstatus.md.tmpl
+var TaskStatusMarkdownTableText string
+
+//go:embed templates/taskstatus.list.tmpl
+var TaskStatusListText string
+
+// Profile is how the statuses and comments are formatted for a git provider,
+// they don't all render the GitHub flavored markdown the same way.
+type Profile struct {
+	Name string
+	// HTML is true when the git provider renders the HTML tags in the
+	// markdown, the texts are written in plain markdown otherwise.
+	HTML bool
+	// Tables shows the statuses of the tasks as a table, as a list otherwise.
+	Tables bool
+	// Emoji shows the statuses of the tasks with an emoji.
+	Emoji bool
+	// Collapsible folds the long sections of the final status in a
+	// <details> tag, only with HTML.
+	Collapsible bool
+	// Locale is the locale of the texts, English when empty.
+	Locale string
+}
+
+// PlainProfile is the profile writing the texts in plain markdown, without
+// any table or emoji.
+const PlainProfile = "plain"
+
+var profiles = map[string]Profile{
+	"github":           {Name: "github", HTML: true, Tables: true, Emoji: true, Collapsible: true},
+	"gitlab":           {Name: "gitlab", HTML: true, Tables: true, Emoji: true, Collapsible: true},
+	"gitea":            {Name: "gitea", HTML: true, Tables: true},
+	"bitbucket-cloud":  {Name: "bitbucket-cloud", Tables: true, Emoji: true},
+	"bitbucket-server": {Name: "bitbucket-server", Emoji: true},
+	PlainProfile:       {Name: PlainProfile},
+}
+
+// ProfileFor returns the formatting profile of the git provider overridden by
+// the formatting and locale settings of the Repository. The providers without
+// a profile of their own, like GitHub Enterprise, get the GitHub one.
+func ProfileFor(providerName string, settings *v1alpha1.Settings) Profile {
+	profile, ok := profiles[providerName]
+	if !ok {
+		profile = profiles["github"]
+	}
+	if settings == nil {
+		return profile
+	}
+	if override := settings.Formatting; override != nil {
+		if builtin, ok := profiles[override.Profile]; ok {
+			profile = builtin
+		}
+		if override.Tables != nil {
+			profile.Tables = *override.Tables
+		}
+		if override.Emoji != nil {
+			profile.Emoji = *override.Emoji
+		}
+		if override.Collapsible != nil {
+			profile.Collapsible = *override.Collapsible && profile.HTML
+		}
+	}
+	profile.Locale = settings.Locale
+	return profile
+}
+
+// Messages returns the messages in the locale of the profile.
+func (p Profile) Messages() Messages {
+	return MessagesFor(p.Locale)
+}
+
+// ValidateProfile checks the profile of the formatting settings is a builtin
+// one.
+func ValidateProfile(settings *v1alpha1.Settings) error {
+	if settings == nil || settings.Formatting == nil || settings.Formatting.Profile == "" {
+		return nil
+	}
+	if _, ok := profiles[settings.Formatting.Profile]; ok {
+		return nil
+	}
+	names := []string{}
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown formatting profile %s, it should be one of: %s", settings.Formatting.Profile, strings.Join(names, ", "))
+}
+
+// StartingTemplate returns the builtin template of the status of a started
+// PipelineRun.
+func (p Profile) StartingTemplate() string {
+	if p.HTML {
+		return StartingPipelineRunText
+	}
+	return StartingPipelineRunMarkdownText
+}
+
+// FinishedTemplate returns the builtin template of the final status of a
+// PipelineRun.
+func (p Profile) FinishedTemplate() string {
+	if p.HTML {
+		return PipelineRunStatusText
+	}
+	return PipelineRunStatusMarkdownText
+}
+
+// TaskStatusTemplate returns the template of the statuses of the tasks.
+func (p Profile) TaskStatusTemplate() string {
+	switch {
+	case p.Tables && p.HTML:
+		return TaskStatusHTMLTableText
+	case p.Tables:
+		return TaskStatusMarkdownTableText
+	}
+	return TaskStatusListText
+}
+
+// FailureSnippet formats the log snippet of the failed task.
+func (p Profile) FailureSnippet(task, reason, snippet string) string {
+	messages := p.Messages()
+	if p.HTML {
+		return fmt.Sprintf("%s\n<pre>%s</pre>", messages.T("status.failure_task", "<b>"+task+"</b>", "<b>\""+reason+"\"</b>"), snippet)
+	}
+	return fmt.Sprintf("%s\n```\n%s\n```", messages.T("status.failure_task", "**"+task+"**", "**\""+reason+"\"**"), snippet)
+}
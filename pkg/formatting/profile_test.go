@@ -0,0 +1,146 @@
+package formatting
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+)
+
+func TestProfileFor(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name         string
+		providerName string
+		settings     *v1alpha1.Settings
+		want         Profile
+	}{
+		{
+			name:         "github",
+			providerName: "github",
+			want:         Profile{Name: "github", HTML: true, Tables: true, Emoji: true, Collapsible: true},
+		},
+		{
+			name:         "provider without a profile gets the github one",
+			providerName: "github-enterprise",
+			settings:     &v1alpha1.Settings{},
+			want:         Profile{Name: "github", HTML: true, Tables: true, Emoji: true, Collapsible: true},
+		},
+		{
+			name:         "gitea without emoji",
+			providerName: "gitea",
+			want:         Profile{Name: "gitea", HTML: true, Tables: true},
+		},
+		{
+			name:         "bitbucket server in plain markdown",
+			providerName: "bitbucket-server",
+			want:         Profile{Name: "bitbucket-server", Emoji: true},
+		},
+		{
+			name:         "override the fields of the provider profile",
+			providerName: "gitlab",
+			settings:     &v1alpha1.Settings{Formatting: &v1alpha1.Formatting{Tables: &no, Collapsible: &no}},
+			want:         Profile{Name: "gitlab", HTML: true, Emoji: true},
+		},
+		{
+			name:         "override the profile",
+			providerName: "github",
+			settings:     &v1alpha1.Settings{Formatting: &v1alpha1.Formatting{Profile: PlainProfile, Emoji: &yes}},
+			want:         Profile{Name: PlainProfile, Emoji: true},
+		},
+		{
+			name:         "no collapsible sections without html",
+			providerName: "bitbucket-cloud",
+			settings:     &v1alpha1.Settings{Formatting: &v1alpha1.Formatting{Collapsible: &yes}},
+			want:         Profile{Name: "bitbucket-cloud", Tables: true, Emoji: true},
+		},
+		{
+			name:         "locale of the repository",
+			providerName: "gitea",
+			settings:     &v1alpha1.Settings{Locale: "fr", Formatting: &v1alpha1.Formatting{Profile: PlainProfile}},
+			want:         Profile{Name: PlainProfile, Locale: "fr"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, ProfileFor(tt.providerName, tt.settings), tt.want)
+		})
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	assert.NilError(t, ValidateProfile(nil))
+	assert.NilError(t, ValidateProfile(&v1alpha1.Settings{Formatting: &v1alpha1.Formatting{}}))
+	assert.NilError(t, ValidateProfile(&v1alpha1.Settings{Formatting: &v1alpha1.Formatting{Profile: "bitbucket-server"}}))
+	assert.ErrorContains(t, ValidateProfile(&v1alpha1.Settings{Formatting: &v1alpha1.Formatting{Profile: "html"}}), "unknown formatting profile html")
+}
+
+func TestProfileTemplates(t *testing.T) {
+	html := ProfileFor("github", nil)
+	assert.Equal(t, html.StartingTemplate(), StartingPipelineRunText)
+	assert.Equal(t, html.FinishedTemplate(), PipelineRunStatusText)
+	assert.Equal(t, html.TaskStatusTemplate(), TaskStatusHTMLTableText)
+	assert.Equal(t, html.FailureSnippet("build", "Failed", "oops"), "task <b>build</b> has the status <b>\"Failed\"</b>:\n<pre>oops</pre>")
+
+	markdown := ProfileFor("bitbucket-cloud", nil)
+	assert.Equal(t, markdown.StartingTemplate(), StartingPipelineRunMarkdownText)
+	assert.Equal(t, markdown.FinishedTemplate(), PipelineRunStatusMarkdownText)
+	assert.Equal(t, markdown.TaskStatusTemplate(), TaskStatusMarkdownTableText)
+	assert.Equal(t, markdown.FailureSnippet("build", "Failed", "oops"), "task **build** has the status **\"Failed\"**:\n```\noops\n```")
+
+	assert.Equal(t, ProfileFor(PlainProfile, nil).TaskStatusTemplate(), TaskStatusListText)
+}
+
+func TestPipelineRunStatusMarkdownText(t *testing.T) {
+	mt := MessageTemplate{
+		PipelineRunName: "pr-abcde",
+		Namespace:       "ns",
+		NamespaceURL:    "https://console/ns",
+		ConsoleURL:      "https://console/ns/pr-abcde",
+		TaskStatus:      "* **Succeeded** build",
+		FlakyTasks:      []string{"unit"},
+		Artifacts:       []Artifact{{Name: "image", URL: "https://registry/image"}},
+		History:         []HistoryRun{{Name: "pr-fghij", Result: "Failed", Duration: 90 * time.Second}},
+		DurationTrend:   "about the average duration of the last run (1 minute)",
+		FailureSnippet:  "task **build** has the status **\"Failed\"**",
+		Profile:         ProfileFor("bitbucket-server", nil),
+	}
+	got, err := mt.MakeTemplate(mt.Profile.FinishedTemplate())
+	assert.NilError(t, err)
+	for _, want := range []string{
+		"* **Namespace**: [ns](https://console/ns)\n* **PipelineRun**: [pr-abcde](https://console/ns/pr-abcde)\n",
+		"#### Task Statuses:\n\n* **Succeeded** build\n",
+		"#### Possibly flaky:\n\n* task **unit** has passed and failed on the same commit\n",
+		"#### Artifacts:\n\n* [image](https://registry/image)\n",
+		"#### History:\n\nThis run is about the average duration of the last run (1 minute).\n\n* pr-fghij: Failed in 1 minute\n",
+		"#### Failure snippet:\n\ntask **build** has the status **\"Failed\"**\n",
+	} {
+		assert.Assert(t, strings.Contains(got, want), "%q not in the status text: %s", want, got)
+	}
+	assert.Assert(t, !strings.Contains(got, "<"), "html in the markdown status text: %s", got)
+
+	got, err = mt.MakeTemplate(mt.Profile.StartingTemplate())
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(got, "Starting Pipelinerun **pr-abcde** in namespace **ns**\n"), got)
+	assert.Assert(t, !strings.Contains(got, "<"), "html in the markdown starting text: %s", got)
+}
+
+func TestPipelineRunStatusTextCollapsible(t *testing.T) {
+	mt := MessageTemplate{
+		History:        []HistoryRun{{Name: "pr-fghij", Result: "Failed", Duration: 90 * time.Second}},
+		FailureSnippet: "task <b>build</b> has failed",
+		Profile:        ProfileFor("github", nil),
+	}
+	got, err := mt.MakeTemplate(mt.Profile.FinishedTemplate())
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(got, "<details>\n<summary><b>History</b></summary>\n<ul>\n<li>pr-fghij: Failed in 1 minute</li>\n</ul>\n</details>"), got)
+	assert.Assert(t, strings.Contains(got, "<details>\n<summary><b>Failure snippet</b></summary>\n\ntask <b>build</b> has failed\n</details>"), got)
+
+	mt.Profile = ProfileFor("gitea", nil)
+	got, err = mt.MakeTemplate(mt.Profile.FinishedTemplate())
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(got, "<details>"), got)
+	assert.Assert(t, strings.Contains(got, "<h4>History:</h4>") && strings.Contains(got, "<h4>Failure snippet:</h4>"), got)
+}
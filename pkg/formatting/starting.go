@@ -3,6 +3,7 @@ package formatting
 import (
 	"bytes"
 	_ "embed"
+	"fmt"
 	"text/template"
 )
 
@@ -25,14 +26,59 @@ type MessageTemplate struct {
 	TknBinaryURL    string
 	TaskStatus      string
 	FailureSnippet  string
+	Artifacts       []Artifact
+	// FlakyTasks are the tasks which have passed and failed on the same
+	// commit, hinted as possibly flaky.
+	FlakyTasks []string
+	// Cost is the estimated cost of the resources requested by the tasks.
+	Cost string
+	// History are the previous runs of the PipelineRun on the same branch,
+	// the most recent first.
+	History []HistoryRun
+	// DurationTrend compares the duration of the PipelineRun with the one of
+	// its previous runs.
+	DurationTrend string
+	// Profile is the formatting profile of the git provider.
+	Profile Profile
 }
 
 func (mt MessageTemplate) MakeTemplate(tmpl string) (string, error) {
 	outputBuffer := bytes.Buffer{}
-	t := template.Must(template.New("Message").Parse(tmpl))
+	t := template.Must(template.New("Message").Funcs(mt.Profile.Messages().FuncMap()).Parse(tmpl))
 	data := struct{ Mt MessageTemplate }{Mt: mt}
 	if err := t.Execute(&outputBuffer, data); err != nil {
 		return "", err
 	}
 	return outputBuffer.String(), nil
 }
+
+// MakeCustomTemplate renders a template from the Repository settings, the
+// text of the builtin template is available in it as {{ .Default }} and the
+// messages of the catalog with the t function. The builtin template is used
+// when the custom one is empty.
+func (mt MessageTemplate) MakeCustomTemplate(custom, builtin string) (string, error) {
+	defaultText, err := mt.MakeTemplate(builtin)
+	if err != nil || custom == "" {
+		return defaultText, err
+	}
+	t, err := template.New("Custom").Funcs(mt.Profile.Messages().FuncMap()).Parse(custom)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse custom template: %w", err)
+	}
+	outputBuffer := bytes.Buffer{}
+	data := struct {
+		Mt      MessageTemplate
+		Default string
+	}{Mt: mt, Default: defaultText}
+	if err := t.Execute(&outputBuffer, data); err != nil {
+		return "", fmt.Errorf("cannot execute custom template: %w", err)
+	}
+	return outputBuffer.String(), nil
+}
+
+// ValidateCustomTemplate checks that a template from the Repository settings
+// can be rendered.
+func ValidateCustomTemplate(custom string) error {
+	_, err := MessageTemplate{}.MakeCustomTemplate(custom, "")
+	return err
+}
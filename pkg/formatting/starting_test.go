@@ -1,7 +1,9 @@
 package formatting
 
 import (
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestMessageTemplate_MakeTemplate(t *testing.T) {
@@ -54,3 +56,126 @@ func TestMessageTemplate_MakeTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageTemplate_MakeCustomTemplate(t *testing.T) {
+	mt := MessageTemplate{
+		PipelineRunName: "test-pipeline",
+		Namespace:       "test-namespace",
+	}
+	builtin := "Starting Pipelinerun {{.Mt.PipelineRunName}}"
+
+	tests := []struct {
+		name    string
+		custom  string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "no custom template",
+			want: "Starting Pipelinerun test-pipeline",
+		},
+		{
+			name:   "custom template with default",
+			custom: "{{ .Default }}\n\nSee the [runbook](https://runbook/{{ .Mt.Namespace }})",
+			want:   "Starting Pipelinerun test-pipeline\n\nSee the [runbook](https://runbook/test-namespace)",
+		},
+		{
+			name:    "bad custom template",
+			custom:  "{{ .Default ",
+			wantErr: "cannot parse custom template",
+		},
+		{
+			name:    "unknown field",
+			custom:  "{{ .Mt.Foo }}",
+			wantErr: "cannot execute custom template",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mt.MakeCustomTemplate(tt.custom, builtin)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("MessageTemplate.MakeCustomTemplate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if verr := ValidateCustomTemplate(tt.custom); verr == nil {
+					t.Errorf("ValidateCustomTemplate() should have failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("MessageTemplate.MakeCustomTemplate() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("MessageTemplate.MakeCustomTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipelineRunStatusTextFlakyTasks(t *testing.T) {
+	got, err := MessageTemplate{FlakyTasks: []string{"unit<tests>"}}.MakeTemplate(PipelineRunStatusText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "<h4>Possibly flaky:</h4>") || !strings.Contains(got, "task <b>unit&lt;tests&gt;</b> has passed and failed on the same commit") {
+		t.Errorf("flaky tasks not in the status text: %s", got)
+	}
+
+	got, err = MessageTemplate{}.MakeTemplate(PipelineRunStatusText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "Possibly flaky") {
+		t.Errorf("unexpected flaky section: %s", got)
+	}
+}
+
+func TestPipelineRunStatusTextCost(t *testing.T) {
+	got, err := MessageTemplate{Cost: "0.12 USD for 1.50 CPU core hours and 3.00 GiB hours of memory requested"}.MakeTemplate(PipelineRunStatusText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "<h4>Estimated cost:</h4>\n0.12 USD for 1.50 CPU core hours and 3.00 GiB hours of memory requested") {
+		t.Errorf("cost not in the status text: %s", got)
+	}
+
+	got, err = MessageTemplate{}.MakeTemplate(PipelineRunStatusText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "Estimated cost") {
+		t.Errorf("unexpected cost section: %s", got)
+	}
+}
+
+func TestPipelineRunStatusTextHistory(t *testing.T) {
+	got, err := MessageTemplate{
+		History: []HistoryRun{
+			{Name: "pr-abcde", URL: "https://console/pr-abcde", Result: "Succeeded", Duration: 4 * time.Minute},
+			{Name: "pr-fghij", Result: "Failed", Duration: 90 * time.Second},
+		},
+		DurationTrend: "20% slower than the average of the last 2 runs (5 minutes)",
+	}.MakeTemplate(PipelineRunStatusText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<h4>History:</h4>",
+		"<p>This run is 20% slower than the average of the last 2 runs (5 minutes).</p>",
+		`<li><a href="https://console/pr-abcde">pr-abcde</a>: Succeeded in 4 minutes</li>`,
+		"<li>pr-fghij: Failed in 1 minute</li>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q not in the status text: %s", want, got)
+		}
+	}
+
+	got, err = MessageTemplate{}.MakeTemplate(PipelineRunStatusText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "History") {
+		t.Errorf("unexpected history section: %s", got)
+	}
+}
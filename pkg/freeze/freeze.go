@@ -0,0 +1,109 @@
+package freeze
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+)
+
+const (
+	// ActionSkip reports a neutral status without running the PipelineRuns.
+	ActionSkip = "skip"
+	// ActionQueue creates the PipelineRuns pending until the end of the window.
+	ActionQueue = "queue"
+
+	// maxDuration keeps the search of the last start of a window short.
+	maxDuration = 31 * 24 * time.Hour
+)
+
+// Active is a freeze window in progress.
+type Active struct {
+	Name   string
+	Action string
+	End    time.Time
+}
+
+// Validate checks the freeze windows of the settings.
+func Validate(settings *v1alpha1.Settings) error {
+	if settings == nil {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, w := range settings.FreezeWindows {
+		if w.Name == "" {
+			return fmt.Errorf("freeze window with the schedule %q has no name", w.Schedule)
+		}
+		if names[w.Name] {
+			return fmt.Errorf("freeze window %s is defined more than once", w.Name)
+		}
+		names[w.Name] = true
+		if _, _, err := parseWindow(w); err != nil {
+			return fmt.Errorf("invalid freeze window %s: %w", w.Name, err)
+		}
+		for _, branch := range w.Branches {
+			if err := matcher.ValidateBranchGlob(branch); err != nil {
+				return fmt.Errorf("invalid freeze window %s: %w", w.Name, err)
+			}
+		}
+		if w.Action != "" && w.Action != ActionSkip && w.Action != ActionQueue {
+			return fmt.Errorf("invalid freeze window %s: action %q needs to be %s or %s", w.Name, w.Action, ActionSkip, ActionQueue)
+		}
+	}
+	return nil
+}
+
+func parseWindow(w v1alpha1.FreezeWindow) (*schedule, time.Duration, error) {
+	s, err := parseSchedule(w.Schedule)
+	if err != nil {
+		return nil, 0, err
+	}
+	d, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid duration %q: %w", w.Duration, err)
+	}
+	if d <= 0 || d > maxDuration {
+		return nil, 0, fmt.Errorf("duration %s needs to be between 1m and %s", w.Duration, maxDuration)
+	}
+	if _, err := time.LoadLocation(w.TimeZone); err != nil {
+		return nil, 0, fmt.Errorf("invalid timezone %q: %w", w.TimeZone, err)
+	}
+	return s, d, nil
+}
+
+// ActiveWindow returns the freeze window of the settings in progress at now
+// for the branch, the one ending last when several of them overlap. The
+// windows lifted by an admin or failing to parse are ignored.
+func ActiveWindow(settings *v1alpha1.Settings, branch string, now time.Time) *Active {
+	if settings == nil || settings.FreezeOverride {
+		return nil
+	}
+	var active *Active
+	for _, w := range settings.FreezeWindows {
+		if len(w.Branches) > 0 && !slices.ContainsFunc(w.Branches, func(b string) bool {
+			return matcher.ValidateBranchGlob(b) == nil && matcher.BranchMatch(b, branch)
+		}) {
+			continue
+		}
+		s, d, err := parseWindow(w)
+		if err != nil {
+			continue
+		}
+		loc, _ := time.LoadLocation(w.TimeZone)
+		local := now.In(loc)
+		start, ok := s.lastStart(local.Add(-d), local)
+		if !ok || !local.Before(start.Add(d)) {
+			continue
+		}
+		if end := start.Add(d); active == nil || end.After(active.End) {
+			action := w.Action
+			if action == "" {
+				action = ActionSkip
+			}
+			active = &Active{Name: w.Name, Action: action, End: end.UTC()}
+		}
+	}
+	return active
+}
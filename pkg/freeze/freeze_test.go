@@ -0,0 +1,224 @@
+package freeze
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// friday 2026-10-16
+	friday := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		expr    string
+		match   []time.Time
+		nomatch []time.Time
+		wantErr string
+	}{
+		{
+			name:    "names",
+			expr:    "0 18 * * fri",
+			match:   []time.Time{friday},
+			nomatch: []time.Time{friday.Add(time.Minute), friday.Add(24 * time.Hour)},
+		},
+		{
+			name:    "ranges and steps",
+			expr:    "*/15 9-17 * * mon-fri",
+			match:   []time.Time{friday.Add(-time.Hour + 15*time.Minute)},
+			nomatch: []time.Time{friday.Add(-time.Hour + 10*time.Minute), friday, friday.Add(-time.Hour + 24*time.Hour)},
+		},
+		{
+			name:  "sunday as 7",
+			expr:  "0 0 * * 7",
+			match: []time.Time{time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "day of month or day of week",
+			expr:    "0 0 1 * mon",
+			match:   []time.Time{time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+			nomatch: []time.Time{time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "macro",
+			expr:  "@yearly",
+			match: []time.Time{time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "missing fields",
+			expr:    "0 18 * *",
+			wantErr: "needs five fields",
+		},
+		{
+			name:    "out of range",
+			expr:    "0 24 * * *",
+			wantErr: "needs to be between 0 and 23",
+		},
+		{
+			name:    "bad range",
+			expr:    "0 0 * * fri-mon",
+			wantErr: "invalid range",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSchedule(tt.expr)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			for _, m := range tt.match {
+				assert.Assert(t, s.matches(m), "%s should match %s", tt.expr, m)
+			}
+			for _, m := range tt.nomatch {
+				assert.Assert(t, !s.matches(m), "%s should not match %s", tt.expr, m)
+			}
+		})
+	}
+}
+
+func TestLastStart(t *testing.T) {
+	// friday 2026-10-16
+	friday := time.Date(2026, 10, 16, 18, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		expr  string
+		since time.Time
+		want  time.Time
+	}{
+		{
+			name:  "same minute",
+			expr:  "30 18 * * fri",
+			since: friday.Add(-time.Hour),
+			want:  friday,
+		},
+		{
+			name:  "earlier the same day",
+			expr:  "*/20 9-17 * * *",
+			since: friday.Add(-24 * time.Hour),
+			want:  time.Date(2026, 10, 16, 17, 40, 0, 0, time.UTC),
+		},
+		{
+			name:  "later the same day is not started",
+			expr:  "45 18 * * *",
+			since: friday.Add(-48 * time.Hour),
+			want:  time.Date(2026, 10, 15, 18, 45, 0, 0, time.UTC),
+		},
+		{
+			name:  "weeks before",
+			expr:  "0 0 1 * *",
+			since: friday.Add(-31 * 24 * time.Hour),
+			want:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "before since",
+			expr:  "0 0 1 * *",
+			since: friday.Add(-24 * time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSchedule(tt.expr)
+			assert.NilError(t, err)
+			got, ok := s.lastStart(tt.since, friday)
+			assert.Equal(t, ok, !tt.want.IsZero())
+			assert.Assert(t, got.Equal(tt.want), "got %s, want %s", got, tt.want)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows []v1alpha1.FreezeWindow
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			windows: []v1alpha1.FreezeWindow{{Name: "weekend", Schedule: "0 18 * * fri", Duration: "62h", TimeZone: "Europe/Paris", Action: ActionQueue}},
+		},
+		{
+			name:    "no name",
+			windows: []v1alpha1.FreezeWindow{{Schedule: "0 18 * * fri", Duration: "62h"}},
+			wantErr: "has no name",
+		},
+		{
+			name: "duplicate",
+			windows: []v1alpha1.FreezeWindow{
+				{Name: "weekend", Schedule: "0 18 * * fri", Duration: "62h"},
+				{Name: "weekend", Schedule: "0 18 * * sat", Duration: "1h"},
+			},
+			wantErr: "defined more than once",
+		},
+		{
+			name:    "bad duration",
+			windows: []v1alpha1.FreezeWindow{{Name: "weekend", Schedule: "0 18 * * fri", Duration: "2d"}},
+			wantErr: "invalid duration",
+		},
+		{
+			name:    "too long",
+			windows: []v1alpha1.FreezeWindow{{Name: "release", Schedule: "0 0 1 12 *", Duration: "1000h"}},
+			wantErr: "needs to be between",
+		},
+		{
+			name:    "bad timezone",
+			windows: []v1alpha1.FreezeWindow{{Name: "weekend", Schedule: "0 18 * * fri", Duration: "62h", TimeZone: "Mars/Olympus"}},
+			wantErr: "invalid timezone",
+		},
+		{
+			name:    "bad branch glob",
+			windows: []v1alpha1.FreezeWindow{{Name: "weekend", Schedule: "0 18 * * fri", Duration: "62h", Branches: []string{"release-[0-9"}}},
+			wantErr: "invalid branch glob \"release-[0-9\"",
+		},
+		{
+			name:    "bad action",
+			windows: []v1alpha1.FreezeWindow{{Name: "weekend", Schedule: "0 18 * * fri", Duration: "62h", Action: "deny"}},
+			wantErr: "action \"deny\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&v1alpha1.Settings{FreezeWindows: tt.windows})
+			if tt.wantErr == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestActiveWindow(t *testing.T) {
+	settings := &v1alpha1.Settings{FreezeWindows: []v1alpha1.FreezeWindow{
+		{Name: "weekend", Schedule: "0 18 * * fri", Duration: "62h", Branches: []string{"main", "release-*"}},
+		{Name: "release", Schedule: "0 0 20 12 *", Duration: "336h", Action: ActionQueue},
+	}}
+	saturday := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	active := ActiveWindow(settings, "refs/heads/main", saturday)
+	assert.DeepEqual(t, active, &Active{Name: "weekend", Action: ActionSkip, End: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)})
+	assert.Assert(t, ActiveWindow(settings, "release-1.2", saturday) != nil)
+	assert.Assert(t, ActiveWindow(settings, "feature", saturday) == nil)
+	assert.Assert(t, ActiveWindow(settings, "main", saturday.Add(-24*time.Hour)) == nil)
+	assert.Assert(t, ActiveWindow(settings, "main", time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)) == nil)
+
+	// the release freeze is on all the branches
+	active = ActiveWindow(settings, "feature", time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC))
+	assert.DeepEqual(t, active, &Active{Name: "release", Action: ActionQueue, End: time.Date(2027, 1, 3, 0, 0, 0, 0, time.UTC)})
+
+	// the schedule is in the timezone of the window
+	paris := &v1alpha1.Settings{FreezeWindows: []v1alpha1.FreezeWindow{{Name: "evening", Schedule: "0 18 * * *", Duration: "1h", TimeZone: "Europe/Paris"}}}
+	assert.Assert(t, ActiveWindow(paris, "main", time.Date(2026, 10, 16, 16, 30, 0, 0, time.UTC)) != nil)
+	assert.Assert(t, ActiveWindow(paris, "main", time.Date(2026, 10, 16, 18, 30, 0, 0, time.UTC)) == nil)
+
+	// an invalid branch glob admitted before being validated is ignored
+	invalid := &v1alpha1.Settings{FreezeWindows: []v1alpha1.FreezeWindow{{Name: "weekend", Schedule: "0 18 * * fri", Duration: "62h", Branches: []string{"release-[0-9"}}}}
+	assert.Assert(t, ActiveWindow(invalid, "main", saturday) == nil)
+
+	// lifted by an admin
+	settings.FreezeOverride = true
+	assert.Assert(t, ActiveWindow(settings, "main", saturday) == nil)
+}
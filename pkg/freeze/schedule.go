@@ -0,0 +1,183 @@
+package freeze
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule is a parsed cron expression with the five standard fields, the
+// minutes, hours, days of the month, months and days of the week.
+type schedule struct {
+	minutes, hours, doms, months, dows uint64
+	// anyDom and anyDow record a * in the day fields, a time matches any of
+	// the two day fields when both are restricted like with cron.
+	anyDom, anyDow bool
+}
+
+type field struct {
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField = field{min: 0, max: 59}
+	hourField   = field{min: 0, max: 23}
+	domField    = field{min: 1, max: 31}
+	monthField  = field{min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	dowField    = field{min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+var macros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+}
+
+// parseSchedule parses a cron expression like "0 18 * * fri", the fields
+// accept the lists, ranges and steps of cron and the names of the months and
+// of the days of the week.
+func parseSchedule(expr string) (*schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := macros[expr]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q, needs five fields: minute hour day-of-month month day-of-week", expr)
+	}
+	s := &schedule{anyDom: fields[2] == "*", anyDow: fields[4] == "*"}
+	var err error
+	for i, f := range []struct {
+		bits *uint64
+		def  field
+	}{
+		{&s.minutes, minuteField},
+		{&s.hours, hourField},
+		{&s.doms, domField},
+		{&s.months, monthField},
+		{&s.dows, dowField},
+	} {
+		if *f.bits, err = parseField(fields[i], f.def); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", expr, err)
+		}
+	}
+	// 7 is sunday too
+	if s.dows&(1<<7) != 0 {
+		s.dows |= 1
+	}
+	return s, nil
+}
+
+func parseField(value string, def field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+		}
+		start, end := def.min, def.max
+		if rangePart != "*" {
+			low, high, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = def.value(low); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = def.value(high); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = def.max
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < f.min || i > f.max {
+		return 0, fmt.Errorf("invalid value %q, needs to be between %d and %d", s, f.min, f.max)
+	}
+	return i, nil
+}
+
+// matches returns true if the minute of t is a start of the schedule.
+func (s *schedule) matches(t time.Time) bool {
+	return s.minutes&(1<<uint(t.Minute())) != 0 && s.hours&(1<<uint(t.Hour())) != 0 && s.matchesDay(t)
+}
+
+// matchesDay returns true if the schedule starts on the day of t.
+func (s *schedule) matchesDay(t time.Time) bool {
+	if s.months&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dom := s.doms&(1<<uint(t.Day())) != 0
+	dow := s.dows&(1<<uint(t.Weekday())) != 0
+	if s.anyDom || s.anyDow {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// lastStart returns the last start of the schedule between since and t. The
+// days are walked back from t, the start of a matching day is its last hour
+// and minute of the schedule, before t on the day of t.
+func (s *schedule) lastStart(since, t time.Time) (time.Time, bool) {
+	since = since.Truncate(time.Minute)
+	t = t.Truncate(time.Minute)
+	year, month, day := t.Date()
+	for i := 0; ; i++ {
+		date := time.Date(year, month, day-i, 0, 0, 0, 0, t.Location())
+		if !date.AddDate(0, 0, 1).After(since) {
+			return time.Time{}, false
+		}
+		if !s.matchesDay(date) {
+			continue
+		}
+		lastHour := 23
+		if i == 0 {
+			lastHour = t.Hour()
+		}
+		for hours := s.hours & (1<<uint(lastHour+1) - 1); hours != 0; hours &^= 1 << uint(bits.Len64(hours)-1) {
+			hour := bits.Len64(hours) - 1
+			lastMinute := 59
+			if i == 0 && hour == t.Hour() {
+				lastMinute = t.Minute()
+			}
+			minutes := s.minutes & (1<<uint(lastMinute+1) - 1)
+			if minutes == 0 {
+				continue
+			}
+			start := time.Date(year, month, day-i, hour, bits.Len64(minutes)-1, 0, 0, t.Location())
+			// a start skipped or repeated by a daylight saving change
+			if start.After(t) {
+				continue
+			}
+			if start.Before(since) {
+				return time.Time{}, false
+			}
+			return start, true
+		}
+	}
+}
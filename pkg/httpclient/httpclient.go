@@ -0,0 +1,256 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"go.uber.org/zap"
+)
+
+const (
+	// most programming languages  do not have a timeout, but c# does a default
+	// of 100 seconds so using that value.
+	ConnectMaxWaitTime = 100 * time.Second
+	RequestMaxWaitTime = 100 * time.Second
+)
+
+// Transport is an http.RoundTripper honoring the proxy, the custom CA bundles,
+// the minimal TLS version and the egress allowlist of the settings. The settings are read on every
+// request so a change of the configmap is applied without a restart, the
+// underlying transports are shared by all the Transports with the same
+// settings so their connections are reused from one event to the other.
+type Transport struct {
+	settings func() *settings.Settings
+	logger   *zap.SugaredLogger
+}
+
+// transports are the underlying transports by fingerprint of the settings
+// they have been made for.
+var (
+	transportsMu sync.Mutex
+	transports   = map[string]*http.Transport{}
+)
+
+// EgressDeniedError is returned for a request to a host not allowed by the
+// egress-allowed-hosts setting.
+type EgressDeniedError struct {
+	Host string
+}
+
+func (e *EgressDeniedError) Error() string {
+	return fmt.Sprintf("outbound request to %s denied, the host is not allowed by the egress-allowed-hosts setting", e.Host)
+}
+
+// NewTransport returns a Transport reading the settings with getSettings, a
+// nil getSettings or a nil settings uses the proxy of the environment and the
+// system certificates. The denied and, when auditing, all the outbound
+// requests are logged with the logger when not nil.
+func NewTransport(getSettings func() *settings.Settings, logger *zap.SugaredLogger) *Transport {
+	return &Transport{
+		settings: getSettings,
+		logger:   logger,
+	}
+}
+
+// NewClient returns an http client for the settings.
+func NewClient(s *settings.Settings, logger *zap.SugaredLogger) *http.Client {
+	return &http.Client{
+		Timeout:   RequestMaxWaitTime,
+		Transport: NewTransport(func() *settings.Settings { return s }, logger),
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var s *settings.Settings
+	if t.settings != nil {
+		s = t.settings()
+	}
+	if s != nil && !s.EgressAllowed(req.URL.Host) {
+		err := &EgressDeniedError{Host: req.URL.Host}
+		if t.logger != nil {
+			t.logger.Warnw("outbound request denied", "method", req.Method, "host", req.URL.Host, "path", req.URL.Path)
+		}
+		return nil, err
+	}
+	tr, err := t.transport(s, req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := tr.RoundTrip(req)
+	if s != nil && s.EgressAudit && t.logger != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.logger.Infow("outbound request", "method", req.Method, "host", req.URL.Host, "path", req.URL.Path, "status", status, "error", err)
+	}
+	return resp, err
+}
+
+// transport returns the transport of the host for the settings, only the
+// hosts with a custom CA bundle get their own transport. A bundle updated on
+// the disk, like a rotated secret, gets a new transport.
+func (t *Transport) transport(s *settings.Settings, host string) (*http.Transport, error) {
+	var bundle string
+	var key string
+	if s != nil {
+		bundle = caBundle(s.CABundlesByHost(), host)
+		var modTime string
+		if bundle != "" {
+			if fi, err := os.Stat(bundle); err == nil {
+				modTime = fi.ModTime().String()
+			}
+		}
+		key = strings.Join([]string{s.HTTPProxy, s.HTTPSProxy, s.NoProxy, s.TLSMinVersion, bundle, modTime}, "|")
+	}
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if tr, ok := transports[key]; ok {
+		return tr, nil
+	}
+	tr, err := newHTTPTransport(s, bundle)
+	if err != nil {
+		return nil, err
+	}
+	transports[key] = tr
+	return tr, nil
+}
+
+func newHTTPTransport(s *settings.Settings, bundle string) (*http.Transport, error) {
+	tr := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout: ConnectMaxWaitTime,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if s == nil {
+		return tr, nil
+	}
+	if s.HTTPProxy != "" || s.HTTPSProxy != "" || s.NoProxy != "" {
+		tr.Proxy = ProxyFunc(s.HTTPProxy, s.HTTPSProxy, s.NoProxy)
+	}
+	if version := s.TLSMinVersionValue(); version != 0 || bundle != "" {
+		tr.TLSClientConfig = &tls.Config{MinVersion: version} //nolint: gosec // 0 is the Go default
+	}
+	if bundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(bundle)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the ca bundle %s: %w", bundle, err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in the ca bundle %s", bundle)
+		}
+		tr.TLSClientConfig.RootCAs = pool
+	}
+	return tr, nil
+}
+
+// caBundle returns the CA bundle of the host, a host with a port uses the
+// bundle of the hostname when there is none for the host and the port.
+func caBundle(bundles map[string]string, host string) string {
+	host = strings.ToLower(host)
+	if bundle, ok := bundles[host]; ok {
+		return bundle
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		return bundles[hostname]
+	}
+	return ""
+}
+
+// ProxyFunc returns a proxy function using httpProxy for the http requests
+// and httpsProxy for the https requests, unless the host matches noProxy. It
+// follows the format of the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables, which are not used.
+func ProxyFunc(httpProxy, httpsProxy, noProxy string) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxy := httpProxy
+		if req.URL.Scheme == "https" {
+			proxy = httpsProxy
+		}
+		if proxy == "" || !useProxy(noProxy, req.URL) {
+			return nil, nil
+		}
+		return url.Parse(proxy)
+	}
+}
+
+// useProxy returns false if the url matches one of the comma separated
+// entries of noProxy: "*", an IP, a CIDR or a domain name matching its
+// subdomains, optionally with a port.
+func useProxy(noProxy string, u *url.URL) bool {
+	hostname := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	if hostname == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(hostname)
+	if ip != nil && ip.IsLoopback() {
+		return false
+	}
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return false
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return false
+			}
+			continue
+		}
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return false
+			}
+			continue
+		}
+		entryHost = strings.TrimPrefix(entryHost, "*")
+		if strings.HasPrefix(entryHost, ".") {
+			if strings.HasSuffix(hostname, entryHost) || hostname == entryHost[1:] {
+				return false
+			}
+			continue
+		}
+		if hostname == entryHost || strings.HasSuffix(hostname, "."+entryHost) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+)
+
+func TestProxyFunc(t *testing.T) {
+	proxy := ProxyFunc("http://proxy:3128", "http://secure-proxy:3128", "internal.example.com, .corp, 10.0.0.0/8, 192.168.1.1, git.example.com:8443")
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "http://hub.tekton.dev/v1", want: "http://proxy:3128"},
+		{url: "https://api.github.com/repos", want: "http://secure-proxy:3128"},
+		{url: "https://internal.example.com/api", want: ""},
+		{url: "https://gitlab.internal.example.com/api", want: ""},
+		{url: "https://ghe.corp/api", want: ""},
+		{url: "https://corp/api", want: ""},
+		{url: "https://10.1.2.3/api", want: ""},
+		{url: "https://192.168.1.1/api", want: ""},
+		{url: "https://192.168.1.2/api", want: "http://secure-proxy:3128"},
+		{url: "https://git.example.com:8443/api", want: ""},
+		{url: "https://git.example.com/api", want: "http://secure-proxy:3128"},
+		{url: "https://localhost/api", want: ""},
+		{url: "http://127.0.0.1:8080/api", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			assert.NilError(t, err)
+			got, err := proxy(&http.Request{URL: u})
+			assert.NilError(t, err)
+			if tt.want == "" {
+				assert.Assert(t, got == nil, "expected no proxy, got %s", got)
+				return
+			}
+			assert.Equal(t, got.String(), tt.want)
+		})
+	}
+
+	noHTTPProxy := ProxyFunc("", "http://secure-proxy:3128", "*")
+	u, _ := url.Parse("https://api.github.com")
+	got, err := noHTTPProxy(&http.Request{URL: u})
+	assert.NilError(t, err)
+	assert.Assert(t, got == nil)
+}
+
+// syncedSettings returns the settings of the config, parsed like the
+// controller does.
+func syncedSettings(t *testing.T, config map[string]string) *settings.Settings {
+	t.Helper()
+	s := &settings.Settings{}
+	assert.NilError(t, settings.SyncConfig(zap.NewNop().Sugar(), s, config))
+	return s
+}
+
+func TestTransportCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "hello")
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NilError(t, err)
+
+	bundle := filepath.Join(t.TempDir(), "ca.crt")
+	assert.NilError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	}), 0o600))
+
+	// without the bundle the self signed certificate is refused
+	_, err = NewClient(&settings.Settings{}, nil).Get(server.URL)
+	assert.ErrorContains(t, err, "certificate")
+
+	s := syncedSettings(t, map[string]string{"ca-bundles": fmt.Sprintf("%s=%s", serverURL.Hostname(), bundle)})
+	resp, err := NewClient(s, nil).Get(server.URL)
+	assert.NilError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	s = syncedSettings(t, map[string]string{"ca-bundles": fmt.Sprintf("%s=%s", serverURL.Host, filepath.Join(t.TempDir(), "missing.crt"))})
+	_, err = NewClient(s, nil).Get(server.URL)
+	assert.ErrorContains(t, err, "cannot read the ca bundle")
+}
+
+func TestTransportTLSMinVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "hello")
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12} //nolint: gosec
+	server.StartTLS()
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NilError(t, err)
+	bundle := filepath.Join(t.TempDir(), "ca.crt")
+	assert.NilError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	}), 0o600))
+
+	s := syncedSettings(t, map[string]string{"ca-bundles": fmt.Sprintf("%s=%s", serverURL.Host, bundle), "tls-min-version": "1.2"})
+	resp, err := NewClient(s, nil).Get(server.URL)
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	s = syncedSettings(t, map[string]string{"ca-bundles": fmt.Sprintf("%s=%s", serverURL.Host, bundle), "tls-min-version": "1.3"})
+	_, err = NewClient(s, nil).Get(server.URL)
+	assert.Assert(t, err != nil && strings.Contains(err.Error(), "protocol version"), err)
+}
+
+func TestTransportSettingsUpdated(t *testing.T) {
+	s := &settings.Settings{}
+	tr := NewTransport(func() *settings.Settings { return s }, nil)
+	first, err := tr.transport(s, "api.github.com")
+	assert.NilError(t, err)
+	again, err := tr.transport(s, "api.github.com")
+	assert.NilError(t, err)
+	assert.Assert(t, first == again)
+
+	s = &settings.Settings{HTTPSProxy: "http://proxy:3128"}
+	updated, err := tr.transport(s, "api.github.com")
+	assert.NilError(t, err)
+	assert.Assert(t, first != updated)
+}
+
+func TestTransportEgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "hello")
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NilError(t, err)
+
+	observer, logs := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observer).Sugar()
+
+	s := &settings.Settings{}
+	assert.NilError(t, settings.SyncConfig(logger, s, map[string]string{"egress-allowed-hosts": "github.com"}))
+	_, err = NewClient(s, logger).Get(server.URL + "/denied")
+	var denied *EgressDeniedError
+	assert.Assert(t, errors.As(err, &denied), err)
+	assert.Equal(t, denied.Host, serverURL.Host)
+	assert.ErrorContains(t, err, "is not allowed by the egress-allowed-hosts setting")
+	assert.Equal(t, logs.FilterMessage("outbound request denied").Len(), 1)
+
+	// not audited by default
+	s = &settings.Settings{}
+	assert.NilError(t, settings.SyncConfig(logger, s, map[string]string{"egress-allowed-hosts": serverURL.Hostname()}))
+	resp, err := NewClient(s, logger).Get(server.URL + "/allowed")
+	assert.NilError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, logs.FilterMessage("outbound request").Len(), 0)
+
+	s.EgressAudit = true
+	resp, err = NewClient(s, logger).Get(server.URL + "/audited")
+	assert.NilError(t, err)
+	resp.Body.Close()
+	audited := logs.FilterMessage("outbound request").All()
+	assert.Equal(t, len(audited), 1)
+	assert.Equal(t, audited[0].ContextMap()["path"], "/audited")
+	assert.Equal(t, audited[0].ContextMap()["status"], int64(http.StatusOK))
+}
+
+func TestTransportShared(t *testing.T) {
+	s := &settings.Settings{HTTPSProxy: "http://shared-proxy:3128"}
+	first, err := NewTransport(func() *settings.Settings { return s }, nil).transport(s, "api.github.com")
+	assert.NilError(t, err)
+	second, err := NewTransport(func() *settings.Settings { return s }, nil).transport(s, "api.github.com")
+	assert.NilError(t, err)
+	assert.Assert(t, first == second, "the transports of the same settings are not shared")
+
+	other := &settings.Settings{HTTPSProxy: "http://other-proxy:3128"}
+	third, err := NewTransport(func() *settings.Settings { return other }, nil).transport(other, "api.github.com")
+	assert.NilError(t, err)
+	assert.Assert(t, first != third, "the transports of different settings are shared")
+}
@@ -56,6 +56,15 @@ func GetResource(ctx context.Context, cli *params.Run, catalogName, resource, ki
 	var rawURL string
 	var err error
 
+	value, _ := cli.Info.Pac.HubCatalogs.Load(catalogName)
+	if catalogValue, ok := value.(settings.HubCatalog); ok && catalogValue.Type == settings.HubCatalogTypeLocal {
+		data, err := getLocalResource(catalogValue.URL, resource, kind)
+		if err != nil {
+			return "", fmt.Errorf("could not fetch remote %s %s: %w", kind, resource, err)
+		}
+		return data, nil
+	}
+
 	if strings.Contains(resource, ":") {
 		rawURL, err = getSpecificVersion(ctx, cli, catalogName, resource, kind)
 	} else {
@@ -0,0 +1,137 @@
+package hub
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// a flat catalog, for example a configmap mounted in the controller, has the
+// files named kind_name_version.yaml.
+var flatCatalogFileRegex = regexp.MustCompile(`^([a-z]+)_([^_]+)_([^_]+)\.ya?ml$`)
+
+// getLocalResource gets a resource from a local catalog, a directory of the
+// controller synced from a configmap or an OCI artifact. The directory is
+// either in the layout of the tekton catalog repository:
+//
+//	task/git-clone/0.9/git-clone.yaml
+//
+// or flat with the kind, name and version in the file name:
+//
+//	task_git-clone_0.9.yaml
+//
+// The latest version is used when the resource has no version.
+func getLocalResource(catalogDir, resource, kind string) (string, error) {
+	resourceName, version, _ := strings.Cut(resource, ":")
+	if err := validLocalName(resourceName); err != nil {
+		return "", err
+	}
+	versions, err := localVersions(catalogDir, resourceName, kind)
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("%s %s not found in the local catalog %s", kind, resourceName, catalogDir)
+	}
+	if version == "" {
+		for v := range versions {
+			if version == "" || compareVersions(v, version) > 0 {
+				version = v
+			}
+		}
+	}
+	fpath, ok := versions[version]
+	if !ok {
+		return "", fmt.Errorf("version %s of %s %s not found in the local catalog %s", version, kind, resourceName, catalogDir)
+	}
+	if !insideDir(catalogDir, fpath) {
+		return "", fmt.Errorf("%s %s:%s is outside of the local catalog %s", kind, resourceName, version, catalogDir)
+	}
+	data, err := os.ReadFile(fpath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s %s:%s from the local catalog: %w", kind, resourceName, version, err)
+	}
+	return string(data), nil
+}
+
+// validLocalName makes sure the name of a resource, coming from the
+// PipelineRuns of the users, can't be used to read outside of the local
+// catalog.
+func validLocalName(name string) error {
+	if name == "" || name == "." || strings.Contains(name, "..") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid resource name %q for the local catalog", name)
+	}
+	return nil
+}
+
+// insideDir returns true if the cleaned path is inside the directory.
+func insideDir(dir, fpath string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(fpath))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// localVersions returns the path of the file of each version of a resource in
+// the local catalog.
+func localVersions(catalogDir, resourceName, kind string) (map[string]string, error) {
+	versions := map[string]string{}
+	entries, err := os.ReadDir(catalogDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the local catalog %s: %w", catalogDir, err)
+	}
+	for _, entry := range entries {
+		m := flatCatalogFileRegex.FindStringSubmatch(entry.Name())
+		if len(m) == 0 || m[1] != kind || m[2] != resourceName {
+			continue
+		}
+		versions[m[3]] = filepath.Join(catalogDir, entry.Name())
+	}
+
+	resourceDir := filepath.Join(catalogDir, kind, resourceName)
+	entries, err = os.ReadDir(resourceDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cannot read the local catalog %s: %w", catalogDir, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		for _, ext := range []string{".yaml", ".yml"} {
+			fpath := filepath.Join(resourceDir, entry.Name(), resourceName+ext)
+			if _, err := os.Stat(fpath); err == nil {
+				versions[entry.Name()] = fpath
+				break
+			}
+		}
+	}
+	return versions, nil
+}
+
+// compareVersions compares two dotted versions number by number, for example
+// 0.10 is greater than 0.9.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xi, xerr := strconv.Atoi(x)
+		yi, yerr := strconv.Atoi(y)
+		switch {
+		case xerr == nil && yerr == nil && xi != yi:
+			if xi > yi {
+				return 1
+			}
+			return -1
+		case (xerr != nil || yerr != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+package hub
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestGetLocalResource(t *testing.T) {
+	catalogDir := t.TempDir()
+	files := map[string]string{
+		"task/git-clone/0.9/git-clone.yaml":  "git-clone 0.9",
+		"task/git-clone/0.10/git-clone.yaml": "git-clone 0.10",
+		"task/git-clone/README.md":           "readme",
+		"task_buildah_0.5.yaml":              "buildah 0.5",
+		"task_buildah_0.6.yml":               "buildah 0.6",
+		"pipeline_buildah_1.0.yaml":          "pipeline buildah 1.0",
+	}
+	for fname, content := range files {
+		fpath := filepath.Join(catalogDir, fname)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(fpath), 0o755))
+		assert.NilError(t, os.WriteFile(fpath, []byte(content), 0o600))
+	}
+
+	var hubCatalogs sync.Map
+	hubCatalogs.Store("airgap", settings.HubCatalog{
+		ID:   "airgap",
+		Name: "tekton",
+		URL:  catalogDir,
+		Type: settings.HubCatalogTypeLocal,
+	})
+	hubCatalogs.Store("missing", settings.HubCatalog{
+		ID:   "missing",
+		Name: "tekton",
+		URL:  filepath.Join(catalogDir, "missing"),
+		Type: settings.HubCatalogTypeLocal,
+	})
+	cs := &params.Run{
+		Info: info.Info{Pac: &info.PacOpts{Settings: settings.Settings{HubCatalogs: &hubCatalogs}}},
+	}
+
+	tests := []struct {
+		name     string
+		catalog  string
+		resource string
+		kind     string
+		want     string
+		wantErr  string
+	}{
+		{name: "latest in catalog layout", catalog: "airgap", resource: "git-clone", kind: "task", want: "git-clone 0.10"},
+		{name: "version in catalog layout", catalog: "airgap", resource: "git-clone:0.9", kind: "task", want: "git-clone 0.9"},
+		{name: "latest in flat layout", catalog: "airgap", resource: "buildah", kind: "task", want: "buildah 0.6"},
+		{name: "version in flat layout", catalog: "airgap", resource: "buildah:0.5", kind: "task", want: "buildah 0.5"},
+		{name: "pipeline", catalog: "airgap", resource: "buildah", kind: "pipeline", want: "pipeline buildah 1.0"},
+		{
+			name: "not found", catalog: "airgap", resource: "kaniko", kind: "task",
+			wantErr: "could not fetch remote task kaniko: task kaniko not found in the local catalog " + catalogDir,
+		},
+		{
+			name: "version not found", catalog: "airgap", resource: "buildah:0.7", kind: "task",
+			wantErr: "could not fetch remote task buildah:0.7: version 0.7 of task buildah not found in the local catalog " + catalogDir,
+		},
+		{
+			name: "missing catalog directory", catalog: "missing", resource: "buildah", kind: "task",
+			wantErr: "cannot read the local catalog",
+		},
+		{
+			name: "parent directory in the name", catalog: "airgap", resource: "../task/git-clone:0.9", kind: "task",
+			wantErr: "invalid resource name \"../task/git-clone\" for the local catalog",
+		},
+		{
+			name: "path separator in the name", catalog: "airgap", resource: "git-clone/0.9", kind: "task",
+			wantErr: "invalid resource name \"git-clone/0.9\" for the local catalog",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			got, err := GetResource(ctx, cs, tt.catalog, tt.resource, tt.kind)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, compareVersions("0.10", "0.9"), 1)
+	assert.Equal(t, compareVersions("0.9", "0.10"), -1)
+	assert.Equal(t, compareVersions("1.0", "1.0"), 0)
+	assert.Equal(t, compareVersions("1.0.1", "1.0"), 1)
+}
+
+func TestInsideDir(t *testing.T) {
+	assert.Assert(t, insideDir("/catalog", "/catalog/task/git-clone/0.9/git-clone.yaml"))
+	assert.Assert(t, insideDir("/catalog/", "/catalog/task_buildah_0.5.yaml"))
+	assert.Assert(t, !insideDir("/catalog", "/catalog/../etc/passwd"))
+	assert.Assert(t, !insideDir("/catalog", "/catalog-other/task.yaml"))
+	assert.Assert(t, !insideDir("/catalog", "/etc/passwd"))
+}
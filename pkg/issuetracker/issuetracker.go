@@ -0,0 +1,131 @@
+package issuetracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+)
+
+const (
+	// TypeJira is the Jira issue tracker, on Jira Cloud or Jira Data Center.
+	TypeJira = "jira"
+	// LinkTypeRemoteLink links the PipelineRuns as remote links of the
+	// issues, updated on each run of the same PipelineRun.
+	LinkTypeRemoteLink = "remote_link"
+	// LinkTypeComment links the PipelineRuns with a comment on the issues.
+	LinkTypeComment = "comment"
+	// DefaultKeyPattern matches the issue keys of Jira, like PROJ-123.
+	DefaultKeyPattern = `\b[A-Z][A-Z0-9_]+-[1-9][0-9]*\b`
+	// DefaultSecretKey is the key of the API token in the secret of the
+	// issue tracker.
+	DefaultSecretKey = "token"
+	// MaxIssueKeys is the maximum number of issues a PipelineRun is linked
+	// to, each link is a request to the issue tracker.
+	MaxIssueKeys = 10
+)
+
+// Run is a PipelineRun linked to the issues.
+type Run struct {
+	// ID identifies the PipelineRun across its runs, the remote link of an
+	// issue is updated instead of being added again.
+	ID string
+	// Title is the title of the link, like the name of the PipelineRun.
+	Title string
+	// Summary describes the run, like the PipelineRun has succeeded on a
+	// commit.
+	Summary string
+	// URL is the URL of the PipelineRun on the console.
+	URL string
+	// Succeeded is true when the PipelineRun has succeeded.
+	Succeeded bool
+	// Application is the name of the application linking the run.
+	Application string
+}
+
+// Interface links the PipelineRuns to the issues of a tracker.
+type Interface interface {
+	GetName() string
+	LinkRun(ctx context.Context, key string, run Run) error
+}
+
+// New returns the issue tracker of the issue_tracker setting, authenticated
+// with the API token of its secret.
+func New(tracker *v1alpha1.IssueTracker, token string, client *http.Client) (Interface, error) {
+	switch tracker.Type {
+	case TypeJira:
+		linkType := tracker.LinkType
+		if linkType == "" {
+			linkType = LinkTypeRemoteLink
+		}
+		return &jira{
+			url:      tracker.URL,
+			user:     tracker.User,
+			token:    token,
+			linkType: linkType,
+			client:   client,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown issue tracker %s", tracker.Type)
+}
+
+// IssueKeys returns the issue keys found in the texts with the key pattern
+// of the issue tracker, in the order they are found and without duplicates,
+// up to MaxIssueKeys.
+func IssueKeys(tracker *v1alpha1.IssueTracker, texts ...string) ([]string, error) {
+	pattern := DefaultKeyPattern
+	if tracker.KeyPattern != "" {
+		pattern = tracker.KeyPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key_pattern of the issue tracker: %w", err)
+	}
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, key := range re.FindAllString(text, -1) {
+			if seen[key] {
+				continue
+			}
+			if len(keys) == MaxIssueKeys {
+				return keys, nil
+			}
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Validate checks the issue_tracker setting of a Repository.
+func Validate(settings *v1alpha1.Settings) error {
+	if settings == nil || settings.IssueTracker == nil {
+		return nil
+	}
+	tracker := settings.IssueTracker
+	if tracker.Type != TypeJira {
+		return fmt.Errorf("unknown issue tracker %q, only %s is supported", tracker.Type, TypeJira)
+	}
+	// the API token is sent to the tracker, never in clear text
+	if u, err := url.Parse(tracker.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("the url %q of the issue tracker must be an https url", tracker.URL)
+	}
+	if tracker.Secret.Name == "" {
+		return fmt.Errorf("the issue tracker needs a secret with its API token")
+	}
+	switch tracker.LinkType {
+	case "", LinkTypeRemoteLink, LinkTypeComment:
+	default:
+		return fmt.Errorf("unknown link_type %q of the issue tracker, it should be %s or %s", tracker.LinkType, LinkTypeRemoteLink, LinkTypeComment)
+	}
+	if tracker.KeyPattern != "" {
+		if _, err := regexp.Compile(tracker.KeyPattern); err != nil {
+			return fmt.Errorf("invalid key_pattern of the issue tracker: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package issuetracker
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+)
+
+func TestIssueKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		texts   []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "jira keys",
+			texts: []string{"refs/heads/PROJ-12-fix-login", "PROJ-12 OPS-7: fix the login", ""},
+			want:  []string{"PROJ-12", "OPS-7"},
+		},
+		{
+			name:  "no keys",
+			texts: []string{"main", "fix the login of the users", "proj-12 in lower case"},
+			want:  []string{},
+		},
+		{
+			name:    "custom pattern",
+			pattern: `#[0-9]+`,
+			texts:   []string{"issue-4-login", "fix #4 and #12"},
+			want:    []string{"#4", "#12"},
+		},
+		{
+			name:  "too many keys",
+			texts: []string{"PROJ-1 PROJ-2 PROJ-3 PROJ-4 PROJ-5 PROJ-6", "PROJ-1 PROJ-7 PROJ-8 PROJ-9 PROJ-10 PROJ-11 PROJ-12"},
+			want:  []string{"PROJ-1", "PROJ-2", "PROJ-3", "PROJ-4", "PROJ-5", "PROJ-6", "PROJ-7", "PROJ-8", "PROJ-9", "PROJ-10"},
+		},
+		{
+			name:    "invalid pattern",
+			pattern: `PROJ-[0-9+`,
+			wantErr: "invalid key_pattern of the issue tracker",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := IssueKeys(&v1alpha1.IssueTracker{KeyPattern: tt.pattern}, tt.texts...)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, keys, tt.want)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *v1alpha1.IssueTracker {
+		return &v1alpha1.IssueTracker{Type: TypeJira, URL: "https://example.atlassian.net", Secret: v1alpha1.Secret{Name: "jira"}}
+	}
+	tests := []struct {
+		name    string
+		tracker func() *v1alpha1.IssueTracker
+		wantErr string
+	}{
+		{name: "not set", tracker: func() *v1alpha1.IssueTracker { return nil }},
+		{name: "valid", tracker: valid},
+		{
+			name: "unknown type",
+			tracker: func() *v1alpha1.IssueTracker {
+				it := valid()
+				it.Type = "redmine"
+				return it
+			},
+			wantErr: `unknown issue tracker "redmine", only jira is supported`,
+		},
+		{
+			name: "url without scheme",
+			tracker: func() *v1alpha1.IssueTracker {
+				it := valid()
+				it.URL = "example.atlassian.net"
+				return it
+			},
+			wantErr: `the url "example.atlassian.net" of the issue tracker must be an https url`,
+		},
+		{
+			name: "plain http url",
+			tracker: func() *v1alpha1.IssueTracker {
+				it := valid()
+				it.URL = "http://jira.example.com"
+				return it
+			},
+			wantErr: `the url "http://jira.example.com" of the issue tracker must be an https url`,
+		},
+		{
+			name: "no secret",
+			tracker: func() *v1alpha1.IssueTracker {
+				it := valid()
+				it.Secret = v1alpha1.Secret{}
+				return it
+			},
+			wantErr: "the issue tracker needs a secret with its API token",
+		},
+		{
+			name: "unknown link type",
+			tracker: func() *v1alpha1.IssueTracker {
+				it := valid()
+				it.LinkType = "mention"
+				return it
+			},
+			wantErr: `unknown link_type "mention" of the issue tracker, it should be remote_link or comment`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&v1alpha1.Settings{IssueTracker: tt.tracker()})
+			if tt.wantErr == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Error(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package issuetracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// jiraApplicationType is the type of the application of the remote links,
+// grouping them on the issues.
+const jiraApplicationType = "dev.tekton.pipelinesascode"
+
+// jira links the PipelineRuns to the issues with the REST API version 2,
+// available on both Jira Cloud and Jira Data Center.
+type jira struct {
+	url      string
+	user     string
+	token    string
+	linkType string
+	client   *http.Client
+}
+
+type jiraRemoteLink struct {
+	GlobalID     string               `json:"globalId"`
+	Application  jiraApplication      `json:"application"`
+	Relationship string               `json:"relationship,omitempty"`
+	Object       jiraRemoteLinkObject `json:"object"`
+}
+
+type jiraApplication struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type jiraRemoteLinkObject struct {
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Summary string `json:"summary,omitempty"`
+}
+
+type jiraComment struct {
+	Body string `json:"body"`
+}
+
+func (j *jira) GetName() string {
+	return TypeJira
+}
+
+// LinkRun adds the run as a remote link of the issue, the remote link with
+// the same global ID is updated by Jira, or comments on the issue.
+func (j *jira) LinkRun(ctx context.Context, key string, run Run) error {
+	path := fmt.Sprintf("/rest/api/2/issue/%s/", url.PathEscape(key))
+	title := "❌ " + run.Title
+	if run.Succeeded {
+		title = "✅ " + run.Title
+	}
+	if j.linkType == LinkTypeComment {
+		return j.post(ctx, path+"comment", jiraComment{
+			Body: fmt.Sprintf("[%s|%s]: %s", title, run.URL, run.Summary),
+		})
+	}
+	return j.post(ctx, path+"remotelink", jiraRemoteLink{
+		GlobalID:     fmt.Sprintf("%s=%s", jiraApplicationType, run.ID),
+		Application:  jiraApplication{Type: jiraApplicationType, Name: run.Application},
+		Relationship: "PipelineRun",
+		Object: jiraRemoteLinkObject{
+			URL:     run.URL,
+			Title:   title,
+			Summary: run.Summary,
+		},
+	})
+}
+
+func (j *jira) post(ctx context.Context, path string, object any) error {
+	body, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(j.url, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	// the API tokens of Jira Cloud go with the email of the account, the
+	// personal access tokens of Jira Data Center are bearer tokens
+	if j.user != "" {
+		req.SetBasicAuth(j.user, j.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("jira answered with the status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
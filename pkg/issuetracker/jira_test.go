@@ -0,0 +1,91 @@
+package issuetracker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+)
+
+func TestJiraLinkRun(t *testing.T) {
+	run := Run{
+		ID:          "ns/repo/build",
+		Title:       "org/repo build",
+		Summary:     "PipelineRun build-abcde has succeeded on 1234567 of main",
+		URL:         "https://console/build-abcde",
+		Succeeded:   true,
+		Application: "Pipelines as Code CI",
+	}
+	tests := []struct {
+		name      string
+		tracker   v1alpha1.IssueTracker
+		status    int
+		wantPath  string
+		wantAuth  string
+		wantBody  string
+		wantError string
+	}{
+		{
+			name:     "remote link with an API token of Jira Cloud",
+			tracker:  v1alpha1.IssueTracker{User: "ci@example.com"},
+			status:   http.StatusCreated,
+			wantPath: "/rest/api/2/issue/PROJ-12/remotelink",
+			wantAuth: "Basic Y2lAZXhhbXBsZS5jb206c2VjcmV0",
+			wantBody: `{"globalId":"dev.tekton.pipelinesascode=ns/repo/build","application":{"type":"dev.tekton.pipelinesascode","name":"Pipelines as Code CI"},` +
+				`"relationship":"PipelineRun","object":{"url":"https://console/build-abcde","title":"✅ org/repo build","summary":"PipelineRun build-abcde has succeeded on 1234567 of main"}}`,
+		},
+		{
+			name:     "comment with a personal access token",
+			tracker:  v1alpha1.IssueTracker{LinkType: LinkTypeComment},
+			status:   http.StatusCreated,
+			wantPath: "/rest/api/2/issue/PROJ-12/comment",
+			wantAuth: "Bearer secret",
+			wantBody: `{"body":"[✅ org/repo build|https://console/build-abcde]: PipelineRun build-abcde has succeeded on 1234567 of main"}`,
+		},
+		{
+			name:      "issue not found",
+			status:    http.StatusNotFound,
+			wantPath:  "/rest/api/2/issue/PROJ-12/remotelink",
+			wantAuth:  "Bearer secret",
+			wantError: `jira answered with the status 404: {"errorMessages":["Issue does not exist"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotAuth, gotBody := "", "", ""
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.Method, http.MethodPost)
+				gotPath = r.URL.Path
+				gotAuth = r.Header.Get("Authorization")
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusNotFound {
+					_ = json.NewEncoder(w).Encode(map[string][]string{"errorMessages": {"Issue does not exist"}})
+				}
+			}))
+			defer server.Close()
+
+			tt.tracker.Type = TypeJira
+			tt.tracker.URL = server.URL + "/"
+			tracker, err := New(&tt.tracker, "secret", server.Client())
+			assert.NilError(t, err)
+			assert.Equal(t, tracker.GetName(), "jira")
+
+			err = tracker.LinkRun(context.Background(), "PROJ-12", run)
+			assert.Equal(t, gotPath, tt.wantPath)
+			assert.Equal(t, gotAuth, tt.wantAuth)
+			if tt.wantError != "" {
+				assert.Error(t, err, tt.wantError)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, gotBody, tt.wantBody)
+		})
+	}
+}
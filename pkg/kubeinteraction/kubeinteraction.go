@@ -15,8 +15,11 @@ type Interface interface {
 	CleanupPipelines(context.Context, *zap.SugaredLogger, *v1alpha1.Repository, *pipelinev1.PipelineRun, int) error
 	CreateSecret(ctx context.Context, ns string, secret *corev1.Secret) error
 	UpdateSecretWithOwnerRef(context.Context, *zap.SugaredLogger, string, string, *pipelinev1.PipelineRun) error
+	DeleteSecret(context.Context, *zap.SugaredLogger, string, string) error
+	CreateServiceAccountToken(context.Context, string, string, string, int64) (string, error)
 	GetSecret(context.Context, ktypes.GetSecretOpt) (string, error)
 	GetPodLogs(context.Context, string, string, string, int64) (string, error)
+	SyncRegistrySecrets(context.Context, *zap.SugaredLogger, string, string, string, []string) error
 }
 
 type Interaction struct {
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/google/uuid"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/version"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 )
@@ -19,6 +21,7 @@ const (
 	StateQueued    = "queued"
 	StateCompleted = "completed"
 	StateFailed    = "failed"
+	StateFrozen    = "frozen"
 )
 
 func AddLabelsAndAnnotations(event *info.Event, pipelineRun *tektonv1.PipelineRun, repo *apipac.Repository, providerConfig *info.ProviderConfig, paramsRun *params.Run) error {
@@ -41,6 +44,10 @@ func AddLabelsAndAnnotations(event *info.Event, pipelineRun *tektonv1.PipelineRu
 		keys.EventType:                 formatting.CleanValueKubernetes(event.EventType),
 	}
 
+	// a new ID for every PipelineRun, even the ones copied from another one
+	runID := uuid.NewString()
+	labels[keys.RunID] = runID
+
 	annotations := map[string]string{
 		keys.ShaTitle:      event.SHATitle,
 		keys.ShaURL:        event.SHAURL,
@@ -51,9 +58,11 @@ func AddLabelsAndAnnotations(event *info.Event, pipelineRun *tektonv1.PipelineRu
 		keys.Sender:        event.Sender,
 		keys.EventType:     event.EventType,
 		keys.Branch:        event.BaseBranch,
+		keys.SourceBranch:  event.HeadBranch,
 		keys.Repository:    repo.GetName(),
 		keys.GitProvider:   providerConfig.Name,
 		keys.State:         StateStarted,
+		keys.RunID:         runID,
 		keys.ControllerInfo: fmt.Sprintf(`{"name":"%s","configmap":"%s","secret":"%s", "gRepo": "%s"}`,
 			paramsinfo.Controller.Name, paramsinfo.Controller.Configmap, paramsinfo.Controller.Secret, paramsinfo.Controller.GlobalRepository),
 	}
@@ -63,6 +72,17 @@ func AddLabelsAndAnnotations(event *info.Event, pipelineRun *tektonv1.PipelineRu
 		annotations[keys.PullRequest] = strconv.Itoa(event.PullRequestNumber)
 	}
 
+	if event.Request != nil && event.EventType == triggertype.Incoming.String() {
+		if chain := event.Request.Header.Get(keys.FanOutChainHeader); chain != "" {
+			annotations[keys.FanOutChain] = chain
+		}
+	}
+
+	if event.DeploymentCallbackURL != "" {
+		annotations[keys.DeploymentEnvironment] = event.DeploymentEnvironment
+		annotations[keys.DeploymentCallbackURL] = event.DeploymentCallbackURL
+	}
+
 	// TODO: move to provider specific function
 	if providerConfig.Name == "github" || providerConfig.Name == "github-enterprise" {
 		if event.InstallationID != -1 {
@@ -73,6 +73,9 @@ func TestAddLabelsAndAnnotations(t *testing.T) {
 			assert.Equal(t, tt.args.pipelineRun.Annotations[keys.ShaURL], tt.args.event.SHAURL)
 			assert.Equal(t, tt.args.pipelineRun.Annotations[keys.ControllerInfo],
 				fmt.Sprintf(`{"name":"%s","configmap":"%s","secret":"%s", "gRepo": "%s"}`, tt.args.controllerInfo.Name, tt.args.controllerInfo.Configmap, tt.args.controllerInfo.Secret, tt.args.controllerInfo.GlobalRepository))
+			runID := tt.args.pipelineRun.Annotations[keys.RunID]
+			assert.Assert(t, runID != "")
+			assert.Equal(t, tt.args.pipelineRun.Labels[keys.RunID], runID)
 		})
 	}
 }
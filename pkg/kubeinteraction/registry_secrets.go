@@ -0,0 +1,146 @@
+package kubeinteraction
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// SyncRegistrySecrets clones the registry secrets of the source namespace in
+// the target namespace and adds them to the secrets and the image pull secrets
+// of the service account, only when the service account has opted in with the
+// registry-secrets annotation. The clones are updated when the source secrets
+// have been rotated, a secret of the same name not cloned by
+// Pipelines-as-Code is left untouched.
+func (k Interaction) SyncRegistrySecrets(ctx context.Context, logger *zap.SugaredLogger, sourceNS, targetNS, serviceAccount string, names []string) error {
+	sa, err := k.Run.Clients.Kube.CoreV1().ServiceAccounts(targetNS).Get(ctx, serviceAccount, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot get the service account %s/%s: %w", targetNS, serviceAccount, err)
+	}
+	if sa.GetAnnotations()[keys.RegistrySecrets] != "true" {
+		logger.Infof("the service account %s/%s has not the %s annotation, not adding the registry secrets to it", targetNS, serviceAccount, keys.RegistrySecrets)
+		return nil
+	}
+	for _, name := range names {
+		if sourceNS == targetNS {
+			continue
+		}
+		if err := k.cloneRegistrySecret(ctx, logger, sourceNS, targetNS, name); err != nil {
+			return err
+		}
+	}
+	return k.addSecretsToServiceAccount(ctx, logger, targetNS, serviceAccount, names)
+}
+
+func (k Interaction) cloneRegistrySecret(ctx context.Context, logger *zap.SugaredLogger, sourceNS, targetNS, name string) error {
+	source, err := k.Run.Clients.Kube.CoreV1().Secrets(sourceNS).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot get the registry secret %s/%s: %w", sourceNS, name, err)
+	}
+	if source.Type != corev1.SecretTypeDockerConfigJson && source.Type != corev1.SecretTypeDockercfg {
+		return fmt.Errorf("the registry secret %s/%s has the type %s instead of %s or %s", sourceNS, name, source.Type,
+			corev1.SecretTypeDockerConfigJson, corev1.SecretTypeDockercfg)
+	}
+	sourceRef := fmt.Sprintf("%s/%s", sourceNS, name)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := k.Run.Clients.Kube.CoreV1().Secrets(targetNS).Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			clone := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: targetNS,
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": pipelinesascode.GroupName,
+					},
+					Annotations: map[string]string{
+						keys.RegistrySecretSource: sourceRef,
+					},
+				},
+				Type: source.Type,
+				Data: source.Data,
+			}
+			if _, err := k.Run.Clients.Kube.CoreV1().Secrets(targetNS).Create(ctx, clone, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("cannot create the registry secret %s/%s: %w", targetNS, name, err)
+			}
+			logger.Infof("cloned the registry secret %s in the namespace %s", sourceRef, targetNS)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if existing.GetAnnotations()[keys.RegistrySecretSource] != sourceRef {
+			logger.Infof("the secret %s/%s has not been cloned from %s, not updating it", targetNS, name, sourceRef)
+			return nil
+		}
+		if existing.Type == source.Type && reflect.DeepEqual(existing.Data, source.Data) {
+			return nil
+		}
+		// the source secret has been rotated
+		existing.Type = source.Type
+		existing.Data = source.Data
+		if _, err := k.Run.Clients.Kube.CoreV1().Secrets(targetNS).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		logger.Infof("updated the registry secret %s/%s rotated in %s", targetNS, name, sourceRef)
+		return nil
+	})
+}
+
+func (k Interaction) addSecretsToServiceAccount(ctx context.Context, logger *zap.SugaredLogger, ns, serviceAccount string, names []string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sa, err := k.Run.Clients.Kube.CoreV1().ServiceAccounts(ns).Get(ctx, serviceAccount, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		changed := false
+		for _, name := range names {
+			if !hasSecretRef(sa.Secrets, name) {
+				sa.Secrets = append(sa.Secrets, corev1.ObjectReference{Name: name})
+				changed = true
+			}
+			if !hasLocalRef(sa.ImagePullSecrets, name) {
+				sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		if _, err := k.Run.Clients.Kube.CoreV1().ServiceAccounts(ns).Update(ctx, sa, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		logger.Infof("added the registry secrets %v to the service account %s/%s", names, ns, serviceAccount)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot add the registry secrets to the service account %s/%s: %w", ns, serviceAccount, err)
+	}
+	return nil
+}
+
+func hasSecretRef(refs []corev1.ObjectReference, name string) bool {
+	for _, ref := range refs {
+		if ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLocalRef(refs []corev1.LocalObjectReference, name string) bool {
+	for _, ref := range refs {
+		if ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package kubeinteraction
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestSyncRegistrySecrets(t *testing.T) {
+	registrySecret := func(ns, name string, secretType corev1.SecretType, data string, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Annotations: annotations},
+			Type:       secretType,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(data)},
+		}
+	}
+	cloned := map[string]string{keys.RegistrySecretSource: "pac/quay-push"}
+
+	tests := []struct {
+		name       string
+		secrets    []*corev1.Secret
+		notOptedIn bool
+		wantData   string
+		wantErr    string
+	}{
+		{
+			name:     "clone the secret",
+			secrets:  []*corev1.Secret{registrySecret("pac", "quay-push", corev1.SecretTypeDockerConfigJson, "new", nil)},
+			wantData: "new",
+		},
+		{
+			name: "update the rotated secret",
+			secrets: []*corev1.Secret{
+				registrySecret("pac", "quay-push", corev1.SecretTypeDockerConfigJson, "new", nil),
+				registrySecret("repo", "quay-push", corev1.SecretTypeDockerConfigJson, "old", cloned),
+			},
+			wantData: "new",
+		},
+		{
+			name: "leave the secret not cloned",
+			secrets: []*corev1.Secret{
+				registrySecret("pac", "quay-push", corev1.SecretTypeDockerConfigJson, "new", nil),
+				registrySecret("repo", "quay-push", corev1.SecretTypeDockerConfigJson, "mine", nil),
+			},
+			wantData: "mine",
+		},
+		{
+			name:       "service account not opted in",
+			secrets:    []*corev1.Secret{registrySecret("pac", "quay-push", corev1.SecretTypeDockerConfigJson, "new", nil)},
+			notOptedIn: true,
+		},
+		{
+			name:    "not a registry secret",
+			secrets: []*corev1.Secret{registrySecret("pac", "quay-push", corev1.SecretTypeOpaque, "new", nil)},
+			wantErr: "the registry secret pac/quay-push has the type Opaque",
+		},
+		{
+			name:    "no source secret",
+			wantErr: "cannot get the registry secret pac/quay-push",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{Secret: tt.secrets})
+			annotations := map[string]string{keys.RegistrySecrets: "true"}
+			if tt.notOptedIn {
+				annotations = nil
+			}
+			_, err := stdata.Kube.CoreV1().ServiceAccounts("repo").Create(ctx, &corev1.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Namespace: "repo", Name: "default", Annotations: annotations},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "quay-push"}},
+			}, metav1.CreateOptions{})
+			assert.NilError(t, err)
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			kint := Interaction{Run: &params.Run{Clients: clients.Clients{Kube: stdata.Kube}}}
+
+			err = kint.SyncRegistrySecrets(ctx, logger, "pac", "repo", "default", []string{"quay-push"})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+
+			if tt.notOptedIn {
+				_, err := stdata.Kube.CoreV1().Secrets("repo").Get(ctx, "quay-push", metav1.GetOptions{})
+				assert.ErrorContains(t, err, "not found")
+				sa, err := stdata.Kube.CoreV1().ServiceAccounts("repo").Get(ctx, "default", metav1.GetOptions{})
+				assert.NilError(t, err)
+				assert.Assert(t, len(sa.Secrets) == 0)
+				return
+			}
+
+			secret, err := stdata.Kube.CoreV1().Secrets("repo").Get(ctx, "quay-push", metav1.GetOptions{})
+			assert.NilError(t, err)
+			assert.Equal(t, string(secret.Data[corev1.DockerConfigJsonKey]), tt.wantData)
+
+			sa, err := stdata.Kube.CoreV1().ServiceAccounts("repo").Get(ctx, "default", metav1.GetOptions{})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, sa.Secrets, []corev1.ObjectReference{{Name: "quay-push"}})
+			assert.DeepEqual(t, sa.ImagePullSecrets, []corev1.LocalObjectReference{{Name: "quay-push"}})
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	ktypes "github.com/openshift-pipelines/pipelines-as-code/pkg/secrets/types"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"go.uber.org/zap"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -67,3 +68,18 @@ func (k Interaction) CreateSecret(ctx context.Context, ns string, secret *corev1
 	_, err := k.Run.Clients.Kube.CoreV1().Secrets(ns).Create(ctx, secret, metav1.CreateOptions{})
 	return err
 }
+
+// CreateServiceAccountToken requests a token of the service account for the
+// audience, valid for the number of seconds.
+func (k Interaction) CreateServiceAccountToken(ctx context.Context, ns, serviceAccount, audience string, expirationSeconds int64) (string, error) {
+	tr, err := k.Run.Clients.Kube.CoreV1().ServiceAccounts(ns).CreateToken(ctx, serviceAccount, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         []string{audience},
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("cannot request a token of the service account %s/%s: %w", ns, serviceAccount, err)
+	}
+	return tr.Status.Token, nil
+}
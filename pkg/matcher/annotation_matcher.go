@@ -26,8 +26,19 @@ const (
 	reValidateTag = `^\[(.*)\]$|^[^[\]\s]*$`
 )
 
-// prunBranch is value from annotations and baseBranch is event.Base value from event.
-func branchMatch(prunBranch, baseBranch string) bool {
+// ValidateBranchGlob checks that a branch pattern given to BranchMatch
+// compiles, BranchMatch panics on an invalid glob.
+func ValidateBranchGlob(pattern string) error {
+	if _, err := glob.Compile(pattern); err != nil {
+		return fmt.Errorf("invalid branch glob %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// BranchMatch matches a branch glob against the branch of an event, both can
+// be with or without the refs/heads/ prefix. prunBranch is value from
+// annotations and baseBranch is event.Base value from event.
+func BranchMatch(prunBranch, baseBranch string) bool {
 	// Helper function to match glob pattern
 	matchGlob := func(pattern, branch string) bool {
 		g := glob.MustCompile(pattern)
@@ -87,7 +98,7 @@ func getAnnotationValues(annotation string) ([]string, error) {
 	return split, nil
 }
 
-func getTargetBranch(prun *tektonv1.PipelineRun, event *info.Event) (bool, string, string, error) {
+func getTargetBranch(prun *tektonv1.PipelineRun, event *info.Event, aliases triggertype.Aliases) (bool, string, string, error) {
 	var targetEvent, targetBranch string
 	if key, ok := prun.GetObjectMeta().GetAnnotations()[keys.OnEvent]; ok {
 		targetEvents := []string{event.TriggerTarget.String()}
@@ -95,6 +106,10 @@ func getTargetBranch(prun *tektonv1.PipelineRun, event *info.Event) (bool, strin
 			// if we have a incoming event, we want to match pipelineruns on both incoming and push
 			targetEvents = []string{triggertype.Incoming.String(), triggertype.Push.String()}
 		}
+		// custom event types declared by the admin match the event type they are aliasing
+		for _, target := range targetEvents {
+			targetEvents = append(targetEvents, aliases.For(triggertype.StringToType(target))...)
+		}
 		matched, err := matchOnAnnotation(key, targetEvents, false)
 		targetEvent = key
 		if err != nil {
@@ -154,6 +169,11 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 	}
 	logger.Info(infomsg)
 
+	var aliases triggertype.Aliases
+	if cs.Info.Pac != nil {
+		aliases = cs.Info.Pac.EventTypeAliases()
+	}
+
 	for _, prun := range pruns {
 		prMatch := Match{
 			PipelineRun: prun,
@@ -185,6 +205,26 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 			}
 		}
 
+		// the deployments waiting on a protection rule only run the
+		// PipelineRuns verifying their environment
+		if event.EventType == provider.DeploymentProtectionRuleEventType {
+			environments, ok := prun.GetObjectMeta().GetAnnotations()[keys.OnDeploymentEnvironment]
+			if !ok {
+				continue
+			}
+			matched, err := matchOnAnnotation(environments, []string{event.DeploymentEnvironment}, false)
+			if err != nil {
+				return matchedPRs, err
+			}
+			if !matched {
+				continue
+			}
+			prMatch.Config["target-environment"] = environments
+			logger.Infof("matched pipelinerun with name: %s on the deployment to %s", prName, event.DeploymentEnvironment)
+			matchedPRs = append(matchedPRs, prMatch)
+			continue
+		}
+
 		if targetComment, ok := prun.GetObjectMeta().GetAnnotations()[keys.OnComment]; ok {
 			re, err := regexp.Compile(targetComment)
 			if err != nil {
@@ -214,7 +254,7 @@ func MatchPipelinerunByAnnotation(ctx context.Context, logger *zap.SugaredLogger
 			}
 			logger.Infof("CEL expression has been evaluated and matched")
 		} else {
-			matched, targetEvent, targetBranch, err := getTargetBranch(prun, event)
+			matched, targetEvent, targetBranch, err := getTargetBranch(prun, event, aliases)
 			if err != nil {
 				return matchedPRs, err
 			}
@@ -278,7 +318,7 @@ func matchOnAnnotation(annotations string, eventType []string, branchMatching bo
 			if v == e {
 				gotit = v
 			}
-			if branchMatching && branchMatch(v, e) {
+			if branchMatching && BranchMatch(v, e) {
 				gotit = v
 			}
 		}
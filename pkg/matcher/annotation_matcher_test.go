@@ -304,6 +304,44 @@ func TestMatchPipelinerunAnnotationAndRepositories(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:       "cel/match signed commit of annotated tag",
+			wantPRName: pipelineTargetNSName,
+			args: annotationTestArgs{
+				pruns: []*tektonv1.PipelineRun{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: pipelineTargetNSName,
+							Annotations: map[string]string{
+								keys.OnCelExpression: `signature_status == "verified" && signature_key_id == "6A0C91B48753C58C" && tag_message.startsWith("Release")`,
+							},
+						},
+					},
+				},
+				runevent: info.Event{
+					URL:           targetURL,
+					TriggerTarget: "push",
+					EventType:     "push",
+					BaseBranch:    "refs/tags/v1.0",
+					HeadBranch:    "refs/tags/v1.0",
+					TagMessage:    "Release 1.0",
+					SHASignature:  info.CommitSignature{Status: info.SignatureVerified, KeyID: "6A0C91B48753C58C"},
+					Organization:  "mylittle",
+					Repository:    "pony",
+				},
+				data: testclient.Data{
+					Repositories: []*v1alpha1.Repository{
+						testnewrepo.NewRepo(
+							testnewrepo.RepoTestcreationOpts{
+								Name:             "test-good",
+								URL:              targetURL,
+								InstallNamespace: targetNamespace,
+							},
+						),
+					},
+				},
+			},
+		},
 		{
 			name:       "cel/match path title pr",
 			wantPRName: pipelineTargetNSName,
@@ -473,6 +511,60 @@ func TestMatchPipelinerunAnnotationAndRepositories(t *testing.T) {
 			},
 		},
 
+		{
+			name:    "cel/no match path ignored by pac-ignore",
+			wantErr: true,
+			args: annotationTestArgs{
+				fileChanged: []struct {
+					FileName    string
+					Status      string
+					NewFile     bool
+					RenamedFile bool
+					DeletedFile bool
+				}{
+					{
+						FileName:    "vendor/github.com/foo/foo.go",
+						Status:      "modified",
+						NewFile:     false,
+						RenamedFile: false,
+						DeletedFile: false,
+					},
+				},
+				pruns: []*tektonv1.PipelineRun{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: pipelineTargetNSName,
+							Annotations: map[string]string{
+								keys.OnCelExpression: `"**.go".pathChanged() || files.all.exists(x, x.matches('foo'))`,
+							},
+						},
+					},
+				},
+				runevent: info.Event{
+					URL:               targetURL,
+					TriggerTarget:     "pull_request",
+					EventType:         "pull_request",
+					BaseBranch:        mainBranch,
+					HeadBranch:        "unittests",
+					PullRequestNumber: 1000,
+					Organization:      "mylittle",
+					Repository:        "pony",
+					State:             info.State{IgnoredPaths: []string{"vendor/"}},
+				},
+				data: testclient.Data{
+					Repositories: []*v1alpha1.Repository{
+						testnewrepo.NewRepo(
+							testnewrepo.RepoTestcreationOpts{
+								Name:             "test-good",
+								URL:              targetURL,
+								InstallNamespace: targetNamespace,
+							},
+						),
+					},
+				},
+			},
+		},
+
 		{
 			name:       "cel/match by direct path",
 			wantPRName: pipelineTargetNSName,
@@ -1180,6 +1272,15 @@ func TestMatchPipelinerunByAnnotation(t *testing.T) {
 		},
 	}
 
+	pipelineDeployment := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pipeline-deployment",
+			Annotations: map[string]string{
+				keys.OnDeploymentEnvironment: "[staging, production]",
+			},
+		},
+	}
+
 	observer, log := zapobserver.New(zap.InfoLevel)
 	logger := zap.New(observer).Sugar()
 
@@ -1255,6 +1356,33 @@ func TestMatchPipelinerunByAnnotation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "match-on-deployment-environment",
+			args: args{
+				pruns: []*tektonv1.PipelineRun{pipelinePush, pipelineDeployment},
+				runevent: info.Event{
+					TriggerTarget:         "push",
+					EventType:             "deployment_protection_rule",
+					BaseBranch:            "main",
+					DeploymentEnvironment: "production",
+				},
+			},
+			wantErr:    false,
+			wantPrName: pipelineDeployment.GetName(),
+		},
+		{
+			name: "no-match-on-deployment-environment",
+			args: args{
+				pruns: []*tektonv1.PipelineRun{pipelinePush, pipelineDeployment},
+				runevent: info.Event{
+					TriggerTarget:         "push",
+					EventType:             "deployment_protection_rule",
+					BaseBranch:            "main",
+					DeploymentEnvironment: "qa",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "no-match-on-event",
 			args: args{
@@ -1660,7 +1788,7 @@ func TestBranchMatch(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := branchMatch(tt.prunBranch, tt.baseBranch)
+			got := BranchMatch(tt.prunBranch, tt.baseBranch)
 			assert.Equal(t, got, tt.output)
 		})
 	}
@@ -1891,10 +2019,49 @@ func TestGetTargetBranch(t *testing.T) {
 		name           string
 		prun           *tektonv1.PipelineRun
 		event          *info.Event
+		aliases        triggertype.Aliases
 		expectedMatch  bool
 		expectedEvent  string
 		expectedBranch string
 	}{
+		{
+			name: "Test with custom event type aliasing incoming",
+			prun: &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						keys.OnEvent:        "[nightly]",
+						keys.OnTargetBranch: "main",
+					},
+				},
+			},
+			event: &info.Event{
+				TriggerTarget: triggertype.Push,
+				EventType:     triggertype.Incoming.String(),
+				BaseBranch:    "main",
+			},
+			aliases:        triggertype.Aliases{"nightly": triggertype.Incoming, "preview": triggertype.PullRequest},
+			expectedMatch:  true,
+			expectedEvent:  "[nightly]",
+			expectedBranch: "main",
+		},
+		{
+			name: "Test with custom event type aliasing another event",
+			prun: &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						keys.OnEvent:        "[preview]",
+						keys.OnTargetBranch: "main",
+					},
+				},
+			},
+			event: &info.Event{
+				TriggerTarget: triggertype.Push,
+				EventType:     triggertype.Push.String(),
+				BaseBranch:    "main",
+			},
+			aliases:       triggertype.Aliases{"nightly": triggertype.Incoming, "preview": triggertype.PullRequest},
+			expectedMatch: false,
+		},
 		{
 			name: "Test with pull_request event",
 			prun: &tektonv1.PipelineRun{
@@ -1956,7 +2123,7 @@ func TestGetTargetBranch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			matched, targetEvent, targetBranch, err := getTargetBranch(tt.prun, tt.event)
+			matched, targetEvent, targetBranch, err := getTargetBranch(tt.prun, tt.event, tt.aliases)
 			assert.NilError(t, err)
 			assert.Equal(t, tt.expectedMatch, matched)
 			assert.Equal(t, tt.expectedEvent, targetEvent)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
@@ -168,11 +169,18 @@ func (rt RemoteTasks) getRemote(ctx context.Context, uri string, fromHub bool, k
 func grabValuesFromAnnotations(annotations map[string]string, annotationReg string) ([]string, error) {
 	rtareg := regexp.MustCompile(fmt.Sprintf("%s/%s", pipelinesascode.GroupName, annotationReg))
 	var ret []string
-	for annotationK, annotationV := range annotations {
+	// go through the annotations in the same order every time so the remote
+	// resources are always fetched and overridden the same way
+	annotationKeys := make([]string, 0, len(annotations))
+	for annotationK := range annotations {
+		annotationKeys = append(annotationKeys, annotationK)
+	}
+	sort.Strings(annotationKeys)
+	for _, annotationK := range annotationKeys {
 		if !rtareg.MatchString(annotationK) {
 			continue
 		}
-		items, err := getAnnotationValues(annotationV)
+		items, err := getAnnotationValues(annotations[annotationK])
 		if err != nil {
 			return ret, err
 		}
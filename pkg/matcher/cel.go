@@ -23,6 +23,24 @@ const (
 )
 
 func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provider.Interface) (ref.Val, error) {
+	return evaluateCel(expr, event, func() (changedfiles.ChangedFiles, error) {
+		if vcx == nil {
+			return changedfiles.ChangedFiles{}, fmt.Errorf("cannot get the changed files of the event without a provider")
+		}
+		return vcx.GetFiles(ctx, event)
+	})
+}
+
+// CelEvaluateWithFiles evaluates an on-cel-expression against the event with
+// the changed files given instead of fetched from the git provider, to test
+// the expressions locally.
+func CelEvaluateWithFiles(expr string, event *info.Event, files changedfiles.ChangedFiles) (ref.Val, error) {
+	return evaluateCel(expr, event, func() (changedfiles.ChangedFiles, error) {
+		return files, nil
+	})
+}
+
+func evaluateCel(expr string, event *info.Event, getFiles func() (changedfiles.ChangedFiles, error)) (ref.Val, error) {
 	eventTitle := event.PullRequestTitle
 	if event.TriggerTarget == triggertype.Push {
 		eventTitle = event.SHATitle
@@ -60,25 +78,40 @@ func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provid
 		headerMap[strings.ToLower(k)] = v[0]
 	}
 
+	// the files of the pac-ignore files don't count as changed
+	if len(event.IgnoredPaths) > 0 {
+		getAllFiles := getFiles
+		getFiles = func() (changedfiles.ChangedFiles, error) {
+			files, err := getAllFiles()
+			if err != nil {
+				return files, err
+			}
+			return files.Exclude(event.IgnoredPaths), nil
+		}
+	}
+
 	r := regexp.MustCompile(reChangedFilesTags)
 	changedFiles := changedfiles.ChangedFiles{}
 
 	if r.MatchString(expr) {
-		changedFiles, err = vcx.GetFiles(ctx, event)
+		changedFiles, err = getFiles()
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	data := map[string]interface{}{
-		"event":         event.TriggerTarget.String(),
-		"event_title":   eventTitle,
-		"target_branch": event.BaseBranch,
-		"source_branch": event.HeadBranch,
-		"target_url":    event.BaseURL,
-		"source_url":    event.HeadURL,
-		"body":          jsonMap,
-		"headers":       headerMap,
+		"event":            event.TriggerTarget.String(),
+		"event_title":      eventTitle,
+		"target_branch":    event.BaseBranch,
+		"source_branch":    event.HeadBranch,
+		"target_url":       event.BaseURL,
+		"source_url":       event.HeadURL,
+		"tag_message":      event.TagMessage,
+		"signature_status": event.SHASignature.Status,
+		"signature_key_id": event.SHASignature.KeyID,
+		"body":             jsonMap,
+		"headers":          headerMap,
 		"files": map[string]interface{}{
 			"all":      changedFiles.All,
 			"added":    changedFiles.Added,
@@ -88,7 +121,7 @@ func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provid
 		},
 	}
 	env, err := cel.NewEnv(
-		cel.Lib(celPac{vcx, ctx, event}),
+		cel.Lib(celPac{getFiles}),
 		cel.Declarations(
 			decls.NewVar("event", decls.String),
 			decls.NewVar("headers", decls.NewMapType(decls.String, decls.Dyn)),
@@ -98,6 +131,9 @@ func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provid
 			decls.NewVar("source_branch", decls.String),
 			decls.NewVar("target_url", decls.String),
 			decls.NewVar("source_url", decls.String),
+			decls.NewVar("tag_message", decls.String),
+			decls.NewVar("signature_status", decls.String),
+			decls.NewVar("signature_key_id", decls.String),
 			decls.NewVar("files", decls.NewMapType(decls.String, decls.Dyn)),
 		))
 	if err != nil {
@@ -127,9 +163,7 @@ func celEvaluate(ctx context.Context, expr string, event *info.Event, vcx provid
 }
 
 type celPac struct {
-	vcx   provider.Interface
-	ctx   context.Context
-	event *info.Event
+	getFiles func() (changedfiles.ChangedFiles, error)
 }
 
 func (t celPac) ProgramOptions() []cel.ProgramOption {
@@ -138,7 +172,7 @@ func (t celPac) ProgramOptions() []cel.ProgramOption {
 
 func (t celPac) pathChanged(vals ref.Val) ref.Val {
 	var match types.Bool
-	changedFiles, err := t.vcx.GetFiles(t.ctx, t.event)
+	changedFiles, err := t.getFiles()
 	if err != nil {
 		return types.Bool(false)
 	}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"strings"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/sort"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -18,16 +20,49 @@ func MatchEventURLRepo(ctx context.Context, cs *params.Run, event *info.Event, n
 	if err != nil {
 		return nil, err
 	}
+	var policy settings.NamespaceIsolationPolicy
+	if cs.Info.Pac != nil {
+		policy = cs.Info.Pac.NamespaceIsolation()
+	}
 	for _, repo := range repositories.Items {
+		if repo.GetLabels()[keys.OrgDefaults] == "true" {
+			continue
+		}
 		repo.Spec.URL = strings.TrimSuffix(repo.Spec.URL, "/")
-		if repo.Spec.URL == event.URL {
-			return &repo, nil
+		if repo.Spec.URL != event.URL {
+			continue
 		}
+		if !policy.Allowed(repo.Spec.URL, repo.GetNamespace()) {
+			if cs.Clients.Log != nil {
+				cs.Clients.Log.Warnf("skipping repository %s/%s, the namespace isolation policy does not allow it to claim %s",
+					repo.GetNamespace(), repo.GetName(), repo.Spec.URL)
+			}
+			continue
+		}
+		return &repo, nil
 	}
 
 	return nil, nil
 }
 
+// GetOrgDefaults returns the org defaults Repositories, they are only read
+// from the namespace of the controller.
+func GetOrgDefaults(ctx context.Context, cs *params.Run) ([]*apipac.Repository, error) {
+	if cs.Info.Kube == nil || cs.Info.Kube.Namespace == "" {
+		return nil, nil
+	}
+	repositories, err := cs.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(cs.Info.Kube.Namespace).List(
+		ctx, metav1.ListOptions{LabelSelector: keys.OrgDefaults + "=true"})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*apipac.Repository, 0, len(repositories.Items))
+	for i := range repositories.Items {
+		ret = append(ret, &repositories.Items[i])
+	}
+	return ret, nil
+}
+
 // GetRepo get a repo by name anywhere on a cluster.
 func GetRepo(ctx context.Context, cs *params.Run, repoName string) (*apipac.Repository, error) {
 	repositories, err := cs.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("").List(
@@ -5,10 +5,12 @@ import (
 	"time"
 
 	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
 	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
 	testnewrepo "github.com/openshift-pipelines/pipelines-as-code/pkg/test/repository"
 	"go.uber.org/zap"
@@ -36,6 +38,7 @@ func Test_getRepoByCR(t *testing.T) {
 		args         args
 		wantTargetNS string
 		wantErr      bool
+		policy       string
 	}{
 		{
 			name: "test-match",
@@ -145,6 +148,35 @@ func Test_getRepoByCR(t *testing.T) {
 			wantTargetNS: targetOldestNamespace,
 			wantErr:      false,
 		},
+		{
+			name: "test-multiple-match-skip-namespace-not-allowed-by-policy",
+			args: args{
+				data: testclient.Data{
+					Repositories: []*v1alpha1.Repository{
+						testnewrepo.NewRepo(
+							testnewrepo.RepoTestcreationOpts{
+								Name:             "test-new",
+								URL:              "https://github.com/team-a/app",
+								InstallNamespace: targetNamespace,
+								CreateTime:       metav1.Time{Time: cw.Now().Add(-1 * time.Minute)},
+							},
+						),
+						testnewrepo.NewRepo(
+							testnewrepo.RepoTestcreationOpts{
+								Name:             "test-old",
+								URL:              "https://github.com/team-a/app",
+								InstallNamespace: targetOldestNamespace,
+								CreateTime:       metav1.Time{Time: cw.Now().Add(-5 * time.Minute)},
+							},
+						),
+					},
+				},
+				runevent: info.Event{URL: "https://github.com/team-a/app", BaseBranch: mainBranch, EventType: "pull_request"},
+			},
+			policy:       "github.com/team-a:" + targetNamespace,
+			wantTargetNS: targetNamespace,
+			wantErr:      false,
+		},
 		{
 			name: "glob-branch",
 			args: args{
@@ -177,8 +209,9 @@ func Test_getRepoByCR(t *testing.T) {
 			logger := zap.New(observer).Sugar()
 			client := &params.Run{
 				Clients: clients.Clients{PipelineAsCode: cs.PipelineAsCode, Log: logger},
-				Info:    info.Info{},
+				Info:    info.Info{Pac: info.NewPacOpts()},
 			}
+			assert.NilError(t, settings.SyncConfig(logger, &client.Info.Pac.Settings, map[string]string{"namespace-isolation-policy": tt.policy}))
 			got, err := MatchEventURLRepo(ctx, client, &tt.args.runevent, "")
 
 			if err == nil && tt.wantErr {
@@ -197,3 +230,42 @@ func Test_getRepoByCR(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOrgDefaults(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	orgDefaults := testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+		Name:             "org",
+		URL:              "https://github.com/org",
+		InstallNamespace: "pipelines-as-code",
+	})
+	orgDefaults.Labels = map[string]string{keys.OrgDefaults: "true"}
+	otherNS := testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+		Name:             "org",
+		URL:              "https://github.com/org",
+		InstallNamespace: "user",
+	})
+	otherNS.Labels = map[string]string{keys.OrgDefaults: "true"}
+	repo := testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+		Name:             "repo",
+		URL:              "https://github.com/org/repo",
+		InstallNamespace: "pipelines-as-code",
+	})
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{
+		Repositories: []*v1alpha1.Repository{orgDefaults, otherNS, repo},
+	})
+	cs := &params.Run{
+		Clients: clients.Clients{PipelineAsCode: stdata.PipelineAsCode},
+		Info:    info.Info{Kube: &info.KubeOpts{Namespace: "pipelines-as-code"}},
+	}
+
+	got, err := GetOrgDefaults(ctx, cs)
+	assert.NilError(t, err)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].GetNamespace(), "pipelines-as-code")
+	assert.Equal(t, got[0].GetName(), "org")
+
+	// the org defaults are never matched as a repository
+	matched, err := MatchEventURLRepo(ctx, cs, &info.Event{URL: "https://github.com/org"}, "")
+	assert.NilError(t, err)
+	assert.Assert(t, matched == nil)
+}
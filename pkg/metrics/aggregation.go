@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// AggregationNone does not label the metrics with their owner.
+	AggregationNone = "none"
+	// AggregationOrg labels the metrics with the organization of the Git repository.
+	AggregationOrg = "org"
+	// AggregationNamespace labels the metrics with the namespace of the Repository.
+	AggregationNamespace = "namespace"
+	// AggregationRepository labels the metrics with the namespace and the name of the Repository.
+	AggregationRepository = "repository"
+
+	// otherValue replaces the owner values not in the allowlist.
+	otherValue = "other"
+)
+
+// Owner is what the metrics are labeled with beside the provider and the
+// event type, an empty field is not a label.
+type Owner struct {
+	Org        string
+	Namespace  string
+	Repository string
+}
+
+// Aggregation keeps the cardinality of the metrics under control: only the
+// owner labels of its level are kept and the values not matching its
+// allowlist are all counted as "other".
+type Aggregation struct {
+	level     string
+	allowlist []string
+}
+
+// ParseAggregationLevel validates the level of the metrics-aggregation-level
+// setting, empty is the same as none.
+func ParseAggregationLevel(level string) (string, error) {
+	switch strings.TrimSpace(level) {
+	case "", AggregationNone:
+		return AggregationNone, nil
+	case AggregationOrg, AggregationNamespace, AggregationRepository:
+		return strings.TrimSpace(level), nil
+	}
+	return "", fmt.Errorf("invalid metrics aggregation level %q, must be one of %s, %s, %s or %s",
+		level, AggregationNone, AggregationOrg, AggregationNamespace, AggregationRepository)
+}
+
+// ParseLabelsAllowlist parses a comma separated list of globs of the owner
+// values keeping their own series, for example "my-org, team-*".
+func ParseLabelsAllowlist(s string) ([]string, error) {
+	globs := []string{}
+	for _, glob := range strings.Split(s, ",") {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
+			continue
+		}
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid metrics label glob %q: %w", glob, err)
+		}
+		globs = append(globs, glob)
+	}
+	return globs, nil
+}
+
+// NewAggregation returns the aggregation of the metrics-aggregation-level and
+// metrics-labels-allowlist settings, an invalid setting aggregates everything.
+// An empty allowlist keeps all the values.
+func NewAggregation(level, allowlist string) Aggregation {
+	a := Aggregation{}
+	var err error
+	if a.level, err = ParseAggregationLevel(level); err != nil {
+		a.level = AggregationNone
+	}
+	if a.allowlist, err = ParseLabelsAllowlist(allowlist); err != nil {
+		a.allowlist = nil
+	}
+	return a
+}
+
+func (a Aggregation) allowed(value string) bool {
+	if len(a.allowlist) == 0 {
+		return true
+	}
+	for _, glob := range a.allowlist {
+		if ok, _ := path.Match(glob, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Owner returns the owner labels of the level, the repository is matched
+// against the allowlist as namespace/name.
+func (a Aggregation) Owner(org, namespace, repository string) Owner {
+	switch a.level {
+	case AggregationOrg:
+		if org == "" {
+			return Owner{}
+		}
+		if !a.allowed(org) {
+			org = otherValue
+		}
+		return Owner{Org: org}
+	case AggregationNamespace:
+		if namespace == "" {
+			return Owner{}
+		}
+		if !a.allowed(namespace) {
+			namespace = otherValue
+		}
+		return Owner{Namespace: namespace}
+	case AggregationRepository:
+		if namespace == "" || repository == "" {
+			return Owner{}
+		}
+		if !a.allowed(namespace + "/" + repository) {
+			namespace, repository = otherValue, otherValue
+		}
+		return Owner{Namespace: namespace, Repository: repository}
+	}
+	return Owner{}
+}
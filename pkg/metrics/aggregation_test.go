@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestAggregationOwner(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     string
+		allowlist string
+		org       string
+		namespace string
+		repo      string
+		want      Owner
+	}{
+		{
+			name:      "no aggregation by default",
+			org:       "org",
+			namespace: "ns",
+			repo:      "repo",
+			want:      Owner{},
+		},
+		{
+			name:      "invalid level",
+			level:     "cluster",
+			org:       "org",
+			namespace: "ns",
+			repo:      "repo",
+			want:      Owner{},
+		},
+		{
+			name:      "org",
+			level:     AggregationOrg,
+			org:       "org",
+			namespace: "ns",
+			repo:      "repo",
+			want:      Owner{Org: "org"},
+		},
+		{
+			name:      "org not allowed",
+			level:     AggregationOrg,
+			allowlist: "team-*, infra",
+			org:       "org",
+			want:      Owner{Org: otherValue},
+		},
+		{
+			name:      "org allowed",
+			level:     AggregationOrg,
+			allowlist: "team-*, infra",
+			org:       "team-a",
+			want:      Owner{Org: "team-a"},
+		},
+		{
+			name:      "namespace",
+			level:     AggregationNamespace,
+			org:       "org",
+			namespace: "ns",
+			repo:      "repo",
+			want:      Owner{Namespace: "ns"},
+		},
+		{
+			name:      "namespace not allowed",
+			level:     AggregationNamespace,
+			allowlist: "prod-*",
+			namespace: "ns",
+			want:      Owner{Namespace: otherValue},
+		},
+		{
+			name:      "repository allowed by namespace",
+			level:     AggregationRepository,
+			allowlist: "ns/*",
+			namespace: "ns",
+			repo:      "repo",
+			want:      Owner{Namespace: "ns", Repository: "repo"},
+		},
+		{
+			name:      "repository not allowed",
+			level:     AggregationRepository,
+			allowlist: "ns/other",
+			namespace: "ns",
+			repo:      "repo",
+			want:      Owner{Namespace: otherValue, Repository: otherValue},
+		},
+		{
+			name:  "repository unknown",
+			level: AggregationRepository,
+			org:   "org",
+			want:  Owner{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewAggregation(tt.level, tt.allowlist).Owner(tt.org, tt.namespace, tt.repo)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseAggregationSettings(t *testing.T) {
+	level, err := ParseAggregationLevel("")
+	assert.NilError(t, err)
+	assert.Equal(t, level, AggregationNone)
+	_, err = ParseAggregationLevel("cluster")
+	assert.ErrorContains(t, err, "invalid metrics aggregation level")
+
+	globs, err := ParseLabelsAllowlist(" team-*, ,infra ")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, globs, []string{"team-*", "infra"})
+	_, err = ParseLabelsAllowlist("team-[")
+	assert.ErrorContains(t, err, "invalid metrics label glob")
+}
@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"go.opencensus.io/stats"
@@ -15,6 +16,150 @@ var prCount = stats.Float64("pipelines_as_code_pipelinerun_count",
 	"number of pipeline runs by pipelines as code",
 	stats.UnitDimensionless)
 
+var patchConflictCount = stats.Float64("pipelines_as_code_pipelinerun_patch_conflict_count",
+	"number of conflicts when patching the pipeline runs",
+	stats.UnitDimensionless)
+
+var queueWait = stats.Float64("pipelines_as_code_pipelinerun_queue_wait_seconds",
+	"time the queued pipeline runs have waited before starting",
+	stats.UnitSeconds)
+
+var queuePreemptionCount = stats.Float64("pipelines_as_code_pipelinerun_queue_preemption_count",
+	"number of queued pipeline runs cancelled by a higher-priority pipeline run",
+	stats.UnitDimensionless)
+
+var eventCount = stats.Float64("pipelines_as_code_event_count",
+	"number of events of the Repositories received by the controller",
+	stats.UnitDimensionless)
+
+var eventDecisionCount = stats.Float64("pipelines_as_code_event_decision_count",
+	"number of events processed by the controller by decision, matched or skipped with the reason of the skip",
+	stats.UnitDimensionless)
+
+var eventLatency = stats.Float64("pipelines_as_code_event_latency_seconds",
+	"latency of the events from their delivery by the git provider to the end of their processing, by stage",
+	stats.UnitSeconds)
+
+var eventLatencySLOBreach = stats.Float64("pipelines_as_code_event_latency_slo_breach",
+	"1 when the 95th percentile of the end-to-end latency of the last events exceeds the event-latency-slo setting",
+	stats.UnitDimensionless)
+
+var providerCallDuration = stats.Float64("pipelines_as_code_provider_api_duration_seconds",
+	"duration of the calls to the git provider APIs",
+	stats.UnitSeconds)
+
+var taskOutcomeCount = stats.Float64("pipelines_as_code_pipelinerun_task_outcome_count",
+	"number of tasks of the finished pipeline runs by outcome and flakiness",
+	stats.UnitDimensionless)
+
+var pipelineRunCost = stats.Float64("pipelines_as_code_pipelinerun_cost",
+	"estimated cost of the finished pipeline runs from the resources requested by their tasks",
+	stats.UnitDimensionless)
+
+var pipelineRunCPUCoreHours = stats.Float64("pipelines_as_code_pipelinerun_cpu_core_hours",
+	"cpu core hours requested by the tasks of the finished pipeline runs",
+	stats.UnitDimensionless)
+
+var pipelineRunMemoryGiBHours = stats.Float64("pipelines_as_code_pipelinerun_memory_gib_hours",
+	"memory GiB hours requested by the tasks of the finished pipeline runs",
+	stats.UnitDimensionless)
+
+var resolutionCacheCount = stats.Float64("pipelines_as_code_resolution_cache_count",
+	"number of lookups of the cache of the PipelineRuns resolved for a commit by outcome",
+	stats.UnitDimensionless)
+
+var providerRequestCount = stats.Float64("pipelines_as_code_provider_api_request_count",
+	"number of requests to the git provider APIs by status class",
+	stats.UnitDimensionless)
+
+var providerRetryCount = stats.Float64("pipelines_as_code_provider_api_retry_count",
+	"number of requests to the git provider APIs retried",
+	stats.UnitDimensionless)
+
+var providerRateLimitRemaining = stats.Float64("pipelines_as_code_provider_api_rate_limit_remaining",
+	"number of requests left in the rate limit of the git provider APIs",
+	stats.UnitDimensionless)
+
+var githubAppTokenRefreshCount = stats.Float64("pipelines_as_code_github_app_token_refresh_count",
+	"number of installation tokens of the GitHub App refreshed before their expiration or re-authenticated",
+	stats.UnitDimensionless)
+
+var githubAppClockSkew = stats.Float64("pipelines_as_code_github_app_clock_skew_seconds",
+	"offset of the clock of GitHub from the clock of the controller, learned from a refused JWT",
+	stats.UnitSeconds)
+
+var (
+	// patchKey tags the conflicts with the patch applied.
+	patchKey = tag.MustNewKey("patch")
+
+	// the owner keys, only set at their aggregation level
+	orgKey        = tag.MustNewKey("org")
+	namespaceKey  = tag.MustNewKey("namespace")
+	repositoryKey = tag.MustNewKey("repository")
+	ownerKeys     = []tag.Key{orgKey, namespaceKey, repositoryKey}
+
+	eventProviderKey = tag.MustNewKey("provider")
+	eventTypeKey     = tag.MustNewKey("event-type")
+
+	operationKey = tag.MustNewKey("operation")
+	outcomeKey   = tag.MustNewKey("outcome")
+	statusKey    = tag.MustNewKey("status")
+
+	pipelineKey = tag.MustNewKey("pipeline")
+	taskKey     = tag.MustNewKey("task")
+	flakyKey    = tag.MustNewKey("flaky")
+
+	cacheKey = tag.MustNewKey("cache")
+
+	reasonKey   = tag.MustNewKey("reason")
+	decisionKey = tag.MustNewKey("decision")
+	stageKey    = tag.MustNewKey("stage")
+
+	// the shared views, they can only be registered again with the same
+	// aggregation
+	queueWaitView = &view.View{
+		Description: queueWait.Description(),
+		Measure:     queueWait,
+		Aggregation: view.Distribution(1, 10, 30, 60, 300, 600, 1800, 3600, 7200),
+		TagKeys:     ownerKeys,
+	}
+	providerCallView = &view.View{
+		Description: providerCallDuration.Description(),
+		Measure:     providerCallDuration,
+		Aggregation: view.Distribution(0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60),
+		TagKeys:     []tag.Key{eventProviderKey, operationKey, outcomeKey},
+	}
+	providerRequestView = &view.View{
+		Description: providerRequestCount.Description(),
+		Measure:     providerRequestCount,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{eventProviderKey, operationKey, statusKey},
+	}
+	providerRetryView = &view.View{
+		Description: providerRetryCount.Description(),
+		Measure:     providerRetryCount,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{eventProviderKey, operationKey},
+	}
+	providerRateLimitView = &view.View{
+		Description: providerRateLimitRemaining.Description(),
+		Measure:     providerRateLimitRemaining,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{eventProviderKey},
+	}
+	githubAppTokenRefreshView = &view.View{
+		Description: githubAppTokenRefreshCount.Description(),
+		Measure:     githubAppTokenRefreshCount,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{reasonKey, outcomeKey},
+	}
+	githubAppClockSkewView = &view.View{
+		Description: githubAppClockSkew.Description(),
+		Measure:     githubAppClockSkew,
+		Aggregation: view.LastValue(),
+	}
+)
+
 // Recorder holds keys for metrics.
 type Recorder struct {
 	initialized     bool
@@ -50,8 +195,51 @@ func NewRecorder() (*Recorder, error) {
 			Description: prCount.Description(),
 			Measure:     prCount,
 			Aggregation: view.Count(),
-			TagKeys:     []tag.Key{r.provider, r.eventType},
+			TagKeys:     append([]tag.Key{r.provider, r.eventType}, ownerKeys...),
 		},
+		&view.View{
+			Description: patchConflictCount.Description(),
+			Measure:     patchConflictCount,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{patchKey},
+		},
+		&view.View{
+			Description: taskOutcomeCount.Description(),
+			Measure:     taskOutcomeCount,
+			Aggregation: view.Count(),
+			TagKeys:     append([]tag.Key{pipelineKey, taskKey, outcomeKey, flakyKey}, ownerKeys...),
+		},
+		&view.View{
+			Description: pipelineRunCost.Description(),
+			Measure:     pipelineRunCost,
+			Aggregation: view.Sum(),
+			TagKeys:     append([]tag.Key{pipelineKey}, ownerKeys...),
+		},
+		&view.View{
+			Description: pipelineRunCPUCoreHours.Description(),
+			Measure:     pipelineRunCPUCoreHours,
+			Aggregation: view.Sum(),
+			TagKeys:     append([]tag.Key{pipelineKey}, ownerKeys...),
+		},
+		&view.View{
+			Description: pipelineRunMemoryGiBHours.Description(),
+			Measure:     pipelineRunMemoryGiBHours,
+			Aggregation: view.Sum(),
+			TagKeys:     append([]tag.Key{pipelineKey}, ownerKeys...),
+		},
+		&view.View{
+			Description: queuePreemptionCount.Description(),
+			Measure:     queuePreemptionCount,
+			Aggregation: view.Count(),
+			TagKeys:     ownerKeys,
+		},
+		queueWaitView,
+		providerCallView,
+		providerRequestView,
+		providerRetryView,
+		providerRateLimitView,
+		githubAppTokenRefreshView,
+		githubAppClockSkewView,
 	)
 	if err != nil {
 		r.initialized = false
@@ -62,17 +250,17 @@ func NewRecorder() (*Recorder, error) {
 }
 
 // Count logs number of times a pipelinerun is ran for a provider.
-func (r *Recorder) Count(provider, event string) error {
+func (r *Recorder) Count(provider, event string, owner Owner) error {
 	if !r.initialized {
 		return fmt.Errorf(
 			"ignoring the metrics recording for pipeline runs,  failed to initialize the metrics recorder")
 	}
 
-	ctx, err := tag.New(
-		context.Background(),
+	mutators := append([]tag.Mutator{
 		tag.Insert(r.provider, provider),
 		tag.Insert(r.eventType, event),
-	)
+	}, ownerMutators(owner)...)
+	ctx, err := tag.New(context.Background(), mutators...)
 	if err != nil {
 		return err
 	}
@@ -80,3 +268,242 @@ func (r *Recorder) Count(provider, event string) error {
 	metrics.Record(ctx, prCount.M(1))
 	return nil
 }
+
+// CountPatchConflict logs a conflict when patching a pipeline run, it is
+// recorded once the views are registered by NewRecorder.
+func CountPatchConflict(patch string) {
+	ctx, err := tag.New(context.Background(), tag.Insert(patchKey, patch))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, patchConflictCount.M(1))
+}
+
+// RecordQueueWait logs the time a queued pipeline run has waited before
+// starting, it is recorded once the views are registered by NewRecorder.
+func RecordQueueWait(owner Owner, wait time.Duration) {
+	ctx, err := tag.New(context.Background(), ownerMutators(owner)...)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, queueWait.M(wait.Seconds()))
+}
+
+// CountQueuePreemption logs a queued pipeline run cancelled by a
+// higher-priority one, it is recorded once the views are registered by
+// NewRecorder.
+func CountQueuePreemption(owner Owner) {
+	ctx, err := tag.New(context.Background(), ownerMutators(owner)...)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, queuePreemptionCount.M(1))
+}
+
+// CountTaskOutcome logs the outcome, passed or failed, of a task of a finished
+// pipeline run and whether it has been detected as flaky, the flake rate of a
+// pipeline is the ratio of the flaky tasks. It is recorded once the views are
+// registered by NewRecorder.
+func CountTaskOutcome(owner Owner, pipeline, task, outcome string, flaky bool) {
+	ctx, err := tag.New(context.Background(),
+		append([]tag.Mutator{
+			tag.Insert(pipelineKey, pipeline),
+			tag.Insert(taskKey, task),
+			tag.Insert(outcomeKey, outcome),
+			tag.Insert(flakyKey, strconv.FormatBool(flaky)),
+		}, ownerMutators(owner)...)...)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, taskOutcomeCount.M(1))
+}
+
+// RecordCost logs the resources requested by the tasks of a finished pipeline
+// run and its estimated cost, it is recorded once the views are registered by
+// NewRecorder.
+func RecordCost(owner Owner, pipeline string, cpuCoreHours, memoryGiBHours, cost float64) {
+	ctx, err := tag.New(context.Background(),
+		append([]tag.Mutator{tag.Insert(pipelineKey, pipeline)}, ownerMutators(owner)...)...)
+	if err != nil {
+		return
+	}
+	metrics.RecordBatch(ctx, pipelineRunCost.M(cost), pipelineRunCPUCoreHours.M(cpuCoreHours), pipelineRunMemoryGiBHours.M(memoryGiBHours))
+}
+
+// RegisterEventViews registers the views of the events received by the
+// controller and of the decisions taken on them, of the provider API calls and
+// of the resolution cache lookups it makes.
+func RegisterEventViews() error {
+	return view.Register(&view.View{
+		Description: eventCount.Description(),
+		Measure:     eventCount,
+		Aggregation: view.Count(),
+		TagKeys:     append([]tag.Key{eventProviderKey, eventTypeKey}, ownerKeys...),
+	}, &view.View{
+		Description: eventDecisionCount.Description(),
+		Measure:     eventDecisionCount,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{eventProviderKey, eventTypeKey, decisionKey, reasonKey},
+	}, &view.View{
+		Description: eventLatency.Description(),
+		Measure:     eventLatency,
+		Aggregation: view.Distribution(0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800),
+		TagKeys:     []tag.Key{eventProviderKey, stageKey},
+	}, &view.View{
+		Description: eventLatencySLOBreach.Description(),
+		Measure:     eventLatencySLOBreach,
+		Aggregation: view.LastValue(),
+	}, &view.View{
+		Description: resolutionCacheCount.Description(),
+		Measure:     resolutionCacheCount,
+		Aggregation: view.Count(),
+		TagKeys:     append([]tag.Key{cacheKey, outcomeKey}, ownerKeys...),
+	}, providerCallView, providerRequestView, providerRetryView, providerRateLimitView,
+		githubAppTokenRefreshView, githubAppClockSkewView)
+}
+
+// CountProviderRequest logs a request to a git provider API, the status is the
+// class of the HTTP status of the answer, like "2xx" or "5xx", or "error" when
+// the request failed without an answer.
+func CountProviderRequest(provider, operation, status string) {
+	ctx, err := tag.New(context.Background(),
+		tag.Insert(eventProviderKey, provider),
+		tag.Insert(operationKey, operation),
+		tag.Insert(statusKey, status))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, providerRequestCount.M(1))
+}
+
+// CountProviderRetry logs a request to a git provider API retried after a
+// gateway or a network error.
+func CountProviderRetry(provider, operation string) {
+	ctx, err := tag.New(context.Background(),
+		tag.Insert(eventProviderKey, provider),
+		tag.Insert(operationKey, operation))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, providerRetryCount.M(1))
+}
+
+// RecordProviderRateLimit logs the number of requests left in the rate limit
+// of a git provider API, as reported by its last answer.
+func RecordProviderRateLimit(provider string, remaining int) {
+	ctx, err := tag.New(context.Background(), tag.Insert(eventProviderKey, provider))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, providerRateLimitRemaining.M(float64(remaining)))
+}
+
+// CountGitHubAppTokenRefresh logs an installation token of the GitHub App
+// refreshed, the reason is "proactive" when it was about to expire or "reauth"
+// when GitHub refused the JWT or the token, and the outcome "done" or "error".
+func CountGitHubAppTokenRefresh(reason, outcome string) {
+	ctx, err := tag.New(context.Background(),
+		tag.Insert(reasonKey, reason),
+		tag.Insert(outcomeKey, outcome))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, githubAppTokenRefreshCount.M(1))
+}
+
+// RecordGitHubAppClockSkew logs the offset of the clock of GitHub from the
+// clock of the controller, positive when GitHub is ahead.
+func RecordGitHubAppClockSkew(offset time.Duration) {
+	metrics.Record(context.Background(), githubAppClockSkew.M(offset.Seconds()))
+}
+
+// CountResolutionCache logs a lookup of the resolution cache, the cache is
+// "templates" or "resolved" and the outcome "hit" or "miss". It is recorded
+// once the views are registered by RegisterEventViews.
+func CountResolutionCache(cache, outcome string, owner Owner) {
+	ctx, err := tag.New(context.Background(),
+		append([]tag.Mutator{tag.Insert(cacheKey, cache), tag.Insert(outcomeKey, outcome)}, ownerMutators(owner)...)...)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, resolutionCacheCount.M(1))
+}
+
+// RecordProviderCall logs the duration of an operation of a git provider API,
+// the outcome is "done" or "timeout" when it took longer than its budget.
+func RecordProviderCall(provider, operation, outcome string, duration time.Duration) {
+	ctx, err := tag.New(context.Background(),
+		tag.Insert(eventProviderKey, provider),
+		tag.Insert(operationKey, operation),
+		tag.Insert(outcomeKey, outcome))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, providerCallDuration.M(duration.Seconds()))
+}
+
+// CountEvent logs an event of a Repository received by the controller, it is
+// recorded once the views are registered by RegisterEventViews.
+func CountEvent(provider, event string, owner Owner) {
+	ctx, err := tag.New(context.Background(),
+		append([]tag.Mutator{tag.Insert(eventProviderKey, provider), tag.Insert(eventTypeKey, event)}, ownerMutators(owner)...)...)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, eventCount.M(1))
+}
+
+// CountEventDecision logs the decision taken on an event processed by the
+// controller, "matched" when PipelineRuns are started for it, "skipped" with
+// the reason of the skip, like "no-repository" or "no-match", or "error". It is
+// recorded once the views are registered by RegisterEventViews.
+func CountEventDecision(provider, event, decision, reason string) {
+	mutators := []tag.Mutator{
+		tag.Insert(eventProviderKey, provider),
+		tag.Insert(eventTypeKey, event),
+		tag.Insert(decisionKey, decision),
+	}
+	if reason != "" {
+		mutators = append(mutators, tag.Insert(reasonKey, reason))
+	}
+	ctx, err := tag.New(context.Background(), mutators...)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, eventDecisionCount.M(1))
+}
+
+// RecordEventLatency logs the latency of a stage of an event, "delivery" from
+// its sending by the git provider to its reception, "queue" until its
+// processing starts, "provider-api" in the requests to the git provider API,
+// "processing" until the end of its processing and "end-to-end". It is
+// recorded once the views are registered by RegisterEventViews.
+func RecordEventLatency(provider, stage string, latency time.Duration) {
+	ctx, err := tag.New(context.Background(),
+		tag.Insert(eventProviderKey, provider),
+		tag.Insert(stageKey, stage))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, eventLatency.M(latency.Seconds()))
+}
+
+// RecordEventLatencySLOBreach logs whether the 95th percentile of the
+// end-to-end latency of the last events exceeds the event-latency-slo setting.
+func RecordEventLatencySLOBreach(breached bool) {
+	value := 0.0
+	if breached {
+		value = 1
+	}
+	metrics.Record(context.Background(), eventLatencySLOBreach.M(value))
+}
+
+func ownerMutators(owner Owner) []tag.Mutator {
+	mutators := []tag.Mutator{}
+	for key, value := range map[tag.Key]string{orgKey: owner.Org, namespaceKey: owner.Namespace, repositoryKey: owner.Repository} {
+		if value != "" {
+			mutators = append(mutators, tag.Insert(key, value))
+		}
+	}
+	return mutators
+}
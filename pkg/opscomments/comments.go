@@ -3,9 +3,11 @@ package opscomments
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 )
 
 var (
@@ -16,6 +18,11 @@ var (
 	oktotestRegex     = regexp.MustCompile(`(?m)^/ok-to-test\s*$`)
 	cancelAllRegex    = regexp.MustCompile(`(?m)^(/cancel)\s*$`)
 	cancelSingleRegex = regexp.MustCompile(`(?m)^(/cancel)[ \t]+\S+`)
+	lintRegex         = regexp.MustCompile(`(?m)^/lint\s*$`)
+	pauseRegex        = regexp.MustCompile(`(?m)^/pause\s*$`)
+	resumeRegex       = regexp.MustCompile(`(?m)^/resume\s*$`)
+	freezeRegex       = regexp.MustCompile(`(?m)^/freeze\s*$`)
+	unfreezeRegex     = regexp.MustCompile(`(?m)^/unfreeze\s*$`)
 )
 
 type EventType string
@@ -34,15 +41,24 @@ var (
 	CancelCommentSingleEventType = EventType("cancel-comment")
 	CancelCommentAllEventType    = EventType("cancel-all-comment")
 	OkToTestCommentEventType     = EventType("ok-to-test-comment")
+	LintCommentEventType         = EventType("lint-comment")
+	PauseCommentEventType        = EventType("pause-comment")
+	ResumeCommentEventType       = EventType("resume-comment")
+	FreezeCommentEventType       = EventType("freeze-comment")
+	UnfreezeCommentEventType     = EventType("unfreeze-comment")
 )
 
 const (
 	testComment   = "/test"
 	retestComment = "/retest"
 	cancelComment = "/cancel"
+	// exactFlag makes /retest re-run the snapshots of the PipelineRuns
+	// instead of the templates of the repository.
+	exactFlag = "--exact"
 )
 
 func CommentEventType(comment string) EventType {
+	comment = StripExactFlag(comment)
 	switch {
 	case retestAllRegex.MatchString(comment):
 		return RetestAllCommentEventType
@@ -58,6 +74,16 @@ func CommentEventType(comment string) EventType {
 		return CancelCommentAllEventType
 	case cancelSingleRegex.MatchString(comment):
 		return CancelCommentSingleEventType
+	case lintRegex.MatchString(comment):
+		return LintCommentEventType
+	case pauseRegex.MatchString(comment):
+		return PauseCommentEventType
+	case resumeRegex.MatchString(comment):
+		return ResumeCommentEventType
+	case freezeRegex.MatchString(comment):
+		return FreezeCommentEventType
+	case unfreezeRegex.MatchString(comment):
+		return UnfreezeCommentEventType
 	default:
 		return NoOpsCommentEventType
 	}
@@ -69,6 +95,9 @@ func SetEventTypeAndTargetPR(event *info.Event, comment string) {
 	if commentType == RetestSingleCommentEventType || commentType == TestSingleCommentEventType {
 		event.TargetTestPipelineRun = GetPipelineRunFromTestComment(comment)
 	}
+	if commentType == RetestSingleCommentEventType || commentType == RetestAllCommentEventType {
+		event.ExactRetest = IsExactRetestComment(comment)
+	}
 	if commentType == CancelCommentAllEventType || commentType == CancelCommentSingleEventType {
 		event.CancelPipelineRuns = true
 	}
@@ -79,6 +108,63 @@ func SetEventTypeAndTargetPR(event *info.Event, comment string) {
 	event.TriggerComment = comment
 }
 
+// SetEventTypeAndTargetPush sets the PipelineRun to test or to cancel from a
+// GitOps comment made on a commit, the event stays a push event. It returns
+// the branch asked with the branch:name argument of the comment or an empty
+// string.
+func SetEventTypeAndTargetPush(event *info.Event, comment string) (string, error) {
+	var prName, branchName string
+	var err error
+
+	event.EventType = triggertype.Push.String()
+	event.TriggerTarget = triggertype.Push
+	event.TriggerComment = comment
+	switch CommentEventType(comment) {
+	case TestAllCommentEventType, TestSingleCommentEventType, RetestAllCommentEventType, RetestSingleCommentEventType:
+		prName, branchName, err = GetPipelineRunAndBranchNameFromTestComment(comment)
+		if err != nil {
+			return "", err
+		}
+		event.TargetTestPipelineRun = prName
+		event.ExactRetest = IsExactRetestComment(comment)
+	case CancelCommentAllEventType, CancelCommentSingleEventType:
+		prName, branchName, err = GetPipelineRunAndBranchNameFromCancelComment(comment)
+		if err != nil {
+			return "", err
+		}
+		event.CancelPipelineRuns = true
+		event.TargetCancelPipelineRun = prName
+	default:
+	}
+	return branchName, nil
+}
+
+// IsCommitComment returns true if the event is a GitOps comment made on a
+// commit instead of a pull request.
+func IsCommitComment(event *info.Event) bool {
+	return event.TriggerTarget == triggertype.Push && event.TriggerComment != ""
+}
+
+// IsExactRetestComment returns true if the comment is a /retest with the
+// --exact flag.
+func IsExactRetestComment(comment string) bool {
+	return StripExactFlag(comment) != comment
+}
+
+// StripExactFlag removes the --exact flag from the /retest commands of the
+// comment, so the name of the PipelineRun can be parsed as usual.
+func StripExactFlag(comment string) string {
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != retestComment || !slices.Contains(fields, exactFlag) {
+			continue
+		}
+		lines[i] = strings.Join(slices.DeleteFunc(fields, func(field string) bool { return field == exactFlag }), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
 func IsOkToTestComment(comment string) bool {
 	return oktotestRegex.MatchString(comment)
 }
@@ -87,6 +173,22 @@ func IsCancelComment(comment string) bool {
 	return cancelAllRegex.MatchString(comment) || cancelSingleRegex.MatchString(comment)
 }
 
+func IsLintComment(comment string) bool {
+	return lintRegex.MatchString(comment)
+}
+
+// IsPauseResumeComment returns true if the comment is a /pause or a /resume
+// of the Repository.
+func IsPauseResumeComment(comment string) bool {
+	return pauseRegex.MatchString(comment) || resumeRegex.MatchString(comment)
+}
+
+// IsFreezeComment returns true if the comment is a /freeze or an /unfreeze
+// of the freeze windows of the Repository.
+func IsFreezeComment(comment string) bool {
+	return freezeRegex.MatchString(comment) || unfreezeRegex.MatchString(comment)
+}
+
 func IsAnyOpsEventType(eventType string) bool {
 	return eventType == TestSingleCommentEventType.String() ||
 		eventType == TestAllCommentEventType.String() ||
@@ -95,10 +197,16 @@ func IsAnyOpsEventType(eventType string) bool {
 		eventType == CancelCommentSingleEventType.String() ||
 		eventType == CancelCommentAllEventType.String() ||
 		eventType == OkToTestCommentEventType.String() ||
+		eventType == LintCommentEventType.String() ||
+		eventType == PauseCommentEventType.String() ||
+		eventType == ResumeCommentEventType.String() ||
+		eventType == FreezeCommentEventType.String() ||
+		eventType == UnfreezeCommentEventType.String() ||
 		eventType == OnCommentEventType.String()
 }
 
 func GetPipelineRunFromTestComment(comment string) string {
+	comment = StripExactFlag(comment)
 	if strings.Contains(comment, testComment) {
 		return getNameFromComment(testComment, comment)
 	}
@@ -128,6 +236,7 @@ func getNameFromComment(typeOfComment, comment string) string {
 }
 
 func GetPipelineRunAndBranchNameFromTestComment(comment string) (string, string, error) {
+	comment = StripExactFlag(comment)
 	if strings.Contains(comment, testComment) {
 		return getPipelineRunAndBranchNameFromComment(testComment, comment)
 	}
@@ -89,6 +89,26 @@ func TestIsAnyOpsEventType(t *testing.T) {
 			eventType: OkToTestCommentEventType.String(),
 			want:      true,
 		},
+		{
+			name:      "LintCommentEventType",
+			eventType: LintCommentEventType.String(),
+			want:      true,
+		},
+		{
+			name:      "PauseCommentEventType",
+			eventType: PauseCommentEventType.String(),
+			want:      true,
+		},
+		{
+			name:      "ResumeCommentEventType",
+			eventType: ResumeCommentEventType.String(),
+			want:      true,
+		},
+		{
+			name:      "UnfreezeCommentEventType",
+			eventType: UnfreezeCommentEventType.String(),
+			want:      true,
+		},
 		{
 			name:      "OnCommentEventType",
 			eventType: OnCommentEventType.String(),
@@ -160,6 +180,36 @@ func TestCommentEventTypeTest(t *testing.T) {
 			comment: "/cancel prname",
 			want:    CancelCommentSingleEventType,
 		},
+		{
+			name:    "lint",
+			comment: "/lint",
+			want:    LintCommentEventType,
+		},
+		{
+			name:    "lint with an argument is not a lint",
+			comment: "/lint foo",
+			want:    NoOpsCommentEventType,
+		},
+		{
+			name:    "pause",
+			comment: "/pause",
+			want:    PauseCommentEventType,
+		},
+		{
+			name:    "resume",
+			comment: "/resume",
+			want:    ResumeCommentEventType,
+		},
+		{
+			name:    "freeze",
+			comment: "/freeze",
+			want:    FreezeCommentEventType,
+		},
+		{
+			name:    "unfreeze",
+			comment: "/unfreeze",
+			want:    UnfreezeCommentEventType,
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,6 +228,7 @@ func TestSetEventTypeTestPipelineRun(t *testing.T) {
 		wantTestPr   string
 		wantCancelPr string
 		wantCancel   bool
+		wantExact    bool
 	}{
 		{
 			name:     "no event type",
@@ -190,6 +241,32 @@ func TestSetEventTypeTestPipelineRun(t *testing.T) {
 			wantType:   RetestSingleCommentEventType.String(),
 			wantTestPr: "prname",
 		},
+		{
+			name:      "exact retest all event type",
+			comment:   "/retest --exact",
+			wantType:  RetestAllCommentEventType.String(),
+			wantExact: true,
+		},
+		{
+			name:       "exact retest single event type",
+			comment:    "/retest prname --exact",
+			wantType:   RetestSingleCommentEventType.String(),
+			wantTestPr: "prname",
+			wantExact:  true,
+		},
+		{
+			name:       "exact flag before the name",
+			comment:    "/retest --exact prname",
+			wantType:   RetestSingleCommentEventType.String(),
+			wantTestPr: "prname",
+			wantExact:  true,
+		},
+		{
+			name:       "exact flag only on retest",
+			comment:    "/test prname --exact",
+			wantType:   TestSingleCommentEventType.String(),
+			wantTestPr: "prname",
+		},
 		{
 			name:       "test single event type",
 			comment:    "/test prname",
@@ -209,6 +286,11 @@ func TestSetEventTypeTestPipelineRun(t *testing.T) {
 			wantType:   CancelCommentAllEventType.String(),
 			wantCancel: true,
 		},
+		{
+			name:     "lint",
+			comment:  "/lint",
+			wantType: LintCommentEventType.String(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -217,6 +299,113 @@ func TestSetEventTypeTestPipelineRun(t *testing.T) {
 			SetEventTypeAndTargetPR(event, tt.comment)
 			assert.Equal(t, tt.wantType, event.EventType)
 			assert.Equal(t, tt.wantTestPr, event.TargetTestPipelineRun)
+			assert.Equal(t, tt.wantExact, event.ExactRetest)
+		})
+	}
+}
+
+func TestSetEventTypeAndTargetPush(t *testing.T) {
+	tests := []struct {
+		name         string
+		comment      string
+		wantBranch   string
+		wantTestPr   string
+		wantCancelPr string
+		wantCancel   bool
+		wantExact    bool
+		wantErr      bool
+	}{
+		{
+			name:    "retest all",
+			comment: "/retest",
+		},
+		{
+			name:       "exact retest of a pipelinerun",
+			comment:    "/retest prname --exact",
+			wantTestPr: "prname",
+			wantExact:  true,
+		},
+		{
+			name:       "test a pipelinerun on a branch",
+			comment:    "/test prname branch:nightly",
+			wantTestPr: "prname",
+			wantBranch: "nightly",
+		},
+		{
+			name:         "cancel a pipelinerun",
+			comment:      "/cancel prname",
+			wantCancelPr: "prname",
+			wantCancel:   true,
+		},
+		{
+			name:       "cancel all on a branch",
+			comment:    "/cancel branch:nightly",
+			wantBranch: "nightly",
+			wantCancel: true,
+		},
+		{
+			name:    "not a branch argument",
+			comment: "/retest prname foo:nightly",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &info.Event{}
+			branch, err := SetEventTypeAndTargetPush(event, tt.comment)
+			if tt.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tt.wantBranch, branch)
+			assert.Equal(t, "push", event.EventType)
+			assert.Assert(t, IsCommitComment(event))
+			assert.Equal(t, tt.wantTestPr, event.TargetTestPipelineRun)
+			assert.Equal(t, tt.wantCancelPr, event.TargetCancelPipelineRun)
+			assert.Equal(t, tt.wantCancel, event.CancelPipelineRuns)
+			assert.Equal(t, tt.wantExact, event.ExactRetest)
+		})
+	}
+}
+
+func TestStripExactFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    string
+	}{
+		{
+			name:    "retest all",
+			comment: "/retest --exact",
+			want:    "/retest",
+		},
+		{
+			name:    "retest single",
+			comment: "/retest  --exact prname",
+			want:    "/retest prname",
+		},
+		{
+			name:    "other lines are kept",
+			comment: "please\n/retest prname --exact\n--exact is not a command",
+			want:    "please\n/retest prname\n--exact is not a command",
+		},
+		{
+			name:    "no flag",
+			comment: "/retest  prname",
+			want:    "/retest  prname",
+		},
+		{
+			name:    "not a retest",
+			comment: "/test --exact",
+			want:    "/test --exact",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, StripExactFlag(tt.comment), tt.want)
+			assert.Equal(t, IsExactRetestComment(tt.comment), tt.want != tt.comment)
 		})
 	}
 }
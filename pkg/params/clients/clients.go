@@ -4,14 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"sync"
-	"time"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/generated/clientset/versioned"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/httpclient"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
 	"github.com/pkg/errors"
 	versioned2 "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	"go.uber.org/zap"
@@ -22,10 +22,8 @@ import (
 )
 
 const (
-	// most programming languages  do not have a timeout, but c# does a default
-	// of 100 seconds so using that value.
-	ConnectMaxWaitTime = 100 * time.Second
-	RequestMaxWaitTime = 100 * time.Second
+	ConnectMaxWaitTime = httpclient.ConnectMaxWaitTime
+	RequestMaxWaitTime = httpclient.RequestMaxWaitTime
 )
 
 type Clients struct {
@@ -146,13 +144,17 @@ func (c *Clients) NewClients(ctx context.Context, info *info.Info) error {
 	}()
 	c.Log = logger
 
+	// the hub and the other outbound calls honor the proxy, tls and egress
+	// settings of the configmap as they get updated
 	c.HTTP = http.Client{
 		Timeout: RequestMaxWaitTime,
-		Transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout: ConnectMaxWaitTime,
-			}).DialContext,
-		},
+		Transport: httpclient.NewTransport(func() *settings.Settings {
+			if info.Pac == nil {
+				return nil
+			}
+			pacOpts := info.GetPacOpts()
+			return &pacOpts.Settings
+		}, logger),
 	}
 	config, err := c.kubeConfig(info)
 	if err != nil {
@@ -35,13 +35,23 @@ type Event struct {
 	HeadURL       string // url from where our SHA get tested
 	SHA           string
 	Sender        string
+	SenderBot     bool   // the sender is flagged as a bot account by the provider
+	SenderRole    Role   // the role of the sender on the repository once resolved by the policy
 	URL           string // WEB url not the git URL, which would match to the repo.spec
 	SHAURL        string // pretty URL for web browsing for UIs (cli/web)
 	SHATitle      string // commit title for UIs
-
-	PullRequestNumber int    // Pull or Merge Request number
-	PullRequestTitle  string // Title of the pull Request
-	TriggerComment    string // The comment triggering the pipelinerun when using on-comment annotation
+	SHAMessage    string // full commit message, for the [skip ci] directives
+	// SHASignature is the verification of the signature of the commit by the
+	// git provider.
+	SHASignature CommitSignature
+	// TagMessage is the message of the annotated tag of a tag push.
+	TagMessage string
+
+	PullRequestNumber int      // Pull or Merge Request number
+	PullRequestTitle  string   // Title of the pull Request
+	PullRequestLabel  []string // Labels of the pull Request
+	TriggerComment    string   // The comment triggering the pipelinerun when using on-comment annotation
+	TriggerCommentID  int64    // The ID of the comment triggering the event, to react to it
 
 	// TODO: move forge specifics to each driver
 	// Github
@@ -49,6 +59,10 @@ type Event struct {
 	Repository     string
 	InstallationID int64
 	GHEURL         string
+	// DeploymentEnvironment and DeploymentCallbackURL are set by the
+	// deployments waiting on the protection rule of an environment.
+	DeploymentEnvironment string
+	DeploymentCallbackURL string
 
 	// TODO: move out inside the provider
 	// Bitbucket Cloud
@@ -68,12 +82,49 @@ type State struct {
 	TargetTestPipelineRun   string
 	CancelPipelineRuns      bool
 	TargetCancelPipelineRun string
+	// ExactRetest is set by /retest --exact, the PipelineRuns are re-run
+	// from the snapshots of their last runs instead of the templates of the
+	// repository.
+	ExactRetest bool
+	// PullRequestClosed is set when the pull request has been closed or
+	// merged, only used to clean up its preview environment.
+	PullRequestClosed bool
+	// BranchDeleted is set on a push deleting a branch, the PipelineRuns
+	// still running for it get cancelled.
+	BranchDeleted bool
+	// RebuildPipelineRuns are the PipelineRuns re-run on a pull request by
+	// an update of its base branch, see the rebuild_on_base_update setting.
+	RebuildPipelineRuns []string
+	// IgnoredPaths are the patterns of the pac-ignore files of the
+	// PipelineRun directories, the changed files matching them are excluded
+	// from the path-change matching.
+	IgnoredPaths []string
+}
+
+// The status of the verification of the signature of a commit.
+const (
+	SignatureVerified   = "verified"
+	SignatureUnverified = "unverified"
+	SignatureUnsigned   = "unsigned"
+)
+
+// CommitSignature is the verification of the signature of a commit by the git
+// provider.
+type CommitSignature struct {
+	// Status is verified, unverified or unsigned, empty when the git provider
+	// doesn't verify the signatures.
+	Status string
+	// KeyID is the ID of the key the commit has been signed with, the long
+	// ID of a GPG key, the SHA256 fingerprint of an SSH key or the subject
+	// key identifier of an X.509 certificate.
+	KeyID string
 }
 
 type Provider struct {
 	Token                 string
 	URL                   string
 	User                  string
+	AuthType              string
 	WebhookSecret         string
 	WebhookSecretFromRepo bool
 }
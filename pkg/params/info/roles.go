@@ -0,0 +1,43 @@
+package info
+
+// Role is the role of a user on a repository, normalized across the git
+// providers.
+type Role string
+
+const (
+	// RoleOwner owns or administers the repository.
+	RoleOwner Role = "owner"
+	// RoleMaintainer maintains the repository without administering it.
+	RoleMaintainer Role = "maintainer"
+	// RoleCollaborator can push to the repository.
+	RoleCollaborator Role = "collaborator"
+	// RoleAuthor cannot push to the repository but has opened the pull
+	// request of the event.
+	RoleAuthor Role = "author"
+	// RoleNone has no role on the repository.
+	RoleNone Role = "none"
+)
+
+// roleRanks orders the roles from the least to the most privileged.
+var roleRanks = map[Role]int{
+	RoleNone:         0,
+	RoleAuthor:       1,
+	RoleCollaborator: 2,
+	RoleMaintainer:   3,
+	RoleOwner:        4,
+}
+
+// ParseRole returns the role named name, false when there is no such role.
+func ParseRole(name string) (Role, bool) {
+	role := Role(name)
+	_, ok := roleRanks[role]
+	return role, ok
+}
+
+// AtLeast returns true if the role is as privileged as the minimum role, an
+// unknown role is never.
+func (r Role) AtLeast(minimum Role) bool {
+	rank, ok := roleRanks[r]
+	minRank, minOk := roleRanks[minimum]
+	return ok && minOk && rank >= minRank
+}
@@ -1,8 +1,8 @@
 package info
 
 type ProviderConfig struct {
-	TaskStatusTMPL string
-	APIURL         string
-	Name           string
-	SkipEmoji      bool
+	APIURL string
+	// Name is the name of the git provider, it selects its formatting
+	// profile.
+	Name string
 }
@@ -0,0 +1,89 @@
+package settings
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// kubernetesAudiences are the usual audiences of the Kubernetes API server,
+// a token of a service account minted for one of them gives access to the
+// cluster.
+var kubernetesAudiences = []string{
+	"api",
+	"kubernetes",
+	"kubernetes.default",
+	"kubernetes.default.svc",
+	"kubernetes.default.svc.cluster.local",
+	"https://kubernetes.default",
+	"https://kubernetes.default.svc",
+	"https://kubernetes.default.svc.cluster.local",
+}
+
+// IsKubernetesAudience returns true if the audience is one of the usual
+// audiences of the Kubernetes API server.
+func IsKubernetesAudience(audience string) bool {
+	return slices.Contains(kubernetesAudiences, strings.TrimSuffix(strings.ToLower(strings.TrimSpace(audience)), "/"))
+}
+
+// ParseCloudCredentialsTokenURLs parses the comma separated list of the https
+// token exchange endpoints the cloud_credentials setting of the Repositories
+// may use.
+func ParseCloudCredentialsTokenURLs(s string) ([]string, error) {
+	urls := []string{}
+	for _, value := range strings.Split(s, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("invalid cloud credentials token url %q, needs to be an https url", value)
+		}
+		urls = append(urls, value)
+	}
+	return urls, nil
+}
+
+func isValidCloudCredentialsTokenURLs(value string) error {
+	_, err := ParseCloudCredentialsTokenURLs(value)
+	return err
+}
+
+// ParseCloudCredentialsAudiences parses the comma separated list of the
+// audiences the cloud_credentials setting of the Repositories may request the
+// service account tokens for, the audiences of the Kubernetes API server are
+// refused.
+func ParseCloudCredentialsAudiences(s string) ([]string, error) {
+	audiences := []string{}
+	for _, value := range strings.Split(s, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if IsKubernetesAudience(value) {
+			return nil, fmt.Errorf("invalid cloud credentials audience %q, it is an audience of the Kubernetes API server", value)
+		}
+		audiences = append(audiences, value)
+	}
+	return audiences, nil
+}
+
+func isValidCloudCredentialsAudiences(value string) error {
+	_, err := ParseCloudCredentialsAudiences(value)
+	return err
+}
+
+// CloudCredentialsAllowed checks the token exchange endpoint and the audience
+// of the cloud_credentials setting of a Repository are allowed by the admin,
+// nothing is allowed when the lists are empty.
+func (s *Settings) CloudCredentialsAllowed(tokenURL, audience string) error {
+	if !slices.Contains(s.Parsed.CloudCredentialsTokenURLs, tokenURL) {
+		return fmt.Errorf("the token_url %s of the cloud credentials is not allowed by the cloud-credentials-token-urls setting", tokenURL)
+	}
+	if !slices.Contains(s.Parsed.CloudCredentialsAudiences, audience) {
+		return fmt.Errorf("the audience %s of the cloud credentials is not allowed by the cloud-credentials-audiences setting", audience)
+	}
+	return nil
+}
@@ -4,11 +4,17 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/configutil"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secretscan"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 )
 
 const (
@@ -16,9 +22,16 @@ const (
 
 	HubURLKey                  = "hub-url"
 	HubCatalogNameKey          = "hub-catalog-name"
+	HubCatalogTypeKey          = "hub-catalog-type"
 	HubURLDefaultValue         = "https://api.hub.tekton.dev/v1"
 	HubCatalogNameDefaultValue = "tekton"
 
+	// HubCatalogTypeHub is a catalog fetched from the API of a tekton hub.
+	HubCatalogTypeHub = "hub"
+	// HubCatalogTypeLocal is a catalog read from a directory of the
+	// controller, for the clusters without internet access.
+	HubCatalogTypeLocal = "local"
+
 	CustomConsoleNameKey         = "custom-console-name"
 	CustomConsoleURLKey          = "custom-console-url"
 	CustomConsolePRDetailKey     = "custom-console-url-pr-details"
@@ -26,6 +39,13 @@ const (
 	CustomConsoleNamespaceURLKey = "custom-console-url-namespace"
 
 	SecretGhAppTokenRepoScopedKey = "secret-github-app-token-scoped" //nolint: gosec
+
+	// PipelineRunNamingGenerate lets Kubernetes add a random suffix to the
+	// name of the PipelineRuns.
+	PipelineRunNamingGenerate = "generate"
+	// PipelineRunNamingDeterministic names the PipelineRuns after their
+	// pipeline, the short SHA of the commit and an attempt counter.
+	PipelineRunNamingDeterministic = "deterministic"
 )
 
 var (
@@ -38,6 +58,7 @@ type HubCatalog struct {
 	ID   string
 	Name string
 	URL  string
+	Type string
 }
 
 type Settings struct {
@@ -55,6 +76,8 @@ type Settings struct {
 	SecretAutoCreation               bool   `default:"true"                             json:"secret-auto-create"`
 	SecretGHAppRepoScoped            bool   `default:"true"                             json:"secret-github-app-token-scoped"`
 	SecretGhAppTokenScopedExtraRepos string `json:"secret-github-app-scope-extra-repos"`
+	SecretAutoCreateRegistrySecrets  string `json:"secret-auto-create-registry-secrets"`
+	RegistrySecretsNamespaces        string `json:"secret-auto-create-registry-secrets-namespaces"`
 
 	ErrorLogSnippet             bool   `default:"true"                                                                          json:"error-log-snippet"`
 	ErrorDetection              bool   `default:"true"                                                                          json:"error-detection-from-container-logs"`
@@ -68,6 +91,163 @@ type Settings struct {
 	CustomConsoleNamespaceURL string `json:"custom-console-url-namespace"`
 
 	RememberOKToTest bool `default:"true" json:"remember-ok-to-test"`
+
+	GitOpsCommentReactions bool `default:"true" json:"gitops-comment-reactions"`
+
+	PreviewEnvironments bool `default:"false" json:"preview-environments"`
+
+	IgnoredSenders string `json:"ignored-senders"`
+	IgnoreBotPRs   bool   `default:"false"        json:"ignore-bot-prs"`
+
+	CustomEventTypes string `json:"custom-event-types"`
+
+	NamespaceIsolationPolicy string `json:"namespace-isolation-policy"`
+
+	ServiceAccountAllowlist string `json:"service-account-allowlist"`
+
+	EventSigningSecret string `json:"event-signing-secret"`
+
+	HTTPProxy     string `json:"http-proxy"`
+	HTTPSProxy    string `json:"https-proxy"`
+	NoProxy       string `json:"no-proxy"`
+	CABundles     string `json:"ca-bundles"`
+	TLSMinVersion string `json:"tls-min-version"`
+
+	EgressAllowedHosts string `json:"egress-allowed-hosts"`
+	EgressAudit        bool   `default:"false"            json:"egress-audit"`
+
+	TokenValidationHosts string `default:"api.github.com, gitlab.com" json:"token-validation-hosts"`
+
+	CloudCredentialsTokenURLs string `json:"cloud-credentials-token-urls"`
+	CloudCredentialsAudiences string `json:"cloud-credentials-audiences"`
+
+	EventDeduplicationWindow string `json:"event-deduplication-window"`
+
+	EventPayloadTTL string `json:"event-payload-ttl"`
+
+	EventLatencySLO string `json:"event-latency-slo"`
+
+	MetricsAggregationLevel string `json:"metrics-aggregation-level"`
+	MetricsLabelsAllowlist  string `json:"metrics-labels-allowlist"`
+
+	ProviderStatusTimeout string `default:"30s" json:"provider-status-timeout"`
+	ProviderFilesTimeout  string `default:"1m"  json:"provider-files-timeout"`
+	ProviderDiffTimeout   string `default:"1m"  json:"provider-diff-timeout"`
+	ProviderReadRetries   int    `default:"2"   json:"provider-read-retries"`
+
+	GlobalConcurrencyLimit int `json:"global-concurrency-limit"`
+
+	MaintenanceMode              bool `default:"false" json:"maintenance-mode"`
+	MaintenanceMaxBufferedEvents int  `default:"1000"  json:"maintenance-max-buffered-events"`
+
+	PipelineRunEnv string `json:"pipelinerun-env"`
+
+	CostCPUCoreHourPrice   string `json:"cost-cpu-core-hour-price"`
+	CostMemoryGiBHourPrice string `json:"cost-memory-gib-hour-price"`
+	CostCurrency           string `default:"USD"                     json:"cost-currency"`
+
+	GitOpsAuthorizerURL      string `json:"gitops-authorizer-url"`
+	GitOpsAuthorizerFailOpen bool   `default:"false"               json:"gitops-authorizer-fail-open"`
+	GitOpsAuthorizerTimeout  string `default:"5s"                  json:"gitops-authorizer-timeout"`
+
+	TektonResultsURL string `json:"tekton-results-url"`
+
+	IncomingWebhookURL string `json:"incoming-webhook-url"`
+
+	ResolutionCacheTTL string `default:"10m" json:"resolution-cache-ttl"`
+
+	MaxPipelineRunTimeout string `json:"max-pipelinerun-timeout"`
+
+	SecretScanning          bool   `default:"false"                   json:"secret-scanning"`
+	SecretScanningRules     string `json:"secret-scanning-rules"`
+	SecretScanningNotifyURL string `json:"secret-scanning-notify-url"`
+
+	GitHubAppJWTClockSkew       string `default:"60s" json:"github-app-jwt-clock-skew"`
+	GitHubAppTokenRefreshBefore string `default:"5m"  json:"github-app-token-refresh-before"`
+
+	PipelineRunNaming string `default:"generate" json:"pipelinerun-naming"`
+
+	// Parsed holds the typed values of the settings, parsed once when the
+	// config is synced.
+	Parsed Parsed
+}
+
+// Parsed are the typed values of the settings, the fields of Settings keep
+// them as they are written in the ConfigMap.
+type Parsed struct {
+	ProviderStatusTimeout     time.Duration
+	ProviderFilesTimeout      time.Duration
+	ProviderDiffTimeout       time.Duration
+	PipelineRunEnv            []corev1.EnvVar
+	EgressAllowedHosts        []string
+	TokenValidationHosts      []string
+	CloudCredentialsTokenURLs []string
+	CloudCredentialsAudiences []string
+	CostCPUCoreHourPrice      float64
+	CostMemoryGiBHourPrice    float64
+
+	EventTypeAliases   triggertype.Aliases
+	NamespaceIsolation NamespaceIsolationPolicy
+	ServiceAccounts    ServiceAccountAllowlist
+	IgnoredSenders     []string
+	CABundles          map[string]string
+	TLSMinVersion      uint16
+
+	RegistrySecrets           []string
+	RegistrySecretsNamespaces []string
+	SecretScanningRules       []secretscan.Rule
+
+	EventDeduplicationWindow    time.Duration
+	EventPayloadTTL             time.Duration
+	EventLatencySLO             time.Duration
+	ResolutionCacheTTL          time.Duration
+	MaxPipelineRunTimeout       time.Duration
+	GitOpsAuthorizerTimeout     time.Duration
+	GitHubAppJWTClockSkew       time.Duration
+	GitHubAppTokenRefreshBefore time.Duration
+}
+
+// parse computes the typed values of the settings, they have already been
+// validated.
+func (s *Settings) parse() {
+	s.Parsed.ProviderStatusTimeout, _ = time.ParseDuration(s.ProviderStatusTimeout)
+	s.Parsed.ProviderFilesTimeout, _ = time.ParseDuration(s.ProviderFilesTimeout)
+	s.Parsed.ProviderDiffTimeout, _ = time.ParseDuration(s.ProviderDiffTimeout)
+	s.Parsed.PipelineRunEnv, _ = ParsePipelineRunEnv(s.PipelineRunEnv)
+	s.Parsed.EgressAllowedHosts, _ = ParseEgressAllowedHosts(s.EgressAllowedHosts)
+	s.Parsed.TokenValidationHosts, _ = ParseTokenValidationHosts(s.TokenValidationHosts)
+	s.Parsed.CloudCredentialsTokenURLs, _ = ParseCloudCredentialsTokenURLs(s.CloudCredentialsTokenURLs)
+	s.Parsed.CloudCredentialsAudiences, _ = ParseCloudCredentialsAudiences(s.CloudCredentialsAudiences)
+	s.Parsed.CostCPUCoreHourPrice, _ = strconv.ParseFloat(s.CostCPUCoreHourPrice, 64)
+	s.Parsed.CostMemoryGiBHourPrice, _ = strconv.ParseFloat(s.CostMemoryGiBHourPrice, 64)
+
+	s.Parsed.EventTypeAliases, _ = triggertype.ParseAliases(s.CustomEventTypes)
+	s.Parsed.NamespaceIsolation, _ = ParseNamespaceIsolationPolicy(s.NamespaceIsolationPolicy)
+	s.Parsed.ServiceAccounts, _ = ParseServiceAccountAllowlist(s.ServiceAccountAllowlist)
+	s.Parsed.IgnoredSenders, _ = ParseIgnoredSenders(s.IgnoredSenders)
+	s.Parsed.CABundles, _ = ParseCABundles(s.CABundles)
+	s.Parsed.TLSMinVersion, _ = ParseTLSMinVersion(s.TLSMinVersion)
+
+	s.Parsed.RegistrySecrets, _ = ParseRegistrySecrets(s.SecretAutoCreateRegistrySecrets)
+	s.Parsed.RegistrySecretsNamespaces, _ = ParseRegistrySecretsNamespaces(s.RegistrySecretsNamespaces)
+
+	rules, _ := secretscan.ParseRules(s.SecretScanningRules)
+	s.Parsed.SecretScanningRules = append(append([]secretscan.Rule{}, secretscan.DefaultRules...), rules...)
+
+	s.Parsed.EventDeduplicationWindow, _ = time.ParseDuration(s.EventDeduplicationWindow)
+	s.Parsed.EventPayloadTTL, _ = time.ParseDuration(s.EventPayloadTTL)
+	s.Parsed.EventLatencySLO, _ = time.ParseDuration(s.EventLatencySLO)
+	s.Parsed.ResolutionCacheTTL, _ = time.ParseDuration(s.ResolutionCacheTTL)
+	s.Parsed.MaxPipelineRunTimeout, _ = time.ParseDuration(s.MaxPipelineRunTimeout)
+	s.Parsed.GitOpsAuthorizerTimeout, _ = time.ParseDuration(s.GitOpsAuthorizerTimeout)
+	s.Parsed.GitHubAppJWTClockSkew = time.Minute
+	if s.GitHubAppJWTClockSkew != "" {
+		s.Parsed.GitHubAppJWTClockSkew, _ = time.ParseDuration(s.GitHubAppJWTClockSkew)
+	}
+	s.Parsed.GitHubAppTokenRefreshBefore = 5 * time.Minute
+	if s.GitHubAppTokenRefreshBefore != "" {
+		s.Parsed.GitHubAppTokenRefreshBefore, _ = time.ParseDuration(s.GitHubAppTokenRefreshBefore)
+	}
 }
 
 func (s *Settings) DeepCopy(out *Settings) {
@@ -81,16 +261,58 @@ func DefaultSettings() Settings {
 		ID:   "default",
 		Name: HubCatalogNameDefaultValue,
 		URL:  HubURLDefaultValue,
+		Type: HubCatalogTypeHub,
 	})
 	newSettings.HubCatalogs = hubCatalog
 
 	_ = configutil.ValidateAndAssignValues(nil, map[string]string{}, newSettings, map[string]func(string) error{
-		"ErrorDetectionSimpleRegexp": isValidRegex,
-		"TektonDashboardURL":         isValidURL,
-		"CustomConsoleURL":           isValidURL,
-		"CustomConsolePRTaskLog":     startWithHTTPorHTTPS,
-		"CustomConsolePRDetail":      startWithHTTPorHTTPS,
+		"ErrorDetectionSimpleRegexp":      isValidRegex,
+		"TektonDashboardURL":              isValidURL,
+		"CustomConsoleURL":                isValidURL,
+		"CustomConsolePRTaskLog":          startWithHTTPorHTTPS,
+		"CustomConsolePRDetail":           startWithHTTPorHTTPS,
+		"CustomEventTypes":                isValidCustomEventTypes,
+		"NamespaceIsolationPolicy":        isValidNamespaceIsolationPolicy,
+		"ServiceAccountAllowlist":         isValidServiceAccountAllowlist,
+		"EventSigningSecret":              isValidEventSigningSecret,
+		"HTTPProxy":                       isValidProxyURL,
+		"HTTPSProxy":                      isValidProxyURL,
+		"CABundles":                       isValidCABundles,
+		"TLSMinVersion":                   isValidTLSMinVersion,
+		"EgressAllowedHosts":              isValidEgressAllowedHosts,
+		"TokenValidationHosts":            isValidTokenValidationHosts,
+		"CloudCredentialsTokenURLs":       isValidCloudCredentialsTokenURLs,
+		"CloudCredentialsAudiences":       isValidCloudCredentialsAudiences,
+		"EventDeduplicationWindow":        isValidDuration,
+		"EventPayloadTTL":                 isValidDuration,
+		"EventLatencySLO":                 isValidDuration,
+		"IgnoredSenders":                  isValidIgnoredSenders,
+		"SecretAutoCreateRegistrySecrets": isValidRegistrySecrets,
+		"RegistrySecretsNamespaces":       isValidRegistrySecretsNamespaces,
+		"MetricsAggregationLevel":         isValidMetricsAggregationLevel,
+		"MetricsLabelsAllowlist":          isValidMetricsLabelsAllowlist,
+		"ProviderStatusTimeout":           isValidDuration,
+		"ProviderFilesTimeout":            isValidDuration,
+		"ProviderDiffTimeout":             isValidDuration,
+		"ProviderReadRetries":             isValidProviderReadRetries,
+		"GlobalConcurrencyLimit":          isValidGlobalConcurrencyLimit,
+		"MaintenanceMaxBufferedEvents":    isValidMaintenanceMaxBufferedEvents,
+		"PipelineRunEnv":                  isValidPipelineRunEnv,
+		"CostCPUCoreHourPrice":            isValidPrice,
+		"CostMemoryGiBHourPrice":          isValidPrice,
+		"GitOpsAuthorizerURL":             startWithHTTPorHTTPS,
+		"GitOpsAuthorizerTimeout":         isValidDuration,
+		"TektonResultsURL":                startWithHTTPorHTTPS,
+		"IncomingWebhookURL":              startWithHTTPorHTTPS,
+		"ResolutionCacheTTL":              isValidDuration,
+		"MaxPipelineRunTimeout":           isValidDuration,
+		"SecretScanningRules":             isValidSecretScanningRules,
+		"SecretScanningNotifyURL":         startWithHTTPorHTTPS,
+		"GitHubAppJWTClockSkew":           isValidGitHubAppJWTClockSkew,
+		"GitHubAppTokenRefreshBefore":     isValidGitHubAppTokenRefreshBefore,
+		"PipelineRunNaming":               isValidPipelineRunNaming,
 	}, false)
+	newSettings.parse()
 
 	return *newSettings
 }
@@ -99,15 +321,56 @@ func SyncConfig(logger *zap.SugaredLogger, setting *Settings, config map[string]
 	setting.HubCatalogs = getHubCatalogs(logger, setting.HubCatalogs, config)
 
 	err := configutil.ValidateAndAssignValues(logger, config, setting, map[string]func(string) error{
-		"ErrorDetectionSimpleRegexp": isValidRegex,
-		"TektonDashboardURL":         isValidURL,
-		"CustomConsoleURL":           isValidURL,
-		"CustomConsolePRTaskLog":     startWithHTTPorHTTPS,
-		"CustomConsolePRDetail":      startWithHTTPorHTTPS,
+		"ErrorDetectionSimpleRegexp":      isValidRegex,
+		"TektonDashboardURL":              isValidURL,
+		"CustomConsoleURL":                isValidURL,
+		"CustomConsolePRTaskLog":          startWithHTTPorHTTPS,
+		"CustomConsolePRDetail":           startWithHTTPorHTTPS,
+		"CustomEventTypes":                isValidCustomEventTypes,
+		"NamespaceIsolationPolicy":        isValidNamespaceIsolationPolicy,
+		"ServiceAccountAllowlist":         isValidServiceAccountAllowlist,
+		"EventSigningSecret":              isValidEventSigningSecret,
+		"HTTPProxy":                       isValidProxyURL,
+		"HTTPSProxy":                      isValidProxyURL,
+		"CABundles":                       isValidCABundles,
+		"TLSMinVersion":                   isValidTLSMinVersion,
+		"EgressAllowedHosts":              isValidEgressAllowedHosts,
+		"TokenValidationHosts":            isValidTokenValidationHosts,
+		"CloudCredentialsTokenURLs":       isValidCloudCredentialsTokenURLs,
+		"CloudCredentialsAudiences":       isValidCloudCredentialsAudiences,
+		"EventDeduplicationWindow":        isValidDuration,
+		"EventPayloadTTL":                 isValidDuration,
+		"EventLatencySLO":                 isValidDuration,
+		"IgnoredSenders":                  isValidIgnoredSenders,
+		"SecretAutoCreateRegistrySecrets": isValidRegistrySecrets,
+		"RegistrySecretsNamespaces":       isValidRegistrySecretsNamespaces,
+		"MetricsAggregationLevel":         isValidMetricsAggregationLevel,
+		"MetricsLabelsAllowlist":          isValidMetricsLabelsAllowlist,
+		"ProviderStatusTimeout":           isValidDuration,
+		"ProviderFilesTimeout":            isValidDuration,
+		"ProviderDiffTimeout":             isValidDuration,
+		"ProviderReadRetries":             isValidProviderReadRetries,
+		"GlobalConcurrencyLimit":          isValidGlobalConcurrencyLimit,
+		"MaintenanceMaxBufferedEvents":    isValidMaintenanceMaxBufferedEvents,
+		"PipelineRunEnv":                  isValidPipelineRunEnv,
+		"CostCPUCoreHourPrice":            isValidPrice,
+		"CostMemoryGiBHourPrice":          isValidPrice,
+		"GitOpsAuthorizerURL":             startWithHTTPorHTTPS,
+		"GitOpsAuthorizerTimeout":         isValidDuration,
+		"TektonResultsURL":                startWithHTTPorHTTPS,
+		"IncomingWebhookURL":              startWithHTTPorHTTPS,
+		"ResolutionCacheTTL":              isValidDuration,
+		"MaxPipelineRunTimeout":           isValidDuration,
+		"SecretScanningRules":             isValidSecretScanningRules,
+		"SecretScanningNotifyURL":         startWithHTTPorHTTPS,
+		"GitHubAppJWTClockSkew":           isValidGitHubAppJWTClockSkew,
+		"GitHubAppTokenRefreshBefore":     isValidGitHubAppTokenRefreshBefore,
+		"PipelineRunNaming":               isValidPipelineRunNaming,
 	}, true)
 	if err != nil {
 		return fmt.Errorf("failed to validate and assign values: %w", err)
 	}
+	setting.parse()
 
 	value, _ := setting.HubCatalogs.Load("default")
 	catalogDefault, ok := value.(HubCatalog)
@@ -120,6 +383,10 @@ func SyncConfig(logger *zap.SugaredLogger, setting *Settings, config map[string]
 			logger.Infof("CONFIG: hub catalog name set to %v", config[HubCatalogNameKey])
 			catalogDefault.Name = config[HubCatalogNameKey]
 		}
+		if catalogDefault.Type != config[HubCatalogTypeKey] {
+			logger.Infof("CONFIG: hub catalog type set to %v", config[HubCatalogTypeKey])
+			catalogDefault.Type = config[HubCatalogTypeKey]
+		}
 	}
 	setting.HubCatalogs.Store("default", catalogDefault)
 	// TODO: detect changes in extra hub catalogs
@@ -147,3 +414,189 @@ func startWithHTTPorHTTPS(url string) error {
 	}
 	return nil
 }
+
+func isValidCustomEventTypes(value string) error {
+	_, err := triggertype.ParseAliases(value)
+	return err
+}
+
+// EventTypeAliases returns the custom event types declared by the admin.
+func (s *Settings) EventTypeAliases() triggertype.Aliases {
+	return s.Parsed.EventTypeAliases
+}
+
+func isValidDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration: %w", err)
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid duration %s, cannot be negative", value)
+	}
+	return nil
+}
+
+// EventDeduplicationWindowDuration returns how long a PipelineRun started for
+// an event keeps the same event delivered again from being started, 0 when
+// the deduplication is disabled.
+func (s *Settings) EventDeduplicationWindowDuration() time.Duration {
+	return s.Parsed.EventDeduplicationWindow
+}
+
+// ResolutionCacheTTLDuration returns how long the PipelineRuns resolved for a
+// commit are cached, 0 when the cache is disabled.
+func (s *Settings) ResolutionCacheTTLDuration() time.Duration {
+	return s.Parsed.ResolutionCacheTTL
+}
+
+// MaxPipelineRunTimeoutDuration returns the maximum timeout of the
+// PipelineRuns, 0 when their timeout is not limited.
+func (s *Settings) MaxPipelineRunTimeoutDuration() time.Duration {
+	return s.Parsed.MaxPipelineRunTimeout
+}
+
+// the JWTs of a GitHub App are valid for 10 minutes at most, its installation
+// tokens for an hour
+const (
+	maxGitHubAppJWTClockSkew       = 5 * time.Minute
+	maxGitHubAppTokenRefreshBefore = 50 * time.Minute
+)
+
+func isValidGitHubAppJWTClockSkew(value string) error {
+	if err := isValidDuration(value); err != nil {
+		return err
+	}
+	if d, _ := time.ParseDuration(value); d > maxGitHubAppJWTClockSkew {
+		return fmt.Errorf("invalid clock skew %s, cannot be more than %s", value, maxGitHubAppJWTClockSkew)
+	}
+	return nil
+}
+
+func isValidGitHubAppTokenRefreshBefore(value string) error {
+	if err := isValidDuration(value); err != nil {
+		return err
+	}
+	if d, _ := time.ParseDuration(value); d > maxGitHubAppTokenRefreshBefore {
+		return fmt.Errorf("invalid token refresh %s, cannot be more than %s before the expiration", value, maxGitHubAppTokenRefreshBefore)
+	}
+	return nil
+}
+
+// GitHubAppJWTClockSkewDuration returns the clock skew with GitHub tolerated by
+// the JWTs of the GitHub App, their issue time is moved back by it.
+func (s *Settings) GitHubAppJWTClockSkewDuration() time.Duration {
+	return s.Parsed.GitHubAppJWTClockSkew
+}
+
+// GitHubAppTokenRefreshBeforeDuration returns how long before its expiration
+// an installation token of the GitHub App is refreshed.
+func (s *Settings) GitHubAppTokenRefreshBeforeDuration() time.Duration {
+	return s.Parsed.GitHubAppTokenRefreshBefore
+}
+
+func isValidPipelineRunNaming(value string) error {
+	switch value {
+	case PipelineRunNamingGenerate, PipelineRunNamingDeterministic:
+		return nil
+	}
+	return fmt.Errorf("invalid pipelinerun naming %q, must be %s or %s", value, PipelineRunNamingGenerate, PipelineRunNamingDeterministic)
+}
+
+// EventPayloadTTLDuration returns how long the redacted event of the
+// PipelineRuns is kept, 0 when the events are not stored.
+func (s *Settings) EventPayloadTTLDuration() time.Duration {
+	return s.Parsed.EventPayloadTTL
+}
+
+// EventLatencySLODuration returns the objective of the 95th percentile of the
+// end-to-end latency of the events, 0 when it is not tracked.
+func (s *Settings) EventLatencySLODuration() time.Duration {
+	return s.Parsed.EventLatencySLO
+}
+
+// GitOpsAuthorizerTimeoutDuration returns how long the external authorizer of
+// the GitOps commands has to answer.
+func (s *Settings) GitOpsAuthorizerTimeoutDuration() time.Duration {
+	if s.Parsed.GitOpsAuthorizerTimeout == 0 {
+		return 5 * time.Second
+	}
+	return s.Parsed.GitOpsAuthorizerTimeout
+}
+
+func isValidProviderReadRetries(value string) error {
+	retries, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid number of retries: %w", err)
+	}
+	if retries < 0 || retries > 10 {
+		return fmt.Errorf("invalid number of retries %d, must be between 0 and 10", retries)
+	}
+	return nil
+}
+
+func isValidGlobalConcurrencyLimit(value string) error {
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid concurrency limit: %w", err)
+	}
+	if limit < 0 {
+		return fmt.Errorf("invalid concurrency limit %d, cannot be negative", limit)
+	}
+	return nil
+}
+
+func isValidMaintenanceMaxBufferedEvents(value string) error {
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid number of buffered events: %w", err)
+	}
+	if limit < 1 {
+		return fmt.Errorf("invalid number of buffered events %d, must be at least 1", limit)
+	}
+	return nil
+}
+
+func isValidPrice(value string) error {
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid price: %w", err)
+	}
+	if price < 0 {
+		return fmt.Errorf("invalid price %s, cannot be negative", value)
+	}
+	return nil
+}
+
+// CostPrices returns the price of a CPU core and of a GiB of memory requested
+// for an hour, the cost of the PipelineRuns is not estimated when both are 0.
+func (s *Settings) CostPrices() (cpu, memory float64) {
+	return s.Parsed.CostCPUCoreHourPrice, s.Parsed.CostMemoryGiBHourPrice
+}
+
+func isValidSecretScanningRules(value string) error {
+	_, err := secretscan.ParseRules(value)
+	return err
+}
+
+// SecretScanningRuleset returns the rules detecting the credentials in the
+// files changed by the pull requests, the default ones and the ones of the
+// settings.
+func (s *Settings) SecretScanningRuleset() []secretscan.Rule {
+	return s.Parsed.SecretScanningRules
+}
+
+func isValidMetricsAggregationLevel(value string) error {
+	_, err := metrics.ParseAggregationLevel(value)
+	return err
+}
+
+func isValidMetricsLabelsAllowlist(value string) error {
+	_, err := metrics.ParseLabelsAllowlist(value)
+	return err
+}
+
+// MetricsAggregation returns how the metrics are labeled with the Repository
+// they belong to.
+func (s *Settings) MetricsAggregation() metrics.Aggregation {
+	return metrics.NewAggregation(s.MetricsAggregationLevel, s.MetricsLabelsAllowlist)
+}
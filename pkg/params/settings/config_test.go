@@ -1,11 +1,17 @@
 package settings
 
 import (
+	"crypto/tls"
 	"reflect"
+	"regexp"
 	"testing"
+	"time"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secretscan"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
 	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestSyncConfig(t *testing.T) {
@@ -44,33 +50,116 @@ func TestSyncConfig(t *testing.T) {
 				CustomConsolePRTaskLog:             "",
 				CustomConsoleNamespaceURL:          "",
 				RememberOKToTest:                   true,
+				GitOpsCommentReactions:             true,
+				ProviderStatusTimeout:              "30s",
+				ProviderFilesTimeout:               "1m",
+				ProviderDiffTimeout:                "1m",
+				ProviderReadRetries:                2,
+				MaintenanceMaxBufferedEvents:       1000,
+				CostCurrency:                       "USD",
+				GitOpsAuthorizerTimeout:            "5s",
+				ResolutionCacheTTL:                 "10m",
+				GitHubAppJWTClockSkew:              "60s",
+				GitHubAppTokenRefreshBefore:        "5m",
+				PipelineRunNaming:                  "generate",
+				TokenValidationHosts:               "api.github.com, gitlab.com",
+				Parsed: Parsed{
+					ProviderStatusTimeout:       30 * time.Second,
+					ProviderFilesTimeout:        time.Minute,
+					ProviderDiffTimeout:         time.Minute,
+					PipelineRunEnv:              []corev1.EnvVar{},
+					EgressAllowedHosts:          []string{},
+					TokenValidationHosts:        []string{"api.github.com", "gitlab.com"},
+					CloudCredentialsTokenURLs:   []string{},
+					CloudCredentialsAudiences:   []string{},
+					EventTypeAliases:            triggertype.Aliases{},
+					NamespaceIsolation:          NamespaceIsolationPolicy{},
+					ServiceAccounts:             ServiceAccountAllowlist{},
+					IgnoredSenders:              []string{},
+					CABundles:                   map[string]string{},
+					RegistrySecrets:             []string{},
+					RegistrySecretsNamespaces:   []string{},
+					SecretScanningRules:         secretscan.DefaultRules,
+					ResolutionCacheTTL:          10 * time.Minute,
+					GitOpsAuthorizerTimeout:     5 * time.Second,
+					GitHubAppJWTClockSkew:       time.Minute,
+					GitHubAppTokenRefreshBefore: 5 * time.Minute,
+				},
 			},
 		},
 		{
 			name: "override values",
 			configMap: map[string]string{
-				"application-name":                       "pac-pac",
-				"remote-tasks":                           "false",
-				"max-keep-run-upper-limit":               "10",
-				"default-max-keep-runs":                  "5",
-				"bitbucket-cloud-check-source-ip":        "false",
-				"bitbucket-cloud-additional-source-ip":   "some-ip",
-				"tekton-dashboard-url":                   "https://tekton-dashboard",
-				"auto-configure-new-github-repo":         "true",
-				"auto-configure-repo-namespace-template": "template",
-				"secret-auto-create":                     "false",
-				"secret-github-app-token-scoped":         "false",
-				"secret-github-app-scope-extra-repos":    "extra-repos",
-				"error-log-snippet":                      "false",
-				"error-detection-from-container-logs":    "false",
-				"error-detection-max-number-of-lines":    "100",
-				"error-detection-simple-regexp":          "^(?P<filename>[^:]*):(?P<line>[0-9]+):(?P<column>[0-9]+):([ ]*)?(?P<error>.*)",
-				"custom-console-name":                    "custom-console",
-				"custom-console-url":                     "https://custom-console",
-				"custom-console-url-pr-details":          "https://custom-console-pr-details",
-				"custom-console-url-pr-tasklog":          "https://custom-console-pr-tasklog",
-				"custom-console-url-namespace":           "https://custom-console-namespace",
-				"remember-ok-to-test":                    "false",
+				"application-name":                               "pac-pac",
+				"remote-tasks":                                   "false",
+				"max-keep-run-upper-limit":                       "10",
+				"default-max-keep-runs":                          "5",
+				"bitbucket-cloud-check-source-ip":                "false",
+				"bitbucket-cloud-additional-source-ip":           "some-ip",
+				"tekton-dashboard-url":                           "https://tekton-dashboard",
+				"auto-configure-new-github-repo":                 "true",
+				"auto-configure-repo-namespace-template":         "template",
+				"secret-auto-create":                             "false",
+				"secret-github-app-token-scoped":                 "false",
+				"secret-github-app-scope-extra-repos":            "extra-repos",
+				"secret-auto-create-registry-secrets":            "quay-push, registry-pull",
+				"secret-auto-create-registry-secrets-namespaces": "team-a-*",
+				"error-log-snippet":                              "false",
+				"error-detection-from-container-logs":            "false",
+				"error-detection-max-number-of-lines":            "100",
+				"error-detection-simple-regexp":                  "^(?P<filename>[^:]*):(?P<line>[0-9]+):(?P<column>[0-9]+):([ ]*)?(?P<error>.*)",
+				"custom-console-name":                            "custom-console",
+				"custom-console-url":                             "https://custom-console",
+				"custom-console-url-pr-details":                  "https://custom-console-pr-details",
+				"custom-console-url-pr-tasklog":                  "https://custom-console-pr-tasklog",
+				"custom-console-url-namespace":                   "https://custom-console-namespace",
+				"remember-ok-to-test":                            "false",
+				"gitops-comment-reactions":                       "false",
+				"preview-environments":                           "true",
+				"ignored-senders":                                "dependabot[bot], renovate*",
+				"ignore-bot-prs":                                 "true",
+				"custom-event-types":                             "nightly:incoming",
+				"namespace-isolation-policy":                     "github.com/org:ns",
+				"service-account-allowlist":                      "team-a-*:restricted|deploy",
+				"event-signing-secret":                           "pac-event-signing",
+				"http-proxy":                                     "http://proxy:3128",
+				"https-proxy":                                    "http://proxy:3128",
+				"no-proxy":                                       ".svc,.cluster.local",
+				"ca-bundles":                                     "ghe.example.com=/etc/pac/ca/ghe.crt",
+				"tls-min-version":                                "1.2",
+				"egress-allowed-hosts":                           "github.com, *.github.com",
+				"egress-audit":                                   "true",
+				"token-validation-hosts":                         "ghe.example.com",
+				"cloud-credentials-token-urls":                   "https://sts.googleapis.com/v1/token",
+				"cloud-credentials-audiences":                    "//iam.googleapis.com/pool, sts.amazonaws.com",
+				"event-deduplication-window":                     "5m",
+				"event-payload-ttl":                              "24h",
+				"event-latency-slo":                              "30s",
+				"metrics-aggregation-level":                      "namespace",
+				"metrics-labels-allowlist":                       "team-*",
+				"provider-status-timeout":                        "10s",
+				"provider-files-timeout":                         "20s",
+				"provider-diff-timeout":                          "0s",
+				"provider-read-retries":                          "5",
+				"global-concurrency-limit":                       "20",
+				"maintenance-mode":                               "true",
+				"maintenance-max-buffered-events":                "50",
+				"pipelinerun-env":                                "HTTP_PROXY=http://proxy:3128\nNO_PROXY=.svc,.cluster.local",
+				"cost-cpu-core-hour-price":                       "0.04",
+				"cost-memory-gib-hour-price":                     "0.005",
+				"cost-currency":                                  "EUR",
+				"gitops-authorizer-url":                          "https://opa.example.com/v1/data/pac/allow",
+				"gitops-authorizer-fail-open":                    "true",
+				"gitops-authorizer-timeout":                      "2s",
+				"tekton-results-url":                             "https://tekton-results-api-service.tekton-pipelines.svc.cluster.local:8080",
+				"incoming-webhook-url":                           "https://pac.example.com/incoming",
+				"resolution-cache-ttl":                           "0",
+				"max-pipelinerun-timeout":                        "2h",
+				"secret-scanning":                                "true",
+				"secret-scanning-rules":                          "internal-token=itk_[0-9a-f]{32}",
+				"secret-scanning-notify-url":                     "https://security.example.com/pac",
+				"github-app-jwt-clock-skew":                      "2m",
+				"pipelinerun-naming":                             "deterministic",
 			},
 			expectedStruct: Settings{
 				ApplicationName:                    "pac-pac",
@@ -86,6 +175,8 @@ func TestSyncConfig(t *testing.T) {
 				SecretAutoCreation:                 false,
 				SecretGHAppRepoScoped:              false,
 				SecretGhAppTokenScopedExtraRepos:   "extra-repos",
+				SecretAutoCreateRegistrySecrets:    "quay-push, registry-pull",
+				RegistrySecretsNamespaces:          "team-a-*",
 				ErrorLogSnippet:                    false,
 				ErrorDetection:                     false,
 				ErrorDetectionNumberOfLines:        100,
@@ -96,6 +187,86 @@ func TestSyncConfig(t *testing.T) {
 				CustomConsolePRTaskLog:             "https://custom-console-pr-tasklog",
 				CustomConsoleNamespaceURL:          "https://custom-console-namespace",
 				RememberOKToTest:                   false,
+				GitOpsCommentReactions:             false,
+				PreviewEnvironments:                true,
+				IgnoredSenders:                     "dependabot[bot], renovate*",
+				IgnoreBotPRs:                       true,
+				CustomEventTypes:                   "nightly:incoming",
+				NamespaceIsolationPolicy:           "github.com/org:ns",
+				ServiceAccountAllowlist:            "team-a-*:restricted|deploy",
+				EventSigningSecret:                 "pac-event-signing",
+				HTTPProxy:                          "http://proxy:3128",
+				HTTPSProxy:                         "http://proxy:3128",
+				NoProxy:                            ".svc,.cluster.local",
+				CABundles:                          "ghe.example.com=/etc/pac/ca/ghe.crt",
+				TLSMinVersion:                      "1.2",
+				EgressAllowedHosts:                 "github.com, *.github.com",
+				EgressAudit:                        true,
+				TokenValidationHosts:               "ghe.example.com",
+				CloudCredentialsTokenURLs:          "https://sts.googleapis.com/v1/token",
+				CloudCredentialsAudiences:          "//iam.googleapis.com/pool, sts.amazonaws.com",
+				EventDeduplicationWindow:           "5m",
+				EventPayloadTTL:                    "24h",
+				EventLatencySLO:                    "30s",
+				MetricsAggregationLevel:            "namespace",
+				MetricsLabelsAllowlist:             "team-*",
+				ProviderStatusTimeout:              "10s",
+				ProviderFilesTimeout:               "20s",
+				ProviderDiffTimeout:                "0s",
+				ProviderReadRetries:                5,
+				GlobalConcurrencyLimit:             20,
+				MaintenanceMode:                    true,
+				MaintenanceMaxBufferedEvents:       50,
+				PipelineRunEnv:                     "HTTP_PROXY=http://proxy:3128\nNO_PROXY=.svc,.cluster.local",
+				CostCPUCoreHourPrice:               "0.04",
+				CostMemoryGiBHourPrice:             "0.005",
+				CostCurrency:                       "EUR",
+				GitOpsAuthorizerURL:                "https://opa.example.com/v1/data/pac/allow",
+				GitOpsAuthorizerFailOpen:           true,
+				GitOpsAuthorizerTimeout:            "2s",
+				TektonResultsURL:                   "https://tekton-results-api-service.tekton-pipelines.svc.cluster.local:8080",
+				IncomingWebhookURL:                 "https://pac.example.com/incoming",
+				ResolutionCacheTTL:                 "0",
+				MaxPipelineRunTimeout:              "2h",
+				SecretScanning:                     true,
+				SecretScanningRules:                "internal-token=itk_[0-9a-f]{32}",
+				SecretScanningNotifyURL:            "https://security.example.com/pac",
+				GitHubAppJWTClockSkew:              "2m",
+				GitHubAppTokenRefreshBefore:        "5m",
+				PipelineRunNaming:                  "deterministic",
+				Parsed: Parsed{
+					ProviderStatusTimeout: 10 * time.Second,
+					ProviderFilesTimeout:  20 * time.Second,
+					PipelineRunEnv: []corev1.EnvVar{
+						{Name: "HTTP_PROXY", Value: "http://proxy:3128"},
+						{Name: "NO_PROXY", Value: ".svc,.cluster.local"},
+					},
+					EgressAllowedHosts:        []string{"github.com", "*.github.com"},
+					TokenValidationHosts:      []string{"ghe.example.com"},
+					CloudCredentialsTokenURLs: []string{"https://sts.googleapis.com/v1/token"},
+					CloudCredentialsAudiences: []string{"//iam.googleapis.com/pool", "sts.amazonaws.com"},
+					CostCPUCoreHourPrice:      0.04,
+					CostMemoryGiBHourPrice:    0.005,
+					EventTypeAliases:          triggertype.Aliases{"nightly": "incoming"},
+					NamespaceIsolation:        NamespaceIsolationPolicy{"github.com/org": {"ns"}},
+					ServiceAccounts:           ServiceAccountAllowlist{"team-a-*": {"restricted", "deploy"}},
+					IgnoredSenders:            []string{"dependabot[bot]", "renovate*"},
+					CABundles:                 map[string]string{"ghe.example.com": "/etc/pac/ca/ghe.crt"},
+					TLSMinVersion:             tls.VersionTLS12,
+					RegistrySecrets:           []string{"quay-push", "registry-pull"},
+					RegistrySecretsNamespaces: []string{"team-a-*"},
+					SecretScanningRules: append(append([]secretscan.Rule{}, secretscan.DefaultRules...), secretscan.Rule{
+						Name:   "internal-token",
+						Regexp: regexp.MustCompile("itk_[0-9a-f]{32}"),
+					}),
+					EventDeduplicationWindow:    5 * time.Minute,
+					EventPayloadTTL:             24 * time.Hour,
+					EventLatencySLO:             30 * time.Second,
+					MaxPipelineRunTimeout:       2 * time.Hour,
+					GitOpsAuthorizerTimeout:     2 * time.Second,
+					GitHubAppJWTClockSkew:       2 * time.Minute,
+					GitHubAppTokenRefreshBefore: 5 * time.Minute,
+				},
 			},
 		},
 		{
@@ -119,6 +290,13 @@ func TestSyncConfig(t *testing.T) {
 			},
 			expectedError: "custom validation failed for field ErrorDetectionSimpleRegexp: invalid regex: error parsing regexp: missing closing ]: `[`",
 		},
+		{
+			name: "invalid secret scanning rule",
+			configMap: map[string]string{
+				"secret-scanning-rules": "internal-token",
+			},
+			expectedError: "custom validation failed for field SecretScanningRules: invalid secret scanning rule \"internal-token\", needs to be of format name=regexp",
+		},
 		{
 			name: "invalid value url",
 			configMap: map[string]string{
@@ -133,6 +311,118 @@ func TestSyncConfig(t *testing.T) {
 			},
 			expectedError: "custom validation failed for field CustomConsolePRTaskLog: invalid value, must start with http:// or https://",
 		},
+		{
+			name: "invalid custom event types",
+			configMap: map[string]string{
+				"custom-event-types": "nightly:unknown",
+			},
+			expectedError: "custom validation failed for field CustomEventTypes: custom event type \"nightly\" targets an unknown event type \"unknown\"",
+		},
+		{
+			name: "invalid namespace isolation policy",
+			configMap: map[string]string{
+				"namespace-isolation-policy": "github.com/org",
+			},
+			expectedError: "custom validation failed for field NamespaceIsolationPolicy: invalid namespace isolation policy \"github.com/org\", needs to be of format org:namespace|namespace",
+		},
+		{
+			name: "invalid https proxy",
+			configMap: map[string]string{
+				"https-proxy": "proxy:3128",
+			},
+			expectedError: "custom validation failed for field HTTPSProxy: invalid proxy url \"proxy:3128\", needs to start with http://, https:// or socks5://",
+		},
+		{
+			name: "invalid tls min version",
+			configMap: map[string]string{
+				"tls-min-version": "1.1",
+			},
+			expectedError: "custom validation failed for field TLSMinVersion: invalid tls min version \"1.1\", needs to be 1.2 or 1.3",
+		},
+		{
+			name: "invalid event deduplication window",
+			configMap: map[string]string{
+				"event-deduplication-window": "-5m",
+			},
+			expectedError: "custom validation failed for field EventDeduplicationWindow: invalid duration -5m, cannot be negative",
+		},
+		{
+			name: "github app jwt clock skew too large",
+			configMap: map[string]string{
+				"github-app-jwt-clock-skew": "10m",
+			},
+			expectedError: "custom validation failed for field GitHubAppJWTClockSkew: invalid clock skew 10m, cannot be more than 5m0s",
+		},
+		{
+			name: "invalid pipelinerun naming",
+			configMap: map[string]string{
+				"pipelinerun-naming": "random",
+			},
+			expectedError: "custom validation failed for field PipelineRunNaming: invalid pipelinerun naming \"random\", must be generate or deterministic",
+		},
+		{
+			name: "invalid egress allowed hosts",
+			configMap: map[string]string{
+				"egress-allowed-hosts": "https://github.com",
+			},
+			expectedError: "custom validation failed for field EgressAllowedHosts: invalid egress allowed host \"https://github.com\", needs to be a hostname without a scheme",
+		},
+		{
+			name: "invalid token validation hosts",
+			configMap: map[string]string{
+				"token-validation-hosts": "https://ghe.example.com",
+			},
+			expectedError: "custom validation failed for field TokenValidationHosts: invalid token validation host \"https://ghe.example.com\", needs to be a hostname without a scheme",
+		},
+		{
+			name: "invalid cloud credentials token urls",
+			configMap: map[string]string{
+				"cloud-credentials-token-urls": "http://sts.example.com/token",
+			},
+			expectedError: "custom validation failed for field CloudCredentialsTokenURLs: invalid cloud credentials token url \"http://sts.example.com/token\", needs to be an https url",
+		},
+		{
+			name: "cloud credentials audience of the api server",
+			configMap: map[string]string{
+				"cloud-credentials-audiences": "https://kubernetes.default.svc",
+			},
+			expectedError: "custom validation failed for field CloudCredentialsAudiences: invalid cloud credentials audience \"https://kubernetes.default.svc\", it is an audience of the Kubernetes API server",
+		},
+		{
+			name: "invalid provider read retries",
+			configMap: map[string]string{
+				"provider-read-retries": "20",
+			},
+			expectedError: "custom validation failed for field ProviderReadRetries: invalid number of retries 20, must be between 0 and 10",
+		},
+		{
+			name: "invalid maintenance max buffered events",
+			configMap: map[string]string{
+				"maintenance-max-buffered-events": "0",
+			},
+			expectedError: "custom validation failed for field MaintenanceMaxBufferedEvents: invalid number of buffered events 0, must be at least 1",
+		},
+		{
+			name: "invalid pipelinerun env",
+			configMap: map[string]string{
+				"pipelinerun-env": "HTTP_PROXY",
+			},
+			expectedError: "custom validation failed for field PipelineRunEnv: invalid pipelinerun env \"HTTP_PROXY\", needs to be of format NAME=value",
+		},
+		{
+			name: "invalid cost price",
+			configMap: map[string]string{
+				"cost-cpu-core-hour-price": "-1",
+			},
+			expectedError: "custom validation failed for field CostCPUCoreHourPrice: invalid price -1, cannot be negative",
+		},
+		{
+			name: "invalid gitops authorizer url",
+			configMap: map[string]string{
+				"gitops-authorizer-url": "opa.example.com",
+			},
+			expectedError: "custom validation failed for field GitOpsAuthorizerURL: invalid value, must start with http:// or https://",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -3,6 +3,7 @@ package settings
 import (
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"sync"
 
 	"go.uber.org/zap"
@@ -20,10 +21,18 @@ func getHubCatalogs(logger *zap.SugaredLogger, catalogs *sync.Map, config map[st
 	if hubCatalogName, ok := config[HubCatalogNameKey]; !ok || hubCatalogName == "" {
 		config[HubCatalogNameKey] = HubCatalogNameDefaultValue
 	}
+	if hubCatalogType, ok := config[HubCatalogTypeKey]; !ok || hubCatalogType == "" {
+		config[HubCatalogTypeKey] = HubCatalogTypeHub
+	} else if err := isValidHubCatalogLocation(hubCatalogType, config[HubURLKey]); err != nil {
+		logger.Warnf("CONFIG: %s for the default hub catalog, using the default hub url %s", err.Error(), HubURLDefaultValue)
+		config[HubCatalogTypeKey] = HubCatalogTypeHub
+		config[HubURLKey] = HubURLDefaultValue
+	}
 	catalogs.Store("default", HubCatalog{
 		ID:   "default",
 		Name: config[HubCatalogNameKey],
 		URL:  config[HubURLKey],
+		Type: config[HubCatalogTypeKey],
 	})
 
 	for k := range config {
@@ -52,16 +61,19 @@ func getHubCatalogs(logger *zap.SugaredLogger, catalogs *sync.Map, config map[st
 					break
 				}
 				catalogURL := config[fmt.Sprintf("%s-url", cPrefix)]
-				u, err := url.Parse(catalogURL)
-				if err != nil || u.Scheme == "" || u.Host == "" {
-					logger.Warnf("CONFIG: custom hub %s, catalog url %s is not valid, skipping catalog configuration", catalogID, catalogURL)
+				catalogType := config[fmt.Sprintf("%s-type", cPrefix)]
+				if catalogType == "" {
+					catalogType = HubCatalogTypeHub
+				}
+				if err := isValidHubCatalogLocation(catalogType, catalogURL); err != nil {
+					logger.Warnf("CONFIG: custom hub %s, %s, skipping catalog configuration", catalogID, err.Error())
 					break
 				}
 				catalogName := config[fmt.Sprintf("%s-name", cPrefix)]
 				value, ok := catalogs.Load(catalogID)
 				if ok {
 					catalogValues, ok := value.(HubCatalog)
-					if ok && (catalogValues.Name == catalogName) && (catalogValues.URL == catalogURL) {
+					if ok && (catalogValues.Name == catalogName) && (catalogValues.URL == catalogURL) && (catalogValues.Type == catalogType) {
 						break
 					}
 				}
@@ -70,9 +82,29 @@ func getHubCatalogs(logger *zap.SugaredLogger, catalogs *sync.Map, config map[st
 					ID:   catalogID,
 					Name: catalogName,
 					URL:  catalogURL,
+					Type: catalogType,
 				})
 			}
 		}
 	}
 	return catalogs
 }
+
+// isValidHubCatalogLocation checks the url of a catalog is the url of a hub
+// API, or an absolute directory for a local catalog.
+func isValidHubCatalogLocation(catalogType, catalogURL string) error {
+	switch catalogType {
+	case HubCatalogTypeHub:
+		u, err := url.Parse(catalogURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("catalog url %s is not valid", catalogURL)
+		}
+	case HubCatalogTypeLocal:
+		if !filepath.IsAbs(catalogURL) {
+			return fmt.Errorf("catalog url %s is not valid, a local catalog needs an absolute directory", catalogURL)
+		}
+	default:
+		return fmt.Errorf("catalog type %s is not valid, needs to be %s or %s", catalogType, HubCatalogTypeHub, HubCatalogTypeLocal)
+	}
+	return nil
+}
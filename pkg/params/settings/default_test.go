@@ -104,6 +104,51 @@ func TestGetCatalogHub(t *testing.T) {
 			hubCatalogs: &sync.Map{},
 			wantLog:     "catalog url /u1!@1!@#$afoo.com is not valid, skipping catalog configuration",
 		},
+		{
+			name: "good/local custom catalog",
+			config: map[string]string{
+				"catalog-1-id":   "airgap",
+				"catalog-1-url":  "/etc/pac/catalogs/tekton",
+				"catalog-1-name": "tekton",
+				"catalog-1-type": "local",
+			},
+			numCatalogs: 2,
+			hubCatalogs: &sync.Map{},
+			wantLog:     "CONFIG: setting custom hub airgap, catalog /etc/pac/catalogs/tekton",
+		},
+		{
+			name: "bad/local custom catalog with a relative directory",
+			config: map[string]string{
+				"catalog-1-id":   "airgap",
+				"catalog-1-url":  "catalogs/tekton",
+				"catalog-1-name": "tekton",
+				"catalog-1-type": "local",
+			},
+			numCatalogs: 1,
+			hubCatalogs: &sync.Map{},
+			wantLog:     "catalog url catalogs/tekton is not valid, a local catalog needs an absolute directory",
+		},
+		{
+			name: "bad/unknown catalog type",
+			config: map[string]string{
+				"catalog-1-id":   "custom",
+				"catalog-1-url":  "https://foo.com",
+				"catalog-1-name": "tekton",
+				"catalog-1-type": "oci",
+			},
+			numCatalogs: 1,
+			hubCatalogs: &sync.Map{},
+			wantLog:     "catalog type oci is not valid, needs to be hub or local",
+		},
+		{
+			name: "bad/local default catalog without a directory",
+			config: map[string]string{
+				"hub-catalog-type": "local",
+			},
+			numCatalogs: 1,
+			hubCatalogs: &sync.Map{},
+			wantLog:     "CONFIG: catalog url https://api.hub.tekton.dev/v1 is not valid, a local catalog needs an absolute directory for the default hub catalog",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,42 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ParsePipelineRunEnv parses the NAME=value entries, one per line, of
+// pipelinerun-env. The values are kept as is, with their commas, to allow
+// lists like NO_PROXY.
+func ParsePipelineRunEnv(s string) ([]corev1.EnvVar, error) {
+	env := []corev1.EnvVar{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid pipelinerun env %q, needs to be of format NAME=value", line)
+		}
+		if errs := validation.IsEnvVarName(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid pipelinerun env name %q: %s", name, strings.Join(errs, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("pipelinerun env %q is set more than once", name)
+		}
+		seen[name] = true
+		env = append(env, corev1.EnvVar{Name: name, Value: strings.TrimSpace(value)})
+	}
+	return env, nil
+}
+
+func isValidPipelineRunEnv(value string) error {
+	_, err := ParsePipelineRunEnv(value)
+	return err
+}
@@ -0,0 +1,24 @@
+package settings
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParsePipelineRunEnv(t *testing.T) {
+	env, err := ParsePipelineRunEnv("HTTPS_PROXY=http://proxy:3128\n\n# the cluster services\n NO_PROXY = .svc,.cluster.local\nEMPTY=")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, env, []corev1.EnvVar{
+		{Name: "HTTPS_PROXY", Value: "http://proxy:3128"},
+		{Name: "NO_PROXY", Value: ".svc,.cluster.local"},
+		{Name: "EMPTY", Value: ""},
+	})
+
+	_, err = ParsePipelineRunEnv("1PROXY=foo")
+	assert.ErrorContains(t, err, "invalid pipelinerun env name \"1PROXY\"")
+
+	_, err = ParsePipelineRunEnv("A=1\nA=2")
+	assert.ErrorContains(t, err, "pipelinerun env \"A\" is set more than once")
+}
@@ -0,0 +1,171 @@
+package settings
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ParseCABundles parses a comma separated list of host=path, for example
+// "ghe.example.com=/etc/pac/ca/ghe.crt, gitlab.example.com:8443=/etc/pac/ca/gitlab.crt".
+// The path is a PEM bundle, usually a key of a secret mounted in the
+// controller, trusted in addition of the system certificates for the host.
+func ParseCABundles(s string) (map[string]string, error) {
+	bundles := map[string]string{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		host, path, ok := strings.Cut(item, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		path = strings.TrimSpace(path)
+		if !ok || host == "" || path == "" {
+			return nil, fmt.Errorf("invalid ca bundle %q, needs to be of format host=path", item)
+		}
+		if strings.Contains(host, "/") {
+			return nil, fmt.Errorf("invalid ca bundle %q, the host needs to be a hostname without a scheme", item)
+		}
+		if !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("invalid ca bundle %q, the path needs to be absolute", item)
+		}
+		bundles[host] = path
+	}
+	return bundles, nil
+}
+
+func isValidCABundles(value string) error {
+	_, err := ParseCABundles(value)
+	return err
+}
+
+// ParseTLSMinVersion parses the minimal TLS version, 1.2 or 1.3, an empty
+// value returns 0 to use the Go default.
+func ParseTLSMinVersion(s string) (uint16, error) {
+	switch strings.TrimSpace(s) {
+	case "":
+		return 0, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("invalid tls min version %q, needs to be 1.2 or 1.3", s)
+}
+
+func isValidTLSMinVersion(value string) error {
+	_, err := ParseTLSMinVersion(value)
+	return err
+}
+
+func isValidProxyURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+		return fmt.Errorf("invalid proxy url %q, needs to start with http://, https:// or socks5://", value)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid proxy url %q, has no host", value)
+	}
+	return nil
+}
+
+// CABundlesByHost returns the custom CA bundles declared by the admin by
+// host.
+func (s *Settings) CABundlesByHost() map[string]string {
+	return s.Parsed.CABundles
+}
+
+// TLSMinVersionValue returns the minimal TLS version declared by the admin,
+// 0 when not set.
+func (s *Settings) TLSMinVersionValue() uint16 {
+	return s.Parsed.TLSMinVersion
+}
+
+// ParseEgressAllowedHosts parses a comma separated list of host globs, for
+// example "github.com, *.github.com, gitlab.example.com:8443".
+func ParseEgressAllowedHosts(s string) ([]string, error) {
+	return parseHostGlobs(s, "egress allowed host")
+}
+
+// parseHostGlobs parses a comma separated list of host globs with an optional
+// port, what names the setting in the errors.
+func parseHostGlobs(s, what string) ([]string, error) {
+	hosts := []string{}
+	for _, host := range strings.Split(s, ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		if strings.Contains(host, "/") {
+			return nil, fmt.Errorf("invalid %s %q, needs to be a hostname without a scheme", what, host)
+		}
+		if _, err := path.Match(host, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s glob %q: %w", what, host, err)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// matchHostGlobs returns true if the host, with an optional port, matches one
+// of the globs with or without its port.
+func matchHostGlobs(patterns []string, host string) bool {
+	host = strings.ToLower(host)
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, hostname); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidEgressAllowedHosts(value string) error {
+	_, err := ParseEgressAllowedHosts(value)
+	return err
+}
+
+// EgressAllowed returns true if the outbound calls to the host, with an
+// optional port, are allowed. All the hosts are allowed when the admin has not
+// set an allowlist, a host matches an entry with or without its port.
+func (s *Settings) EgressAllowed(host string) bool {
+	if len(s.Parsed.EgressAllowedHosts) == 0 {
+		return true
+	}
+	return matchHostGlobs(s.Parsed.EgressAllowedHosts, host)
+}
+
+// ParseTokenValidationHosts parses the comma separated list of the host globs
+// of the provider APIs the admission webhook may send the git_provider tokens
+// to, for example "api.github.com, ghe.example.com".
+func ParseTokenValidationHosts(s string) ([]string, error) {
+	return parseHostGlobs(s, "token validation host")
+}
+
+func isValidTokenValidationHosts(value string) error {
+	_, err := ParseTokenValidationHosts(value)
+	return err
+}
+
+// TokenValidationAllowed returns true if the admission webhook may check a
+// git_provider token against the provider API on the host, with an optional
+// port. Unlike the egress allowlist no host is allowed when the list is empty.
+func (s *Settings) TokenValidationAllowed(host string) bool {
+	return matchHostGlobs(s.Parsed.TokenValidationHosts, host)
+}
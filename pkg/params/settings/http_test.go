@@ -0,0 +1,116 @@
+package settings
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseCABundles(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  map[string]string{},
+		},
+		{
+			name:  "multiple hosts",
+			value: "GHE.example.com=/etc/pac/ca/ghe.crt, gitlab.example.com:8443 = /etc/pac/ca/gitlab.crt",
+			want: map[string]string{
+				"ghe.example.com":         "/etc/pac/ca/ghe.crt",
+				"gitlab.example.com:8443": "/etc/pac/ca/gitlab.crt",
+			},
+		},
+		{
+			name:    "no path",
+			value:   "ghe.example.com",
+			wantErr: "invalid ca bundle \"ghe.example.com\", needs to be of format host=path",
+		},
+		{
+			name:    "url instead of a host",
+			value:   "https://ghe.example.com=/etc/pac/ca/ghe.crt",
+			wantErr: "invalid ca bundle \"https://ghe.example.com=/etc/pac/ca/ghe.crt\", the host needs to be a hostname without a scheme",
+		},
+		{
+			name:    "relative path",
+			value:   "ghe.example.com=ca/ghe.crt",
+			wantErr: "invalid ca bundle \"ghe.example.com=ca/ghe.crt\", the path needs to be absolute",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCABundles(tt.value)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestTLSMinVersionValue(t *testing.T) {
+	for value, want := range map[string]uint16{"": 0, "1.2": tls.VersionTLS12, "1.3": tls.VersionTLS13} {
+		s := &Settings{TLSMinVersion: value}
+		s.parse()
+		assert.Equal(t, s.TLSMinVersionValue(), want)
+	}
+}
+
+func TestEgressAllowed(t *testing.T) {
+	s := DefaultSettings()
+	assert.Assert(t, s.EgressAllowed("anything.example.com"))
+
+	assert.NilError(t, SyncConfig(zap.NewNop().Sugar(), &s, map[string]string{
+		"egress-allowed-hosts": "github.com, *.github.com, gitlab.example.com:8443",
+	}))
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "github.com", want: true},
+		{host: "api.github.com", want: true},
+		{host: "API.GitHub.com:443", want: true},
+		{host: "gitlab.example.com:8443", want: true},
+		{host: "gitlab.example.com", want: false},
+		{host: "gitlab.example.com:443", want: false},
+		{host: "notgithub.com", want: false},
+		{host: "hub.tekton.dev", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, s.EgressAllowed(tt.host), tt.want, tt.host)
+	}
+
+	_, err := ParseEgressAllowedHosts("[github.com")
+	assert.ErrorContains(t, err, "invalid egress allowed host glob \"[github.com\"")
+}
+
+func TestTokenValidationAllowed(t *testing.T) {
+	s := &Settings{}
+	assert.Assert(t, !s.TokenValidationAllowed("api.github.com"))
+
+	assert.NilError(t, SyncConfig(zap.NewNop().Sugar(), s, map[string]string{
+		"token-validation-hosts": "api.github.com, *.example.com",
+	}))
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "api.github.com", want: true},
+		{host: "api.github.com:443", want: true},
+		{host: "ghe.example.com", want: true},
+		{host: "169.254.169.254", want: false},
+		{host: "gitlab.com", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, s.TokenValidationAllowed(tt.host), tt.want, tt.host)
+	}
+}
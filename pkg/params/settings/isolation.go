@@ -0,0 +1,91 @@
+package settings
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// NamespaceIsolationPolicy maps a git organization or group (ie:
+// github.com/org or gitlab.com/group/subgroup) to the namespaces allowed to
+// have a Repository claiming its repositories.
+type NamespaceIsolationPolicy map[string][]string
+
+// ParseNamespaceIsolationPolicy parses a comma separated list of
+// org:namespace|namespace, for example
+// "github.com/team-a:team-a-*, gitlab.com/group:ci|shared". Namespaces can be
+// globs.
+func ParseNamespaceIsolationPolicy(s string) (NamespaceIsolationPolicy, error) {
+	policy := NamespaceIsolationPolicy{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		// split on the last colon, the org may have a host with a port
+		i := strings.LastIndex(item, ":")
+		if i == -1 {
+			return nil, fmt.Errorf("invalid namespace isolation policy %q, needs to be of format org:namespace|namespace", item)
+		}
+		org := strings.Trim(strings.ToLower(strings.TrimSpace(item[:i])), "/")
+		if org == "" {
+			return nil, fmt.Errorf("invalid namespace isolation policy %q, needs to be of format org:namespace|namespace", item)
+		}
+		for _, ns := range strings.Split(item[i+1:], "|") {
+			ns = strings.TrimSpace(ns)
+			if ns == "" {
+				continue
+			}
+			if _, err := path.Match(ns, ""); err != nil {
+				return nil, fmt.Errorf("invalid namespace glob %q in namespace isolation policy: %w", ns, err)
+			}
+			policy[org] = append(policy[org], ns)
+		}
+		if len(policy[org]) == 0 {
+			return nil, fmt.Errorf("namespace isolation policy %q has no namespace", item)
+		}
+	}
+	return policy, nil
+}
+
+// Allowed returns true if a Repository in the namespace may claim the
+// repository url. The most specific org of the policy matching the url is
+// used, a url not matching any org is not restricted.
+func (p NamespaceIsolationPolicy) Allowed(repoURL, namespace string) bool {
+	if len(p) == 0 {
+		return true
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return false
+	}
+	key := strings.ToLower(u.Host + "/" + strings.Trim(u.Path, "/"))
+
+	matched := ""
+	for org := range p {
+		if (key == org || strings.HasPrefix(key, org+"/")) && len(org) > len(matched) {
+			matched = org
+		}
+	}
+	if matched == "" {
+		return true
+	}
+	for _, ns := range p[matched] {
+		if ok, _ := path.Match(ns, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidNamespaceIsolationPolicy(value string) error {
+	_, err := ParseNamespaceIsolationPolicy(value)
+	return err
+}
+
+// NamespaceIsolation returns the namespace isolation policy declared by the
+// admin.
+func (s *Settings) NamespaceIsolation() NamespaceIsolationPolicy {
+	return s.Parsed.NamespaceIsolation
+}
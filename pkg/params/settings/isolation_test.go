@@ -0,0 +1,83 @@
+package settings
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseNamespaceIsolationPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    NamespaceIsolationPolicy
+		wantErr string
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  NamespaceIsolationPolicy{},
+		},
+		{
+			name:  "multiple orgs and namespaces",
+			value: "github.com/Team-A:team-a-*, gitlab.example.com:8443/group/:ci | shared",
+			want: NamespaceIsolationPolicy{
+				"github.com/team-a":             {"team-a-*"},
+				"gitlab.example.com:8443/group": {"ci", "shared"},
+			},
+		},
+		{
+			name:    "no namespace",
+			value:   "github.com/org:",
+			wantErr: "namespace isolation policy \"github.com/org:\" has no namespace",
+		},
+		{
+			name:    "no org",
+			value:   ":ns",
+			wantErr: "invalid namespace isolation policy \":ns\", needs to be of format org:namespace|namespace",
+		},
+		{
+			name:    "invalid glob",
+			value:   "github.com/org:ns[",
+			wantErr: "invalid namespace glob \"ns[\" in namespace isolation policy: syntax error in pattern",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNamespaceIsolationPolicy(tt.value)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestNamespaceIsolationPolicyAllowed(t *testing.T) {
+	policy, err := ParseNamespaceIsolationPolicy("github.com/team-a:team-a-*, github.com/team-a/shared:shared, gitlab.com/group:ci")
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name      string
+		url       string
+		namespace string
+		want      bool
+	}{
+		{name: "namespace glob", url: "https://github.com/team-a/app", namespace: "team-a-ci", want: true},
+		{name: "namespace not allowed", url: "https://github.com/team-a/app", namespace: "team-b", want: false},
+		{name: "most specific org", url: "https://github.com/team-a/shared", namespace: "shared", want: true},
+		{name: "most specific org only", url: "https://github.com/team-a/shared", namespace: "team-a-ci", want: false},
+		{name: "org prefix is not a path prefix", url: "https://github.com/team-ab/app", namespace: "team-b", want: true},
+		{name: "subgroup", url: "https://gitlab.com/group/sub/project", namespace: "ci", want: true},
+		{name: "case insensitive host", url: "https://GitHub.com/team-a/app", namespace: "other", want: false},
+		{name: "unrestricted org", url: "https://github.com/other/app", namespace: "other", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, policy.Allowed(tt.url, tt.namespace), tt.want)
+		})
+	}
+	assert.Assert(t, NamespaceIsolationPolicy{}.Allowed("https://github.com/team-a/app", "team-b"))
+}
@@ -0,0 +1,65 @@
+package settings
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ParseRegistrySecrets parses the comma separated list of the registry
+// secrets names of secret-auto-create-registry-secrets.
+func ParseRegistrySecrets(s string) ([]string, error) {
+	names := []string{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid registry secret name %q: %s", name, strings.Join(errs, ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func isValidRegistrySecrets(value string) error {
+	_, err := ParseRegistrySecrets(value)
+	return err
+}
+
+// ParseRegistrySecretsNamespaces parses the comma separated list of the
+// namespace globs of secret-auto-create-registry-secrets-namespaces.
+func ParseRegistrySecretsNamespaces(s string) ([]string, error) {
+	globs := []string{}
+	for _, glob := range strings.Split(s, ",") {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
+			continue
+		}
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace glob %q of the registry secrets: %w", glob, err)
+		}
+		globs = append(globs, glob)
+	}
+	return globs, nil
+}
+
+func isValidRegistrySecretsNamespaces(value string) error {
+	_, err := ParseRegistrySecretsNamespaces(value)
+	return err
+}
+
+// RegistrySecrets returns the registry secrets cloned in the namespace, none
+// when the namespace doesn't match any glob of
+// secret-auto-create-registry-secrets-namespaces.
+func (s *Settings) RegistrySecrets(namespace string) []string {
+	for _, glob := range s.Parsed.RegistrySecretsNamespaces {
+		if ok, _ := path.Match(glob, namespace); ok {
+			return s.Parsed.RegistrySecrets
+		}
+	}
+	return nil
+}
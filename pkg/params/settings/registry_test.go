@@ -0,0 +1,32 @@
+package settings
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseRegistrySecrets(t *testing.T) {
+	names, err := ParseRegistrySecrets(" quay-push, registry-pull ,,")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names, []string{"quay-push", "registry-pull"})
+
+	_, err = ParseRegistrySecrets("quay-push, Bad_Name")
+	assert.ErrorContains(t, err, "invalid registry secret name \"Bad_Name\"")
+}
+
+func TestRegistrySecrets(t *testing.T) {
+	_, err := ParseRegistrySecretsNamespaces("team-a-*, ns[")
+	assert.ErrorContains(t, err, "invalid namespace glob \"ns[\" of the registry secrets")
+
+	s := &Settings{SecretAutoCreateRegistrySecrets: "quay-push", RegistrySecretsNamespaces: "team-a-*, ci"}
+	s.parse()
+	assert.DeepEqual(t, s.RegistrySecrets("team-a-dev"), []string{"quay-push"})
+	assert.DeepEqual(t, s.RegistrySecrets("ci"), []string{"quay-push"})
+	assert.Assert(t, s.RegistrySecrets("team-b") == nil)
+
+	// no namespace gets them without the allowlist
+	s = &Settings{SecretAutoCreateRegistrySecrets: "quay-push"}
+	s.parse()
+	assert.Assert(t, s.RegistrySecrets("team-a-dev") == nil)
+}
@@ -0,0 +1,50 @@
+package settings
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ParseIgnoredSenders parses a comma separated list of sender globs, for
+// example "dependabot[bot], renovate*".
+func ParseIgnoredSenders(s string) ([]string, error) {
+	senders := []string{}
+	for _, sender := range strings.Split(s, ",") {
+		sender = strings.ToLower(strings.TrimSpace(sender))
+		if sender == "" {
+			continue
+		}
+		if _, err := path.Match(sender, ""); err != nil {
+			return nil, fmt.Errorf("invalid ignored sender glob %q: %w", sender, err)
+		}
+		senders = append(senders, sender)
+	}
+	return senders, nil
+}
+
+func isValidIgnoredSenders(value string) error {
+	_, err := ParseIgnoredSenders(value)
+	return err
+}
+
+// IsIgnoredSender returns true if the automatic runs of the sender are
+// ignored, because it matches one of the ignored-senders globs or it is a bot
+// opening or updating a pull request and ignore-bot-prs is set. A sender is a
+// bot when the provider flags it as such or its login ends with [bot].
+func (s *Settings) IsIgnoredSender(sender string, bot, pullRequest bool) bool {
+	sender = strings.ToLower(sender)
+	if s.IgnoreBotPRs && pullRequest && (bot || strings.HasSuffix(sender, "[bot]")) {
+		return true
+	}
+	for _, pattern := range s.Parsed.IgnoredSenders {
+		// the brackets of the bot logins are literal, not a glob class
+		if pattern == sender {
+			return true
+		}
+		if ok, _ := path.Match(pattern, sender); ok {
+			return true
+		}
+	}
+	return false
+}
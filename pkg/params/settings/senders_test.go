@@ -0,0 +1,88 @@
+package settings
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseIgnoredSenders(t *testing.T) {
+	senders, err := ParseIgnoredSenders(" Dependabot[bot], renovate* ,,")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, senders, []string{"dependabot[bot]", "renovate*"})
+
+	_, err = ParseIgnoredSenders("bad[")
+	assert.ErrorContains(t, err, "invalid ignored sender glob \"bad[\"")
+}
+
+func TestIsIgnoredSender(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+		sender   string
+		bot      bool
+		push     bool
+		want     bool
+	}{
+		{
+			name:   "nothing ignored",
+			sender: "dependabot[bot]",
+			bot:    true,
+		},
+		{
+			name:     "exact sender with brackets",
+			settings: Settings{IgnoredSenders: "dependabot[bot]"},
+			sender:   "Dependabot[bot]",
+			want:     true,
+		},
+		{
+			name:     "sender glob",
+			settings: Settings{IgnoredSenders: "user, renovate*"},
+			sender:   "renovate-bot",
+			want:     true,
+		},
+		{
+			name:     "sender not matching",
+			settings: Settings{IgnoredSenders: "renovate*"},
+			sender:   "someone",
+		},
+		{
+			name:     "bot suffix",
+			settings: Settings{IgnoreBotPRs: true},
+			sender:   "renovate[bot]",
+			want:     true,
+		},
+		{
+			name:     "bot flagged by the provider",
+			settings: Settings{IgnoreBotPRs: true},
+			sender:   "ci-robot",
+			bot:      true,
+			want:     true,
+		},
+		{
+			name:     "not a bot",
+			settings: Settings{IgnoreBotPRs: true},
+			sender:   "someone",
+		},
+		{
+			name:     "push of a bot",
+			settings: Settings{IgnoreBotPRs: true},
+			sender:   "github-merge-queue[bot]",
+			bot:      true,
+			push:     true,
+		},
+		{
+			name:     "push of an ignored sender",
+			settings: Settings{IgnoredSenders: "renovate*"},
+			sender:   "renovate[bot]",
+			push:     true,
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.settings.parse()
+			assert.Equal(t, tt.settings.IsIgnoredSender(tt.sender, tt.bot, !tt.push), tt.want)
+		})
+	}
+}
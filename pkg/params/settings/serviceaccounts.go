@@ -0,0 +1,78 @@
+package settings
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ServiceAccountAllowlist maps a namespace glob to the service accounts the
+// PipelineRuns of the namespaces matching it may run with.
+type ServiceAccountAllowlist map[string][]string
+
+// ParseServiceAccountAllowlist parses a comma separated list of
+// namespace:serviceaccount|serviceaccount, for example
+// "team-a-*:restricted|deploy, ci:pipeline". Namespaces can be globs.
+func ParseServiceAccountAllowlist(s string) (ServiceAccountAllowlist, error) {
+	allowlist := ServiceAccountAllowlist{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		ns, serviceAccounts, ok := strings.Cut(item, ":")
+		ns = strings.TrimSpace(ns)
+		if !ok || ns == "" {
+			return nil, fmt.Errorf("invalid service account allowlist %q, needs to be of format namespace:serviceaccount|serviceaccount", item)
+		}
+		if _, err := path.Match(ns, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace glob %q in service account allowlist: %w", ns, err)
+		}
+		for _, sa := range strings.Split(serviceAccounts, "|") {
+			sa = strings.TrimSpace(sa)
+			if sa == "" {
+				continue
+			}
+			if errs := validation.IsDNS1123Subdomain(sa); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid service account %q in service account allowlist: %s", sa, strings.Join(errs, ", "))
+			}
+			allowlist[ns] = append(allowlist[ns], sa)
+		}
+		if len(allowlist[ns]) == 0 {
+			return nil, fmt.Errorf("service account allowlist %q has no service account", item)
+		}
+	}
+	return allowlist, nil
+}
+
+// Allowed returns true if the PipelineRuns of the namespace may run with the
+// service account, allowed by any of the globs matching the namespace. The
+// namespaces not matching any glob are not restricted.
+func (a ServiceAccountAllowlist) Allowed(namespace, serviceAccount string) bool {
+	restricted := false
+	for ns, serviceAccounts := range a {
+		if ok, _ := path.Match(ns, namespace); !ok {
+			continue
+		}
+		restricted = true
+		for _, sa := range serviceAccounts {
+			if sa == serviceAccount {
+				return true
+			}
+		}
+	}
+	return !restricted
+}
+
+func isValidServiceAccountAllowlist(value string) error {
+	_, err := ParseServiceAccountAllowlist(value)
+	return err
+}
+
+// ServiceAccounts returns the service accounts allowed by the admin for the
+// PipelineRuns of each namespace.
+func (s *Settings) ServiceAccounts() ServiceAccountAllowlist {
+	return s.Parsed.ServiceAccounts
+}
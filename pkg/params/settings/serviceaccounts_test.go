@@ -0,0 +1,75 @@
+package settings
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseServiceAccountAllowlist(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    ServiceAccountAllowlist
+		wantErr string
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  ServiceAccountAllowlist{},
+		},
+		{
+			name:  "multiple namespaces and service accounts",
+			value: "team-a-*:restricted | deploy, ci:pipeline",
+			want: ServiceAccountAllowlist{
+				"team-a-*": {"restricted", "deploy"},
+				"ci":       {"pipeline"},
+			},
+		},
+		{
+			name:    "no service account",
+			value:   "ci:",
+			wantErr: "service account allowlist \"ci:\" has no service account",
+		},
+		{
+			name:    "no namespace",
+			value:   "pipeline",
+			wantErr: "invalid service account allowlist \"pipeline\", needs to be of format namespace:serviceaccount|serviceaccount",
+		},
+		{
+			name:    "invalid glob",
+			value:   "ns[:pipeline",
+			wantErr: "invalid namespace glob \"ns[\" in service account allowlist: syntax error in pattern",
+		},
+		{
+			name:    "invalid service account",
+			value:   "ci:Pipeline",
+			wantErr: "invalid service account \"Pipeline\" in service account allowlist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseServiceAccountAllowlist(tt.value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestServiceAccountAllowlistAllowed(t *testing.T) {
+	allowlist, err := ParseServiceAccountAllowlist("team-a-*:restricted|deploy, team-a-prod:release")
+	assert.NilError(t, err)
+	assert.Assert(t, allowlist.Allowed("team-a-dev", "restricted"))
+	assert.Assert(t, !allowlist.Allowed("team-a-dev", "default"))
+	assert.Assert(t, !allowlist.Allowed("team-a-dev", "release"))
+	// the globs matching the namespace add up
+	assert.Assert(t, allowlist.Allowed("team-a-prod", "release"))
+	assert.Assert(t, allowlist.Allowed("team-a-prod", "deploy"))
+	// not restricted
+	assert.Assert(t, allowlist.Allowed("team-b", "default"))
+	assert.Assert(t, ServiceAccountAllowlist{}.Allowed("team-a-dev", "default"))
+}
@@ -0,0 +1,20 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// isValidEventSigningSecret checks the name of the secret of the controller
+// namespace holding the key signing the events, empty disables the signing.
+func isValidEventSigningSecret(value string) error {
+	if value == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(value); len(errs) > 0 {
+		return fmt.Errorf("invalid event signing secret name %q: %s", value, strings.Join(errs, ", "))
+	}
+	return nil
+}
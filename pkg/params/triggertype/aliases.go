@@ -0,0 +1,53 @@
+package triggertype
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Aliases is the registry of the custom event types declared by the admin,
+// it maps an alias (ie: nightly) to the trigger type it stands for (ie:
+// incoming) so it can be used in the on-event annotation.
+type Aliases map[string]Trigger
+
+// ParseAliases parses a comma separated list of alias:trigger, for example
+// "nightly:incoming, preview:pull_request".
+func ParseAliases(s string) (Aliases, error) {
+	aliases := Aliases{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		alias, target, ok := strings.Cut(item, ":")
+		alias, target = strings.TrimSpace(alias), strings.TrimSpace(target)
+		if !ok || alias == "" || target == "" {
+			return nil, fmt.Errorf("invalid custom event type %q, needs to be of format alias:event_type", item)
+		}
+		if StringToType(alias) != "" {
+			return nil, fmt.Errorf("custom event type %q cannot override the builtin event type", alias)
+		}
+		trigger := StringToType(target)
+		if trigger == "" {
+			return nil, fmt.Errorf("custom event type %q targets an unknown event type %q", alias, target)
+		}
+		if existing, ok := aliases[alias]; ok && existing != trigger {
+			return nil, fmt.Errorf("custom event type %q is declared more than once", alias)
+		}
+		aliases[alias] = trigger
+	}
+	return aliases, nil
+}
+
+// For returns the sorted aliases declared for the trigger type.
+func (a Aliases) For(trigger Trigger) []string {
+	ret := []string{}
+	for alias, target := range a {
+		if target == trigger {
+			ret = append(ret, alias)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,64 @@
+package triggertype
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Aliases
+		wantErr string
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  Aliases{},
+		},
+		{
+			name:  "aliases",
+			value: "nightly:incoming, preview:pull_request,weekly: incoming",
+			want:  Aliases{"nightly": Incoming, "preview": PullRequest, "weekly": Incoming},
+		},
+		{
+			name:    "bad format",
+			value:   "nightly",
+			wantErr: "invalid custom event type \"nightly\", needs to be of format alias:event_type",
+		},
+		{
+			name:    "unknown target",
+			value:   "nightly:cron",
+			wantErr: "custom event type \"nightly\" targets an unknown event type \"cron\"",
+		},
+		{
+			name:    "override builtin",
+			value:   "push:incoming",
+			wantErr: "custom event type \"push\" cannot override the builtin event type",
+		},
+		{
+			name:    "declared twice",
+			value:   "nightly:incoming,nightly:push",
+			wantErr: "custom event type \"nightly\" is declared more than once",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAliases(tt.value)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestAliasesFor(t *testing.T) {
+	aliases := Aliases{"weekly": Incoming, "nightly": Incoming, "preview": PullRequest}
+	assert.DeepEqual(t, aliases.For(Incoming), []string{"nightly", "weekly"})
+	assert.DeepEqual(t, aliases.For(Push), []string{})
+}
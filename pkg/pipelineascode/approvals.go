@@ -0,0 +1,116 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// filterApprovals keeps only the PipelineRuns of a pull request whose
+// min-approvals annotation is met by the approvals of the pull request. A
+// review event only runs the gated PipelineRuns which have their number of
+// approvals and have not been started yet for the commit, the others have
+// already run. The annotation is ignored by the providers unable to count the
+// approvals.
+func (p *PacRun) filterApprovals(ctx context.Context, repo *v1alpha1.Repository, matchedPRs []matcher.Match) []matcher.Match {
+	if len(matchedPRs) == 0 || p.event.TriggerTarget != triggertype.PullRequest {
+		return matchedPRs
+	}
+	review := p.event.EventType == provider.PullRequestReviewEventType
+
+	approvals := -1
+	var started map[string]bool
+	kept := []matcher.Match{}
+	for _, match := range matchedPRs {
+		value, ok := match.PipelineRun.GetAnnotations()[keys.MinApprovals]
+		if !ok {
+			if !review {
+				kept = append(kept, match)
+			}
+			continue
+		}
+		required, err := strconv.Atoi(value)
+		if err != nil || required < 0 {
+			p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositoryInvalidMinApprovals",
+				fmt.Sprintf("invalid %s annotation %q on the PipelineRun %s, ignoring it", keys.MinApprovals, value, match.PipelineRun.GetGenerateName()))
+			if !review {
+				kept = append(kept, match)
+			}
+			continue
+		}
+
+		if approvals == -1 {
+			approvals = p.countApprovals(ctx, repo)
+		}
+		switch {
+		case approvals == -1:
+			// cannot count the approvals, run the PipelineRun as without the annotation
+			if !review {
+				kept = append(kept, match)
+			}
+		case approvals >= required:
+			if review {
+				if started == nil {
+					started = p.startedPipelineRuns(ctx, repo)
+				}
+				if started[match.PipelineRun.GetAnnotations()[keys.OriginalPRName]] {
+					continue
+				}
+			}
+			kept = append(kept, match)
+		default:
+			p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryWaitingForApprovals",
+				fmt.Sprintf("the PipelineRun %s needs %d approvals of the pull request %d and has %d, skipping it until then",
+					match.PipelineRun.GetGenerateName(), required, p.event.PullRequestNumber, approvals))
+		}
+	}
+	return kept
+}
+
+// countApprovals returns the number of approvals of the pull request, -1 when
+// the provider cannot count them.
+func (p *PacRun) countApprovals(ctx context.Context, repo *v1alpha1.Repository) int {
+	counter, ok := p.vcx.(provider.ApprovalsInterface)
+	if !ok {
+		p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositoryMinApprovalsUnsupported",
+			fmt.Sprintf("the %s annotation is not supported by the %s provider, ignoring it", keys.MinApprovals, p.vcx.GetConfig().Name))
+		return -1
+	}
+	approvals, err := counter.CountApprovals(ctx, p.event)
+	if err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCountApprovals",
+			fmt.Sprintf("cannot count the approvals of the pull request %d, ignoring the %s annotation: %s", p.event.PullRequestNumber, keys.MinApprovals, err))
+		return -1
+	}
+	return approvals
+}
+
+// startedPipelineRuns returns the names of the PipelineRuns already started
+// for the commit of the pull request. When they cannot be listed none is
+// returned, the approved PipelineRuns run again rather than never.
+func (p *PacRun) startedPipelineRuns(ctx context.Context, repo *v1alpha1.Repository) map[string]bool {
+	started := map[string]bool{}
+	labelSelector := getLabelSelector(map[string]string{
+		keys.SHA:         formatting.CleanValueKubernetes(p.event.SHA),
+		keys.PullRequest: strconv.Itoa(p.event.PullRequestNumber),
+	})
+	prs, err := p.run.Clients.Tekton.TektonV1().PipelineRuns(repo.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositoryListPipelineRuns",
+			fmt.Sprintf("cannot list the PipelineRuns of the commit %s: %s", p.event.SHA, err))
+		return started
+	}
+	for _, pr := range prs.Items {
+		started[pr.GetAnnotations()[keys.OriginalPRName]] = true
+	}
+	return started
+}
@@ -0,0 +1,142 @@
+package pipelineascode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+type approvalsProvider struct {
+	testprovider.TestProviderImp
+	approvals int
+}
+
+func (v *approvalsProvider) CountApprovals(_ context.Context, _ *info.Event) (int, error) {
+	return v.approvals, nil
+}
+
+func TestFilterApprovals(t *testing.T) {
+	matchedPRs := []matcher.Match{
+		{PipelineRun: &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Name:        "unit",
+			Annotations: map[string]string{keys.OriginalPRName: "unit"},
+		}}},
+		{PipelineRun: &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Name:        "e2e",
+			Annotations: map[string]string{keys.OriginalPRName: "e2e", keys.MinApprovals: "1"},
+		}}},
+		{PipelineRun: &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Name:        "perf",
+			Annotations: map[string]string{keys.OriginalPRName: "perf", keys.MinApprovals: "2"},
+		}}},
+	}
+	startedRun := func(name, sha string) *tektonv1.PipelineRun {
+		return &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Name:        name + "-abcde",
+			Namespace:   "ns",
+			Labels:      map[string]string{keys.SHA: sha, keys.PullRequest: "1"},
+			Annotations: map[string]string{keys.OriginalPRName: name},
+		}}
+	}
+	tests := []struct {
+		name    string
+		event   *info.Event
+		vcx     provider.Interface
+		started []*tektonv1.PipelineRun
+		want    []string
+	}{
+		{
+			name:  "pull request without approvals",
+			event: &info.Event{TriggerTarget: triggertype.PullRequest, EventType: "pull_request"},
+			vcx:   &approvalsProvider{},
+			want:  []string{"unit"},
+		},
+		{
+			name:  "pull request already approved",
+			event: &info.Event{TriggerTarget: triggertype.PullRequest, EventType: "pull_request"},
+			vcx:   &approvalsProvider{approvals: 2},
+			want:  []string{"unit", "e2e", "perf"},
+		},
+		{
+			name:  "first approval",
+			event: &info.Event{TriggerTarget: triggertype.PullRequest, EventType: provider.PullRequestReviewEventType},
+			vcx:   &approvalsProvider{approvals: 1},
+			want:  []string{"e2e"},
+		},
+		{
+			name:    "second approval",
+			event:   &info.Event{TriggerTarget: triggertype.PullRequest, EventType: provider.PullRequestReviewEventType, SHA: "sha1", PullRequestNumber: 1},
+			vcx:     &approvalsProvider{approvals: 2},
+			started: []*tektonv1.PipelineRun{startedRun("unit", "sha1"), startedRun("e2e", "sha1")},
+			want:    []string{"perf"},
+		},
+		{
+			name:  "two approvals at once",
+			event: &info.Event{TriggerTarget: triggertype.PullRequest, EventType: provider.PullRequestReviewEventType, SHA: "sha1", PullRequestNumber: 1},
+			vcx:   &approvalsProvider{approvals: 2},
+			want:  []string{"e2e", "perf"},
+		},
+		{
+			name:    "third approval",
+			event:   &info.Event{TriggerTarget: triggertype.PullRequest, EventType: provider.PullRequestReviewEventType, SHA: "sha1", PullRequestNumber: 1},
+			vcx:     &approvalsProvider{approvals: 3},
+			started: []*tektonv1.PipelineRun{startedRun("e2e", "sha1"), startedRun("perf", "sha1")},
+			want:    []string{},
+		},
+		{
+			name:    "approval after a new commit",
+			event:   &info.Event{TriggerTarget: triggertype.PullRequest, EventType: provider.PullRequestReviewEventType, SHA: "sha2", PullRequestNumber: 1},
+			vcx:     &approvalsProvider{approvals: 1},
+			started: []*tektonv1.PipelineRun{startedRun("e2e", "sha1")},
+			want:    []string{"e2e"},
+		},
+		{
+			name:  "provider unable to count the approvals",
+			event: &info.Event{TriggerTarget: triggertype.PullRequest, EventType: "pull_request"},
+			vcx:   &testprovider.TestProviderImp{},
+			want:  []string{"unit", "e2e", "perf"},
+		},
+		{
+			name:  "push",
+			event: &info.Event{TriggerTarget: triggertype.Push, EventType: "push"},
+			vcx:   &approvalsProvider{},
+			want:  []string{"unit", "e2e", "perf"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: tt.started})
+			logger := zap.NewNop().Sugar()
+			p := &PacRun{
+				event:        tt.event,
+				vcx:          tt.vcx,
+				run:          &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline}},
+				logger:       logger,
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+			repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+			got := []string{}
+			for _, match := range p.filterApprovals(ctx, repo, matchedPRs) {
+				got = append(got, match.PipelineRun.GetName())
+			}
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package pipelineascode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"go.uber.org/zap"
+)
+
+// authorizerRequest is the request sent to the external authorizer of the
+// GitOps commands.
+type authorizerRequest struct {
+	Action     string                 `json:"action"`
+	Comment    string                 `json:"comment"`
+	Sender     string                 `json:"sender"`
+	Repository authorizerRepository   `json:"repository"`
+	Event      authorizerRequestEvent `json:"event"`
+}
+
+type authorizerRepository struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	URL       string `json:"url"`
+}
+
+type authorizerRequestEvent struct {
+	Provider          string `json:"provider"`
+	TriggerTarget     string `json:"trigger_target"`
+	Organization      string `json:"organization"`
+	Repository        string `json:"repository"`
+	SHA               string `json:"sha"`
+	BaseBranch        string `json:"base_branch"`
+	HeadBranch        string `json:"head_branch"`
+	PullRequestNumber int    `json:"pull_request_number,omitempty"`
+	TargetPipelineRun string `json:"target_pipelinerun,omitempty"`
+}
+
+// authorizerResponse is the answer of the external authorizer, the command is
+// only run when allowed.
+type authorizerResponse struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// gitOpsCommand returns the builtin GitOps command of the comment of the
+// event, an empty string when the event is not a builtin GitOps command.
+func gitOpsCommand(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	command := opscomments.CommentEventType(comment)
+	if command == opscomments.NoOpsCommentEventType {
+		return ""
+	}
+	return command.String()
+}
+
+// authorizeGitOpsCommand asks the external authorizer set in the settings if
+// the sender can run the GitOps command of the event. When the authorizer
+// cannot be reached or answers with an error, the command is allowed in the
+// fail-open mode and denied otherwise.
+func (p *PacRun) authorizeGitOpsCommand(ctx context.Context, repo *v1alpha1.Repository) bool {
+	command := gitOpsCommand(p.event.TriggerComment)
+	if p.pacInfo == nil || p.pacInfo.GitOpsAuthorizerURL == "" || command == "" {
+		return true
+	}
+
+	response, err := p.callAuthorizer(ctx, repo, command)
+	if err != nil {
+		if p.pacInfo.GitOpsAuthorizerFailOpen {
+			p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "GitOpsAuthorizerFailed",
+				fmt.Sprintf("cannot authorize the %s command of %s, allowing it: %v", command, p.event.Sender, err))
+			return true
+		}
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "GitOpsAuthorizerFailed",
+			fmt.Sprintf("cannot authorize the %s command of %s, denying it: %v", command, p.event.Sender, err))
+		return false
+	}
+	if !response.Allowed {
+		msg := fmt.Sprintf("the %s command of %s has been denied by the authorizer", command, p.event.Sender)
+		if response.Reason != "" {
+			msg += ": " + response.Reason
+		}
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPermissionDenied", msg)
+		return false
+	}
+	return true
+}
+
+func (p *PacRun) callAuthorizer(ctx context.Context, repo *v1alpha1.Repository, command string) (*authorizerResponse, error) {
+	targetPipelineRun := p.event.TargetTestPipelineRun
+	if p.event.CancelPipelineRuns {
+		targetPipelineRun = p.event.TargetCancelPipelineRun
+	}
+	body, err := json.Marshal(authorizerRequest{
+		Action:  command,
+		Comment: p.event.TriggerComment,
+		Sender:  p.event.Sender,
+		Repository: authorizerRepository{
+			Name:      repo.GetName(),
+			Namespace: repo.GetNamespace(),
+			URL:       repo.Spec.URL,
+		},
+		Event: authorizerRequestEvent{
+			Provider:          p.vcx.GetConfig().Name,
+			TriggerTarget:     p.event.TriggerTarget.String(),
+			Organization:      p.event.Organization,
+			Repository:        p.event.Repository,
+			SHA:               p.event.SHA,
+			BaseBranch:        p.event.BaseBranch,
+			HeadBranch:        p.event.HeadBranch,
+			PullRequestNumber: p.event.PullRequestNumber,
+			TargetPipelineRun: targetPipelineRun,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.pacInfo.GitOpsAuthorizerTimeoutDuration())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.pacInfo.GitOpsAuthorizerURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.run.Clients.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("authorizer answered with the status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	response := &authorizerResponse{}
+	if err := json.Unmarshal(data, response); err != nil {
+		return nil, fmt.Errorf("cannot parse the answer of the authorizer: %w", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,171 @@
+package pipelineascode
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketserver"
+	bbv1test "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketserver/test"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestAuthorizeGitOpsCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		comment    string
+		noURL      bool
+		status     int
+		response   string
+		failOpen   bool
+		want       bool
+		wantCalled bool
+	}{
+		{
+			name:    "no authorizer",
+			comment: "/test",
+			noURL:   true,
+			want:    true,
+		},
+		{
+			name:    "not a gitops command",
+			comment: "lgtm",
+			want:    true,
+		},
+		{
+			name:       "allowed",
+			comment:    "/retest unit",
+			status:     http.StatusOK,
+			response:   `{"allowed": true}`,
+			want:       true,
+			wantCalled: true,
+		},
+		{
+			name:       "denied",
+			comment:    "/cancel",
+			status:     http.StatusOK,
+			response:   `{"allowed": false, "reason": "outside of the business hours"}`,
+			wantCalled: true,
+		},
+		{
+			name:       "error fail-closed",
+			comment:    "/test",
+			status:     http.StatusInternalServerError,
+			wantCalled: true,
+		},
+		{
+			name:       "error fail-open",
+			comment:    "/test",
+			status:     http.StatusInternalServerError,
+			failOpen:   true,
+			want:       true,
+			wantCalled: true,
+		},
+		{
+			name:       "invalid answer",
+			comment:    "/test",
+			status:     http.StatusOK,
+			response:   `allowed`,
+			wantCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				request := authorizerRequest{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&request))
+				assert.Equal(t, request.Action, gitOpsCommand(tt.comment))
+				assert.Equal(t, request.Sender, "fantasio")
+				assert.Equal(t, request.Repository.Namespace, "test")
+				assert.Equal(t, request.Event.PullRequestNumber, 42)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			logger := zap.NewNop().Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "testrepo", Namespace: "test"},
+				Spec:       v1alpha1.RepositorySpec{URL: "https://github.com/owner/repo"},
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{Repositories: []*v1alpha1.Repository{repo}})
+			cs := &params.Run{Clients: clients.Clients{Log: logger, Kube: stdata.Kube, HTTP: *server.Client()}}
+			pacInfo := &info.PacOpts{Settings: settings.Settings{
+				GitOpsAuthorizerURL:      server.URL,
+				GitOpsAuthorizerFailOpen: tt.failOpen,
+			}}
+			if tt.noURL {
+				pacInfo.GitOpsAuthorizerURL = ""
+			}
+			event := &info.Event{
+				Sender:            "fantasio",
+				TriggerTarget:     triggertype.PullRequest,
+				TriggerComment:    tt.comment,
+				PullRequestNumber: 42,
+			}
+			p := NewPacs(event, &testprovider.TestProviderImp{}, cs, pacInfo, &kitesthelper.KinterfaceTest{}, logger, nil)
+			p.eventEmitter = events.NewEventEmitter(stdata.Kube, logger)
+
+			assert.Equal(t, p.authorizeGitOpsCommand(ctx, repo), tt.want)
+			assert.Equal(t, called, tt.wantCalled)
+		})
+	}
+}
+
+func TestAuthorizeGitOpsCommandBitbucketDataCenter(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		request := authorizerRequest{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, request.Action, "retest-comment")
+		assert.Equal(t, request.Comment, "/retest unit")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	logger := zap.NewNop().Sugar()
+	ctx, _ := rtesting.SetupFakeContext(t)
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "testrepo", Namespace: "test"}}
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{Repositories: []*v1alpha1.Repository{repo}})
+	cs := &params.Run{Clients: clients.Clients{Log: logger, Kube: stdata.Kube, HTTP: *server.Client()}}
+
+	payload, err := json.Marshal(bbv1test.MakePREvent(&info.Event{
+		AccountID:    "12345",
+		Sender:       "fantasio",
+		Organization: "PROJ",
+		Repository:   "repo",
+		URL:          "http://forge/PROJ/repo/browse",
+		SHA:          "abcd",
+	}, "/retest unit"))
+	assert.NilError(t, err)
+	req := &http.Request{Header: http.Header{}}
+	req.Header.Set("X-Event-Key", "pr:comment:added")
+	event, err := (&bitbucketserver.Provider{}).ParsePayload(ctx, cs, req, string(payload))
+	assert.NilError(t, err)
+
+	// the comments of Bitbucket Data Center go through the authorizer too,
+	// denied when it fails in the fail-closed mode
+	pacInfo := &info.PacOpts{Settings: settings.Settings{GitOpsAuthorizerURL: server.URL}}
+	p := NewPacs(event, &testprovider.TestProviderImp{}, cs, pacInfo, &kitesthelper.KinterfaceTest{}, logger, nil)
+	p.eventEmitter = events.NewEventEmitter(stdata.Kube, logger)
+	assert.Assert(t, !p.authorizeGitOpsCommand(ctx, repo))
+	assert.Assert(t, called)
+}
@@ -271,6 +271,67 @@ func TestCancelPipelinerun(t *testing.T) {
 				"pr-foo-abc-123": true,
 			},
 		},
+		{
+			name: "cancel runs of a deleted branch",
+			event: &info.Event{
+				Repository:    "foo",
+				SHA:           "foosha",
+				TriggerTarget: "push",
+				BaseBranch:    "refs/heads/feature",
+				State: info.State{
+					CancelPipelineRuns: true,
+					BranchDeleted:      true,
+				},
+			},
+			pipelineRuns: []*pipelinev1.PipelineRun{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "pr-feature-older-sha",
+						Namespace:   "foo",
+						Labels:      map[string]string{keys.URLRepository: "foo", keys.SHA: "oldersha"},
+						Annotations: map[string]string{keys.Branch: "refs/heads/feature", keys.SourceBranch: "refs/heads/feature"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "pr-feature",
+						Namespace:   "foo",
+						Labels:      fooRepoLabelsForPush,
+						Annotations: map[string]string{keys.Branch: "refs/heads/feature", keys.SourceBranch: "refs/heads/feature"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "pr-main",
+						Namespace:   "foo",
+						Labels:      fooRepoLabelsForPush,
+						Annotations: map[string]string{keys.Branch: "refs/heads/main", keys.SourceBranch: "refs/heads/main"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "pr-fix-into-feature",
+						Namespace:   "foo",
+						Labels:      fooRepoLabelsForPush,
+						Annotations: map[string]string{keys.Branch: "feature", keys.SourceBranch: "fix"},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "pr-feature-into-main",
+						Namespace:   "foo",
+						Labels:      fooRepoLabelsForPush,
+						Annotations: map[string]string{keys.Branch: "main", keys.SourceBranch: "feature"},
+					},
+				},
+			},
+			repo: fooRepo,
+			cancelledPipelineRuns: map[string]bool{
+				"pr-feature-older-sha": true,
+				"pr-feature":           true,
+				"pr-feature-into-main": true,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"strconv"
-	"sync"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
@@ -18,12 +17,6 @@ import (
 	"k8s.io/apimachinery/pkg/selection"
 )
 
-var cancelMergePatch = map[string]interface{}{
-	"spec": map[string]interface{}{
-		"status": tektonv1.PipelineRunSpecStatusCancelledRunFinally,
-	},
-}
-
 func (p *PacRun) cancelPipelineRuns(ctx context.Context, repo *v1alpha1.Repository) error {
 	labelSelector := getLabelSelector(map[string]string{
 		keys.URLRepository: formatting.CleanValueKubernetes(p.event.Repository),
@@ -36,6 +29,16 @@ func (p *PacRun) cancelPipelineRuns(ctx context.Context, repo *v1alpha1.Reposito
 		})
 	}
 
+	// on a deleted branch we cancel all the runs of its commits, whatever their
+	// SHA, including the pull requests from it but not the ones targeting it
+	deletedBranch := ""
+	if p.event.BranchDeleted {
+		deletedBranch = formatting.SanitizeBranch(p.event.BaseBranch)
+		labelSelector = getLabelSelector(map[string]string{
+			keys.URLRepository: formatting.CleanValueKubernetes(p.event.Repository),
+		})
+	}
+
 	prs, err := p.run.Clients.Tekton.TektonV1().PipelineRuns(repo.Namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector,
 	})
@@ -50,32 +53,23 @@ func (p *PacRun) cancelPipelineRuns(ctx context.Context, repo *v1alpha1.Reposito
 		return nil
 	}
 
-	var wg sync.WaitGroup
+	toCancel := []tektonv1.PipelineRun{}
 	for _, pr := range prs.Items {
+		if deletedBranch != "" && formatting.SanitizeBranch(pr.GetAnnotations()[keys.SourceBranch]) != deletedBranch {
+			continue
+		}
 		if p.event.TargetCancelPipelineRun != "" {
 			if prName, ok := pr.GetAnnotations()[keys.OriginalPRName]; !ok || prName != p.event.TargetCancelPipelineRun {
 				continue
 			}
 		}
-		if pr.IsDone() {
-			p.logger.Infof("pipelinerun %v/%v is done, skipping cancellation", pr.GetNamespace(), pr.GetName())
-			continue
-		}
-		if pr.IsCancelled() || pr.IsGracefullyCancelled() || pr.IsGracefullyStopped() {
-			p.logger.Infof("pipelinerun %v/%v is already in %v state", pr.GetNamespace(), pr.GetName(), pr.Spec.Status)
-			continue
-		}
+		toCancel = append(toCancel, pr)
+	}
 
-		wg.Add(1)
-		go func(ctx context.Context, pr tektonv1.PipelineRun) {
-			defer wg.Done()
-			if _, err := action.PatchPipelineRun(ctx, p.logger, "cancel patch", p.run.Clients.Tekton, &pr, cancelMergePatch); err != nil {
-				errMsg := fmt.Sprintf("failed to cancel pipelineRun %s/%s: %s", pr.GetNamespace(), pr.GetName(), err.Error())
-				p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryPipelineRun", errMsg)
-			}
-		}(ctx, pr)
+	_, errs := action.CancelPipelineRuns(ctx, p.logger, p.run.Clients.Tekton, toCancel)
+	for _, err := range errs {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryPipelineRun", err.Error())
 	}
-	wg.Wait()
 
 	return nil
 }
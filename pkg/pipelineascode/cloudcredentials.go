@@ -0,0 +1,57 @@
+package pipelineascode
+
+import (
+	"context"
+	"encoding/json"
+
+	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secrets"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/templates"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// changeCloudCredentialsSecret replaces the cloud_credentials_secret
+// variable of the PipelineRuns using it with a random secret name, stored in
+// the annotations to create the secret when the PipelineRun starts and delete
+// it once it is done.
+func changeCloudCredentialsSecret(prs []*tektonv1.PipelineRun) error {
+	for k, p := range prs {
+		b, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+
+		name := secrets.GenerateCloudCredentialsSecretName()
+		processed := templates.ReplacePlaceHoldersVariables(string(b), map[string]string{
+			"cloud_credentials_secret": name,
+		}, nil, nil, map[string]interface{}{})
+		// don't mint credentials for the PipelineRuns not asking for them
+		if processed == string(b) {
+			continue
+		}
+
+		var np *tektonv1.PipelineRun
+		if err := json.Unmarshal([]byte(processed), &np); err != nil {
+			return err
+		}
+		if np.Annotations == nil {
+			np.Annotations = map[string]string{}
+		}
+		np.Annotations[apipac.CloudCredentialsSecret] = name
+		prs[k] = np
+	}
+	return nil
+}
+
+// createCloudCredentialsSecret exchanges a token of the service account of
+// the cloud_credentials setting for a short-lived cloud access token and
+// stores it in the secret of the PipelineRun.
+func (p *PacRun) createCloudCredentialsSecret(ctx context.Context, match matcher.Match, secretName string) error {
+	serviceAccount, err := secrets.MintCloudCredentials(ctx, p.run, p.k8int, &p.pacInfo.Settings, match.Repo, match.PipelineRun, p.event, secretName)
+	if err != nil {
+		return err
+	}
+	p.logger.Infof("created the cloud credentials secret %s/%s for the service account %s", match.Repo.GetNamespace(), secretName, serviceAccount)
+	return nil
+}
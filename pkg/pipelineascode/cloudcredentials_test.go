@@ -0,0 +1,212 @@
+package pipelineascode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestChangeCloudCredentialsSecret(t *testing.T) {
+	prs := []*tektonv1.PipelineRun{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "{{ cloud_credentials_secret }}",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "no-credentials",
+			},
+		},
+	}
+	err := changeCloudCredentialsSecret(prs)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(prs[0].GetName(), "pac-cloudcreds-"), prs[0].GetName(), "has no pac-cloudcreds prefix")
+	assert.Equal(t, prs[0].GetAnnotations()[apipac.CloudCredentialsSecret], prs[0].GetName())
+	_, ok := prs[1].GetAnnotations()[apipac.CloudCredentialsSecret]
+	assert.Assert(t, !ok)
+}
+
+func TestCreateCloudCredentialsSecret(t *testing.T) {
+	tests := []struct {
+		name           string
+		cc             *v1alpha1.CloudCredentials
+		serviceAccount string
+		tokenURL       string
+		wantToken      string
+		wantErr        string
+	}{
+		{
+			name:           "service account of the pipelinerun",
+			cc:             &v1alpha1.CloudCredentials{Audience: "pool"},
+			serviceAccount: "builder",
+			wantToken:      "builder-token",
+		},
+		{
+			name:           "service account of the setting",
+			cc:             &v1alpha1.CloudCredentials{Audience: "pool", ServiceAccount: "deployer"},
+			serviceAccount: "builder",
+			wantToken:      "deployer-token",
+		},
+		{
+			name:      "default service account",
+			cc:        &v1alpha1.CloudCredentials{Audience: "pool"},
+			wantToken: "default-token",
+		},
+		{
+			name:    "no setting",
+			wantErr: "without the cloud_credentials setting",
+		},
+		{
+			name:     "token url not allowed",
+			cc:       &v1alpha1.CloudCredentials{Audience: "pool"},
+			tokenURL: "https://attacker.example.com/token",
+			wantErr:  "not allowed by the cloud-credentials-token-urls setting",
+		},
+		{
+			name:    "audience not allowed",
+			cc:      &v1alpha1.CloudCredentials{Audience: "other-pool"},
+			wantErr: "not allowed by the cloud-credentials-audiences setting",
+		},
+		{
+			name:           "service account not opted in",
+			cc:             &v1alpha1.CloudCredentials{Audience: "pool"},
+			serviceAccount: "admin",
+			wantErr:        "has not opted in the cloud credentials",
+		},
+		{
+			name:           "service account not found",
+			cc:             &v1alpha1.CloudCredentials{Audience: "pool"},
+			serviceAccount: "missing",
+			wantErr:        "cannot get the service account ns/missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NilError(t, r.ParseForm())
+				_, _ = w.Write([]byte(`{"access_token":"cloud-` + r.Form.Get("subject_token") + `","token_type":"Bearer","expires_in":3600}`))
+			}))
+			defer server.Close()
+
+			kint := &kitesthelper.KinterfaceTest{
+				ServiceAccountTokens: map[string]string{
+					"ns/default":  "default-token",
+					"ns/builder":  "builder-token",
+					"ns/deployer": "deployer-token",
+					"ns/admin":    "admin-token",
+				},
+			}
+			optedIn := map[string]string{apipac.CloudCredentials: "true"}
+			kube := kubefake.NewSimpleClientset(
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "ns", Annotations: optedIn}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "builder", Namespace: "ns", Annotations: optedIn}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "ns", Annotations: optedIn}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "ns"}},
+			)
+			pacInfo := &info.PacOpts{}
+			assert.NilError(t, settings.SyncConfig(zap.NewNop().Sugar(), &pacInfo.Settings, map[string]string{
+				"cloud-credentials-token-urls": server.URL,
+				"cloud-credentials-audiences":  "pool",
+			}))
+			p := &PacRun{
+				event:   &info.Event{Organization: "owner", Repository: "repo", SHA: "123"},
+				run:     &params.Run{Clients: clients.Clients{HTTP: *server.Client(), Kube: kube, Log: zap.NewNop().Sugar()}},
+				k8int:   kint,
+				pacInfo: pacInfo,
+				logger:  zap.NewNop().Sugar(),
+			}
+			if tt.cc != nil {
+				tt.cc.TokenURL = server.URL
+				if tt.tokenURL != "" {
+					tt.cc.TokenURL = tt.tokenURL
+				}
+			}
+			pr := &tektonv1.PipelineRun{}
+			pr.Spec.TaskRunTemplate.ServiceAccountName = tt.serviceAccount
+			match := matcher.Match{
+				PipelineRun: pr,
+				Repo: &v1alpha1.Repository{
+					ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+					Spec:       v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{CloudCredentials: tt.cc}},
+				},
+			}
+			err := p.createCloudCredentialsSecret(context.Background(), match, "pac-cloudcreds-abcdef")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Equal(t, len(kint.CreatedSecrets), 0)
+				return
+			}
+			assert.NilError(t, err)
+			secret, ok := kint.CreatedSecrets["ns/pac-cloudcreds-abcdef"]
+			assert.Assert(t, ok)
+			assert.Equal(t, secret.StringData["token"], "cloud-"+tt.wantToken)
+		})
+	}
+}
+
+func TestStartPRQueuedCloudCredentials(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	logger := zap.NewNop().Sugar()
+	limit := 1
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+		Spec: v1alpha1.RepositorySpec{
+			URL:              "https://github.com/owner/repo",
+			ConcurrencyLimit: &limit,
+			Settings: &v1alpha1.Settings{CloudCredentials: &v1alpha1.CloudCredentials{
+				TokenURL: "https://sts.example.com/token",
+				Audience: "pool",
+			}},
+		},
+	}
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{Repositories: []*v1alpha1.Repository{repo}})
+	cs := &params.Run{Clients: clients.Clients{
+		PipelineAsCode: stdata.PipelineAsCode,
+		Kube:           stdata.Kube,
+		Tekton:         stdata.Pipeline,
+		Log:            logger,
+	}, Info: info.Info{Controller: &info.ControllerInfo{Name: "default"}}}
+	cs.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+	kint := &kitesthelper.KinterfaceTest{}
+	vcx := &testprovider.TestProviderImp{}
+	event := &info.Event{Organization: "owner", Repository: "repo", SHA: "123", EventType: "push", TriggerTarget: "push", BaseBranch: "main"}
+	p := NewPacs(event, vcx, cs, &info.PacOpts{}, kint, logger, nil)
+
+	pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Name:        "push",
+		Namespace:   "ns",
+		Labels:      map[string]string{},
+		Annotations: map[string]string{apipac.CloudCredentialsSecret: "pac-cloudcreds-abcdef", apipac.OriginalPRName: "push"},
+	}}
+	got, err := p.startPR(ctx, matcher.Match{PipelineRun: pr, Repo: repo})
+	assert.NilError(t, err)
+	assert.Equal(t, got.Spec.Status, tektonv1.PipelineRunSpecStatus(tektonv1.PipelineRunSpecStatusPending))
+	// the cloud credentials are minted by the reconciler when the run starts
+	assert.Equal(t, len(kint.CreatedSecrets), 0)
+	_, owned := kint.OwnedSecrets["ns/pac-cloudcreds-abcdef"]
+	assert.Assert(t, !owned)
+	assert.Equal(t, len(vcx.CreatedStatuses), 1)
+	assert.Equal(t, vcx.CreatedStatuses[0].Status, queuedStatus)
+}
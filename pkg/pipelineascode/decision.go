@@ -0,0 +1,73 @@
+package pipelineascode
+
+import (
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+)
+
+// the decisions taken on an event, recorded by the event decision metric
+const (
+	decisionMatched = "matched"
+	decisionSkipped = "skipped"
+	decisionError   = "error"
+)
+
+// the reasons of the events skipped, to know from the metrics why a
+// PipelineRun has not been triggered
+const (
+	skipReasonNoRepository      = "no-repository"
+	skipReasonNoPipelineRun     = "no-pipelinerun"
+	skipReasonNoMatch           = "no-match"
+	skipReasonPolicyDenied      = "policy-denied"
+	skipReasonIgnoredSender     = "ignored-sender"
+	skipReasonPendingApprovals  = "pending-approvals"
+	skipReasonSecretsDetected   = "secrets-detected"
+	skipReasonSkipCI            = "skip-ci"
+	skipReasonUnsignedCommit    = "unsigned-commit"
+	skipReasonPaused            = "paused"
+	skipReasonFrozen            = "frozen"
+	skipReasonPullRequestClosed = "pull-request-closed"
+	skipReasonGitOpsCommand     = "gitops-command"
+	skipReasonNameCollision     = "name-collision"
+	skipReasonDuplicate         = "duplicate"
+	skipReasonNoSnapshot        = "no-snapshot"
+	skipReasonServiceAccount    = "service-account-denied"
+)
+
+// eventDecision returns the decision taken on an event from the error of its
+// processing and the number of PipelineRuns started, the event is skipped
+// with the reason recorded or as not matching any PipelineRun.
+func eventDecision(err error, started int, skipReason string) (string, string) {
+	switch {
+	case err != nil:
+		return decisionError, ""
+	case started > 0:
+		return decisionMatched, ""
+	case skipReason != "":
+		return decisionSkipped, skipReason
+	}
+	return decisionSkipped, skipReasonNoMatch
+}
+
+// countEventDecision records the decision taken on the event by provider and
+// event type.
+func (p *PacRun) countEventDecision(err error, started int) {
+	decision, reason := eventDecision(err, started, p.skipReason)
+	metrics.CountEventDecision(p.vcx.GetConfig().Name, p.event.EventType, decision, reason)
+}
+
+// skipped records why the event is skipped, the first reason is kept.
+func (p *PacRun) skipped(reason string) {
+	if p.skipReason == "" {
+		p.skipReason = reason
+	}
+}
+
+// filtered returns the PipelineRuns kept by a filter, recording the reason of
+// the skip when it has kept none of them.
+func (p *PacRun) filtered(matchedPRs, kept []matcher.Match, reason string) []matcher.Match {
+	if len(matchedPRs) > 0 && len(kept) == 0 {
+		p.skipped(reason)
+	}
+	return kept
+}
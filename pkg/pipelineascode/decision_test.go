@@ -0,0 +1,69 @@
+package pipelineascode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"gotest.tools/v3/assert"
+)
+
+func TestEventDecision(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		started      int
+		skipReason   string
+		wantDecision string
+		wantReason   string
+	}{
+		{
+			name:         "matched",
+			started:      2,
+			wantDecision: decisionMatched,
+		},
+		{
+			name:         "error",
+			err:          fmt.Errorf("cannot get the commit"),
+			skipReason:   skipReasonNoRepository,
+			wantDecision: decisionError,
+		},
+		{
+			name:         "skipped with a reason",
+			skipReason:   skipReasonPolicyDenied,
+			wantDecision: decisionSkipped,
+			wantReason:   skipReasonPolicyDenied,
+		},
+		{
+			name:         "skipped without a reason",
+			wantDecision: decisionSkipped,
+			wantReason:   skipReasonNoMatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, reason := eventDecision(tt.err, tt.started, tt.skipReason)
+			assert.Equal(t, decision, tt.wantDecision)
+			assert.Equal(t, reason, tt.wantReason)
+		})
+	}
+}
+
+func TestFiltered(t *testing.T) {
+	p := &PacRun{}
+	matches := []matcher.Match{{}, {}}
+
+	// a filter keeping some PipelineRuns is not a skip
+	kept := p.filtered(matches, matches[:1], skipReasonIgnoredSender)
+	assert.Equal(t, len(kept), 1)
+	assert.Equal(t, p.skipReason, "")
+
+	// nothing to filter
+	p.filtered(nil, nil, skipReasonIgnoredSender)
+	assert.Equal(t, p.skipReason, "")
+
+	// the first filter keeping none of them is the reason
+	p.filtered(matches, nil, skipReasonPendingApprovals)
+	p.filtered(matches, nil, skipReasonSecretsDetected)
+	assert.Equal(t, p.skipReason, skipReasonPendingApprovals)
+}
@@ -0,0 +1,159 @@
+package pipelineascode
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	dedupLeasePrefix = "pac-dedup-"
+
+	// DedupCleanupInterval is how often the expired event deduplication
+	// leases are deleted.
+	DedupCleanupInterval = 10 * time.Minute
+)
+
+// skipDuplicateEvents returns the matched PipelineRuns not already started by
+// another delivery of the same event.
+func (p *PacRun) skipDuplicateEvents(ctx context.Context, repo *v1alpha1.Repository, matchedPRs []matcher.Match) []matcher.Match {
+	ret := make([]matcher.Match, 0, len(matchedPRs))
+	for _, match := range matchedPRs {
+		prName := match.PipelineRun.GetAnnotations()[keys.OriginalPRName]
+		duplicate, startedBy, err := p.isDuplicateEvent(ctx, prName)
+		if err != nil {
+			// better run twice than not run at all
+			p.logger.Warnf("cannot check if the event has already been delivered for the PipelineRun %s: %s", prName, err.Error())
+		}
+		if duplicate {
+			msg := fmt.Sprintf("skipping the PipelineRun %s on %s, it has already been started for the same event", prName, p.event.SHA)
+			if startedBy != "" {
+				msg += " by " + startedBy
+			}
+			p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryDuplicateEvent", msg)
+			continue
+		}
+		ret = append(ret, match)
+	}
+	return ret
+}
+
+// dedupLeaseName returns the name of the lease recording that the PipelineRun
+// has been started for the event, the same event delivered by the GitHub App
+// and a webhook or by two webhooks gives the same name. The ID of the comment
+// triggering the event is part of it, two /retest comments on the same commit
+// are two events.
+func dedupLeaseName(url, sha, eventType, triggerTarget, baseBranch, headBranch string, pullRequestNumber int, commentID int64, prName string) string {
+	key := strings.Join([]string{url, sha, eventType, triggerTarget, baseBranch, headBranch, fmt.Sprint(pullRequestNumber), fmt.Sprint(commentID), prName}, "\n")
+	return fmt.Sprintf("%s%x", dedupLeasePrefix, sha256.Sum256([]byte(key)))[:len(dedupLeasePrefix)+40]
+}
+
+// isDuplicateEvent records in a lease of the controller namespace, shared by
+// all the controllers of the cluster, that the PipelineRun is started for the
+// event. It returns true if another delivery of the same event already started
+// it during the event-deduplication-window setting.
+func (p *PacRun) isDuplicateEvent(ctx context.Context, prName string) (bool, string, error) {
+	window := p.pacInfo.EventDeduplicationWindowDuration()
+	if window == 0 || p.run.Info.Kube == nil || p.run.Info.Kube.Namespace == "" {
+		return false, "", nil
+	}
+	holder := "pipelines-as-code"
+	if p.run.Info.Controller != nil && p.run.Info.Controller.Name != "" {
+		holder = p.run.Info.Controller.Name
+	}
+	if p.event.InstallationID > 0 {
+		holder += "/github-app"
+	} else {
+		holder += "/webhook"
+	}
+
+	leases := p.run.Clients.Kube.CoordinationV1().Leases(p.run.Info.Kube.Namespace)
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(window.Seconds())
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: dedupLeaseName(p.event.URL, p.event.SHA, p.event.EventType, p.event.TriggerTarget.String(),
+				p.event.BaseBranch, p.event.HeadBranch, p.event.PullRequestNumber, p.event.TriggerCommentID, prName),
+			Labels: map[string]string{
+				keys.EventDedup:             "true",
+				"app.kubernetes.io/part-of": "pipelines-as-code",
+			},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			AcquireTime:          &now,
+			LeaseDurationSeconds: &seconds,
+		},
+	}
+	_, err := leases.Create(ctx, lease, metav1.CreateOptions{})
+	if err == nil {
+		return false, "", nil
+	}
+	if !errors.IsAlreadyExists(err) {
+		return false, "", err
+	}
+
+	existing, err := leases.Get(ctx, lease.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return false, "", err
+	}
+	if !dedupLeaseExpired(existing, now.Time) {
+		var startedBy string
+		if existing.Spec.HolderIdentity != nil {
+			startedBy = *existing.Spec.HolderIdentity
+		}
+		return true, startedBy, nil
+	}
+	// the event is delivered again after the window, for example a
+	// redelivery asked by the user, it is started again
+	existing.Spec = lease.Spec
+	if _, err := leases.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		if errors.IsConflict(err) {
+			// another delivery of the event took it in the meantime
+			return true, "", nil
+		}
+		return false, "", err
+	}
+	return false, "", nil
+}
+
+func dedupLeaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.AcquireTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return !now.Before(lease.Spec.AcquireTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+// CleanupDedupLeases deletes the leases of the events older than their
+// window, it is run periodically by the controller.
+func CleanupDedupLeases(ctx context.Context, run *params.Run, logger *zap.SugaredLogger) {
+	if run.Info.Kube == nil || run.Info.Kube.Namespace == "" {
+		return
+	}
+	leases := run.Clients.Kube.CoordinationV1().Leases(run.Info.Kube.Namespace)
+	list, err := leases.List(ctx, metav1.ListOptions{LabelSelector: keys.EventDedup + "=true"})
+	if err != nil {
+		logger.Warnf("cannot list the event deduplication leases: %s", err.Error())
+		return
+	}
+	now := time.Now()
+	for i := range list.Items {
+		if !dedupLeaseExpired(&list.Items[i], now) {
+			continue
+		}
+		if err := leases.Delete(ctx, list.Items[i].GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			logger.Warnf("cannot delete the event deduplication lease %s: %s", list.Items[i].GetName(), err.Error())
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package pipelineascode
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestSkipDuplicateEvents(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	kube := kubefake.NewSimpleClientset()
+	log, _ := logger.GetLogger()
+	newPacRun := func(installationID int64, window string) *PacRun {
+		return &PacRun{
+			event: &info.Event{
+				URL:               "https://github.com/owner/repo",
+				SHA:               "sha",
+				EventType:         "pull_request",
+				TriggerTarget:     triggertype.PullRequest,
+				BaseBranch:        "main",
+				HeadBranch:        "feature",
+				PullRequestNumber: 1,
+				InstallationID:    installationID,
+			},
+			run: &params.Run{
+				Clients: clients.Clients{Kube: kube},
+				Info: info.Info{
+					Kube:       &info.KubeOpts{Namespace: "pipelines-as-code"},
+					Controller: &info.ControllerInfo{Name: "default"},
+				},
+			},
+			pacInfo:      syncedPacOpts(t, map[string]string{"event-deduplication-window": window}),
+			logger:       log,
+			eventEmitter: events.NewEventEmitter(kube, log),
+		}
+	}
+	newMatch := func(name string) matcher.Match {
+		return matcher.Match{PipelineRun: &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{keys.OriginalPRName: name},
+		}}}
+	}
+
+	// disabled by default
+	app := newPacRun(1, "")
+	assert.Equal(t, len(app.skipDuplicateEvents(ctx, nil, []matcher.Match{newMatch("pr")})), 1)
+	assert.Equal(t, len(app.skipDuplicateEvents(ctx, nil, []matcher.Match{newMatch("pr")})), 1)
+
+	app = newPacRun(1, "5m")
+	got := app.skipDuplicateEvents(ctx, nil, []matcher.Match{newMatch("pr"), newMatch("push")})
+	assert.Equal(t, len(got), 2)
+
+	// the same event delivered by the webhook
+	webhook := newPacRun(0, "5m")
+	got = webhook.skipDuplicateEvents(ctx, nil, []matcher.Match{newMatch("pr"), newMatch("other")})
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].PipelineRun.GetAnnotations()[keys.OriginalPRName], "other")
+	duplicate, startedBy, err := webhook.isDuplicateEvent(ctx, "push")
+	assert.NilError(t, err)
+	assert.Assert(t, duplicate)
+	assert.Equal(t, startedBy, "default/github-app")
+
+	// another commit is not a duplicate
+	webhook.event.SHA = "other"
+	duplicate, _, err = webhook.isDuplicateEvent(ctx, "pr")
+	assert.NilError(t, err)
+	assert.Assert(t, !duplicate)
+
+	// the event is started again after the window
+	leases := kube.CoordinationV1().Leases("pipelines-as-code")
+	name := dedupLeaseName(app.event.URL, app.event.SHA, app.event.EventType, app.event.TriggerTarget.String(),
+		app.event.BaseBranch, app.event.HeadBranch, app.event.PullRequestNumber, 0, "pr")
+	lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+	assert.NilError(t, err)
+	past := metav1.NewMicroTime(time.Now().Add(-10 * time.Minute))
+	lease.Spec.AcquireTime = &past
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+	duplicate, _, err = newPacRun(0, "5m").isDuplicateEvent(ctx, "pr")
+	assert.NilError(t, err)
+	assert.Assert(t, !duplicate)
+}
+
+func TestSkipDuplicateEventsComments(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	kube := kubefake.NewSimpleClientset()
+	log, _ := logger.GetLogger()
+	newPacRun := func(installationID, commentID int64) *PacRun {
+		return &PacRun{
+			event: &info.Event{
+				URL:               "https://github.com/owner/repo",
+				SHA:               "sha",
+				EventType:         "retest-comment",
+				TriggerTarget:     triggertype.PullRequest,
+				PullRequestNumber: 1,
+				InstallationID:    installationID,
+				TriggerCommentID:  commentID,
+			},
+			run: &params.Run{
+				Clients: clients.Clients{Kube: kube},
+				Info:    info.Info{Kube: &info.KubeOpts{Namespace: "pipelines-as-code"}},
+			},
+			pacInfo: syncedPacOpts(t, map[string]string{"event-deduplication-window": "5m"}),
+			logger:  log,
+		}
+	}
+
+	duplicate, _, err := newPacRun(1, 1).isDuplicateEvent(ctx, "pr")
+	assert.NilError(t, err)
+	assert.Assert(t, !duplicate)
+	// the same comment delivered by the webhook
+	duplicate, _, err = newPacRun(0, 1).isDuplicateEvent(ctx, "pr")
+	assert.NilError(t, err)
+	assert.Assert(t, duplicate)
+	// another /retest comment on the same commit
+	duplicate, _, err = newPacRun(1, 2).isDuplicateEvent(ctx, "pr")
+	assert.NilError(t, err)
+	assert.Assert(t, !duplicate)
+}
+
+func TestCleanupDedupLeases(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	kube := kubefake.NewSimpleClientset()
+	log, _ := logger.GetLogger()
+	run := &params.Run{
+		Clients: clients.Clients{Kube: kube},
+		Info:    info.Info{Kube: &info.KubeOpts{Namespace: "pipelines-as-code"}},
+	}
+	newPacRun := func(sha string) *PacRun {
+		return &PacRun{
+			event:   &info.Event{SHA: sha},
+			run:     run,
+			pacInfo: syncedPacOpts(t, map[string]string{"event-deduplication-window": "1m"}),
+			logger:  log,
+		}
+	}
+	for _, sha := range []string{"old", "new"} {
+		duplicate, _, err := newPacRun(sha).isDuplicateEvent(ctx, "pr")
+		assert.NilError(t, err)
+		assert.Assert(t, !duplicate)
+	}
+	leases := kube.CoordinationV1().Leases("pipelines-as-code")
+	old, err := leases.Get(ctx, dedupLeaseName("", "old", "", "", "", "", 0, 0, "pr"), metav1.GetOptions{})
+	assert.NilError(t, err)
+	past := metav1.NewMicroTime(time.Now().Add(-2 * time.Minute))
+	old.Spec.AcquireTime = &past
+	_, err = leases.Update(ctx, old, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+	// not a lease of the deduplication
+	_, err = leases.Create(ctx, &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+		Spec:       coordinationv1.LeaseSpec{AcquireTime: &past},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+
+	CleanupDedupLeases(ctx, run, log)
+	list, err := leases.List(ctx, metav1.ListOptions{})
+	assert.NilError(t, err)
+	names := []string{}
+	for _, lease := range list.Items {
+		names = append(names, lease.GetName())
+	}
+	sort.Strings(names)
+	assert.DeepEqual(t, names, []string{"other", dedupLeaseName("", "new", "", "", "", "", 0, 0, "pr")})
+}
@@ -0,0 +1,35 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+)
+
+// rejectDeployment rejects the deployment waiting on the protection rule of
+// its environment when no PipelineRun verifies it, it would wait until its
+// timeout otherwise.
+func (p *PacRun) rejectDeployment(ctx context.Context, repo *v1alpha1.Repository, err error) {
+	if p.event.EventType != provider.DeploymentProtectionRuleEventType || p.event.DeploymentCallbackURL == "" {
+		return
+	}
+	reviewer, ok := p.vcx.(provider.DeploymentProtectionInterface)
+	if !ok {
+		return
+	}
+	environment := p.event.DeploymentEnvironment
+	comment := fmt.Sprintf("no PipelineRun of the default branch %s verifies the deployment to %s", p.event.DefaultBranch, environment)
+	if err != nil {
+		comment = fmt.Sprintf("cannot verify the deployment to %s: %s", environment, err)
+	}
+	if err := reviewer.ReviewDeployment(ctx, p.event, p.event.DeploymentCallbackURL, environment, false, comment); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "DeploymentReviewFailed",
+			fmt.Sprintf("cannot reject the deployment to %s: %s", environment, err))
+		return
+	}
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "DeploymentReviewed",
+		fmt.Sprintf("deployment to %s rejected: %s", environment, comment))
+}
@@ -0,0 +1,92 @@
+package pipelineascode
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+type deploymentReviewer struct {
+	testprovider.TestProviderImp
+	reviews  int
+	approved bool
+	comment  string
+}
+
+func (d *deploymentReviewer) ReviewDeployment(_ context.Context, _ *info.Event, _, _ string, approved bool, comment string) error {
+	d.reviews++
+	d.approved = approved
+	d.comment = comment
+	return nil
+}
+
+func TestRejectDeployment(t *testing.T) {
+	tests := []struct {
+		name        string
+		eventType   string
+		err         error
+		wantReviews int
+		wantComment string
+	}{
+		{
+			name:        "no PipelineRun verifies the deployment",
+			eventType:   provider.DeploymentProtectionRuleEventType,
+			wantReviews: 1,
+			wantComment: "no PipelineRun of the default branch main verifies the deployment to production",
+		},
+		{
+			name:        "the PipelineRuns cannot be matched",
+			eventType:   provider.DeploymentProtectionRuleEventType,
+			err:         errors.New("invalid template"),
+			wantReviews: 1,
+			wantComment: "cannot verify the deployment to production: invalid template",
+		},
+		{
+			name:      "not a deployment",
+			eventType: "push",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			logger := zap.NewNop().Sugar()
+			reviewer := &deploymentReviewer{}
+			p := &PacRun{
+				event: &info.Event{
+					EventType:             tt.eventType,
+					DefaultBranch:         "main",
+					DeploymentEnvironment: "production",
+					DeploymentCallbackURL: "https://api.github.com/repos/owner/repo/actions/runs/1/deployment_protection_rule",
+				},
+				vcx:          reviewer,
+				logger:       logger,
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+			repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+			p.rejectDeployment(ctx, repo, tt.err)
+			assert.Equal(t, reviewer.reviews, tt.wantReviews)
+			assert.Assert(t, !reviewer.approved)
+			assert.Equal(t, reviewer.comment, tt.wantComment)
+		})
+	}
+}
+
+func TestPipelineRunProvenance(t *testing.T) {
+	repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{PipelineRunProvenance: "source"}}}
+	assert.Equal(t, pipelineRunProvenance(repo, &info.Event{EventType: "push"}), "source")
+	assert.Equal(t, pipelineRunProvenance(&v1alpha1.Repository{}, &info.Event{EventType: "push"}), "source")
+	// the deployments are verified by the PipelineRuns of the default branch
+	assert.Equal(t, pipelineRunProvenance(repo, &info.Event{EventType: provider.DeploymentProtectionRuleEventType}), "default_branch")
+}
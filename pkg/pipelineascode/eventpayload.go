@@ -0,0 +1,249 @@
+package pipelineascode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/random"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	eventPayloadPrefix = "pac-event-"
+	redactedValue      = "[redacted]"
+	// maxEventPayloadSize keeps the ConfigMap below the size limit of the
+	// objects, a bigger payload is not stored.
+	maxEventPayloadSize = 900 * 1024
+
+	// EventPayloadHeadersKey is the key of the headers of the event in its ConfigMap.
+	EventPayloadHeadersKey = "headers.json"
+	// EventPayloadPayloadKey is the key of the payload of the event in its ConfigMap.
+	EventPayloadPayloadKey = "payload.json"
+	// EventPayloadEventKey is the key of the event computed by Pipelines-as-Code in its ConfigMap.
+	EventPayloadEventKey = "event.json"
+)
+
+// sensitiveKeyRe matches the headers and the payload fields redacted from a
+// stored event.
+var sensitiveKeyRe = regexp.MustCompile(`(?i)(authorization|cookie|password|secret|signature|token)`)
+
+// storeEventPayloads stores the redacted event in a ConfigMap of the
+// namespaces of the matched PipelineRuns and references it in their
+// annotations, when the event-payload-ttl setting is set. A failure is only
+// logged, the PipelineRuns still start.
+func (p *PacRun) storeEventPayloads(ctx context.Context, repo *v1alpha1.Repository, matchedPRs []matcher.Match) {
+	ttl := p.pacInfo.EventPayloadTTLDuration()
+	if ttl == 0 || len(matchedPRs) == 0 {
+		return
+	}
+	data, err := redactEvent(p.event)
+	if err != nil {
+		p.logger.Warnf("cannot redact the event payload: %s", err.Error())
+		return
+	}
+
+	names := map[string]string{}
+	for _, match := range matchedPRs {
+		mrepo := match.Repo
+		if mrepo == nil {
+			mrepo = repo
+		}
+		ns := mrepo.GetNamespace()
+		if _, ok := names[ns]; !ok {
+			name, err := p.createEventPayload(ctx, mrepo, data, ttl)
+			if err != nil {
+				p.logger.Warnf("cannot store the event payload in the namespace %s: %s", ns, err.Error())
+			}
+			names[ns] = name
+			p.cleanupEventPayloads(ctx, ns)
+		}
+		if names[ns] == "" {
+			continue
+		}
+		if match.PipelineRun.Annotations == nil {
+			match.PipelineRun.Annotations = map[string]string{}
+		}
+		match.PipelineRun.Annotations[keys.EventPayload] = names[ns]
+	}
+}
+
+func (p *PacRun) createEventPayload(ctx context.Context, repo *v1alpha1.Repository, data map[string]string, ttl time.Duration) (string, error) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      eventPayloadPrefix + strings.ToLower(random.AlphaString(8)),
+			Namespace: repo.GetNamespace(),
+			Labels: map[string]string{
+				keys.EventPayload:           "true",
+				keys.Repository:             formatting.CleanValueKubernetes(repo.GetName()),
+				"app.kubernetes.io/part-of": "pipelines-as-code",
+			},
+			Annotations: map[string]string{
+				keys.EventPayloadExpires: time.Now().Add(ttl).UTC().Format(time.RFC3339),
+				keys.EventType:           p.event.EventType,
+				keys.SHA:                 p.event.SHA,
+			},
+		},
+		Data: data,
+	}
+	created, err := p.run.Clients.Kube.CoreV1().ConfigMaps(repo.GetNamespace()).Create(ctx, cm, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return created.GetName(), nil
+}
+
+// addConfigMapOwner makes the PipelineRun an owner of the ConfigMap named by
+// the annotation, like the one of its event, the ConfigMap is deleted with the
+// last of its PipelineRuns.
+func (p *PacRun) addConfigMapOwner(ctx context.Context, pr *tektonv1.PipelineRun, annotation string) {
+	name := pr.GetAnnotations()[annotation]
+	if name == "" {
+		return
+	}
+	configMaps := p.run.Clients.Kube.CoreV1().ConfigMaps(pr.GetNamespace())
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		cm.OwnerReferences = append(cm.OwnerReferences, metav1.OwnerReference{
+			APIVersion: tektonv1.SchemeGroupVersion.String(),
+			Kind:       "PipelineRun",
+			Name:       pr.GetName(),
+			UID:        pr.GetUID(),
+		})
+		_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		p.logger.Warnf("cannot add the PipelineRun %s as owner of the ConfigMap %s: %s", pr.GetName(), name, err.Error())
+	}
+}
+
+func eventPayloadExpired(cm *corev1.ConfigMap, now time.Time) bool {
+	expires, err := time.Parse(time.RFC3339, cm.GetAnnotations()[keys.EventPayloadExpires])
+	if err != nil {
+		return true
+	}
+	return !now.Before(expires)
+}
+
+// cleanupEventPayloads deletes the ConfigMaps of the events older than their
+// TTL, the ones of the PipelineRuns still there included.
+func (p *PacRun) cleanupEventPayloads(ctx context.Context, ns string) {
+	configMaps := p.run.Clients.Kube.CoreV1().ConfigMaps(ns)
+	list, err := configMaps.List(ctx, metav1.ListOptions{LabelSelector: keys.EventPayload + "=true"})
+	if err != nil {
+		p.logger.Warnf("cannot list the event payloads of the namespace %s: %s", ns, err.Error())
+		return
+	}
+	now := time.Now()
+	for i := range list.Items {
+		if !eventPayloadExpired(&list.Items[i], now) {
+			continue
+		}
+		if err := configMaps.Delete(ctx, list.Items[i].GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			p.logger.Warnf("cannot delete the event payload %s/%s: %s", ns, list.Items[i].GetName(), err.Error())
+		}
+	}
+}
+
+// redactEvent returns the headers, the payload and the event as computed by
+// Pipelines-as-Code with the tokens, the signatures and the secrets replaced.
+func redactEvent(event *info.Event) (map[string]string, error) {
+	data := map[string]string{}
+	if event.Request != nil {
+		headers := http.Header{}
+		for name, values := range event.Request.Header {
+			if sensitiveKeyRe.MatchString(name) {
+				values = []string{redactedValue}
+			}
+			headers[name] = values
+		}
+		b, err := json.MarshalIndent(headers, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		data[EventPayloadHeadersKey] = string(b)
+
+		payload, err := redactPayload(event.Request.Payload)
+		if err != nil {
+			return nil, err
+		}
+		data[EventPayloadPayloadKey] = payload
+	}
+
+	computed := *event
+	computed.Event = nil
+	computed.Request = nil
+	if event.Provider != nil {
+		computed.Provider = &info.Provider{
+			URL:                   event.Provider.URL,
+			User:                  event.Provider.User,
+			WebhookSecretFromRepo: event.Provider.WebhookSecretFromRepo,
+		}
+		if event.Provider.Token != "" {
+			computed.Provider.Token = redactedValue
+		}
+		if event.Provider.WebhookSecret != "" {
+			computed.Provider.WebhookSecret = redactedValue
+		}
+	}
+	b, err := json.MarshalIndent(computed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data[EventPayloadEventKey] = string(b)
+	return data, nil
+}
+
+func redactPayload(payload []byte) (string, error) {
+	if len(payload) == 0 {
+		return "", nil
+	}
+	if len(payload) > maxEventPayloadSize {
+		return fmt.Sprintf(`{"error": "payload of %d bytes too big to be stored"}`, len(payload)), nil
+	}
+	var body any
+	if err := json.Unmarshal(payload, &body); err != nil {
+		// only the JSON payloads can be redacted
+		return `{"error": "payload is not JSON"}`, nil
+	}
+	b, err := json.MarshalIndent(redactValue(body), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if _, isString := field.(string); isString && sensitiveKeyRe.MatchString(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return value
+}
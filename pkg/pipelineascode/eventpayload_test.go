@@ -0,0 +1,123 @@
+package pipelineascode
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestRedactEvent(t *testing.T) {
+	event := &info.Event{
+		SHA:       "sha",
+		EventType: "push",
+		Request: &info.Request{
+			Header: http.Header{
+				"X-Github-Event":      []string{"push"},
+				"X-Hub-Signature-256": []string{"sha256=abcd"},
+				"X-Gitlab-Token":      []string{"glpat-xyz"},
+			},
+			Payload: []byte(`{"ref": "main", "secret": "incoming", "commits": [{"id": "sha", "token": "commit"}], "size": 1}`),
+		},
+		Provider: &info.Provider{Token: "provider-token", WebhookSecret: "webhook-secret", URL: "https://api.github.com"},
+	}
+	data, err := redactEvent(event)
+	assert.NilError(t, err)
+	for _, value := range data {
+		for _, secret := range []string{"abcd", "glpat-xyz", "incoming", "commit\"", "provider-token", "webhook-secret"} {
+			assert.Assert(t, !strings.Contains(value, secret), "%s found in %s", secret, value)
+		}
+	}
+	assert.Assert(t, strings.Contains(data[EventPayloadHeadersKey], `"X-Github-Event": [`))
+	assert.Assert(t, strings.Contains(data[EventPayloadPayloadKey], `"ref": "main"`))
+	assert.Assert(t, strings.Contains(data[EventPayloadPayloadKey], `"size": 1`))
+	assert.Assert(t, strings.Contains(data[EventPayloadEventKey], `"SHA": "sha"`))
+	assert.Assert(t, strings.Contains(data[EventPayloadEventKey], `"URL": "https://api.github.com"`))
+	// the event given is not modified
+	assert.Equal(t, event.Provider.Token, "provider-token")
+
+	event.Request.Payload = []byte("not json")
+	data, err = redactEvent(event)
+	assert.NilError(t, err)
+	assert.Equal(t, data[EventPayloadPayloadKey], `{"error": "payload is not JSON"}`)
+}
+
+func TestStoreEventPayloads(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	kube := kubefake.NewSimpleClientset()
+	log, _ := logger.GetLogger()
+	newPacRun := func(ttl string) *PacRun {
+		return &PacRun{
+			event: &info.Event{SHA: "sha", EventType: "pull_request", Request: &info.Request{Payload: []byte(`{}`)}},
+			run: &params.Run{
+				Clients: clients.Clients{Kube: kube},
+			},
+			pacInfo: syncedPacOpts(t, map[string]string{"event-payload-ttl": ttl}),
+			logger:  log,
+		}
+	}
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+	newMatch := func() matcher.Match {
+		return matcher.Match{PipelineRun: &tektonv1.PipelineRun{}}
+	}
+	configMaps := kube.CoreV1().ConfigMaps("ns")
+
+	// disabled by default
+	match := newMatch()
+	newPacRun("").storeEventPayloads(ctx, repo, []matcher.Match{match})
+	assert.Equal(t, match.PipelineRun.GetAnnotations()[keys.EventPayload], "")
+
+	p := newPacRun("1h")
+	first, second := newMatch(), newMatch()
+	p.storeEventPayloads(ctx, repo, []matcher.Match{first, second})
+	name := first.PipelineRun.GetAnnotations()[keys.EventPayload]
+	assert.Assert(t, strings.HasPrefix(name, eventPayloadPrefix))
+	assert.Equal(t, second.PipelineRun.GetAnnotations()[keys.EventPayload], name)
+	cm, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, cm.GetLabels()[keys.Repository], "repo")
+	assert.Equal(t, cm.Data[EventPayloadPayloadKey], "{}")
+
+	for _, prName := range []string{"pr-1", "pr-2"} {
+		pr := first.PipelineRun.DeepCopy()
+		pr.Name, pr.Namespace, pr.UID = prName, "ns", types.UID("uid-"+prName)
+		p.addConfigMapOwner(ctx, pr, keys.EventPayload)
+	}
+	cm, err = configMaps.Get(ctx, name, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(cm.OwnerReferences), 2)
+	assert.Equal(t, cm.OwnerReferences[1].Name, "pr-2")
+
+	// the expired payloads are garbage collected when a new one is stored
+	cm.Annotations[keys.EventPayloadExpires] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+	_, err = configMaps.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns"}}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	next := newMatch()
+	p.storeEventPayloads(ctx, repo, []matcher.Match{next})
+	list, err := configMaps.List(ctx, metav1.ListOptions{})
+	assert.NilError(t, err)
+	names := []string{}
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	sort.Strings(names)
+	assert.DeepEqual(t, names, []string{"other", next.PipelineRun.GetAnnotations()[keys.EventPayload]})
+}
@@ -0,0 +1,66 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/freeze"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+)
+
+const frozenTitle = "CI frozen"
+
+func isFreezeEvent(eventType string) bool {
+	return eventType == opscomments.FreezeCommentEventType.String() ||
+		eventType == opscomments.UnfreezeCommentEventType.String()
+}
+
+// activeFreezeWindow returns the freeze window in progress for a push event
+// on the branch, the other events are never frozen.
+func (p *PacRun) activeFreezeWindow(repo *v1alpha1.Repository) *freeze.Active {
+	if p.event.TriggerTarget != triggertype.Push {
+		return nil
+	}
+	return freeze.ActiveWindow(repo.Spec.Settings, p.event.BaseBranch, time.Now())
+}
+
+func freezeOverridden(repo *v1alpha1.Repository) bool {
+	return repo.Spec.Settings != nil && repo.Spec.Settings.FreezeOverride
+}
+
+// freezeRepository sets the freeze_override setting of the Repository for an
+// /unfreeze or a /freeze GitOps comment made by an admin.
+func (p *PacRun) freezeRepository(ctx context.Context, repo *v1alpha1.Repository) error {
+	override := p.event.EventType == opscomments.UnfreezeCommentEventType.String()
+	command := "/freeze"
+	msg := fmt.Sprintf("freeze windows of the repository %s/%s enforced again by %s", repo.Namespace, repo.Name, p.event.Sender)
+	if override {
+		command = "/unfreeze"
+		msg = fmt.Sprintf("freeze windows of the repository %s/%s lifted by %s", repo.Namespace, repo.Name, p.event.Sender)
+	}
+	return p.toggleRepositorySetting(ctx, repo, command, "freeze_override", freezeOverridden(repo), override,
+		func(s *v1alpha1.Settings) { s.FreezeOverride = override }, "RepositoryFreezeOverride", msg)
+}
+
+// reportFrozen acknowledges a push event during a freeze window with the
+// skip action with a neutral status, no PipelineRun gets created.
+func (p *PacRun) reportFrozen(ctx context.Context, repo *v1alpha1.Repository, window *freeze.Active) {
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryFrozen",
+		fmt.Sprintf("the %s freeze window of the repository %s/%s is in progress, skipping the PipelineRuns for %s on %s",
+			window.Name, repo.Namespace, repo.Name, p.event.BaseBranch, p.event.SHA))
+	if err := p.vcx.CreateStatus(ctx, p.event, provider.StatusOpts{
+		Status:     CompletedStatus,
+		Conclusion: neutralConclusion,
+		Title:      frozenTitle,
+		Text: fmt.Sprintf("The %s freeze window is in progress until %s, no PipelineRun has been run. An admin can lift the freeze windows with an /unfreeze comment.",
+			window.Name, window.End.Format(time.RFC1123)),
+		DetailsURL: p.event.URL,
+	}); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("cannot create status: %s", err))
+	}
+}
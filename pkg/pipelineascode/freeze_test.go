@@ -0,0 +1,123 @@
+package pipelineascode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestFreezeRepository(t *testing.T) {
+	tests := []struct {
+		name         string
+		eventType    opscomments.EventType
+		override     bool
+		policyDeny   bool
+		inOwners     bool
+		wantOverride bool
+		wantEvents   int
+	}{
+		{
+			name:         "unfreeze by an admin",
+			eventType:    opscomments.UnfreezeCommentEventType,
+			wantOverride: true,
+			wantEvents:   1,
+		},
+		{
+			name:       "unfreeze by a non admin",
+			eventType:  opscomments.UnfreezeCommentEventType,
+			policyDeny: true,
+			wantEvents: 1,
+		},
+		{
+			name:       "unfreeze by a reviewer of the owners file",
+			eventType:  opscomments.UnfreezeCommentEventType,
+			policyDeny: true,
+			inOwners:   true,
+			wantEvents: 1,
+		},
+		{
+			name:       "freeze by an admin",
+			eventType:  opscomments.FreezeCommentEventType,
+			override:   true,
+			wantEvents: 1,
+		},
+		{
+			name:      "freeze of a repository not unfrozen",
+			eventType: opscomments.FreezeCommentEventType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zap.NewNop().Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "testrepo", Namespace: "test"},
+				Spec: v1alpha1.RepositorySpec{
+					URL: "https://github.com/owner/repo",
+					Settings: &v1alpha1.Settings{
+						FreezeOverride: tt.override,
+						Policy:         &v1alpha1.Policy{Admins: []string{"ops"}},
+					},
+				},
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{Repositories: []*v1alpha1.Repository{repo}})
+			cs := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: stdata.PipelineAsCode,
+					Log:            logger,
+					Kube:           stdata.Kube,
+				},
+			}
+			event := &info.Event{Sender: "fantasio", EventType: tt.eventType.String()}
+			vcx := &testprovider.TestProviderImp{PolicyDisallowing: tt.policyDeny, AllowedInOwnersFile: tt.inOwners}
+			p := NewPacs(event, vcx, cs, &info.PacOpts{}, &kitesthelper.KinterfaceTest{}, logger, nil)
+			p.eventEmitter = events.NewEventEmitter(stdata.Kube, logger)
+
+			assert.NilError(t, p.freezeRepository(ctx, repo.DeepCopy()))
+			got, err := stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("test").Get(ctx, "testrepo", metav1.GetOptions{})
+			assert.NilError(t, err)
+			assert.Equal(t, got.Spec.Settings.FreezeOverride, tt.wantOverride)
+			list, err := stdata.Kube.CoreV1().Events("test").List(ctx, metav1.ListOptions{})
+			assert.NilError(t, err)
+			assert.Equal(t, len(list.Items), tt.wantEvents)
+		})
+	}
+}
+
+func TestActiveFreezeWindow(t *testing.T) {
+	// a window always in progress
+	repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{
+		FreezeWindows: []v1alpha1.FreezeWindow{{Name: "always", Schedule: "* * * * *", Duration: "1h", Branches: []string{"main"}}},
+	}}}
+	p := &PacRun{event: &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/main"}}
+	window := p.activeFreezeWindow(repo)
+	assert.Assert(t, window != nil)
+	assert.Equal(t, window.Name, "always")
+	assert.Assert(t, window.End.After(time.Now()))
+
+	p.event.BaseBranch = "refs/heads/feature"
+	assert.Assert(t, p.activeFreezeWindow(repo) == nil)
+
+	p.event = &info.Event{TriggerTarget: triggertype.PullRequest, BaseBranch: "main"}
+	assert.Assert(t, p.activeFreezeWindow(repo) == nil)
+
+	assert.Assert(t, isFreezeEvent(opscomments.CommentEventType("/unfreeze").String()))
+	assert.Assert(t, !isFreezeEvent(opscomments.CommentEventType("/pause").String()))
+	assert.Assert(t, freezeOverridden(&v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{FreezeOverride: true}}}))
+	assert.Assert(t, !freezeOverridden(&v1alpha1.Repository{}))
+}
@@ -0,0 +1,17 @@
+package pipelineascode
+
+import (
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/issuetracker"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// eventIssueKeys returns the keys of the issues found in the branch name, the
+// commit title and the pull request title of the event when the Repository
+// has an issue tracker, the watcher links the PipelineRuns to them once done.
+func eventIssueKeys(repo *v1alpha1.Repository, event *info.Event) ([]string, error) {
+	if repo.Spec.Settings == nil || repo.Spec.Settings.IssueTracker == nil {
+		return nil, nil
+	}
+	return issuetracker.IssueKeys(repo.Spec.Settings.IssueTracker, event.HeadBranch, event.SHATitle, event.PullRequestTitle)
+}
@@ -0,0 +1,33 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"gotest.tools/v3/assert"
+)
+
+func TestEventIssueKeys(t *testing.T) {
+	event := &info.Event{
+		HeadBranch:       "PROJ-12-fix-login",
+		SHATitle:         "OPS-7: bump the image",
+		PullRequestTitle: "PROJ-12 Fix the login",
+	}
+
+	keys, err := eventIssueKeys(&v1alpha1.Repository{}, event)
+	assert.NilError(t, err)
+	assert.Assert(t, keys == nil)
+
+	repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{
+		IssueTracker: &v1alpha1.IssueTracker{Type: "jira"},
+	}}}
+	keys, err = eventIssueKeys(repo, event)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, keys, []string{"PROJ-12", "OPS-7"})
+
+	repo.Spec.Settings.IssueTracker.KeyPattern = `OPS-[0-9]+`
+	keys, err = eventIssueKeys(repo, event)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, keys, []string{"OPS-7"})
+}
@@ -0,0 +1,139 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	"go.uber.org/zap"
+)
+
+// lintResult is the result of the validation of the templates of a pull
+// request, the names of the PipelineRuns checked and the problems found.
+type lintResult struct {
+	pipelineRuns []string
+	problems     []string
+}
+
+func (l lintResult) failed() bool {
+	return len(l.problems) > 0 || len(l.pipelineRuns) == 0
+}
+
+// report formats the result as a markdown report for the status of the pull
+// request.
+func (l lintResult) report(dirs string) string {
+	var b strings.Builder
+	if l.failed() {
+		fmt.Fprintf(&b, "The PipelineRuns in %s have some problems:\n\n", dirs)
+		for _, problem := range l.problems {
+			fmt.Fprintf(&b, "* %s\n", problem)
+		}
+		if len(l.pipelineRuns) == 0 && len(l.problems) == 0 {
+			fmt.Fprintf(&b, "* no PipelineRun found\n")
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, "All the PipelineRuns in %s are valid:\n\n", dirs)
+	for _, name := range l.pipelineRuns {
+		fmt.Fprintf(&b, "* %s\n", name)
+	}
+	return b.String()
+}
+
+// lintPipelineRuns validates the templates of the pull request for a /lint
+// GitOps comment. The templates are fetched, parsed and resolved like for a
+// run but no PipelineRun gets created, the result is reported as a status.
+func (p *PacRun) lintPipelineRuns(ctx context.Context, repo *v1alpha1.Repository) error {
+	result := p.lintTemplates(ctx, repo)
+	dirs := templatesDirs(provider.PipelineRunDirs(repo))
+
+	title, conclusion, level := "Lint succeeded", successConclusion, zap.InfoLevel
+	if result.failed() {
+		title, conclusion, level = "Lint failed", failureConclusion, zap.WarnLevel
+	}
+	p.eventEmitter.EmitMessage(repo, level, "RepositoryLint",
+		fmt.Sprintf("linted the PipelineRuns in %s on %s: %s", dirs, p.event.SHA, strings.ToLower(title)))
+	if err := p.vcx.CreateStatus(ctx, p.event, provider.StatusOpts{
+		Status:     CompletedStatus,
+		Conclusion: conclusion,
+		Title:      title,
+		Text:       result.report(dirs),
+		DetailsURL: p.event.URL,
+	}); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("cannot create status: %s", err))
+	}
+	return nil
+}
+
+// lintTemplates runs the checks done before creating the PipelineRuns on all
+// the templates of the repository: the yaml parsing, the tekton schema
+// validation, the metadata and the resolution of the tasks.
+func (p *PacRun) lintTemplates(ctx context.Context, repo *v1alpha1.Repository) lintResult {
+	result := lintResult{}
+	provenance := "source"
+	if repo.Spec.Settings != nil && repo.Spec.Settings.PipelineRunProvenance != "" {
+		provenance = repo.Spec.Settings.PipelineRunProvenance
+	}
+	tektonDirs := provider.PipelineRunDirs(repo)
+	rawTemplates, dirErrs, err := p.getTemplatesFromRepo(ctx, tektonDirs, provenance)
+	if err != nil {
+		result.problems = append(result.problems, err.Error())
+		return result
+	}
+	result.problems = append(result.problems, dirErrs...)
+	if rawTemplates == "" {
+		return result
+	}
+	if msg, needUpdate := p.checkNeedUpdate(rawTemplates); needUpdate {
+		result.problems = append(result.problems, msg)
+		return result
+	}
+
+	allTemplates := p.makeTemplate(ctx, repo, rawTemplates)
+	types, err := resolve.ReadTektonTypes(ctx, p.logger, allTemplates)
+	if err != nil {
+		result.problems = append(result.problems, err.Error())
+		return result
+	}
+	names := make([]string, 0, len(types.ValidationErrors))
+	for name := range types.ValidationErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result.problems = append(result.problems, fmt.Sprintf("%s: tekton validation error: %s", name, types.ValidationErrors[name]))
+	}
+	if len(types.PipelineRuns) == 0 {
+		return result
+	}
+
+	pipelineRuns, err := resolve.MetadataResolve(types.PipelineRuns)
+	if err != nil {
+		result.problems = append(result.problems, err.Error())
+		if len(pipelineRuns) == 0 {
+			return result
+		}
+	}
+	for _, pr := range pipelineRuns {
+		name := pr.GetName()
+		if name == "" {
+			name = pr.GetGenerateName()
+		}
+		result.pipelineRuns = append(result.pipelineRuns, name)
+		if _, _, err := resolve.ApplyTimeout(pr, p.pacInfo.MaxPipelineRunTimeoutDuration(), tektonDefaultTimeout(ctx)); err != nil {
+			result.problems = append(result.problems, err.Error())
+		}
+	}
+	types.PipelineRuns = pipelineRuns
+	if _, err := resolve.Resolve(ctx, p.run, p.logger, p.vcx, types, p.event, &resolve.Opts{
+		GenerateName: true,
+		RemoteTasks:  p.pacInfo.RemoteTasks,
+	}); err != nil {
+		result.problems = append(result.problems, err.Error())
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package pipelineascode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	ghprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestLintTemplates(t *testing.T) {
+	tests := []struct {
+		name             string
+		tektondir        string
+		pipelineRunDirs  []string
+		wantPipelineRuns []string
+		wantProblems     []string
+	}{
+		{
+			name:             "valid pipelineruns",
+			tektondir:        "testdata/pull_request",
+			wantPipelineRuns: []string{"pull_request"},
+		},
+		{
+			name:             "pipelineruns not matching the event are linted too",
+			tektondir:        "testdata/no-match",
+			wantPipelineRuns: []string{"pull_request-1", "pull_request-2", "no-match"},
+		},
+		{
+			name:         "schema validation error",
+			tektondir:    "testdata/invalid_tekton_yaml",
+			wantProblems: []string{"bad-tekton-yaml: tekton validation error: json: cannot unmarshal object"},
+		},
+		{
+			name:             "unresolved pipeline",
+			tektondir:        "testdata/lint_unresolved",
+			wantPipelineRuns: []string{"lint-unresolved"},
+			wantProblems:     []string{"cannot find referenced pipeline missing-pipeline"},
+		},
+		{
+			name:            "no templates",
+			tektondir:       "testdata/multiple_dirs",
+			pipelineRunDirs: []string{"ci"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observerCore, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observerCore).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+
+			event := &info.Event{
+				SHA:           "principale",
+				Organization:  "organizationes",
+				Repository:    "lagaffe",
+				URL:           "https://service/documentation",
+				HeadBranch:    "main",
+				BaseBranch:    "main",
+				Sender:        "fantasio",
+				EventType:     "lint-comment",
+				TriggerTarget: "pull_request",
+				Provider:      &info.Provider{},
+			}
+			ghtesthelper.SetupGitTree(t, mux, tt.tektondir, event, false)
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "testrepo", Namespace: "test"},
+			}
+			if tt.pipelineRunDirs != nil {
+				repo.Spec.Settings = &v1alpha1.Settings{PipelineRunDirs: tt.pipelineRunDirs}
+			}
+
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			cs := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: stdata.PipelineAsCode,
+					Log:            logger,
+					Kube:           stdata.Kube,
+					Tekton:         stdata.Pipeline,
+				},
+			}
+			cs.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+			vcx := &ghprovider.Provider{
+				Client: fakeclient,
+				Token:  github.String("None"),
+				Logger: logger,
+			}
+			assert.NilError(t, vcx.SetClient(ctx, cs, event, repo, nil))
+			p := NewPacs(event, vcx, cs, &info.PacOpts{}, &kitesthelper.KinterfaceTest{}, logger, nil)
+			p.eventEmitter = events.NewEventEmitter(stdata.Kube, logger)
+
+			result := p.lintTemplates(ctx, repo)
+			assert.DeepEqual(t, result.pipelineRuns, tt.wantPipelineRuns)
+			assert.Equal(t, len(result.problems), len(tt.wantProblems), result.problems)
+			for i, want := range tt.wantProblems {
+				assert.Assert(t, strings.Contains(result.problems[i], want), result.problems[i])
+			}
+			assert.Equal(t, result.failed(), len(tt.wantProblems) > 0 || len(tt.wantPipelineRuns) == 0)
+		})
+	}
+}
+
+func TestLintReport(t *testing.T) {
+	report := lintResult{pipelineRuns: []string{"pr1", "pr2"}}.report(".tekton/")
+	assert.Equal(t, report, "All the PipelineRuns in .tekton/ are valid:\n\n* pr1\n* pr2\n")
+
+	report = lintResult{pipelineRuns: []string{"pr1"}, problems: []string{"pr1: bad"}}.report(".tekton/")
+	assert.Equal(t, report, "The PipelineRuns in .tekton/ have some problems:\n\n* pr1: bad\n")
+
+	report = lintResult{}.report(".tekton/")
+	assert.Equal(t, report, "The PipelineRuns in .tekton/ have some problems:\n\n* no PipelineRun found\n")
+}
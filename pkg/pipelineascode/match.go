@@ -8,9 +8,12 @@ import (
 
 	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/freeze"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/policy"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
 
@@ -27,46 +30,138 @@ func (p *PacRun) matchRepoPR(ctx context.Context) ([]matcher.Match, *v1alpha1.Re
 		return nil, nil, err
 	}
 	if repo == nil {
+		p.skipped(skipReasonNoRepository)
 		return nil, nil, nil
 	}
 
+	if !p.authorizeGitOpsCommand(ctx, repo) {
+		p.skipped(skipReasonPolicyDenied)
+		return nil, repo, nil
+	}
+
+	// the comments not matching a builtin command are acknowledged once
+	// matched to a PipelineRun and allowed, see getPipelineRunsFromRepo
+	if p.event.EventType != opscomments.NoOpsCommentEventType.String() && p.event.EventType != opscomments.OnCommentEventType.String() {
+		p.acknowledgeComment(ctx, repo)
+	}
+
+	if p.event.PullRequestClosed {
+		p.deactivatePreviewEnvironment(ctx, repo)
+		p.skipped(skipReasonPullRequestClosed)
+		return nil, repo, nil
+	}
+
+	// the commands not running PipelineRuns
+	if p.event.CancelPipelineRuns || p.event.EventType == opscomments.LintCommentEventType.String() ||
+		isPauseResumeEvent(p.event.EventType) || isFreezeEvent(p.event.EventType) {
+		p.skipped(skipReasonGitOpsCommand)
+	}
 	if p.event.CancelPipelineRuns {
 		return nil, repo, p.cancelPipelineRuns(ctx, repo)
 	}
 
+	if p.event.EventType == opscomments.LintCommentEventType.String() {
+		return nil, repo, p.lintPipelineRuns(ctx, repo)
+	}
+
+	if isPauseResumeEvent(p.event.EventType) {
+		return nil, repo, p.pauseResumeRepository(ctx, repo)
+	}
+
+	if isFreezeEvent(p.event.EventType) {
+		return nil, repo, p.freezeRepository(ctx, repo)
+	}
+
+	if isPaused(repo) {
+		p.reportPaused(ctx, repo)
+		p.skipped(skipReasonPaused)
+		return nil, repo, nil
+	}
+
+	if window := p.activeFreezeWindow(repo); window != nil {
+		if window.Action == freeze.ActionSkip {
+			p.reportFrozen(ctx, repo, window)
+			p.skipped(skipReasonFrozen)
+			return nil, repo, nil
+		}
+		p.freezeWindow = window
+	}
+
+	if reason := skipCIDirective(repo, p.event); reason != "" {
+		p.reportSkippedByDirective(ctx, repo, reason)
+		p.skipped(skipReasonSkipCI)
+		return nil, repo, nil
+	}
+	if reason := unsignedCommitRefused(repo, p.event); reason != "" {
+		p.reportUnsignedCommit(ctx, repo, reason)
+		p.skipped(skipReasonUnsignedCommit)
+		return nil, repo, nil
+	}
+	_, p.rebuildOnBaseUpdate = rebuildBranch(repo, p.event)
+
 	matchedPRs, err := p.getPipelineRunsFromRepo(ctx, repo)
 	if err != nil {
 		return nil, repo, err
 	}
+	matchedPRs = p.filtered(matchedPRs, filterRebuildPipelineRuns(p.event, matchedPRs), skipReasonNoMatch)
+	matchedPRs = p.filtered(matchedPRs, p.filterIgnoredSender(repo, matchedPRs), skipReasonIgnoredSender)
+	matchedPRs = p.filtered(matchedPRs, p.filterApprovals(ctx, repo, matchedPRs), skipReasonPendingApprovals)
+	matchedPRs = p.filtered(matchedPRs, p.filterSecretScanning(ctx, repo, matchedPRs), skipReasonSecretsDetected)
+	matchedPRs = p.filtered(matchedPRs, p.applyServiceAccounts(repo, matchedPRs), skipReasonServiceAccount)
+	p.applyTimeouts(ctx, repo, matchedPRs)
 	return matchedPRs, repo, nil
 }
 
-// verifyRepoAndUser verifies if the Repo CR exists for the Git Repository,
-// if the user has permission to run CI  and also initialise provider client.
-func (p *PacRun) verifyRepoAndUser(ctx context.Context) (*v1alpha1.Repository, error) {
+// ValidateEvent checks the payload of the event with the webhook secret of
+// its Repository without processing it, for the events buffered during a
+// maintenance.
+func (p *PacRun) ValidateEvent(ctx context.Context) error {
+	repo, secretNS, err := p.matchRepository(ctx)
+	if err != nil {
+		return err
+	}
+	if repo == nil {
+		return fmt.Errorf("cannot find a repository match for %s", p.event.URL)
+	}
+	return p.validatePayload(ctx, repo, secretNS)
+}
+
+// matchRepository matches the URL of the event to a Repository, merges the
+// settings of its defaults in it and returns the namespace of its secrets.
+func (p *PacRun) matchRepository(ctx context.Context) (*v1alpha1.Repository, string, error) {
 	// Match the Event URL to a Repository URL,
 	repo, err := matcher.MatchEventURLRepo(ctx, p.run, p.event, "")
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	if repo == nil {
 		msg := fmt.Sprintf("cannot find a repository match for %s", p.event.URL)
 		p.eventEmitter.EmitMessage(nil, zap.WarnLevel, "RepositoryNamespaceMatch", msg)
-		return nil, nil
+		return nil, "", nil
 	}
 
-	secretNS := repo.GetNamespace()
-	if repo.Spec.GitProvider != nil && repo.Spec.GitProvider.Secret == nil && p.globalRepo.Spec.GitProvider != nil && p.globalRepo.Spec.GitProvider.Secret != nil {
-		secretNS = p.globalRepo.GetNamespace()
+	// the settings not defined in the Repository come from the most specific
+	// org defaults and then from the global repository
+	orgDefaults, err := matcher.GetOrgDefaults(ctx, p.run)
+	if err != nil {
+		p.logger.Warnf("cannot get the org defaults repositories: %s", err.Error())
 	}
+	defaults := v1alpha1.OrgDefaultsFor(repo.Spec.URL, repo.GetNamespace(), orgDefaults)
 	if p.globalRepo != nil {
-		repo.Spec.Merge(p.globalRepo.Spec)
+		defaults = append(defaults, p.globalRepo)
 	}
+	secretNS := repo.MergeDefaults(defaults...)
 
 	p.logger = p.logger.With("namespace", repo.Namespace)
 	p.vcx.SetLogger(p.logger)
 	p.eventEmitter.SetLogger(p.logger)
+	return repo, secretNS, nil
+}
+
+// validatePayload gets the webhook secret of the Repository and validates the
+// payload of the event with it.
+func (p *PacRun) validatePayload(ctx context.Context, repo *v1alpha1.Repository, secretNS string) error {
 	// If we have a git_provider field in repository spec, then get all the
 	// information from there, including the webhook secret.
 	// otherwise get the secret from the current ns (i.e: pipelines-as-code/openshift-pipelines.)
@@ -89,7 +184,7 @@ func (p *PacRun) verifyRepoAndUser(ctx context.Context) (*v1alpha1.Repository, e
 			Namespace:   secretNS,
 		}
 		if err := scm.Get(ctx); err != nil {
-			return repo, fmt.Errorf("cannot get secret from repository: %w", err)
+			return fmt.Errorf("cannot get secret from repository: %w", err)
 		}
 	}
 
@@ -104,9 +199,22 @@ it seems that we have detected a \n or a space at the end of your webhook secret
 is that what you want? make sure you use -n when generating the secret, eg: echo -n secret|base64`
 				p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositorySecretValidation", msg)
 			}
-			return repo, fmt.Errorf("could not validate payload, check your webhook secret?: %w", err)
+			return fmt.Errorf("could not validate payload, check your webhook secret?: %w", err)
 		}
 	}
+	return nil
+}
+
+// verifyRepoAndUser verifies if the Repo CR exists for the Git Repository,
+// if the user has permission to run CI  and also initialise provider client.
+func (p *PacRun) verifyRepoAndUser(ctx context.Context) (*v1alpha1.Repository, error) {
+	repo, secretNS, err := p.matchRepository(ctx)
+	if err != nil || repo == nil {
+		return nil, err
+	}
+	if err := p.validatePayload(ctx, repo, secretNS); err != nil {
+		return repo, err
+	}
 
 	// Set the client, we should error out if there is a problem with
 	// token or secret or we won't be able to do much.
@@ -133,9 +241,7 @@ is that what you want? make sure you use -n when generating the secret, eg: echo
 	}
 
 	// Check if the submitter is allowed to run this.
-	// on push we don't need to check the policy since the user has pushed to the repo so it has access to it.
-	// on comment we skip it for now, we are going to check later on
-	if p.event.TriggerTarget != triggertype.Push && p.event.EventType != opscomments.NoOpsCommentEventType.String() {
+	if needsAccessCheck(p.event) {
 		if allowed, err := p.checkAccessOrErrror(ctx, repo, "via "+p.event.TriggerTarget.String()); !allowed {
 			return nil, err
 		}
@@ -143,26 +249,53 @@ is that what you want? make sure you use -n when generating the secret, eg: echo
 	return repo, nil
 }
 
+// needsAccessCheck returns true if the sender of the event has to be allowed
+// to run the CI before matching the PipelineRuns.
+// on push we don't need to check the policy since the user has pushed to the repo so it has access to it,
+// but anyone able to comment on a commit has not, so the GitOps comments on a commit are checked.
+// on comment we skip it for now, we are going to check later on
+// on a closed pull request we only clean up, nothing gets run.
+func needsAccessCheck(event *info.Event) bool {
+	if event.TriggerTarget == triggertype.Push && !opscomments.IsCommitComment(event) {
+		return false
+	}
+	return event.EventType != opscomments.NoOpsCommentEventType.String() && !event.PullRequestClosed
+}
+
+// pipelineRunProvenance returns where the PipelineRuns of the event are read
+// from. The PipelineRuns verifying a deployment are always the ones of the
+// default branch, the commit deployed can't choose how it is verified.
+func pipelineRunProvenance(repo *v1alpha1.Repository, event *info.Event) string {
+	if event.EventType == provider.DeploymentProtectionRuleEventType {
+		return "default_branch"
+	}
+	if repo.Spec.Settings != nil && repo.Spec.Settings.PipelineRunProvenance != "" {
+		return repo.Spec.Settings.PipelineRunProvenance
+	}
+	return "source"
+}
+
 // getPipelineRunsFromRepo fetches pipelineruns from git repository and prepare them for creation.
 func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Repository) ([]matcher.Match, error) {
-	provenance := "source"
-	if repo.Spec.Settings != nil && repo.Spec.Settings.PipelineRunProvenance != "" {
-		provenance = repo.Spec.Settings.PipelineRunProvenance
-	}
-	rawTemplates, err := p.vcx.GetTektonDir(ctx, p.event, tektonDir, provenance)
-	if err != nil && strings.Contains(err.Error(), "error unmarshalling yaml file") {
-		// make the error a bit more friendly for users who don't know what marshalling or intricacies of the yaml parser works
-		errmsg := err.Error()
-		errmsg = strings.ReplaceAll(errmsg, " error converting YAML to JSON: yaml:", "")
-		errmsg = strings.ReplaceAll(errmsg, "unmarshalling", "while parsing the")
-		return nil, fmt.Errorf(errmsg)
-	}
-	if err != nil || rawTemplates == "" {
-		msg := fmt.Sprintf("cannot locate templates in %s/ directory for this repository in %s", tektonDir, p.event.HeadBranch)
-		if err != nil {
-			msg += fmt.Sprintf(" err: %s", err.Error())
+	// /retest --exact re-runs the snapshots of the last PipelineRuns, not
+	// the templates of the repository
+	if p.event.ExactRetest {
+		return p.getPipelineRunsFromSnapshots(ctx, repo)
+	}
+
+	provenance := pipelineRunProvenance(repo, p.event)
+	tektonDirs := provider.PipelineRunDirs(repo)
+	rawTemplates, dirErrs, err := p.getCachedTemplatesFromRepo(ctx, repo, tektonDirs, provenance)
+	if err != nil {
+		return nil, err
+	}
+	if rawTemplates == "" {
+		msg := fmt.Sprintf("cannot locate templates in %s directory for this repository in %s", templatesDirs(tektonDirs), p.event.HeadBranch)
+		if len(dirErrs) > 0 {
+			msg += fmt.Sprintf(" err: %s", strings.Join(dirErrs, ", "))
 		}
 		p.eventEmitter.EmitMessage(nil, zap.InfoLevel, "RepositoryPipelineRunNotFound", msg)
+		p.skipped(skipReasonNoPipelineRun)
 		return nil, nil
 	}
 
@@ -179,7 +312,11 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 	}
 
 	// Replace those {{var}} placeholders user has in her template to the run.Info variable
-	allTemplates := p.makeTemplate(ctx, repo, rawTemplates)
+	// as well as in the values of the labels and annotations to add to the PipelineRuns
+	metadata := newPipelineRunMetadata(repo)
+	expanded := p.makeTemplates(ctx, repo, metadata.params(ctx, p), append([]string{rawTemplates}, metadata.values...)...)
+	allTemplates := expanded[0]
+	metadataLabels, metadataAnnotations := metadata.expand(expanded[1:])
 
 	types, err := resolve.ReadTektonTypes(ctx, p.logger, allTemplates)
 	if err != nil {
@@ -194,8 +331,9 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 	}
 	pipelineRuns := types.PipelineRuns
 	if len(pipelineRuns) == 0 {
-		msg := fmt.Sprintf("cannot locate templates in %s/ directory for this repository in %s", tektonDir, p.event.HeadBranch)
+		msg := fmt.Sprintf("cannot locate templates in %s directory for this repository in %s", templatesDirs(tektonDirs), p.event.HeadBranch)
 		p.eventEmitter.EmitMessage(nil, zap.InfoLevel, "RepositoryCannotLocatePipelineRun", msg)
+		p.skipped(skipReasonNoPipelineRun)
 		return nil, nil
 	}
 
@@ -204,6 +342,16 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "FailedToResolvePipelineRunMetadata", err.Error())
 		return nil, err
 	}
+	if err := resolve.PropagateMetadata(pipelineRuns, metadataLabels, metadataAnnotations); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositoryPipelineRunMetadata", err.Error())
+	}
+	p.injectPipelineRunEnv(repo, pipelineRuns)
+
+	// the files of the pac-ignore files are excluded from the path-change
+	// matching of all the PipelineRuns
+	if matchesOnChangedFiles(pipelineRuns) {
+		p.event.IgnoredPaths = p.getIgnoredPaths(ctx, repo, tektonDirs, provenance)
+	}
 
 	// Match the PipelineRun with annotation
 	var matchedPRs []matcher.Match
@@ -221,6 +369,7 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 		if allowed, err := p.checkAccessOrErrror(ctx, repo, "by gitops comment"); !allowed {
 			return nil, err
 		}
+		p.acknowledgeComment(ctx, repo)
 	}
 
 	// if event type is incoming then filter out the pipelineruns related to incoming event
@@ -256,7 +405,7 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 				}
 			}
 		}
-		pipelineRuns, err = resolve.Resolve(ctx, p.run, p.logger, p.vcx, types, p.event, &resolve.Opts{
+		pipelineRuns, err = p.resolveCached(ctx, repo, p.templatesCacheKey(repo, tektonDirs, provenance), allTemplates, types, &resolve.Opts{
 			GenerateName: true,
 			RemoteTasks:  true,
 		})
@@ -266,10 +415,19 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 		}
 	}
 
+	p.recordSnapshots(pipelineRuns)
 	err = changeSecret(pipelineRuns)
 	if err != nil {
 		return nil, err
 	}
+	if repo.Spec.Settings != nil && repo.Spec.Settings.CloudCredentials != nil {
+		if err := changeCloudCredentialsSecret(pipelineRuns); err != nil {
+			return nil, err
+		}
+	}
+	if err := changeSignedEventSecret(pipelineRuns); err != nil {
+		return nil, err
+	}
 	// if we are doing explicit /test command then we only want to run the one that has matched the /test
 	if p.event.TargetTestPipelineRun != "" {
 		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryMatchedPipelineRun", fmt.Sprintf("explicit testing via /test of PipelineRun %s", p.event.TargetTestPipelineRun))
@@ -290,6 +448,42 @@ func (p *PacRun) getPipelineRunsFromRepo(ctx context.Context, repo *v1alpha1.Rep
 	return matchedPRs, nil
 }
 
+// getTemplatesFromRepo fetches and concatenates the templates of the
+// directories, the errors of the directories that cannot be fetched are
+// returned in dirErrs.
+func (p *PacRun) getTemplatesFromRepo(ctx context.Context, tektonDirs []string, provenance string) (string, []string, error) {
+	var rawTemplates string
+	var dirErrs []string
+	for _, tektonDir := range tektonDirs {
+		templates, err := p.vcx.GetTektonDir(ctx, p.event, tektonDir, provenance)
+		if err != nil && strings.Contains(err.Error(), "error unmarshalling yaml file") {
+			// make the error a bit more friendly for users who don't know what marshalling or intricacies of the yaml parser works
+			errmsg := err.Error()
+			errmsg = strings.ReplaceAll(errmsg, " error converting YAML to JSON: yaml:", "")
+			errmsg = strings.ReplaceAll(errmsg, "unmarshalling", "while parsing the")
+			return "", nil, fmt.Errorf(errmsg)
+		}
+		if err != nil {
+			dirErrs = append(dirErrs, err.Error())
+			continue
+		}
+		if templates == "" {
+			continue
+		}
+		if rawTemplates != "" && !strings.HasPrefix(strings.TrimSpace(templates), "---") {
+			rawTemplates += "---"
+		}
+		rawTemplates += templates
+	}
+	return rawTemplates, dirErrs, nil
+}
+
+// templatesDirs formats the directories where the PipelineRuns are looked
+// up for the messages, ie: ".tekton/" or ".ci/, ci/tekton/".
+func templatesDirs(dirs []string) string {
+	return strings.Join(dirs, "/, ") + "/"
+}
+
 func filterRunningPipelineRunOnTargetTest(testPipeline string, prs []*tektonv1.PipelineRun) *tektonv1.PipelineRun {
 	for _, pr := range prs {
 		if prName, ok := pr.GetAnnotations()[apipac.OriginalPRName]; ok {
@@ -344,6 +538,13 @@ func (p *PacRun) checkNeedUpdate(_ string) (string, bool) {
 }
 
 func (p *PacRun) checkAccessOrErrror(ctx context.Context, repo *v1alpha1.Repository, viamsg string) (bool, error) {
+	// the pull requests of the trusted bots don't need an /ok-to-test, the
+	// comments are still checked against the commenter
+	if p.event.TriggerTarget == triggertype.PullRequest && !isGitOpsComment(p.event) && policy.IsTrustedBot(repo, p.event.Sender) {
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "PolicyTrustedBot",
+			fmt.Sprintf("policy check: sender %s is a trusted bot, allowed to run CI %s", p.event.Sender, viamsg))
+		return true, nil
+	}
 	allowed, err := p.vcx.IsAllowed(ctx, p.event)
 	if err != nil {
 		return false, err
@@ -356,6 +557,7 @@ func (p *PacRun) checkAccessOrErrror(ctx context.Context, repo *v1alpha1.Reposit
 		msg = fmt.Sprintf("User: %s AccountID: %s is not allowed to trigger CI %s on this repo.", p.event.Sender, p.event.AccountID, viamsg)
 	}
 	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPermissionDenied", msg)
+	p.skipped(skipReasonPolicyDenied)
 	status := provider.StatusOpts{
 		Status:     queuedStatus,
 		Title:      "Pending approval",
@@ -9,14 +9,17 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	ghprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
 	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
 	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
 	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"go.uber.org/zap"
 	zapobserver "go.uber.org/zap/zaptest/observer"
@@ -145,6 +148,58 @@ func TestGetPipelineRunsFromRepo(t *testing.T) {
 			expectedNumberOfPruns: 1,
 			event:                 pullRequestEvent,
 		},
+		{
+			name: "pipelineruns in multiple directories",
+			repositories: &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testrepo",
+					Namespace: "test",
+				},
+				Spec: v1alpha1.RepositorySpec{
+					Settings: &v1alpha1.Settings{
+						PipelineRunDirs: []string{".tekton", ".ci/", "notthere"},
+					},
+				},
+			},
+			tektondir:             "testdata/multiple_dirs",
+			expectedNumberOfPruns: 2,
+			event:                 pullRequestEvent,
+		},
+		{
+			name: "pipelineruns in multiple directories filtered by extension",
+			repositories: &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testrepo",
+					Namespace: "test",
+				},
+				Spec: v1alpha1.RepositorySpec{
+					Settings: &v1alpha1.Settings{
+						PipelineRunDirs:       []string{".tekton", ".ci"},
+						PipelineRunExtensions: []string{".yaml"},
+					},
+				},
+			},
+			tektondir:             "testdata/multiple_dirs",
+			expectedNumberOfPruns: 1,
+			event:                 pullRequestEvent,
+		},
+		{
+			name: "no pipelineruns in the directories",
+			repositories: &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testrepo",
+					Namespace: "test",
+				},
+				Spec: v1alpha1.RepositorySpec{
+					Settings: &v1alpha1.Settings{
+						PipelineRunDirs: []string{"ci", "notthere"},
+					},
+				},
+			},
+			tektondir:  "testdata/multiple_dirs",
+			event:      pullRequestEvent,
+			logSnippet: "cannot locate templates in ci/, notthere/ directory for this repository in main",
+		},
 		{
 			name: "invalid tekton pipelineruns in directory",
 			repositories: &v1alpha1.Repository{
@@ -225,6 +280,10 @@ func TestGetPipelineRunsFromRepo(t *testing.T) {
 					RemoteTasks:        true,
 				},
 			}
+			if tt.event.Provider == nil {
+				tt.event.Provider = &info.Provider{}
+			}
+			assert.NilError(t, vcx.SetClient(ctx, cs, tt.event, tt.repositories, nil))
 			p := NewPacs(tt.event, vcx, cs, pacInfo, k8int, logger, nil)
 			p.eventEmitter = events.NewEventEmitter(stdata.Kube, logger)
 			matchedPRs, err := p.getPipelineRunsFromRepo(ctx, tt.repositories)
@@ -240,3 +299,85 @@ func TestGetPipelineRunsFromRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckAccessTrustedBot(t *testing.T) {
+	repo := &v1alpha1.Repository{
+		Spec: v1alpha1.RepositorySpec{
+			Settings: &v1alpha1.Settings{
+				Policy: &v1alpha1.Policy{TrustedBots: []string{"dependabot[bot]"}},
+			},
+		},
+	}
+	tests := []struct {
+		name  string
+		event *info.Event
+		want  bool
+	}{
+		{
+			name:  "pull request of a trusted bot",
+			event: &info.Event{Sender: "dependabot[bot]", TriggerTarget: "pull_request", EventType: "pull_request"},
+			want:  true,
+		},
+		{
+			name:  "pull request of another sender",
+			event: &info.Event{Sender: "someone", TriggerTarget: "pull_request", EventType: "pull_request"},
+		},
+		{
+			name:  "gitops comment of a trusted bot",
+			event: &info.Event{Sender: "dependabot[bot]", TriggerTarget: "pull_request", EventType: "retest-all-comment"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			p := &PacRun{
+				event:        tt.event,
+				vcx:          &testprovider.TestProviderImp{},
+				logger:       logger,
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+			allowed, err := p.checkAccessOrErrror(ctx, repo, "via test")
+			assert.NilError(t, err)
+			assert.Equal(t, allowed, tt.want)
+		})
+	}
+}
+
+func TestNeedsAccessCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *info.Event
+		want  bool
+	}{
+		{
+			name:  "push",
+			event: &info.Event{TriggerTarget: triggertype.Push, EventType: "push"},
+		},
+		{
+			name:  "gitops comment on a commit",
+			event: &info.Event{TriggerTarget: triggertype.Push, EventType: "retest-all-comment", TriggerComment: "/retest"},
+			want:  true,
+		},
+		{
+			name:  "pull request",
+			event: &info.Event{TriggerTarget: triggertype.PullRequest, EventType: "pull_request"},
+			want:  true,
+		},
+		{
+			name:  "comment not matching a command",
+			event: &info.Event{TriggerTarget: triggertype.PullRequest, EventType: opscomments.NoOpsCommentEventType.String()},
+		},
+		{
+			name:  "closed pull request",
+			event: &info.Event{TriggerTarget: triggertype.PullRequest, EventType: "pull_request", State: info.State{PullRequestClosed: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, needsAccessCheck(tt.event), tt.want)
+		})
+	}
+}
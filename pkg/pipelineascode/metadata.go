@@ -0,0 +1,139 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	tektonconfig "github.com/tektoncd/pipeline/pkg/apis/config"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+)
+
+const senderTeamParam = "sender_team"
+
+// pipelineRunMetadata holds the labels and the annotations of the
+// pipelinerun_labels and pipelinerun_annotations settings in the order of
+// their values, so they can be expanded with the templates.
+type pipelineRunMetadata struct {
+	labelKeys      []string
+	annotationKeys []string
+	values         []string
+}
+
+func newPipelineRunMetadata(repo *v1alpha1.Repository) *pipelineRunMetadata {
+	m := &pipelineRunMetadata{}
+	if repo.Spec.Settings == nil {
+		return m
+	}
+	for key, value := range repo.Spec.Settings.PipelineRunLabels {
+		m.labelKeys = append(m.labelKeys, key)
+		m.values = append(m.values, value)
+	}
+	for key, value := range repo.Spec.Settings.PipelineRunAnnotations {
+		m.annotationKeys = append(m.annotationKeys, key)
+		m.values = append(m.values, value)
+	}
+	return m
+}
+
+// expand returns the labels and the annotations with the values expanded.
+func (m *pipelineRunMetadata) expand(values []string) (map[string]string, map[string]string) {
+	labels, annotations := map[string]string{}, map[string]string{}
+	for i, key := range m.labelKeys {
+		labels[key] = values[i]
+	}
+	for i, key := range m.annotationKeys {
+		annotations[key] = values[len(m.labelKeys)+i]
+	}
+	return labels, annotations
+}
+
+// params returns the params only used by the metadata: the sender_team, the
+// first of the teams of the OWNERS_ALIASES file of the default branch the
+// sender belongs to, only fetched when a value uses it.
+func (m *pipelineRunMetadata) params(ctx context.Context, p *PacRun) map[string]string {
+	usesTeam := false
+	for _, value := range m.values {
+		if strings.Contains(value, senderTeamParam) {
+			usesTeam = true
+			break
+		}
+	}
+	if !usesTeam || p.event.Sender == "" {
+		return nil
+	}
+	aliases, err := p.vcx.GetFileInsideRepo(ctx, p.event, "OWNERS_ALIASES", p.event.DefaultBranch)
+	if err != nil {
+		p.logger.Infof("cannot get the OWNERS_ALIASES file for the %s param: %v", senderTeamParam, err)
+		return nil
+	}
+	teams, err := acl.SenderTeams(aliases, p.event.Sender)
+	if err != nil {
+		p.eventEmitter.EmitMessage(nil, zap.WarnLevel, "RepositoryInvalidOwnersAliases", "cannot parse the OWNERS_ALIASES file: "+err.Error())
+		return nil
+	}
+	if len(teams) == 0 {
+		return nil
+	}
+	return map[string]string{senderTeamParam: teams[0]}
+}
+
+// injectPipelineRunEnv injects the environment variables of the
+// pipelinerun-env setting in the PipelineRuns, minus the ones the Repository
+// opted out of.
+func (p *PacRun) injectPipelineRunEnv(repo *v1alpha1.Repository, prs []*tektonv1.PipelineRun) {
+	if p.pacInfo == nil || len(p.pacInfo.Parsed.PipelineRunEnv) == 0 {
+		return
+	}
+	var skip []string
+	if repo.Spec.Settings != nil {
+		skip = repo.Spec.Settings.SkipPipelineRunEnv
+	}
+	resolve.InjectEnv(prs, p.pacInfo.Parsed.PipelineRunEnv, skip)
+}
+
+// applyTimeouts sets the timeout of the matched PipelineRuns from their
+// timeout annotation, clamped to the max-pipelinerun-timeout setting. The
+// note added to the status of the clamped PipelineRuns is kept by their
+// original name.
+func (p *PacRun) applyTimeouts(ctx context.Context, repo *v1alpha1.Repository, matchedPRs []matcher.Match) {
+	maxTimeout := time.Duration(0)
+	if p.pacInfo != nil {
+		maxTimeout = p.pacInfo.MaxPipelineRunTimeoutDuration()
+	}
+	defaultTimeout := tektonDefaultTimeout(ctx)
+	for _, match := range matchedPRs {
+		pr := match.PipelineRun
+		requested, clamped, err := resolve.ApplyTimeout(pr, maxTimeout, defaultTimeout)
+		if err != nil {
+			p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositoryInvalidTimeout", fmt.Sprintf("%s, ignoring it", err))
+		}
+		if !clamped {
+			continue
+		}
+		asked := requested.String()
+		if requested == 0 {
+			asked = "no timeout"
+		}
+		note := fmt.Sprintf("The timeout of the PipelineRun has been limited to %s, the maximum allowed by the administrator, instead of %s.", maxTimeout, asked)
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryTimeoutClamped",
+			fmt.Sprintf("the timeout of the PipelineRun %s has been limited to %s instead of %s", pr.GetAnnotations()[keys.OriginalPRName], maxTimeout, asked))
+		if p.timeoutNotes == nil {
+			p.timeoutNotes = map[string]string{}
+		}
+		p.timeoutNotes[pr.GetAnnotations()[keys.OriginalPRName]] = note
+	}
+}
+
+// tektonDefaultTimeout returns the timeout Tekton gives to the PipelineRuns
+// without any.
+func tektonDefaultTimeout(ctx context.Context) time.Duration {
+	return time.Duration(tektonconfig.FromContextOrDefaults(ctx).Defaults.DefaultTimeoutMinutes) * time.Minute
+}
@@ -0,0 +1,152 @@
+package pipelineascode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestPipelineRunMetadata(t *testing.T) {
+	repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{
+		PipelineRunLabels:      map[string]string{"example.com/team": "{{ sender_team }}", "cost-center": "ci"},
+		PipelineRunAnnotations: map[string]string{"example.com/sender": "{{ sender }}"},
+	}}}
+	metadata := newPipelineRunMetadata(repo)
+	assert.Equal(t, len(metadata.values), 3)
+
+	// expand with the values in the same order
+	expanded := make([]string, 0, len(metadata.values))
+	for _, value := range metadata.values {
+		expanded = append(expanded, "expanded "+value)
+	}
+	labels, annotations := metadata.expand(expanded)
+	assert.DeepEqual(t, labels, map[string]string{"example.com/team": "expanded {{ sender_team }}", "cost-center": "expanded ci"})
+	assert.DeepEqual(t, annotations, map[string]string{"example.com/sender": "expanded {{ sender }}"})
+
+	empty := newPipelineRunMetadata(&v1alpha1.Repository{})
+	labels, annotations = empty.expand(nil)
+	assert.Equal(t, len(labels)+len(annotations), 0)
+}
+
+func TestPipelineRunMetadataSenderTeam(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		files  map[string]string
+		want   map[string]string
+	}{
+		{
+			name:   "sender team",
+			values: []string{"{{ sender_team }}"},
+			files:  map[string]string{"OWNERS_ALIASES": "aliases:\n  platform:\n    - fantasio\n  backend:\n    - fantasio\n"},
+			want:   map[string]string{senderTeamParam: "backend"},
+		},
+		{
+			name:   "sender in no team",
+			values: []string{"{{ sender_team }}"},
+			files:  map[string]string{"OWNERS_ALIASES": "aliases:\n  platform:\n    - spirou\n"},
+		},
+		{
+			name:   "no OWNERS_ALIASES file",
+			values: []string{"{{ sender_team }}"},
+		},
+		{
+			name:   "sender team not used",
+			values: []string{"{{ sender }}"},
+			files:  map[string]string{"OWNERS_ALIASES": "aliases:\n  backend:\n    - fantasio\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			p := &PacRun{
+				event:        &info.Event{Sender: "fantasio", DefaultBranch: "main"},
+				vcx:          &testprovider.TestProviderImp{FilesInsideRepo: tt.files},
+				logger:       logger,
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+			metadata := &pipelineRunMetadata{values: tt.values}
+			assert.DeepEqual(t, metadata.params(ctx, p), tt.want)
+		})
+	}
+}
+
+func TestInjectPipelineRunEnv(t *testing.T) {
+	pacInfo := &info.PacOpts{Settings: settings.DefaultSettings()}
+	assert.NilError(t, settings.SyncConfig(zap.NewNop().Sugar(), &pacInfo.Settings, map[string]string{
+		"pipelinerun-env": "HTTPS_PROXY=http://proxy:3128\nNO_PROXY=.svc",
+	}))
+	p := &PacRun{pacInfo: pacInfo, logger: zap.NewNop().Sugar()}
+
+	prs := []*tektonv1.PipelineRun{{}}
+	p.injectPipelineRunEnv(&v1alpha1.Repository{}, prs)
+	assert.DeepEqual(t, prs[0].Spec.TaskRunTemplate.PodTemplate.Env, []corev1.EnvVar{
+		{Name: "HTTPS_PROXY", Value: "http://proxy:3128"},
+		{Name: "NO_PROXY", Value: ".svc"},
+	})
+
+	prs = []*tektonv1.PipelineRun{{}}
+	repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{SkipPipelineRunEnv: []string{"*"}}}}
+	p.injectPipelineRunEnv(repo, prs)
+	assert.Assert(t, prs[0].Spec.TaskRunTemplate.PodTemplate == nil)
+}
+
+func TestApplyTimeouts(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+	observerCore, logs := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observerCore).Sugar()
+	p := &PacRun{
+		pacInfo:      syncedPacOpts(t, map[string]string{"max-pipelinerun-timeout": "1h"}),
+		logger:       logger,
+		eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+	}
+	makePR := func(name, timeout string) *tektonv1.PipelineRun {
+		return &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{keys.OriginalPRName: name, keys.Timeout: timeout},
+		}}
+	}
+	prs := []*tektonv1.PipelineRun{makePR("short", "30m"), makePR("long", "2h"), makePR("invalid", "soon")}
+	matchedPRs := []matcher.Match{}
+	for _, pr := range prs {
+		matchedPRs = append(matchedPRs, matcher.Match{PipelineRun: pr})
+	}
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+
+	p.applyTimeouts(ctx, repo, matchedPRs)
+	assert.Equal(t, prs[0].Spec.Timeouts.Pipeline.Duration, 30*time.Minute)
+	assert.Equal(t, prs[1].Spec.Timeouts.Pipeline.Duration, time.Hour)
+	// the invalid annotation is ignored, the default of Tekton is kept
+	assert.Equal(t, prs[2].Spec.Timeouts.Pipeline.Duration, time.Hour)
+	assert.DeepEqual(t, p.timeoutNotes, map[string]string{
+		"long": "The timeout of the PipelineRun has been limited to 1h0m0s, the maximum allowed by the administrator, instead of 2h0m0s.",
+	})
+
+	// the default of Tekton is clamped as well
+	p.pacInfo = syncedPacOpts(t, map[string]string{"max-pipelinerun-timeout": "30m"})
+	pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: map[string]string{keys.OriginalPRName: "default"}}}
+	p.applyTimeouts(ctx, repo, []matcher.Match{{PipelineRun: pr}})
+	assert.Equal(t, pr.Spec.Timeouts.Pipeline.Duration, 30*time.Minute)
+	assert.Equal(t, p.timeoutNotes["default"], "The timeout of the PipelineRun has been limited to 30m0s, the maximum allowed by the administrator, instead of 1h0m0s.")
+	assert.Equal(t, logs.FilterMessage("the timeout of the PipelineRun long has been limited to 1h0m0s instead of 2h0m0s").Len(), 1)
+	assert.Equal(t, logs.FilterMessageSnippet("invalid pipelinesascode.tekton.dev/timeout annotation \"soon\"").Len(), 1)
+}
@@ -0,0 +1,64 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+)
+
+// matchesOnChangedFiles returns whether a PipelineRun matches the event on
+// its changed files, with pathChanged() or the files of its CEL expression.
+func matchesOnChangedFiles(prs []*tektonv1.PipelineRun) bool {
+	for _, pr := range prs {
+		expr := pr.GetAnnotations()[keys.OnCelExpression]
+		if strings.Contains(expr, "pathChanged") || strings.Contains(expr, "files.") {
+			return true
+		}
+	}
+	return false
+}
+
+// getIgnoredPaths returns the patterns of the pac-ignore files of the
+// PipelineRun directories, fetched at the same revision as the templates and
+// cached with them. A file which cannot be fetched counts as none, the git
+// providers don't tell a missing file apart.
+func (p *PacRun) getIgnoredPaths(ctx context.Context, repo *v1alpha1.Repository, tektonDirs []string, provenance string) []string {
+	key := p.templatesCacheKey(repo, tektonDirs, provenance)
+	fingerprint := ""
+	if key != "" {
+		fingerprint = settingsFingerprint(&p.pacInfo.Settings)
+		if entry, ok := resolutions.get(fingerprint, resolutionCacheIgnore+"|"+key); ok {
+			p.countResolutionCache(repo, resolutionCacheIgnore, true)
+			return entry.ignoredPaths
+		}
+		p.countResolutionCache(repo, resolutionCacheIgnore, false)
+	}
+
+	patterns := []string{}
+	for _, tektonDir := range tektonDirs {
+		file := path.Join(tektonDir, changedfiles.IgnoreFile)
+		content, err := p.vcx.GetFileInsideRepo(ctx, p.event, file, "")
+		if err != nil {
+			p.logger.Debugf("no %s file: %v", file, err)
+			continue
+		}
+		filePatterns, err := changedfiles.ParseIgnoreFile(content)
+		if err != nil {
+			p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositoryInvalidPacIgnore",
+				fmt.Sprintf("skipping the invalid patterns of the %s file: %s", file, err.Error()))
+		}
+		patterns = append(patterns, filePatterns...)
+	}
+	if key != "" {
+		resolutions.set(fingerprint, resolutionCacheIgnore+"|"+key,
+			resolutionCacheEntry{ignoredPaths: patterns}, p.pacInfo.ResolutionCacheTTLDuration())
+	}
+	return patterns
+}
@@ -0,0 +1,70 @@
+package pipelineascode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMatchesOnChangedFiles(t *testing.T) {
+	withCel := func(expr string) *tektonv1.PipelineRun {
+		return &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{keys.OnCelExpression: expr}}}
+	}
+	assert.Assert(t, !matchesOnChangedFiles([]*tektonv1.PipelineRun{{}, withCel(`event == "push"`)}))
+	assert.Assert(t, matchesOnChangedFiles([]*tektonv1.PipelineRun{{}, withCel(`"docs/*.md".pathChanged()`)}))
+	assert.Assert(t, matchesOnChangedFiles([]*tektonv1.PipelineRun{withCel(`files.all.exists(x, x.matches('src/'))`)}))
+}
+
+func TestGetIgnoredPaths(t *testing.T) {
+	saved := resolutions
+	defer func() { resolutions = saved }()
+	resolutions = newResolutionCache(clockwork.NewFakeClock(), resolutionCacheMaxEntries)
+
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+		Spec:       v1alpha1.RepositorySpec{URL: "https://github.com/owner/repo"},
+	}
+	vcx := &testprovider.TestProviderImp{FilesInsideRepo: map[string]string{
+		".tekton/pac-ignore": "# generated\n*.pb.go\nvendor/\n[\n",
+		"ci/pac-ignore":      "docs/**\n",
+	}}
+	log, _ := logger.GetLogger()
+	kube := kubefake.NewSimpleClientset()
+	p := &PacRun{
+		event:        &info.Event{SHA: cachedSHA},
+		vcx:          vcx,
+		logger:       log,
+		eventEmitter: events.NewEventEmitter(kube, log),
+		pacInfo:      syncedPacOpts(t, map[string]string{"resolution-cache-ttl": "10m"}),
+	}
+	ctx := context.Background()
+
+	// the patterns of all the directories, without the invalid ones
+	got := p.getIgnoredPaths(ctx, repo, []string{".tekton", "ci", "other"}, "source")
+	assert.DeepEqual(t, got, []string{"*.pb.go", "vendor/", "docs/**"})
+	list, err := kube.CoreV1().Events("ns").List(ctx, metav1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(list.Items), 1)
+	assert.Equal(t, list.Items[0].Reason, "RepositoryInvalidPacIgnore")
+
+	// same commit, the provider is not asked again
+	vcx.FilesInsideRepo = nil
+	got = p.getIgnoredPaths(ctx, repo, []string{".tekton", "ci", "other"}, "source")
+	assert.DeepEqual(t, got, []string{"*.pb.go", "vendor/", "docs/**"})
+
+	// another commit without pac-ignore files
+	p.event.SHA = "1111111111111111111111111111111111111111"
+	got = p.getIgnoredPaths(ctx, repo, []string{".tekton"}, "source")
+	assert.DeepEqual(t, got, []string{})
+}
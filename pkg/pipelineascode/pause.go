@@ -0,0 +1,93 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const pausedTitle = "CI paused by admin"
+
+func isPauseResumeEvent(eventType string) bool {
+	return eventType == opscomments.PauseCommentEventType.String() ||
+		eventType == opscomments.ResumeCommentEventType.String()
+}
+
+func isPaused(repo *v1alpha1.Repository) bool {
+	return repo.Spec.Settings != nil && repo.Spec.Settings.Paused
+}
+
+// isRepositoryAdmin checks if the sender of the event is a member of the
+// admins teams of the policy, the OWNERS file is not looked at since its
+// reviewers are not trusted to stop the CI of the Repository.
+func (p *PacRun) isRepositoryAdmin(ctx context.Context, repo *v1alpha1.Repository) bool {
+	if repo.Spec.Settings == nil || repo.Spec.Settings.Policy == nil || len(repo.Spec.Settings.Policy.Admins) == 0 {
+		return false
+	}
+	allowed, _ := p.vcx.CheckPolicyAllowing(ctx, p.event, repo.Spec.Settings.Policy.Admins)
+	return allowed
+}
+
+// toggleRepositorySetting sets the jsonKey boolean setting of the Repository
+// to value for a GitOps command made by an admin, the Repository in memory is
+// updated with apply and msg is emitted once it has been patched.
+func (p *PacRun) toggleRepositorySetting(ctx context.Context, repo *v1alpha1.Repository, command, jsonKey string, current, value bool,
+	apply func(*v1alpha1.Settings), reason, msg string,
+) error {
+	if !p.isRepositoryAdmin(ctx, repo) {
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPermissionDenied",
+			fmt.Sprintf("user %s is not an admin of the repository, ignoring %s", p.event.Sender, command))
+		return nil
+	}
+	if current == value {
+		return nil
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"settings":{%q:%t}}}`, jsonKey, value))
+	if _, err := p.run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(repo.Namespace).Patch(
+		ctx, repo.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("cannot %s the repository %s/%s: %w", command[1:], repo.Namespace, repo.Name, err)
+	}
+	if repo.Spec.Settings == nil {
+		repo.Spec.Settings = &v1alpha1.Settings{}
+	}
+	apply(repo.Spec.Settings)
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, reason, msg)
+	return nil
+}
+
+// pauseResumeRepository sets the paused setting of the Repository for a
+// /pause or a /resume GitOps comment made by an admin.
+func (p *PacRun) pauseResumeRepository(ctx context.Context, repo *v1alpha1.Repository) error {
+	paused := p.event.EventType == opscomments.PauseCommentEventType.String()
+	command := "/resume"
+	msg := fmt.Sprintf("repository %s/%s resumed by %s", repo.Namespace, repo.Name, p.event.Sender)
+	if paused {
+		command = "/pause"
+		msg = fmt.Sprintf("repository %s/%s paused by %s", repo.Namespace, repo.Name, p.event.Sender)
+	}
+	return p.toggleRepositorySetting(ctx, repo, command, "paused", isPaused(repo), paused,
+		func(s *v1alpha1.Settings) { s.Paused = paused }, "RepositoryPaused", msg)
+}
+
+// reportPaused acknowledges an event of a paused Repository with a neutral
+// status, no PipelineRun gets created.
+func (p *PacRun) reportPaused(ctx context.Context, repo *v1alpha1.Repository) {
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryPaused",
+		fmt.Sprintf("repository %s/%s is paused, skipping the PipelineRuns for %s on %s", repo.Namespace, repo.Name, p.event.EventType, p.event.SHA))
+	if err := p.vcx.CreateStatus(ctx, p.event, provider.StatusOpts{
+		Status:     CompletedStatus,
+		Conclusion: neutralConclusion,
+		Title:      pausedTitle,
+		Text:       "The CI of this repository has been paused by an admin, no PipelineRun has been run. It runs again once resumed with a /resume comment.",
+		DetailsURL: p.event.URL,
+	}); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("cannot create status: %s", err))
+	}
+}
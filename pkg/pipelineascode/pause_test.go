@@ -0,0 +1,121 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestPauseResumeRepository(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventType  opscomments.EventType
+		paused     bool
+		admins     []string
+		policyDeny bool
+		inOwners   bool
+		wantPaused bool
+	}{
+		{
+			name:       "pause by a member of the admins teams",
+			eventType:  opscomments.PauseCommentEventType,
+			admins:     []string{"ops"},
+			wantPaused: true,
+		},
+		{
+			name:       "pause by a non admin",
+			eventType:  opscomments.PauseCommentEventType,
+			admins:     []string{"ops"},
+			policyDeny: true,
+		},
+		{
+			name:       "pause by a reviewer of the owners file",
+			eventType:  opscomments.PauseCommentEventType,
+			admins:     []string{"ops"},
+			policyDeny: true,
+			inOwners:   true,
+		},
+		{
+			name:      "pause by an owner without admins teams",
+			eventType: opscomments.PauseCommentEventType,
+			inOwners:  true,
+		},
+		{
+			name:      "resume by a member of the admins teams",
+			eventType: opscomments.ResumeCommentEventType,
+			paused:    true,
+			admins:    []string{"ops"},
+		},
+		{
+			name:       "resume by a reviewer of the owners file",
+			eventType:  opscomments.ResumeCommentEventType,
+			paused:     true,
+			admins:     []string{"ops"},
+			policyDeny: true,
+			inOwners:   true,
+			wantPaused: true,
+		},
+		{
+			name:       "resume by a non admin",
+			eventType:  opscomments.ResumeCommentEventType,
+			paused:     true,
+			wantPaused: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observerCore, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observerCore).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "testrepo", Namespace: "test"},
+				Spec: v1alpha1.RepositorySpec{
+					URL: "https://github.com/owner/repo",
+					Settings: &v1alpha1.Settings{
+						Paused: tt.paused,
+						Policy: &v1alpha1.Policy{Admins: tt.admins},
+					},
+				},
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{Repositories: []*v1alpha1.Repository{repo}})
+			cs := &params.Run{
+				Clients: clients.Clients{
+					PipelineAsCode: stdata.PipelineAsCode,
+					Log:            logger,
+					Kube:           stdata.Kube,
+				},
+			}
+			event := &info.Event{Sender: "fantasio", EventType: tt.eventType.String()}
+			vcx := &testprovider.TestProviderImp{PolicyDisallowing: tt.policyDeny, AllowedInOwnersFile: tt.inOwners}
+			p := NewPacs(event, vcx, cs, &info.PacOpts{}, &kitesthelper.KinterfaceTest{}, logger, nil)
+			p.eventEmitter = events.NewEventEmitter(stdata.Kube, logger)
+
+			assert.NilError(t, p.pauseResumeRepository(ctx, repo.DeepCopy()))
+			got, err := stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("test").Get(ctx, "testrepo", metav1.GetOptions{})
+			assert.NilError(t, err)
+			assert.Equal(t, got.Spec.Settings.Paused, tt.wantPaused)
+		})
+	}
+}
+
+func TestCommentEventTypePauseResume(t *testing.T) {
+	assert.Assert(t, isPauseResumeEvent(opscomments.CommentEventType("/pause").String()))
+	assert.Assert(t, isPauseResumeEvent(opscomments.CommentEventType("/resume").String()))
+	assert.Assert(t, !isPauseResumeEvent(opscomments.CommentEventType("/pause now").String()))
+	assert.Assert(t, isPaused(&v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{Paused: true}}}))
+	assert.Assert(t, !isPaused(&v1alpha1.Repository{}))
+}
@@ -3,7 +3,9 @@ package pipelineascode
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
@@ -11,8 +13,10 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/customparams"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/freeze"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
@@ -21,16 +25,16 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/secrets"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"go.uber.org/zap"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
-	tektonDir         = ".tekton"
 	CompletedStatus   = "completed"
 	inProgressStatus  = "in_progress"
 	queuedStatus      = "queued"
+	successConclusion = "success"
 	failureConclusion = "failure"
 	pendingConclusion = "pending"
+	neutralConclusion = "neutral"
 )
 
 type PacRun struct {
@@ -43,6 +47,21 @@ type PacRun struct {
 	manager      *ConcurrencyManager
 	pacInfo      *info.PacOpts
 	globalRepo   *v1alpha1.Repository
+	// freezeWindow is the freeze window in progress holding the PipelineRuns
+	// of the event until its end
+	freezeWindow *freeze.Active
+	// timeoutNotes are the notes on the timeouts clamped to the
+	// max-pipelinerun-timeout setting, by original PipelineRun name
+	timeoutNotes map[string]string
+	// snapshots are the resolved PipelineRuns before the generation of their
+	// secrets, by original PipelineRun name, stored once they are created
+	snapshots map[string]string
+	// rebuildOnBaseUpdate is set when the push event re-runs the open pull
+	// requests of the branch once its PipelineRuns are started
+	rebuildOnBaseUpdate bool
+	// skipReason is why the event has not started any PipelineRun, recorded
+	// by the event decision metric
+	skipReason string
 }
 
 func NewPacs(event *info.Event, vcx provider.Interface, run *params.Run, pacInfo *info.PacOpts, k8int kubeinteraction.Interface, logger *zap.SugaredLogger, globalRepo *v1alpha1.Repository) PacRun {
@@ -55,6 +74,15 @@ func NewPacs(event *info.Event, vcx provider.Interface, run *params.Run, pacInfo
 
 func (p *PacRun) Run(ctx context.Context) error {
 	matchedPRs, repo, err := p.matchRepoPR(ctx)
+	if p.rebuildOnBaseUpdate {
+		// the pauses between the batches would hold the push event and count
+		// in its latency
+		defer func() { go p.rebuildOpenPullRequests(provider.WithoutAPITime(ctx), repo) }()
+	}
+	if repo != nil {
+		owner := p.pacInfo.MetricsAggregation().Owner(p.event.Organization, repo.GetNamespace(), repo.GetName())
+		metrics.CountEvent(p.vcx.GetConfig().Name, p.event.EventType, owner)
+	}
 	if err != nil {
 		createStatusErr := p.vcx.CreateStatus(ctx, p.event, provider.StatusOpts{
 			Status:     CompletedStatus,
@@ -62,17 +90,40 @@ func (p *PacRun) Run(ctx context.Context) error {
 			Text:       fmt.Sprintf("There was an issue validating the commit: %q", err),
 			DetailsURL: p.run.Clients.ConsoleUI().URL(),
 		})
-		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("an error occurred: %s", err))
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, events.ReasonForError(err, "RepositoryCreateStatus"), fmt.Sprintf("an error occurred: %s", err))
 		if createStatusErr != nil {
 			p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("cannot create status: %s: %s", err, createStatusErr))
 		}
 	}
 	if len(matchedPRs) == 0 {
+		p.countEventDecision(err, 0)
+		p.rejectDeployment(ctx, repo, err)
 		return nil
 	}
-	if repo.Spec.ConcurrencyLimit != nil && *repo.Spec.ConcurrencyLimit != 0 {
+	if err := checkNamesCollision(matchedPRs, repo, p.pacInfo, p.event); err != nil {
+		p.skipped(skipReasonNameCollision)
+		p.countEventDecision(nil, 0)
+		p.rejectDeployment(ctx, repo, err)
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCheckNameCollision", err.Error())
+		if createStatusErr := p.vcx.CreateStatus(ctx, p.event, provider.StatusOpts{
+			Status:     CompletedStatus,
+			Conclusion: failureConclusion,
+			Text:       fmt.Sprintf("There was an issue with the check_run_name_template setting: %s", err.Error()),
+			DetailsURL: p.run.Clients.ConsoleUI().URL(),
+		}); createStatusErr != nil {
+			p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("cannot create status: %s: %s", err, createStatusErr))
+		}
+		return nil
+	}
+	if matchedPRs = p.filtered(matchedPRs, p.skipDuplicateEvents(ctx, repo, matchedPRs), skipReasonDuplicate); len(matchedPRs) == 0 {
+		p.countEventDecision(nil, 0)
+		return nil
+	}
+	if (repo.Spec.ConcurrencyLimit != nil && *repo.Spec.ConcurrencyLimit != 0) || p.freezeWindow != nil {
 		p.manager.Enable()
 	}
+	p.storeEventPayloads(ctx, repo, matchedPRs)
+	p.countEventDecision(nil, len(matchedPRs))
 
 	// set params for the console driver, only used for the custom console ones
 	cp := customparams.NewCustomParams(p.event, repo, p.run, p.k8int, p.eventEmitter, p.vcx)
@@ -132,6 +183,56 @@ func (p *PacRun) Run(ctx context.Context) error {
 	return nil
 }
 
+// checkNamesCollision makes sure the check_run_name_template setting doesn't
+// give the same check name to different PipelineRuns, they would otherwise
+// overwrite each other status.
+func checkNamesCollision(matchedPRs []matcher.Match, repo *v1alpha1.Repository, pacInfo *info.PacOpts, event *info.Event) error {
+	names := map[string]string{}
+	for _, match := range matchedPRs {
+		mrepo := match.Repo
+		if mrepo == nil {
+			mrepo = repo
+		}
+		if mrepo.Spec.Settings == nil || mrepo.Spec.Settings.CheckRunNameTemplate == "" {
+			continue
+		}
+		prName := match.PipelineRun.GetAnnotations()[keys.OriginalPRName]
+		checkName := provider.GetCheckName(provider.StatusOpts{
+			OriginalPipelineRunName: prName,
+			Stage:                   match.PipelineRun.GetAnnotations()[keys.Stage],
+		}, pacInfo, mrepo, event)
+		if other, ok := names[checkName]; ok && other != prName {
+			return fmt.Errorf("the PipelineRuns %s and %s have the same check name %q", other, prName, checkName)
+		}
+		names[checkName] = prName
+	}
+	return nil
+}
+
+// syncRegistrySecrets clones the registry secrets of
+// secret-auto-create-registry-secrets from the Pipelines-as-Code namespace in
+// the Repository namespace, when the admin has allowed it, and adds them to
+// the service account of the PipelineRun when it has opted in. An error does
+// not prevent the PipelineRun to start, it may not need to push or pull
+// images.
+func (p *PacRun) syncRegistrySecrets(ctx context.Context, match matcher.Match) {
+	if p.run.Info.Kube == nil || p.run.Info.Kube.Namespace == "" {
+		return
+	}
+	names := p.pacInfo.RegistrySecrets(match.Repo.GetNamespace())
+	if len(names) == 0 {
+		return
+	}
+	serviceAccount := "default"
+	if match.PipelineRun.Spec.TaskRunTemplate.ServiceAccountName != "" {
+		serviceAccount = match.PipelineRun.Spec.TaskRunTemplate.ServiceAccountName
+	}
+	if err := p.k8int.SyncRegistrySecrets(ctx, p.logger, p.run.Info.Kube.Namespace, match.Repo.GetNamespace(), serviceAccount, names); err != nil {
+		p.eventEmitter.EmitMessage(match.Repo, zap.ErrorLevel, "RepositoryRegistrySecrets",
+			fmt.Sprintf("cannot sync the registry secrets in the namespace %s: %s", match.Repo.GetNamespace(), err))
+	}
+}
+
 func (p *PacRun) startPR(ctx context.Context, match matcher.Match) (*tektonv1.PipelineRun, error) {
 	var gitAuthSecretName string
 
@@ -151,6 +252,15 @@ func (p *PacRun) startPR(ctx context.Context, match matcher.Match) (*tektonv1.Pi
 		if err = p.k8int.CreateSecret(ctx, match.Repo.GetNamespace(), authSecret); err != nil {
 			return nil, fmt.Errorf("creating basic auth secret: %s has failed: %w ", authSecret.GetName(), err)
 		}
+
+		p.syncRegistrySecrets(ctx, match)
+	}
+
+	signedEventSecretName := match.PipelineRun.GetAnnotations()[keys.SignedEventSecret]
+	if signedEventSecretName != "" {
+		if err := p.createSignedEventSecret(ctx, match, signedEventSecretName); err != nil {
+			return nil, fmt.Errorf("cannot sign the event: %w", err)
+		}
 	}
 
 	// Add labels and annotations to pipelinerun
@@ -168,19 +278,56 @@ func (p *PacRun) startPR(ctx context.Context, match matcher.Match) (*tektonv1.Pi
 		match.PipelineRun.Labels[keys.State] = kubeinteraction.StateQueued
 		match.PipelineRun.Annotations[keys.State] = kubeinteraction.StateQueued
 	}
+	// during a freeze window the pipelineRun is pending until its end, the
+	// watcher then queues it
+	if p.freezeWindow != nil {
+		match.PipelineRun.Spec.Status = tektonv1.PipelineRunSpecStatusPending
+		match.PipelineRun.Labels[keys.State] = kubeinteraction.StateFrozen
+		match.PipelineRun.Annotations[keys.State] = kubeinteraction.StateFrozen
+		match.PipelineRun.Annotations[keys.FrozenUntil] = p.freezeWindow.End.Format(time.RFC3339)
+	}
+
+	// the cloud credentials of the queued or frozen PipelineRuns are minted
+	// by the reconciler when they start, not to expire while they wait
+	cloudCredentialsSecretName := match.PipelineRun.GetAnnotations()[keys.CloudCredentialsSecret]
+	cloudCredentialsMinted := cloudCredentialsSecretName != "" && match.PipelineRun.Spec.Status != tektonv1.PipelineRunSpecStatusPending
+	if cloudCredentialsMinted {
+		if err := p.createCloudCredentialsSecret(ctx, match, cloudCredentialsSecretName); err != nil {
+			return nil, fmt.Errorf("cannot get the cloud credentials: %w", err)
+		}
+	}
+
+	issueKeys, err := eventIssueKeys(match.Repo, p.event)
+	if err != nil {
+		p.eventEmitter.EmitMessage(match.Repo, zap.WarnLevel, "RepositoryInvalidIssueTracker", err.Error())
+	} else if len(issueKeys) > 0 {
+		match.PipelineRun.Annotations[keys.IssueKeys] = strings.Join(issueKeys, ",")
+	}
+
+	// the status_url variable is only known once the status has been created,
+	// hold the pipelineRun until then
+	holdForStatusURL := usesStatusURL(match.PipelineRun) && match.PipelineRun.Spec.Status != tektonv1.PipelineRunSpecStatusPending
+	if holdForStatusURL {
+		match.PipelineRun.Spec.Status = tektonv1.PipelineRunSpecStatusPending
+	}
+
+	p.storeSnapshot(ctx, match)
 
 	// Create the actual pipeline
-	pr, err := p.run.Clients.Tekton.TektonV1().PipelineRuns(match.Repo.GetNamespace()).Create(ctx,
-		match.PipelineRun, metav1.CreateOptions{})
+	pr, err := p.createPipelineRun(ctx, match)
 	if err != nil {
+		p.deleteSnapshot(ctx, match)
 		// we need to make difference between markdown error and normal error that goes to namespace/controller stream
 		return nil, fmt.Errorf("creating pipelinerun %s in namespace %s has failed.\n\nTekton Controller has reported this error: ```%w``` ", match.PipelineRun.GetGenerateName(),
 			match.Repo.GetNamespace(), err)
 	}
 
 	// Create status with the log url
-	p.logger.Infof("pipelinerun %s has been created in namespace %s for SHA: %s Target Branch: %s",
+	runID := pr.GetAnnotations()[keys.RunID]
+	p.logger.With("run-id", runID).Infof("pipelinerun %s has been created in namespace %s for SHA: %s Target Branch: %s",
 		pr.GetName(), match.Repo.GetNamespace(), p.event.SHA, p.event.BaseBranch)
+	p.eventEmitter.EmitPipelineRunMessage(pr, zap.InfoLevel, "PipelineRunCreated",
+		fmt.Sprintf("PipelineRun created by Pipelines-as-Code for the %s event on the SHA %s with the run ID %s", p.event.EventType, p.event.SHA, runID))
 
 	consoleURL := p.run.Clients.ConsoleUI().DetailURL(pr)
 	mt := formatting.MessageTemplate{
@@ -190,8 +337,13 @@ func (p *PacRun) startPR(ctx context.Context, match matcher.Match) (*tektonv1.Pi
 		ConsoleURL:      consoleURL,
 		TknBinary:       settings.TknBinaryName,
 		TknBinaryURL:    settings.TknBinaryURL,
+		Profile:         formatting.ProfileFor(p.vcx.GetConfig().Name, match.Repo.Spec.Settings),
+	}
+	statusTemplates := &v1alpha1.StatusTemplates{}
+	if match.Repo.Spec.Settings != nil && match.Repo.Spec.Settings.StatusTemplates != nil {
+		statusTemplates = match.Repo.Spec.Settings.StatusTemplates
 	}
-	msg, err := mt.MakeTemplate(formatting.StartingPipelineRunText)
+	msg, err := mt.MakeCustomTemplate(statusTemplates.Starting, mt.Profile.StartingTemplate())
 	if err != nil {
 		return nil, fmt.Errorf("cannot create message template: %w", err)
 	}
@@ -203,15 +355,23 @@ func (p *PacRun) startPR(ctx context.Context, match matcher.Match) (*tektonv1.Pi
 		PipelineRunName:         pr.GetName(),
 		PipelineRun:             pr,
 		OriginalPipelineRunName: pr.GetAnnotations()[keys.OriginalPRName],
+		RunID:                   pr.GetAnnotations()[keys.RunID],
+		Stage:                   pr.GetAnnotations()[keys.Stage],
 	}
 
 	// if pipelineRun is in pending state then report status as queued
-	if pr.Spec.Status == tektonv1.PipelineRunSpecStatusPending {
+	if pr.Spec.Status == tektonv1.PipelineRunSpecStatusPending && !holdForStatusURL {
 		status.Status = queuedStatus
-		if status.Text, err = mt.MakeTemplate(formatting.QueuingPipelineRunText); err != nil {
+		if status.Text, err = mt.MakeCustomTemplate(statusTemplates.Queued, formatting.QueuingPipelineRunText); err != nil {
 			return nil, fmt.Errorf("cannot create message template: %w", err)
 		}
 	}
+	if p.freezeWindow != nil {
+		status.Text = mt.Profile.Messages().T("status.frozen", p.freezeWindow.Name, p.freezeWindow.End.Format(time.RFC1123))
+	}
+	if note := p.timeoutNotes[pr.GetAnnotations()[keys.OriginalPRName]]; note != "" {
+		status.Text += "\n\n" + note
+	}
 
 	if err := p.vcx.CreateStatus(ctx, p.event, status); err != nil {
 		// we still return the created PR with error, and allow caller to decide what to do with the PR, and avoid
@@ -229,6 +389,12 @@ func (p *PacRun) startPR(ctx context.Context, match matcher.Match) (*tektonv1.Pi
 		}
 	}
 
+	if usesStatusURL(pr) {
+		if pr, err = p.patchStatusURL(ctx, pr, holdForStatusURL); err != nil {
+			return pr, fmt.Errorf("cannot patch the status url of the pipelinerun %s: %w", pr.GetName(), err)
+		}
+	}
+
 	// update ownerRef of secret with pipelineRun, so that it gets cleanedUp with pipelineRun
 	if p.pacInfo.SecretAutoCreation {
 		err := p.k8int.UpdateSecretWithOwnerRef(ctx, p.logger, pr.Namespace, gitAuthSecretName, pr)
@@ -238,6 +404,21 @@ func (p *PacRun) startPR(ctx context.Context, match matcher.Match) (*tektonv1.Pi
 			return pr, fmt.Errorf("cannot update pipelinerun %s with ownerRef: %w", pr.GetGenerateName(), err)
 		}
 	}
+	// the cloud credentials are deleted by the watcher once the pipelineRun
+	// is done, the ownerRef cleans them up if it is deleted before. The
+	// reconciler sets it on the ones it mints.
+	if cloudCredentialsMinted {
+		if err := p.k8int.UpdateSecretWithOwnerRef(ctx, p.logger, pr.Namespace, cloudCredentialsSecretName, pr); err != nil {
+			return pr, fmt.Errorf("cannot update pipelinerun %s with ownerRef: %w", pr.GetGenerateName(), err)
+		}
+	}
+	if signedEventSecretName != "" {
+		if err := p.k8int.UpdateSecretWithOwnerRef(ctx, p.logger, pr.Namespace, signedEventSecretName, pr); err != nil {
+			return pr, fmt.Errorf("cannot update pipelinerun %s with ownerRef: %w", pr.GetGenerateName(), err)
+		}
+	}
+	p.addConfigMapOwner(ctx, pr, keys.EventPayload)
+	p.addConfigMapOwner(ctx, pr, keys.Snapshot)
 	return pr, nil
 }
 
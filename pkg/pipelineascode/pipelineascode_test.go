@@ -19,6 +19,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
@@ -43,6 +44,18 @@ const (
 	testCatalogHubName = "tekton"
 )
 
+// syncedPacOpts returns the options with the settings of the config, parsed
+// like the controller does.
+func syncedPacOpts(t *testing.T, config map[string]string) *info.PacOpts {
+	t.Helper()
+	if config == nil {
+		config = map[string]string{}
+	}
+	pacOpts := &info.PacOpts{Settings: settings.DefaultSettings()}
+	assert.NilError(t, settings.SyncConfig(zap.NewNop().Sugar(), &pacOpts.Settings, config))
+	return pacOpts
+}
+
 func replyString(mux *http.ServeMux, url, body string) {
 	mux.HandleFunc(url, func(w http.ResponseWriter, _ *http.Request) {
 		_, _ = fmt.Fprint(w, body)
@@ -74,6 +87,12 @@ func testSetupCommonGhReplies(t *testing.T, mux *http.ServeMux, runevent info.Ev
 		fmt.Sprintf("/repos/%s/%s/git/commits/%s", runevent.Organization, runevent.Repository, runevent.SHA),
 		jj)
 
+	if tag, ok := strings.CutPrefix(runevent.BaseBranch, "refs/tags/"); ok {
+		replyString(mux,
+			fmt.Sprintf("/repos/%s/%s/git/ref/tags/%s", runevent.Organization, runevent.Repository, tag),
+			fmt.Sprintf(`{"ref": "refs/tags/%s", "object": {"type": "commit", "sha": "%s"}}`, tag, runevent.SHA))
+	}
+
 	if !noReplyOrgPublicMembers {
 		mux.HandleFunc("/orgs/"+runevent.Organization+"/members", func(rw http.ResponseWriter, _ *http.Request) {
 			_, _ = fmt.Fprintf(rw, `[{"login": "%s"}]`, runevent.Sender)
@@ -657,3 +676,43 @@ func TestGetLogURLMergePatch(t *testing.T) {
 	assert.Assert(t, ok)
 	assert.Equal(t, a[filepath.Join(apipac.GroupName, "log-url")], con.URL())
 }
+
+func TestCheckNamesCollision(t *testing.T) {
+	newMatch := func(name string) matcher.Match {
+		return matcher.Match{PipelineRun: &pipelinev1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{keys.OriginalPRName: name}},
+		}}
+	}
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{
+			name: "no template",
+		},
+		{
+			name:     "unique names",
+			template: "ci/{{ pipelinerun }}",
+		},
+		{
+			name:     "collision",
+			template: "ci/{{ target_branch }}",
+			wantErr:  `the PipelineRuns build and test have the same check name "ci/main"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{
+				Settings: &v1alpha1.Settings{CheckRunNameTemplate: tt.template},
+			}}
+			pacInfo := &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}}
+			err := checkNamesCollision([]matcher.Match{newMatch("build"), newMatch("test")}, repo, pacInfo, &info.Event{BaseBranch: "main"})
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
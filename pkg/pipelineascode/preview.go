@@ -0,0 +1,26 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+)
+
+// deactivatePreviewEnvironment marks the preview environment of a closed pull
+// request as inactive on the providers supporting it. Errors are only
+// reported as events, there is nobody left on the pull request to show them.
+func (p *PacRun) deactivatePreviewEnvironment(ctx context.Context, repo *v1alpha1.Repository) {
+	previewer, ok := p.vcx.(provider.PreviewEnvironmentInterface)
+	if !ok {
+		return
+	}
+	if err := previewer.DeactivatePreviewEnvironment(ctx, p.event); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryPreviewEnvironment",
+			fmt.Sprintf("cannot deactivate the preview environment of pull request #%d: %s", p.event.PullRequestNumber, err.Error()))
+		return
+	}
+	p.logger.Infof("preview environment %s of closed pull request has been deactivated", provider.PreviewEnvironmentName(p.event))
+}
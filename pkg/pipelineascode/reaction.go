@@ -0,0 +1,33 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+)
+
+// acknowledgeComment reacts to the GitOps comment of the event once it has
+// been accepted, so the user knows it has been seen before any status gets
+// reported. The providers without reactions and the errors are ignored,
+// reacting is only a courtesy.
+func (p *PacRun) acknowledgeComment(ctx context.Context, repo *v1alpha1.Repository) {
+	if !p.pacInfo.GitOpsCommentReactions || p.event.TriggerCommentID == 0 {
+		return
+	}
+	reacter, ok := p.vcx.(provider.CommentReactionInterface)
+	if !ok {
+		return
+	}
+	reaction := provider.ReactionRocket
+	if p.event.CancelPipelineRuns || p.event.EventType == opscomments.LintCommentEventType.String() || isPauseResumeEvent(p.event.EventType) || isFreezeEvent(p.event.EventType) {
+		reaction = provider.ReactionThumbsUp
+	}
+	if err := reacter.AddCommentReaction(ctx, p.event, reaction); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositoryCommentReaction",
+			fmt.Sprintf("cannot react to the comment %d: %s", p.event.TriggerCommentID, err.Error()))
+	}
+}
@@ -0,0 +1,90 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+)
+
+type reactingProvider struct {
+	testprovider.TestProviderImp
+	reactions []provider.Reaction
+	err       error
+}
+
+func (r *reactingProvider) AddCommentReaction(_ context.Context, _ *info.Event, reaction provider.Reaction) error {
+	r.reactions = append(r.reactions, reaction)
+	return r.err
+}
+
+func TestAcknowledgeComment(t *testing.T) {
+	tests := []struct {
+		name         string
+		event        *info.Event
+		disabled     bool
+		err          error
+		wantReaction []provider.Reaction
+		wantLog      string
+	}{
+		{
+			name:         "test comment",
+			event:        &info.Event{EventType: opscomments.TestAllCommentEventType.String(), TriggerCommentID: 1},
+			wantReaction: []provider.Reaction{provider.ReactionRocket},
+		},
+		{
+			name:         "cancel comment",
+			event:        &info.Event{EventType: opscomments.CancelCommentAllEventType.String(), State: info.State{CancelPipelineRuns: true}, TriggerCommentID: 1},
+			wantReaction: []provider.Reaction{provider.ReactionThumbsUp},
+		},
+		{
+			name:         "lint comment",
+			event:        &info.Event{EventType: opscomments.LintCommentEventType.String(), TriggerCommentID: 1},
+			wantReaction: []provider.Reaction{provider.ReactionThumbsUp},
+		},
+		{
+			name:  "not a comment",
+			event: &info.Event{EventType: "pull_request"},
+		},
+		{
+			name:     "disabled",
+			event:    &info.Event{EventType: opscomments.TestAllCommentEventType.String(), TriggerCommentID: 1},
+			disabled: true,
+		},
+		{
+			name:         "reaction error is only reported",
+			event:        &info.Event{EventType: opscomments.RetestAllCommentEventType.String(), TriggerCommentID: 1},
+			err:          fmt.Errorf("forbidden"),
+			wantReaction: []provider.Reaction{provider.ReactionRocket},
+			wantLog:      "cannot react to the comment 1: forbidden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observerCore, logs := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observerCore).Sugar()
+			vcx := &reactingProvider{err: tt.err}
+			p := &PacRun{
+				event:        tt.event,
+				vcx:          vcx,
+				logger:       logger,
+				eventEmitter: events.NewEventEmitter(nil, logger),
+				pacInfo:      &info.PacOpts{Settings: settings.Settings{GitOpsCommentReactions: !tt.disabled}},
+			}
+			p.acknowledgeComment(context.Background(), nil)
+			assert.DeepEqual(t, vcx.reactions, tt.wantReaction)
+			if tt.wantLog != "" {
+				assert.Equal(t, logs.FilterMessage(tt.wantLog).Len(), 1, logs.All())
+			}
+		})
+	}
+}
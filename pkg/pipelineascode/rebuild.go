@@ -0,0 +1,200 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRebuildMaxPullRequests = 10
+	defaultRebuildBatchSize       = 5
+	defaultRebuildBatchInterval   = 30 * time.Second
+)
+
+// rebuildTracker keeps the rebuild of the open pull requests in progress for
+// each branch of a Repository, a new push to the branch cancels the one still
+// running for the previous push so the pull requests are not re-run twice.
+type rebuildTracker struct {
+	mu      sync.Mutex
+	next    uint64
+	running map[string]rebuildInProgress
+}
+
+type rebuildInProgress struct {
+	id     uint64
+	cancel context.CancelFunc
+}
+
+// rebuilds is shared by the PacRuns of all the push events.
+var rebuilds = &rebuildTracker{running: map[string]rebuildInProgress{}}
+
+// start cancels the rebuild in progress for the key and registers a new one,
+// done has to be called once the new rebuild is finished.
+func (t *rebuildTracker) start(ctx context.Context, key string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if previous, ok := t.running[key]; ok {
+		previous.cancel()
+	}
+	t.next++
+	id := t.next
+	t.running[key] = rebuildInProgress{id: id, cancel: cancel}
+	return ctx, func() {
+		cancel()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.running[key].id == id {
+			delete(t.running, key)
+		}
+	}
+}
+
+// rebuildBranch returns the branch updated by the push event when its open
+// pull requests have to be re-run by the rebuild_on_base_update setting.
+func rebuildBranch(repo *v1alpha1.Repository, event *info.Event) (string, bool) {
+	if repo.Spec.Settings == nil || repo.Spec.Settings.RebuildOnBaseUpdate == nil ||
+		event.TriggerTarget != triggertype.Push || event.BranchDeleted ||
+		strings.HasPrefix(event.BaseBranch, "refs/tags/") {
+		return "", false
+	}
+	branch := strings.TrimPrefix(event.BaseBranch, "refs/heads/")
+	branches := repo.Spec.Settings.RebuildOnBaseUpdate.Branches
+	if len(branches) == 0 {
+		return branch, branch == strings.TrimPrefix(event.DefaultBranch, "refs/heads/")
+	}
+	return branch, slices.ContainsFunc(branches, func(b string) bool {
+		return matcher.BranchMatch(b, branch)
+	})
+}
+
+// rebuildEvent is the pull request event re-running the PipelineRuns of the
+// rebuild_on_base_update setting on an open pull request. The sender is the
+// author of the pull request so the usual ACL applies to it.
+func rebuildEvent(event *info.Event, branch string, pr provider.PullRequest, pipelineRuns []string) *info.Event {
+	ret := info.NewEvent()
+	event.DeepCopyInto(ret)
+	eventProvider := *event.Provider
+	ret.Provider = &eventProvider
+	ret.Event = nil
+	ret.State = info.State{RebuildPipelineRuns: pipelineRuns}
+	ret.TriggerTarget = triggertype.PullRequest
+	ret.EventType = triggertype.PullRequest.String()
+	ret.BaseBranch = branch
+	ret.HeadBranch = pr.HeadBranch
+	ret.BaseURL = pr.BaseURL
+	ret.HeadURL = pr.HeadURL
+	ret.SHA = pr.SHA
+	ret.SHAURL = ""
+	ret.SHATitle = ""
+	ret.Sender = pr.Author
+	ret.SenderBot = false
+	ret.PullRequestNumber = pr.Number
+	ret.PullRequestTitle = pr.Title
+	ret.PullRequestLabel = pr.Labels
+	ret.SourceProjectID = pr.SourceProjectID
+	if ret.SourceProjectID == 0 {
+		ret.SourceProjectID = event.TargetProjectID
+	}
+	return ret
+}
+
+// filterRebuildPipelineRuns keeps the matched PipelineRuns selected by the
+// rebuild_on_base_update setting when the event re-runs a pull request.
+func filterRebuildPipelineRuns(event *info.Event, matchedPRs []matcher.Match) []matcher.Match {
+	if len(event.RebuildPipelineRuns) == 0 {
+		return matchedPRs
+	}
+	ret := []matcher.Match{}
+	for _, match := range matchedPRs {
+		if slices.Contains(event.RebuildPipelineRuns, match.PipelineRun.GetAnnotations()[keys.OriginalPRName]) {
+			ret = append(ret, match)
+		}
+	}
+	return ret
+}
+
+// rebuildOpenPullRequests re-runs the PipelineRuns of the
+// rebuild_on_base_update setting on the open pull requests targeting the
+// branch updated by the push, the most recently updated first. The pull
+// requests are re-run one after the other by batches, with a pause between
+// the batches to not flood the cluster on a busy branch. It runs in the
+// background once the push event has been processed, a newer push to the
+// branch stops it before the next pull request.
+func (p *PacRun) rebuildOpenPullRequests(ctx context.Context, repo *v1alpha1.Repository) {
+	branch, ok := rebuildBranch(repo, p.event)
+	if !ok {
+		return
+	}
+	lister, ok := p.vcx.(provider.OpenPullRequestsInterface)
+	if !ok {
+		p.logger.Infof("provider %s cannot list the open pull requests to rebuild on the update of %s", p.vcx.GetConfig().Name, branch)
+		return
+	}
+	ctx, done := rebuilds.start(ctx, fmt.Sprintf("%s/%s/%s", repo.Namespace, repo.Name, branch))
+	defer done()
+
+	rebuild := repo.Spec.Settings.RebuildOnBaseUpdate
+	maxPullRequests := rebuild.MaxPullRequests
+	if maxPullRequests <= 0 {
+		maxPullRequests = defaultRebuildMaxPullRequests
+	}
+	batchSize := rebuild.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultRebuildBatchSize
+	}
+	batchInterval := defaultRebuildBatchInterval
+	if rebuild.BatchInterval != "" {
+		// refused by the webhook, unless the Repository predates it
+		if d, err := time.ParseDuration(rebuild.BatchInterval); err == nil && d >= 0 {
+			batchInterval = min(d, provider.MaxRebuildBatchInterval)
+		} else {
+			p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositoryRebuildOnBaseUpdate",
+				fmt.Sprintf("invalid batch_interval %q of rebuild_on_base_update, using %s", rebuild.BatchInterval, defaultRebuildBatchInterval))
+		}
+	}
+
+	prs, err := lister.ListOpenPullRequests(ctx, p.event, branch, maxPullRequests)
+	if err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryRebuildOnBaseUpdate",
+			fmt.Sprintf("cannot list the open pull requests targeting %s to rebuild: %s", branch, err.Error()))
+		return
+	}
+	if len(prs) == 0 || ctx.Err() != nil {
+		return
+	}
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryRebuildOnBaseUpdate",
+		fmt.Sprintf("re-running the PipelineRuns %s of %d open pull requests after the update of %s to %s",
+			strings.Join(rebuild.PipelineRuns, ", "), len(prs), branch, p.event.SHA))
+
+	for i, pr := range prs {
+		if i > 0 && i%batchSize == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(batchInterval):
+			}
+		}
+		if ctx.Err() != nil {
+			p.logger.Infof("stopping the rebuild of the open pull requests of %s, superseded by a newer push", branch)
+			return
+		}
+		rp := NewPacs(rebuildEvent(p.event, branch, pr, rebuild.PipelineRuns), p.vcx, p.run, p.pacInfo, p.k8int, p.logger, p.globalRepo)
+		// a pull request being re-run is not left half created
+		if err := rp.Run(context.WithoutCancel(ctx)); err != nil {
+			p.logger.Errorf("cannot rebuild the pull request %d after the update of %s: %v", pr.Number, branch, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package pipelineascode
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestRebuildBranch(t *testing.T) {
+	tests := []struct {
+		name       string
+		rebuild    *v1alpha1.RebuildOnBaseUpdate
+		event      *info.Event
+		wantBranch string
+		want       bool
+	}{
+		{
+			name:       "push to the default branch",
+			rebuild:    &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}},
+			event:      &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/main", DefaultBranch: "main"},
+			wantBranch: "main",
+			want:       true,
+		},
+		{
+			name:       "push to another branch",
+			rebuild:    &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}},
+			event:      &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/release-1.0", DefaultBranch: "main"},
+			wantBranch: "release-1.0",
+		},
+		{
+			name:       "push to a branch of the setting",
+			rebuild:    &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}, Branches: []string{"release-*"}},
+			event:      &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/release-1.0", DefaultBranch: "main"},
+			wantBranch: "release-1.0",
+			want:       true,
+		},
+		{
+			name:    "pushed tag",
+			rebuild: &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}, Branches: []string{"*"}},
+			event:   &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/tags/v1.0", DefaultBranch: "main"},
+		},
+		{
+			name:    "deleted branch",
+			rebuild: &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}},
+			event:   &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/main", DefaultBranch: "main", State: info.State{BranchDeleted: true}},
+		},
+		{
+			name:    "pull request",
+			rebuild: &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}},
+			event:   &info.Event{TriggerTarget: triggertype.PullRequest, BaseBranch: "main", DefaultBranch: "main"},
+		},
+		{
+			name:  "no setting",
+			event: &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/main", DefaultBranch: "main"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{RebuildOnBaseUpdate: tt.rebuild}}}
+			branch, got := rebuildBranch(repo, tt.event)
+			assert.Equal(t, got, tt.want)
+			if tt.want || tt.wantBranch != "" {
+				assert.Equal(t, branch, tt.wantBranch)
+			}
+		})
+	}
+}
+
+func TestRebuildEvent(t *testing.T) {
+	push := &info.Event{
+		EventType:       "push",
+		TriggerTarget:   triggertype.Push,
+		Organization:    "owner",
+		Repository:      "repo",
+		URL:             "https://github.com/owner/repo",
+		BaseBranch:      "refs/heads/main",
+		HeadBranch:      "refs/heads/main",
+		SHA:             "base",
+		SHATitle:        "bump",
+		Sender:          "maintainer",
+		TargetProjectID: 10,
+		Provider:        &info.Provider{Token: "token"},
+	}
+	pr := provider.PullRequest{
+		Number: 42, Title: "fix", SHA: "head", HeadBranch: "fix", Author: "alice",
+		HeadURL: "https://github.com/alice/repo", BaseURL: "https://github.com/owner/repo",
+	}
+	event := rebuildEvent(push, "main", pr, []string{"e2e"})
+	assert.Equal(t, event.TriggerTarget, triggertype.PullRequest)
+	assert.Equal(t, event.EventType, triggertype.PullRequest.String())
+	assert.Equal(t, event.BaseBranch, "main")
+	assert.Equal(t, event.HeadBranch, "fix")
+	assert.Equal(t, event.SHA, "head")
+	assert.Equal(t, event.SHATitle, "")
+	assert.Equal(t, event.Sender, "alice")
+	assert.Equal(t, event.PullRequestNumber, 42)
+	assert.Equal(t, event.SourceProjectID, 10)
+	assert.DeepEqual(t, event.RebuildPipelineRuns, []string{"e2e"})
+
+	// the push event is left untouched
+	event.Provider.Token = "scoped"
+	assert.Equal(t, push.Provider.Token, "token")
+	assert.Equal(t, push.SHA, "base")
+}
+
+func TestFilterRebuildPipelineRuns(t *testing.T) {
+	newMatch := func(name string) matcher.Match {
+		return matcher.Match{PipelineRun: &tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{keys.OriginalPRName: name}},
+		}}
+	}
+	matched := []matcher.Match{newMatch("lint"), newMatch("e2e")}
+
+	got := filterRebuildPipelineRuns(&info.Event{}, matched)
+	assert.Equal(t, len(got), 2)
+
+	got = filterRebuildPipelineRuns(&info.Event{State: info.State{RebuildPipelineRuns: []string{"e2e"}}}, matched)
+	assert.Equal(t, len(got), 1)
+	assert.Equal(t, got[0].PipelineRun.GetAnnotations()[keys.OriginalPRName], "e2e")
+}
+
+// openPullRequestsLister lists the open pull requests with a callback.
+type openPullRequestsLister struct {
+	testprovider.TestProviderImp
+	list func(ctx context.Context) []provider.PullRequest
+}
+
+func (l *openPullRequestsLister) ListOpenPullRequests(ctx context.Context, _ *info.Event, _ string, _ int) ([]provider.PullRequest, error) {
+	return l.list(ctx), nil
+}
+
+func TestRebuildOpenPullRequestsConsecutivePushes(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	logger := zap.NewNop().Sugar()
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "testrepo", Namespace: "test"},
+		Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{
+			RebuildOnBaseUpdate: &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}, BatchInterval: "soon"},
+		}},
+	}
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+	cs := &params.Run{Clients: clients.Clients{Kube: stdata.Kube, Log: logger}}
+	newPush := func(sha string, vcx provider.Interface) PacRun {
+		event := &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/main", DefaultBranch: "main", SHA: sha}
+		p := NewPacs(event, vcx, cs, &info.PacOpts{}, &kitesthelper.KinterfaceTest{}, logger, nil)
+		p.eventEmitter = events.NewEventEmitter(stdata.Kube, logger)
+		return p
+	}
+
+	// the second push arrives while the open pull requests of the first one
+	// are listed, there is no open pull request anymore for the second one
+	var firstCanceled bool
+	second := newPush("second", &openPullRequestsLister{list: func(context.Context) []provider.PullRequest { return nil }})
+	first := newPush("first", &openPullRequestsLister{list: func(ctx context.Context) []provider.PullRequest {
+		second.rebuildOpenPullRequests(ctx, repo)
+		firstCanceled = ctx.Err() != nil
+		return []provider.PullRequest{{Number: 42, SHA: "head"}}
+	}})
+	first.rebuildOpenPullRequests(ctx, repo)
+
+	assert.Assert(t, firstCanceled)
+	list, err := stdata.Kube.CoreV1().Events("test").List(ctx, metav1.ListOptions{})
+	assert.NilError(t, err)
+	// only the two warnings about the invalid batch interval, the first push
+	// has not re-run the pull request
+	assert.Equal(t, len(list.Items), 1)
+	assert.Equal(t, list.Items[0].Count, int32(2))
+	assert.Assert(t, strings.Contains(list.Items[0].Message, `invalid batch_interval "soon"`), list.Items[0].Message)
+	assert.Equal(t, len(rebuilds.running), 0)
+}
+
+func TestRebuildTracker(t *testing.T) {
+	tracker := &rebuildTracker{running: map[string]rebuildInProgress{}}
+	firstCtx, firstDone := tracker.start(context.Background(), "test/repo/main")
+	otherCtx, otherDone := tracker.start(context.Background(), "test/repo/release")
+	secondCtx, secondDone := tracker.start(context.Background(), "test/repo/main")
+	assert.ErrorIs(t, firstCtx.Err(), context.Canceled)
+	assert.NilError(t, otherCtx.Err())
+	assert.NilError(t, secondCtx.Err())
+
+	// the first rebuild finishing does not forget the second one
+	firstDone()
+	assert.Equal(t, len(tracker.running), 2)
+	secondDone()
+	otherDone()
+	assert.Equal(t, len(tracker.running), 0)
+}
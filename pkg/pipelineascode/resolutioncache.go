@@ -0,0 +1,208 @@
+package pipelineascode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+const (
+	// resolutionCacheMaxEntries bounds the number of templates and of
+	// resolved PipelineRuns sets cached, the oldest one is dropped first.
+	resolutionCacheMaxEntries = 256
+
+	resolutionCacheTemplates = "templates"
+	resolutionCacheResolved  = "resolved"
+	resolutionCacheIgnore    = "ignore"
+)
+
+var commitSHARe = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+type resolutionCacheEntry struct {
+	templates    string
+	pipelineRuns []*tektonv1.PipelineRun
+	ignoredPaths []string
+	added        time.Time
+	expires      time.Time
+}
+
+// resolutionCache caches the PipelineRun templates fetched from the git
+// provider, the PipelineRuns resolved from them and the patterns of the
+// pac-ignore files for a commit of a repository. The content of the
+// directories is pinned by the commit, a retest of the same commit then skips
+// the provider calls and the resolution of the remote tasks. The whole cache is dropped when the settings change.
+type resolutionCache struct {
+	mu         sync.Mutex
+	clock      clockwork.Clock
+	maxEntries int
+	settings   string
+	entries    map[string]resolutionCacheEntry
+}
+
+// resolutions is shared by the PacRuns of all the events.
+var resolutions = newResolutionCache(clockwork.NewRealClock(), resolutionCacheMaxEntries)
+
+func newResolutionCache(clock clockwork.Clock, maxEntries int) *resolutionCache {
+	return &resolutionCache{
+		clock:      clock,
+		maxEntries: maxEntries,
+		entries:    map[string]resolutionCacheEntry{},
+	}
+}
+
+// settingsFingerprint identifies the settings the resolution depends on, the
+// hub catalogs are a sync.Map not marshaled with the other settings.
+func settingsFingerprint(s *settings.Settings) string {
+	data, _ := json.Marshal(s)
+	catalogs := []string{}
+	if s.HubCatalogs != nil {
+		s.HubCatalogs.Range(func(_, value any) bool {
+			catalogs = append(catalogs, fmt.Sprintf("%+v", value))
+			return true
+		})
+	}
+	sort.Strings(catalogs)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(string(data)+strings.Join(catalogs, "\n"))))
+}
+
+// checkSettings drops the whole cache when the settings have changed since
+// the entries were cached.
+func (c *resolutionCache) checkSettings(fingerprint string) {
+	if c.settings != fingerprint {
+		c.settings = fingerprint
+		c.entries = map[string]resolutionCacheEntry{}
+	}
+}
+
+func (c *resolutionCache) get(fingerprint, key string) (resolutionCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkSettings(fingerprint)
+	entry, ok := c.entries[key]
+	if !ok || !c.clock.Now().Before(entry.expires) {
+		return resolutionCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *resolutionCache) set(fingerprint, key string, entry resolutionCacheEntry, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkSettings(fingerprint)
+	now := c.clock.Now()
+	// drop the expired entries so the cache doesn't grow forever
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		oldest := ""
+		for k, e := range c.entries {
+			if oldest == "" || e.added.Before(c.entries[oldest].added) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	entry.added = now
+	entry.expires = now.Add(ttl)
+	c.entries[key] = entry
+}
+
+func copyPipelineRuns(prs []*tektonv1.PipelineRun) []*tektonv1.PipelineRun {
+	copied := make([]*tektonv1.PipelineRun, 0, len(prs))
+	for _, pr := range prs {
+		copied = append(copied, pr.DeepCopy())
+	}
+	return copied
+}
+
+// templatesCacheKey returns the key of the templates of the directories of
+// the repository at the commit of the event, an empty key when they cannot be
+// cached: the cache is disabled or the templates don't come from the commit
+// of the event, like for the default_branch or merge_base provenances which
+// move with the target branch.
+func (p *PacRun) templatesCacheKey(repo *v1alpha1.Repository, tektonDirs []string, provenance string) string {
+	if p.pacInfo == nil || p.pacInfo.ResolutionCacheTTLDuration() == 0 || provenance != "source" || !commitSHARe.MatchString(p.event.SHA) {
+		return ""
+	}
+	return strings.Join([]string{
+		repo.GetNamespace(), repo.GetName(), repo.Spec.URL, p.event.SHA, provenance, strings.Join(tektonDirs, ","),
+	}, "|")
+}
+
+func (p *PacRun) countResolutionCache(repo *v1alpha1.Repository, cache string, hit bool) {
+	outcome := "miss"
+	if hit {
+		outcome = "hit"
+	}
+	owner := p.pacInfo.MetricsAggregation().Owner(p.event.Organization, repo.GetNamespace(), repo.GetName())
+	metrics.CountResolutionCache(cache, outcome, owner)
+}
+
+// getCachedTemplatesFromRepo returns the templates of the directories from
+// the cache or fetches them from the git provider and caches them.
+func (p *PacRun) getCachedTemplatesFromRepo(ctx context.Context, repo *v1alpha1.Repository, tektonDirs []string, provenance string) (string, []string, error) {
+	key := p.templatesCacheKey(repo, tektonDirs, provenance)
+	if key == "" {
+		return p.getTemplatesFromRepo(ctx, tektonDirs, provenance)
+	}
+	fingerprint := settingsFingerprint(&p.pacInfo.Settings)
+	if entry, ok := resolutions.get(fingerprint, resolutionCacheTemplates+"|"+key); ok {
+		p.countResolutionCache(repo, resolutionCacheTemplates, true)
+		p.logger.Debugf("using the cached templates of %s at %s", repo.GetName(), p.event.SHA)
+		return entry.templates, nil, nil
+	}
+	p.countResolutionCache(repo, resolutionCacheTemplates, false)
+
+	rawTemplates, dirErrs, err := p.getTemplatesFromRepo(ctx, tektonDirs, provenance)
+	// only cache a complete fetch, a directory may have failed temporarily
+	if err == nil && len(dirErrs) == 0 && rawTemplates != "" {
+		resolutions.set(fingerprint, resolutionCacheTemplates+"|"+key,
+			resolutionCacheEntry{templates: rawTemplates}, p.pacInfo.ResolutionCacheTTLDuration())
+	}
+	return rawTemplates, dirErrs, err
+}
+
+// resolveCached resolves the remote tasks of the PipelineRuns or returns them
+// from the cache. The key includes the templates expanded for the event and
+// the PipelineRuns to resolve, another sender or comment on the same commit
+// giving other values is resolved again.
+func (p *PacRun) resolveCached(ctx context.Context, repo *v1alpha1.Repository, templatesKey, allTemplates string, types resolve.TektonTypes, ropt *resolve.Opts) ([]*tektonv1.PipelineRun, error) {
+	if templatesKey == "" {
+		return resolve.Resolve(ctx, p.run, p.logger, p.vcx, types, p.event, ropt)
+	}
+	data, err := json.Marshal(types.PipelineRuns)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s|%s|%x", resolutionCacheResolved, templatesKey, sha256.Sum256([]byte(allTemplates+string(data))))
+	fingerprint := settingsFingerprint(&p.pacInfo.Settings)
+	if entry, ok := resolutions.get(fingerprint, key); ok {
+		p.countResolutionCache(repo, resolutionCacheResolved, true)
+		p.logger.Debugf("using the cached resolved PipelineRuns of %s at %s", repo.GetName(), p.event.SHA)
+		return copyPipelineRuns(entry.pipelineRuns), nil
+	}
+	p.countResolutionCache(repo, resolutionCacheResolved, false)
+
+	pipelineRuns, err := resolve.Resolve(ctx, p.run, p.logger, p.vcx, types, p.event, ropt)
+	if err != nil {
+		return nil, err
+	}
+	resolutions.set(fingerprint, key, resolutionCacheEntry{pipelineRuns: copyPipelineRuns(pipelineRuns)}, p.pacInfo.ResolutionCacheTTLDuration())
+	return pipelineRuns, nil
+}
@@ -0,0 +1,157 @@
+package pipelineascode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const cachedSHA = "6dc9a1c1e5a3d5a2bd3a2d9c4e0d4d5e6f7a8b9c"
+
+func TestResolutionCache(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	cache := newResolutionCache(clock, 2)
+	pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pr"}}
+
+	cache.set("settings", "a", resolutionCacheEntry{templates: "a"}, time.Minute)
+	entry, ok := cache.get("settings", "a")
+	assert.Assert(t, ok)
+	assert.Equal(t, entry.templates, "a")
+
+	// expired
+	clock.Advance(2 * time.Minute)
+	_, ok = cache.get("settings", "a")
+	assert.Assert(t, !ok)
+
+	// the oldest entry is dropped
+	cache.set("settings", "a", resolutionCacheEntry{templates: "a"}, time.Minute)
+	clock.Advance(time.Second)
+	cache.set("settings", "b", resolutionCacheEntry{pipelineRuns: []*tektonv1.PipelineRun{pr}}, time.Minute)
+	clock.Advance(time.Second)
+	cache.set("settings", "c", resolutionCacheEntry{templates: "c"}, time.Minute)
+	_, ok = cache.get("settings", "a")
+	assert.Assert(t, !ok)
+	entry, ok = cache.get("settings", "b")
+	assert.Assert(t, ok)
+	assert.Equal(t, entry.pipelineRuns[0].GetName(), "pr")
+
+	// a change of the settings drops everything
+	_, ok = cache.get("other settings", "c")
+	assert.Assert(t, !ok)
+	_, ok = cache.get("settings", "c")
+	assert.Assert(t, !ok)
+}
+
+func TestSettingsFingerprint(t *testing.T) {
+	s := settings.DefaultSettings()
+	fingerprint := settingsFingerprint(&s)
+	assert.Equal(t, settingsFingerprint(&s), fingerprint)
+
+	s.RemoteTasks = !s.RemoteTasks
+	assert.Assert(t, settingsFingerprint(&s) != fingerprint)
+	s.RemoteTasks = !s.RemoteTasks
+
+	s.HubCatalogs.Store("custom", settings.HubCatalog{ID: "custom", URL: "https://hub.example.com"})
+	assert.Assert(t, settingsFingerprint(&s) != fingerprint)
+}
+
+func TestTemplatesCacheKey(t *testing.T) {
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+		Spec:       v1alpha1.RepositorySpec{URL: "https://github.com/owner/repo"},
+	}
+	tests := []struct {
+		name       string
+		ttl        string
+		sha        string
+		provenance string
+		want       string
+	}{
+		{
+			name:       "cached",
+			ttl:        "10m",
+			sha:        cachedSHA,
+			provenance: "source",
+			want:       "ns|repo|https://github.com/owner/repo|" + cachedSHA + "|source|.tekton",
+		},
+		{
+			name:       "disabled",
+			ttl:        "0",
+			sha:        cachedSHA,
+			provenance: "source",
+		},
+		{
+			name:       "default branch provenance",
+			ttl:        "10m",
+			sha:        cachedSHA,
+			provenance: "default_branch",
+		},
+		{
+			name:       "not a commit",
+			ttl:        "10m",
+			sha:        "main",
+			provenance: "source",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PacRun{
+				event:   &info.Event{SHA: tt.sha},
+				pacInfo: syncedPacOpts(t, map[string]string{"resolution-cache-ttl": tt.ttl}),
+			}
+			assert.Equal(t, p.templatesCacheKey(repo, []string{".tekton"}, tt.provenance), tt.want)
+		})
+	}
+}
+
+func TestGetCachedTemplatesFromRepo(t *testing.T) {
+	saved := resolutions
+	defer func() { resolutions = saved }()
+	resolutions = newResolutionCache(clockwork.NewFakeClock(), resolutionCacheMaxEntries)
+
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+		Spec:       v1alpha1.RepositorySpec{URL: "https://github.com/owner/repo"},
+	}
+	vcx := &testprovider.TestProviderImp{TektonDirTemplate: "first"}
+	p := &PacRun{
+		event:   &info.Event{SHA: cachedSHA},
+		vcx:     vcx,
+		logger:  zap.NewNop().Sugar(),
+		pacInfo: syncedPacOpts(t, map[string]string{"resolution-cache-ttl": "10m"}),
+	}
+	ctx := context.Background()
+
+	got, _, err := p.getCachedTemplatesFromRepo(ctx, repo, []string{".tekton"}, "source")
+	assert.NilError(t, err)
+	assert.Equal(t, got, "first")
+
+	// same commit, the provider is not asked again
+	vcx.TektonDirTemplate = "second"
+	got, _, err = p.getCachedTemplatesFromRepo(ctx, repo, []string{".tekton"}, "source")
+	assert.NilError(t, err)
+	assert.Equal(t, got, "first")
+
+	// another commit
+	p.event.SHA = "1111111111111111111111111111111111111111"
+	got, _, err = p.getCachedTemplatesFromRepo(ctx, repo, []string{".tekton"}, "source")
+	assert.NilError(t, err)
+	assert.Equal(t, got, "second")
+
+	// the settings have changed
+	p.event.SHA = cachedSHA
+	p.pacInfo.RemoteTasks = !p.pacInfo.RemoteTasks
+	got, _, err = p.getCachedTemplatesFromRepo(ctx, repo, []string{".tekton"}, "source")
+	assert.NilError(t, err)
+	assert.Equal(t, got, "second")
+}
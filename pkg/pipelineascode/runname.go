@@ -0,0 +1,78 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// the name of a PipelineRun is a label of its TaskRuns and pods
+	maxPipelineRunNameLength = 63
+	shortSHALength           = 7
+	// the attempts taken by the other PipelineRuns created at the same time
+	// are skipped, up to this number
+	maxNameAttempts = 10
+)
+
+// deterministicName returns the name of the attempt of a PipelineRun on a
+// commit: the name of the PipelineRun of the .tekton directory, the short SHA
+// of the commit and the attempt, the name is shortened to fit in a label.
+func deterministicName(name, sha string, attempt int) string {
+	suffix := "-" + strconv.Itoa(attempt)
+	if len(sha) > shortSHALength {
+		sha = sha[:shortSHALength]
+	}
+	if sha != "" {
+		suffix = "-" + strings.ToLower(sha) + suffix
+	}
+	name = strings.TrimSuffix(name, "-")
+	if len(name)+len(suffix) > maxPipelineRunNameLength {
+		name = strings.TrimRight(name[:maxPipelineRunNameLength-len(suffix)], "-.")
+	}
+	return name + suffix
+}
+
+// createPipelineRun creates the matched PipelineRun, with a random suffix or
+// the deterministic name of its next attempt on the commit depending on the
+// pipelinerun-naming setting.
+func (p *PacRun) createPipelineRun(ctx context.Context, match matcher.Match) (*tektonv1.PipelineRun, error) {
+	pipelineRuns := p.run.Clients.Tekton.TektonV1().PipelineRuns(match.Repo.GetNamespace())
+	if p.pacInfo.PipelineRunNaming != settings.PipelineRunNamingDeterministic {
+		return pipelineRuns.Create(ctx, match.PipelineRun, metav1.CreateOptions{})
+	}
+
+	// the previous attempts have the same labels, the ones deleted by the
+	// cleanups only let their name be taken again
+	prLabels := match.PipelineRun.GetLabels()
+	selector := labels.SelectorFromSet(labels.Set{
+		keys.Repository:     prLabels[keys.Repository],
+		keys.OriginalPRName: prLabels[keys.OriginalPRName],
+		keys.SHA:            prLabels[keys.SHA],
+	})
+	previous, err := pipelineRuns.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the previous attempts: %w", err)
+	}
+
+	attempt := len(previous.Items) + 1
+	for i := 0; i < maxNameAttempts; i++ {
+		match.PipelineRun.Name = deterministicName(match.PipelineRun.GetGenerateName(), p.event.SHA, attempt)
+		match.PipelineRun.Annotations[keys.RunAttempt] = strconv.Itoa(attempt)
+		pr, err := pipelineRuns.Create(ctx, match.PipelineRun, metav1.CreateOptions{})
+		if !errors.IsAlreadyExists(err) {
+			return pr, err
+		}
+		attempt++
+	}
+	return nil, fmt.Errorf("cannot find a free name for the PipelineRun after %d attempts, the last one was %s", maxNameAttempts, match.PipelineRun.GetName())
+}
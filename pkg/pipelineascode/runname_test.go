@@ -0,0 +1,101 @@
+package pipelineascode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestDeterministicName(t *testing.T) {
+	tests := []struct {
+		name    string
+		prName  string
+		sha     string
+		attempt int
+		want    string
+	}{
+		{
+			name:    "generate name",
+			prName:  "pull-request-",
+			sha:     "ABCDEF1234567890",
+			attempt: 1,
+			want:    "pull-request-abcdef1-1",
+		},
+		{
+			name:    "short sha",
+			prName:  "push",
+			sha:     "abc",
+			attempt: 12,
+			want:    "push-abc-12",
+		},
+		{
+			name:    "no sha",
+			prName:  "incoming-",
+			attempt: 2,
+			want:    "incoming-2",
+		},
+		{
+			name:    "long name",
+			prName:  strings.Repeat("a", 50) + "-" + strings.Repeat("b", 20) + "-",
+			sha:     "abcdef1234567890",
+			attempt: 3,
+			want:    strings.Repeat("a", 50) + "-bb-abcdef1-3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deterministicName(tt.prName, tt.sha, tt.attempt)
+			assert.Equal(t, got, tt.want)
+			assert.Assert(t, len(got) <= maxPipelineRunNameLength)
+		})
+	}
+}
+
+func TestCreatePipelineRunDeterministic(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	prLabels := map[string]string{
+		keys.Repository:     "testrepo",
+		keys.OriginalPRName: "pull-request",
+		keys.SHA:            "abcdef1234567890",
+	}
+	previous := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Name: "pull-request-abcdef1-1", Namespace: "test", Labels: prLabels,
+	}}
+	// a PipelineRun of another Repository has taken the name of the second
+	// attempt
+	other := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Name: "pull-request-abcdef1-2", Namespace: "test", Labels: map[string]string{keys.Repository: "other"},
+	}}
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: []*tektonv1.PipelineRun{previous, other}})
+	cs := &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline}}
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "testrepo", Namespace: "test"}}
+	newMatch := func() matcher.Match {
+		return matcher.Match{Repo: repo, PipelineRun: &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "pull-request-", Labels: prLabels, Annotations: map[string]string{},
+		}}}
+	}
+	event := &info.Event{SHA: "abcdef1234567890"}
+
+	p := NewPacs(event, nil, cs, &info.PacOpts{Settings: settings.Settings{PipelineRunNaming: settings.PipelineRunNamingDeterministic}}, nil, zap.NewNop().Sugar(), nil)
+	pr, err := p.createPipelineRun(ctx, newMatch())
+	assert.NilError(t, err)
+	assert.Equal(t, pr.GetName(), "pull-request-abcdef1-3")
+	assert.Equal(t, pr.GetAnnotations()[keys.RunAttempt], "3")
+
+	pr, err = p.createPipelineRun(ctx, newMatch())
+	assert.NilError(t, err)
+	assert.Equal(t, pr.GetName(), "pull-request-abcdef1-4")
+}
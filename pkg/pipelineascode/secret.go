@@ -63,6 +63,7 @@ func (s *SecretFromRepository) Get(ctx context.Context) error {
 		return nil
 	}
 	s.Event.Provider.User = s.Repo.Spec.GitProvider.User
+	s.Event.Provider.AuthType = s.Repo.Spec.GitProvider.AuthType
 
 	if s.Repo.Spec.GitProvider.WebhookSecret == nil {
 		// repo.Spec.GitProvider.url/token without a webhook secret is probably going to be bitbucket cloud which
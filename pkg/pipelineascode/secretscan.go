@@ -0,0 +1,207 @@
+package pipelineascode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secretscan"
+	"go.uber.org/zap"
+)
+
+const (
+	secretsDetectedTitle  = "Secrets detected"
+	secretScanFailedTitle = "Secret scanning failed"
+
+	// maxScannedFiles, maxScannedFileSize and secretScanTimeout bound the
+	// files fetched from the provider to scan a pull request.
+	maxScannedFiles    = 50
+	maxScannedFileSize = 1 << 20
+	secretScanTimeout  = 30 * time.Second
+)
+
+// secretScanNotification is sent to the secret-scanning-notify-url setting
+// when some credentials are detected in a pull request.
+type secretScanNotification struct {
+	Repository        authorizerRepository `json:"repository"`
+	Provider          string               `json:"provider"`
+	URL               string               `json:"url"`
+	Sender            string               `json:"sender"`
+	SHA               string               `json:"sha"`
+	PullRequestNumber int                  `json:"pull_request_number"`
+	Findings          []secretscan.Finding `json:"findings"`
+	Skipped           []string             `json:"skipped_pipelineruns"`
+}
+
+// filterSecretScanning scans the files changed by a pull request for
+// committed credentials when the secret-scanning setting is enabled. When
+// some are detected, the PipelineRuns consuming secrets are skipped, a
+// neutral status lists the findings and the security team is notified if
+// configured. The PipelineRuns consuming secrets are skipped as well when the
+// files cannot all be scanned.
+func (p *PacRun) filterSecretScanning(ctx context.Context, repo *v1alpha1.Repository, matchedPRs []matcher.Match) []matcher.Match {
+	if len(matchedPRs) == 0 || p.pacInfo == nil || !p.pacInfo.SecretScanning || p.event.TriggerTarget != triggertype.PullRequest {
+		return matchedPRs
+	}
+	findings, scanErr := p.scanChangedFiles(ctx)
+	if scanErr == nil && len(findings) == 0 {
+		return matchedPRs
+	}
+
+	kept := []matcher.Match{}
+	skipped := []string{}
+	for _, match := range matchedPRs {
+		if secretscan.ConsumesSecrets(match.PipelineRun) {
+			skipped = append(skipped, match.PipelineRun.GetGenerateName())
+			continue
+		}
+		kept = append(kept, match)
+	}
+	title, text := secretsDetectedTitle, secretScanReport(findings, skipped)
+	if scanErr != nil {
+		title, text = secretScanFailedTitle, secretScanFailureReport(scanErr, skipped)
+		p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositorySecretScanningFailed",
+			fmt.Sprintf("cannot scan the files of the pull request %d on %s, skipping %d of the %d matched PipelineRuns: %s",
+				p.event.PullRequestNumber, p.event.SHA, len(skipped), len(matchedPRs), scanErr))
+	} else {
+		p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositorySecretsDetected",
+			fmt.Sprintf("%d credentials detected in the files of the pull request %d on %s, skipping %d of the %d matched PipelineRuns",
+				len(findings), p.event.PullRequestNumber, p.event.SHA, len(skipped), len(matchedPRs)))
+	}
+	if err := p.vcx.CreateStatus(ctx, p.event, provider.StatusOpts{
+		Status:     CompletedStatus,
+		Conclusion: neutralConclusion,
+		Title:      title,
+		Text:       text,
+		DetailsURL: p.event.URL,
+	}); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("cannot create status: %s", err))
+	}
+	if scanErr == nil && p.pacInfo.SecretScanningNotifyURL != "" {
+		if err := p.notifySecretScan(ctx, repo, findings, skipped); err != nil {
+			p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "SecretScanningNotifyFailed",
+				fmt.Sprintf("cannot notify the credentials detected in the pull request %d: %s", p.event.PullRequestNumber, err))
+		}
+	}
+	return kept
+}
+
+// scanChangedFiles returns the credentials detected in the files added or
+// modified by the pull request at its head commit, whatever the provenance of
+// the PipelineRun definitions. It fails when the changed files cannot be
+// listed, when there are too many of them or when they cannot be fetched in
+// time or when one of them cannot be fetched or is too large to be scanned.
+func (p *PacRun) scanChangedFiles(ctx context.Context) ([]secretscan.Finding, error) {
+	getter, ok := p.vcx.(provider.RevisionFileInterface)
+	if !ok {
+		return nil, fmt.Errorf("the %s provider cannot get the files of a commit", p.vcx.GetConfig().Name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, secretScanTimeout)
+	defer cancel()
+
+	changed, err := p.vcx.GetFiles(ctx, p.event)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the changed files: %w", err)
+	}
+	paths := []string{}
+	for _, path := range changed.All {
+		if !slices.Contains(changed.Deleted, path) && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) > maxScannedFiles {
+		return nil, fmt.Errorf("the pull request changes %d files, more than the %d scanned", len(paths), maxScannedFiles)
+	}
+
+	rules := p.pacInfo.SecretScanningRuleset()
+	findings := []secretscan.Finding{}
+	for _, path := range paths {
+		content, err := getter.GetFileAtRevision(ctx, p.event, path, p.event.SHA)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("cannot fetch the changed files within %s", secretScanTimeout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot get the file %s to scan it: %w", path, err)
+		}
+		if len(content) > maxScannedFileSize {
+			return nil, fmt.Errorf("the file %s is larger than the %d bytes scanned", path, maxScannedFileSize)
+		}
+		findings = append(findings, secretscan.Scan(rules, path, content)...)
+	}
+	return findings, nil
+}
+
+func secretScanReport(findings []secretscan.Finding, skipped []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Some credentials have been detected in the files of the pull request:\n\n")
+	for _, finding := range findings {
+		fmt.Fprintf(&b, "* `%s`\n", finding)
+	}
+	if len(skipped) == 0 {
+		fmt.Fprintf(&b, "\nNo matched PipelineRun consumes secrets, they are run.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\nThe PipelineRuns consuming secrets have been skipped:\n\n")
+	for _, name := range skipped {
+		fmt.Fprintf(&b, "* %s\n", name)
+	}
+	fmt.Fprintf(&b, "\nRemove the credentials from the pull request and revoke them to run them.\n")
+	return b.String()
+}
+
+func secretScanFailureReport(scanErr error, skipped []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The files of the pull request could not be scanned for credentials: %s\n", scanErr)
+	if len(skipped) == 0 {
+		fmt.Fprintf(&b, "\nNo matched PipelineRun consumes secrets, they are run.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\nThe PipelineRuns consuming secrets have been skipped:\n\n")
+	for _, name := range skipped {
+		fmt.Fprintf(&b, "* %s\n", name)
+	}
+	return b.String()
+}
+
+func (p *PacRun) notifySecretScan(ctx context.Context, repo *v1alpha1.Repository, findings []secretscan.Finding, skipped []string) error {
+	body, err := json.Marshal(secretScanNotification{
+		Repository: authorizerRepository{
+			Name:      repo.GetName(),
+			Namespace: repo.GetNamespace(),
+			URL:       repo.Spec.URL,
+		},
+		Provider:          p.vcx.GetConfig().Name,
+		URL:               p.event.URL,
+		Sender:            p.event.Sender,
+		SHA:               p.event.SHA,
+		PullRequestNumber: p.event.PullRequestNumber,
+		Findings:          findings,
+		Skipped:           skipped,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.pacInfo.SecretScanningNotifyURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.run.Clients.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint answered with the status %d", resp.StatusCode)
+	}
+	return nil
+}
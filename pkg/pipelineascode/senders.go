@@ -0,0 +1,40 @@
+package pipelineascode
+
+import (
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"go.uber.org/zap"
+)
+
+// filterIgnoredSender keeps only the PipelineRuns annotated to run for the
+// ignored senders when the sender of a pull request or a push is ignored by
+// the ignored-senders setting, or the bot of a pull request by the
+// ignore-bot-prs setting. The pushes of the bots, like the ones of a merge
+// queue, are not filtered by it. GitOps comments are explicit requests to run
+// from the commenter and are never filtered.
+func (p *PacRun) filterIgnoredSender(repo *v1alpha1.Repository, matchedPRs []matcher.Match) []matcher.Match {
+	if len(matchedPRs) == 0 || isGitOpsComment(p.event) {
+		return matchedPRs
+	}
+	if p.event.TriggerTarget != triggertype.PullRequest && p.event.TriggerTarget != triggertype.Push {
+		return matchedPRs
+	}
+	if !p.pacInfo.IsIgnoredSender(p.event.Sender, p.event.SenderBot, p.event.TriggerTarget == triggertype.PullRequest) {
+		return matchedPRs
+	}
+
+	kept := []matcher.Match{}
+	for _, match := range matchedPRs {
+		if match.PipelineRun.GetAnnotations()[keys.OnIgnoredSender] == "true" {
+			kept = append(kept, match)
+		}
+	}
+	msg := fmt.Sprintf("the sender %s is ignored by the settings, skipping %d of the %d matched PipelineRuns",
+		p.event.Sender, len(matchedPRs)-len(kept), len(matchedPRs))
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryIgnoredSender", msg)
+	return kept
+}
@@ -0,0 +1,100 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFilterIgnoredSender(t *testing.T) {
+	matchedPRs := []matcher.Match{
+		{PipelineRun: &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "full"}}},
+		{PipelineRun: &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+			Name:        "lint",
+			Annotations: map[string]string{keys.OnIgnoredSender: "true"},
+		}}},
+	}
+	tests := []struct {
+		name    string
+		event   *info.Event
+		config  map[string]string
+		want    []string
+		wantLog string
+	}{
+		{
+			name:  "sender not ignored",
+			event: &info.Event{Sender: "someone", TriggerTarget: triggertype.PullRequest, EventType: "pull_request"},
+			want:  []string{"full", "lint"},
+		},
+		{
+			name:    "ignored sender",
+			event:   &info.Event{Sender: "renovate-bot", TriggerTarget: triggertype.PullRequest, EventType: "pull_request"},
+			config:  map[string]string{"ignored-senders": "renovate*"},
+			want:    []string{"lint"},
+			wantLog: "the sender renovate-bot is ignored by the settings, skipping 1 of the 2 matched PipelineRuns",
+		},
+		{
+			name:   "ignored bot on pull request",
+			event:  &info.Event{Sender: "ci", SenderBot: true, TriggerTarget: triggertype.PullRequest, EventType: "pull_request"},
+			config: map[string]string{"ignore-bot-prs": "true"},
+			want:   []string{"lint"},
+		},
+		{
+			name:   "bot push of the merge queue",
+			event:  &info.Event{Sender: "github-merge-queue[bot]", SenderBot: true, TriggerTarget: triggertype.Push, EventType: "push"},
+			config: map[string]string{"ignore-bot-prs": "true"},
+			want:   []string{"full", "lint"},
+		},
+		{
+			name:   "ignored sender on push",
+			event:  &info.Event{Sender: "github-merge-queue[bot]", SenderBot: true, TriggerTarget: triggertype.Push, EventType: "push"},
+			config: map[string]string{"ignored-senders": "github-merge-queue[bot]"},
+			want:   []string{"lint"},
+		},
+		{
+			name: "gitops comment of an ignored sender",
+			event: &info.Event{
+				Sender: "dependabot[bot]", TriggerTarget: triggertype.PullRequest,
+				EventType: opscomments.RetestAllCommentEventType.String(),
+			},
+			config: map[string]string{"ignore-bot-prs": "true"},
+			want:   []string{"full", "lint"},
+		},
+		{
+			name:   "incoming webhook",
+			event:  &info.Event{Sender: "dependabot[bot]", TriggerTarget: triggertype.Incoming, EventType: "incoming"},
+			config: map[string]string{"ignore-bot-prs": "true"},
+			want:   []string{"full", "lint"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observerCore, logs := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observerCore).Sugar()
+			p := &PacRun{
+				event:        tt.event,
+				logger:       logger,
+				eventEmitter: events.NewEventEmitter(nil, logger),
+				pacInfo:      syncedPacOpts(t, tt.config),
+			}
+			got := []string{}
+			for _, match := range p.filterIgnoredSender(nil, matchedPRs) {
+				got = append(got, match.PipelineRun.GetName())
+			}
+			assert.DeepEqual(t, got, tt.want)
+			if tt.wantLog != "" {
+				assert.Equal(t, logs.FilterMessage(tt.wantLog).Len(), 1, logs.All())
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package pipelineascode
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+)
+
+// defaultServiceAccount is the service account of the PipelineRuns not
+// choosing one.
+const defaultServiceAccount = "default"
+
+// forcedServiceAccount returns the service account forced by the
+// service_accounts setting of the Repository for the event, empty when the
+// PipelineRuns choose theirs.
+func forcedServiceAccount(repo *v1alpha1.Repository, event *info.Event) string {
+	if repo.Spec.Settings == nil || repo.Spec.Settings.ServiceAccounts == nil {
+		return ""
+	}
+	switch event.TriggerTarget {
+	case triggertype.PullRequest:
+		return repo.Spec.Settings.ServiceAccounts.PullRequest
+	case triggertype.Push:
+		return repo.Spec.Settings.ServiceAccounts.Push
+	default:
+	}
+	return ""
+}
+
+// serviceAccountFor returns the service account a PipelineRun runs with: the
+// one forced by the service_accounts setting of the Repository for the event,
+// or the one it chooses with the service-account annotation or in its spec.
+func serviceAccountFor(repo *v1alpha1.Repository, event *info.Event, pr *tektonv1.PipelineRun) string {
+	if forced := forcedServiceAccount(repo, event); forced != "" {
+		return forced
+	}
+	if sa := pr.GetAnnotations()[keys.ServiceAccount]; sa != "" {
+		return sa
+	}
+	if sa := pr.Spec.TaskRunTemplate.ServiceAccountName; sa != "" {
+		return sa
+	}
+	return defaultServiceAccount
+}
+
+// applyServiceAccounts sets the service account of the matched PipelineRuns
+// and skips the ones whose service account, or the one of any of their
+// tasks, is not allowed in their namespace by the service-account-allowlist
+// setting. The service account forced by the Repository replaces the ones of
+// the tasks as well.
+func (p *PacRun) applyServiceAccounts(repo *v1alpha1.Repository, matchedPRs []matcher.Match) []matcher.Match {
+	if len(matchedPRs) == 0 {
+		return matchedPRs
+	}
+	allowlist := settings.ServiceAccountAllowlist{}
+	if p.pacInfo != nil {
+		allowlist = p.pacInfo.ServiceAccounts()
+	}
+	kept := []matcher.Match{}
+	for _, match := range matchedPRs {
+		mrepo := match.Repo
+		if mrepo == nil {
+			mrepo = repo
+		}
+		pr := match.PipelineRun
+		sa := serviceAccountFor(mrepo, p.event, pr)
+		forced := forcedServiceAccount(mrepo, p.event)
+		serviceAccounts := []string{sa}
+		for i := range pr.Spec.TaskRunSpecs {
+			switch {
+			case pr.Spec.TaskRunSpecs[i].ServiceAccountName == "":
+			case forced != "":
+				pr.Spec.TaskRunSpecs[i].ServiceAccountName = forced
+			default:
+				serviceAccounts = append(serviceAccounts, pr.Spec.TaskRunSpecs[i].ServiceAccountName)
+			}
+		}
+		if notAllowed := slices.IndexFunc(serviceAccounts, func(name string) bool {
+			return !allowlist.Allowed(mrepo.GetNamespace(), name)
+		}); notAllowed != -1 {
+			p.eventEmitter.EmitMessage(mrepo, zap.WarnLevel, "RepositoryServiceAccountNotAllowed",
+				fmt.Sprintf("skipping the PipelineRun %s, the service account %s is not allowed in the namespace %s by the service-account-allowlist setting",
+					pr.GetAnnotations()[keys.OriginalPRName], serviceAccounts[notAllowed], mrepo.GetNamespace()))
+			continue
+		}
+		if sa != defaultServiceAccount || pr.Spec.TaskRunTemplate.ServiceAccountName != "" {
+			pr.Spec.TaskRunTemplate.ServiceAccountName = sa
+		}
+		kept = append(kept, match)
+	}
+	return kept
+}
@@ -0,0 +1,148 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplyServiceAccounts(t *testing.T) {
+	newMatches := func() []matcher.Match {
+		return []matcher.Match{
+			{PipelineRun: &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+				Name:        "plain",
+				Annotations: map[string]string{keys.OriginalPRName: "plain"},
+			}}},
+			{PipelineRun: &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "spec", Annotations: map[string]string{keys.OriginalPRName: "spec"}},
+				Spec: tektonv1.PipelineRunSpec{
+					TaskRunTemplate: tektonv1.PipelineTaskRunTemplate{ServiceAccountName: "builder"},
+				},
+			}},
+			{PipelineRun: &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "annotation", Annotations: map[string]string{
+					keys.OriginalPRName: "annotation",
+					keys.ServiceAccount: "deploy",
+				}},
+				Spec: tektonv1.PipelineRunSpec{
+					TaskRunTemplate: tektonv1.PipelineTaskRunTemplate{ServiceAccountName: "builder"},
+				},
+			}},
+			{PipelineRun: &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "task", Annotations: map[string]string{keys.OriginalPRName: "task"}},
+				Spec: tektonv1.PipelineRunSpec{
+					TaskRunSpecs: []tektonv1.PipelineTaskRunSpec{
+						{PipelineTaskName: "build"},
+						{PipelineTaskName: "deploy", ServiceAccountName: "admin"},
+					},
+				},
+			}},
+		}
+	}
+	forced := &v1alpha1.Settings{ServiceAccounts: &v1alpha1.ServiceAccounts{PullRequest: "restricted"}}
+	tests := []struct {
+		name     string
+		trigger  triggertype.Trigger
+		settings *v1alpha1.Settings
+		allow    string
+		// want maps the PipelineRuns kept to their service account
+		want map[string]string
+		// wantTasks are the service accounts of the tasks of the task
+		// PipelineRun when it is kept
+		wantTasks []string
+		wantLog   string
+	}{
+		{
+			name:      "chosen by the PipelineRuns",
+			trigger:   triggertype.PullRequest,
+			want:      map[string]string{"plain": "", "spec": "builder", "annotation": "deploy", "task": ""},
+			wantTasks: []string{"", "admin"},
+		},
+		{
+			name:      "forced on the pull requests",
+			trigger:   triggertype.PullRequest,
+			settings:  forced,
+			allow:     "ns:restricted",
+			want:      map[string]string{"plain": "restricted", "spec": "restricted", "annotation": "restricted", "task": "restricted"},
+			wantTasks: []string{"", "restricted"},
+		},
+		{
+			name:      "not forced on the pushes",
+			trigger:   triggertype.Push,
+			settings:  forced,
+			want:      map[string]string{"plain": "", "spec": "builder", "annotation": "deploy", "task": ""},
+			wantTasks: []string{"", "admin"},
+		},
+		{
+			name:      "forced on the pushes",
+			trigger:   triggertype.Push,
+			settings:  &v1alpha1.Settings{ServiceAccounts: &v1alpha1.ServiceAccounts{PullRequest: "restricted", Push: "deploy"}},
+			allow:     "n*:restricted|deploy",
+			want:      map[string]string{"plain": "deploy", "spec": "deploy", "annotation": "deploy", "task": "deploy"},
+			wantTasks: []string{"", "deploy"},
+		},
+		{
+			name:    "not allowed in the namespace",
+			trigger: triggertype.Push,
+			allow:   "ns:default|deploy",
+			want:    map[string]string{"plain": "", "annotation": "deploy"},
+			wantLog: "skipping the PipelineRun spec, the service account builder is not allowed in the namespace ns by the service-account-allowlist setting",
+		},
+		{
+			name:    "task not allowed in the namespace",
+			trigger: triggertype.Push,
+			allow:   "ns:default|builder|deploy",
+			want:    map[string]string{"plain": "", "spec": "builder", "annotation": "deploy"},
+			wantLog: "skipping the PipelineRun task, the service account admin is not allowed in the namespace ns by the service-account-allowlist setting",
+		},
+		{
+			name:      "other namespaces",
+			trigger:   triggertype.Push,
+			allow:     "other:deploy",
+			want:      map[string]string{"plain": "", "spec": "builder", "annotation": "deploy", "task": ""},
+			wantTasks: []string{"", "admin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observerCore, logs := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observerCore).Sugar()
+			p := &PacRun{
+				event:        &info.Event{TriggerTarget: tt.trigger},
+				logger:       logger,
+				eventEmitter: events.NewEventEmitter(kubefake.NewSimpleClientset(), logger),
+				pacInfo:      syncedPacOpts(t, map[string]string{"service-account-allowlist": tt.allow}),
+			}
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec:       v1alpha1.RepositorySpec{Settings: tt.settings},
+			}
+			got := map[string]string{}
+			for _, match := range p.applyServiceAccounts(repo, newMatches()) {
+				got[match.PipelineRun.GetName()] = match.PipelineRun.Spec.TaskRunTemplate.ServiceAccountName
+				if match.PipelineRun.GetName() == "task" {
+					tasks := []string{}
+					for _, spec := range match.PipelineRun.Spec.TaskRunSpecs {
+						tasks = append(tasks, spec.ServiceAccountName)
+					}
+					assert.DeepEqual(t, tasks, tt.wantTasks)
+				}
+			}
+			assert.DeepEqual(t, got, tt.want)
+			if tt.wantLog != "" {
+				assert.Equal(t, logs.FilterMessage(tt.wantLog).Len(), 1, logs.All())
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+)
+
+// unsignedCommitRefused returns why the PipelineRuns should not run when the
+// commit pushed to a branch of the require_signed_commits policy doesn't
+// have a signature verified by the git provider.
+func unsignedCommitRefused(repo *v1alpha1.Repository, event *info.Event) string {
+	if event.TriggerTarget != triggertype.Push || repo.Spec.Settings == nil || repo.Spec.Settings.Policy == nil {
+		return ""
+	}
+	if !slices.ContainsFunc(repo.Spec.Settings.Policy.RequireSignedCommits, func(branch string) bool {
+		return matcher.BranchMatch(branch, event.BaseBranch)
+	}) {
+		return ""
+	}
+
+	switch event.SHASignature.Status {
+	case info.SignatureVerified:
+		return ""
+	case info.SignatureUnverified:
+		return fmt.Sprintf("the signature of the commit with the key %s has not been verified by the git provider", event.SHASignature.KeyID)
+	}
+	return "the commit is not signed"
+}
+
+// reportUnsignedCommit reports a failure on the commit, so it is visible the
+// CI has been refused by the policy.
+func (p *PacRun) reportUnsignedCommit(ctx context.Context, repo *v1alpha1.Repository, reason string) {
+	msg := fmt.Sprintf("CI has been refused on %s pushed to %s, %s", p.event.SHA, p.event.BaseBranch, reason)
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryUnsignedCommit", msg)
+	if err := p.vcx.CreateStatus(ctx, p.event, provider.StatusOpts{
+		Status:     CompletedStatus,
+		Conclusion: failureConclusion,
+		Title:      "Unsigned commit",
+		Text:       fmt.Sprintf("CI has been refused since %s and the branch requires signed commits.", reason),
+		DetailsURL: p.event.URL,
+	}); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("cannot create status: %s", err))
+	}
+}
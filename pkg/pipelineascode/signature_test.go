@@ -0,0 +1,69 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"gotest.tools/v3/assert"
+)
+
+func TestUnsignedCommitRefused(t *testing.T) {
+	verified := info.CommitSignature{Status: info.SignatureVerified, KeyID: "6A0C91B48753C58C"}
+	unverified := info.CommitSignature{Status: info.SignatureUnverified, KeyID: "6A0C91B48753C58C"}
+	unsigned := info.CommitSignature{Status: info.SignatureUnsigned}
+	tests := []struct {
+		name       string
+		event      *info.Event
+		policy     *v1alpha1.Policy
+		wantReason string
+	}{
+		{
+			name:  "no policy",
+			event: &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/main", SHASignature: unsigned},
+		},
+		{
+			name:   "verified commit",
+			event:  &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/main", SHASignature: verified},
+			policy: &v1alpha1.Policy{RequireSignedCommits: []string{"main"}},
+		},
+		{
+			name:       "unsigned commit",
+			event:      &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/main", SHASignature: unsigned},
+			policy:     &v1alpha1.Policy{RequireSignedCommits: []string{"main"}},
+			wantReason: "the commit is not signed",
+		},
+		{
+			name:       "unverified commit on a glob",
+			event:      &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/release-1.0", SHASignature: unverified},
+			policy:     &v1alpha1.Policy{RequireSignedCommits: []string{"main", "release-*"}},
+			wantReason: "the signature of the commit with the key 6A0C91B48753C58C has not been verified by the git provider",
+		},
+		{
+			name:       "provider not verifying the signatures",
+			event:      &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/main"},
+			policy:     &v1alpha1.Policy{RequireSignedCommits: []string{"main"}},
+			wantReason: "the commit is not signed",
+		},
+		{
+			name:   "branch without the policy",
+			event:  &info.Event{TriggerTarget: triggertype.Push, BaseBranch: "refs/heads/feature", SHASignature: unsigned},
+			policy: &v1alpha1.Policy{RequireSignedCommits: []string{"main", "release-*"}},
+		},
+		{
+			name:   "pull requests are not refused",
+			event:  &info.Event{TriggerTarget: triggertype.PullRequest, BaseBranch: "main", SHASignature: unsigned},
+			policy: &v1alpha1.Policy{RequireSignedCommits: []string{"main"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &v1alpha1.Repository{}
+			if tt.policy != nil {
+				repo.Spec.Settings = &v1alpha1.Settings{Policy: tt.policy}
+			}
+			assert.Equal(t, unsignedCommitRefused(repo, tt.event), tt.wantReason)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package pipelineascode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secrets"
+	ktypes "github.com/openshift-pipelines/pipelines-as-code/pkg/secrets/types"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/templates"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// changeSignedEventSecret replaces the signed_event_secret variable of the
+// PipelineRuns using it with a random secret name, stored in the annotations
+// to create the secret with the signed metadata of the event when the
+// PipelineRun starts.
+func changeSignedEventSecret(prs []*tektonv1.PipelineRun) error {
+	for k, p := range prs {
+		b, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+
+		name := secrets.GenerateSignedEventSecretName()
+		processed := templates.ReplacePlaceHoldersVariables(string(b), map[string]string{
+			"signed_event_secret": name,
+		}, nil, nil, map[string]interface{}{})
+		// don't sign the event for the PipelineRuns not asking for it
+		if processed == string(b) {
+			continue
+		}
+
+		var np *tektonv1.PipelineRun
+		if err := json.Unmarshal([]byte(processed), &np); err != nil {
+			return err
+		}
+		if np.Annotations == nil {
+			np.Annotations = map[string]string{}
+		}
+		np.Annotations[apipac.SignedEventSecret] = name
+		prs[k] = np
+	}
+	return nil
+}
+
+// createSignedEventSecret signs the metadata of the event with the key of the
+// event-signing-secret setting and stores it in the secret of the
+// PipelineRun, the tasks holding the key can check that the revision or the
+// branches they are given really come from the event.
+func (p *PacRun) createSignedEventSecret(ctx context.Context, match matcher.Match, secretName string) error {
+	if p.pacInfo.EventSigningSecret == "" {
+		return fmt.Errorf("the PipelineRun uses the signed_event_secret variable without the event-signing-secret setting")
+	}
+	if p.run.Info.Kube == nil || p.run.Info.Kube.Namespace == "" {
+		return fmt.Errorf("cannot get the namespace of the event-signing-secret %s", p.pacInfo.EventSigningSecret)
+	}
+	key, err := p.k8int.GetSecret(ctx, ktypes.GetSecretOpt{
+		Namespace: p.run.Info.Kube.Namespace,
+		Name:      p.pacInfo.EventSigningSecret,
+		Key:       secrets.EventSigningKey,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot get the event-signing-secret %s/%s: %w", p.run.Info.Kube.Namespace, p.pacInfo.EventSigningSecret, err)
+	}
+	if key == "" {
+		return fmt.Errorf("the event-signing-secret %s/%s has no %s key", p.run.Info.Kube.Namespace, p.pacInfo.EventSigningSecret, secrets.EventSigningKey)
+	}
+
+	event := secrets.NewSignedEvent(p.event, p.vcx.GetConfig().Name, match.Repo.GetNamespace(), match.Repo.GetName(),
+		match.PipelineRun.GetAnnotations()[apipac.OriginalPRName])
+	secret, err := secrets.MakeSignedEventSecret(p.event, secretName, event, []byte(key))
+	if err != nil {
+		return fmt.Errorf("making signed event secret: %s has failed: %w", secretName, err)
+	}
+	if err := p.k8int.CreateSecret(ctx, match.Repo.GetNamespace(), secret); err != nil {
+		return fmt.Errorf("creating signed event secret: %s has failed: %w", secretName, err)
+	}
+	return nil
+}
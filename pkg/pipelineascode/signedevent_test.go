@@ -0,0 +1,111 @@
+package pipelineascode
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secrets"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestChangeSignedEventSecret(t *testing.T) {
+	prs := []*tektonv1.PipelineRun{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "{{ signed_event_secret }}",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "not-signed",
+			},
+		},
+	}
+	err := changeSignedEventSecret(prs)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(prs[0].GetName(), "pac-signedevent-"), prs[0].GetName(), "has no pac-signedevent prefix")
+	assert.Equal(t, prs[0].GetAnnotations()[apipac.SignedEventSecret], prs[0].GetName())
+	_, ok := prs[1].GetAnnotations()[apipac.SignedEventSecret]
+	assert.Assert(t, !ok)
+}
+
+func TestCreateSignedEventSecret(t *testing.T) {
+	tests := []struct {
+		name          string
+		signingSecret string
+		key           map[string]string
+		wantErr       string
+	}{
+		{
+			name:          "signed",
+			signingSecret: "pac-event-signing",
+			key:           map[string]string{"pac-event-signing": "s3cr3t"},
+		},
+		{
+			name:    "no setting",
+			wantErr: "without the event-signing-secret setting",
+		},
+		{
+			name:          "no secret",
+			signingSecret: "pac-event-signing",
+			wantErr:       "cannot get the event-signing-secret pipelines-as-code/pac-event-signing",
+		},
+		{
+			name:          "empty key",
+			signingSecret: "pac-event-signing",
+			key:           map[string]string{"pac-event-signing": ""},
+			wantErr:       "has no signing-key key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kint := &kitesthelper.KinterfaceTest{GetSecretResult: tt.key}
+			p := &PacRun{
+				event: &info.Event{
+					EventType: "pull_request", Organization: "owner", Repository: "repo", URL: "https://forge/owner/repo",
+					SHA: "123", HeadBranch: "feature", BaseBranch: "main", PullRequestNumber: 42, Sender: "author",
+				},
+				run:     &params.Run{Info: info.Info{Kube: &info.KubeOpts{Namespace: "pipelines-as-code"}}},
+				vcx:     &testprovider.TestProviderImp{},
+				pacInfo: &info.PacOpts{Settings: settings.Settings{EventSigningSecret: tt.signingSecret}},
+				k8int:   kint,
+				logger:  zap.NewNop().Sugar(),
+			}
+			match := matcher.Match{
+				PipelineRun: &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{apipac.OriginalPRName: "build"},
+				}},
+				Repo: &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}},
+			}
+			err := p.createSignedEventSecret(context.Background(), match, "pac-signedevent-abcdef")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			secret, ok := kint.CreatedSecrets["ns/pac-signedevent-abcdef"]
+			assert.Assert(t, ok)
+			event, err := secrets.VerifySignedEvent([]byte(secret.StringData[secrets.SignedEventPayloadKey]),
+				secret.StringData[secrets.SignedEventSignatureKey], []byte("s3cr3t"))
+			assert.NilError(t, err)
+			assert.Equal(t, event.Revision, "123")
+			assert.Equal(t, event.SourceBranch, "feature")
+			assert.Equal(t, event.TargetBranch, "main")
+			assert.Equal(t, event.PullRequestNumber, 42)
+			assert.Equal(t, event.Namespace, "ns")
+			assert.Equal(t, event.PipelineRun, "build")
+		})
+	}
+}
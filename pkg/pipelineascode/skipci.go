@@ -0,0 +1,79 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"go.uber.org/zap"
+)
+
+const skipCIPolicyDeny = "deny"
+
+var skipCIRegexp = regexp.MustCompile(`(?i)\[(skip ci|ci skip)\]`)
+
+// isGitOpsComment returns true if the event comes from a GitOps comment on a
+// pull request or a commit.
+func isGitOpsComment(event *info.Event) bool {
+	return opscomments.IsCommitComment(event) ||
+		opscomments.IsAnyOpsEventType(event.EventType) ||
+		event.EventType == opscomments.NoOpsCommentEventType.String()
+}
+
+// skipCIDirective returns why the event should be skipped if the commit
+// message has a [skip ci] or [ci skip] directive or the pull request has the
+// skip label, and the skip_ci setting of the Repository allows it. GitOps
+// comments are explicit requests to run and never skipped.
+func skipCIDirective(repo *v1alpha1.Repository, event *info.Event) string {
+	if (event.TriggerTarget != triggertype.Push && event.TriggerTarget != triggertype.PullRequest) || isGitOpsComment(event) {
+		return ""
+	}
+
+	if repo.Spec.Settings != nil && repo.Spec.Settings.SkipCI != nil {
+		skipCI := repo.Spec.Settings.SkipCI
+		if skipCI.Policy == skipCIPolicyDeny {
+			return ""
+		}
+		if len(skipCI.Branches) > 0 && !slices.ContainsFunc(skipCI.Branches, func(branch string) bool {
+			return matcher.BranchMatch(branch, event.BaseBranch)
+		}) {
+			return ""
+		}
+	}
+
+	message := event.SHAMessage
+	if message == "" {
+		message = event.SHATitle
+	}
+	if directive := skipCIRegexp.FindString(message); directive != "" {
+		return fmt.Sprintf("the commit message has a %s directive", directive)
+	}
+	if slices.Contains(event.PullRequestLabel, keys.SkipCILabel) {
+		return fmt.Sprintf("the pull request has the %s label", keys.SkipCILabel)
+	}
+	return ""
+}
+
+// reportSkippedByDirective reports a neutral status on the commit, so it is
+// visible the CI has not run on it.
+func (p *PacRun) reportSkippedByDirective(ctx context.Context, repo *v1alpha1.Repository, reason string) {
+	msg := fmt.Sprintf("CI has been skipped on %s, %s", p.event.SHA, reason)
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositorySkippedByDirective", msg)
+	if err := p.vcx.CreateStatus(ctx, p.event, provider.StatusOpts{
+		Status:     CompletedStatus,
+		Conclusion: neutralConclusion,
+		Title:      "Skipped by directive",
+		Text:       fmt.Sprintf("CI has been skipped since %s.", reason),
+		DetailsURL: p.event.URL,
+	}); err != nil {
+		p.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryCreateStatus", fmt.Sprintf("cannot create status: %s", err))
+	}
+}
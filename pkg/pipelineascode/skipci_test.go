@@ -0,0 +1,91 @@
+package pipelineascode
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"gotest.tools/v3/assert"
+)
+
+func TestSkipCIDirective(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      *info.Event
+		skipCI     *v1alpha1.SkipCI
+		wantReason string
+	}{
+		{
+			name:  "no directive",
+			event: &info.Event{TriggerTarget: triggertype.Push, SHATitle: "fix the bug"},
+		},
+		{
+			name:       "skip ci in commit message on push",
+			event:      &info.Event{TriggerTarget: triggertype.Push, SHATitle: "update docs [skip ci]"},
+			wantReason: "the commit message has a [skip ci] directive",
+		},
+		{
+			name: "skip ci in the body of the commit message",
+			event: &info.Event{
+				TriggerTarget: triggertype.PullRequest, EventType: "pull_request", SHATitle: "Update the docs",
+				SHAMessage: "Update the docs\n\nOnly a typo.\n\n[skip ci]",
+			},
+			wantReason: "the commit message has a [skip ci] directive",
+		},
+		{
+			name:       "ci skip in commit message on pull request",
+			event:      &info.Event{TriggerTarget: triggertype.PullRequest, EventType: "pull_request", SHATitle: "[CI SKIP] typo"},
+			wantReason: "the commit message has a [CI SKIP] directive",
+		},
+		{
+			name: "skip label on pull request",
+			event: &info.Event{
+				TriggerTarget: triggertype.PullRequest, EventType: "pull_request",
+				PullRequestLabel: []string{"bug", keys.SkipCILabel},
+			},
+			wantReason: "the pull request has the pipelinesascode.tekton.dev/skip label",
+		},
+		{
+			name: "gitops comments are never skipped",
+			event: &info.Event{
+				TriggerTarget: triggertype.PullRequest, EventType: opscomments.RetestAllCommentEventType.String(),
+				SHATitle: "[skip ci]",
+			},
+		},
+		{
+			name: "gitops comments on a commit are never skipped",
+			event: &info.Event{
+				TriggerTarget: triggertype.Push, EventType: triggertype.Push.String(),
+				TriggerComment: "/retest", SHATitle: "[skip ci]",
+			},
+		},
+		{
+			name:   "denied by policy",
+			event:  &info.Event{TriggerTarget: triggertype.Push, SHATitle: "[skip ci]"},
+			skipCI: &v1alpha1.SkipCI{Policy: "deny"},
+		},
+		{
+			name:       "allowed on branch",
+			event:      &info.Event{TriggerTarget: triggertype.Push, SHATitle: "[skip ci]", BaseBranch: "refs/heads/docs-update"},
+			skipCI:     &v1alpha1.SkipCI{Policy: "allow", Branches: []string{"main", "docs-*"}},
+			wantReason: "the commit message has a [skip ci] directive",
+		},
+		{
+			name:   "not allowed on branch",
+			event:  &info.Event{TriggerTarget: triggertype.PullRequest, EventType: "pull_request", SHATitle: "[skip ci]", BaseBranch: "release-1.0"},
+			skipCI: &v1alpha1.SkipCI{Branches: []string{"main", "docs-*"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &v1alpha1.Repository{}
+			if tt.skipCI != nil {
+				repo.Spec.Settings = &v1alpha1.Settings{SkipCI: tt.skipCI}
+			}
+			assert.Equal(t, skipCIDirective(repo, tt.event), tt.wantReason)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package pipelineascode
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/random"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	snapshotPrefix = "pac-snapshot-"
+
+	// SnapshotPipelineRunKey is the key of the resolved PipelineRun in its snapshot ConfigMap.
+	SnapshotPipelineRunKey = "pipelinerun.yaml"
+)
+
+// recordSnapshots keeps the resolved PipelineRuns, after the expansion of the
+// templates and the resolution of the remote tasks but before the generation
+// of their secrets, to store them as the snapshots of the PipelineRuns
+// created.
+func (p *PacRun) recordSnapshots(prs []*tektonv1.PipelineRun) {
+	p.snapshots = map[string]string{}
+	for _, pr := range prs {
+		b, err := yaml.Marshal(pr)
+		if err != nil {
+			p.logger.Warnf("cannot record the snapshot of the PipelineRun %s: %s", pr.GetGenerateName(), err.Error())
+			continue
+		}
+		p.snapshots[pr.GetAnnotations()[keys.OriginalPRName]] = string(b)
+	}
+}
+
+// snapshotLabels returns the labels of the snapshots of the event, they are
+// looked up by commit, and by pull request as well for the pull requests: the
+// snapshots of an older head of the pull request are never re-run on its new
+// head.
+func snapshotLabels(repo *v1alpha1.Repository, pullRequestNumber int, sha string) labels.Set {
+	set := labels.Set{
+		keys.Snapshot:   "true",
+		keys.Repository: formatting.CleanValueKubernetes(repo.GetName()),
+		keys.SHA:        formatting.CleanValueKubernetes(sha),
+	}
+	if pullRequestNumber != 0 {
+		set[keys.PullRequest] = strconv.Itoa(pullRequestNumber)
+	}
+	return set
+}
+
+// storeSnapshot stores the resolved PipelineRun in a ConfigMap of its
+// namespace and references it in its annotations, /retest --exact re-runs it
+// even when the templates or the remote tasks have changed since. A failure is
+// only logged, the PipelineRun still starts.
+func (p *PacRun) storeSnapshot(ctx context.Context, match matcher.Match) {
+	prName := match.PipelineRun.GetAnnotations()[keys.OriginalPRName]
+	data, ok := p.snapshots[prName]
+	if !ok {
+		return
+	}
+	if len(data) > maxEventPayloadSize {
+		p.eventEmitter.EmitMessage(match.Repo, zap.WarnLevel, "RepositorySnapshotTooBig",
+			fmt.Sprintf("the snapshot of the PipelineRun %s of %d bytes is too big to be stored, it cannot be re-run with /retest --exact", prName, len(data)))
+		return
+	}
+
+	cmLabels := snapshotLabels(match.Repo, p.event.PullRequestNumber, p.event.SHA)
+	cmLabels["app.kubernetes.io/part-of"] = "pipelines-as-code"
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      snapshotPrefix + strings.ToLower(random.AlphaString(8)),
+			Namespace: match.Repo.GetNamespace(),
+			Labels:    cmLabels,
+			Annotations: map[string]string{
+				keys.OriginalPRName: prName,
+				keys.EventType:      p.event.EventType,
+				keys.SHA:            p.event.SHA,
+			},
+		},
+		Data: map[string]string{SnapshotPipelineRunKey: data},
+	}
+	created, err := p.run.Clients.Kube.CoreV1().ConfigMaps(match.Repo.GetNamespace()).Create(ctx, cm, metav1.CreateOptions{})
+	if err != nil {
+		p.logger.Warnf("cannot store the snapshot of the PipelineRun %s in the namespace %s: %s", prName, match.Repo.GetNamespace(), err.Error())
+		return
+	}
+	if match.PipelineRun.Annotations == nil {
+		match.PipelineRun.Annotations = map[string]string{}
+	}
+	match.PipelineRun.Annotations[keys.Snapshot] = created.GetName()
+}
+
+// deleteSnapshot deletes the snapshot of a PipelineRun which could not be
+// created, nothing would clean it up otherwise.
+func (p *PacRun) deleteSnapshot(ctx context.Context, match matcher.Match) {
+	name := match.PipelineRun.GetAnnotations()[keys.Snapshot]
+	if name == "" {
+		return
+	}
+	err := p.run.Clients.Kube.CoreV1().ConfigMaps(match.Repo.GetNamespace()).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		p.logger.Warnf("cannot delete the snapshot %s/%s: %s", match.Repo.GetNamespace(), name, err.Error())
+	}
+}
+
+// getPipelineRunsFromSnapshots returns the PipelineRuns of the last snapshots
+// of the head commit of the pull request, or of the commit on a push, to
+// re-run them as they have been created with /retest --exact.
+func (p *PacRun) getPipelineRunsFromSnapshots(ctx context.Context, repo *v1alpha1.Repository) ([]matcher.Match, error) {
+	selector := labels.SelectorFromSet(snapshotLabels(repo, p.event.PullRequestNumber, p.event.SHA))
+	list, err := p.run.Clients.Kube.CoreV1().ConfigMaps(repo.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the snapshots of the PipelineRuns: %w", err)
+	}
+	// the last snapshot of each PipelineRun is re-run
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		ti, tj := list.Items[i].GetCreationTimestamp(), list.Items[j].GetCreationTimestamp()
+		return tj.Before(&ti)
+	})
+
+	p.snapshots = map[string]string{}
+	pipelineRuns := []*tektonv1.PipelineRun{}
+	for i := range list.Items {
+		cm := &list.Items[i]
+		prName := cm.GetAnnotations()[keys.OriginalPRName]
+		if _, ok := p.snapshots[prName]; ok {
+			continue
+		}
+		if p.event.TargetTestPipelineRun != "" && prName != p.event.TargetTestPipelineRun {
+			continue
+		}
+		pr := &tektonv1.PipelineRun{}
+		if err := yaml.Unmarshal([]byte(cm.Data[SnapshotPipelineRunKey]), pr); err != nil {
+			p.eventEmitter.EmitMessage(repo, zap.WarnLevel, "RepositoryInvalidSnapshot",
+				fmt.Sprintf("cannot read the snapshot %s of the PipelineRun %s: %s", cm.GetName(), prName, err.Error()))
+			continue
+		}
+		p.snapshots[prName] = cm.Data[SnapshotPipelineRunKey]
+		if pr.Annotations == nil {
+			pr.Annotations = map[string]string{}
+		}
+		for _, owner := range cm.GetOwnerReferences() {
+			if owner.Kind == "PipelineRun" {
+				pr.Annotations[keys.ExactRetestOf] = owner.Name
+			}
+		}
+		pipelineRuns = append(pipelineRuns, pr)
+	}
+	if len(pipelineRuns) == 0 {
+		msg := fmt.Sprintf("cannot find the snapshots of the PipelineRuns of the commit %s to re-run with /retest --exact", p.event.SHA)
+		if p.event.TargetTestPipelineRun != "" {
+			msg = fmt.Sprintf("cannot find the snapshot of the PipelineRun %s of the commit %s to re-run with /retest --exact", p.event.TargetTestPipelineRun, p.event.SHA)
+		}
+		p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositorySnapshotNotFound", msg)
+		p.skipped(skipReasonNoSnapshot)
+		return nil, nil
+	}
+
+	// the secrets of the snapshots are generated again, the ones of their
+	// last run have been deleted with it
+	if err := changeSecret(pipelineRuns); err != nil {
+		return nil, err
+	}
+	if repo.Spec.Settings != nil && repo.Spec.Settings.CloudCredentials != nil {
+		if err := changeCloudCredentialsSecret(pipelineRuns); err != nil {
+			return nil, err
+		}
+	}
+	if err := changeSignedEventSecret(pipelineRuns); err != nil {
+		return nil, err
+	}
+	matchedPRs := make([]matcher.Match, 0, len(pipelineRuns))
+	names := make([]string, 0, len(pipelineRuns))
+	for _, pr := range pipelineRuns {
+		matchedPRs = append(matchedPRs, matcher.Match{PipelineRun: pr, Repo: repo})
+		names = append(names, pr.GetAnnotations()[keys.OriginalPRName])
+	}
+	p.eventEmitter.EmitMessage(repo, zap.InfoLevel, "RepositoryMatchedPipelineRun",
+		fmt.Sprintf("exact re-run via /retest --exact of the snapshots of the PipelineRuns %s", strings.Join(names, ", ")))
+	return matchedPRs, nil
+}
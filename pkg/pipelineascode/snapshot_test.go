@@ -0,0 +1,136 @@
+package pipelineascode
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestSnapshots(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	kube := kubefake.NewSimpleClientset()
+	log, _ := logger.GetLogger()
+	newPacRun := func(event *info.Event) *PacRun {
+		return &PacRun{
+			event:        event,
+			run:          &params.Run{Clients: clients.Clients{Kube: kube}},
+			pacInfo:      &info.PacOpts{},
+			logger:       log,
+			eventEmitter: events.NewEventEmitter(kube, log),
+		}
+	}
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+	newPipelineRun := func(name, serviceAccount string) *tektonv1.PipelineRun {
+		return &tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: name + "-",
+				Annotations:  map[string]string{keys.OriginalPRName: name},
+			},
+			Spec: tektonv1.PipelineRunSpec{
+				TaskRunTemplate: tektonv1.PipelineTaskRunTemplate{ServiceAccountName: serviceAccount},
+				Workspaces: []tektonv1.WorkspaceBinding{{
+					Name:   "basic-auth",
+					Secret: &corev1.SecretVolumeSource{SecretName: "{{ git_auth_secret }}"},
+				}},
+			},
+		}
+	}
+
+	// the snapshots of the pull request are recorded before the generation of
+	// the secrets and stored when the PipelineRuns are created
+	p := newPacRun(&info.Event{SHA: "sha1", EventType: "pull_request", PullRequestNumber: 1})
+	p.recordSnapshots([]*tektonv1.PipelineRun{newPipelineRun("build", "old"), newPipelineRun("test", "old")})
+	stored := map[string]string{}
+	for _, prName := range []string{"build", "test"} {
+		match := matcher.Match{PipelineRun: newPipelineRun(prName, "old"), Repo: repo}
+		p.storeSnapshot(ctx, match)
+		name := match.PipelineRun.GetAnnotations()[keys.Snapshot]
+		assert.Assert(t, strings.HasPrefix(name, snapshotPrefix))
+		stored[prName] = name
+
+		pr := match.PipelineRun.DeepCopy()
+		pr.Name, pr.Namespace, pr.UID = prName+"-abcde", "ns", types.UID("uid-"+prName)
+		p.addConfigMapOwner(ctx, pr, keys.Snapshot)
+	}
+	configMaps := kube.CoreV1().ConfigMaps("ns")
+	cm, err := configMaps.Get(ctx, stored["build"], metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, cm.GetLabels()[keys.PullRequest], "1")
+	assert.Equal(t, cm.GetLabels()[keys.SHA], "sha1")
+	assert.Equal(t, cm.GetLabels()[keys.Repository], "repo")
+	assert.Equal(t, cm.GetAnnotations()[keys.OriginalPRName], "build")
+	assert.Assert(t, strings.Contains(cm.Data[SnapshotPipelineRunKey], "{{ git_auth_secret }}"))
+	assert.Equal(t, cm.OwnerReferences[0].Name, "build-abcde")
+
+	// an older snapshot of the same PipelineRun is not re-run
+	older := cm.DeepCopy()
+	older.Name, older.ResourceVersion, older.OwnerReferences = "pac-snapshot-older", "", nil
+	older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	older.Data[SnapshotPipelineRunKey] = strings.ReplaceAll(older.Data[SnapshotPipelineRunKey], "old", "older")
+	_, err = configMaps.Create(ctx, older, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	cm.CreationTimestamp = metav1.Now()
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+
+	// /retest --exact re-runs the snapshots of the pull request, even if the
+	// templates have changed since
+	exact := newPacRun(&info.Event{SHA: "sha1", EventType: "retest-all-comment", PullRequestNumber: 1, State: info.State{ExactRetest: true}})
+	matches, err := exact.getPipelineRunsFromRepo(ctx, repo)
+	assert.NilError(t, err)
+	assert.Equal(t, len(matches), 2)
+	for _, match := range matches {
+		pr := match.PipelineRun
+		assert.Equal(t, pr.Spec.TaskRunTemplate.ServiceAccountName, "old")
+		secretName := pr.GetAnnotations()[keys.GitAuthSecret]
+		assert.Assert(t, secretName != "")
+		assert.Equal(t, pr.Spec.Workspaces[0].Secret.SecretName, secretName)
+		assert.Equal(t, pr.GetAnnotations()[keys.ExactRetestOf], pr.GetAnnotations()[keys.OriginalPRName]+"-abcde")
+		assert.Equal(t, match.Repo, repo)
+	}
+	// the snapshot is stored again for the new PipelineRun
+	assert.Assert(t, strings.Contains(exact.snapshots["build"], "{{ git_auth_secret }}"))
+
+	// a single PipelineRun
+	exact = newPacRun(&info.Event{SHA: "sha1", PullRequestNumber: 1, State: info.State{ExactRetest: true, TargetTestPipelineRun: "test"}})
+	matches, err = exact.getPipelineRunsFromRepo(ctx, repo)
+	assert.NilError(t, err)
+	assert.Equal(t, len(matches), 1)
+	assert.Equal(t, matches[0].PipelineRun.GetAnnotations()[keys.OriginalPRName], "test")
+
+	// the snapshots of an older head of the pull request are not re-run on
+	// its new head
+	exact = newPacRun(&info.Event{SHA: "sha2", PullRequestNumber: 1, State: info.State{ExactRetest: true}})
+	matches, err = exact.getPipelineRunsFromRepo(ctx, repo)
+	assert.NilError(t, err)
+	assert.Equal(t, len(matches), 0)
+
+	// no snapshots for another pull request
+	exact = newPacRun(&info.Event{SHA: "sha1", PullRequestNumber: 2, State: info.State{ExactRetest: true}})
+	matches, err = exact.getPipelineRunsFromRepo(ctx, repo)
+	assert.NilError(t, err)
+	assert.Equal(t, len(matches), 0)
+
+	// the snapshot of a PipelineRun which could not be created is deleted
+	match := matcher.Match{PipelineRun: newPipelineRun("build", "old"), Repo: repo}
+	p.storeSnapshot(ctx, match)
+	p.deleteSnapshot(ctx, match)
+	_, err = configMaps.Get(ctx, match.PipelineRun.GetAnnotations()[keys.Snapshot], metav1.GetOptions{})
+	assert.ErrorContains(t, err, "not found")
+}
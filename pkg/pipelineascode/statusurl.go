@@ -0,0 +1,83 @@
+package pipelineascode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/templates"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// statusURLParam is the dynamic variable of the URL of the status of the
+// PipelineRun, only known once the status has been created on the provider.
+const statusURLParam = "status_url"
+
+// usesStatusURL returns true if the spec of the PipelineRun uses the
+// status_url dynamic variable, left as is by the templating of the matching.
+func usesStatusURL(pr *tektonv1.PipelineRun) bool {
+	b, err := json.Marshal(pr.Spec)
+	if err != nil {
+		return false
+	}
+	for _, match := range keys.ParamsRe.FindAllStringSubmatch(string(b), -1) {
+		if strings.TrimSpace(match[1]) == statusURLParam {
+			return true
+		}
+	}
+	return false
+}
+
+// statusURL returns the URL of the status of the PipelineRun on the provider,
+// the commit page when the provider has no page for the status.
+func (p *PacRun) statusURL(ctx context.Context, pr *tektonv1.PipelineRun) string {
+	if linker, ok := p.vcx.(provider.StatusURLInterface); ok {
+		statusURL, err := linker.StatusURL(ctx, p.event, pr)
+		if err != nil {
+			p.logger.Warnf("cannot get the status url of the pipelinerun %s: %v", pr.GetName(), err)
+		}
+		if statusURL != "" {
+			return statusURL
+		}
+	}
+	if p.event.SHAURL != "" {
+		return p.event.SHAURL
+	}
+	return p.event.URL
+}
+
+// patchStatusURL replaces the status_url dynamic variable in the spec of a
+// PipelineRun created pending for its status to be created first, and
+// releases it unless it has to stay pending in the queue.
+func (p *PacRun) patchStatusURL(ctx context.Context, pr *tektonv1.PipelineRun, release bool) (*tektonv1.PipelineRun, error) {
+	// the github app reporting patches the check run id on the pipelineRun
+	latest, err := p.run.Clients.Tekton.TektonV1().PipelineRuns(pr.GetNamespace()).Get(ctx, pr.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return pr, err
+	}
+	statusURL := p.statusURL(ctx, latest)
+
+	b, err := json.Marshal(latest.Spec)
+	if err != nil {
+		return latest, err
+	}
+	replaced := templates.ReplacePlaceHoldersVariables(string(b), map[string]string{statusURLParam: statusURL}, nil, nil, map[string]any{})
+	spec := map[string]any{}
+	if err := json.Unmarshal([]byte(replaced), &spec); err != nil {
+		return latest, fmt.Errorf("cannot replace the %s variable: %w", statusURLParam, err)
+	}
+	if release {
+		spec["status"] = nil
+	}
+	return action.PatchPipelineRun(ctx, p.logger, statusURLParam, p.run.Clients.Tekton, latest, map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{keys.StatusURL: statusURL},
+		},
+		"spec": spec,
+	})
+}
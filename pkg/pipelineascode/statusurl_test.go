@@ -0,0 +1,91 @@
+package pipelineascode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+type statusURLProvider struct {
+	testprovider.TestProviderImp
+}
+
+func (v *statusURLProvider) StatusURL(_ context.Context, event *info.Event, pr *tektonv1.PipelineRun) (string, error) {
+	return event.URL + "/runs/" + pr.GetAnnotations()[keys.CheckRunID], nil
+}
+
+func TestPatchStatusURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		vcx        provider.Interface
+		release    bool
+		wantURL    string
+		wantStatus tektonv1.PipelineRunSpecStatus
+	}{
+		{
+			name:    "check run of the pipelinerun",
+			vcx:     &statusURLProvider{},
+			release: true,
+			wantURL: "https://github.com/owner/repo/runs/42",
+		},
+		{
+			name:       "queued pipelinerun",
+			vcx:        &statusURLProvider{},
+			wantURL:    "https://github.com/owner/repo/runs/42",
+			wantStatus: tektonv1.PipelineRunSpecStatusPending,
+		},
+		{
+			name:    "provider without status page",
+			vcx:     &testprovider.TestProviderImp{},
+			release: true,
+			wantURL: "https://github.com/owner/repo/commit/abcd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			pr := &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pr-abcde", Namespace: "ns",
+					Annotations: map[string]string{keys.CheckRunID: "42"},
+				},
+				Spec: tektonv1.PipelineRunSpec{
+					Status: tektonv1.PipelineRunSpecStatusPending,
+					Params: []tektonv1.Param{
+						{Name: "link", Value: *tektonv1.NewStructuredValues("{{ status_url }}")},
+						{Name: "revision", Value: *tektonv1.NewStructuredValues("abcd")},
+					},
+				},
+			}
+			assert.Assert(t, usesStatusURL(pr))
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: []*tektonv1.PipelineRun{pr}})
+			logger := zap.NewNop().Sugar()
+			p := &PacRun{
+				run:    &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline}},
+				event:  &info.Event{URL: "https://github.com/owner/repo", SHAURL: "https://github.com/owner/repo/commit/abcd"},
+				vcx:    tt.vcx,
+				logger: logger,
+			}
+
+			got, err := p.patchStatusURL(ctx, pr, tt.release)
+			assert.NilError(t, err)
+			assert.Equal(t, got.Spec.Params[0].Value.StringVal, tt.wantURL)
+			assert.Equal(t, got.Spec.Params[1].Value.StringVal, "abcd")
+			assert.Equal(t, got.GetAnnotations()[keys.StatusURL], tt.wantURL)
+			assert.Equal(t, got.Spec.Status, tt.wantStatus)
+			assert.Assert(t, !usesStatusURL(got))
+		})
+	}
+}
@@ -14,6 +14,12 @@ import (
 // makeTemplate will process all templates replacing the value from the event and from the
 // params as set on Repo CR.
 func (p *PacRun) makeTemplate(ctx context.Context, repo *v1alpha1.Repository, template string) string {
+	return p.makeTemplates(ctx, repo, nil, template)[0]
+}
+
+// makeTemplates processes the templates like makeTemplate with the params
+// fetched once, the extra params are added to the ones of the event.
+func (p *PacRun) makeTemplates(ctx context.Context, repo *v1alpha1.Repository, extra map[string]string, tmpls ...string) []string {
 	cp := customparams.NewCustomParams(p.event, repo, p.run, p.k8int, p.eventEmitter, p.vcx)
 	maptemplate, changedFiles, err := cp.GetParams(ctx)
 	if err != nil {
@@ -25,6 +31,9 @@ func (p *PacRun) makeTemplate(ctx context.Context, repo *v1alpha1.Repository, te
 	if p.event.PullRequestNumber != 0 {
 		maptemplate["pull_request_number"] = fmt.Sprintf("%d", p.event.PullRequestNumber)
 	}
+	for k, v := range extra {
+		maptemplate[k] = v
+	}
 
 	// replace placeholders variable as well as evaluate cel expressions
 	headers := http.Header{}
@@ -32,5 +41,9 @@ func (p *PacRun) makeTemplate(ctx context.Context, repo *v1alpha1.Repository, te
 		headers = p.event.Request.Header
 	}
 
-	return templates.ReplacePlaceHoldersVariables(template, maptemplate, p.event.Event, headers, changedFiles)
+	replaced := make([]string, 0, len(tmpls))
+	for _, template := range tmpls {
+		replaced = append(replaced, templates.ReplacePlaceHoldersVariables(template, maptemplate, p.event.Event, headers, changedFiles))
+	}
+	return replaced
 }
@@ -3,9 +3,12 @@ package policy
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/gobwas/glob"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
@@ -14,6 +17,10 @@ import (
 
 type Result int
 
+// RolePrefix prefixes the minimum roles in the lists of a policy, the other
+// entries are teams.
+const RolePrefix = "role:"
+
 const (
 	ResultNotSet     Result = 0
 	ResultAllowed    Result = 1
@@ -26,6 +33,9 @@ type Policy struct {
 	VCX          provider.Interface
 	Logger       *zap.SugaredLogger
 	EventEmitter *events.EventEmitter
+	// IgnoreTeams ignores the teams of the policies with the providers not
+	// able to resolve them, a policy without any role is then not set.
+	IgnoreTeams bool
 }
 
 // checkAllowed checks if the policy is set and allows the event to be processed.
@@ -54,6 +64,12 @@ func (p *Policy) checkAllowed(ctx context.Context, tType triggertype.Trigger) (R
 		return ResultNotSet, ""
 	}
 
+	if p.IgnoreTeams {
+		if _, roles := splitRoles(sType); len(roles) == 0 {
+			return ResultNotSet, ""
+		}
+	}
+
 	// if policy is set but empty then it mean disallow everything
 	if len(sType) == 0 {
 		return ResultDisallowed, "no policy set"
@@ -73,13 +89,114 @@ func (p *Policy) checkAllowed(ctx context.Context, tType triggertype.Trigger) (R
 		return ResultDisallowed, "policy set and empty with no groups"
 	}
 
-	allowed, reason := p.VCX.CheckPolicyAllowing(ctx, p.Event, sType)
+	teams, roles := splitRoles(sType)
+	if p.IgnoreTeams {
+		teams = []string{}
+	}
+	if len(roles) > 0 {
+		allowed, reason := p.checkRoles(ctx, tType, roles)
+		if allowed {
+			return ResultAllowed, ""
+		}
+		if len(teams) == 0 {
+			return ResultDisallowed, fmt.Sprintf("policy check: %s, %s", string(tType), reason)
+		}
+	}
+
+	allowed, reason := p.VCX.CheckPolicyAllowing(ctx, p.Event, teams)
 	if allowed {
 		return ResultAllowed, ""
 	}
 	return ResultDisallowed, fmt.Sprintf("policy check: %s, %s", string(tType), reason)
 }
 
+// Validate checks that the roles in the lists of the policy of the settings
+// exist and that the branch globs of require_signed_commits compile.
+func Validate(settings *v1alpha1.Settings) error {
+	if settings == nil || settings.Policy == nil {
+		return nil
+	}
+	for _, list := range []struct {
+		action  string
+		entries []string
+	}{
+		{"ok_to_test", settings.Policy.OkToTest},
+		{"pull_request", settings.Policy.PullRequest},
+	} {
+		for _, entry := range list.entries {
+			name, ok := strings.CutPrefix(entry, RolePrefix)
+			if !ok {
+				continue
+			}
+			if _, ok := info.ParseRole(strings.TrimSpace(name)); !ok {
+				return fmt.Errorf("invalid role %q in the %s policy, it needs to be one of %s, %s, %s, %s or %s", name, list.action,
+					info.RoleOwner, info.RoleMaintainer, info.RoleCollaborator, info.RoleAuthor, info.RoleNone)
+			}
+		}
+	}
+	for _, branch := range settings.Policy.RequireSignedCommits {
+		// matcher.BranchMatch panics on an invalid glob
+		if _, err := glob.Compile(branch); err != nil {
+			return fmt.Errorf("invalid branch glob %q of require_signed_commits: %w", branch, err)
+		}
+	}
+	return nil
+}
+
+// splitRoles splits the entries of a policy between the teams and the
+// minimum roles prefixed with role:, ie: role:maintainer.
+func splitRoles(entries []string) ([]string, []info.Role) {
+	teams := []string{}
+	roles := []info.Role{}
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry, RolePrefix)
+		if !ok {
+			teams = append(teams, entry)
+			continue
+		}
+		if role, ok := info.ParseRole(strings.TrimSpace(name)); ok {
+			roles = append(roles, role)
+		}
+	}
+	return teams, roles
+}
+
+// checkRoles checks if the role of the sender is at least one of the roles.
+// The author of a pull request only has the author role to /retest it, not to
+// /test or /ok-to-test it.
+func (p *Policy) checkRoles(ctx context.Context, tType triggertype.Trigger, roles []info.Role) (bool, string) {
+	role, err := p.SenderRole(ctx)
+	if err != nil {
+		return false, fmt.Sprintf("error while getting the role of the user: %s, error: %s", p.Event.Sender, err.Error())
+	}
+	if role == info.RoleAuthor && (tType == triggertype.OkToTest || tType == triggertype.Retest) && !isRetestComment(p.Event) {
+		role = info.RoleNone
+	}
+	for _, minimum := range roles {
+		if role.AtLeast(minimum) {
+			return true, fmt.Sprintf("allowing user: %s as %s", p.Event.Sender, role)
+		}
+	}
+	return false, fmt.Sprintf("user: %s with the role %s has none of the allowed roles: %v", p.Event.Sender, role, roles)
+}
+
+// SenderRole returns the role of the sender of the event on the repository,
+// resolved once by the provider and kept on the event. The role is none with
+// the providers not able to resolve it.
+func (p *Policy) SenderRole(ctx context.Context) (info.Role, error) {
+	return provider.ResolveSenderRole(ctx, p.VCX, p.Event)
+}
+
+// isRetestComment returns true if the event is a /retest comment.
+func isRetestComment(event *info.Event) bool {
+	switch opscomments.CommentEventType(event.TriggerComment) {
+	case opscomments.RetestAllCommentEventType, opscomments.RetestSingleCommentEventType:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Policy) IsAllowed(ctx context.Context, tType triggertype.Trigger) (Result, string) {
 	var reason string
 	policyRes, reason := p.checkAllowed(ctx, tType)
@@ -48,6 +48,8 @@ func TestPolicy_IsAllowed(t *testing.T) {
 		wantErr              bool
 		wantReason           string
 		allowedInOwnersFile  bool
+		senderRole           info.Role
+		ignoreTeams          bool
 		expectedLogsSnippets []string
 	}{
 		{
@@ -201,6 +203,109 @@ func TestPolicy_IsAllowed(t *testing.T) {
 			want:                 ResultDisallowed,
 			expectedLogsSnippets: []string{"policy check: pull_request, policy disallowing"},
 		},
+		{
+			name: "allowed/role at least collaborator",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{PullRequest: []string{"role:collaborator"}}),
+				event:      &info.Event{Sender: senderName},
+			},
+			args: args{
+				tType: triggertype.PullRequest,
+			},
+			senderRole: info.RoleMaintainer,
+			want:       ResultAllowed,
+			expectedLogsSnippets: []string{
+				fmt.Sprintf("policy check: policy is set for sender %s has been allowed to run CI via policy", senderName),
+			},
+		},
+		{
+			name: "allowed/author retesting own pull request",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{OkToTest: []string{"ci-admins", "role:author"}}),
+				event:      &info.Event{TriggerComment: "/retest"},
+			},
+			args: args{
+				tType: triggertype.Retest,
+			},
+			senderRole: info.RoleAuthor,
+			want:       ResultAllowed,
+		},
+		{
+			name: "disallowed/author testing own pull request",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{OkToTest: []string{"role:author"}}),
+				event:      &info.Event{TriggerComment: "/test e2e"},
+			},
+			args: args{
+				tType: triggertype.Retest,
+			},
+			senderRole:           info.RoleAuthor,
+			want:                 ResultDisallowed,
+			expectedLogsSnippets: []string{"policy check: retest, user:  with the role none has none of the allowed roles: [author]"},
+		},
+		{
+			name: "disallowed/author ok-to-test own pull request",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{OkToTest: []string{"role:author"}}),
+				event:      &info.Event{TriggerComment: "/ok-to-test"},
+			},
+			args: args{
+				tType: triggertype.OkToTest,
+			},
+			senderRole: info.RoleAuthor,
+			want:       ResultDisallowed,
+		},
+		{
+			name: "notset/teams ignored",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{OkToTest: []string{"ci-admins"}}),
+			},
+			args: args{
+				tType: triggertype.OkToTest,
+			},
+			ignoreTeams:     true,
+			vcsReplyAllowed: true,
+			want:            ResultNotSet,
+		},
+		{
+			name: "disallowed/teams ignored beside the roles",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{OkToTest: []string{"ci-admins", "role:maintainer"}}),
+			},
+			args: args{
+				tType: triggertype.OkToTest,
+			},
+			ignoreTeams:     true,
+			senderRole:      info.RoleCollaborator,
+			vcsReplyAllowed: true,
+			want:            ResultDisallowed,
+		},
+		{
+			name: "allowed/role not allowed but member of the team",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{OkToTest: []string{"ci-admins", "role:maintainer"}}),
+				event:      info.NewEvent(),
+			},
+			args: args{
+				tType: triggertype.OkToTest,
+			},
+			senderRole:      info.RoleCollaborator,
+			vcsReplyAllowed: true,
+			want:            ResultAllowed,
+		},
+		{
+			name: "disallowed/role not allowed",
+			fields: fields{
+				repository: newRepoWithPolicy(&v1alpha1.Policy{OkToTest: []string{"role:author"}}),
+				event:      info.NewEvent(),
+			},
+			args: args{
+				tType: triggertype.Retest,
+			},
+			vcsReplyAllowed:      true,
+			want:                 ResultDisallowed,
+			expectedLogsSnippets: []string{"policy check: retest, user:  with the role none has none of the allowed roles: [author]"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -213,6 +318,7 @@ func TestPolicy_IsAllowed(t *testing.T) {
 			vcx := &testprovider.TestProviderImp{
 				PolicyDisallowing:   !tt.vcsReplyAllowed,
 				AllowedInOwnersFile: tt.allowedInOwnersFile,
+				SenderRoleReply:     tt.senderRole,
 			}
 			if tt.fields.event == nil {
 				tt.fields.event = info.NewEvent()
@@ -223,6 +329,7 @@ func TestPolicy_IsAllowed(t *testing.T) {
 				VCX:          vcx,
 				Logger:       logger,
 				EventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+				IgnoreTeams:  tt.ignoreTeams,
 			}
 			got, reason := p.IsAllowed(ctx, tt.args.tType)
 			switch got {
@@ -0,0 +1,48 @@
+package policy
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+)
+
+// the version update in the title of the pull requests of Dependabot or
+// Renovate, ie: "Bump golang.org/x/net from 0.22.0 to 0.23.0".
+var versionUpdateRegex = regexp.MustCompile(`(?i)\bfrom\s+v?(\d+)\.\d+\S*\s+to\s+v?(\d+)\.\d+`)
+
+// IsTrustedBot returns true if the sender is one of the trusted bots of the
+// policy of the Repository.
+func IsTrustedBot(repo *v1alpha1.Repository, sender string) bool {
+	if sender == "" || repo == nil || repo.Spec.Settings == nil || repo.Spec.Settings.Policy == nil {
+		return false
+	}
+	for _, bot := range repo.Spec.Settings.Policy.TrustedBots {
+		if strings.EqualFold(strings.TrimSpace(bot), sender) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAutoMerge returns true if the pull request of the sender, with the
+// title, can be merged automatically once its PipelineRuns have succeeded:
+// the policy enables it, the sender is a trusted bot and the title updates a
+// dependency to a version with the same major version.
+func CanAutoMerge(repo *v1alpha1.Repository, sender, title string) bool {
+	if !IsTrustedBot(repo, sender) || !repo.Spec.Settings.Policy.AutoMergeTrustedBots {
+		return false
+	}
+	return IsPatchOrMinorUpdate(title)
+}
+
+// IsPatchOrMinorUpdate returns true if the first line of the title updates a
+// version to another one with the same major version.
+func IsPatchOrMinorUpdate(title string) bool {
+	title, _, _ = strings.Cut(title, "\n")
+	m := versionUpdateRegex.FindStringSubmatch(title)
+	if m == nil {
+		return false
+	}
+	return m[1] == m[2]
+}
@@ -0,0 +1,49 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+)
+
+func TestIsPatchOrMinorUpdate(t *testing.T) {
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{title: "Bump golang.org/x/net from 0.22.0 to 0.23.0", want: true},
+		{title: "build(deps): bump actions/checkout from v4.1.1 to v4.1.2", want: true},
+		{title: "Update dependency eslint from 8.56 to 8.57", want: true},
+		{title: "Bump github.com/google/go-github from 60.0.0 to 61.0.0", want: false},
+		{title: "Bump golang.org/x/net\n\nfrom 0.22.0 to 0.23.0", want: false},
+		{title: "Update the documentation", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, IsPatchOrMinorUpdate(tt.title), tt.want)
+		})
+	}
+}
+
+func TestCanAutoMerge(t *testing.T) {
+	repo := &v1alpha1.Repository{
+		Spec: v1alpha1.RepositorySpec{
+			Settings: &v1alpha1.Settings{
+				Policy: &v1alpha1.Policy{TrustedBots: []string{"dependabot[bot]", " renovate[bot] "}},
+			},
+		},
+	}
+	title := "Bump golang.org/x/net from 0.22.0 to 0.23.0"
+	assert.Assert(t, IsTrustedBot(repo, "Dependabot[bot]"))
+	assert.Assert(t, IsTrustedBot(repo, "renovate[bot]"))
+	assert.Assert(t, !IsTrustedBot(repo, "dependabot"))
+	assert.Assert(t, !IsTrustedBot(repo, ""))
+	assert.Assert(t, !IsTrustedBot(&v1alpha1.Repository{}, "dependabot[bot]"))
+
+	assert.Assert(t, !CanAutoMerge(repo, "dependabot[bot]", title))
+	repo.Spec.Settings.Policy.AutoMergeTrustedBots = true
+	assert.Assert(t, CanAutoMerge(repo, "dependabot[bot]", title))
+	assert.Assert(t, !CanAutoMerge(repo, "someone", title))
+	assert.Assert(t, !CanAutoMerge(repo, "dependabot[bot]", "Bump golang.org/x/net from 0.22.0 to 1.0.0"))
+}
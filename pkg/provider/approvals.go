@@ -0,0 +1,19 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// PullRequestReviewEventType is the event type of the pull request reviews,
+// they run the PipelineRuns gated by the min-approvals annotation.
+const PullRequestReviewEventType = "pull_request_review"
+
+// ApprovalsInterface is implemented by the providers able to count the
+// approvals of the pull request of an event.
+type ApprovalsInterface interface {
+	// CountApprovals returns the number of approvals of the pull request of
+	// the event by the users with write access to the repository.
+	CountApprovals(ctx context.Context, event *info.Event) (int, error)
+}
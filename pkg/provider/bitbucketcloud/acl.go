@@ -8,6 +8,7 @@ import (
 	"github.com/ktrysmt/go-bitbucket"
 	"github.com/mitchellh/mapstructure"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud/types"
 )
@@ -22,6 +23,11 @@ func (v *Provider) IsAllowed(ctx context.Context, event *info.Event) (bool, erro
 		return true, nil
 	}
 
+	// a comment on a commit has no pull request to be given an /ok-to-test on
+	if opscomments.IsCommitComment(event) {
+		return false, nil
+	}
+
 	// Check then from comment if there is a approved user that has done a /ok-to-test
 	return v.checkOkToTestCommentFromApprovedMember(ctx, event)
 }
@@ -0,0 +1,80 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ktrysmt/go-bitbucket"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"golang.org/x/oauth2"
+	oauthbitbucket "golang.org/x/oauth2/bitbucket"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	// AuthTypeAppPassword authenticates with the app password of the user.
+	AuthTypeAppPassword = "app-password"
+	// AuthTypeWorkspaceToken authenticates with a workspace, project or
+	// repository access token.
+	AuthTypeWorkspaceToken = "workspace-token"
+	// AuthTypeOAuth authenticates with the client credentials of an OAuth
+	// consumer, its key is the user and its secret the secret.
+	AuthTypeOAuth = "oauth"
+
+	// tokenAuthUser is the user git authenticates with the access tokens.
+	tokenAuthUser = "x-token-auth"
+)
+
+// oauthTokenURL is where the access tokens of the OAuth consumers are created.
+var oauthTokenURL = oauthbitbucket.Endpoint.TokenURL
+
+// newClient returns a client authenticated with the secret of the git
+// provider as its auth type says. The access tokens of the OAuth consumers
+// are refreshed by the HTTP client when they expire, the first one is set as
+// the token of the event with the user of the access tokens so git can clone
+// with it.
+func newClient(ctx context.Context, httpClient *http.Client, p *info.Provider) (*bitbucket.Client, error) {
+	switch p.AuthType {
+	case "", AuthTypeAppPassword:
+		if p.User == "" {
+			return nil, fmt.Errorf("no git_provider.user has been in repo crd")
+		}
+		client := bitbucket.NewBasicAuth(p.User, p.Token)
+		client.HttpClient = httpClient
+		return client, nil
+	case AuthTypeWorkspaceToken:
+		client := bitbucket.NewOAuthbearerToken(p.Token)
+		client.HttpClient = httpClient
+		p.User = tokenAuthUser
+		return client, nil
+	case AuthTypeOAuth:
+		if p.User == "" {
+			return nil, fmt.Errorf("no git_provider.user has been set in repo crd with the key of the oauth consumer")
+		}
+		conf := &clientcredentials.Config{
+			ClientID:     p.User,
+			ClientSecret: p.Token,
+			TokenURL:     oauthTokenURL,
+		}
+		source := conf.TokenSource(context.WithValue(ctx, oauth2.HTTPClient, httpClient))
+		token, err := source.Token()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get an access token for the oauth consumer %s: %w", p.User, err)
+		}
+		// the bearer token is set on the requests by the oauth2 transport
+		client := bitbucket.NewOAuthbearerToken("")
+		client.HttpClient = &http.Client{
+			Transport: &oauth2.Transport{Source: source, Base: httpClient.Transport},
+			Timeout:   httpClient.Timeout,
+		}
+		// the event now carries an access token, for the clients set again
+		// with it
+		p.User = tokenAuthUser
+		p.Token = token.AccessToken
+		p.AuthType = AuthTypeWorkspaceToken
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown git_provider.auth_type %s, must be one of %s, %s or %s", p.AuthType, AuthTypeAppPassword, AuthTypeWorkspaceToken, AuthTypeOAuth)
+	}
+}
@@ -0,0 +1,101 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		provider      *info.Provider
+		wantAuth      string
+		wantUser      string
+		wantToken     string
+		wantErrSubstr string
+	}{
+		{
+			name:      "app password",
+			provider:  &info.Provider{User: "user", Token: "password"},
+			wantAuth:  "Basic dXNlcjpwYXNzd29yZA==",
+			wantUser:  "user",
+			wantToken: "password",
+		},
+		{
+			name:          "app password without user",
+			provider:      &info.Provider{Token: "password", AuthType: AuthTypeAppPassword},
+			wantErrSubstr: "no git_provider.user",
+		},
+		{
+			name:      "workspace token",
+			provider:  &info.Provider{Token: "workspace-token", AuthType: AuthTypeWorkspaceToken},
+			wantAuth:  "Bearer workspace-token",
+			wantUser:  tokenAuthUser,
+			wantToken: "workspace-token",
+		},
+		{
+			name:      "oauth consumer",
+			provider:  &info.Provider{User: "key", Token: "secret", AuthType: AuthTypeOAuth},
+			wantAuth:  "Bearer access-1",
+			wantUser:  tokenAuthUser,
+			wantToken: "access-1",
+		},
+		{
+			name:          "oauth consumer refused",
+			provider:      &info.Provider{User: "key", Token: "wrong", AuthType: AuthTypeOAuth},
+			wantErrSubstr: "cannot get an access token for the oauth consumer key",
+		},
+		{
+			name:          "unknown auth type",
+			provider:      &info.Provider{User: "user", Token: "token", AuthType: "ssh"},
+			wantErrSubstr: "unknown git_provider.auth_type ssh",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := 0
+			gotAuth := ""
+			mux := http.NewServeMux()
+			mux.HandleFunc("/site/oauth2/access_token", func(w http.ResponseWriter, r *http.Request) {
+				assert.NilError(t, r.ParseForm())
+				assert.Equal(t, r.Form.Get("grant_type"), "client_credentials")
+				if user, password, _ := r.BasicAuth(); user != "key" || password != "secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					_, _ = fmt.Fprint(w, `{"error":"invalid_client"}`)
+					return
+				}
+				tokens++
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"bearer","expires_in":7200}`, tokens)
+			})
+			mux.HandleFunc("/2.0/user", func(w http.ResponseWriter, r *http.Request) {
+				gotAuth = r.Header.Get("Authorization")
+				_, _ = fmt.Fprint(w, `{"username":"user"}`)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+			defer env.Patch(t, "BITBUCKET_API_BASE_URL", server.URL+"/2.0")()
+			defer func(tokenURL string) { oauthTokenURL = tokenURL }(oauthTokenURL)
+			oauthTokenURL = server.URL + "/site/oauth2/access_token"
+
+			client, err := newClient(context.Background(), server.Client(), tt.provider)
+			if tt.wantErrSubstr != "" {
+				assert.ErrorContains(t, err, tt.wantErrSubstr)
+				return
+			}
+			assert.NilError(t, err)
+			_, err = client.User.Profile()
+			assert.NilError(t, err)
+			assert.Equal(t, gotAuth, tt.wantAuth)
+			assert.Equal(t, tt.provider.User, tt.wantUser)
+			assert.Equal(t, tt.provider.Token, tt.wantToken)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package bitbucketcloud
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,6 +13,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
@@ -21,6 +24,7 @@ import (
 )
 
 var _ provider.Interface = (*Provider)(nil)
+var _ provider.RevisionFileInterface = (*Provider)(nil)
 
 type Provider struct {
 	Client        *bitbucket.Client
@@ -30,6 +34,7 @@ type Provider struct {
 	Token, APIURL *string
 	Username      *string
 	provenance    string
+	repo          *v1alpha1.Repository
 }
 
 // CheckPolicyAllowing TODO: Implement ME.
@@ -46,11 +51,6 @@ func (v *Provider) SetPacInfo(pacInfo *info.PacOpts) {
 	v.pacInfo = pacInfo
 }
 
-const taskStatusTemplate = `| **Status** | **Duration** | **Name** |
-| --- | --- | --- |
-{{range $taskrun := .TaskRunList }}|{{ formatCondition $taskrun.PipelineRunTaskRunStatus.Status.Conditions }}|{{ formatDuration $taskrun.PipelineRunTaskRunStatus.Status.StartTime $taskrun.PipelineRunTaskRunStatus.Status.CompletionTime }}|{{ $taskrun.ConsoleLogURL }}|
-{{ end }}`
-
 func (v *Provider) Validate(_ context.Context, _ *params.Run, _ *info.Event) error {
 	return nil
 }
@@ -61,13 +61,16 @@ func (v *Provider) SetLogger(logger *zap.SugaredLogger) {
 
 func (v *Provider) GetConfig() *info.ProviderConfig {
 	return &info.ProviderConfig{
-		TaskStatusTMPL: taskStatusTemplate,
-		APIURL:         bitbucket.DEFAULT_BITBUCKET_API_BASE_URL,
-		Name:           "bitbucket-cloud",
+		APIURL: bitbucket.DEFAULT_BITBUCKET_API_BASE_URL,
+		Name:   "bitbucket-cloud",
 	}
 }
 
-func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusopts provider.StatusOpts) error {
+func (v *Provider) CreateStatus(ctx context.Context, event *info.Event, statusopts provider.StatusOpts) error {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationStatus)
+	defer done()
+	defer v.withContext(ctx)()
+
 	switch statusopts.Conclusion {
 	case "skipped":
 		statusopts.Conclusion = "STOPPED"
@@ -93,8 +96,10 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusopts
 		detailsURL = statusopts.DetailsURL
 	}
 
+	checkName := provider.GetCheckName(statusopts, v.pacInfo, v.repo, event)
 	cso := &bitbucket.CommitStatusOptions{
-		Key:         v.pacInfo.ApplicationName,
+		Key:         statusKey(checkName),
+		Name:        checkName,
 		Url:         detailsURL,
 		State:       statusopts.Conclusion,
 		Description: statusopts.Title,
@@ -115,16 +120,16 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusopts
 	}
 	if statusopts.Conclusion != "STOPPED" && statusopts.Status == "completed" &&
 		statusopts.Text != "" && event.EventType == triggertype.PullRequest.String() {
-		onPr := ""
-		if statusopts.OriginalPipelineRunName != "" {
-			onPr = "/" + statusopts.OriginalPipelineRunName
+		content := fmt.Sprintf("**%s** - %s\n\n%s", checkName, statusopts.Title, statusopts.Text)
+		if provider.UpdateStatusComment(v.repo) {
+			return v.createOrUpdateStatusComment(event, statusopts.OriginalPipelineRunName, content)
 		}
 		_, err = v.Client.Repositories.PullRequests.AddComment(
 			&bitbucket.PullRequestCommentOptions{
 				Owner:         event.Organization,
 				RepoSlug:      event.Repository,
 				PullRequestID: strconv.Itoa(event.PullRequestNumber),
-				Content:       fmt.Sprintf("**%s%s** - %s\n\n%s", v.pacInfo.ApplicationName, onPr, statusopts.Title, statusopts.Text),
+				Content:       content,
 			})
 		if err != nil {
 			return err
@@ -133,7 +138,65 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusopts
 	return nil
 }
 
-func (v *Provider) GetTektonDir(_ context.Context, event *info.Event, path, provenance string) (string, error) {
+// maxStatusKeyLength is the longest key of a commit status accepted by
+// Bitbucket Cloud.
+const maxStatusKeyLength = 40
+
+// statusKey returns the key of the commit status of a check name, the statuses
+// with the same key replace each other. The check names too long for a key are
+// shortened with a hash to stay unique.
+func statusKey(checkName string) string {
+	if len(checkName) <= maxStatusKeyLength {
+		return checkName
+	}
+	sum := sha256.Sum256([]byte(checkName))
+	suffix := hex.EncodeToString(sum[:])[:8]
+	return checkName[:maxStatusKeyLength-len(suffix)-1] + "-" + suffix
+}
+
+// createOrUpdateStatusComment updates the comment we have previously posted
+// for the PipelineRun on this SHA, with the previous statuses kept as history,
+// or creates it if there is none yet.
+func (v *Provider) createOrUpdateStatusComment(event *info.Event, prName, content string) error {
+	marker := provider.StatusCommentMarker(prName, event.SHA)
+	opts := &bitbucket.PullRequestCommentOptions{
+		Owner:         event.Organization,
+		RepoSlug:      event.Repository,
+		PullRequestID: strconv.Itoa(event.PullRequestNumber),
+	}
+	// the client follows the next links of the comments, the status comment
+	// of a busy pull request is looked up on all their pages
+	commentsIntf, err := v.Client.Repositories.PullRequests.GetComments(&bitbucket.PullRequestsOptions{
+		Owner:    event.Organization,
+		RepoSlug: event.Repository,
+		ID:       strconv.Itoa(event.PullRequestNumber),
+	})
+	if err != nil {
+		return err
+	}
+	comments := &types.Comments{}
+	if err := mapstructure.Decode(commentsIntf, comments); err != nil {
+		return err
+	}
+	for _, comment := range comments.Values {
+		if !strings.HasPrefix(comment.Content.Raw, marker) {
+			continue
+		}
+		opts.CommentId = strconv.Itoa(comment.ID)
+		opts.Content = provider.StatusCommentBody(marker, content, comment.Content.Raw)
+		_, err := v.Client.Repositories.PullRequests.UpdateComment(opts)
+		return err
+	}
+	opts.Content = provider.StatusCommentBody(marker, content, "")
+	_, err = v.Client.Repositories.PullRequests.AddComment(opts)
+	return err
+}
+
+func (v *Provider) GetTektonDir(ctx context.Context, event *info.Event, path, provenance string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+	defer v.withContext(ctx)()
+
 	v.provenance = provenance
 	repositoryFiles, err := v.getDir(event, path)
 	if err != nil {
@@ -144,12 +207,13 @@ func (v *Provider) GetTektonDir(_ context.Context, event *info.Event, path, prov
 }
 
 func (v *Provider) getDir(event *info.Event, path string) ([]bitbucket.RepositoryFile, error) {
-	// default set provenance from the SHA
-	revision := event.SHA
-	if v.provenance == "default_branch" {
-		revision = event.DefaultBranch
+	revision := v.getProvenanceRevision(event)
+	switch v.provenance {
+	case "default_branch":
 		v.Logger.Infof("Using PipelineRun definition from default_branch: %s", event.DefaultBranch)
-	} else {
+	case "merge_base":
+		v.Logger.Infof("Using PipelineRun definition from target branch %s, merge base is not available on Bitbucket Cloud", revision)
+	default:
 		v.Logger.Infof("Using PipelineRun definition from source pull request SHA: %s", event.SHA)
 	}
 	repoFileOpts := &bitbucket.RepositoryFilesOptions{
@@ -166,25 +230,66 @@ func (v *Provider) getDir(event *info.Event, path string) ([]bitbucket.Repositor
 	return repositoryFiles, nil
 }
 
-func (v *Provider) GetFileInsideRepo(_ context.Context, event *info.Event, path, _ string) (string, error) {
-	revision := event.SHA
-	if v.provenance == "default_branch" {
-		revision = event.DefaultBranch
+// getProvenanceRevision returns the revision where to get the PipelineRun
+// definitions from according to the provenance setting. The API doesn't let us
+// get the merge base of a pull request, the target branch is used instead
+// which is as safe since it cannot be modified by the pull request.
+func (v *Provider) getProvenanceRevision(event *info.Event) string {
+	switch v.provenance {
+	case "default_branch":
+		return event.DefaultBranch
+	case "merge_base":
+		if event.TriggerTarget == triggertype.PullRequest && event.BaseBranch != "" {
+			return event.BaseBranch
+		}
 	}
+	// default set provenance from the SHA
+	return event.SHA
+}
+
+func (v *Provider) GetFileInsideRepo(ctx context.Context, event *info.Event, path, _ string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+	defer v.withContext(ctx)()
+
+	return v.getBlob(event, v.getProvenanceRevision(event), path)
+}
+
+// GetFileAtRevision returns the content of a file of the repository at a
+// revision.
+func (v *Provider) GetFileAtRevision(ctx context.Context, event *info.Event, path, revision string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+	defer v.withContext(ctx)()
+
 	return v.getBlob(event, revision, path)
 }
 
-func (v *Provider) SetClient(_ context.Context, run *params.Run, event *info.Event, _ *v1alpha1.Repository, _ *events.EventEmitter) error {
+// withContext sends the requests of the client with the context of an
+// operation, the client does not take the context of the calls. The returned
+// function restores the client.
+func (v *Provider) withContext(ctx context.Context) func() {
+	if v.Client == nil || v.Client.HttpClient == nil {
+		return func() {}
+	}
+	httpClient := v.Client.HttpClient
+	v.Client.HttpClient = provider.ClientWithContext(ctx, httpClient)
+	return func() { v.Client.HttpClient = httpClient }
+}
+
+func (v *Provider) SetClient(ctx context.Context, run *params.Run, event *info.Event, repo *v1alpha1.Repository, _ *events.EventEmitter) error {
 	if event.Provider.Token == "" {
 		return fmt.Errorf("no git_provider.secret has been set in the repo crd")
 	}
-	if event.Provider.User == "" {
-		return fmt.Errorf("no git_provider.user has been in repo crd")
+	client, err := newClient(ctx, provider.HTTPClient(ctx, run, v.GetConfig().Name), event.Provider)
+	if err != nil {
+		return err
 	}
-	v.Client = bitbucket.NewBasicAuth(event.Provider.User, event.Provider.Token)
+	v.Client = client
 	v.Token = &event.Provider.Token
 	v.Username = &event.Provider.User
 	v.run = run
+	v.repo = repo
 	return nil
 }
 
@@ -220,6 +325,7 @@ func (v *Provider) GetCommitInfo(_ context.Context, event *info.Event) error {
 
 	// Some silliness since we get first the account id and we fill it properly after
 	event.SHATitle = commitinfo.Message
+	event.SHAMessage = commitinfo.Message
 	event.SHAURL = commitinfo.Links.HTML.HRef
 	event.SHA = commitinfo.Hash
 
@@ -232,16 +338,32 @@ func (v *Provider) GetCommitInfo(_ context.Context, event *info.Event) error {
 		return err
 	}
 	event.DefaultBranch = repo.Mainbranch.Name
+
+	// a GitOps comment on a commit only runs the PipelineRuns of a branch
+	// when the commit is the head of that branch.
+	if opscomments.IsCommitComment(event) {
+		if event.HeadBranch == "" {
+			event.HeadBranch, event.BaseBranch = event.DefaultBranch, event.DefaultBranch
+		}
+		branch, err := v.Client.Repositories.Repository.GetBranch(&bitbucket.RepositoryBranchOptions{
+			Owner:      event.Organization,
+			RepoSlug:   event.Repository,
+			BranchName: event.HeadBranch,
+		})
+		if err != nil {
+			return err
+		}
+		if hash, _ := branch.Target["hash"].(string); hash != event.SHA {
+			return fmt.Errorf("provided branch %s does not contain sha %s", event.HeadBranch, event.SHA)
+		}
+	}
 	return nil
 }
 
 func (v *Provider) concatAllYamlFiles(objects []bitbucket.RepositoryFile, event *info.Event) (string, error) {
 	var allTemplates string
 
-	revision := event.SHA
-	if v.provenance == "default_branch" {
-		revision = event.DefaultBranch
-	}
+	revision := v.getProvenanceRevision(event)
 	for _, value := range objects {
 		if value.Type == "commit_directory" {
 			objects, err := v.getDir(event, value.Path)
@@ -256,8 +378,7 @@ func (v *Provider) concatAllYamlFiles(objects []bitbucket.RepositoryFile, event
 				allTemplates += "---"
 			}
 			allTemplates += fmt.Sprintf("\n%s\n", subdirdata)
-		} else if strings.HasSuffix(value.Path, ".yaml") ||
-			strings.HasSuffix(value.Path, ".yml") {
+		} else if provider.IsPipelineRunFile(v.repo, value.Path) {
 			data, err := v.getBlob(event, revision, value.Path)
 			if err != nil {
 				return "", err
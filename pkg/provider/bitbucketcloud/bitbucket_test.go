@@ -1,6 +1,8 @@
 package bitbucketcloud
 
 import (
+	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -8,6 +10,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	bbcloudtest "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud/test"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud/types"
@@ -156,6 +159,7 @@ func TestGetCommitInfo(t *testing.T) {
 		wantErr    bool
 		commitinfo types.Commit
 		repoinfo   *bitbucket.Repository
+		branchHead string
 	}{
 		{
 			name:  "Get commit info",
@@ -196,6 +200,33 @@ func TestGetCommitInfo(t *testing.T) {
 				Mainbranch: bitbucket.RepositoryBranch{Name: "branshe"},
 			},
 		},
+		{
+			name: "Get commit info of a comment on the head commit of the branch",
+			event: bbcloudtest.MakeEvent(&info.Event{
+				SHA:            "headcommit",
+				TriggerTarget:  triggertype.Push,
+				TriggerComment: "/retest",
+			}),
+			commitinfo: types.Commit{Hash: "headcommit", Message: "Das Commit"},
+			repoinfo: &bitbucket.Repository{
+				Mainbranch: bitbucket.RepositoryBranch{Name: "branshe"},
+			},
+			branchHead: "headcommit",
+		},
+		{
+			name: "Get commit info of a comment on a commit not the head of the branch",
+			event: bbcloudtest.MakeEvent(&info.Event{
+				SHA:            "oldcommit",
+				TriggerTarget:  triggertype.Push,
+				TriggerComment: "/retest",
+			}),
+			commitinfo: types.Commit{Hash: "oldcommit", Message: "Das Commit"},
+			repoinfo: &bitbucket.Repository{
+				Mainbranch: bitbucket.RepositoryBranch{Name: "branshe"},
+			},
+			branchHead: "headcommit",
+			wantErr:    true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -207,6 +238,9 @@ func TestGetCommitInfo(t *testing.T) {
 				tt.commitinfo,
 			})
 			bbcloudtest.MuxRepoInfo(t, mux, tt.event, tt.repoinfo)
+			if tt.branchHead != "" {
+				bbcloudtest.MuxBranch(t, mux, tt.event, tt.event.HeadBranch, tt.branchHead)
+			}
 
 			if err := v.GetCommitInfo(ctx, tt.event); (err != nil) != tt.wantErr {
 				t.Errorf("GetCommitInfo() error = %v, wantErr %v", err, tt.wantErr)
@@ -315,3 +349,40 @@ func TestCreateStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusKey(t *testing.T) {
+	assert.Equal(t, statusKey("Pipelines as Code CI / pr"), "Pipelines as Code CI / pr")
+	long := statusKey("Pipelines as Code CI / 1. build / a-very-long-pipelinerun-name")
+	assert.Equal(t, len(long), maxStatusKeyLength)
+	assert.Assert(t, long != statusKey("Pipelines as Code CI / 1. build / a-very-long-pipelinerun-name-2"))
+}
+
+func TestCreateOrUpdateStatusCommentPaginated(t *testing.T) {
+	client, mux, tearDown := bbcloudtest.SetupBBCloudClient(t)
+	defer tearDown()
+	v := &Provider{Client: client}
+	event := &info.Event{Organization: "owner", Repository: "repo", PullRequestNumber: 1, SHA: "sha"}
+	marker := provider.StatusCommentMarker("pr", "sha")
+
+	commentsURL := "/repositories/owner/repo/pullrequests/1/comments/"
+	mux.HandleFunc(commentsURL, func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			t.Error("the status comment of the second page has not been found")
+		case r.URL.Query().Get("page") == "2":
+			fmt.Fprintf(rw, `{"values": [{"id": 2, "content": {"raw": %q}}]}`, marker+"\n**Pipelines as Code CI / pr** - failed")
+		default:
+			fmt.Fprintf(rw, `{"values": [{"id": 1, "content": {"raw": "hello"}}], "next": %q}`,
+				client.GetApiBaseURL()+commentsURL+"?page=2")
+		}
+	})
+	updated := false
+	mux.HandleFunc(commentsURL+"2", func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Method, http.MethodPut)
+		updated = true
+		fmt.Fprint(rw, `{}`)
+	})
+
+	assert.NilError(t, v.createOrUpdateStatusComment(event, "pr", "**Pipelines as Code CI / pr** - completed"))
+	assert.Assert(t, updated)
+}
@@ -50,6 +50,15 @@ func (v *Provider) Detect(req *http.Request, payload string, logger *zap.Sugared
 			if provider.IsCancelComment(e.Comment.Content.Raw) {
 				return setLoggerAndProceed(true, "", nil)
 			}
+			if provider.IsLintComment(e.Comment.Content.Raw) {
+				return setLoggerAndProceed(true, "", nil)
+			}
+			if provider.IsPauseResumeComment(e.Comment.Content.Raw) {
+				return setLoggerAndProceed(true, "", nil)
+			}
+			if provider.IsFreezeComment(e.Comment.Content.Raw) {
+				return setLoggerAndProceed(true, "", nil)
+			}
 		}
 		return setLoggerAndProceed(false, fmt.Sprintf("not a valid gitops comment: \"%s\"", event), nil)
 
@@ -61,6 +70,12 @@ func (v *Provider) Detect(req *http.Request, payload string, logger *zap.Sugared
 		}
 		return setLoggerAndProceed(false, fmt.Sprintf("invalid push event: \"%s\"", event), nil)
 
+	case *types.CommitCommentEvent:
+		if provider.IsTestRetestComment(e.Comment.Content.Raw) || provider.IsCancelComment(e.Comment.Content.Raw) {
+			return setLoggerAndProceed(true, "", nil)
+		}
+		return setLoggerAndProceed(false, fmt.Sprintf("not a valid gitops comment: \"%s\"", event), nil)
+
 	default:
 		return setLoggerAndProceed(false, "", fmt.Errorf("bitbucket-cloud: event \"%s\" is not supported", event))
 	}
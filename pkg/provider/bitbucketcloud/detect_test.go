@@ -49,6 +49,25 @@ func TestProvider_Detect(t *testing.T) {
 			processReq: true,
 			name:       "push event",
 		},
+		{
+			name: "commit comment event with retest",
+			event: types.CommitCommentEvent{
+				Comment: types.Comment{Content: types.Content{Raw: "/retest branch:nightly"}},
+			},
+			eventType:  "repo:commit_comment_created",
+			isBC:       true,
+			processReq: true,
+		},
+		{
+			name: "commit comment event without a gitops command",
+			event: types.CommitCommentEvent{
+				Comment: types.Comment{Content: types.Content{Raw: "looks good"}},
+			},
+			eventType:  "repo:commit_comment_created",
+			isBC:       true,
+			processReq: false,
+			wantReason: "not a valid gitops comment: \"repo:commit_comment_created\"",
+		},
 		{
 			name:       "pull_request event",
 			event:      types.PullRequestEvent{},
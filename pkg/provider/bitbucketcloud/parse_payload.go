@@ -86,6 +86,8 @@ func parsePayloadType(event, rawPayload string) (interface{}, error) {
 		localEvent = triggertype.PullRequest.String()
 	} else if event == "repo:push" {
 		localEvent = "push"
+	} else if event == "repo:commit_comment_created" {
+		localEvent = "commit_comment"
 	}
 
 	switch localEvent {
@@ -93,6 +95,8 @@ func parsePayloadType(event, rawPayload string) (interface{}, error) {
 		payload = &types.PullRequestEvent{}
 	case "push":
 		payload = &types.PushRequestEvent{}
+	case "commit_comment":
+		payload = &types.CommitCommentEvent{}
 	default:
 		return nil, nil
 	}
@@ -148,6 +152,9 @@ func (v *Provider) ParsePayload(ctx context.Context, run *params.Run, request *h
 		processedEvent.PullRequestNumber = e.PullRequest.ID
 		processedEvent.PullRequestTitle = e.PullRequest.Title
 	case *types.PushRequestEvent:
+		if len(e.Push.Changes) == 0 {
+			return nil, fmt.Errorf("no changes attached to this push event")
+		}
 		processedEvent.Event = "push"
 		processedEvent.TriggerTarget = "push"
 		processedEvent.EventType = "push"
@@ -161,6 +168,25 @@ func (v *Provider) ParsePayload(ctx context.Context, run *params.Run, request *h
 		processedEvent.HeadURL = e.Push.Changes[0].Old.Target.Links.HTML.HRef
 		processedEvent.AccountID = e.Actor.AccountID
 		processedEvent.Sender = e.Actor.Nickname
+	case *types.CommitCommentEvent:
+		processedEvent.Event = "push"
+		// the branch of the commit is not in the payload, GetCommitInfo uses
+		// the default branch unless the comment asks for another one and
+		// checks the commit is the head of the branch.
+		branchName, err := opscomments.SetEventTypeAndTargetPush(processedEvent, e.Comment.Content.Raw)
+		if err != nil {
+			return nil, err
+		}
+		processedEvent.HeadBranch = branchName
+		processedEvent.BaseBranch = branchName
+		processedEvent.Organization = e.Repository.Workspace.Slug
+		processedEvent.Repository = e.Repository.Name
+		processedEvent.SHA = e.Commit.Hash
+		processedEvent.URL = e.Repository.Links.HTML.HRef
+		processedEvent.BaseURL = processedEvent.URL
+		processedEvent.HeadURL = processedEvent.URL
+		processedEvent.AccountID = e.Actor.AccountID
+		processedEvent.Sender = e.Actor.Nickname
 	default:
 		return nil, fmt.Errorf("event %s is not recognized", event)
 	}
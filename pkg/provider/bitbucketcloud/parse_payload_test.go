@@ -31,6 +31,7 @@ func TestParsePayload(t *testing.T) {
 		additionalAllowedsourceIP string
 		targetPipelinerun         string
 		cancelPipelinerun         string
+		expectedBranch            string
 	}{
 		{
 			name:              "parse push request",
@@ -198,6 +199,27 @@ func TestParsePayload(t *testing.T) {
 			expectedSHA:       "sha",
 			cancelPipelinerun: "dummy",
 		},
+		{
+			name:              "retest comment on a commit",
+			expectedEventType: triggertype.Push.String(),
+			payloadEvent:      bbcloudtest.MakeCommitCommentEvent("account", "sender", "sha", "/retest dummy"),
+			eventType:         "repo:commit_comment_created",
+			expectedAccountID: "account",
+			expectedSender:    "sender",
+			expectedSHA:       "sha",
+			targetPipelinerun: "dummy",
+		},
+		{
+			name:              "cancel comment on a commit of a branch",
+			expectedEventType: triggertype.Push.String(),
+			payloadEvent:      bbcloudtest.MakeCommitCommentEvent("account", "sender", "sha", "/cancel dummy branch:nightly"),
+			eventType:         "repo:commit_comment_created",
+			expectedAccountID: "account",
+			expectedSender:    "sender",
+			expectedSHA:       "sha",
+			cancelPipelinerun: "dummy",
+			expectedBranch:    "nightly",
+		},
 		{
 			name:              "cancel all comment",
 			expectedEventType: opscomments.CancelCommentAllEventType.String(),
@@ -256,6 +278,9 @@ func TestParsePayload(t *testing.T) {
 			if tt.targetPipelinerun != "" {
 				assert.Equal(t, tt.targetPipelinerun, got.TargetTestPipelineRun, tt.targetPipelinerun, got.TargetTestPipelineRun)
 			}
+			if tt.expectedBranch != "" {
+				assert.Equal(t, tt.expectedBranch, got.HeadBranch)
+			}
 			if tt.cancelPipelinerun != "" {
 				assert.Equal(t, tt.cancelPipelinerun, got.TargetCancelPipelineRun, tt.cancelPipelinerun, got.TargetCancelPipelineRun)
 			}
@@ -144,6 +144,15 @@ func MuxRepoInfo(t *testing.T, mux *http.ServeMux, event *info.Event, repo *bitb
 	})
 }
 
+func MuxBranch(t *testing.T, mux *http.ServeMux, event *info.Event, branch, sha string) {
+	t.Helper()
+
+	path := fmt.Sprintf("/repositories/%s/%s/refs/branches/%s", event.Organization, event.Repository, branch)
+	mux.HandleFunc(path, func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(rw, `{"name": "%s", "target": {"hash": "%s"}}`, branch, sha)
+	})
+}
+
 func MuxCreateCommitstatus(t *testing.T, mux *http.ServeMux, event *info.Event, expectedDescSubstr string, expStatus provider.StatusOpts) {
 	t.Helper()
 
@@ -325,6 +334,32 @@ func MakePushEvent(accountid, nickname, sha string) types.PushRequestEvent {
 	}
 }
 
+func MakeCommitCommentEvent(accountid, nickname, sha, comment string) types.CommitCommentEvent {
+	return types.CommitCommentEvent{
+		Actor: types.User{
+			AccountID: accountid,
+			Nickname:  nickname,
+		},
+		Comment: types.Comment{
+			Content: types.Content{Raw: comment},
+		},
+		Commit: types.Commit{
+			Hash: sha,
+		},
+		Repository: types.Repository{
+			Workspace: types.Workspace{
+				Slug: "org",
+			},
+			Name: "repo",
+			Links: types.Links{
+				HTML: types.HTMLLink{
+					HRef: "https://vavar/repo/org",
+				},
+			},
+		},
+	}
+}
+
 // MakeEvent should we try to reflect? or json.Marshall? may be better ways, right?
 func MakeEvent(event *info.Event) *info.Event {
 	if event == nil {
@@ -72,6 +72,13 @@ type PushRequestEvent struct {
 	Push       Push `json:"push"`
 }
 
+type CommitCommentEvent struct {
+	Repository Repository `json:"repository"`
+	Actor      User       `json:"actor"`
+	Comment    Comment    `json:"comment"`
+	Commit     Commit     `json:"commit"`
+}
+
 type ChangeType struct {
 	Name   string
 	Target Commit
@@ -113,6 +120,7 @@ type Content struct {
 }
 
 type Comment struct {
+	ID      int     `json:"id"`
 	Content Content `json:"content"`
 	User    User
 }
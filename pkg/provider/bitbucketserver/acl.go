@@ -10,6 +10,7 @@ import (
 	bbv1 "github.com/gfleury/go-bitbucket-v1"
 	"github.com/mitchellh/mapstructure"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 )
 
@@ -24,6 +25,11 @@ func (v *Provider) IsAllowed(ctx context.Context, event *info.Event) (bool, erro
 		return true, nil
 	}
 
+	// a comment on a commit has no pull request to be given an /ok-to-test on
+	if opscomments.IsCommitComment(event) {
+		return false, nil
+	}
+
 	// Check then from comment if there is a approved user that has done a /ok-to-test
 	return v.checkOkToTestCommentFromApprovedMember(ctx, event)
 }
@@ -46,7 +52,7 @@ func (v *Provider) IsAllowedOwnersFile(ctx context.Context, event *info.Event) (
 }
 
 func (v *Provider) checkOkToTestCommentFromApprovedMember(ctx context.Context, event *info.Event) (bool, error) {
-	allPages, err := paginate(func(nextPage int) (*bbv1.APIResponse, error) {
+	allPages, err := paginate(ctx, func(nextPage int) (*bbv1.APIResponse, error) {
 		localVarOptionals := map[string]interface{}{
 			"fromType": "COMMENT",
 		}
@@ -115,7 +121,7 @@ func (v *Provider) checkMemberShipResults(results []interface{}, event *info.Eve
 
 func (v *Provider) checkMemberShip(ctx context.Context, event *info.Event) (bool, error) {
 	// Get permissions from project
-	allValues, err := paginate(func(nextPage int) (*bbv1.APIResponse, error) {
+	allValues, err := paginate(ctx, func(nextPage int) (*bbv1.APIResponse, error) {
 		localVarOptionals := map[string]interface{}{}
 		if nextPage > 0 {
 			localVarOptionals["start"] = int(nextPage)
@@ -134,7 +140,7 @@ func (v *Provider) checkMemberShip(ctx context.Context, event *info.Event) (bool
 	}
 
 	// Get permissions from repo
-	allValues, err = paginate(func(nextPage int) (*bbv1.APIResponse, error) {
+	allValues, err = paginate(ctx, func(nextPage int) (*bbv1.APIResponse, error) {
 		localVarOptionals := map[string]interface{}{}
 		if nextPage > 0 {
 			localVarOptionals["start"] = int(nextPage)
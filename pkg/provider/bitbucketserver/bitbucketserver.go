@@ -12,6 +12,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
@@ -19,14 +20,12 @@ import (
 	"go.uber.org/zap"
 )
 
-const taskStatusTemplate = `
-{{range $taskrun := .TaskRunList }}* **{{ formatCondition $taskrun.PipelineRunTaskRunStatus.Status.Conditions }}**  {{ $taskrun.ConsoleLogURL }} *{{ formatDuration $taskrun.Status.StartTime $taskrun.Status.CompletionTime }}*
-{{ end }}`
-
 var _ provider.Interface = (*Provider)(nil)
+var _ provider.RevisionFileInterface = (*Provider)(nil)
 
 type Provider struct {
 	Client                    *bbv1.APIClient
+	clientConfig              *bbv1.Configuration
 	Logger                    *zap.SugaredLogger
 	run                       *params.Run
 	pacInfo                   *info.PacOpts
@@ -36,6 +35,7 @@ type Provider struct {
 	apiURL                    string
 	provenance                string
 	projectKey                string
+	repo                      *v1alpha1.Repository
 }
 
 func (v *Provider) SetPacInfo(pacInfo *info.PacOpts) {
@@ -68,7 +68,11 @@ func sanitizeTitle(s string) string {
 	return strings.Split(s, "\n")[0]
 }
 
-func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts provider.StatusOpts) error {
+func (v *Provider) CreateStatus(ctx context.Context, event *info.Event, statusOpts provider.StatusOpts) error {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationStatus)
+	defer done()
+	defer v.withContext(ctx)()
+
 	detailsURL := event.Provider.URL
 	switch statusOpts.Conclusion {
 	case "skipped":
@@ -97,6 +101,7 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts
 		return fmt.Errorf("no token has been set, cannot set status")
 	}
 
+	checkName := provider.GetCheckName(statusOpts, v.pacInfo, v.repo, event)
 	key := statusOpts.PipelineRunName
 	if key == "" {
 		key = statusOpts.Conclusion
@@ -106,7 +111,7 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts
 		event.SHA,
 		bbv1.BuildStatus{
 			State:       statusOpts.Conclusion,
-			Name:        v.pacInfo.ApplicationName,
+			Name:        checkName,
 			Key:         key,
 			Description: statusOpts.Title,
 			Url:         detailsURL,
@@ -116,13 +121,8 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts
 		return err
 	}
 
-	onPr := ""
-	if statusOpts.OriginalPipelineRunName != "" {
-		onPr = "/" + statusOpts.OriginalPipelineRunName
-	}
 	bbcomment := bbv1.Comment{
-		Text: fmt.Sprintf("**%s%s** - %s\n\n%s", v.pacInfo.ApplicationName, onPr,
-			statusOpts.Title, statusOpts.Text),
+		Text: fmt.Sprintf("**%s** - %s\n\n%s", checkName, statusOpts.Title, statusOpts.Text),
 	}
 
 	if statusOpts.Conclusion == "SUCCESSFUL" && statusOpts.Status == "completed" &&
@@ -142,9 +142,12 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts
 func (v *Provider) concatAllYamlFiles(objects []string, runevent *info.Event) (string, error) {
 	var allTemplates string
 	for _, value := range objects {
-		if strings.HasSuffix(value, ".yaml") ||
-			strings.HasSuffix(value, ".yml") {
-			data, err := v.getRaw(runevent, runevent.SHA, value)
+		if provider.IsPipelineRunFile(v.repo, value) {
+			revision := runevent.SHA
+			if v.provenance == "merge_base" {
+				revision = v.mergeBaseRevision(runevent)
+			}
+			data, err := v.getRaw(runevent, revision, value)
 			if err != nil {
 				return "", err
 			}
@@ -169,16 +172,36 @@ func (v *Provider) getRaw(runevent *info.Event, revision, path string) (string,
 	return string(resp.Payload), nil
 }
 
-func (v *Provider) GetTektonDir(_ context.Context, event *info.Event, path, provenance string) (string, error) {
+// mergeBaseRevision returns the revision to use for the merge_base provenance.
+// The API doesn't let us get the merge base of a pull request, the target
+// branch is used instead which is as safe since it cannot be modified by the
+// pull request.
+func (v *Provider) mergeBaseRevision(event *info.Event) string {
+	if event.TriggerTarget == triggertype.PullRequest && event.BaseBranch != "" {
+		return event.BaseBranch
+	}
+	return event.SHA
+}
+
+func (v *Provider) GetTektonDir(ctx context.Context, event *info.Event, path, provenance string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+	defer v.withContext(ctx)()
+
 	v.provenance = provenance
-	allValues, err := paginate(func(nextPage int) (*bbv1.APIResponse, error) {
+	allValues, err := paginate(ctx, func(nextPage int) (*bbv1.APIResponse, error) {
 		// according to the docs, if no at parameters is specified it will default to the default branch
 		// cf: https://docs.atlassian.com/bitbucket-server/rest/4.1.0/bitbucket-rest.html#idp2425664
 		localVarOptionals := map[string]interface{}{}
-		if v.provenance == "source" {
+		switch v.provenance {
+		case "source":
 			localVarOptionals = map[string]interface{}{"at": event.SHA}
 			v.Logger.Infof("Using PipelineRun definition from source pull request SHA: %s", event.SHA)
-		} else {
+		case "merge_base":
+			revision := v.mergeBaseRevision(event)
+			localVarOptionals = map[string]interface{}{"at": revision}
+			v.Logger.Infof("Using PipelineRun definition from %s, merge base is not available on Bitbucket Data Center", revision)
+		default:
 			v.Logger.Infof("Using PipelineRun definition from default_branch: %s", event.DefaultBranch)
 		}
 		if nextPage != 0 {
@@ -202,7 +225,11 @@ func (v *Provider) GetTektonDir(_ context.Context, event *info.Event, path, prov
 	return v.concatAllYamlFiles(fpathTmpl, event)
 }
 
-func (v *Provider) GetFileInsideRepo(_ context.Context, event *info.Event, path, targetBranch string) (string, error) {
+func (v *Provider) GetFileInsideRepo(ctx context.Context, event *info.Event, path, targetBranch string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+	defer v.withContext(ctx)()
+
 	branch := event.SHA
 	// TODO: this may be buggy? we need to figure out how to get the fromSource ref
 	if targetBranch == event.DefaultBranch {
@@ -213,7 +240,29 @@ func (v *Provider) GetFileInsideRepo(_ context.Context, event *info.Event, path,
 	return ret, err
 }
 
-func (v *Provider) SetClient(ctx context.Context, run *params.Run, event *info.Event, _ *v1alpha1.Repository, _ *events.EventEmitter) error {
+// GetFileAtRevision returns the content of a file of the repository at a
+// revision.
+func (v *Provider) GetFileAtRevision(ctx context.Context, event *info.Event, path, revision string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+	defer v.withContext(ctx)()
+
+	return v.getRaw(event, revision, path)
+}
+
+// withContext sends the requests of the client with the context of an
+// operation, the client only has the context it has been created with. The
+// returned function restores the client.
+func (v *Provider) withContext(ctx context.Context) func() {
+	if v.clientConfig == nil || v.clientConfig.HTTPClient == nil {
+		return func() {}
+	}
+	httpClient := v.clientConfig.HTTPClient
+	v.clientConfig.HTTPClient = provider.ClientWithContext(ctx, httpClient)
+	return func() { v.clientConfig.HTTPClient = httpClient }
+}
+
+func (v *Provider) SetClient(ctx context.Context, run *params.Run, event *info.Event, repo *v1alpha1.Repository, _ *events.EventEmitter) error {
 	if event.Provider.User == "" {
 		return fmt.Errorf("no provider.user has been set in the repo crd")
 	}
@@ -237,8 +286,11 @@ func (v *Provider) SetClient(ctx context.Context, run *params.Run, event *info.E
 
 	ctx = context.WithValue(ctx, bbv1.ContextBasicAuth, basicAuth)
 	cfg := bbv1.NewConfiguration(event.Provider.URL)
+	cfg.HTTPClient = provider.HTTPClient(ctx, run, v.GetConfig().Name)
 	v.Client = bbv1.NewAPIClient(ctx, cfg)
+	v.clientConfig = cfg
 	v.run = run
+	v.repo = repo
 
 	return nil
 }
@@ -255,6 +307,7 @@ func (v *Provider) GetCommitInfo(_ context.Context, event *info.Event) error {
 		return err
 	}
 	event.SHATitle = sanitizeTitle(commitInfo.Message)
+	event.SHAMessage = commitInfo.Message
 	event.SHAURL = fmt.Sprintf("%s/projects/%s/repos/%s/commits/%s", v.baseURL, v.projectKey, event.Repository, event.SHA)
 
 	resp, err = v.Client.DefaultApi.GetDefaultBranch(v.projectKey, event.Repository)
@@ -269,13 +322,38 @@ func (v *Provider) GetCommitInfo(_ context.Context, event *info.Event) error {
 
 	v.defaultBranchLatestCommit = branchInfo.LatestCommit
 	event.DefaultBranch = branchInfo.DisplayID
+
+	// a GitOps comment on a commit only runs the PipelineRuns of a branch
+	// when the commit is the head of that branch.
+	if opscomments.IsCommitComment(event) {
+		return v.checkCommitIsBranchHead(event)
+	}
 	return nil
 }
 
+func (v *Provider) checkCommitIsBranchHead(event *info.Event) error {
+	if event.HeadBranch == "" {
+		event.HeadBranch, event.BaseBranch = event.DefaultBranch, event.DefaultBranch
+	}
+	resp, err := v.Client.DefaultApi.GetBranches(v.projectKey, event.Repository, map[string]interface{}{"filterText": event.HeadBranch})
+	if err != nil {
+		return err
+	}
+	branches, err := bbv1.GetBranchesResponse(resp)
+	if err != nil {
+		return err
+	}
+	for _, branch := range branches {
+		if branch.DisplayID == event.HeadBranch && branch.LatestCommit == event.SHA {
+			return nil
+		}
+	}
+	return fmt.Errorf("provided branch %s does not contain sha %s", event.HeadBranch, event.SHA)
+}
+
 func (v *Provider) GetConfig() *info.ProviderConfig {
 	return &info.ProviderConfig{
-		TaskStatusTMPL: taskStatusTemplate,
-		Name:           "bitbucket-server",
+		Name: "bitbucket-server",
 	}
 }
 
@@ -17,6 +17,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	bbtest "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketserver/test"
 	"go.uber.org/zap"
@@ -306,6 +307,8 @@ func TestGetCommitInfo(t *testing.T) {
 		commit        bbv1.Commit
 		defaultBranch string
 		latestCommit  string
+		branches      []bbv1.Branch
+		wantErr       string
 	}{
 		{
 			name: "Test valid Commit",
@@ -320,6 +323,45 @@ func TestGetCommitInfo(t *testing.T) {
 			},
 			latestCommit: "latestcommit",
 		},
+		{
+			name: "Test comment on the head commit of the default branch",
+			event: &info.Event{
+				Organization:   "owner",
+				Repository:     "repo",
+				SHA:            "latestcommit",
+				TriggerTarget:  triggertype.Push,
+				TriggerComment: "/retest",
+			},
+			defaultBranch: "branchmain",
+			commit: bbv1.Commit{
+				Message: "hello moto",
+			},
+			latestCommit: "latestcommit",
+			branches: []bbv1.Branch{
+				{DisplayID: "branchmain-old", LatestCommit: "othercommit"},
+				{DisplayID: "branchmain", LatestCommit: "latestcommit"},
+			},
+		},
+		{
+			name: "Test comment on a commit not the head of the branch",
+			event: &info.Event{
+				Organization:   "owner",
+				Repository:     "repo",
+				SHA:            "sha",
+				HeadBranch:     "nightly",
+				TriggerTarget:  triggertype.Push,
+				TriggerComment: "/retest",
+			},
+			defaultBranch: "branchmain",
+			commit: bbv1.Commit{
+				Message: "hello moto",
+			},
+			latestCommit: "latestcommit",
+			branches: []bbv1.Branch{
+				{DisplayID: "nightly", LatestCommit: "othercommit"},
+			},
+			wantErr: "provided branch nightly does not contain sha sha",
+		},
 	}
 
 	for _, tt := range tests {
@@ -328,9 +370,14 @@ func TestGetCommitInfo(t *testing.T) {
 			bbclient, mux, tearDown := bbtest.SetupBBServerClient(ctx)
 			bbtest.MuxCommitInfo(t, mux, tt.event, tt.commit)
 			bbtest.MuxDefaultBranch(t, mux, tt.event, tt.defaultBranch, tt.latestCommit)
+			bbtest.MuxBranches(t, mux, tt.event, tt.branches)
 			defer tearDown()
 			v := &Provider{Client: bbclient, baseURL: defaultBaseURL, projectKey: tt.event.Organization}
 			err := v.GetCommitInfo(ctx, tt.event)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
 			assert.NilError(t, err)
 			assert.Equal(t, tt.defaultBranch, tt.event.DefaultBranch)
 			assert.Equal(t, tt.latestCommit, v.defaultBranchLatestCommit)
@@ -342,7 +389,7 @@ func TestGetCommitInfo(t *testing.T) {
 func TestGetConfig(t *testing.T) {
 	v := &Provider{}
 	config := v.GetConfig()
-	assert.Equal(t, config.TaskStatusTMPL, taskStatusTemplate)
+	assert.Equal(t, config.Name, "bitbucket-server")
 }
 
 func TestValidate(t *testing.T) {
@@ -51,6 +51,15 @@ func (v *Provider) Detect(req *http.Request, payload string, logger *zap.Sugared
 			if provider.IsCancelComment(e.Comment.Text) {
 				return setLoggerAndProceed(true, "", nil)
 			}
+			if provider.IsLintComment(e.Comment.Text) {
+				return setLoggerAndProceed(true, "", nil)
+			}
+			if provider.IsPauseResumeComment(e.Comment.Text) {
+				return setLoggerAndProceed(true, "", nil)
+			}
+			if provider.IsFreezeComment(e.Comment.Text) {
+				return setLoggerAndProceed(true, "", nil)
+			}
 		}
 		return setLoggerAndProceed(false, fmt.Sprintf("not a recognized bitbucket event: \"%s\"", event), nil)
 
@@ -62,6 +71,12 @@ func (v *Provider) Detect(req *http.Request, payload string, logger *zap.Sugared
 		}
 		return setLoggerAndProceed(false, fmt.Sprintf("not an event we support: \"%s\"", event), nil)
 
+	case *types.CommitCommentEvent:
+		if provider.IsTestRetestComment(e.Comment.Text) || provider.IsCancelComment(e.Comment.Text) {
+			return setLoggerAndProceed(true, "", nil)
+		}
+		return setLoggerAndProceed(false, fmt.Sprintf("not a gitops comment on a commit: \"%s\"", event), nil)
+
 	default:
 		return setLoggerAndProceed(false, "", fmt.Errorf("bitbucket-server: event \"%s\" is not supported", event))
 	}
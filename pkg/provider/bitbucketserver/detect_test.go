@@ -52,6 +52,25 @@ func TestProvider_Detect(t *testing.T) {
 			isBS:       true,
 			processReq: true,
 		},
+		{
+			name: "commit comment event with cancel",
+			event: types.CommitCommentEvent{
+				Comment: bbv1.Comment{Text: "/cancel"},
+			},
+			eventType:  "repo:comment:added",
+			isBS:       true,
+			processReq: true,
+		},
+		{
+			name: "commit comment event without a gitops command",
+			event: types.CommitCommentEvent{
+				Comment: bbv1.Comment{Text: "looks good"},
+			},
+			eventType:  "repo:comment:added",
+			isBS:       true,
+			processReq: false,
+			wantReason: "not a gitops comment on a commit: \"repo:comment:added\"",
+		},
 		{
 			name:       "pull_request event",
 			event:      types.PullRequestEvent{},
@@ -111,6 +130,33 @@ func TestProvider_Detect(t *testing.T) {
 			isBS:       true,
 			processReq: true,
 		},
+		{
+			name: "lint comment",
+			event: types.PullRequestEvent{
+				Comment: bbv1.Comment{Text: "/lint"},
+			},
+			eventType:  "pr:comment:added",
+			isBS:       true,
+			processReq: true,
+		},
+		{
+			name: "pause comment",
+			event: types.PullRequestEvent{
+				Comment: bbv1.Comment{Text: "/pause"},
+			},
+			eventType:  "pr:comment:added",
+			isBS:       true,
+			processReq: true,
+		},
+		{
+			name: "unfreeze comment",
+			event: types.PullRequestEvent{
+				Comment: bbv1.Comment{Text: "/unfreeze"},
+			},
+			eventType:  "pr:comment:added",
+			isBS:       true,
+			processReq: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,56 +1,56 @@
 package bitbucketserver
 
 import (
+	"context"
 	"fmt"
 
 	bbv1 "github.com/gfleury/go-bitbucket-v1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 )
 
 type apiResultfunc func(int) (*bbv1.APIResponse, error)
 
 // paginate go over an API call and fetch next results.
-func paginate(apiResultfunc apiResultfunc) ([]interface{}, error) {
-	var nextPageStart int
-
-	allValues := []interface{}{}
-	for {
+func paginate(ctx context.Context, apiResultfunc apiResultfunc) ([]interface{}, error) {
+	allValues, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(nextPageStart int) ([]interface{}, int, error) {
 		result, err := apiResultfunc(nextPageStart)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
+		var values []interface{}
 		if result.Payload != nil {
 			// I know I know your eyebrow is 🤨, the lib sometime return the payload and sometime parsed values.
 			// so we just return the raw payload and we will handle it in the caller
-			allValues = append(allValues, result.Payload)
+			values = []interface{}{result.Payload}
 		} else {
 			if result.Values["values"] == nil {
-				return nil, fmt.Errorf("key \"values\" not found in result")
+				return nil, 0, fmt.Errorf("key \"values\" not found in result")
 			}
-			values, ok := result.Values["values"].([]interface{})
+			var ok bool
+			values, ok = result.Values["values"].([]interface{})
 			if !ok {
-				return nil, fmt.Errorf("key \"values\" is not an array")
+				return nil, 0, fmt.Errorf("key \"values\" is not an array")
 			}
-			allValues = append(allValues, values...)
-		}
-		np, ok := result.Values["nextPageStart"].(float64)
-		if !ok {
-			break
-		}
-		nextPageStart = int(np)
-
-		isLastPage, ok := result.Values["isLastPage"]
-		if !ok {
-			break
-		}
-		isLastPageb, ok := isLastPage.(bool)
-		if !ok {
-			break
-		}
-
-		if isLastPageb {
-			break
 		}
+		return values, nextStart(result), nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return allValues, nil
 }
+
+// nextStart returns the start of the next page of a result, 0 on the last
+// page.
+func nextStart(result *bbv1.APIResponse) int {
+	np, ok := result.Values["nextPageStart"].(float64)
+	if !ok {
+		return 0
+	}
+	isLastPage, ok := result.Values["isLastPage"].(bool)
+	if !ok || isLastPage {
+		return 0
+	}
+	return int(np)
+}
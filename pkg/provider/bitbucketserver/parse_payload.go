@@ -8,6 +8,8 @@ import (
 	"net/url"
 	"strings"
 
+	bbv1 "github.com/gfleury/go-bitbucket-v1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
@@ -52,6 +54,8 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 			processedEvent.TriggerTarget = triggertype.PullRequest
 			processedEvent.EventType = triggertype.PullRequest.String()
 		} else if provider.Valid(eventType, []string{"pr:comment:added", "pr:comment:edited"}) {
+			// the GitOps commands are authorized and matched on the comment
+			processedEvent.TriggerComment = e.Comment.Text
 			switch {
 			case provider.IsTestRetestComment(e.Comment.Text):
 				processedEvent.TriggerTarget = triggertype.PullRequest
@@ -59,8 +63,9 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 					processedEvent.EventType = "test-comment"
 				} else {
 					processedEvent.EventType = "retest-comment"
+					processedEvent.ExactRetest = opscomments.IsExactRetestComment(e.Comment.Text)
 				}
-				processedEvent.TargetTestPipelineRun = provider.GetPipelineRunFromTestComment(e.Comment.Text)
+				processedEvent.TargetTestPipelineRun = provider.GetPipelineRunFromTestComment(opscomments.StripExactFlag(e.Comment.Text))
 			case provider.IsOkToTestComment(e.Comment.Text):
 				processedEvent.TriggerTarget = triggertype.PullRequest
 				processedEvent.EventType = "ok-to-test-comment"
@@ -69,8 +74,20 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 				processedEvent.EventType = "cancel-comment"
 				processedEvent.CancelPipelineRuns = true
 				processedEvent.TargetCancelPipelineRun = provider.GetPipelineRunFromCancelComment(e.Comment.Text)
+			case provider.IsLintComment(e.Comment.Text):
+				processedEvent.TriggerTarget = triggertype.PullRequest
+				processedEvent.EventType = opscomments.LintCommentEventType.String()
+			case provider.IsPauseResumeComment(e.Comment.Text), provider.IsFreezeComment(e.Comment.Text):
+				processedEvent.TriggerTarget = triggertype.PullRequest
+				processedEvent.EventType = opscomments.CommentEventType(e.Comment.Text).String()
 			}
 		}
+		if err := checkRepository(e.PulRequest.ToRef.Repository); err != nil {
+			return nil, err
+		}
+		if !hasSelfLink(e.PulRequest.FromRef.Repository) {
+			return nil, fmt.Errorf("no self link in the source repository %q of the payload", e.PulRequest.FromRef.Repository.Slug)
+		}
 		// TODO: It's Really not an OWNER but a PROJECT
 		processedEvent.Organization = e.PulRequest.ToRef.Repository.Project.Key
 		processedEvent.Repository = e.PulRequest.ToRef.Repository.Name
@@ -83,13 +100,15 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.HeadURL = e.PulRequest.FromRef.Repository.Links.Self[0].Href
 		processedEvent.AccountID = fmt.Sprintf("%d", e.Actor.ID)
 		processedEvent.Sender = e.Actor.Name
-		for _, value := range e.PulRequest.FromRef.Repository.Links.Clone {
-			if value.Name == "http" {
-				processedEvent.CloneURL = value.Href
-			}
-		}
+		processedEvent.CloneURL = httpCloneURL(e.PulRequest.FromRef.Repository)
 		v.pullRequestNumber = e.PulRequest.ID
 	case *types.PushRequestEvent:
+		if err := checkRepository(e.Repository); err != nil {
+			return nil, err
+		}
+		if len(e.Changes) == 0 {
+			return nil, fmt.Errorf("no changes attached to this push event")
+		}
 		processedEvent.Event = "push"
 		processedEvent.TriggerTarget = "push"
 		processedEvent.Organization = e.Repository.Project.Key
@@ -103,11 +122,30 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.AccountID = fmt.Sprintf("%d", e.Actor.ID)
 		processedEvent.Sender = e.Actor.Name
 		// Should we care about clone via SSH or just only do HTTP clones?
-		for _, value := range e.Repository.Links.Clone {
-			if value.Name == "http" {
-				processedEvent.CloneURL = value.Href
-			}
+		processedEvent.CloneURL = httpCloneURL(e.Repository)
+	case *types.CommitCommentEvent:
+		if err := checkRepository(e.Repository); err != nil {
+			return nil, err
+		}
+		processedEvent.Event = "push"
+		// the branch of the commit is not in the payload, GetCommitInfo uses
+		// the default branch unless the comment asks for another one and
+		// checks the commit is the head of the branch.
+		branchName, err := opscomments.SetEventTypeAndTargetPush(processedEvent, e.Comment.Text)
+		if err != nil {
+			return nil, err
 		}
+		processedEvent.HeadBranch = branchName
+		processedEvent.BaseBranch = branchName
+		processedEvent.Organization = e.Repository.Project.Key
+		processedEvent.Repository = e.Repository.Slug
+		processedEvent.SHA = e.Commit
+		processedEvent.URL = e.Repository.Links.Self[0].Href
+		processedEvent.BaseURL = e.Repository.Links.Self[0].Href
+		processedEvent.HeadURL = e.Repository.Links.Self[0].Href
+		processedEvent.AccountID = fmt.Sprintf("%d", e.Actor.ID)
+		processedEvent.Sender = e.Actor.Name
+		processedEvent.CloneURL = httpCloneURL(e.Repository)
 	default:
 		return nil, fmt.Errorf("event %s is not supported", eventType)
 	}
@@ -142,6 +180,8 @@ func parsePayloadType(event string) (interface{}, error) {
 		localEvent = triggertype.PullRequest.String()
 	} else if event == "repo:refs_changed" {
 		localEvent = "push"
+	} else if event == "repo:comment:added" {
+		localEvent = "commit_comment"
 	}
 
 	var intfType interface{}
@@ -150,8 +190,36 @@ func parsePayloadType(event string) (interface{}, error) {
 		intfType = &types.PullRequestEvent{}
 	case "push":
 		intfType = &types.PushRequestEvent{}
+	case "commit_comment":
+		intfType = &types.CommitCommentEvent{}
 	default:
 		intfType = nil
 	}
 	return intfType, nil
 }
+
+// checkRepository checks the repository of a payload has the project and the
+// self link the event is built from.
+func checkRepository(repo bbv1.Repository) error {
+	if repo.Project == nil {
+		return fmt.Errorf("no project in the repository %q of the payload", repo.Slug)
+	}
+	if !hasSelfLink(repo) {
+		return fmt.Errorf("no self link in the repository %q of the payload", repo.Slug)
+	}
+	return nil
+}
+
+func hasSelfLink(repo bbv1.Repository) bool {
+	return repo.Links != nil && len(repo.Links.Self) > 0
+}
+
+func httpCloneURL(repo bbv1.Repository) string {
+	cloneURL := ""
+	for _, value := range repo.Links.Clone {
+		if value.Name == "http" {
+			cloneURL = value.Href
+		}
+	}
+	return cloneURL
+}
@@ -33,6 +33,8 @@ func TestParsePayload(t *testing.T) {
 		rawStr                  string
 		targetPipelinerun       string
 		canceltargetPipelinerun string
+		branch                  string
+		triggerComment          string
 	}{
 		{
 			name:          "bad/invalid event type",
@@ -75,22 +77,25 @@ func TestParsePayload(t *testing.T) {
 			expEvent:     ev1,
 		},
 		{
-			name:         "good/comment ok-to-test",
-			eventType:    "pr:comment:added",
-			payloadEvent: bbv1test.MakePREvent(ev1, "/ok-to-test"),
-			expEvent:     ev1,
+			name:           "good/comment ok-to-test",
+			eventType:      "pr:comment:added",
+			payloadEvent:   bbv1test.MakePREvent(ev1, "/ok-to-test"),
+			expEvent:       ev1,
+			triggerComment: "/ok-to-test",
 		},
 		{
-			name:         "good/comment test",
-			eventType:    "pr:comment:added",
-			payloadEvent: bbv1test.MakePREvent(ev1, "/test"),
-			expEvent:     ev1,
+			name:           "good/comment test",
+			eventType:      "pr:comment:added",
+			payloadEvent:   bbv1test.MakePREvent(ev1, "/test"),
+			expEvent:       ev1,
+			triggerComment: "/test",
 		},
 		{
 			name:              "good/comment retest a pr",
 			eventType:         "pr:comment:added",
 			payloadEvent:      bbv1test.MakePREvent(ev1, "/retest dummy"),
 			expEvent:          ev1,
+			triggerComment:    "/retest dummy",
 			targetPipelinerun: "dummy",
 		},
 		{
@@ -98,13 +103,30 @@ func TestParsePayload(t *testing.T) {
 			eventType:               "pr:comment:added",
 			payloadEvent:            bbv1test.MakePREvent(ev1, "/cancel dummy"),
 			expEvent:                ev1,
+			triggerComment:          "/cancel dummy",
 			canceltargetPipelinerun: "dummy",
 		},
 		{
-			name:         "good/comment cancel all",
-			eventType:    "pr:comment:added",
-			payloadEvent: bbv1test.MakePREvent(ev1, "/cancel"),
-			expEvent:     ev1,
+			name:              "good/commit comment retest",
+			eventType:         "repo:comment:added",
+			payloadEvent:      bbv1test.MakeCommitCommentEvent(ev1, "/retest dummy"),
+			expEvent:          ev1,
+			targetPipelinerun: "dummy",
+		},
+		{
+			name:                    "good/commit comment cancel on a branch",
+			eventType:               "repo:comment:added",
+			payloadEvent:            bbv1test.MakeCommitCommentEvent(ev1, "/cancel dummy branch:nightly"),
+			expEvent:                ev1,
+			canceltargetPipelinerun: "dummy",
+			branch:                  "nightly",
+		},
+		{
+			name:           "good/comment cancel all",
+			eventType:      "pr:comment:added",
+			payloadEvent:   bbv1test.MakePREvent(ev1, "/cancel"),
+			expEvent:       ev1,
+			triggerComment: "/cancel",
 		},
 	}
 	for _, tt := range tests {
@@ -145,6 +167,12 @@ func TestParsePayload(t *testing.T) {
 			if tt.canceltargetPipelinerun != "" {
 				assert.Equal(t, got.TargetCancelPipelineRun, tt.canceltargetPipelinerun)
 			}
+			if tt.branch != "" {
+				assert.Equal(t, got.HeadBranch, tt.branch)
+			}
+			if tt.triggerComment != "" {
+				assert.Equal(t, got.TriggerComment, tt.triggerComment)
+			}
 		})
 	}
 }
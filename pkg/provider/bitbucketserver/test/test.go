@@ -201,6 +201,19 @@ func MuxListDir(t *testing.T, mux *http.ServeMux, event *info.Event, path string
 	})
 }
 
+func MuxBranches(t *testing.T, mux *http.ServeMux, event *info.Event, branches []bbv1.Branch) {
+	path := fmt.Sprintf("/projects/%s/repos/%s/branches", event.Organization, event.Repository)
+	mux.HandleFunc(path, func(rw http.ResponseWriter, _ *http.Request) {
+		resp := map[string]interface{}{
+			"values":     branches,
+			"isLastPage": true,
+		}
+		b, err := json.Marshal(resp)
+		assert.NilError(t, err)
+		fmt.Fprint(rw, string(b))
+	})
+}
+
 func MuxCreateAndTestCommitStatus(t *testing.T, mux *http.ServeMux, event *info.Event, expectedDescSubstr string, expStatus provider.StatusOpts) {
 	path := fmt.Sprintf("/commits/%s", event.SHA)
 	mux.HandleFunc(path, func(rw http.ResponseWriter, r *http.Request) {
@@ -355,3 +368,13 @@ func MakePushEvent(event *info.Event) *types.PushRequestEvent {
 		},
 	}
 }
+
+func MakeCommitCommentEvent(event *info.Event, comment string) *types.CommitCommentEvent {
+	push := MakePushEvent(event)
+	return &types.CommitCommentEvent{
+		Actor:      push.Actor,
+		Repository: push.Repository,
+		Comment:    bbv1.Comment{Text: comment},
+		Commit:     event.SHA,
+	}
+}
@@ -25,3 +25,10 @@ type PushRequestEvent struct {
 	Repository bbv1.Repository          `json:"repository"`
 	Changes    []PushRequestEventChange `json:"changes"`
 }
+
+type CommitCommentEvent struct {
+	Actor      EventActor      `json:"actor"`
+	Repository bbv1.Repository `json:"repository"`
+	Comment    bbv1.Comment    `json:"comment"`
+	Commit     string          `json:"commit"`
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// Operation is a kind of call to the provider API with its own timeout.
+type Operation string
+
+const (
+	// OperationStatus posts the statuses, the check runs and the status comments.
+	OperationStatus Operation = "status"
+	// OperationFiles fetches the PipelineRuns and the other files of the repository.
+	OperationFiles Operation = "files"
+	// OperationDiff lists the files changed by the event.
+	OperationDiff Operation = "diff"
+)
+
+// WithBudget returns the context of an operation on the provider API, done
+// after the timeout of the operation in the settings, and the function to
+// call when the operation is over to record its duration. A timeout of 0 or
+// no settings do not limit the operation.
+func WithBudget(ctx context.Context, pacInfo *info.PacOpts, providerName string, op Operation) (context.Context, func()) {
+	start := time.Now()
+	ctx = withOperation(ctx, op)
+	cancel := func() {}
+	if timeout := budget(pacInfo, op); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func() {
+		outcome := "done"
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			outcome = "timeout"
+		}
+		metrics.RecordProviderCall(providerName, string(op), outcome, time.Since(start))
+		cancel()
+	}
+}
+
+func budget(pacInfo *info.PacOpts, op Operation) time.Duration {
+	if pacInfo == nil {
+		return 0
+	}
+	switch op {
+	case OperationStatus:
+		return pacInfo.Parsed.ProviderStatusTimeout
+	case OperationFiles:
+		return pacInfo.Parsed.ProviderFilesTimeout
+	case OperationDiff:
+		return pacInfo.Parsed.ProviderDiffTimeout
+	}
+	return 0
+}
+
+// ClientWithContext returns a copy of the HTTP client sending its requests
+// with the context, for the clients of the providers not taking the context of
+// the calls.
+func ClientWithContext(ctx context.Context, client *http.Client) *http.Client {
+	c := *client
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c.Transport = contextTransport{ctx: ctx, base: base}
+	return &c
+}
+
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithBudget(t *testing.T) {
+	pacInfo := &info.PacOpts{Settings: settings.Settings{}}
+	assert.NilError(t, settings.SyncConfig(zap.NewNop().Sugar(), &pacInfo.Settings, map[string]string{
+		"provider-status-timeout": "1ms",
+		"provider-files-timeout":  "1h",
+		"provider-diff-timeout":   "0s",
+	}))
+
+	ctx, done := WithBudget(context.Background(), pacInfo, "github", OperationStatus)
+	<-ctx.Done()
+	assert.Equal(t, ctx.Err(), context.DeadlineExceeded)
+	done()
+
+	ctx, done = WithBudget(context.Background(), pacInfo, "github", OperationFiles)
+	deadline, ok := ctx.Deadline()
+	assert.Assert(t, ok)
+	assert.Assert(t, time.Until(deadline) > 59*time.Minute)
+	done()
+	// the context is released when the operation is over
+	assert.Equal(t, ctx.Err(), context.Canceled)
+
+	for _, pacInfo := range []*info.PacOpts{pacInfo, nil} {
+		ctx, done = WithBudget(context.Background(), pacInfo, "github", OperationDiff)
+		_, ok = ctx.Deadline()
+		assert.Assert(t, !ok)
+		done()
+	}
+}
+
+func TestClientWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := ClientWithContext(ctx, server.Client())
+	resp, err := client.Get(server.URL)
+	assert.NilError(t, err)
+	resp.Body.Close()
+
+	// the requests built without a context get the one of the client
+	cancel()
+	_, err = client.Get(server.URL)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/templates"
+)
+
+// knownStages are the well known values of the stage annotation, in the
+// order their PipelineRuns run.
+var knownStages = []string{"build", "test", "deploy"}
+
+// GetCheckName returns the name of the check run or commit status for a
+// PipelineRun. By default it is "<ApplicationName> / <original PipelineRun
+// name>", or "<ApplicationName> / <stage> / <original PipelineRun name>" for
+// the PipelineRuns with a stage annotation, the check_run_name_template
+// setting of the Repository can override it.
+func GetCheckName(status StatusOpts, pacopts *info.PacOpts, repo *v1alpha1.Repository, event *info.Event) string {
+	if repo != nil && repo.Spec.Settings != nil && repo.Spec.Settings.CheckRunNameTemplate != "" && status.OriginalPipelineRunName != "" {
+		return strings.TrimSpace(templates.ReplacePlaceHoldersVariables(repo.Spec.Settings.CheckRunNameTemplate,
+			checkNameVariables(status.OriginalPipelineRunName, status.Stage, GetApplicationName(pacopts, repo), event), nil, nil, map[string]any{}))
+	}
+	prName := status.OriginalPipelineRunName
+	if prName != "" && status.Stage != "" {
+		prName = fmt.Sprintf("%s / %s", StageLabel(status.Stage), prName)
+	}
+	if applicationName := GetApplicationName(pacopts, repo); applicationName != "" {
+		if prName == "" {
+			return applicationName
+		}
+		return fmt.Sprintf("%s / %s", applicationName, prName)
+	}
+	return prName
+}
+
+// GetExternalID returns the external ID of the status of a PipelineRun on the
+// git provider, its run ID or its name for the PipelineRuns created before the
+// run IDs.
+func GetExternalID(status StatusOpts) string {
+	if status.RunID != "" {
+		return status.RunID
+	}
+	return status.PipelineRunName
+}
+
+// StageLabel returns the stage of a PipelineRun as shown in its check name,
+// the well known stages are prefixed with their position so their check runs
+// are listed in order, before the other stages.
+func StageLabel(stage string) string {
+	if i := slices.Index(knownStages, strings.ToLower(stage)); i != -1 {
+		return fmt.Sprintf("%d. %s", i+1, stage)
+	}
+	return stage
+}
+
+// GetApplicationName returns the application name shown in the check runs,
+// statuses and comments, the application_name setting of the Repository
+// overrides the one of the controller.
+func GetApplicationName(pacopts *info.PacOpts, repo *v1alpha1.Repository) string {
+	if repo != nil && repo.Spec.Settings != nil && repo.Spec.Settings.ApplicationName != "" {
+		return repo.Spec.Settings.ApplicationName
+	}
+	if pacopts == nil {
+		return ""
+	}
+	return pacopts.ApplicationName
+}
+
+// checkNameVariables returns the variables that can be used in the
+// check_run_name_template setting.
+func checkNameVariables(prName, stage, applicationName string, event *info.Event) map[string]string {
+	vars := map[string]string{
+		"pipelinerun":      prName,
+		"stage":            stage,
+		"application_name": applicationName,
+	}
+	if event != nil {
+		vars["repo_owner"] = event.Organization
+		vars["repo_name"] = event.Repository
+		vars["target_branch"] = event.BaseBranch
+		vars["source_branch"] = event.HeadBranch
+		vars["event_type"] = event.TriggerTarget.String()
+	}
+	return vars
+}
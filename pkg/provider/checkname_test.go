@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"gotest.tools/v3/assert"
+)
+
+func TestGetCheckName(t *testing.T) {
+	event := &info.Event{
+		Organization:  "owner",
+		Repository:    "repo",
+		BaseBranch:    "main",
+		HeadBranch:    "feature",
+		TriggerTarget: triggertype.PullRequest,
+	}
+	tests := []struct {
+		name            string
+		status          StatusOpts
+		pacopts         *info.PacOpts
+		template        string
+		applicationName string
+		want            string
+	}{
+		{
+			name:    "no application name",
+			status:  StatusOpts{OriginalPipelineRunName: "HELLO"},
+			pacopts: &info.PacOpts{Settings: settings.Settings{ApplicationName: ""}},
+			want:    "HELLO",
+		},
+		{
+			name:    "application and pipelinerun name",
+			status:  StatusOpts{OriginalPipelineRunName: "MOTO"},
+			pacopts: &info.PacOpts{Settings: settings.Settings{ApplicationName: "HELLO"}},
+			want:    "HELLO / MOTO",
+		},
+		{
+			name:    "application no pipelinerun name",
+			status:  StatusOpts{OriginalPipelineRunName: ""},
+			pacopts: &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
+			want:    "PAC",
+		},
+		{
+			name:    "well known stage",
+			status:  StatusOpts{OriginalPipelineRunName: "image", Stage: "build"},
+			pacopts: &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
+			want:    "PAC / 1. build / image",
+		},
+		{
+			name:    "other stage",
+			status:  StatusOpts{OriginalPipelineRunName: "docs", Stage: "lint"},
+			pacopts: &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
+			want:    "PAC / lint / docs",
+		},
+		{
+			name:    "stage without application name",
+			status:  StatusOpts{OriginalPipelineRunName: "e2e", Stage: "test"},
+			pacopts: &info.PacOpts{Settings: settings.Settings{ApplicationName: ""}},
+			want:    "2. test / e2e",
+		},
+		{
+			name:     "stage in template",
+			status:   StatusOpts{OriginalPipelineRunName: "prod", Stage: "deploy"},
+			pacopts:  &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
+			template: "{{ stage }}: {{ pipelinerun }}",
+			want:     "deploy: prod",
+		},
+		{
+			name:     "template",
+			status:   StatusOpts{OriginalPipelineRunName: "build"},
+			pacopts:  &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
+			template: "ci/{{ pipelinerun }} ({{ target_branch }}, {{event_type}})",
+			want:     "ci/build (main, pull_request)",
+		},
+		{
+			name:     "template with all variables",
+			status:   StatusOpts{OriginalPipelineRunName: "build"},
+			pacopts:  &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
+			template: "{{ application_name }} {{ repo_owner }}/{{ repo_name }} {{ source_branch }} {{ unknown }}",
+			want:     "PAC owner/repo feature {{ unknown }}",
+		},
+		{
+			name:     "template not used without pipelinerun name",
+			status:   StatusOpts{},
+			pacopts:  &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
+			template: "ci/{{ pipelinerun }}",
+			want:     "PAC",
+		},
+		{
+			name:            "repository application name",
+			status:          StatusOpts{OriginalPipelineRunName: "scan"},
+			pacopts:         &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
+			applicationName: "Security Scans",
+			want:            "Security Scans / scan",
+		},
+		{
+			name:            "repository application name in template",
+			status:          StatusOpts{OriginalPipelineRunName: "scan"},
+			pacopts:         &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
+			applicationName: "Security Scans",
+			template:        "{{ application_name }}: {{ pipelinerun }}",
+			want:            "Security Scans: scan",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{
+				Settings: &v1alpha1.Settings{CheckRunNameTemplate: tt.template, ApplicationName: tt.applicationName},
+			}}
+			assert.Equal(t, GetCheckName(tt.status, tt.pacopts, repo, event), tt.want)
+		})
+	}
+}
+
+func TestGetExternalID(t *testing.T) {
+	assert.Equal(t, GetExternalID(StatusOpts{PipelineRunName: "pr-abcde", RunID: "8b2f6c1e"}), "8b2f6c1e")
+	assert.Equal(t, GetExternalID(StatusOpts{PipelineRunName: "pr-abcde"}), "pr-abcde")
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+)
+
+const (
+	// CommentStrategyUpdate is the comment_strategy setting value to update
+	// the existing status comment instead of posting a new one on each run.
+	CommentStrategyUpdate = "update"
+
+	statusCommentMarker  = "<!-- pipelines-as-code status: %s %s -->"
+	statusHistoryHeader  = "<details><summary>Previous statuses</summary>\n\n"
+	statusHistoryTrailer = "\n</details>"
+)
+
+// UpdateStatusComment returns true if the Repository asks to update the
+// existing status comment instead of posting a new one.
+func UpdateStatusComment(repo *v1alpha1.Repository) bool {
+	return repo != nil && repo.Spec.Settings != nil && repo.Spec.Settings.CommentStrategy == CommentStrategyUpdate
+}
+
+// StatusCommentMarker returns the hidden marker added to the status comment of
+// a PipelineRun on a SHA, to find it back when it needs to be updated.
+func StatusCommentMarker(prName, sha string) string {
+	return fmt.Sprintf(statusCommentMarker, prName, sha)
+}
+
+// StatusCommentBody returns the body of a status comment, when there is a
+// previous body the first line of its status is added to the history of
+// statuses at the end of the comment.
+func StatusCommentBody(marker, body, previous string) string {
+	newBody := fmt.Sprintf("%s\n%s", marker, body)
+	if previous == "" {
+		return newBody
+	}
+
+	previous = strings.TrimSpace(strings.TrimPrefix(previous, marker))
+	history := []string{}
+	if i := strings.Index(previous, statusHistoryHeader); i != -1 {
+		for _, line := range strings.Split(strings.TrimSuffix(previous[i+len(statusHistoryHeader):], statusHistoryTrailer), "\n") {
+			if strings.HasPrefix(line, "- ") {
+				history = append(history, line)
+			}
+		}
+		previous = previous[:i]
+	}
+	if headline := strings.TrimSpace(strings.SplitN(strings.TrimSpace(previous), "\n", 2)[0]); headline != "" {
+		history = append(history, "- "+headline)
+	}
+	if len(history) == 0 {
+		return newBody
+	}
+	return fmt.Sprintf("%s\n\n%s%s%s", newBody, statusHistoryHeader, strings.Join(history, "\n"), statusHistoryTrailer)
+}
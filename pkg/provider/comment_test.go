@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+)
+
+func TestStatusCommentBody(t *testing.T) {
+	marker := StatusCommentMarker("pr", "sha")
+	first := StatusCommentBody(marker, "**pr** has failed\n\nlogs", "")
+	assert.Equal(t, first, marker+"\n**pr** has failed\n\nlogs")
+
+	second := StatusCommentBody(marker, "**pr** has started", first)
+	assert.Equal(t, second, marker+"\n**pr** has started\n\n"+
+		"<details><summary>Previous statuses</summary>\n\n- **pr** has failed\n</details>")
+
+	third := StatusCommentBody(marker, "**pr** has completed", second)
+	assert.Equal(t, third, marker+"\n**pr** has completed\n\n"+
+		"<details><summary>Previous statuses</summary>\n\n- **pr** has failed\n- **pr** has started\n</details>")
+}
+
+func TestUpdateStatusComment(t *testing.T) {
+	assert.Assert(t, !UpdateStatusComment(nil))
+	assert.Assert(t, !UpdateStatusComment(&v1alpha1.Repository{}))
+	assert.Assert(t, UpdateStatusComment(&v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{
+		Settings: &v1alpha1.Settings{CommentStrategy: CommentStrategyUpdate},
+	}}))
+}
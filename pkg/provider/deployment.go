@@ -0,0 +1,20 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// DeploymentProtectionRuleEventType is the event type of the deployments
+// waiting on a protection rule, they run the PipelineRuns verifying the
+// environment of the deployment.
+const DeploymentProtectionRuleEventType = "deployment_protection_rule"
+
+// DeploymentProtectionInterface is implemented by the providers able to act
+// as a protection rule of the deployments to an environment.
+type DeploymentProtectionInterface interface {
+	// ReviewDeployment approves or rejects the deployment waiting on the
+	// callback URL of the protection rule of the environment.
+	ReviewDeployment(ctx context.Context, event *info.Event, callbackURL, environment string, approved bool, comment string) error
+}
@@ -11,15 +11,23 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/policy"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 )
 
-func (v *Provider) CheckPolicyAllowing(_ context.Context, event *info.Event, allowedTeams []string) (bool, string) {
+func (v *Provider) CheckPolicyAllowing(ctx context.Context, event *info.Event, allowedTeams []string) (bool, string) {
 	if event.Organization == event.Repository {
 		return true, ""
 	}
-	// TODO: caching
-	orgTeams, resp, err := v.Client.ListOrgTeams(event.Organization, gitea.ListTeamsOptions{})
-	if resp.StatusCode == http.StatusNotFound {
+	notFound := false
+	orgTeams, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*gitea.Team, int, error) {
+		teams, resp, err := v.Client.ListOrgTeams(event.Organization, gitea.ListTeamsOptions{ListOptions: gitea.ListOptions{Page: max(page, 1)}})
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			notFound = true
+			return nil, 0, nil
+		}
+		return teams, nextPage(resp, page), err
+	})
+	if notFound {
 		// we explicitly disallow the policy when there is no team on org
 		return false, fmt.Sprintf("no teams on org %s", event.Organization)
 	}
@@ -29,15 +37,22 @@ func (v *Provider) CheckPolicyAllowing(_ context.Context, event *info.Event, all
 	}
 	for _, allowedTeam := range allowedTeams {
 		for _, orgTeam := range orgTeams {
-			if orgTeam.Name == allowedTeam {
+			if orgTeam.Name != allowedTeam {
+				continue
+			}
+			key := v.membershipKey("team-member", event.Organization, orgTeam.Name, event.Sender)
+			member, ok := v.memberships.Get(key)
+			if !ok {
 				teamMember, _, err := v.Client.GetTeamMember(orgTeam.ID, event.Sender)
 				if err != nil {
 					v.Logger.Infof("error while getting team member: %s, error: %s", event.Sender, err.Error())
 					continue
 				}
-				if teamMember.ID != 0 {
-					return true, fmt.Sprintf("allowing user: %s as a member of the team: %s", event.Sender, orgTeam.Name)
-				}
+				member = teamMember.ID != 0
+				v.memberships.Set(key, member)
+			}
+			if member {
+				return true, fmt.Sprintf("allowing user: %s as a member of the team: %s", event.Sender, orgTeam.Name)
 			}
 		}
 	}
@@ -130,6 +145,7 @@ func (v *Provider) aclAllowedOkToTestFromAnOwner(ctx context.Context, event *inf
 
 	for _, comment := range comments {
 		revent.Sender = comment.Poster.UserName
+		revent.SenderRole = ""
 		allowed, err := v.aclCheckAll(ctx, revent)
 		if err != nil {
 			return false, err
@@ -150,6 +166,7 @@ func (v *Provider) aclAllowedOkToTestCurrentComment(ctx context.Context, revent
 	}
 	if acl.MatchRegexp(acl.OKToTestCommentRegexp, comment.Body) {
 		revent.Sender = comment.Poster.UserName
+		revent.SenderRole = ""
 		allowed, err := v.aclCheckAll(ctx, revent)
 		if err != nil {
 			return false, err
@@ -203,9 +220,26 @@ func (v *Provider) IsAllowedOwnersFile(ctx context.Context, rev *info.Event) (bo
 	return acl.UserInOwnerFile(ownerContent, ownerAliasesContent, rev.Sender)
 }
 
-func (v *Provider) checkSenderRepoMembership(_ context.Context, runevent *info.Event) (bool, error) {
-	ret, _, err := v.Client.IsCollaborator(runevent.Organization, runevent.Repository, runevent.Sender)
-	return ret, err
+// checkSenderRepoMembership checks the sender has at least the collaborator
+// role on the repository.
+func (v *Provider) checkSenderRepoMembership(ctx context.Context, runevent *info.Event) (bool, error) {
+	key := v.membershipKey("collaborator", runevent.Organization, runevent.Repository, runevent.Sender)
+	if member, ok := v.memberships.Get(key); ok {
+		return member, nil
+	}
+	role, err := provider.ResolveSenderRole(ctx, v, runevent)
+	if err != nil {
+		return false, err
+	}
+	isCollab := role.AtLeast(info.RoleCollaborator)
+	v.memberships.Set(key, isCollab)
+	return isCollab, nil
+}
+
+// membershipKey returns the membership cache key of a lookup on the Gitea
+// instance the provider is using.
+func (v *Provider) membershipKey(kind string, parts ...string) string {
+	return acl.MembershipKey(append([]string{v.giteaInstanceURL, kind}, parts...)...)
 }
 
 // getFileFromDefaultBranch will get a file directly from the Default BaseBranch as
@@ -220,20 +254,24 @@ func (v *Provider) getFileFromDefaultBranch(ctx context.Context, path string, ru
 
 // GetStringPullRequestComment return the comment if we find a regexp in one of
 // the comments text of a pull request.
-func (v *Provider) GetStringPullRequestComment(_ context.Context, runevent *info.Event, reg string) ([]*gitea.Comment, error) {
+func (v *Provider) GetStringPullRequestComment(ctx context.Context, runevent *info.Event, reg string) ([]*gitea.Comment, error) {
 	var ret []*gitea.Comment
 	prNumber, err := convertPullRequestURLtoNumber(runevent.URL)
 	if err != nil {
 		return nil, err
 	}
 
-	comments, _, err := v.Client.ListIssueComments(runevent.Organization, runevent.Repository, int64(prNumber), gitea.ListIssueCommentOptions{})
+	comments, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*gitea.Comment, int, error) {
+		comments, resp, err := v.Client.ListIssueComments(runevent.Organization, runevent.Repository, int64(prNumber),
+			gitea.ListIssueCommentOptions{ListOptions: gitea.ListOptions{Page: max(page, 1)}})
+		return comments, nextPage(resp, page), err
+	})
 	if err != nil {
 		return nil, err
 	}
-	for _, v := range comments {
-		if acl.MatchRegexp(reg, v.Body) {
-			ret = append(ret, v)
+	for _, comment := range comments {
+		if acl.MatchRegexp(reg, comment.Body) {
+			ret = append(ret, comment)
 		}
 	}
 	return ret, nil
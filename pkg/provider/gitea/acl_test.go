@@ -25,6 +25,7 @@ func TestCheckPolicyAllowing(t *testing.T) {
 		name                string
 		allowedTeams        []string
 		listOrgReply        string
+		listOrgReplyPage2   string
 		listTeamMemberships string
 		wantAllowed         bool
 		wantReason          string
@@ -37,6 +38,15 @@ func TestCheckPolicyAllowing(t *testing.T) {
 			listOrgReply:        `[{"name": "allowedTeam", "id": 1}]`,
 			listTeamMemberships: `{"id": 2}`,
 		},
+		{
+			name:                "allowed team is on the second page of the org teams",
+			allowedTeams:        []string{"allowedTeam"},
+			wantAllowed:         true,
+			wantReason:          "allowing user: allowedUser as a member of the team: allowedTeam",
+			listOrgReply:        `[{"name": "otherteam", "id": 3}]`,
+			listOrgReplyPage2:   `[{"name": "allowedTeam", "id": 1}]`,
+			listTeamMemberships: `{"id": 2}`,
+		},
 		{
 			name:                "user is not a member of the allowed team",
 			allowedTeams:        []string{"otherteam"},
@@ -70,7 +80,16 @@ func TestCheckPolicyAllowing(t *testing.T) {
 				Sender:       "allowedUser",
 			}
 			if tt.listOrgReply != "" {
-				mux.HandleFunc(fmt.Sprintf("/orgs/%s/teams", event.Organization), func(rw http.ResponseWriter, _ *http.Request) {
+				mux.HandleFunc(fmt.Sprintf("/orgs/%s/teams", event.Organization), func(rw http.ResponseWriter, r *http.Request) {
+					if tt.listOrgReplyPage2 == "" {
+						fmt.Fprint(rw, tt.listOrgReply)
+						return
+					}
+					if r.URL.Query().Get("page") == "2" {
+						fmt.Fprint(rw, tt.listOrgReplyPage2)
+						return
+					}
+					rw.Header().Add("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
 					fmt.Fprint(rw, tt.listOrgReply)
 				})
 			}
@@ -366,9 +385,9 @@ func TestAclCheckAll(t *testing.T) {
 			}
 
 			if tt.allowedRules.collabo {
-				mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/collaborators/%s", tt.runevent.Organization,
+				mux.HandleFunc(fmt.Sprintf("/repos/%s/%s/collaborators/%s/permission", tt.runevent.Organization,
 					tt.runevent.Repository, tt.runevent.Sender), func(rw http.ResponseWriter, _ *http.Request) {
-					rw.WriteHeader(http.StatusNoContent)
+					fmt.Fprint(rw, `{"permission": "write"}`)
 				})
 			}
 			if tt.allowedRules.ownerFile {
@@ -58,6 +58,7 @@ func detectTriggerTypeFromPayload(ghEventType string, eventInt any) (triggertype
 
 	case *giteaStructs.IssueCommentPayload:
 		if event.Action == "created" &&
+			event.Issue != nil && event.Comment != nil &&
 			event.Issue.PullRequest != nil &&
 			event.Issue.State == "open" {
 			if provider.IsTestRetestComment(event.Comment.Body) {
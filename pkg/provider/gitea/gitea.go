@@ -5,12 +5,13 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"path"
 	"strconv"
 	"strings"
 
 	"code.gitea.io/sdk/gitea"
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
@@ -23,25 +24,9 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-const (
-	taskStatusTemplate = `
-<table>
-  <tr><th>Status</th><th>Duration</th><th>Name</th></tr>
-
-{{- range $taskrun := .TaskRunList }}
-<tr>
-<td>{{ formatCondition $taskrun.PipelineRunTaskRunStatus.Status.Conditions }}</td>
-<td>{{ formatDuration $taskrun.PipelineRunTaskRunStatus.Status.StartTime $taskrun.Status.CompletionTime }}</td><td>
-
-{{ $taskrun.ConsoleLogURL }}
-
-</td></tr>
-{{- end }}
-</table>`
-)
-
 // validate the struct to interface.
 var _ provider.Interface = (*Provider)(nil)
+var _ provider.RevisionFileInterface = (*Provider)(nil)
 
 type Provider struct {
 	Client           *gitea.Client
@@ -54,8 +39,14 @@ type Provider struct {
 	repo         *v1alpha1.Repository
 	eventEmitter *events.EventEmitter
 	run          *params.Run
+	mergeBaseSHA string
+	memberships  *acl.MembershipCache
 }
 
+// memberships is shared by the providers of all the events, the membership
+// lookups of the comments on a pull request are then done only once.
+var memberships = acl.NewMembershipCache(clockwork.NewRealClock(), acl.MembershipCacheTTL, acl.MembershipNegativeCacheTTL)
+
 func (v *Provider) SetPacInfo(pacInfo *info.PacOpts) {
 	v.pacInfo = pacInfo
 }
@@ -85,36 +76,49 @@ func convertPullRequestURLtoNumber(pullRequest string) (int, error) {
 
 func (v *Provider) GetConfig() *info.ProviderConfig {
 	return &info.ProviderConfig{
-		TaskStatusTMPL: taskStatusTemplate,
-		APIURL:         v.giteaInstanceURL,
-		Name:           "gitea",
-		SkipEmoji:      true,
+		APIURL: v.giteaInstanceURL,
+		Name:   "gitea",
+	}
+}
+
+// withContext makes the client calls use the context of an operation, the
+// returned function restores the background context of the client.
+func (v *Provider) withContext(ctx context.Context) func() {
+	if v.Client == nil {
+		return func() {}
 	}
+	v.Client.SetContext(ctx)
+	return func() { v.Client.SetContext(context.Background()) }
 }
 
-func (v *Provider) SetClient(_ context.Context, run *params.Run, runevent *info.Event, repo *v1alpha1.Repository, emitter *events.EventEmitter) error {
+func (v *Provider) SetClient(ctx context.Context, run *params.Run, runevent *info.Event, repo *v1alpha1.Repository, emitter *events.EventEmitter) error {
 	var err error
 	apiURL := runevent.Provider.URL
 	// password is not exposed to CRD, it's only used from the e2e tests
 	if v.Password != "" && runevent.Provider.User != "" {
-		v.Client, err = gitea.NewClient(apiURL, gitea.SetBasicAuth(runevent.Provider.User, v.Password))
+		v.Client, err = gitea.NewClient(apiURL, gitea.SetHTTPClient(provider.HTTPClient(ctx, run, v.GetConfig().Name)), gitea.SetBasicAuth(runevent.Provider.User, v.Password))
 	} else {
 		if runevent.Provider.Token == "" {
 			return fmt.Errorf("no git_provider.secret has been set in the repo crd")
 		}
-		v.Client, err = gitea.NewClient(apiURL, gitea.SetToken(runevent.Provider.Token))
+		v.Client, err = gitea.NewClient(apiURL, gitea.SetHTTPClient(provider.HTTPClient(ctx, run, v.GetConfig().Name)), gitea.SetToken(runevent.Provider.Token))
 	}
 	if err != nil {
 		return err
 	}
 	v.giteaInstanceURL = runevent.Provider.URL
+	v.memberships = memberships
 	v.eventEmitter = emitter
 	v.repo = repo
 	v.run = run
 	return nil
 }
 
-func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts provider.StatusOpts) error {
+func (v *Provider) CreateStatus(ctx context.Context, event *info.Event, statusOpts provider.StatusOpts) error {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationStatus)
+	defer done()
+	defer v.withContext(ctx)()
+
 	if v.Client == nil {
 		return fmt.Errorf("cannot set status on gitea no token or url set")
 	}
@@ -133,8 +137,12 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts
 		// for unauthorized user set title as Pending approval
 		statusOpts.Summary = "is skipping this commit."
 	case "neutral":
-		statusOpts.Title = "Unknown"
-		statusOpts.Summary = "doesn't know what happened with this commit."
+		if statusOpts.Title == "" {
+			statusOpts.Title = "Unknown"
+			statusOpts.Summary = "doesn't know what happened with this commit."
+		} else {
+			statusOpts.Summary = "is skipping this commit."
+		}
 	}
 
 	if statusOpts.Status == "in_progress" {
@@ -147,7 +155,7 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts
 		onPr = fmt.Sprintf("/%s", statusOpts.PipelineRunName)
 	}
 	// gitea show weirdly the <br>
-	statusOpts.Summary = fmt.Sprintf("%s%s %s", v.pacInfo.ApplicationName, onPr, statusOpts.Summary)
+	statusOpts.Summary = fmt.Sprintf("%s%s %s", provider.GetApplicationName(v.pacInfo, v.repo), onPr, statusOpts.Summary)
 
 	return v.createStatusCommit(event, v.pacInfo, statusOpts)
 }
@@ -170,7 +178,7 @@ func (v *Provider) createStatusCommit(event *info.Event, pacopts *info.PacOpts,
 		State:       state,
 		TargetURL:   status.DetailsURL,
 		Description: status.Title,
-		Context:     getCheckName(status, pacopts),
+		Context:     provider.GetCheckName(status, pacopts, v.repo, event),
 	}
 	if _, _, err := v.Client.CreateStatus(event.Organization, event.Repository, event.SHA, gStatus); err != nil {
 		return err
@@ -198,44 +206,49 @@ func (v *Provider) createStatusCommit(event *info.Event, pacopts *info.PacOpts,
 	return nil
 }
 
-// TODO: move to common since used in github and here.
-func getCheckName(status provider.StatusOpts, pacopts *info.PacOpts) string {
-	if pacopts.ApplicationName != "" {
-		if status.OriginalPipelineRunName == "" {
-			return pacopts.ApplicationName
-		}
-		return fmt.Sprintf("%s / %s", pacopts.ApplicationName, status.OriginalPipelineRunName)
-	}
-	return status.OriginalPipelineRunName
-}
+func (v *Provider) GetTektonDir(ctx context.Context, event *info.Event, path, provenance string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+	defer v.withContext(ctx)()
 
-func (v *Provider) GetTektonDir(_ context.Context, event *info.Event, path, provenance string) (string, error) {
 	// default set provenance from the SHA
 	revision := event.SHA
-	if provenance == "default_branch" {
+	switch provenance {
+	case "default_branch":
 		revision = event.DefaultBranch
 		v.Logger.Infof("Using PipelineRun definition from default_branch: %s", event.DefaultBranch)
-	} else {
+	case "merge_base":
+		mergeBase, err := v.getMergeBase(event)
+		if err != nil {
+			return "", err
+		}
+		v.mergeBaseSHA = mergeBase
+		revision = mergeBase
+		v.Logger.Infof("Using PipelineRun definition from merge base of %s and %s: %s", event.BaseBranch, event.SHA, mergeBase)
+	default:
 		v.Logger.Infof("Using PipelineRun definition from source pull request SHA: %s", event.SHA)
 	}
 
-	tektonDirSha := ""
-	rootobjects, _, err := v.Client.GetTrees(event.Organization, event.Repository, revision, false)
-	if err != nil {
-		return "", err
-	}
-	for _, object := range rootobjects.Entries {
-		if object.Path == path {
-			if object.Type != "tree" {
-				return "", fmt.Errorf("%s has been found but is not a directory", path)
+	// walk down the tree to the directory, one level at a time
+	tektonDirSha := revision
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		objects, _, err := v.Client.GetTrees(event.Organization, event.Repository, tektonDirSha, false)
+		if err != nil {
+			return "", err
+		}
+		tektonDirSha = ""
+		for _, object := range objects.Entries {
+			if object.Path == segment {
+				if object.Type != "tree" {
+					return "", fmt.Errorf("%s has been found but is not a directory", path)
+				}
+				tektonDirSha = object.SHA
 			}
-			tektonDirSha = object.SHA
 		}
-	}
-
-	// If we didn't find a .tekton directory then just silently ignore the error.
-	if tektonDirSha == "" {
-		return "", nil
+		// If we didn't find the directory then just silently ignore the error.
+		if tektonDirSha == "" {
+			return "", nil
+		}
 	}
 	// Get all files in the .tekton directory recursively
 	// TODO: figure out if there is a object limit we need to handle here
@@ -246,14 +259,29 @@ func (v *Provider) GetTektonDir(_ context.Context, event *info.Event, path, prov
 	return v.concatAllYamlFiles(tektonDirObjects.Entries, event)
 }
 
+// getMergeBase returns the merge base of the pull request as computed by
+// Gitea, on other events there is no merge base and the SHA is returned.
+func (v *Provider) getMergeBase(event *info.Event) (string, error) {
+	if event.TriggerTarget != triggertype.PullRequest || event.PullRequestNumber == 0 {
+		return event.SHA, nil
+	}
+	pr, _, err := v.Client.GetPullRequest(event.Organization, event.Repository, int64(event.PullRequestNumber))
+	if err != nil {
+		return "", fmt.Errorf("cannot get the merge base of pull request %d: %w", event.PullRequestNumber, err)
+	}
+	if pr.MergeBase == "" {
+		return "", fmt.Errorf("no merge base found for pull request %d", event.PullRequestNumber)
+	}
+	return pr.MergeBase, nil
+}
+
 func (v *Provider) concatAllYamlFiles(objects []gitea.GitEntry, event *info.Event) (string,
 	error,
 ) {
 	var allTemplates string
 
 	for _, value := range objects {
-		if strings.HasSuffix(value.Path, ".yaml") ||
-			strings.HasSuffix(value.Path, ".yml") {
+		if provider.IsPipelineRunFile(v.repo, value.Path) {
 			data, err := v.getObject(value.SHA, event)
 			if err != nil {
 				return "", err
@@ -284,13 +312,24 @@ func (v *Provider) getObject(sha string, event *info.Event) ([]byte, error) {
 	return decoded, err
 }
 
-func (v *Provider) GetFileInsideRepo(_ context.Context, runevent *info.Event, path, target string) (string, error) {
+func (v *Provider) GetFileInsideRepo(ctx context.Context, runevent *info.Event, path, target string) (string, error) {
 	ref := runevent.SHA
 	if target != "" {
 		ref = runevent.BaseBranch
+	} else if v.mergeBaseSHA != "" {
+		ref = v.mergeBaseSHA
 	}
+	return v.GetFileAtRevision(ctx, runevent, path, ref)
+}
+
+// GetFileAtRevision returns the content of a file of the repository at a
+// revision.
+func (v *Provider) GetFileAtRevision(ctx context.Context, runevent *info.Event, path, revision string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+	defer v.withContext(ctx)()
 
-	content, _, err := v.Client.GetContents(runevent.Organization, runevent.Repository, ref, path)
+	content, _, err := v.Client.GetContents(runevent.Organization, runevent.Repository, revision, path)
 	if err != nil {
 		return "", err
 	}
@@ -331,61 +370,81 @@ func (v *Provider) GetCommitInfo(_ context.Context, runevent *info.Event) error
 	}
 	runevent.SHAURL = commit.HTMLURL
 	runevent.SHATitle = strings.Split(commit.RepoCommit.Message, "\n\n")[0]
+	runevent.SHAMessage = commit.RepoCommit.Message
 	runevent.SHA = commit.SHA
+	if verification := commit.RepoCommit.Verification; verification != nil {
+		runevent.SHASignature = provider.CommitSignatureFromVerification(verification.Verified, verification.Signature)
+	}
+
+	if tagName, ok := provider.PushedTag(runevent); ok {
+		tag, _, err := v.Client.GetTag(runevent.Organization, runevent.Repository, tagName)
+		if err != nil {
+			return err
+		}
+		// the ID of a lightweight tag is the SHA of its commit
+		if tag.Commit == nil || tag.ID != tag.Commit.SHA {
+			runevent.TagMessage = tag.Message
+		}
+	}
 	return nil
 }
 
-func ShouldGetNextPage(resp *gitea.Response, currentPage int) (bool, int) {
-	val, exists := resp.Response.Header[http.CanonicalHeaderKey("x-pagecount")]
-	if !exists {
-		return false, 0
+// nextPage returns the page after the current page of a paginated response,
+// 0 on the last page. The Link header is used when Gitea sets it, the
+// x-pagecount header otherwise.
+func nextPage(resp *gitea.Response, currentPage int) int {
+	if resp == nil || resp.Response == nil {
+		return 0
 	}
-	i, err := strconv.Atoi(val[0])
+	if resp.NextPage > 0 {
+		return resp.NextPage
+	}
+	pageCount, err := strconv.Atoi(resp.Header.Get("x-pagecount"))
 	if err != nil {
-		return false, 0
+		return 0
 	}
-	if i >= currentPage {
-		return false, i
+	currentPage = max(currentPage, 1)
+	if currentPage >= pageCount {
+		return 0
 	}
-	return true, (currentPage + 1)
+	return currentPage + 1
 }
 
 type PushPayload struct {
 	Commits []gitea.PayloadCommit `json:"commits,omitempty"`
 }
 
-func (v *Provider) GetFiles(_ context.Context, runevent *info.Event) (changedfiles.ChangedFiles, error) {
+func (v *Provider) GetFiles(ctx context.Context, runevent *info.Event) (changedfiles.ChangedFiles, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationDiff)
+	defer done()
+	defer v.withContext(ctx)()
+
 	changedFiles := changedfiles.ChangedFiles{}
 
 	//nolint:exhaustive // we don't need to handle all cases
 	switch runevent.TriggerTarget {
 	case triggertype.PullRequest:
-		opt := gitea.ListPullRequestFilesOptions{ListOptions: gitea.ListOptions{Page: 1, PageSize: 50}}
-		shouldGetNextPage := false
-		for {
-			prChangedFiles, resp, err := v.Client.ListPullRequestFiles(runevent.Organization, runevent.Repository, int64(runevent.PullRequestNumber), opt)
-			if err != nil {
-				return changedfiles.ChangedFiles{}, err
+		prChangedFiles, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*gitea.ChangedFile, int, error) {
+			files, resp, err := v.Client.ListPullRequestFiles(runevent.Organization, runevent.Repository, int64(runevent.PullRequestNumber),
+				gitea.ListPullRequestFilesOptions{ListOptions: gitea.ListOptions{Page: max(page, 1), PageSize: 50}})
+			return files, nextPage(resp, page), err
+		})
+		if err != nil {
+			return changedfiles.ChangedFiles{}, err
+		}
+		for j := range prChangedFiles {
+			changedFiles.All = append(changedFiles.All, prChangedFiles[j].Filename)
+			if prChangedFiles[j].Status == "added" {
+				changedFiles.Added = append(changedFiles.Added, prChangedFiles[j].Filename)
 			}
-			for j := range prChangedFiles {
-				changedFiles.All = append(changedFiles.All, prChangedFiles[j].Filename)
-				if prChangedFiles[j].Status == "added" {
-					changedFiles.Added = append(changedFiles.Added, prChangedFiles[j].Filename)
-				}
-				if prChangedFiles[j].Status == "deleted" {
-					changedFiles.Deleted = append(changedFiles.Deleted, prChangedFiles[j].Filename)
-				}
-				if prChangedFiles[j].Status == "changed" {
-					changedFiles.Modified = append(changedFiles.Modified, prChangedFiles[j].Filename)
-				}
-				if prChangedFiles[j].Status == "renamed" {
-					changedFiles.Renamed = append(changedFiles.Renamed, prChangedFiles[j].Filename)
-				}
+			if prChangedFiles[j].Status == "deleted" {
+				changedFiles.Deleted = append(changedFiles.Deleted, prChangedFiles[j].Filename)
 			}
-
-			shouldGetNextPage, opt.Page = ShouldGetNextPage(resp, opt.Page)
-			if !shouldGetNextPage {
-				break
+			if prChangedFiles[j].Status == "changed" {
+				changedFiles.Modified = append(changedFiles.Modified, prChangedFiles[j].Filename)
+			}
+			if prChangedFiles[j].Status == "renamed" {
+				changedFiles.Renamed = append(changedFiles.Renamed, prChangedFiles[j].Filename)
 			}
 		}
 	case triggertype.Push:
@@ -21,6 +21,7 @@ import (
 	tgitea "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea/test"
 	"go.uber.org/zap"
 	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
 	rtesting "knative.dev/pkg/reconciler/testing"
 )
 
@@ -288,6 +289,45 @@ func TestProvider_GetFiles(t *testing.T) {
 	}
 }
 
+func TestProvider_GetFilesPaginated(t *testing.T) {
+	fakeclient, mux, teardown := tgitea.Setup(t)
+	defer teardown()
+
+	runevent := &info.Event{Organization: "myorg", Repository: "myrepo", PullRequestNumber: 1, TriggerTarget: "pull_request"}
+	mux.HandleFunc("/repos/myorg/myrepo/pulls/1/files", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("x-pagecount", "3")
+		page := r.URL.Query().Get("page")
+		fmt.Fprintf(rw, `[{"filename":"page%s.txt","status":"added"}]`, page)
+	})
+	ctx, _ := rtesting.SetupFakeContext(t)
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	gprovider := Provider{Client: fakeclient, Logger: zap.New(observer).Sugar()}
+
+	got, err := gprovider.GetFiles(ctx, runevent)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got.All, []string{"page1.txt", "page2.txt", "page3.txt"})
+}
+
+func TestProvider_GetFileInsideRepoTimeout(t *testing.T) {
+	fakeclient, mux, teardown := tgitea.Setup(t)
+	defer teardown()
+
+	release := make(chan struct{})
+	defer close(release)
+	mux.HandleFunc("/repos/myorg/myrepo/contents/file", func(_ http.ResponseWriter, _ *http.Request) {
+		<-release
+	})
+	ctx, _ := rtesting.SetupFakeContext(t)
+	pacInfo := &info.PacOpts{Settings: settings.Settings{}}
+	assert.NilError(t, settings.SyncConfig(zap.NewNop().Sugar(), &pacInfo.Settings, map[string]string{
+		"provider-files-timeout": "10ms",
+	}))
+	gprovider := Provider{Client: fakeclient, Logger: zap.NewNop().Sugar(), pacInfo: pacInfo}
+
+	_, err := gprovider.GetFileInsideRepo(ctx, &info.Event{Organization: "myorg", Repository: "myrepo", SHA: "sha"}, "file", "")
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+}
+
 func TestProvider_CreateStatusCommit(t *testing.T) {
 	type args struct {
 		event   *info.Event
@@ -470,3 +510,105 @@ func TestProvider_CreateStatusCommit(t *testing.T) {
 		})
 	}
 }
+
+func TestProvider_GetMergeBase(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   *info.Event
+		prjson  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "push has no merge base",
+			event: &info.Event{TriggerTarget: triggertype.Push, SHA: "headsha"},
+			want:  "headsha",
+		},
+		{
+			name:   "pull request",
+			event:  &info.Event{TriggerTarget: triggertype.PullRequest, SHA: "headsha", PullRequestNumber: 1},
+			prjson: `{"number": 1, "merge_base": "mergebasesha"}`,
+			want:   "mergebasesha",
+		},
+		{
+			name:    "pull request without merge base",
+			event:   &info.Event{TriggerTarget: triggertype.PullRequest, SHA: "headsha", PullRequestNumber: 1},
+			prjson:  `{"number": 1}`,
+			wantErr: "no merge base found for pull request 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeclient, mux, teardown := tgitea.Setup(t)
+			defer teardown()
+			tt.event.Organization, tt.event.Repository = "myorg", "myrepo"
+			mux.HandleFunc("/repos/myorg/myrepo/pulls/1", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, tt.prjson)
+			})
+			gprovider := Provider{Client: fakeclient}
+			got, err := gprovider.getMergeBase(tt.event)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestProvider_GetCommitInfoSignatureAndTag(t *testing.T) {
+	tests := []struct {
+		name          string
+		ref           string
+		verification  string
+		tag           string
+		wantSignature info.CommitSignature
+		wantMessage   string
+	}{
+		{
+			name:          "unverified commit on a branch",
+			ref:           "refs/heads/main",
+			verification:  `{"verified": false, "reason": "gpg.error.no_gpg_keys_found", "signature": "-----BEGIN PGP SIGNATURE-----\n-----END PGP SIGNATURE-----"}`,
+			wantSignature: info.CommitSignature{Status: info.SignatureUnverified},
+		},
+		{
+			name:          "annotated tag",
+			ref:           "refs/tags/v1.0.0",
+			verification:  `{"verified": false, "reason": "gpg.error.not_signed_commit"}`,
+			tag:           `{"name": "v1.0.0", "id": "tagsha", "message": "Release v1.0.0", "commit": {"sha": "sha"}}`,
+			wantSignature: info.CommitSignature{Status: info.SignatureUnsigned},
+			wantMessage:   "Release v1.0.0",
+		},
+		{
+			name:          "lightweight tag",
+			ref:           "refs/tags/v1.0.0",
+			verification:  `null`,
+			tag:           `{"name": "v1.0.0", "id": "sha", "message": "commit", "commit": {"sha": "sha"}}`,
+			wantSignature: info.CommitSignature{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeclient, mux, teardown := tgitea.Setup(t)
+			defer teardown()
+			mux.HandleFunc("/repos/myorg/myrepo/git/commits/sha", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprintf(rw, `{"sha": "sha", "commit": {"message": "commit", "verification": %s}}`, tt.verification)
+			})
+			mux.HandleFunc("/repos/myorg/myrepo/tags/v1.0.0", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, tt.tag)
+			})
+			event := &info.Event{
+				Organization:  "myorg",
+				Repository:    "myrepo",
+				SHA:           "sha",
+				BaseBranch:    tt.ref,
+				TriggerTarget: triggertype.Push,
+			}
+			gprovider := Provider{Client: fakeclient}
+			assert.NilError(t, gprovider.GetCommitInfo(context.Background(), event))
+			assert.DeepEqual(t, event.SHASignature, tt.wantSignature)
+			assert.Equal(t, event.TagMessage, tt.wantMessage)
+		})
+	}
+}
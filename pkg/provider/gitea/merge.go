@@ -0,0 +1,29 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.PullRequestMergeInterface = (*Provider)(nil)
+
+// MergePullRequest merges the pull request of the event with a merge commit if
+// its head is still the SHA of the event.
+func (v *Provider) MergePullRequest(_ context.Context, event *info.Event) error {
+	if v.Client == nil {
+		return fmt.Errorf("no gitea client has been initialized")
+	}
+	merged, _, err := v.Client.MergePullRequest(event.Organization, event.Repository, int64(event.PullRequestNumber),
+		gitea.MergePullRequestOption{Style: gitea.MergeStyleMerge, HeadCommitId: event.SHA})
+	if err != nil {
+		return err
+	}
+	if !merged {
+		return fmt.Errorf("pull request %d has not been merged", event.PullRequestNumber)
+	}
+	return nil
+}
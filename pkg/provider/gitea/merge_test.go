@@ -0,0 +1,34 @@
+package gitea
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	tgitea "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestMergePullRequest(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, teardown := tgitea.Setup(t)
+	defer teardown()
+	v := &Provider{Client: fakeclient}
+
+	status := http.StatusOK
+	mux.HandleFunc("/repos/owner/repo/pulls/42/merge", func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Method, http.MethodPost)
+		body := map[string]any{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, body["Do"], "merge")
+		assert.Equal(t, body["head_commit_id"], "sha")
+		rw.WriteHeader(status)
+	})
+	event := &info.Event{Organization: "owner", Repository: "repo", PullRequestNumber: 42, SHA: "sha"}
+	assert.NilError(t, v.MergePullRequest(ctx, event))
+
+	status = http.StatusMethodNotAllowed
+	assert.Error(t, v.MergePullRequest(ctx, event), "pull request 42 has not been merged")
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 )
 
 func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.Request,
@@ -33,6 +34,11 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 
 	switch gitEvent := eventInt.(type) {
 	case *giteaStructs.PullRequestPayload:
+		if gitEvent.Sender == nil || gitEvent.Repository == nil || gitEvent.Repository.Owner == nil || gitEvent.PullRequest == nil ||
+			gitEvent.PullRequest.Head == nil || gitEvent.PullRequest.Head.Repository == nil ||
+			gitEvent.PullRequest.Base == nil || gitEvent.PullRequest.Base.Repository == nil {
+			return nil, fmt.Errorf("invalid %s payload: no sender, repository or pull request branches", eventType)
+		}
 		processedEvent = info.NewEvent()
 		// // Organization:  event.GetRepo().GetOwner().GetLogin(),
 		processedEvent.Sender = gitEvent.Sender.UserName
@@ -46,18 +52,34 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.BaseURL = gitEvent.PullRequest.Base.Repository.HTMLURL
 		processedEvent.PullRequestNumber = int(gitEvent.Index)
 		processedEvent.PullRequestTitle = gitEvent.PullRequest.Title
+		for _, label := range gitEvent.PullRequest.Labels {
+			if label == nil {
+				continue
+			}
+			processedEvent.PullRequestLabel = append(processedEvent.PullRequestLabel, label.Name)
+		}
 		processedEvent.Organization = gitEvent.Repository.Owner.UserName
 		processedEvent.Repository = gitEvent.Repository.Name
 		processedEvent.TriggerTarget = triggertype.PullRequest
 		processedEvent.EventType = triggertype.PullRequest.String()
 	case *giteaStructs.PushPayload:
+		if gitEvent.Sender == nil || gitEvent.Repo == nil || gitEvent.Repo.Owner == nil {
+			return nil, fmt.Errorf("invalid %s payload: no sender or repository", eventType)
+		}
 		processedEvent = info.NewEvent()
-		processedEvent.SHA = gitEvent.HeadCommit.ID
+		if gitEvent.HeadCommit != nil {
+			processedEvent.SHA = gitEvent.HeadCommit.ID
+			processedEvent.SHAURL = gitEvent.HeadCommit.URL
+			processedEvent.SHATitle = gitEvent.HeadCommit.Message
+			processedEvent.SHAMessage = gitEvent.HeadCommit.Message
+		}
 		if processedEvent.SHA == "" {
 			processedEvent.SHA = gitEvent.Before
 		}
-		processedEvent.SHAURL = gitEvent.HeadCommit.URL
-		processedEvent.SHATitle = gitEvent.HeadCommit.Message
+		if provider.IsDeletedRef(gitEvent.After) {
+			processedEvent.BranchDeleted = true
+			processedEvent.CancelPipelineRuns = true
+		}
 		processedEvent.Organization = gitEvent.Repo.Owner.UserName
 		processedEvent.Repository = gitEvent.Repo.Name
 		processedEvent.DefaultBranch = gitEvent.Repo.DefaultBranch
@@ -70,6 +92,9 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.HeadURL = processedEvent.BaseURL // in push events Head URL is the same as BaseURL
 		processedEvent.TriggerTarget = "push"
 	case *giteaStructs.IssueCommentPayload:
+		if gitEvent.Sender == nil || gitEvent.Repository == nil || gitEvent.Repository.Owner == nil || gitEvent.Comment == nil || gitEvent.Issue == nil {
+			return nil, fmt.Errorf("invalid %s payload: no sender, repository, comment or issue", eventType)
+		}
 		if gitEvent.Issue.PullRequest == nil {
 			return info.NewEvent(), fmt.Errorf("issue comment is not coming from a pull_request")
 		}
@@ -79,6 +104,7 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.Sender = gitEvent.Sender.UserName
 		processedEvent.TriggerTarget = triggertype.PullRequest
 		opscomments.SetEventTypeAndTargetPR(processedEvent, gitEvent.Comment.Body)
+		processedEvent.TriggerCommentID = gitEvent.Comment.ID
 		processedEvent.PullRequestNumber, err = convertPullRequestURLtoNumber(gitEvent.Issue.URL)
 		if err != nil {
 			return nil, err
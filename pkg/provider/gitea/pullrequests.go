@@ -0,0 +1,69 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.OpenPullRequestsInterface = (*Provider)(nil)
+
+// ListOpenPullRequests lists the open pull requests targeting the base
+// branch, the most recently updated first. Gitea cannot filter the pull
+// requests on their base branch, they are filtered while paginating.
+func (v *Provider) ListOpenPullRequests(_ context.Context, event *info.Event, baseBranch string, limit int) ([]provider.PullRequest, error) {
+	if v.Client == nil {
+		return nil, fmt.Errorf("no gitea client has been initialized")
+	}
+	opts := gitea.ListPullRequestsOptions{
+		ListOptions: gitea.ListOptions{Page: 1, PageSize: 50},
+		State:       gitea.StateOpen,
+		Sort:        "recentupdate",
+	}
+	ret := []provider.PullRequest{}
+	for {
+		prs, resp, err := v.Client.ListRepoPullRequests(event.Organization, event.Repository, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			if pr.Base == nil || pr.Head == nil || pr.Base.Ref != baseBranch {
+				continue
+			}
+			p := provider.PullRequest{
+				Number:     int(pr.Index),
+				Title:      pr.Title,
+				SHA:        pr.Head.Sha,
+				HeadBranch: pr.Head.Ref,
+				HeadURL:    event.URL,
+				BaseURL:    event.URL,
+			}
+			for _, label := range pr.Labels {
+				p.Labels = append(p.Labels, label.Name)
+			}
+			if pr.Head.Repository != nil {
+				p.HeadURL = pr.Head.Repository.HTMLURL
+			}
+			if pr.Base.Repository != nil {
+				p.BaseURL = pr.Base.Repository.HTMLURL
+			}
+			if pr.Poster != nil {
+				p.Author = pr.Poster.UserName
+			}
+			if pr.Updated != nil {
+				p.UpdatedAt = *pr.Updated
+			}
+			ret = append(ret, p)
+			if len(ret) == limit {
+				return ret, nil
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return ret, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,38 @@
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	tgitea "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestListOpenPullRequests(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, teardown := tgitea.Setup(t)
+	defer teardown()
+	v := &Provider{Client: fakeclient}
+
+	mux.HandleFunc("/repos/owner/repo/pulls", func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("state"), "open")
+		fmt.Fprint(rw, `[
+			{"number": 2, "title": "fix", "user": {"login": "alice"},
+			 "head": {"ref": "fix", "sha": "abc", "repo": {"html_url": "https://gitea.com/alice/repo"}},
+			 "base": {"ref": "main"}},
+			{"number": 1, "title": "backport", "user": {"login": "bob"},
+			 "head": {"ref": "backport", "sha": "def"}, "base": {"ref": "release-1.0"}}
+		]`)
+	})
+	prs, err := v.ListOpenPullRequests(ctx, &info.Event{Organization: "owner", Repository: "repo", URL: "https://gitea.com/owner/repo"}, "main", 10)
+	assert.NilError(t, err)
+	assert.Equal(t, len(prs), 1)
+	assert.Equal(t, prs[0].Number, 2)
+	assert.Equal(t, prs[0].SHA, "abc")
+	assert.Equal(t, prs[0].HeadURL, "https://gitea.com/alice/repo")
+	assert.Equal(t, prs[0].BaseURL, "https://gitea.com/owner/repo")
+	assert.Equal(t, prs[0].Author, "alice")
+}
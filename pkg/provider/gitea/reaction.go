@@ -0,0 +1,24 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.CommentReactionInterface = (*Provider)(nil)
+
+// AddCommentReaction reacts to the pull request comment of the event.
+func (v *Provider) AddCommentReaction(_ context.Context, event *info.Event, reaction provider.Reaction) error {
+	if v.Client == nil {
+		return fmt.Errorf("no gitea client has been initialized")
+	}
+	content := "+1"
+	if reaction == provider.ReactionRocket {
+		content = "rocket"
+	}
+	_, _, err := v.Client.PostIssueCommentReaction(event.Organization, event.Repository, event.TriggerCommentID, content)
+	return err
+}
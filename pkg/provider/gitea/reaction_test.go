@@ -0,0 +1,34 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	tgitea "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestAddCommentReaction(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, teardown := tgitea.Setup(t)
+	defer teardown()
+	v := &Provider{Client: fakeclient}
+
+	reacted := false
+	mux.HandleFunc("/repos/owner/repo/issues/comments/42/reactions", func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Method, http.MethodPost)
+		body := map[string]string{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, body["content"], "+1")
+		reacted = true
+		fmt.Fprint(rw, `{}`)
+	})
+	event := &info.Event{Organization: "owner", Repository: "repo", TriggerCommentID: 42}
+	assert.NilError(t, v.AddCommentReaction(ctx, event, provider.ReactionThumbsUp))
+	assert.Assert(t, reacted)
+}
@@ -0,0 +1,53 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	giteaStructs "code.gitea.io/gitea/modules/structs"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.SenderRoleInterface = (*Provider)(nil)
+
+// SenderRole returns the role of the sender from its permission on the
+// repository: owner, admin for a maintainer or write for a collaborator.
+func (v *Provider) SenderRole(_ context.Context, event *info.Event) (info.Role, error) {
+	if v.Client == nil {
+		return info.RoleNone, fmt.Errorf("no gitea client has been initialized")
+	}
+	if event.Organization == event.Sender {
+		return info.RoleOwner, nil
+	}
+	perm, resp, err := v.Client.CollaboratorPermission(event.Organization, event.Repository, event.Sender)
+	if err != nil && (resp == nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusForbidden)) {
+		return info.RoleNone, err
+	}
+	if err == nil && perm != nil {
+		switch perm.Permission {
+		case "owner":
+			return info.RoleOwner, nil
+		case "admin":
+			return info.RoleMaintainer, nil
+		case "write":
+			return info.RoleCollaborator, nil
+		}
+	}
+	author := ""
+	switch gitEvent := event.Event.(type) {
+	case *giteaStructs.PullRequestPayload:
+		if gitEvent.PullRequest != nil && gitEvent.PullRequest.Poster != nil {
+			author = gitEvent.PullRequest.Poster.UserName
+		}
+	case *giteaStructs.IssueCommentPayload:
+		if gitEvent.Issue != nil && gitEvent.Issue.PullRequest != nil && gitEvent.Issue.Poster != nil {
+			author = gitEvent.Issue.Poster.UserName
+		}
+	}
+	if author != "" && author == event.Sender {
+		return info.RoleAuthor, nil
+	}
+	return info.RoleNone, nil
+}
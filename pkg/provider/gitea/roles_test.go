@@ -0,0 +1,72 @@
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	giteaStructs "code.gitea.io/gitea/modules/structs"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	tgitea "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestSenderRole(t *testing.T) {
+	prEvent := &giteaStructs.PullRequestPayload{
+		PullRequest: &giteaStructs.PullRequest{Poster: &giteaStructs.User{UserName: "contributor"}},
+	}
+	tests := []struct {
+		name       string
+		sender     string
+		permission string
+		want       info.Role
+	}{
+		{
+			name:   "owner of the repository",
+			sender: "owner",
+			want:   info.RoleOwner,
+		},
+		{
+			name:       "admin",
+			sender:     "admin",
+			permission: "admin",
+			want:       info.RoleMaintainer,
+		},
+		{
+			name:       "collaborator",
+			sender:     "collaborator",
+			permission: "write",
+			want:       info.RoleCollaborator,
+		},
+		{
+			name:       "author of the pull request",
+			sender:     "contributor",
+			permission: "read",
+			want:       info.RoleAuthor,
+		},
+		{
+			name:   "not a collaborator",
+			sender: "someone",
+			want:   info.RoleNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, teardown := tgitea.Setup(t)
+			defer teardown()
+			v := &Provider{Client: fakeclient}
+			mux.HandleFunc(fmt.Sprintf("/repos/owner/repo/collaborators/%s/permission", tt.sender), func(rw http.ResponseWriter, _ *http.Request) {
+				if tt.permission == "" {
+					rw.WriteHeader(http.StatusForbidden)
+					return
+				}
+				fmt.Fprintf(rw, `{"permission": %q}`, tt.permission)
+			})
+			got, err := v.SenderRole(ctx, &info.Event{Organization: "owner", Repository: "repo", Sender: tt.sender, Event: prEvent})
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
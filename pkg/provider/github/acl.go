@@ -10,6 +10,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/policy"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 )
 
 // CheckPolicyAllowing check that policy is allowing the event to be processed
@@ -17,29 +18,45 @@ import (
 // if the team is not found we explicitly disallow the policy, user have to correct the setting.
 func (v *Provider) CheckPolicyAllowing(ctx context.Context, event *info.Event, allowedTeams []string) (bool, string) {
 	for _, team := range allowedTeams {
-		// TODO: caching
-		opt := github.ListOptions{PerPage: v.paginedNumber}
-		for {
-			members, resp, err := v.Client.Teams.ListTeamMembersBySlug(ctx, event.Organization, team, &github.TeamListTeamMembersOptions{ListOptions: opt})
-			if resp.StatusCode == http.StatusNotFound {
-				// we explicitly disallow the policy when the team is not found
-				// maybe we should ignore it instead? i'd rather keep this explicit
-				// and conservative since being security related.
-				return false, fmt.Sprintf("team: %s is not found on the organization: %s", team, event.Organization)
+		key := v.membershipKey("team-member", event.Organization, team, event.Sender)
+		if member, ok := v.memberships.Get(key); ok {
+			if member {
+				return true, fmt.Sprintf("allowing user: %s as a member of the team: %s", event.Sender, team)
+			}
+			continue
+		}
+		isMember, notFound := false, false
+		_, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*github.User, int, error) {
+			members, resp, err := v.Client.Teams.ListTeamMembersBySlug(ctx, event.Organization, team,
+				&github.TeamListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: v.paginedNumber, Page: page}})
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				notFound = true
+				return nil, 0, nil
 			}
 			if err != nil {
-				// probably a 500 or another api error, no need to try again and again with other teams
-				return false, fmt.Sprintf("error while getting team membership for user: %s in team: %s, error: %s", event.Sender, team, err.Error())
+				return nil, 0, err
 			}
 			for _, member := range members {
 				if member.GetLogin() == event.Sender {
-					return true, fmt.Sprintf("allowing user: %s as a member of the team: %s", event.Sender, team)
+					isMember = true
+					return nil, 0, nil
 				}
 			}
-			if resp.NextPage == 0 {
-				break
-			}
-			opt.Page = resp.NextPage
+			return nil, nextPage(resp), nil
+		})
+		if notFound {
+			// we explicitly disallow the policy when the team is not found
+			// maybe we should ignore it instead? i'd rather keep this explicit
+			// and conservative since being security related.
+			return false, fmt.Sprintf("team: %s is not found on the organization: %s", team, event.Organization)
+		}
+		if err != nil {
+			// probably a 500 or another api error, no need to try again and again with other teams
+			return false, fmt.Sprintf("error while getting team membership for user: %s in team: %s, error: %s", event.Sender, team, err.Error())
+		}
+		v.memberships.Set(key, isMember)
+		if isMember {
+			return true, fmt.Sprintf("allowing user: %s as a member of the team: %s", event.Sender, team)
 		}
 	}
 
@@ -155,6 +172,7 @@ func (v *Provider) aclAllowedOkToTestFromAnOwner(ctx context.Context, event *inf
 
 	for _, comment := range comments {
 		revent.Sender = comment.User.GetLogin()
+		revent.SenderRole = ""
 		allowed, err := v.aclCheckAll(ctx, revent)
 		if err != nil {
 			return false, err
@@ -175,6 +193,7 @@ func (v *Provider) aclAllowedOkToTestCurrentComment(ctx context.Context, revent
 	}
 	if acl.MatchRegexp(acl.OKToTestCommentRegexp, comment.GetBody()) {
 		revent.Sender = comment.User.GetLogin()
+		revent.SenderRole = ""
 		allowed, err := v.aclCheckAll(ctx, revent)
 		if err != nil {
 			return false, err
@@ -253,41 +272,63 @@ func (v *Provider) checkPullRequestForSameURL(ctx context.Context, runevent *inf
 // checkSenderOrgMembership Get sender user's organization. We can
 // only get the one that the user sets as public 🤷.
 func (v *Provider) checkSenderOrgMembership(ctx context.Context, runevent *info.Event) (bool, error) {
-	opt := &github.ListMembersOptions{
-		ListOptions: github.ListOptions{PerPage: v.paginedNumber},
+	key := v.membershipKey("org-member", runevent.Organization, runevent.Sender)
+	if member, ok := v.memberships.Get(key); ok {
+		return member, nil
 	}
 
-	for {
-		users, resp, err := v.Client.Organizations.ListMembers(ctx, runevent.Organization, opt)
+	isMember := false
+	_, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*github.User, int, error) {
+		users, resp, err := v.Client.Organizations.ListMembers(ctx, runevent.Organization, &github.ListMembersOptions{
+			ListOptions: github.ListOptions{PerPage: v.paginedNumber, Page: page},
+		})
 		// If we are 404 it means we are checking a repo owner and not a org so let's bail out with grace
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			return false, nil
+			return nil, 0, nil
 		}
-
 		if err != nil {
-			return false, err
+			return nil, 0, err
 		}
-		for _, v := range users {
-			if v.GetLogin() == runevent.Sender {
-				return true, nil
+		for _, user := range users {
+			if user.GetLogin() == runevent.Sender {
+				isMember = true
+				return nil, 0, nil
 			}
 		}
-		if resp.NextPage == 0 {
-			break
-		}
-		opt.Page = resp.NextPage
+		return nil, nextPage(resp), nil
+	})
+	if err != nil {
+		return false, err
 	}
-	return false, nil
+	v.memberships.Set(key, isMember)
+	return isMember, nil
 }
 
-// checkSenderRepoMembership check if user is allowed to run CI.
+// checkSenderRepoMembership check if user is allowed to run CI, with at least
+// the collaborator role on the repository.
 func (v *Provider) checkSenderRepoMembership(ctx context.Context, runevent *info.Event) (bool, error) {
-	isCollab, _, err := v.Client.Repositories.IsCollaborator(ctx,
-		runevent.Organization,
-		runevent.Repository,
-		runevent.Sender)
+	key := v.membershipKey("collaborator", runevent.Organization, runevent.Repository, runevent.Sender)
+	if member, ok := v.memberships.Get(key); ok {
+		return member, nil
+	}
 
-	return isCollab, err
+	role, err := provider.ResolveSenderRole(ctx, v, runevent)
+	if err != nil {
+		return false, err
+	}
+	isCollab := role.AtLeast(info.RoleCollaborator)
+	v.memberships.Set(key, isCollab)
+	return isCollab, nil
+}
+
+// membershipKey returns the membership cache key of a lookup on the GitHub
+// API the provider is using.
+func (v *Provider) membershipKey(kind string, parts ...string) string {
+	apiURL := ""
+	if v.APIURL != nil {
+		apiURL = *v.APIURL
+	}
+	return acl.MembershipKey(append([]string{apiURL, kind}, parts...)...)
 }
 
 // getFileFromDefaultBranch will get a file directly from the Default BaseBranch as
@@ -303,30 +344,24 @@ func (v *Provider) getFileFromDefaultBranch(ctx context.Context, path string, ru
 // GetStringPullRequestComment return the comment if we find a regexp in one of
 // the comments text of a pull request.
 func (v *Provider) GetStringPullRequestComment(ctx context.Context, runevent *info.Event, reg string) ([]*github.IssueComment, error) {
-	var ret []*github.IssueComment
 	prNumber, err := convertPullRequestURLtoNumber(runevent.URL)
 	if err != nil {
 		return nil, err
 	}
 
-	opt := &github.IssueListCommentsOptions{
-		ListOptions: github.ListOptions{PerPage: v.paginedNumber},
+	comments, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*github.IssueComment, int, error) {
+		comments, resp, err := v.Client.Issues.ListComments(ctx, runevent.Organization, runevent.Repository, prNumber,
+			&github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: v.paginedNumber, Page: page}})
+		return comments, nextPage(resp), err
+	})
+	if err != nil {
+		return nil, err
 	}
-	for {
-		comments, resp, err := v.Client.Issues.ListComments(ctx, runevent.Organization, runevent.Repository,
-			prNumber, opt)
-		if err != nil {
-			return nil, err
-		}
-		for _, v := range comments {
-			if acl.MatchRegexp(reg, v.GetBody()) {
-				ret = append(ret, v)
-			}
-		}
-		if resp.NextPage == 0 {
-			break
+	var ret []*github.IssueComment
+	for _, comment := range comments {
+		if acl.MatchRegexp(reg, comment.GetBody()) {
+			ret = append(ret, comment)
 		}
-		opt.Page = resp.NextPage
 	}
 	return ret, nil
 }
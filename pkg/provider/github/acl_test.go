@@ -7,7 +7,10 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/jonboulle/clockwork"
+
 	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
@@ -395,8 +398,8 @@ func TestAclCheckAll(t *testing.T) {
 		fmt.Fprintf(rw, `{"content": "%s"}`, base64.RawStdEncoding.EncodeToString([]byte("approvers:\n  - approved\n")))
 	})
 
-	mux.HandleFunc(fmt.Sprintf("/repos/%v/%v/collaborators/%v", collabOwner, collabRepo, collaborator), func(rw http.ResponseWriter, _ *http.Request) {
-		rw.WriteHeader(http.StatusNoContent)
+	mux.HandleFunc(fmt.Sprintf("/repos/%v/%v/collaborators/%v/permission", collabOwner, collabRepo, collaborator), func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, `{"permission": "write", "role_name": "write"}`)
 	})
 
 	observer, _ := zapobserver.New(zap.InfoLevel)
@@ -484,6 +487,40 @@ func TestAclCheckAll(t *testing.T) {
 	}
 }
 
+func TestCheckSenderOrgMembershipCached(t *testing.T) {
+	fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc("/orgs/org/members", func(rw http.ResponseWriter, _ *http.Request) {
+		calls++
+		fmt.Fprint(rw, `[{"login": "member"}]`)
+	})
+
+	ctx, _ := rtesting.SetupFakeContext(t)
+	clock := clockwork.NewFakeClock()
+	gprovider := Provider{
+		Client:      fakeclient,
+		memberships: acl.NewMembershipCache(clock, acl.MembershipCacheTTL, acl.MembershipNegativeCacheTTL),
+	}
+
+	for _, sender := range []string{"member", "member", "stranger", "stranger"} {
+		runevent := &info.Event{Organization: "org", Sender: sender}
+		got, err := gprovider.checkSenderOrgMembership(ctx, runevent)
+		assert.NilError(t, err)
+		assert.Equal(t, got, sender == "member")
+	}
+	assert.Equal(t, calls, 2)
+
+	// the negative answer expires first
+	clock.Advance(acl.MembershipNegativeCacheTTL)
+	_, err := gprovider.checkSenderOrgMembership(ctx, &info.Event{Organization: "org", Sender: "stranger"})
+	assert.NilError(t, err)
+	_, err = gprovider.checkSenderOrgMembership(ctx, &info.Event{Organization: "org", Sender: "member"})
+	assert.NilError(t, err)
+	assert.Equal(t, calls, 3)
+}
+
 func TestIfPullRequestIsForSameRepoWithoutFork(t *testing.T) {
 	tests := []struct {
 		name              string
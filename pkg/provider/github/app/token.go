@@ -14,6 +14,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
 )
 
@@ -133,15 +134,23 @@ func (ip *Install) GenerateJWT(ctx context.Context) (string, error) {
 		return "", err
 	}
 
+	// The issue time is moved back by the clock skew tolerated with GitHub.
+	now := time.Now().Truncate(time.Second)
+	appSettings := settings.DefaultSettings()
+	if ip.run.Info.Pac != nil {
+		appSettings = ip.run.Info.GetPacOpts().Settings
+	}
+	skew := appSettings.GitHubAppJWTClockSkewDuration()
+
 	// The expirationTime claim identifies the expiration time on or after which the JWT MUST NOT be accepted for processing.
 	// Value cannot be longer duration.
 	// See https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.4
-	expirationTime := time.Now().Add(5 * time.Minute)
+	expirationTime := now.Add(5 * time.Minute)
 	claims := &JWTClaim{
 		Issuer: applicationID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now.Add(-skew)),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
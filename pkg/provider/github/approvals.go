@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.ApprovalsInterface = (*Provider)(nil)
+
+// writeAccessAssociations are the author associations of the reviewers with
+// write access to the repository, like the required reviewers of GitHub the
+// approvals of the others are not counted.
+var writeAccessAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// CountApprovals returns the number of reviewers with write access whose
+// latest review of the pull request is an approval of its head commit, the
+// approvals of an older commit don't carry over to the commits pushed since.
+func (v *Provider) CountApprovals(ctx context.Context, event *info.Event) (int, error) {
+	if v.Client == nil {
+		return 0, fmt.Errorf("no github client has been initialized")
+	}
+	reviews, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*github.PullRequestReview, int, error) {
+		reviews, resp, err := v.Client.PullRequests.ListReviews(ctx, event.Organization, event.Repository, event.PullRequestNumber,
+			&github.ListOptions{PerPage: v.paginedNumber, Page: page})
+		if err != nil {
+			return nil, 0, err
+		}
+		return reviews, nextPage(resp), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// reviews are listed chronologically, only the latest state of a reviewer
+	// counts and comments do not change it
+	latest := map[string]string{}
+	for _, review := range reviews {
+		if !provider.Valid(review.GetAuthorAssociation(), writeAccessAssociations) {
+			continue
+		}
+		switch state := review.GetState(); state {
+		case "APPROVED":
+			if review.GetCommitID() != event.SHA {
+				state = "STALE"
+			}
+			latest[review.GetUser().GetLogin()] = state
+		case "CHANGES_REQUESTED", "DISMISSED":
+			latest[review.GetUser().GetLogin()] = state
+		}
+	}
+	approvals := 0
+	for _, state := range latest {
+		if state == "APPROVED" {
+			approvals++
+		}
+	}
+	return approvals, nil
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestCountApprovals(t *testing.T) {
+	tests := []struct {
+		name    string
+		reviews string
+		want    int
+	}{
+		{
+			name:    "no reviews",
+			reviews: `[]`,
+		},
+		{
+			name: "approvals of the users with write access",
+			reviews: `[
+				{"user": {"login": "alice"}, "state": "APPROVED", "commit_id": "sha2", "author_association": "MEMBER"},
+				{"user": {"login": "bob"}, "state": "APPROVED", "commit_id": "sha2", "author_association": "COLLABORATOR"},
+				{"user": {"login": "eve"}, "state": "APPROVED", "commit_id": "sha2", "author_association": "CONTRIBUTOR"}
+			]`,
+			want: 2,
+		},
+		{
+			name: "latest review of a reviewer",
+			reviews: `[
+				{"user": {"login": "alice"}, "state": "APPROVED", "commit_id": "sha2", "author_association": "OWNER"},
+				{"user": {"login": "alice"}, "state": "COMMENTED", "author_association": "OWNER"},
+				{"user": {"login": "bob"}, "state": "APPROVED", "commit_id": "sha2", "author_association": "MEMBER"},
+				{"user": {"login": "bob"}, "state": "CHANGES_REQUESTED", "author_association": "MEMBER"}
+			]`,
+			want: 1,
+		},
+		{
+			name: "approvals of an older commit",
+			reviews: `[
+				{"user": {"login": "alice"}, "state": "APPROVED", "commit_id": "sha1", "author_association": "OWNER"},
+				{"user": {"login": "bob"}, "state": "APPROVED", "commit_id": "sha1", "author_association": "MEMBER"},
+				{"user": {"login": "bob"}, "state": "APPROVED", "commit_id": "sha2", "author_association": "MEMBER"}
+			]`,
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			v := &Provider{Client: fakeclient}
+			mux.HandleFunc("/repos/owner/repo/pulls/42/reviews", func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(w, tt.reviews)
+			})
+			got, err := v.CountApprovals(ctx, &info.Event{Organization: "owner", Repository: "repo", PullRequestNumber: 42, SHA: "sha2"})
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
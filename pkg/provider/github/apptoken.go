@@ -0,0 +1,187 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	ghinstallation "github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/golang-jwt/jwt/v4"
+	oGitHub "github.com/google/go-github/v60/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+)
+
+// maxJWTLifetime is the longest lifetime of a JWT accepted by GitHub.
+const maxJWTLifetime = 10 * time.Minute
+
+// clockOffsets are the offsets of the clocks of the GitHub API servers from
+// the clock of the controller by base URL, learned from the Date header of
+// the answers refusing a JWT.
+var clockOffsets sync.Map
+
+// skewSigner signs the JWTs of the GitHub App with their issue time moved back
+// by the clock skew tolerance, so GitHub accepts them while the clocks differ
+// by up to the tolerance either way.
+type skewSigner struct {
+	signer  ghinstallation.Signer
+	skew    time.Duration
+	baseURL func() string
+}
+
+func (s *skewSigner) now() time.Time {
+	now := time.Now()
+	if offset, ok := clockOffsets.Load(s.baseURL()); ok {
+		now = now.Add(offset.(time.Duration))
+	}
+	return now.Truncate(time.Second)
+}
+
+// Sign implements ghinstallation.Signer.
+func (s *skewSigner) Sign(claims jwt.Claims) (string, error) {
+	if rc, ok := claims.(*jwt.RegisteredClaims); ok {
+		now := s.now()
+		rc.IssuedAt = jwt.NewNumericDate(now.Add(-s.skew))
+		rc.ExpiresAt = jwt.NewNumericDate(now.Add(maxJWTLifetime - s.skew))
+	}
+	return s.signer.Sign(claims)
+}
+
+// appTokenTransport authenticates the requests with an installation token of
+// the GitHub App. The token is refreshed refreshBefore its expiration, instead
+// of the minute of ghinstallation, to hand out tokens still valid for a while.
+// When GitHub refuses the JWT or the token, the transport learns the offset of
+// the clock of GitHub from the Date of the answer and authenticates again once.
+type appTokenTransport struct {
+	apps           *ghinstallation.AppsTransport
+	installationID int64
+	tokenOptions   *oGitHub.InstallationTokenOptions
+	refreshBefore  time.Duration
+	// BaseURL is the GitHub API where the tokens are created.
+	BaseURL string
+
+	mu      sync.Mutex
+	current *ghinstallation.Transport
+}
+
+func newAppTokenTransport(tr http.RoundTripper, appID, installationID int64, privateKey []byte, skew, refreshBefore time.Duration) (*appTokenTransport, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
+	if err != nil {
+		return nil, errors.New("could not parse private key: " + err.Error())
+	}
+	t := &appTokenTransport{
+		installationID: installationID,
+		refreshBefore:  refreshBefore,
+		BaseURL:        "https://api.github.com",
+	}
+	signer := &skewSigner{
+		signer:  ghinstallation.NewRSASigner(jwt.SigningMethodRS256, key),
+		skew:    skew,
+		baseURL: func() string { return t.BaseURL },
+	}
+	t.apps, err = ghinstallation.NewAppsTransportWithOptions(tr, appID, ghinstallation.WithSigner(signer))
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *appTokenTransport) newTransport() *ghinstallation.Transport {
+	itr := ghinstallation.NewFromAppsTransport(t.apps, t.installationID)
+	itr.BaseURL = t.BaseURL
+	itr.InstallationTokenOptions = t.tokenOptions
+	return itr
+}
+
+// transport returns the installation transport, a new one when its token
+// expires in less than refreshBefore.
+func (t *appTokenTransport) transport() *ghinstallation.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current == nil {
+		t.current = t.newTransport()
+		return t.current
+	}
+	if expiresAt, _, err := t.current.Expiry(); err == nil && time.Until(expiresAt) < t.refreshBefore {
+		t.current = t.newTransport()
+		if _, err := t.current.Token(context.Background()); err != nil {
+			metrics.CountGitHubAppTokenRefresh("proactive", "error")
+		} else {
+			metrics.CountGitHubAppTokenRefresh("proactive", "done")
+		}
+	}
+	return t.current
+}
+
+// reauth drops the installation transport refused by GitHub, learning the
+// offset of the clock of GitHub from the Date of its answer.
+func (t *appTokenTransport) reauth(refused *ghinstallation.Transport, resp *http.Response) {
+	if resp != nil {
+		if serverDate, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+			offset := time.Until(serverDate).Round(time.Second)
+			clockOffsets.Store(t.BaseURL, offset)
+			metrics.RecordGitHubAppClockSkew(offset)
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current == refused {
+		t.current = nil
+	}
+}
+
+// Token returns an installation token of the GitHub App.
+func (t *appTokenTransport) Token(ctx context.Context) (string, error) {
+	itr := t.transport()
+	token, err := itr.Token(ctx)
+	var herr *ghinstallation.HTTPError
+	if err == nil || !errors.As(err, &herr) || herr.Response == nil || herr.Response.StatusCode != http.StatusUnauthorized {
+		return token, err
+	}
+	t.reauth(itr, herr.Response)
+	token, err = t.transport().Token(ctx)
+	countReauth(err)
+	return token, err
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *appTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the body is consumed by the first try, only retry the requests
+	// whose body can be read again
+	var retry *http.Request
+	if req.Body == nil || req.GetBody != nil {
+		retry = req.Clone(req.Context())
+	}
+	// get a token first, through the re-authentication of a refused JWT
+	if _, err := t.Token(req.Context()); err != nil {
+		return nil, err
+	}
+	itr := t.transport()
+	resp, err := itr.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || retry == nil {
+		return resp, err
+	}
+	if retry.GetBody != nil {
+		if retry.Body, err = retry.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	t.reauth(itr, resp)
+	resp, err = t.transport().RoundTrip(retry)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		countReauth(errors.New(resp.Status))
+	} else {
+		countReauth(err)
+	}
+	return resp, err
+}
+
+func countReauth(err error) {
+	if err != nil {
+		metrics.CountGitHubAppTokenRefresh("reauth", "error")
+		return
+	}
+	metrics.CountGitHubAppTokenRefresh("reauth", "done")
+}
@@ -0,0 +1,143 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gotest.tools/v3/assert"
+)
+
+type claimsSigner struct {
+	claims *jwt.RegisteredClaims
+}
+
+func (s *claimsSigner) Sign(claims jwt.Claims) (string, error) {
+	s.claims, _ = claims.(*jwt.RegisteredClaims)
+	return "signed", nil
+}
+
+func TestSkewSigner(t *testing.T) {
+	baseURL := "https://skew.example.com"
+	tests := []struct {
+		name   string
+		offset time.Duration
+	}{
+		{
+			name: "no clock offset",
+		},
+		{
+			name:   "clock offset learned",
+			offset: -time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clockOffsets.Delete(baseURL)
+			if tt.offset != 0 {
+				clockOffsets.Store(baseURL, tt.offset)
+				defer clockOffsets.Delete(baseURL)
+			}
+			signer := &claimsSigner{}
+			s := &skewSigner{signer: signer, skew: time.Minute, baseURL: func() string { return baseURL }}
+			_, err := s.Sign(&jwt.RegisteredClaims{})
+			assert.NilError(t, err)
+			assert.Assert(t, signer.claims != nil)
+
+			now := time.Now().Add(tt.offset)
+			issuedAt := signer.claims.IssuedAt.Time
+			assert.Assert(t, issuedAt.Before(now.Add(-time.Minute+time.Second)), "issued at %s", issuedAt)
+			assert.Assert(t, issuedAt.After(now.Add(-time.Minute-2*time.Second)), "issued at %s", issuedAt)
+			assert.Equal(t, signer.claims.ExpiresAt.Sub(issuedAt), maxJWTLifetime)
+		})
+	}
+}
+
+func TestAppTokenTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiresIn  time.Duration
+		refuse     int
+		serverSkew time.Duration
+		wantCalls  int
+		wantOffset time.Duration
+		wantErr    string
+	}{
+		{
+			name:      "token cached",
+			expiresIn: time.Hour,
+			wantCalls: 1,
+		},
+		{
+			name:      "token refreshed before its expiration",
+			expiresIn: 2 * time.Minute,
+			wantCalls: 2,
+		},
+		{
+			name:       "jwt refused and clock offset learned",
+			expiresIn:  time.Hour,
+			refuse:     1,
+			serverSkew: time.Hour,
+			wantCalls:  2,
+			wantOffset: time.Hour,
+		},
+		{
+			name:      "jwt refused again",
+			expiresIn: time.Hour,
+			refuse:    2,
+			wantErr:   "401",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.URL.Path, fmt.Sprintf("/app/installations/%d/access_tokens", testInstallationID))
+				calls++
+				w.Header().Set("Date", time.Now().Add(tt.serverSkew).UTC().Format(http.TimeFormat))
+				if calls <= tt.refuse {
+					w.WriteHeader(http.StatusUnauthorized)
+					_, _ = fmt.Fprint(w, `{"message":"'Issued at' claim ('iat') must be an Integer representing the time that the assertion was issued"}`)
+					return
+				}
+				_, _ = fmt.Fprintf(w, `{"token":"token-%d","expires_at":%q}`, calls, time.Now().Add(tt.expiresIn).UTC().Format(time.RFC3339))
+			}))
+			defer server.Close()
+			defer clockOffsets.Delete(server.URL)
+
+			itr, err := newAppTokenTransport(http.DefaultTransport, 12345, testInstallationID, []byte(fakePrivateKey), time.Minute, 5*time.Minute)
+			assert.NilError(t, err)
+			itr.BaseURL = server.URL
+
+			ctx := context.Background()
+			token, err := itr.Token(ctx)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, token, fmt.Sprintf("token-%d", tt.refuse+1))
+			token, err = itr.Token(ctx)
+			assert.NilError(t, err)
+			assert.Equal(t, token, fmt.Sprintf("token-%d", tt.wantCalls))
+			assert.Equal(t, calls, tt.wantCalls)
+
+			offset, ok := clockOffsets.Load(server.URL)
+			if tt.wantOffset == 0 {
+				assert.Assert(t, !ok || offset.(time.Duration) == 0)
+				return
+			}
+			assert.Assert(t, ok)
+			assert.Assert(t, (offset.(time.Duration)-tt.wantOffset).Abs() <= 2*time.Second, "offset %s", offset)
+		})
+	}
+}
+
+func TestNewAppTokenTransportInvalidKey(t *testing.T) {
+	_, err := newAppTokenTransport(http.DefaultTransport, 12345, testInstallationID, []byte("not a key"), time.Minute, 5*time.Minute)
+	assert.ErrorContains(t, err, "could not parse private key")
+}
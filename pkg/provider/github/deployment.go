@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.DeploymentProtectionInterface = (*Provider)(nil)
+
+type deploymentProtectionReview struct {
+	EnvironmentName string `json:"environment_name"`
+	State           string `json:"state"`
+	Comment         string `json:"comment,omitempty"`
+}
+
+// ReviewDeployment approves or rejects the deployment waiting on the
+// deployment protection rule of the GitHub App. The callback URL comes from
+// the annotations of the PipelineRun, it has to be on the API of the
+// provider to not send the token anywhere else.
+func (v *Provider) ReviewDeployment(ctx context.Context, _ *info.Event, callbackURL, environment string, approved bool, comment string) error {
+	if v.Client == nil {
+		return fmt.Errorf("no github client has been initialized, exiting... (client setup failed?)")
+	}
+	callback, err := url.Parse(callbackURL)
+	if err != nil {
+		return fmt.Errorf("invalid deployment callback url %s: %w", callbackURL, err)
+	}
+	if callback.Scheme != v.Client.BaseURL.Scheme || callback.Host != v.Client.BaseURL.Host {
+		return fmt.Errorf("deployment callback url %s is not on the github api %s", callbackURL, v.Client.BaseURL.String())
+	}
+
+	state := "rejected"
+	if approved {
+		state = "approved"
+	}
+	req, err := v.Client.NewRequest(http.MethodPost, callback.String(), &deploymentProtectionReview{
+		EnvironmentName: environment,
+		State:           state,
+		Comment:         comment,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := v.Client.Do(ctx, req, nil); err != nil {
+		return fmt.Errorf("cannot review the deployment to %s: %w", environment, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestReviewDeployment(t *testing.T) {
+	tests := []struct {
+		name        string
+		approved    bool
+		callbackURL string
+		wantState   string
+		wantErr     string
+	}{
+		{
+			name:      "approved",
+			approved:  true,
+			wantState: "approved",
+		},
+		{
+			name:      "rejected",
+			wantState: "rejected",
+		},
+		{
+			name:        "callback not on the api",
+			approved:    true,
+			callbackURL: "https://attacker.example.com/repos/owner/repo/actions/runs/1/deployment_protection_rule",
+			wantErr:     "is not on the github api",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			v := &Provider{Client: fakeclient}
+			gotState := ""
+			mux.HandleFunc("/repos/owner/repo/actions/runs/1/deployment_protection_rule", func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.Method, http.MethodPost)
+				review := deploymentProtectionReview{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&review))
+				assert.Equal(t, review.EnvironmentName, "production")
+				gotState = review.State
+				w.WriteHeader(http.StatusNoContent)
+			})
+			callbackURL := tt.callbackURL
+			if callbackURL == "" {
+				callbackURL = fakeclient.BaseURL.String() + "repos/owner/repo/actions/runs/1/deployment_protection_rule"
+			}
+			err := v.ReviewDeployment(ctx, &info.Event{}, callbackURL, "production", tt.approved, "verified by pipelinerun deploy-check")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Equal(t, gotState, "")
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, gotState, tt.wantState)
+		})
+	}
+}
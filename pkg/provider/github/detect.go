@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/google/go-github/v61/github"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
@@ -40,6 +41,12 @@ func (v *Provider) Detect(req *http.Request, payload string, logger *zap.Sugared
 	}
 
 	_ = json.Unmarshal([]byte(payload), &eventInt)
+	// the closed pull requests are only used to deactivate their preview
+	// environment
+	if prEvent, ok := eventInt.(*github.PullRequestEvent); ok && prEvent.GetAction() == "closed" &&
+		(v.pacInfo == nil || !v.pacInfo.PreviewEnvironments) {
+		return setLoggerAndProceed(false, "pull_request: closed pull requests are only processed with the preview-environments setting", nil)
+	}
 	eType, errReason := detectTriggerTypeFromPayload(eventType, eventInt)
 	if eType != "" {
 		return setLoggerAndProceed(true, "", nil)
@@ -59,10 +66,21 @@ func detectTriggerTypeFromPayload(ghEventType string, eventInt any) (triggertype
 		}
 		return "", "no pusher in payload"
 	case *github.PullRequestEvent:
-		if provider.Valid(event.GetAction(), []string{"opened", "synchronize", "synchronized", "reopened"}) {
+		if provider.Valid(event.GetAction(), []string{"opened", "synchronize", "synchronized", "reopened", "closed"}) {
 			return triggertype.PullRequest, ""
 		}
 		return "", fmt.Sprintf("pull_request: unsupported action \"%s\"", event.GetAction())
+	case *github.PullRequestReviewEvent:
+		if event.GetAction() == "submitted" && strings.EqualFold(event.GetReview().GetState(), "approved") {
+			return triggertype.PullRequest, ""
+		}
+		return "", fmt.Sprintf("pull_request_review: unsupported action \"%s\" with review state \"%s\"",
+			event.GetAction(), event.GetReview().GetState())
+	case *github.DeploymentProtectionRuleEvent:
+		if event.GetAction() == "requested" {
+			return triggertype.Push, ""
+		}
+		return "", fmt.Sprintf("deployment_protection_rule: unsupported action \"%s\"", event.GetAction())
 	case *github.IssueCommentEvent:
 		if event.GetAction() == "created" &&
 			event.GetIssue().IsPullRequest() &&
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
 	"gotest.tools/v3/assert"
 )
@@ -20,6 +22,8 @@ func TestProvider_Detect(t *testing.T) {
 		event         interface{}
 		eventType     string
 		wantReason    string
+		// previewEnvironments enables the preview-environments setting
+		previewEnvironments bool
 	}{
 		{
 			name:       "not a github Event",
@@ -208,6 +212,26 @@ func TestProvider_Detect(t *testing.T) {
 			isGH:       true,
 			processReq: true,
 		},
+		{
+			name: "pull request closed event",
+			event: github.PullRequestEvent{
+				Action: github.String("closed"),
+			},
+			eventType:           "pull_request",
+			isGH:                true,
+			processReq:          true,
+			previewEnvironments: true,
+		},
+		{
+			name: "pull request closed event without preview environments",
+			event: github.PullRequestEvent{
+				Action: github.String("closed"),
+			},
+			eventType:  "pull_request",
+			isGH:       true,
+			processReq: false,
+			wantReason: "closed pull requests are only processed with the preview-environments setting",
+		},
 		{
 			name: "pull request event not supported action",
 			event: github.PullRequestEvent{
@@ -217,6 +241,36 @@ func TestProvider_Detect(t *testing.T) {
 			isGH:       true,
 			processReq: false,
 		},
+		{
+			name: "pull request review approved",
+			event: github.PullRequestReviewEvent{
+				Action: github.String("submitted"),
+				Review: &github.PullRequestReview{State: github.String("approved")},
+			},
+			eventType:  "pull_request_review",
+			isGH:       true,
+			processReq: true,
+		},
+		{
+			name: "pull request review commented",
+			event: github.PullRequestReviewEvent{
+				Action: github.String("submitted"),
+				Review: &github.PullRequestReview{State: github.String("commented")},
+			},
+			eventType:  "pull_request_review",
+			isGH:       true,
+			processReq: false,
+		},
+		{
+			name: "deployment protection rule requested",
+			event: github.DeploymentProtectionRuleEvent{
+				Action:      github.String("requested"),
+				Environment: github.String("production"),
+			},
+			eventType:  "deployment_protection_rule",
+			isGH:       true,
+			processReq: true,
+		},
 		{
 			name: "issue comment event with cancel comment",
 			event: github.IssueCommentEvent{
@@ -298,7 +352,7 @@ func TestProvider_Detect(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gprovider := Provider{}
+			gprovider := Provider{pacInfo: &info.PacOpts{Settings: settings.Settings{PreviewEnvironments: tt.previewEnvironments}}}
 			logger, _ := logger.GetLogger()
 			jeez, err := json.Marshal(tt.event)
 			if err != nil {
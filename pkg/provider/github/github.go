@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/go-github/v61/github"
 	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
@@ -39,6 +41,7 @@ const (
 )
 
 var _ provider.Interface = (*Provider)(nil)
+var _ provider.RevisionFileInterface = (*Provider)(nil)
 
 type Provider struct {
 	Client        *github.Client
@@ -49,13 +52,28 @@ type Provider struct {
 	ApplicationID *int64
 	providerName  string
 	provenance    string
+	mergeBaseSHA  string
 	RepositoryIDs []int64
 	repo          *v1alpha1.Repository
 	eventEmitter  *events.EventEmitter
 	paginedNumber int
+	memberships   *acl.MembershipCache
+	tektonDirs    *provider.TektonDirCache
+	// pullRequestChanges are the changes of the pull request since its merge
+	// base, fetched once for all the PipelineRun directories.
+	pullRequestChanges *pullRequestChanges
 	skippedRun
 }
 
+// memberships is shared by the providers of all the events, the membership
+// lookups of the comments on a pull request are then done only once.
+var memberships = acl.NewMembershipCache(clockwork.NewRealClock(), acl.MembershipCacheTTL, acl.MembershipNegativeCacheTTL)
+
+// tektonDirs is shared by the providers of all the events, a pull request
+// only fetches the PipelineRun files it changes when the files of its merge
+// base are cached.
+var tektonDirs = provider.NewTektonDirCache(clockwork.NewRealClock(), provider.TektonDirCacheTTL, provider.TektonDirCacheMaxCommits)
+
 type skippedRun struct {
 	mutex      *sync.Mutex
 	checkRunID int64
@@ -65,6 +83,8 @@ func New() *Provider {
 	return &Provider{
 		APIURL:        github.String(keys.PublicGithubAPIURL),
 		paginedNumber: defaultPaginedNumber,
+		memberships:   memberships,
+		tektonDirs:    tektonDirs,
 		skippedRun: skippedRun{
 			mutex: &sync.Mutex{},
 		},
@@ -184,18 +204,32 @@ func (v *Provider) Validate(_ context.Context, _ *params.Run, event *info.Event)
 
 func (v *Provider) GetConfig() *info.ProviderConfig {
 	return &info.ProviderConfig{
-		TaskStatusTMPL: taskStatusTemplate,
-		APIURL:         apiPublicURL,
-		Name:           v.providerName,
+		APIURL: apiPublicURL,
+		Name:   v.providerName,
 	}
 }
 
-func makeClient(ctx context.Context, apiURL, token string) (*github.Client, string, *string) {
+// providerNameFor returns the name of the provider of the API URL, an empty
+// URL is the public GitHub.
+func providerNameFor(apiURL string) string {
+	if apiURL != "" && !strings.HasPrefix(apiURL, "https") && !strings.HasPrefix(apiURL, "http") {
+		apiURL = "https://" + apiURL
+	}
+	if apiURL != "" && apiURL != apiPublicURL {
+		return "github-enterprise"
+	}
+	return "github"
+}
+
+func makeClient(ctx context.Context, httpClient *http.Client, apiURL, token string) (*github.Client, string, *string) {
 	var client *github.Client
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 
+	if httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	}
 	tc := oauth2.NewClient(ctx, ts)
 	if apiURL != "" {
 		if !strings.HasPrefix(apiURL, "https") && !strings.HasPrefix(apiURL, "http") {
@@ -203,9 +237,8 @@ func makeClient(ctx context.Context, apiURL, token string) (*github.Client, stri
 		}
 	}
 
-	providerName := "github"
-	if apiURL != "" && apiURL != apiPublicURL {
-		providerName = "github-enterprise"
+	providerName := providerNameFor(apiURL)
+	if providerName == "github-enterprise" {
 		uploadURL := apiURL + "/api/uploads"
 		client, _ = github.NewClient(tc).WithEnterpriseURLs(apiURL, uploadURL)
 	} else {
@@ -270,7 +303,7 @@ func (v *Provider) checkWebhookSecretValidity(ctx context.Context, cw clockwork.
 }
 
 func (v *Provider) SetClient(ctx context.Context, run *params.Run, event *info.Event, repo *v1alpha1.Repository, eventsEmitter *events.EventEmitter) error {
-	client, providerName, apiURL := makeClient(ctx, event.Provider.URL, event.Provider.Token)
+	client, providerName, apiURL := makeClient(ctx, provider.HTTPClient(ctx, run, providerNameFor(event.Provider.URL)), event.Provider.URL, event.Provider.Token)
 	v.providerName = providerName
 	v.Run = run
 	v.repo = repo
@@ -296,34 +329,60 @@ func (v *Provider) SetClient(ctx context.Context, run *params.Run, event *info.E
 
 // GetTektonDir Get all yaml files in tekton directory return as a single concated file.
 func (v *Provider) GetTektonDir(ctx context.Context, runevent *info.Event, path, provenance string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+
 	tektonDirSha := ""
 
 	v.provenance = provenance
 	// default set provenance from the SHA
 	revision := runevent.SHA
-	if provenance == "default_branch" {
+	switch provenance {
+	case "default_branch":
 		revision = runevent.DefaultBranch
 		v.Logger.Infof("Using PipelineRun definition from default_branch: %s", runevent.DefaultBranch)
-	} else {
+	case "merge_base":
+		mergeBase, err := v.getMergeBase(ctx, runevent)
+		if err != nil {
+			return "", err
+		}
+		v.mergeBaseSHA = mergeBase
+		revision = mergeBase
+		v.Logger.Infof("Using PipelineRun definition from merge base of %s and %s: %s", runevent.BaseBranch, runevent.SHA, mergeBase)
+	default:
 		v.Logger.Infof("Using PipelineRun definition from source pull request %s/%s#%d SHA on %s", runevent.Organization, runevent.Repository, runevent.PullRequestNumber, runevent.SHA)
+		templates, ok, err := v.getChangedTektonDir(ctx, runevent, path)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return templates, nil
+		}
 	}
 
-	rootobjects, _, err := v.Client.Git.GetTree(ctx, runevent.Organization, runevent.Repository, revision, false)
-	if err != nil {
-		return "", err
-	}
-	for _, object := range rootobjects.Entries {
-		if object.GetPath() == path {
-			if object.GetType() != "tree" {
-				return "", fmt.Errorf("%s has been found but is not a directory", path)
+	// walk down the tree to the directory, one level at a time
+	tektonDirSha = revision
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		objects, _, err := v.Client.Git.GetTree(ctx, runevent.Organization, runevent.Repository, tektonDirSha, false)
+		if err != nil {
+			return "", err
+		}
+		tektonDirSha = ""
+		for _, object := range objects.Entries {
+			if object.GetPath() == segment {
+				if object.GetType() != "tree" {
+					return "", fmt.Errorf("%s has been found but is not a directory", path)
+				}
+				tektonDirSha = object.GetSHA()
 			}
-			tektonDirSha = object.GetSHA()
 		}
-	}
-
-	// If we didn't find a .tekton directory then just silently ignore the error.
-	if tektonDirSha == "" {
-		return "", nil
+		// If we didn't find the directory then just silently ignore the error.
+		if tektonDirSha == "" {
+			if isCommitSHA(revision) {
+				v.tektonDirs.Set(v.tektonDirCacheKey(runevent, path), revision, map[string]string{})
+			}
+			return "", nil
+		}
 	}
 
 	// Get all files in the .tekton directory recursively
@@ -335,7 +394,31 @@ func (v *Provider) GetTektonDir(ctx context.Context, runevent *info.Event, path,
 	if err != nil {
 		return "", err
 	}
-	return v.concatAllYamlFiles(ctx, tektonDirObjects.Entries, runevent)
+	files, err := v.getAllYamlFiles(ctx, tektonDirObjects.Entries, runevent)
+	if err != nil {
+		return "", err
+	}
+	if isCommitSHA(revision) {
+		v.tektonDirs.Set(v.tektonDirCacheKey(runevent, path), revision, files)
+	}
+	return concatAllYamlFiles(files), nil
+}
+
+// getMergeBase returns the merge base between the target branch and the SHA
+// of the pull request, on other events there is no merge base and the SHA is
+// returned.
+func (v *Provider) getMergeBase(ctx context.Context, runevent *info.Event) (string, error) {
+	if runevent.TriggerTarget != triggertype.PullRequest || runevent.BaseBranch == "" {
+		return runevent.SHA, nil
+	}
+	comparison, _, err := v.Client.Repositories.CompareCommits(ctx, runevent.Organization, runevent.Repository, runevent.BaseBranch, runevent.SHA, &github.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("cannot get the merge base of %s and %s: %w", runevent.BaseBranch, runevent.SHA, err)
+	}
+	if comparison.GetMergeBaseCommit().GetSHA() == "" {
+		return "", fmt.Errorf("no merge base found between %s and %s", runevent.BaseBranch, runevent.SHA)
+	}
+	return comparison.GetMergeBaseCommit().GetSHA(), nil
 }
 
 // GetCommitInfo get info (url and title) on a commit in runevent, this needs to
@@ -365,11 +448,37 @@ func (v *Provider) GetCommitInfo(ctx context.Context, runevent *info.Event) erro
 
 	runevent.SHAURL = commit.GetHTMLURL()
 	runevent.SHATitle = strings.Split(commit.GetMessage(), "\n\n")[0]
+	runevent.SHAMessage = commit.GetMessage()
 	runevent.SHA = commit.GetSHA()
+	if verification := commit.GetVerification(); verification != nil {
+		runevent.SHASignature = provider.CommitSignatureFromVerification(verification.GetVerified(), verification.GetSignature())
+	}
 
+	if tag, ok := provider.PushedTag(runevent); ok {
+		if runevent.TagMessage, err = v.tagMessage(ctx, runevent, tag); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// tagMessage returns the message of an annotated tag, empty for a lightweight
+// one.
+func (v *Provider) tagMessage(ctx context.Context, runevent *info.Event, tag string) (string, error) {
+	ref, _, err := v.Client.Git.GetRef(ctx, runevent.Organization, runevent.Repository, "tags/"+tag)
+	if err != nil {
+		return "", err
+	}
+	if ref.GetObject().GetType() != "tag" {
+		return "", nil
+	}
+	annotated, _, err := v.Client.Git.GetTag(ctx, runevent.Organization, runevent.Repository, ref.GetObject().GetSHA())
+	if err != nil {
+		return "", err
+	}
+	return annotated.GetMessage(), nil
+}
+
 // GetFileInsideRepo Get a file via Github API using the runinfo information, we
 // branch is true, the user the branch as ref instead of the SHA
 // TODO: merge GetFileInsideRepo amd GetTektonDir.
@@ -379,10 +488,20 @@ func (v *Provider) GetFileInsideRepo(ctx context.Context, runevent *info.Event,
 		ref = runevent.BaseBranch
 	} else if v.provenance == "default_branch" {
 		ref = runevent.DefaultBranch
+	} else if v.provenance == "merge_base" && v.mergeBaseSHA != "" {
+		ref = v.mergeBaseSHA
 	}
+	return v.GetFileAtRevision(ctx, runevent, path, ref)
+}
+
+// GetFileAtRevision returns the content of a file of the repository at a
+// revision.
+func (v *Provider) GetFileAtRevision(ctx context.Context, runevent *info.Event, path, revision string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
 
 	fp, objects, _, err := v.Client.Repositories.GetContents(ctx, runevent.Organization,
-		runevent.Repository, path, &github.RepositoryContentGetOptions{Ref: ref})
+		runevent.Repository, path, &github.RepositoryContentGetOptions{Ref: revision})
 	if err != nil {
 		return "", err
 	}
@@ -398,29 +517,45 @@ func (v *Provider) GetFileInsideRepo(ctx context.Context, runevent *info.Event,
 	return string(getobj), nil
 }
 
-// concatAllYamlFiles concat all yaml files from a directory as one big multi document yaml string.
-func (v *Provider) concatAllYamlFiles(ctx context.Context, objects []*github.TreeEntry, runevent *info.Event) (string, error) {
-	var allTemplates string
-
+// getAllYamlFiles gets the content of all the yaml files of a directory, by
+// their path relative to the directory.
+func (v *Provider) getAllYamlFiles(ctx context.Context, objects []*github.TreeEntry, runevent *info.Event) (map[string]string, error) {
+	files := map[string]string{}
 	for _, value := range objects {
-		if strings.HasSuffix(value.GetPath(), ".yaml") ||
-			strings.HasSuffix(value.GetPath(), ".yml") {
+		if provider.IsPipelineRunFile(v.repo, value.GetPath()) {
 			data, err := v.getObject(ctx, value.GetSHA(), runevent)
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 			// validate yaml
 			var i any
 			if err := yaml.Unmarshal(data, &i); err != nil {
-				return "", fmt.Errorf("error unmarshalling yaml file %s: %w", value.GetPath(), err)
-			}
-			if allTemplates != "" && !strings.HasPrefix(string(data), "---") {
-				allTemplates += "---"
+				return nil, fmt.Errorf("error unmarshalling yaml file %s: %w", value.GetPath(), err)
 			}
-			allTemplates += "\n" + string(data) + "\n"
+			files[value.GetPath()] = string(data)
 		}
 	}
-	return allTemplates, nil
+	return files, nil
+}
+
+// concatAllYamlFiles concat all yaml files from a directory as one big multi
+// document yaml string, in the order of the git tree.
+func concatAllYamlFiles(files map[string]string) string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var allTemplates string
+	for _, path := range paths {
+		data := files[path]
+		if allTemplates != "" && !strings.HasPrefix(data, "---") {
+			allTemplates += "---"
+		}
+		allTemplates += "\n" + data + "\n"
+	}
+	return allTemplates
 }
 
 // getPullRequest get a pull request details.
@@ -456,61 +591,50 @@ func (v *Provider) getPullRequest(ctx context.Context, runevent *info.Event) (*i
 
 // GetFiles get a files from pull request.
 func (v *Provider) GetFiles(ctx context.Context, runevent *info.Event) (changedfiles.ChangedFiles, error) {
-	if runevent.TriggerTarget == triggertype.PullRequest {
-		opt := &github.ListOptions{PerPage: v.paginedNumber}
-		changedFiles := changedfiles.ChangedFiles{}
-		for {
-			repoCommit, resp, err := v.Client.PullRequests.ListFiles(ctx, runevent.Organization, runevent.Repository, runevent.PullRequestNumber, opt)
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationDiff)
+	defer done()
+
+	var files []*github.CommitFile
+	var err error
+	switch runevent.TriggerTarget {
+	case triggertype.PullRequest:
+		files, err = provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*github.CommitFile, int, error) {
+			prFiles, resp, err := v.Client.PullRequests.ListFiles(ctx, runevent.Organization, runevent.Repository, runevent.PullRequestNumber,
+				&github.ListOptions{PerPage: v.paginedNumber, Page: page})
+			return prFiles, nextPage(resp), err
+		})
+	case triggertype.Push:
+		// the files of a commit are paginated past the first 300 files
+		files, err = provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*github.CommitFile, int, error) {
+			commit, resp, err := v.Client.Repositories.GetCommit(ctx, runevent.Organization, runevent.Repository, runevent.SHA,
+				&github.ListOptions{PerPage: v.paginedNumber, Page: page})
 			if err != nil {
-				return changedfiles.ChangedFiles{}, err
+				return nil, 0, err
 			}
-			for j := range repoCommit {
-				changedFiles.All = append(changedFiles.All, *repoCommit[j].Filename)
-				if *repoCommit[j].Status == "added" {
-					changedFiles.Added = append(changedFiles.Added, *repoCommit[j].Filename)
-				}
-				if *repoCommit[j].Status == "removed" {
-					changedFiles.Deleted = append(changedFiles.Deleted, *repoCommit[j].Filename)
-				}
-				if *repoCommit[j].Status == "modified" {
-					changedFiles.Modified = append(changedFiles.Modified, *repoCommit[j].Filename)
-				}
-				if *repoCommit[j].Status == "renamed" {
-					changedFiles.Renamed = append(changedFiles.Renamed, *repoCommit[j].Filename)
-				}
-			}
-			if resp.NextPage == 0 {
-				break
-			}
-			opt.Page = resp.NextPage
-		}
-		return changedFiles, nil
+			return commit.Files, nextPage(resp), nil
+		})
+	default:
+		return changedfiles.ChangedFiles{}, nil
 	}
-
-	if runevent.TriggerTarget == "push" {
-		changedFiles := changedfiles.ChangedFiles{}
-		rC, _, err := v.Client.Repositories.GetCommit(ctx, runevent.Organization, runevent.Repository, runevent.SHA, &github.ListOptions{})
-		if err != nil {
-			return changedfiles.ChangedFiles{}, err
-		}
-		for i := range rC.Files {
-			changedFiles.All = append(changedFiles.All, *rC.Files[i].Filename)
-			if *rC.Files[i].Status == "added" {
-				changedFiles.Added = append(changedFiles.Added, *rC.Files[i].Filename)
-			}
-			if *rC.Files[i].Status == "removed" {
-				changedFiles.Deleted = append(changedFiles.Deleted, *rC.Files[i].Filename)
-			}
-			if *rC.Files[i].Status == "modified" {
-				changedFiles.Modified = append(changedFiles.Modified, *rC.Files[i].Filename)
-			}
-			if *rC.Files[i].Status == "renamed" {
-				changedFiles.Renamed = append(changedFiles.Renamed, *rC.Files[i].Filename)
-			}
+	if err != nil {
+		return changedfiles.ChangedFiles{}, err
+	}
+
+	changedFiles := changedfiles.ChangedFiles{}
+	for _, file := range files {
+		changedFiles.All = append(changedFiles.All, file.GetFilename())
+		switch file.GetStatus() {
+		case "added":
+			changedFiles.Added = append(changedFiles.Added, file.GetFilename())
+		case "removed":
+			changedFiles.Deleted = append(changedFiles.Deleted, file.GetFilename())
+		case "modified":
+			changedFiles.Modified = append(changedFiles.Modified, file.GetFilename())
+		case "renamed":
+			changedFiles.Renamed = append(changedFiles.Renamed, file.GetFilename())
 		}
-		return changedFiles, nil
 	}
-	return changedfiles.ChangedFiles{}, nil
+	return changedFiles, nil
 }
 
 // getObject Get an object from a repository.
@@ -534,24 +658,31 @@ func ListRepos(ctx context.Context, v *Provider) ([]string, error) {
 			"exiting... (hint: did you forget setting a secret on your repo?)")
 	}
 
-	opt := &github.ListOptions{PerPage: v.paginedNumber}
-	repoURLs := []string{}
-	for {
-		repoList, resp, err := v.Client.Apps.ListRepos(ctx, opt)
+	repoURLs, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]string, int, error) {
+		repoList, resp, err := v.Client.Apps.ListRepos(ctx, &github.ListOptions{PerPage: v.paginedNumber, Page: page})
 		if err != nil {
-			return []string{}, err
+			return nil, 0, err
 		}
-		for i := range repoList.Repositories {
-			repoURLs = append(repoURLs, *repoList.Repositories[i].HTMLURL)
+		urls := []string{}
+		for _, repo := range repoList.Repositories {
+			urls = append(urls, repo.GetHTMLURL())
 		}
-		if resp.NextPage == 0 {
-			break
-		}
-		opt.Page = resp.NextPage
+		return urls, nextPage(resp), nil
+	})
+	if err != nil {
+		return []string{}, err
 	}
 	return repoURLs, nil
 }
 
+// nextPage returns the next page of a paginated response, 0 on the last page.
+func nextPage(resp *github.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.NextPage
+}
+
 func (v *Provider) CreateToken(ctx context.Context, repository []string, event *info.Event) (string, error) {
 	for _, r := range repository {
 		split := strings.Split(r, "/")
@@ -23,6 +23,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
 	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
@@ -256,6 +257,31 @@ func TestGetTektonDir(t *testing.T) {
 			provenance:           "default_branch",
 			filterMessageSnippet: "Using PipelineRun definition from default_branch: main",
 		},
+		{
+			name: "test provenance merge_base",
+			event: &info.Event{
+				Organization:  "tekton",
+				Repository:    "cat",
+				BaseBranch:    "main",
+				TriggerTarget: triggertype.PullRequest,
+			},
+			expectedString:       "PipelineRun",
+			treepath:             "testdata/tree/simple",
+			provenance:           "merge_base",
+			filterMessageSnippet: "Using PipelineRun definition from merge base of main and headsha",
+		},
+		{
+			name: "test provenance merge_base error",
+			event: &info.Event{
+				Organization:  "tekton",
+				Repository:    "cat",
+				BaseBranch:    "nomergebase",
+				TriggerTarget: triggertype.PullRequest,
+			},
+			treepath:   "testdata/tree/simple",
+			provenance: "merge_base",
+			wantErr:    "no merge base found between nomergebase and headsha",
+		},
 		{
 			name: "test with subtree",
 			event: &info.Event{
@@ -296,6 +322,16 @@ func TestGetTektonDir(t *testing.T) {
 				tt.event.SHA = shaDir
 			}
 			ghtesthelper.SetupGitTree(t, mux, tt.treepath, tt.event, false)
+			if tt.provenance == "merge_base" {
+				mergeBase := tt.event.SHA
+				mux.HandleFunc("/repos/tekton/cat/compare/main...headsha", func(w http.ResponseWriter, _ *http.Request) {
+					fmt.Fprintf(w, `{"merge_base_commit": {"sha": "%s"}}`, mergeBase)
+				})
+				mux.HandleFunc("/repos/tekton/cat/compare/nomergebase...headsha", func(w http.ResponseWriter, _ *http.Request) {
+					fmt.Fprint(w, `{}`)
+				})
+				tt.event.SHA = "headsha"
+			}
 
 			got, err := gvcs.GetTektonDir(ctx, tt.event, ".tekton", tt.provenance)
 			if tt.wantErr != "" {
@@ -313,6 +349,46 @@ func TestGetTektonDir(t *testing.T) {
 	}
 }
 
+func TestGetTektonDirNested(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+	logger, _ := logger.GetLogger()
+	gvcs := Provider{
+		Client: fakeclient,
+		Logger: logger,
+		repo: &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{
+			PipelineRunExtensions: []string{".yml"},
+		}}},
+	}
+	trees := map[string]string{
+		"123":       `{"tree": [{"path": "ci", "type": "tree", "sha": "cisha"}, {"path": "README.md", "type": "blob", "sha": "readme"}]}`,
+		"cisha":     `{"tree": [{"path": "tekton", "type": "tree", "sha": "tektonsha"}]}`,
+		"tektonsha": `{"tree": [{"path": "pr.yml", "type": "blob", "sha": "prsha"}, {"path": "pr.yaml", "type": "blob", "sha": "ignored"}]}`,
+	}
+	for sha, tree := range trees {
+		tree := tree
+		mux.HandleFunc("/repos/tekton/cat/git/trees/"+sha, func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprint(w, tree)
+		})
+	}
+	mux.HandleFunc("/repos/tekton/cat/git/blobs/prsha", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"sha": "prsha", "content": "%s"}`, base64.StdEncoding.EncodeToString([]byte("kind: PipelineRun")))
+	})
+	event := &info.Event{Organization: "tekton", Repository: "cat", SHA: "123"}
+
+	got, err := gvcs.GetTektonDir(ctx, event, "ci/tekton", "")
+	assert.NilError(t, err)
+	assert.Equal(t, strings.TrimSpace(got), "kind: PipelineRun")
+
+	got, err = gvcs.GetTektonDir(ctx, event, "ci/other", "")
+	assert.NilError(t, err)
+	assert.Equal(t, got, "")
+
+	_, err = gvcs.GetTektonDir(ctx, event, "README.md/tekton", "")
+	assert.Error(t, err, "README.md/tekton has been found but is not a directory")
+}
+
 func TestGetFileInsideRepo(t *testing.T) {
 	testGetTektonDir := []struct {
 		name       string
@@ -396,6 +472,25 @@ func TestGetFileInsideRepo(t *testing.T) {
 	}
 }
 
+func TestGetFileAtRevision(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+	// the revision is used whatever the provenance of the PipelineRuns
+	gvcs := Provider{Client: fakeclient, provenance: "default_branch"}
+	mux.HandleFunc("/repos/tekton/thecat/contents/retfile", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("ref"), "123")
+		fmt.Fprint(w, `{"name": "file", "path": "retfile", "sha": "shafile"}`)
+	})
+	mux.HandleFunc("/repos/tekton/thecat/git/blobs/shafile", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"content": "aGVsbG8=", "encoding": "base64", "sha": "shafile"}`)
+	})
+	event := &info.Event{Organization: "tekton", Repository: "thecat", SHA: "123", DefaultBranch: "main"}
+	got, err := gvcs.GetFileAtRevision(ctx, event, "retfile", event.SHA)
+	assert.NilError(t, err)
+	assert.Equal(t, got, "hello")
+}
+
 func TestCheckSenderOrgMembership(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -566,6 +661,72 @@ func TestGithubGetCommitInfo(t *testing.T) {
 	}
 }
 
+func TestGithubGetCommitInfoSignatureAndTag(t *testing.T) {
+	signature := "-----BEGIN SSH SIGNATURE-----\n" +
+		"U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAgU0gYuzB/oAJkYLen/kZbg7ZbA0\n" +
+		"Zq9lKa310jG7x8SWAAAAADZ2l0AAAAAAAAAAZzaGE1MTIAAABTAAAAC3NzaC1lZDI1NTE5\n" +
+		"AAAAQHar/0teBLNQJJXs6rIzZyt6TnveUaQZXZ5bcacC5gurLEzd/InQaVLNtK+b1uIXma\n" +
+		"xhjoU+aNGXV7HnsmNRAwM=\n" +
+		"-----END SSH SIGNATURE-----\n"
+	tests := []struct {
+		name          string
+		ref           string
+		verification  *github.SignatureVerification
+		tagType       string
+		wantSignature info.CommitSignature
+		wantMessage   string
+	}{
+		{
+			name:          "verified commit on a branch",
+			ref:           "refs/heads/main",
+			verification:  &github.SignatureVerification{Verified: github.Bool(true), Signature: github.String(signature)},
+			wantSignature: info.CommitSignature{Status: info.SignatureVerified, KeyID: "SHA256:zNghUkztfISnTg3TcUNzTVNuYbffCNwCuem20+t5VVE"},
+		},
+		{
+			name:          "unsigned commit with an annotated tag",
+			ref:           "refs/tags/v1.0.0",
+			verification:  &github.SignatureVerification{Verified: github.Bool(false), Reason: github.String("unsigned")},
+			tagType:       "tag",
+			wantSignature: info.CommitSignature{Status: info.SignatureUnsigned},
+			wantMessage:   "Release v1.0.0",
+		},
+		{
+			name:          "lightweight tag",
+			ref:           "refs/tags/v1.0.0",
+			tagType:       "commit",
+			wantSignature: info.CommitSignature{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			mux.HandleFunc("/repos/owner/repository/git/commits/sha", func(rw http.ResponseWriter, _ *http.Request) {
+				b, _ := json.Marshal(&github.Commit{SHA: github.String("sha"), Message: github.String("commit"), Verification: tt.verification})
+				fmt.Fprint(rw, string(b))
+			})
+			mux.HandleFunc("/repos/owner/repository/git/ref/tags/v1.0.0", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprintf(rw, `{"ref": "refs/tags/v1.0.0", "object": {"type": "%s", "sha": "tagsha"}}`, tt.tagType)
+			})
+			mux.HandleFunc("/repos/owner/repository/git/tags/tagsha", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, `{"tag": "v1.0.0", "message": "Release v1.0.0"}`)
+			})
+			ctx, _ := rtesting.SetupFakeContext(t)
+			event := &info.Event{
+				Organization:  "owner",
+				Repository:    "repository",
+				SHA:           "sha",
+				BaseBranch:    tt.ref,
+				TriggerTarget: triggertype.Push,
+			}
+			provider := &Provider{Client: fakeclient}
+			assert.NilError(t, provider.GetCommitInfo(ctx, event))
+			assert.DeepEqual(t, event.SHASignature, tt.wantSignature)
+			assert.Equal(t, event.TagMessage, tt.wantMessage)
+		})
+	}
+}
+
 func TestGithubSetClient(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,30 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.PullRequestMergeInterface = (*Provider)(nil)
+
+// MergePullRequest merges the pull request of the event with the default merge
+// method of the repository, GitHub refuses it if the head is not the SHA of the
+// event anymore.
+func (v *Provider) MergePullRequest(ctx context.Context, event *info.Event) error {
+	if v.Client == nil {
+		return fmt.Errorf("no github client has been initialized")
+	}
+	result, _, err := v.Client.PullRequests.Merge(ctx, event.Organization, event.Repository, event.PullRequestNumber, "",
+		&github.PullRequestOptions{SHA: event.SHA})
+	if err != nil {
+		return err
+	}
+	if !result.GetMerged() {
+		return fmt.Errorf("pull request %d has not been merged: %s", event.PullRequestNumber, result.GetMessage())
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestMergePullRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		wantErr string
+	}{
+		{
+			name:  "merged",
+			reply: `{"merged": true}`,
+		},
+		{
+			name:    "not merged",
+			reply:   `{"merged": false, "message": "not mergeable"}`,
+			wantErr: "pull request 42 has not been merged: not mergeable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			v := &Provider{Client: fakeclient}
+			mux.HandleFunc("/repos/owner/repo/pulls/42/merge", func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.Method, http.MethodPut)
+				body := map[string]any{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, body["sha"], "sha")
+				fmt.Fprint(w, tt.reply)
+			})
+			err := v.MergePullRequest(ctx, &info.Event{Organization: "owner", Repository: "repo", PullRequestNumber: 42, SHA: "sha"})
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"strconv"
 	"strings"
 
-	ghinstallation "github.com/bradleyfalzon/ghinstallation/v2"
 	oGitHub "github.com/google/go-github/v60/github"
 	"github.com/google/go-github/v61/github"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,6 +20,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 )
@@ -51,13 +51,18 @@ func (v *Provider) GetAppToken(ctx context.Context, kube kubernetes.Interface, g
 		return "", err
 	}
 	v.ApplicationID = &applicationID
-	tr := http.DefaultTransport
+	tr := provider.HTTPClient(ctx, v.Run, providerNameFor(gheURL)).Transport
 
-	itr, err := ghinstallation.New(tr, applicationID, installationID, privateKey)
+	appSettings := settings.DefaultSettings()
+	if v.Run != nil && v.Run.Info.Pac != nil {
+		appSettings = v.Run.Info.GetPacOpts().Settings
+	}
+	itr, err := newAppTokenTransport(tr, applicationID, installationID, privateKey,
+		appSettings.GitHubAppJWTClockSkewDuration(), appSettings.GitHubAppTokenRefreshBeforeDuration())
 	if err != nil {
 		return "", err
 	}
-	itr.InstallationTokenOptions = &oGitHub.InstallationTokenOptions{
+	itr.tokenOptions = &oGitHub.InstallationTokenOptions{
 		RepositoryIDs: v.RepositoryIDs,
 	}
 
@@ -99,7 +104,7 @@ func (v *Provider) parseEventType(request *http.Request, event *info.Event) erro
 
 	event.Provider.URL = request.Header.Get("X-GitHub-Enterprise-Host")
 
-	if event.EventType == "push" {
+	if event.EventType == "push" || event.EventType == provider.DeploymentProtectionRuleEventType {
 		event.TriggerTarget = triggertype.Push
 	} else {
 		event.TriggerTarget = triggertype.PullRequest
@@ -264,31 +269,46 @@ func (v *Provider) processEvent(ctx context.Context, event *info.Event, eventInt
 		}
 		processedEvent.SHAURL = gitEvent.GetHeadCommit().GetURL()
 		processedEvent.SHATitle = gitEvent.GetHeadCommit().GetMessage()
+		processedEvent.SHAMessage = gitEvent.GetHeadCommit().GetMessage()
 		processedEvent.Sender = gitEvent.GetSender().GetLogin()
+		processedEvent.SenderBot = gitEvent.GetSender().GetType() == "Bot"
 		processedEvent.BaseBranch = gitEvent.GetRef()
 		processedEvent.EventType = event.TriggerTarget.String()
 		processedEvent.HeadBranch = processedEvent.BaseBranch // in push events Head Branch is the same as Basebranch
 		processedEvent.BaseURL = gitEvent.GetRepo().GetHTMLURL()
 		processedEvent.HeadURL = processedEvent.BaseURL // in push events Head URL is the same as BaseURL
+		if gitEvent.GetDeleted() {
+			processedEvent.BranchDeleted = true
+			processedEvent.CancelPipelineRuns = true
+		}
 	case *github.PullRequestEvent:
+		v.processPullRequest(processedEvent, gitEvent.GetRepo(), gitEvent.GetPullRequest())
+		processedEvent.EventType = event.EventType
+		processedEvent.PullRequestClosed = gitEvent.GetAction() == "closed"
+	case *github.PullRequestReviewEvent:
+		// the approvals run the PipelineRuns gated by the min-approvals
+		// annotation, the sender stays the author of the pull request for
+		// the ACL checks.
+		v.processPullRequest(processedEvent, gitEvent.GetRepo(), gitEvent.GetPullRequest())
+		processedEvent.EventType = provider.PullRequestReviewEventType
+	case *github.DeploymentProtectionRuleEvent:
+		// the deployment waits on the PipelineRuns verifying its environment,
+		// they approve or reject it through the callback URL once finished.
+		processedEvent.Organization = gitEvent.GetRepo().GetOwner().GetLogin()
 		processedEvent.Repository = gitEvent.GetRepo().GetName()
-		processedEvent.Organization = gitEvent.GetRepo().Owner.GetLogin()
 		processedEvent.DefaultBranch = gitEvent.GetRepo().GetDefaultBranch()
-		processedEvent.SHA = gitEvent.GetPullRequest().Head.GetSHA()
 		processedEvent.URL = gitEvent.GetRepo().GetHTMLURL()
-		processedEvent.BaseBranch = gitEvent.GetPullRequest().Base.GetRef()
-		processedEvent.HeadBranch = gitEvent.GetPullRequest().Head.GetRef()
-		processedEvent.BaseURL = gitEvent.GetPullRequest().Base.GetRepo().GetHTMLURL()
-		processedEvent.HeadURL = gitEvent.GetPullRequest().Head.GetRepo().GetHTMLURL()
-		processedEvent.Sender = gitEvent.GetPullRequest().GetUser().GetLogin()
-		processedEvent.EventType = event.EventType
-		processedEvent.PullRequestNumber = gitEvent.GetPullRequest().GetNumber()
-		processedEvent.PullRequestTitle = gitEvent.GetPullRequest().GetTitle()
-		// getting the repository ids of the base and head of the pull request
-		// to scope the token to
-		v.RepositoryIDs = []int64{
-			gitEvent.GetPullRequest().GetBase().GetRepo().GetID(),
-		}
+		v.RepositoryIDs = []int64{gitEvent.GetRepo().GetID()}
+		processedEvent.SHA = gitEvent.GetDeployment().GetSHA()
+		processedEvent.Sender = gitEvent.GetSender().GetLogin()
+		processedEvent.SenderBot = gitEvent.GetSender().GetType() == "Bot"
+		processedEvent.BaseBranch = gitEvent.GetDeployment().GetRef()
+		processedEvent.HeadBranch = processedEvent.BaseBranch
+		processedEvent.BaseURL = processedEvent.URL
+		processedEvent.HeadURL = processedEvent.BaseURL
+		processedEvent.EventType = provider.DeploymentProtectionRuleEventType
+		processedEvent.DeploymentEnvironment = gitEvent.GetEnvironment()
+		processedEvent.DeploymentCallbackURL = gitEvent.GetDeploymentCallbackURL()
 	default:
 		return nil, errors.New("this event is not supported")
 	}
@@ -299,7 +319,35 @@ func (v *Provider) processEvent(ctx context.Context, event *info.Event, eventInt
 	return processedEvent, nil
 }
 
+// processPullRequest fills the event with the fields of the pull request.
+func (v *Provider) processPullRequest(processedEvent *info.Event, repo *github.Repository, pr *github.PullRequest) {
+	processedEvent.Repository = repo.GetName()
+	processedEvent.Organization = repo.GetOwner().GetLogin()
+	processedEvent.DefaultBranch = repo.GetDefaultBranch()
+	processedEvent.SHA = pr.GetHead().GetSHA()
+	processedEvent.URL = repo.GetHTMLURL()
+	processedEvent.BaseBranch = pr.GetBase().GetRef()
+	processedEvent.HeadBranch = pr.GetHead().GetRef()
+	processedEvent.BaseURL = pr.GetBase().GetRepo().GetHTMLURL()
+	processedEvent.HeadURL = pr.GetHead().GetRepo().GetHTMLURL()
+	processedEvent.Sender = pr.GetUser().GetLogin()
+	processedEvent.SenderBot = pr.GetUser().GetType() == "Bot"
+	processedEvent.PullRequestNumber = pr.GetNumber()
+	processedEvent.PullRequestTitle = pr.GetTitle()
+	for _, label := range pr.Labels {
+		processedEvent.PullRequestLabel = append(processedEvent.PullRequestLabel, label.GetName())
+	}
+	// getting the repository ids of the base and head of the pull request
+	// to scope the token to
+	v.RepositoryIDs = []int64{
+		pr.GetBase().GetRepo().GetID(),
+	}
+}
+
 func (v *Provider) handleReRequestEvent(ctx context.Context, event *github.CheckRunEvent) (*info.Event, error) {
+	if event.GetCheckRun().GetCheckSuite() == nil {
+		return nil, fmt.Errorf("no check suite in the check_run event")
+	}
 	runevent := info.NewEvent()
 	runevent.Organization = event.GetRepo().GetOwner().GetLogin()
 	runevent.Repository = event.GetRepo().GetName()
@@ -325,6 +373,9 @@ func (v *Provider) handleReRequestEvent(ctx context.Context, event *github.Check
 }
 
 func (v *Provider) handleCheckSuites(ctx context.Context, event *github.CheckSuiteEvent) (*info.Event, error) {
+	if event.GetCheckSuite() == nil {
+		return nil, fmt.Errorf("no check suite in the check_suite event")
+	}
 	runevent := info.NewEvent()
 	runevent.Organization = event.GetRepo().GetOwner().GetLogin()
 	runevent.Repository = event.GetRepo().GetName()
@@ -372,6 +423,7 @@ func (v *Provider) handleIssueCommentEvent(ctx context.Context, event *github.Is
 		return info.NewEvent(), fmt.Errorf("issue comment is not coming from a pull_request")
 	}
 	opscomments.SetEventTypeAndTargetPR(runevent, event.GetComment().GetBody())
+	runevent.TriggerCommentID = event.GetComment().GetID()
 	// We are getting the full URL so we have to get the last part to get the PR number,
 	// we don't have to care about URL query string/hash and other stuff because
 	// that comes up from the API.
@@ -395,36 +447,19 @@ func (v *Provider) handleCommitCommentEvent(ctx context.Context, event *github.C
 	runevent.SHA = event.GetComment().GetCommitID()
 	runevent.HeadURL = runevent.URL
 	runevent.BaseURL = runevent.HeadURL
-	runevent.EventType = "push"
-	runevent.TriggerTarget = "push"
-	runevent.TriggerComment = event.GetComment().GetBody()
 
 	// Set main as default branch to runevent.HeadBranch, runevent.BaseBranch
 	runevent.HeadBranch, runevent.BaseBranch = "main", "main"
-	var (
-		branchName string
-		prName     string
-		err        error
-	)
-
-	// TODO: reuse the code from opscomments
-	// If it is a /test or /retest comment with pipelinerun name figure out the pipelinerun name
-	if provider.IsTestRetestComment(event.GetComment().GetBody()) {
-		prName, branchName, err = provider.GetPipelineRunAndBranchNameFromTestComment(event.GetComment().GetBody())
-		if err != nil {
-			return runevent, err
-		}
-		runevent.TargetTestPipelineRun = prName
+
+	// If it is a /test, /retest or /cancel comment figure out the pipelinerun
+	// name and the branch
+	branchName, err := opscomments.SetEventTypeAndTargetPush(runevent, event.GetComment().GetBody())
+	if err != nil {
+		return runevent, err
 	}
-	// Check for /cancel comment
-	if provider.IsCancelComment(event.GetComment().GetBody()) {
+	runevent.TriggerCommentID = event.GetComment().GetID()
+	if runevent.CancelPipelineRuns {
 		action = "cancellation"
-		prName, branchName, err = provider.GetPipelineRunAndBranchNameFromCancelComment(event.GetComment().GetBody())
-		if err != nil {
-			return runevent, err
-		}
-		runevent.CancelPipelineRuns = true
-		runevent.TargetCancelPipelineRun = prName
 	}
 
 	// If no branch is specified in GitOps comments, use runevent.HeadBranch
@@ -434,9 +469,7 @@ func (v *Provider) handleCommitCommentEvent(ctx context.Context, event *github.C
 
 	// Check if the specified branch contains the commit
 	if err = v.isBranchContainsCommit(ctx, runevent, branchName); err != nil {
-		if provider.IsCancelComment(event.GetComment().GetBody()) {
-			runevent.CancelPipelineRuns = false
-		}
+		runevent.CancelPipelineRuns = false
 		return runevent, err
 	}
 	// Finally update branch information to runevent.HeadBranch and runevent.BaseBranch
@@ -280,6 +280,21 @@ func TestParsePayLoad(t *testing.T) {
 			},
 			shaRet: "SHAPush",
 		},
+		{
+			name:          "good/push deleting a branch",
+			eventType:     "push",
+			triggerTarget: "push",
+			payloadEventStruct: github.PushEvent{
+				Repo: &github.PushEventRepository{
+					Owner: &github.User{Login: github.String("owner")},
+					Name:  github.String("pushRepo"),
+				},
+				Before:  github.String("SHABefore"),
+				Deleted: github.Bool(true),
+			},
+			shaRet:                     "SHABefore",
+			isCancelPipelineRunEnabled: true,
+		},
 		{
 			name:          "good/issue comment for retest",
 			eventType:     "issue_comment",
@@ -591,6 +606,10 @@ func TestParsePayLoad(t *testing.T) {
 			if tt.eventType == "pull_request" {
 				assert.Equal(t, "my first PR", ret.PullRequestTitle)
 			}
+			if tt.eventType == "push" {
+				assert.Equal(t, tt.isCancelPipelineRunEnabled, ret.CancelPipelineRuns)
+				assert.Equal(t, tt.isCancelPipelineRunEnabled, ret.BranchDeleted)
+			}
 			if tt.eventType == "commit_comment" {
 				assert.Equal(t, tt.wantedBranchName, ret.HeadBranch)
 				assert.Equal(t, tt.wantedBranchName, ret.BaseBranch)
@@ -0,0 +1,70 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.PreviewEnvironmentInterface = (*Provider)(nil)
+
+// CreatePreviewEnvironment creates a deployment of the pull request SHA to its
+// preview environment with a successful status pointing to url, the previous
+// deployments of the environment are automatically set as inactive.
+func (v *Provider) CreatePreviewEnvironment(ctx context.Context, event *info.Event, url, detailsURL string) error {
+	if v.Client == nil {
+		return fmt.Errorf("no github client has been initialized")
+	}
+	environment := provider.PreviewEnvironmentName(event)
+	deployment, _, err := v.Client.Repositories.CreateDeployment(ctx, event.Organization, event.Repository, &github.DeploymentRequest{
+		Ref:                  github.String(event.SHA),
+		Environment:          github.String(environment),
+		Description:          github.String(fmt.Sprintf("Preview of pull request #%d", event.PullRequestNumber)),
+		AutoMerge:            github.Bool(false),
+		RequiredContexts:     &[]string{},
+		TransientEnvironment: github.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create deployment for environment %s: %w", environment, err)
+	}
+
+	status := &github.DeploymentStatusRequest{
+		State:          github.String("success"),
+		EnvironmentURL: github.String(url),
+		AutoInactive:   github.Bool(true),
+	}
+	if detailsURL != "" {
+		status.LogURL = github.String(detailsURL)
+	}
+	if _, _, err := v.Client.Repositories.CreateDeploymentStatus(ctx, event.Organization, event.Repository, deployment.GetID(), status); err != nil {
+		return fmt.Errorf("cannot create deployment status for environment %s: %w", environment, err)
+	}
+	return nil
+}
+
+// DeactivatePreviewEnvironment sets the deployments of the preview environment
+// of the pull request as inactive.
+func (v *Provider) DeactivatePreviewEnvironment(ctx context.Context, event *info.Event) error {
+	if v.Client == nil {
+		return fmt.Errorf("no github client has been initialized")
+	}
+	environment := provider.PreviewEnvironmentName(event)
+	deployments, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*github.Deployment, int, error) {
+		deployments, resp, err := v.Client.Repositories.ListDeployments(ctx, event.Organization, event.Repository,
+			&github.DeploymentsListOptions{Environment: environment, ListOptions: github.ListOptions{PerPage: 100, Page: page}})
+		return deployments, nextPage(resp), err
+	})
+	if err != nil {
+		return fmt.Errorf("cannot list deployments for environment %s: %w", environment, err)
+	}
+	for _, deployment := range deployments {
+		if _, _, err := v.Client.Repositories.CreateDeploymentStatus(ctx, event.Organization, event.Repository, deployment.GetID(),
+			&github.DeploymentStatusRequest{State: github.String("inactive")}); err != nil {
+			return fmt.Errorf("cannot deactivate deployment %d of environment %s: %w", deployment.GetID(), environment, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestCreatePreviewEnvironment(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+	v := &Provider{Client: fakeclient}
+	event := &info.Event{Organization: "owner", Repository: "repo", SHA: "sha", PullRequestNumber: 42}
+
+	mux.HandleFunc("/repos/owner/repo/deployments", func(w http.ResponseWriter, r *http.Request) {
+		deployment := &github.DeploymentRequest{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(deployment))
+		assert.Equal(t, deployment.GetRef(), "sha")
+		assert.Equal(t, deployment.GetEnvironment(), "preview/pr-42")
+		assert.Assert(t, deployment.GetTransientEnvironment())
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+	statusCreated := false
+	mux.HandleFunc("/repos/owner/repo/deployments/1/statuses", func(w http.ResponseWriter, r *http.Request) {
+		status := &github.DeploymentStatusRequest{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(status))
+		assert.Equal(t, status.GetState(), "success")
+		assert.Equal(t, status.GetEnvironmentURL(), "https://preview.example.com")
+		assert.Equal(t, status.GetLogURL(), "https://console/pr")
+		statusCreated = true
+		fmt.Fprint(w, `{}`)
+	})
+
+	assert.NilError(t, v.CreatePreviewEnvironment(ctx, event, "https://preview.example.com", "https://console/pr"))
+	assert.Assert(t, statusCreated)
+}
+
+func TestDeactivatePreviewEnvironment(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+	v := &Provider{Client: fakeclient}
+	event := &info.Event{Organization: "owner", Repository: "repo", PullRequestNumber: 42}
+
+	mux.HandleFunc("/repos/owner/repo/deployments", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("environment"), "preview/pr-42")
+		fmt.Fprint(w, `[{"id": 1}, {"id": 2}]`)
+	})
+	deactivated := []int{}
+	for _, id := range []int{1, 2} {
+		id := id
+		mux.HandleFunc(fmt.Sprintf("/repos/owner/repo/deployments/%d/statuses", id), func(w http.ResponseWriter, r *http.Request) {
+			status := &github.DeploymentStatusRequest{}
+			assert.NilError(t, json.NewDecoder(r.Body).Decode(status))
+			assert.Equal(t, status.GetState(), "inactive")
+			deactivated = append(deactivated, id)
+			fmt.Fprint(w, `{}`)
+		})
+	}
+
+	assert.NilError(t, v.DeactivatePreviewEnvironment(ctx, event))
+	assert.DeepEqual(t, deactivated, []int{1, 2})
+}
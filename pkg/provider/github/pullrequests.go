@@ -0,0 +1,58 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.OpenPullRequestsInterface = (*Provider)(nil)
+
+// ListOpenPullRequests lists the open pull requests targeting the base
+// branch, the most recently updated first.
+func (v *Provider) ListOpenPullRequests(ctx context.Context, event *info.Event, baseBranch string, limit int) ([]provider.PullRequest, error) {
+	if v.Client == nil {
+		return nil, fmt.Errorf("no github client has been initialized")
+	}
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		Base:        baseBranch,
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	ret := []provider.PullRequest{}
+	for {
+		prs, resp, err := v.Client.PullRequests.List(ctx, event.Organization, event.Repository, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			labels := []string{}
+			for _, label := range pr.Labels {
+				labels = append(labels, label.GetName())
+			}
+			ret = append(ret, provider.PullRequest{
+				Number:     pr.GetNumber(),
+				Title:      pr.GetTitle(),
+				Labels:     labels,
+				SHA:        pr.GetHead().GetSHA(),
+				HeadBranch: pr.GetHead().GetRef(),
+				HeadURL:    pr.GetHead().GetRepo().GetHTMLURL(),
+				BaseURL:    pr.GetBase().GetRepo().GetHTMLURL(),
+				Author:     pr.GetUser().GetLogin(),
+				UpdatedAt:  pr.GetUpdatedAt().Time,
+			})
+			if len(ret) == limit {
+				return ret, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return ret, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,46 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestListOpenPullRequests(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+	v := &Provider{Client: fakeclient}
+	mux.HandleFunc("/repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("state"), "open")
+		assert.Equal(t, r.URL.Query().Get("base"), "main")
+		assert.Equal(t, r.URL.Query().Get("sort"), "updated")
+		fmt.Fprint(w, `[
+			{"number": 2, "title": "fix", "user": {"login": "alice"}, "labels": [{"name": "bug"}],
+			 "head": {"sha": "abc", "ref": "fix", "repo": {"html_url": "https://github.com/alice/repo"}},
+			 "base": {"ref": "main", "repo": {"html_url": "https://github.com/owner/repo"}}},
+			{"number": 1, "title": "feature", "user": {"login": "bob"},
+			 "head": {"sha": "def", "ref": "feature"}, "base": {"ref": "main"}}
+		]`)
+	})
+	event := &info.Event{Organization: "owner", Repository: "repo"}
+
+	prs, err := v.ListOpenPullRequests(ctx, event, "main", 10)
+	assert.NilError(t, err)
+	assert.Equal(t, len(prs), 2)
+	assert.Equal(t, prs[0].Number, 2)
+	assert.Equal(t, prs[0].SHA, "abc")
+	assert.Equal(t, prs[0].HeadBranch, "fix")
+	assert.Equal(t, prs[0].HeadURL, "https://github.com/alice/repo")
+	assert.Equal(t, prs[0].Author, "alice")
+	assert.DeepEqual(t, prs[0].Labels, []string{"bug"})
+
+	prs, err = v.ListOpenPullRequests(ctx, event, "main", 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(prs), 1)
+}
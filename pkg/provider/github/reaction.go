@@ -0,0 +1,31 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.CommentReactionInterface = (*Provider)(nil)
+
+// AddCommentReaction reacts to the pull request or commit comment of the
+// event.
+func (v *Provider) AddCommentReaction(ctx context.Context, event *info.Event, reaction provider.Reaction) error {
+	if v.Client == nil {
+		return fmt.Errorf("no github client has been initialized")
+	}
+	content := "+1"
+	if reaction == provider.ReactionRocket {
+		content = "rocket"
+	}
+	var err error
+	if opscomments.IsCommitComment(event) {
+		_, _, err = v.Client.Reactions.CreateCommentReaction(ctx, event.Organization, event.Repository, event.TriggerCommentID, content)
+	} else {
+		_, _, err = v.Client.Reactions.CreateIssueCommentReaction(ctx, event.Organization, event.Repository, event.TriggerCommentID, content)
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestAddCommentReaction(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       *info.Event
+		reaction    provider.Reaction
+		wantPath    string
+		wantContent string
+	}{
+		{
+			name:        "pull request comment",
+			event:       &info.Event{TriggerTarget: triggertype.PullRequest, TriggerComment: "/test", TriggerCommentID: 42},
+			reaction:    provider.ReactionRocket,
+			wantPath:    "/repos/owner/repo/issues/comments/42/reactions",
+			wantContent: "rocket",
+		},
+		{
+			name:        "commit comment",
+			event:       &info.Event{TriggerTarget: triggertype.Push, TriggerComment: "/cancel", TriggerCommentID: 42},
+			reaction:    provider.ReactionThumbsUp,
+			wantPath:    "/repos/owner/repo/comments/42/reactions",
+			wantContent: "+1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			v := &Provider{Client: fakeclient}
+			tt.event.Organization, tt.event.Repository = "owner", "repo"
+
+			reacted := false
+			mux.HandleFunc(tt.wantPath, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.Method, http.MethodPost)
+				body := map[string]string{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, body["content"], tt.wantContent)
+				reacted = true
+				fmt.Fprint(w, `{}`)
+			})
+			assert.NilError(t, v.AddCommentReaction(ctx, tt.event, tt.reaction))
+			assert.Assert(t, reacted)
+		})
+	}
+}
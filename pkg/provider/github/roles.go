@@ -0,0 +1,71 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+)
+
+var _ provider.SenderRoleInterface = (*Provider)(nil)
+
+// permissionLevel is the permission of a user on a repository, with the name
+// of its role which go-github doesn't decode.
+type permissionLevel struct {
+	Permission string `json:"permission"`
+	RoleName   string `json:"role_name"`
+}
+
+// SenderRole returns the role of the sender from its permission on the
+// repository: admin is an owner, maintain a maintainer and write a
+// collaborator.
+func (v *Provider) SenderRole(ctx context.Context, event *info.Event) (info.Role, error) {
+	if v.Client == nil {
+		return info.RoleNone, fmt.Errorf("no github client has been initialized")
+	}
+	if event.Organization == event.Sender {
+		return info.RoleOwner, nil
+	}
+	req, err := v.Client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/collaborators/%s/permission",
+		url.PathEscape(event.Organization), url.PathEscape(event.Repository), url.PathEscape(event.Sender)), nil)
+	if err != nil {
+		return info.RoleNone, err
+	}
+	perm := &permissionLevel{}
+	resp, err := v.Client.Do(ctx, req, perm)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return info.RoleNone, err
+	}
+	switch {
+	case perm.RoleName == "admin" || perm.Permission == "admin":
+		return info.RoleOwner, nil
+	case perm.RoleName == "maintain":
+		return info.RoleMaintainer, nil
+	case perm.Permission == "write":
+		return info.RoleCollaborator, nil
+	}
+	if author := pullRequestAuthor(event); author != "" && author == event.Sender {
+		return info.RoleAuthor, nil
+	}
+	return info.RoleNone, nil
+}
+
+// pullRequestAuthor returns the login of the author of the pull request of
+// the event, empty when the event is not about a pull request.
+func pullRequestAuthor(event *info.Event) string {
+	switch gitEvent := event.Event.(type) {
+	case *github.PullRequestEvent:
+		return gitEvent.GetPullRequest().GetUser().GetLogin()
+	case *github.IssueCommentEvent:
+		if gitEvent.GetIssue().IsPullRequest() {
+			return gitEvent.GetIssue().GetUser().GetLogin()
+		}
+	case *github.PullRequestReviewEvent:
+		return gitEvent.GetPullRequest().GetUser().GetLogin()
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestSenderRole(t *testing.T) {
+	prEvent := &github.IssueCommentEvent{
+		Issue: &github.Issue{
+			User:             &github.User{Login: github.String("contributor")},
+			PullRequestLinks: &github.PullRequestLinks{URL: github.String("https://api.github.com/repos/owner/repo/pulls/1")},
+		},
+	}
+	tests := []struct {
+		name       string
+		sender     string
+		permission string
+		status     int
+		want       info.Role
+	}{
+		{
+			name:   "owner of the repository",
+			sender: "owner",
+			want:   info.RoleOwner,
+		},
+		{
+			name:       "admin",
+			sender:     "admin",
+			permission: `{"permission": "admin", "role_name": "admin"}`,
+			want:       info.RoleOwner,
+		},
+		{
+			name:       "maintainer",
+			sender:     "maintainer",
+			permission: `{"permission": "write", "role_name": "maintain"}`,
+			want:       info.RoleMaintainer,
+		},
+		{
+			name:       "collaborator",
+			sender:     "collaborator",
+			permission: `{"permission": "write", "role_name": "write"}`,
+			want:       info.RoleCollaborator,
+		},
+		{
+			name:       "author of the pull request",
+			sender:     "contributor",
+			permission: `{"permission": "read", "role_name": "read"}`,
+			want:       info.RoleAuthor,
+		},
+		{
+			name:       "someone else",
+			sender:     "someone",
+			permission: `{"permission": "read", "role_name": "read"}`,
+			want:       info.RoleNone,
+		},
+		{
+			name:   "unknown user",
+			sender: "ghost",
+			status: http.StatusNotFound,
+			want:   info.RoleNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			v := &Provider{Client: fakeclient}
+			mux.HandleFunc(fmt.Sprintf("/repos/owner/repo/collaborators/%s/permission", tt.sender), func(w http.ResponseWriter, _ *http.Request) {
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, `{"message": "Not Found"}`)
+					return
+				}
+				fmt.Fprint(w, tt.permission)
+			})
+			got, err := v.SenderRole(ctx, &info.Event{Organization: "owner", Repository: "repo", Sender: tt.sender, Event: prEvent})
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
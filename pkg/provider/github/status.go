@@ -19,61 +19,49 @@ import (
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 )
 
-const taskStatusTemplate = `
-<table>
-  <tr><th>Status</th><th>Duration</th><th>Name</th></tr>
-
-{{- range $taskrun := .TaskRunList }}
-<tr>
-<td>{{ formatCondition $taskrun.PipelineRunTaskRunStatus.Status.Conditions }}</td>
-<td>{{ formatDuration $taskrun.PipelineRunTaskRunStatus.Status.StartTime $taskrun.PipelineRunTaskRunStatus.Status.CompletionTime }}</td><td>
-
-{{ $taskrun.ConsoleLogURL }}
-
-</td></tr>
-{{- end }}
-</table>`
-
-func getCheckName(status provider.StatusOpts, pacopts *info.PacOpts) string {
-	if pacopts.ApplicationName != "" {
-		if status.OriginalPipelineRunName == "" {
-			return pacopts.ApplicationName
-		}
-		return fmt.Sprintf("%s / %s", pacopts.ApplicationName, status.OriginalPipelineRunName)
-	}
-	return status.OriginalPipelineRunName
-}
+const (
+	// maxCheckRunOutputLength is the maximum size GitHub accepts for the
+	// summary and the text of a check run output.
+	maxCheckRunOutputLength = 65535
+	// maxCheckRunAnnotationsPerRequest is the maximum number of annotations
+	// GitHub accepts in a single check run update.
+	maxCheckRunAnnotationsPerRequest = 50
+	tableEnd                         = "</table>"
+)
 
 func (v *Provider) getExistingCheckRunID(ctx context.Context, runevent *info.Event, status provider.StatusOpts) (*int64, error) {
-	opt := github.ListOptions{PerPage: v.paginedNumber}
-	for {
+	var checkRunID *int64
+	_, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*github.CheckRun, int, error) {
 		res, resp, err := v.Client.Checks.ListCheckRunsForRef(ctx, runevent.Organization, runevent.Repository,
 			runevent.SHA, &github.ListCheckRunsOptions{
 				AppID:       v.ApplicationID,
-				ListOptions: opt,
+				ListOptions: github.ListOptions{PerPage: v.paginedNumber, Page: page},
 			})
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		for _, checkrun := range res.CheckRuns {
 			// if it is a Pending approval CheckRun then overwrite it
 			if isPendingApprovalCheckrun(checkrun) || isFailedCheckrun(checkrun) {
 				if v.canIUseCheckrunID(checkrun.ID) {
-					return checkrun.ID, nil
+					checkRunID = checkrun.ID
+					return nil, 0, nil
 				}
 			}
-			if *checkrun.ExternalID == status.PipelineRunName {
-				return checkrun.ID, nil
+			// the check runs created before the run IDs have the name of
+			// their PipelineRun as external ID
+			if externalID := checkrun.GetExternalID(); externalID == provider.GetExternalID(status) || externalID == status.PipelineRunName {
+				checkRunID = checkrun.ID
+				return nil, 0, nil
 			}
 		}
-		if resp.NextPage == 0 {
-			break
-		}
-		opt.Page = resp.NextPage
+		return nil, nextPage(resp), nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, nil
+	return checkRunID, nil
 }
 
 func isPendingApprovalCheckrun(run *github.CheckRun) bool {
@@ -114,11 +102,11 @@ func (v *Provider) canIUseCheckrunID(checkrunid *int64) bool {
 func (v *Provider) createCheckRunStatus(ctx context.Context, runevent *info.Event, status provider.StatusOpts) (*int64, error) {
 	now := github.Timestamp{Time: time.Now()}
 	checkrunoption := github.CreateCheckRunOptions{
-		Name:       getCheckName(status, v.pacInfo),
+		Name:       provider.GetCheckName(status, v.pacInfo, v.repo, runevent),
 		HeadSHA:    runevent.SHA,
 		Status:     github.String("in_progress"),
 		DetailsURL: github.String(status.DetailsURL),
-		ExternalID: github.String(status.PipelineRunName),
+		ExternalID: github.String(provider.GetExternalID(status)),
 		StartedAt:  &now,
 	}
 
@@ -239,27 +227,32 @@ func (v *Provider) getOrUpdateCheckRunStatus(ctx context.Context, runevent *info
 		}
 	}
 
-	text := statusOpts.Text
+	summary := truncateCheckRunOutput(statusOpts.Summary, statusOpts.DetailsURL)
 	checkRunOutput := &github.CheckRunOutput{
 		Title:   &statusOpts.Title,
-		Summary: &statusOpts.Summary,
+		Summary: &summary,
 	}
 
+	annotations := []*github.CheckRunAnnotation{}
 	if statusOpts.PipelineRun != nil {
 		if pacopts.ErrorDetection {
-			checkRunOutput.Annotations = v.getFailuresMessageAsAnnotations(ctx, statusOpts.PipelineRun, pacopts)
+			annotations = v.getFailuresMessageAsAnnotations(ctx, statusOpts.PipelineRun, pacopts)
 		}
 	}
+	annotationPages := paginateAnnotations(annotations)
+	if len(annotationPages) > 0 {
+		checkRunOutput.Annotations = annotationPages[0]
+	}
 
-	checkRunOutput.Text = github.String(text)
+	checkRunOutput.Text = github.String(truncateCheckRunOutput(statusOpts.Text, statusOpts.DetailsURL))
 
 	opts := github.UpdateCheckRunOptions{
-		Name:   getCheckName(statusOpts, pacopts),
+		Name:   provider.GetCheckName(statusOpts, pacopts, v.repo, runevent),
 		Status: github.String(statusOpts.Status),
 		Output: checkRunOutput,
 	}
 	if statusOpts.PipelineRunName != "" {
-		opts.ExternalID = github.String(statusOpts.PipelineRunName)
+		opts.ExternalID = github.String(provider.GetExternalID(statusOpts))
 	}
 	if statusOpts.DetailsURL != "" {
 		opts.DetailsURL = &statusOpts.DetailsURL
@@ -274,8 +267,65 @@ func (v *Provider) getOrUpdateCheckRunStatus(ctx context.Context, runevent *info
 		opts.Conclusion = github.String("cancelled")
 	}
 
-	_, _, err = v.Client.Checks.UpdateCheckRun(ctx, runevent.Organization, runevent.Repository, *checkRunID, opts)
-	return err
+	if _, _, err = v.Client.Checks.UpdateCheckRun(ctx, runevent.Organization, runevent.Repository, *checkRunID, opts); err != nil {
+		return err
+	}
+
+	// GitHub only accepts a limited number of annotations per request, the
+	// next ones get appended to the check run with subsequent updates.
+	for _, page := range annotationPages[min(1, len(annotationPages)):] {
+		opts.Output = &github.CheckRunOutput{
+			Title:       checkRunOutput.Title,
+			Summary:     checkRunOutput.Summary,
+			Text:        checkRunOutput.Text,
+			Annotations: page,
+		}
+		if _, _, err = v.Client.Checks.UpdateCheckRun(ctx, runevent.Organization, runevent.Repository, *checkRunID, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncateCheckRunOutput makes sure the summary or the text of a check run
+// output fits in the GitHub limit. When it doesn't, it is cut at the last row
+// of the task status table (or the last line) that fits and a link to the full
+// report on the console is added.
+func truncateCheckRunOutput(text, detailsURL string) string {
+	if len(text) <= maxCheckRunOutputLength {
+		return text
+	}
+	notice := "\n\n:warning: This report has been truncated as it is over the size limit of GitHub"
+	if detailsURL != "" {
+		notice += fmt.Sprintf(", see the [full report](%s)", detailsURL)
+	}
+	notice += "."
+
+	truncated := text[:maxCheckRunOutputLength-len(notice)-len(tableEnd)]
+	if i := strings.LastIndex(truncated, "</tr>"); i != -1 {
+		truncated = truncated[:i+len("</tr>")]
+	} else if i := strings.LastIndex(truncated, "\n"); i != -1 {
+		truncated = truncated[:i]
+	}
+	// close the task status table if we have cut it
+	if strings.Count(truncated, "<table>") > strings.Count(truncated, tableEnd) {
+		truncated += tableEnd
+	}
+	return truncated + notice
+}
+
+// paginateAnnotations splits the annotations in pages of the maximum number
+// of annotations GitHub accepts in a single check run update.
+func paginateAnnotations(annotations []*github.CheckRunAnnotation) [][]*github.CheckRunAnnotation {
+	pages := [][]*github.CheckRunAnnotation{}
+	for len(annotations) > maxCheckRunAnnotationsPerRequest {
+		pages = append(pages, annotations[:maxCheckRunAnnotationsPerRequest])
+		annotations = annotations[maxCheckRunAnnotationsPerRequest:]
+	}
+	if len(annotations) > 0 {
+		pages = append(pages, annotations)
+	}
+	return pages
 }
 
 func isPipelineRunCancelledOrStopped(run *tektonv1.PipelineRun) bool {
@@ -323,13 +373,13 @@ func (v *Provider) createStatusCommit(ctx context.Context, runevent *info.Event,
 		State:       github.String(status.Conclusion),
 		TargetURL:   github.String(status.DetailsURL),
 		Description: github.String(status.Title),
-		Context:     github.String(getCheckName(status, v.pacInfo)),
+		Context:     github.String(provider.GetCheckName(status, v.pacInfo, v.repo, runevent)),
 		CreatedAt:   &github.Timestamp{Time: now},
 	}
 
 	if _, _, err := v.Client.Repositories.CreateStatus(ctx,
 		runevent.Organization, runevent.Repository, runevent.SHA, ghstatus); err != nil {
-		return err
+		return v.tokenPermissionError(err, "statuses:write")
 	}
 	if (status.Status == "completed" || (status.Status == "queued" && status.Title == "Pending approval")) && status.Text != "" && runevent.EventType == triggertype.PullRequest.String() {
 		_, _, err = v.Client.Issues.CreateComment(ctx, runevent.Organization, runevent.Repository,
@@ -339,7 +389,7 @@ func (v *Provider) createStatusCommit(ctx context.Context, runevent *info.Event,
 			},
 		)
 		if err != nil {
-			return err
+			return v.tokenPermissionError(err, "pull_requests:write")
 		}
 	}
 
@@ -347,6 +397,9 @@ func (v *Provider) createStatusCommit(ctx context.Context, runevent *info.Event,
 }
 
 func (v *Provider) CreateStatus(ctx context.Context, runevent *info.Event, statusOpts provider.StatusOpts) error {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationStatus)
+	defer done()
+
 	if v.Client == nil {
 		return fmt.Errorf("cannot set status on github no token or url set")
 	}
@@ -368,8 +421,12 @@ func (v *Provider) CreateStatus(ctx context.Context, runevent *info.Event, statu
 			statusOpts.Summary = "is waiting for approval."
 		}
 	case "neutral":
-		statusOpts.Title = "Unknown"
-		statusOpts.Summary = "doesn't know what happened with this commit."
+		if statusOpts.Title == "" {
+			statusOpts.Title = "Unknown"
+			statusOpts.Summary = "doesn't know what happened with this commit."
+		} else {
+			statusOpts.Summary = "is skipping this commit."
+		}
 	}
 
 	if statusOpts.Status == "in_progress" {
@@ -381,7 +438,7 @@ func (v *Provider) CreateStatus(ctx context.Context, runevent *info.Event, statu
 	if statusOpts.OriginalPipelineRunName != "" {
 		onPr = "/" + statusOpts.OriginalPipelineRunName
 	}
-	statusOpts.Summary = fmt.Sprintf("%s%s %s", v.pacInfo.ApplicationName, onPr, statusOpts.Summary)
+	statusOpts.Summary = fmt.Sprintf("%s%s %s", provider.GetApplicationName(v.pacInfo, v.repo), onPr, statusOpts.Summary)
 
 	// If we have an installationID which mean we have a github apps and we can use the checkRun API
 	if runevent.InstallationID > 0 {
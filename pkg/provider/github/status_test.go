@@ -561,56 +561,6 @@ func TestGithubProvidercreateStatusCommit(t *testing.T) {
 	}
 }
 
-func TestGetCheckName(t *testing.T) {
-	type args struct {
-		status  provider.StatusOpts
-		pacopts *info.PacOpts
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
-		{
-			name: "no application name",
-			args: args{
-				status: provider.StatusOpts{
-					OriginalPipelineRunName: "HELLO",
-				},
-				pacopts: &info.PacOpts{Settings: settings.Settings{ApplicationName: ""}},
-			},
-			want: "HELLO",
-		},
-		{
-			name: "application and pipelinerun name",
-			args: args{
-				status: provider.StatusOpts{
-					OriginalPipelineRunName: "MOTO",
-				},
-				pacopts: &info.PacOpts{Settings: settings.Settings{ApplicationName: "HELLO"}},
-			},
-			want: "HELLO / MOTO",
-		},
-		{
-			name: "application no pipelinerun name",
-			args: args{
-				status: provider.StatusOpts{
-					OriginalPipelineRunName: "",
-				},
-				pacopts: &info.PacOpts{Settings: settings.Settings{ApplicationName: "PAC"}},
-			},
-			want: "PAC",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := getCheckName(tt.args.status, tt.args.pacopts); got != tt.want {
-				t.Errorf("getCheckName() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestProviderGetExistingCheckRunID(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -618,6 +568,7 @@ func TestProviderGetExistingCheckRunID(t *testing.T) {
 		expectedID *int64
 		wantErr    bool
 		prname     string
+		runID      string
 	}{
 		{
 			name: "has check runs",
@@ -633,6 +584,40 @@ func TestProviderGetExistingCheckRunID(t *testing.T) {
 			expectedID: github.Int64(55555),
 			prname:     "blahpr",
 		},
+		{
+			name: "has check runs with the run id",
+			jsonret: `{
+			"total_count": 2,
+			"check_runs": [
+				{
+					"id": 44444,
+					"external_id": "otherpr"
+				},
+				{
+					"id": 55555,
+					"external_id": "8b2f6c1e-run-id"
+				}
+			]
+		}`,
+			expectedID: github.Int64(55555),
+			prname:     "blahpr",
+			runID:      "8b2f6c1e-run-id",
+		},
+		{
+			name: "has check runs created before the run id",
+			jsonret: `{
+			"total_count": 1,
+			"check_runs": [
+				{
+					"id": 55555,
+					"external_id": "blahpr"
+				}
+			]
+		}`,
+			expectedID: github.Int64(55555),
+			prname:     "blahpr",
+			runID:      "8b2f6c1e-run-id",
+		},
 		{
 			name:       "no check runs",
 			jsonret:    `{"total_count": 0,"check_runs": []}`,
@@ -664,6 +649,7 @@ func TestProviderGetExistingCheckRunID(t *testing.T) {
 
 			got, err := v.getExistingCheckRunID(ctx, event, provider.StatusOpts{
 				PipelineRunName: tt.prname,
+				RunID:           tt.runID,
 			})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getExistingCheckRunID() error = %v, wantErr %v", err, tt.wantErr)
@@ -675,3 +661,32 @@ func TestProviderGetExistingCheckRunID(t *testing.T) {
 		})
 	}
 }
+
+func TestTruncateCheckRunOutput(t *testing.T) {
+	assert.Equal(t, truncateCheckRunOutput("small", "https://console"), "small")
+
+	row := "<tr>\n<td>✅ Succeeded</td>\n<td>1 minute</td><td>\n\ntask\n\n</td></tr>"
+	text := "<table>\n  <tr><th>Status</th><th>Duration</th><th>Name</th></tr>\n" +
+		strings.Repeat(row+"\n", maxCheckRunOutputLength/len(row)+10) + "</table>"
+	got := truncateCheckRunOutput(text, "https://console/pr")
+	assert.Assert(t, len(got) <= maxCheckRunOutputLength)
+	assert.Assert(t, strings.Contains(got, "</td></tr></table>"))
+	assert.Assert(t, strings.HasSuffix(got, "see the [full report](https://console/pr)."))
+
+	got = truncateCheckRunOutput(strings.Repeat("line\n", maxCheckRunOutputLength), "")
+	assert.Assert(t, len(got) <= maxCheckRunOutputLength)
+	assert.Assert(t, strings.HasSuffix(got, "line\n\n:warning: This report has been truncated as it is over the size limit of GitHub."))
+}
+
+func TestPaginateAnnotations(t *testing.T) {
+	annotations := []*github.CheckRunAnnotation{}
+	for i := 0; i < 120; i++ {
+		annotations = append(annotations, &github.CheckRunAnnotation{Message: github.String(strconv.Itoa(i))})
+	}
+	pages := paginateAnnotations(annotations)
+	assert.Equal(t, len(pages), 3)
+	assert.Equal(t, len(pages[0]), 50)
+	assert.Equal(t, len(pages[2]), 20)
+	assert.Equal(t, *pages[2][19].Message, "119")
+	assert.Equal(t, len(paginateAnnotations(nil)), 0)
+}
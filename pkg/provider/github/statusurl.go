@@ -0,0 +1,24 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+var _ provider.StatusURLInterface = (*Provider)(nil)
+
+// StatusURL returns the URL of the check run of the PipelineRun, the commit
+// statuses of the webhooks have no page of their own.
+func (v *Provider) StatusURL(_ context.Context, event *info.Event, pr *tektonv1.PipelineRun) (string, error) {
+	checkRunID, ok := pr.GetAnnotations()[keys.CheckRunID]
+	if !ok {
+		return "", nil
+	}
+	return fmt.Sprintf("%s/runs/%s", strings.TrimSuffix(event.URL, "/"), checkRunID), nil
+}
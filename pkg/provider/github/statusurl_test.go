@@ -0,0 +1,27 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestStatusURL(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	v := &Provider{}
+	event := &info.Event{URL: "https://github.com/owner/repo"}
+
+	pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{keys.CheckRunID: "1234"}}}
+	got, err := v.StatusURL(ctx, event, pr)
+	assert.NilError(t, err)
+	assert.Equal(t, got, "https://github.com/owner/repo/runs/1234")
+
+	got, err = v.StatusURL(ctx, event, &tektonv1.PipelineRun{})
+	assert.NilError(t, err)
+	assert.Equal(t, got, "")
+}
@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// maxPullRequestFiles is the maximum number of files GitHub lists for a pull
+// request, the list of a larger pull request is truncated.
+const maxPullRequestFiles = 3000
+
+var commitSHARe = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// isCommitSHA returns true if the revision is a commit SHA, the files of a
+// branch may change and can't be cached.
+func isCommitSHA(revision string) bool {
+	return commitSHARe.MatchString(revision)
+}
+
+type pullRequestChanges struct {
+	sha       string
+	mergeBase string
+	files     changedfiles.ChangedFiles
+}
+
+func (v *Provider) tektonDirCacheKey(runevent *info.Event, path string) string {
+	apiURL := ""
+	if v.APIURL != nil {
+		apiURL = *v.APIURL
+	}
+	return provider.TektonDirCacheKey(apiURL, runevent.Organization+"/"+runevent.Repository, path,
+		strings.Join(provider.PipelineRunExtensions(v.repo), ","))
+}
+
+// getPullRequestChanges gets the merge base and the changed files of the pull
+// request, only once for all the PipelineRun directories.
+func (v *Provider) getPullRequestChanges(ctx context.Context, runevent *info.Event) (*pullRequestChanges, error) {
+	if v.pullRequestChanges != nil && v.pullRequestChanges.sha == runevent.SHA {
+		return v.pullRequestChanges, nil
+	}
+	mergeBase, err := v.getMergeBase(ctx, runevent)
+	if err != nil {
+		return nil, err
+	}
+	files, err := v.GetFiles(ctx, runevent)
+	if err != nil {
+		return nil, err
+	}
+	v.pullRequestChanges = &pullRequestChanges{sha: runevent.SHA, mergeBase: mergeBase, files: files}
+	return v.pullRequestChanges, nil
+}
+
+// getChangedTektonDir gets the PipelineRuns of the directory on a pull request
+// from the cached files of its merge base, only the files changed by the pull
+// request are fetched. It returns false when the files of the merge base are
+// not cached or when the changes can't be applied to them, the whole
+// directory needs to be fetched then.
+func (v *Provider) getChangedTektonDir(ctx context.Context, runevent *info.Event, path string) (string, bool, error) {
+	if runevent.TriggerTarget != triggertype.PullRequest || runevent.BaseBranch == "" {
+		return "", false, nil
+	}
+	key := v.tektonDirCacheKey(runevent, path)
+	if !v.tektonDirs.Has(key) {
+		return "", false, nil
+	}
+	changes, err := v.getPullRequestChanges(ctx, runevent)
+	if err != nil {
+		v.Logger.Debugf("cannot get the changes of pull request %d, fetching the whole %s directory: %v", runevent.PullRequestNumber, path, err)
+		return "", false, nil
+	}
+	files, ok := v.tektonDirs.Get(key, changes.mergeBase)
+	if !ok {
+		return "", false, nil
+	}
+	// the previous name of a renamed file is not known and the list of a
+	// large pull request is truncated
+	if len(changes.files.Renamed) > 0 || len(changes.files.All) >= maxPullRequestFiles {
+		return "", false, nil
+	}
+
+	prefix := strings.Trim(path, "/") + "/"
+	fetched := 0
+	for _, fname := range changes.files.All {
+		if !strings.HasPrefix(fname, prefix) {
+			continue
+		}
+		relpath := strings.TrimPrefix(fname, prefix)
+		switch {
+		case slices.Contains(changes.files.Deleted, fname):
+			delete(files, relpath)
+		case slices.Contains(changes.files.Added, fname), slices.Contains(changes.files.Modified, fname):
+			if !provider.IsPipelineRunFile(v.repo, relpath) {
+				continue
+			}
+			data, err := v.getFileContent(ctx, runevent, fname)
+			if err != nil {
+				v.Logger.Debugf("cannot get %s, fetching the whole %s directory: %v", fname, path, err)
+				return "", false, nil
+			}
+			// validate yaml
+			var i any
+			if err := yaml.Unmarshal(data, &i); err != nil {
+				return "", false, fmt.Errorf("error unmarshalling yaml file %s: %w", relpath, err)
+			}
+			files[relpath] = string(data)
+			fetched++
+		default:
+			// copied or changed files, fetch everything to be safe
+			return "", false, nil
+		}
+	}
+
+	v.Logger.Infof("Using the cached PipelineRun definition of merge base %s, fetched the %d files changed in %s", changes.mergeBase, fetched, path)
+	if isCommitSHA(runevent.SHA) {
+		v.tektonDirs.Set(key, runevent.SHA, files)
+	}
+	return concatAllYamlFiles(files), true, nil
+}
+
+// getFileContent gets the content of a file at the SHA of the event.
+func (v *Provider) getFileContent(ctx context.Context, runevent *info.Event, fname string) ([]byte, error) {
+	fp, objects, _, err := v.Client.Repositories.GetContents(ctx, runevent.Organization,
+		runevent.Repository, fname, &github.RepositoryContentGetOptions{Ref: runevent.SHA})
+	if err != nil {
+		return nil, err
+	}
+	if objects != nil {
+		return nil, fmt.Errorf("%s is a directory", fname)
+	}
+	// the content of the files larger than 1MB is not returned
+	if fp.Content == nil || fp.GetEncoding() == "none" {
+		return v.getObject(ctx, fp.GetSHA(), runevent)
+	}
+	content, err := fp.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
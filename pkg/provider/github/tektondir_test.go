@@ -0,0 +1,146 @@
+package github
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestGetTektonDirChangedFiles(t *testing.T) {
+	mergeBase := strings.Repeat("a", 40)
+	headSHA := strings.Repeat("b", 40)
+	cached := map[string]string{
+		"pull-request.yaml": "name: pull-request",
+		"push.yaml":         "name: push",
+		"deleted.yaml":      "name: deleted",
+	}
+
+	tests := []struct {
+		name        string
+		files       string
+		cached      bool
+		wantFetched bool
+		want        []string
+		wantErr     string
+	}{
+		{
+			name: "only the changed files are fetched",
+			files: `[{"filename": ".tekton/pull-request.yaml", "status": "modified"},
+				{"filename": ".tekton/added.yaml", "status": "added"},
+				{"filename": ".tekton/deleted.yaml", "status": "removed"},
+				{"filename": ".tekton/README.md", "status": "added"},
+				{"filename": "main.go", "status": "modified"}]`,
+			cached: true,
+			want:   []string{"name: added", "name: pull-request-changed", "name: push"},
+		},
+		{
+			name:        "merge base not cached",
+			files:       `[{"filename": "main.go", "status": "modified"}]`,
+			wantFetched: true,
+			want:        []string{"name: from-tree"},
+		},
+		{
+			name:        "renamed files fetch the whole directory",
+			files:       `[{"filename": ".tekton/renamed.yaml", "status": "renamed", "previous_filename": ".tekton/push.yaml"}]`,
+			cached:      true,
+			wantFetched: true,
+			want:        []string{"name: from-tree"},
+		},
+		{
+			name:    "changed file with a bad yaml",
+			files:   `[{"filename": ".tekton/bad.yaml", "status": "added"}]`,
+			cached:  true,
+			wantErr: "error unmarshalling yaml file bad.yaml: error converting YAML to JSON: yaml: line 1: did not find expected ',' or '}'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+			logger, _ := logger.GetLogger()
+
+			cache := provider.NewTektonDirCache(clockwork.NewFakeClock(), provider.TektonDirCacheTTL, provider.TektonDirCacheMaxCommits)
+			gvcs := &Provider{
+				Client:        fakeclient,
+				Logger:        logger,
+				APIURL:        github.String("https://api.github.com"),
+				paginedNumber: defaultPaginedNumber,
+				tektonDirs:    cache,
+			}
+			event := &info.Event{
+				Organization:      "owner",
+				Repository:        "repo",
+				SHA:               headSHA,
+				BaseBranch:        "main",
+				PullRequestNumber: 1,
+				TriggerTarget:     triggertype.PullRequest,
+			}
+			key := gvcs.tektonDirCacheKey(event, ".tekton")
+			if tt.cached {
+				cache.Set(key, mergeBase, cached)
+			} else {
+				cache.Set(key, strings.Repeat("c", 40), cached)
+			}
+
+			mux.HandleFunc("/repos/owner/repo/compare/main..."+headSHA, func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprintf(w, `{"merge_base_commit": {"sha": "%s"}}`, mergeBase)
+			})
+			mux.HandleFunc("/repos/owner/repo/pulls/1/files", func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(w, tt.files)
+			})
+			contents := map[string]string{
+				"pull-request.yaml": "name: pull-request-changed",
+				"added.yaml":        "name: added",
+				"bad.yaml":          "{bad",
+			}
+			for fname, content := range contents {
+				content := content
+				mux.HandleFunc("/repos/owner/repo/contents/.tekton/"+fname, func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, r.URL.Query().Get("ref"), headSHA)
+					fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": "%s"}`, base64.StdEncoding.EncodeToString([]byte(content)))
+				})
+			}
+			fetched := false
+			mux.HandleFunc("/repos/owner/repo/git/trees/"+headSHA, func(w http.ResponseWriter, _ *http.Request) {
+				fetched = true
+				fmt.Fprint(w, `{"tree": [{"path": ".tekton", "type": "tree", "sha": "tektonsha"}]}`)
+			})
+			mux.HandleFunc("/repos/owner/repo/git/trees/tektonsha", func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(w, `{"tree": [{"path": "pr.yaml", "type": "blob", "sha": "prsha"}]}`)
+			})
+			mux.HandleFunc("/repos/owner/repo/git/blobs/prsha", func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprintf(w, `{"sha": "prsha", "content": "%s"}`, base64.StdEncoding.EncodeToString([]byte("name: from-tree")))
+			})
+
+			got, err := gvcs.GetTektonDir(ctx, event, ".tekton", "source")
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, fetched, tt.wantFetched)
+			for _, want := range tt.want {
+				assert.Assert(t, strings.Contains(got, want), "%s not in %s", want, got)
+			}
+			assert.Equal(t, strings.Count(got, "name:"), len(tt.want), got)
+
+			// the files of the head commit are cached for the next events
+			files, ok := cache.Get(key, headSHA)
+			assert.Assert(t, ok)
+			assert.Equal(t, len(files), len(tt.want))
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v61/github"
+	"go.uber.org/zap"
+)
+
+const (
+	TokenTypeClassic     = "classic personal access token"
+	TokenTypeFineGrained = "fine-grained personal access token"
+	TokenTypeOAuth       = "OAuth token"
+	TokenTypeApp         = "GitHub App token"
+	TokenTypeUnknown     = "unknown token"
+
+	// acceptedPermissionsHeader is returned by GitHub on a denied request
+	// made with a fine-grained token, it lists the permissions the request
+	// needed, ie: "statuses=write".
+	acceptedPermissionsHeader = "X-Accepted-GitHub-Permissions"
+	oauthScopesHeader         = "X-OAuth-Scopes"
+)
+
+// TokenType detects the type of a GitHub token from its prefix.
+func TokenType(token string) string {
+	switch {
+	case strings.HasPrefix(token, "github_pat_"):
+		return TokenTypeFineGrained
+	case strings.HasPrefix(token, "ghp_"):
+		return TokenTypeClassic
+	case strings.HasPrefix(token, "gho_"), strings.HasPrefix(token, "ghu_"):
+		return TokenTypeOAuth
+	case strings.HasPrefix(token, "ghs_"):
+		return TokenTypeApp
+	}
+	return TokenTypeUnknown
+}
+
+// TokenReport is the result of the check of a token against a repository.
+type TokenReport struct {
+	Type string
+	// Scopes are the OAuth scopes of a classic or an OAuth token.
+	Scopes []string
+	// Missing are the scopes or the permissions the token lacks.
+	Missing []string
+	// Unverified are the permissions that cannot be checked without writing
+	// to the repository.
+	Unverified []string
+}
+
+// tokenProbe is a read only request done with a fine-grained token to find
+// out if it has a permission.
+type tokenProbe struct {
+	permission string
+	probe      func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error)
+}
+
+var fineGrainedProbes = []tokenProbe{
+	{"metadata:read", func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+		_, resp, err := client.Repositories.Get(ctx, owner, repo)
+		return resp, err
+	}},
+	{"contents:read", func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+		_, resp, err := client.Repositories.ListCommits(ctx, owner, repo, &github.CommitsListOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		return resp, err
+	}},
+	{"pull_requests:read", func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+		_, resp, err := client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		return resp, err
+	}},
+	{"statuses:read", func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+		_, resp, err := client.Repositories.ListStatuses(ctx, owner, repo, "HEAD", &github.ListOptions{PerPage: 1})
+		return resp, err
+	}},
+}
+
+// CheckToken checks the token used in webhook mode has the scopes, or the
+// permissions for a fine-grained token, needed by Pipelines-as-Code on the
+// owner/repo repository.
+func CheckToken(ctx context.Context, client *github.Client, token, owner, repo string) (*TokenReport, error) {
+	report := &TokenReport{Type: TokenType(token)}
+
+	if report.Type == TokenTypeFineGrained {
+		for _, p := range fineGrainedProbes {
+			resp, err := p.probe(ctx, client, owner, repo)
+			if err == nil {
+				continue
+			}
+			// a repository the token can't see is not found, it is not a
+			// missing permission
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil, fmt.Errorf("the repository %s/%s is not found or not accessible with the token: %w", owner, repo, err)
+			}
+			if resp == nil || resp.StatusCode != http.StatusForbidden {
+				return nil, err
+			}
+			report.Missing = append(report.Missing, p.permission)
+		}
+		report.Unverified = []string{"statuses:write", "pull_requests:write"}
+		return report, nil
+	}
+
+	_, resp, err := client.Users.Get(ctx, "")
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("the token is invalid or has expired")
+		}
+		return nil, err
+	}
+	header := resp.Header.Get(oauthScopesHeader)
+	if header == "" {
+		return report, nil
+	}
+	hasRepo := false
+	for _, scope := range strings.Split(header, ",") {
+		scope = strings.TrimSpace(scope)
+		report.Scopes = append(report.Scopes, scope)
+		if scope == "repo" || scope == "public_repo" {
+			hasRepo = true
+		}
+	}
+	if !hasRepo {
+		report.Missing = append(report.Missing, "repo")
+	}
+	return report, nil
+}
+
+// tokenPermissionError adds to an error denied by GitHub the permission or
+// the scope the token needs and reports it on the Repository.
+func (v *Provider) tokenPermissionError(err error, permission string) error {
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response == nil || ghErr.Response.StatusCode != http.StatusForbidden {
+		return err
+	}
+	token := ""
+	if v.Token != nil {
+		token = *v.Token
+	}
+	tokenType := TokenType(token)
+	var advice string
+	switch tokenType {
+	case TokenTypeFineGrained:
+		if accepted := ghErr.Response.Header.Get(acceptedPermissionsHeader); accepted != "" {
+			permission = strings.ReplaceAll(accepted, "=", ":")
+		}
+		advice = fmt.Sprintf("the %s needs the %s permission", tokenType, permission)
+	case TokenTypeApp:
+		return err
+	default:
+		advice = fmt.Sprintf("the %s needs the repo scope (or public_repo for a public repository)", tokenType)
+	}
+	if v.eventEmitter != nil {
+		v.eventEmitter.EmitMessage(v.repo, zap.ErrorLevel, "RepositoryTokenPermissions", fmt.Sprintf("%s: %s", advice, err.Error()))
+	}
+	return fmt.Errorf("%s: %w", advice, err)
+}
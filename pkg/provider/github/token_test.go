@@ -0,0 +1,199 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	ghtesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/github"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestTokenType(t *testing.T) {
+	assert.Equal(t, TokenType("github_pat_11AAAA"), TokenTypeFineGrained)
+	assert.Equal(t, TokenType("ghp_AAAA"), TokenTypeClassic)
+	assert.Equal(t, TokenType("gho_AAAA"), TokenTypeOAuth)
+	assert.Equal(t, TokenType("ghs_AAAA"), TokenTypeApp)
+	assert.Equal(t, TokenType("0123456789abcdef"), TokenTypeUnknown)
+}
+
+func TestCheckToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		scopes         string
+		deniedPaths    []string
+		wantScopes     []string
+		wantMissing    []string
+		wantUnverified []string
+		wantErr        string
+		unauthorized   bool
+		notFoundPaths  []string
+	}{
+		{
+			name:       "classic token with repo scope",
+			token:      "ghp_token",
+			scopes:     "repo, read:org",
+			wantScopes: []string{"repo", "read:org"},
+		},
+		{
+			name:        "classic token without repo scope",
+			token:       "ghp_token",
+			scopes:      "read:org",
+			wantScopes:  []string{"read:org"},
+			wantMissing: []string{"repo"},
+		},
+		{
+			name:         "invalid token",
+			token:        "ghp_token",
+			unauthorized: true,
+			wantErr:      "the token is invalid or has expired",
+		},
+		{
+			name:           "fine-grained token with all permissions",
+			token:          "github_pat_token",
+			wantUnverified: []string{"statuses:write", "pull_requests:write"},
+		},
+		{
+			name:           "fine-grained token missing permissions",
+			token:          "github_pat_token",
+			deniedPaths:    []string{"/repos/owner/repo/pulls", "/repos/owner/repo/commits/HEAD/statuses"},
+			wantMissing:    []string{"pull_requests:read", "statuses:read"},
+			wantUnverified: []string{"statuses:write", "pull_requests:write"},
+		},
+		{
+			name:          "fine-grained token without access to the repository",
+			token:         "github_pat_token",
+			notFoundPaths: []string{"/repos/owner/repo"},
+			wantErr:       "the repository owner/repo is not found or not accessible with the token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+
+			mux.HandleFunc("/user", func(w http.ResponseWriter, _ *http.Request) {
+				if tt.unauthorized {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if tt.scopes != "" {
+					w.Header().Set("X-OAuth-Scopes", tt.scopes)
+				}
+				fmt.Fprint(w, `{"login": "user"}`)
+			})
+			for _, path := range []string{"/repos/owner/repo", "/repos/owner/repo/commits", "/repos/owner/repo/pulls", "/repos/owner/repo/commits/HEAD/statuses"} {
+				path := path
+				mux.HandleFunc(path, func(w http.ResponseWriter, _ *http.Request) {
+					for _, denied := range tt.deniedPaths {
+						if denied == path {
+							w.WriteHeader(http.StatusForbidden)
+							return
+						}
+					}
+					for _, notFound := range tt.notFoundPaths {
+						if notFound == path {
+							w.WriteHeader(http.StatusNotFound)
+							return
+						}
+					}
+					if path == "/repos/owner/repo" {
+						fmt.Fprint(w, `{}`)
+						return
+					}
+					fmt.Fprint(w, `[]`)
+				})
+			}
+
+			report, err := CheckToken(ctx, fakeclient, tt.token, "owner", "repo")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, report.Type, TokenType(tt.token))
+			assert.DeepEqual(t, report.Scopes, tt.wantScopes)
+			assert.DeepEqual(t, report.Missing, tt.wantMissing)
+			assert.DeepEqual(t, report.Unverified, tt.wantUnverified)
+		})
+	}
+}
+
+func TestCreateStatusTokenPermissionError(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		accepted string
+		wantErr  string
+	}{
+		{
+			name:     "fine-grained token with accepted permissions",
+			token:    "github_pat_token",
+			accepted: "statuses=write",
+			wantErr:  "the fine-grained personal access token needs the statuses:write permission",
+		},
+		{
+			name:    "fine-grained token without accepted permissions",
+			token:   "github_pat_token",
+			wantErr: "the fine-grained personal access token needs the statuses:write permission",
+		},
+		{
+			name:    "classic token",
+			token:   "ghp_token",
+			wantErr: "the classic personal access token needs the repo scope (or public_repo for a public repository)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+			defer teardown()
+
+			mux.HandleFunc("/repos/owner/repo/statuses/sha", func(w http.ResponseWriter, _ *http.Request) {
+				if tt.accepted != "" {
+					w.Header().Set("X-Accepted-GitHub-Permissions", tt.accepted)
+				}
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "Resource not accessible by personal access token"}`)
+			})
+
+			v := &Provider{
+				Client:  fakeclient,
+				Token:   github.String(tt.token),
+				pacInfo: &info.PacOpts{},
+			}
+			event := &info.Event{Organization: "owner", Repository: "repo", SHA: "sha"}
+			err := v.CreateStatus(ctx, event, provider.StatusOpts{Conclusion: "success"})
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.ErrorContains(t, err, "403")
+		})
+	}
+}
+
+func TestCreateStatusNotFoundIsNotAPermissionError(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	fakeclient, mux, _, teardown := ghtesthelper.SetupGH()
+	defer teardown()
+
+	mux.HandleFunc("/repos/owner/repo/statuses/sha", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+
+	v := &Provider{
+		Client:  fakeclient,
+		Token:   github.String("github_pat_token"),
+		pacInfo: &info.PacOpts{},
+	}
+	event := &info.Event{Organization: "owner", Repository: "repo", SHA: "sha"}
+	err := v.CreateStatus(ctx, event, provider.StatusOpts{Conclusion: "success"})
+	assert.ErrorContains(t, err, "404")
+	assert.Assert(t, !strings.Contains(err.Error(), "needs the"), err.Error())
+}
@@ -3,22 +3,27 @@ package gitlab
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/opscomments"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/policy"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	"github.com/xanzy/go-gitlab"
 )
 
 // IsAllowedOwnersFile get the owner files (OWNERS, OWNERS_ALIASES) from main branch
 // and check if we have explicitly allowed the user in there.
-func (v *Provider) IsAllowedOwnersFile(_ context.Context, event *info.Event) (bool, error) {
-	ownerContent, _ := v.getObject("OWNERS", event.DefaultBranch, v.targetProjectID)
+func (v *Provider) IsAllowedOwnersFile(ctx context.Context, event *info.Event) (bool, error) {
+	ownerContent, _ := v.getObject(ctx, "OWNERS", event.DefaultBranch, v.targetProjectID)
 	if string(ownerContent) == "" {
 		return false, nil
 	}
 	// OWNERS_ALIASES file existence is not required, if we get "not found" continue
-	ownerAliasesContent, err := v.getObject("OWNERS_ALIASES", event.DefaultBranch, v.targetProjectID)
+	ownerAliasesContent, err := v.getObject(ctx, "OWNERS_ALIASES", event.DefaultBranch, v.targetProjectID)
 	if !strings.Contains(err.Error(), "not found") {
 		return false, err
 	}
@@ -26,9 +31,19 @@ func (v *Provider) IsAllowedOwnersFile(_ context.Context, event *info.Event) (bo
 	return allowed, nil
 }
 
+// checkMembership checks the user has at least the collaborator role on the
+// project, or is allowed by the OWNERS file.
 func (v *Provider) checkMembership(ctx context.Context, event *info.Event, userid int) bool {
-	member, _, err := v.Client.ProjectMembers.GetInheritedProjectMember(v.targetProjectID, userid)
-	if err == nil && member.ID == userid {
+	key := acl.MembershipKey(v.apiURL, "project-collaborator", strconv.Itoa(v.targetProjectID), strconv.Itoa(userid))
+	isMember, ok := v.memberships.Get(key)
+	if !ok {
+		role, err := v.memberRole(userid)
+		isMember = err == nil && role.AtLeast(info.RoleCollaborator)
+		if err == nil {
+			v.memberships.Set(key, isMember)
+		}
+	}
+	if isMember {
 		return true
 	}
 
@@ -36,39 +51,40 @@ func (v *Provider) checkMembership(ctx context.Context, event *info.Event, useri
 	return isAllowed
 }
 
-func (v *Provider) checkOkToTestCommentFromApprovedMember(ctx context.Context, event *info.Event, page int) (bool, error) {
-	var nextPage int
-	opt := &gitlab.ListMergeRequestDiscussionsOptions{Page: page}
-	discussions, resp, err := v.Client.Discussions.ListMergeRequestDiscussions(v.targetProjectID, event.PullRequestNumber, opt)
-	if err != nil {
-		return false, err
-	}
-	if resp.NextPage != 0 {
-		nextPage = resp.NextPage
-	}
-
-	for _, comment := range discussions {
-		// TODO: maybe we do threads in the future but for now we just check the top thread for ops related comments
-		topthread := comment.Notes[0]
-		if acl.MatchRegexp(acl.OKToTestCommentRegexp, topthread.Body) {
-			commenterEvent := info.NewEvent()
-			commenterEvent.Event = event.Event
-			commenterEvent.Sender = topthread.Author.Username
-			commenterEvent.BaseBranch = event.BaseBranch
-			commenterEvent.HeadBranch = event.HeadBranch
-			commenterEvent.DefaultBranch = event.DefaultBranch
-			// TODO: we could probably do with caching when checking all issues?
-			if v.checkMembership(ctx, commenterEvent, topthread.Author.ID) {
-				return true, nil
+func (v *Provider) checkOkToTestCommentFromApprovedMember(ctx context.Context, event *info.Event) (bool, error) {
+	allowed := false
+	_, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*gitlab.Discussion, int, error) {
+		discussions, resp, err := v.Client.Discussions.ListMergeRequestDiscussions(v.targetProjectID, event.PullRequestNumber,
+			&gitlab.ListMergeRequestDiscussionsOptions{Page: max(page, 1)})
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, comment := range discussions {
+			if len(comment.Notes) == 0 {
+				continue
+			}
+			// TODO: maybe we do threads in the future but for now we just check the top thread for ops related comments
+			topthread := comment.Notes[0]
+			if acl.MatchRegexp(acl.OKToTestCommentRegexp, topthread.Body) {
+				commenterEvent := info.NewEvent()
+				commenterEvent.Event = event.Event
+				commenterEvent.Sender = topthread.Author.Username
+				commenterEvent.BaseBranch = event.BaseBranch
+				commenterEvent.HeadBranch = event.HeadBranch
+				commenterEvent.DefaultBranch = event.DefaultBranch
+				// TODO: we could probably do with caching when checking all issues?
+				if v.checkMembership(ctx, commenterEvent, topthread.Author.ID) {
+					allowed = true
+					return nil, 0, nil
+				}
 			}
 		}
+		return nil, nextPage(resp), nil
+	})
+	if err != nil {
+		return false, err
 	}
-
-	if nextPage != 0 {
-		return v.checkOkToTestCommentFromApprovedMember(ctx, event, nextPage)
-	}
-
-	return false, nil
+	return allowed, nil
 }
 
 func (v *Provider) IsAllowed(ctx context.Context, event *info.Event) (bool, error) {
@@ -76,9 +92,64 @@ func (v *Provider) IsAllowed(ctx context.Context, event *info.Event) (bool, erro
 		return false, fmt.Errorf("no github client has been initialized, " +
 			"exiting... (hint: did you forget setting a secret on your repo?)")
 	}
+
+	aclPolicy := policy.Policy{
+		Repository:   v.repo,
+		EventEmitter: v.eventEmitter,
+		Event:        event,
+		VCX:          v,
+		Logger:       v.Logger,
+		// the teams of GitLab are groups, not resolved
+		IgnoreTeams: true,
+	}
+	policyAllowed, policyReason := aclPolicy.IsAllowed(ctx, policyTriggerType(event))
+	switch policyAllowed {
+	case policy.ResultAllowed:
+		return true, nil
+	case policy.ResultDisallowed:
+		return false, nil
+	case policy.ResultNotSet: // this is to make golangci-lint happy
+	}
+
 	if v.checkMembership(ctx, event, v.userID) {
 		return true, nil
 	}
 
-	return v.checkOkToTestCommentFromApprovedMember(ctx, event, 1)
+	// a comment on a commit has no merge request to be given an /ok-to-test on
+	if opscomments.IsCommitComment(event) {
+		return false, nil
+	}
+
+	allowed, err := v.checkOkToTestCommentFromApprovedMember(ctx, event)
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	// error with the policy reason if it was set
+	if policyReason != "" {
+		return false, fmt.Errorf(policyReason)
+	}
+	return false, nil
+}
+
+// policyTriggerType returns the trigger type of the event the policies apply
+// to, the GitOps comments on a merge request are told apart by their command.
+func policyTriggerType(event *info.Event) triggertype.Trigger {
+	switch event.Event.(type) {
+	case *gitlab.MergeEvent:
+		return triggertype.PullRequest
+	case *gitlab.MergeCommentEvent, *gitlab.CommitCommentEvent:
+		switch opscomments.CommentEventType(event.TriggerComment) {
+		case opscomments.RetestAllCommentEventType, opscomments.RetestSingleCommentEventType,
+			opscomments.TestAllCommentEventType, opscomments.TestSingleCommentEventType:
+			return triggertype.Retest
+		case opscomments.OkToTestCommentEventType:
+			return triggertype.OkToTest
+		case opscomments.CancelCommentAllEventType, opscomments.CancelCommentSingleEventType:
+			return triggertype.Cancel
+		default:
+			return triggertype.Comment
+		}
+	}
+	return triggertype.Push
 }
@@ -1,10 +1,17 @@
 package gitlab
 
 import (
+	"fmt"
+	"net/http"
 	"testing"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	thelp "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab/test"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/xanzy/go-gitlab"
+	"go.uber.org/zap"
 	rtesting "knative.dev/pkg/reconciler/testing"
 )
 
@@ -29,6 +36,8 @@ func TestIsAllowed(t *testing.T) {
 		commentContent  string
 		commentAuthor   string
 		commentAuthorID int
+		policy          *v1alpha1.Policy
+		accessLevel     int
 	}{
 		{
 			name:    "check client has been set",
@@ -89,6 +98,61 @@ func TestIsAllowed(t *testing.T) {
 			commentContent: "/ok-to-test",
 			commentAuthor:  "notallowed",
 		},
+		{
+			name:       "allowed by the role of the policy",
+			allowed:    true,
+			wantClient: true,
+			fields: fields{
+				userID:          123,
+				targetProjectID: 2525,
+			},
+			args: args{
+				event: &info.Event{Sender: "maintainer", Event: &gitlab.MergeEvent{}},
+			},
+			policy:      &v1alpha1.Policy{PullRequest: []string{"role:collaborator"}},
+			accessLevel: 40,
+		},
+		{
+			name:       "disallowed by the role of the policy",
+			wantClient: true,
+			fields: fields{
+				userID:          123,
+				targetProjectID: 2525,
+			},
+			args: args{
+				event: &info.Event{Sender: "reporter", Event: &gitlab.MergeEvent{}},
+			},
+			policy:      &v1alpha1.Policy{PullRequest: []string{"role:collaborator"}},
+			accessLevel: 20,
+		},
+		{
+			name:       "team policy ignored for a developer",
+			allowed:    true,
+			wantClient: true,
+			fields: fields{
+				userID:          123,
+				targetProjectID: 2525,
+			},
+			args: args{
+				event: &info.Event{Sender: "developer", Event: &gitlab.MergeEvent{}},
+			},
+			policy:      &v1alpha1.Policy{PullRequest: []string{"ci-users"}},
+			accessLevel: 30,
+		},
+		{
+			name:       "reporter is not a member allowed to run the CI",
+			wantClient: true,
+			fields: fields{
+				userID:          123,
+				targetProjectID: 2525,
+			},
+			args: args{
+				event: &info.Event{Sender: "reporter", PullRequestNumber: 1, Event: &gitlab.MergeEvent{}},
+			},
+			accessLevel:    20,
+			commentContent: "hello",
+			commentAuthor:  "reporter",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -99,12 +163,24 @@ func TestIsAllowed(t *testing.T) {
 				sourceProjectID: tt.fields.sourceProjectID,
 				userID:          tt.fields.userID,
 			}
+			if tt.policy != nil {
+				stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+				logger := zap.NewNop().Sugar()
+				v.Logger = logger
+				v.eventEmitter = events.NewEventEmitter(stdata.Kube, logger)
+				v.repo = &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{Policy: tt.policy}}}
+			}
 			if tt.wantClient {
 				client, mux, tearDown := thelp.Setup(t)
 				v.Client = client
 				if tt.allowMemberID != 0 {
 					thelp.MuxAllowUserID(mux, tt.fields.targetProjectID, tt.allowMemberID)
 				}
+				if tt.accessLevel != 0 {
+					mux.HandleFunc(fmt.Sprintf("/projects/%d/members/all/%d", tt.fields.targetProjectID, tt.fields.userID), func(rw http.ResponseWriter, _ *http.Request) {
+						fmt.Fprintf(rw, `{"id": %d, "access_level": %d}`, tt.fields.userID, tt.accessLevel)
+					})
+				}
 				if tt.ownerFile != "" {
 					thelp.MuxGetFile(mux, tt.fields.targetProjectID, "OWNERS", tt.ownerFile)
 				}
@@ -45,6 +45,11 @@ func (v *Provider) Detect(req *http.Request, payload string, logger *zap.Sugared
 		if provider.Valid(gitEvent.ObjectAttributes.Action, []string{"open", "reopen"}) {
 			return setLoggerAndProceed(true, "", nil)
 		}
+		// the closed merge requests are only used to deactivate their preview
+		// environment
+		if provider.Valid(gitEvent.ObjectAttributes.Action, []string{"close", "merge"}) && v.pacInfo != nil && v.pacInfo.PreviewEnvironments {
+			return setLoggerAndProceed(true, "", nil)
+		}
 		return setLoggerAndProceed(false, fmt.Sprintf("not a merge event we care about: \"%s\"",
 			gitEvent.ObjectAttributes.Action), nil)
 	case *gitlab.PushEvent, *gitlab.TagEvent:
@@ -54,6 +59,12 @@ func (v *Provider) Detect(req *http.Request, payload string, logger *zap.Sugared
 			return setLoggerAndProceed(true, "", nil)
 		}
 		return setLoggerAndProceed(false, "comments on closed merge requests is not supported", nil)
+	case *gitlab.CommitCommentEvent:
+		comment := gitEvent.ObjectAttributes.Note
+		if provider.IsTestRetestComment(comment) || provider.IsCancelComment(comment) {
+			return setLoggerAndProceed(true, "", nil)
+		}
+		return setLoggerAndProceed(false, "not a gitops comment on a commit", nil)
 	default:
 		return setLoggerAndProceed(false, "", fmt.Errorf("gitlab: event \"%s\" is not supported", event))
 	}
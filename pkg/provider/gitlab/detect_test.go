@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
 	thelp "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab/test"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
 	"github.com/xanzy/go-gitlab"
@@ -35,6 +37,8 @@ func TestProvider_Detect(t *testing.T) {
 		event         string
 		eventType     gitlab.EventType
 		wantReason    string
+		// previewEnvironments enables the preview-environments setting
+		previewEnvironments bool
 	}{
 		{
 			name:       "bad/not a gitlab Event",
@@ -63,6 +67,36 @@ func TestProvider_Detect(t *testing.T) {
 			isGL:       true,
 			processReq: false,
 		},
+		{
+			name:                "good/mergeRequest close Event",
+			event:               sample.MREventAsJSON("close", ""),
+			eventType:           gitlab.EventTypeMergeRequest,
+			isGL:                true,
+			processReq:          true,
+			previewEnvironments: true,
+		},
+		{
+			name:       "bad/mergeRequest close Event without preview environments",
+			event:      sample.MREventAsJSON("close", ""),
+			eventType:  gitlab.EventTypeMergeRequest,
+			isGL:       true,
+			processReq: false,
+		},
+		{
+			name:                "good/mergeRequest merge Event",
+			event:               sample.MREventAsJSON("merge", ""),
+			eventType:           gitlab.EventTypeMergeRequest,
+			isGL:                true,
+			processReq:          true,
+			previewEnvironments: true,
+		},
+		{
+			name:       "bad/mergeRequest merge Event without preview environments",
+			event:      sample.MREventAsJSON("merge", ""),
+			eventType:  gitlab.EventTypeMergeRequest,
+			isGL:       true,
+			processReq: false,
+		},
 		{
 			name:       "good/mergeRequest update Event with commit",
 			event:      sample.MREventAsJSON("update", `"oldrev": "123"`),
@@ -112,6 +146,21 @@ func TestProvider_Detect(t *testing.T) {
 			isGL:       true,
 			processReq: true,
 		},
+		{
+			name:       "good/commit comment Event with retest",
+			event:      sample.CommitNoteEventAsJSON("/retest branch:nightly"),
+			eventType:  gitlab.EventTypeNote,
+			isGL:       true,
+			processReq: true,
+		},
+		{
+			name:       "bad/commit comment Event without a gitops command",
+			event:      sample.CommitNoteEventAsJSON("looks good"),
+			eventType:  gitlab.EventTypeNote,
+			isGL:       true,
+			processReq: false,
+			wantReason: "not a gitops comment on a commit",
+		},
 		{
 			name:       "good/issue comment Event with cancel a pr",
 			event:      sample.NoteEventAsJSON("/cancel dummy"),
@@ -137,7 +186,7 @@ func TestProvider_Detect(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gprovider := Provider{}
+			gprovider := Provider{pacInfo: &info.PacOpts{Settings: settings.Settings{PreviewEnvironments: tt.previewEnvironments}}}
 			logger, _ := logger.GetLogger()
 
 			header := http.Header{}
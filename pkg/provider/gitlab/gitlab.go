@@ -7,8 +7,11 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/acl"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/changedfiles"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
@@ -19,29 +22,17 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	"github.com/xanzy/go-gitlab"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
 	"gopkg.in/yaml.v2"
 )
 
 const (
-	apiPublicURL       = "https://gitlab.com"
-	taskStatusTemplate = `
-<table>
-  <tr><th>Status</th><th>Duration</th><th>Name</th></tr>
-
-{{- range $taskrun := .TaskRunList }}
-<tr>
-<td>{{ formatCondition $taskrun.PipelineRunTaskRunStatus.Status.Conditions }}</td>
-<td>{{ formatDuration $taskrun.PipelineRunTaskRunStatus.Status.StartTime $taskrun.PipelineRunTaskRunStatus.Status.CompletionTime }}</td><td>
-
-{{ $taskrun.ConsoleLogURL }}
-
-</td></tr>
-{{- end }}
-</table>`
+	apiPublicURL   = "https://gitlab.com"
 	noClientErrStr = `no gitlab client has been initialized, exiting... (hint: did you forget setting a secret on your repo?)`
 )
 
 var _ provider.Interface = (*Provider)(nil)
+var _ provider.RevisionFileInterface = (*Provider)(nil)
 
 type Provider struct {
 	Client            *gitlab.Client
@@ -55,8 +46,16 @@ type Provider struct {
 	pathWithNamespace string
 	repoURL           string
 	apiURL            string
+	mergeBaseSHA      string
+	repo              *v1alpha1.Repository
+	eventEmitter      *events.EventEmitter
+	memberships       *acl.MembershipCache
 }
 
+// memberships is shared by the providers of all the events, the membership
+// lookups of the comments on a merge request are then done only once.
+var memberships = acl.NewMembershipCache(clockwork.NewRealClock(), acl.MembershipCacheTTL, acl.MembershipNegativeCacheTTL)
+
 func (v *Provider) SetPacInfo(pacInfo *info.PacOpts) {
 	v.pacInfo = pacInfo
 }
@@ -67,8 +66,10 @@ func (v *Provider) GetTaskURI(_ context.Context, _ *info.Event, _ string) (bool,
 }
 
 // CheckPolicyAllowing TODO: Implement ME.
-func (v *Provider) CheckPolicyAllowing(_ context.Context, _ *info.Event, _ []string) (bool, string) {
-	return false, ""
+// CheckPolicyAllowing disallows the teams of the policies, only their roles
+// are supported on GitLab.
+func (v *Provider) CheckPolicyAllowing(_ context.Context, _ *info.Event, allowedTeams []string) (bool, string) {
+	return false, fmt.Sprintf("the teams %v of the policy are not supported on GitLab, only the roles", allowedTeams)
 }
 
 func (v *Provider) SetLogger(logger *zap.SugaredLogger) {
@@ -103,14 +104,15 @@ func getOrgRepo(pathWithNamespace string) (string, string) {
 
 func (v *Provider) GetConfig() *info.ProviderConfig {
 	return &info.ProviderConfig{
-		TaskStatusTMPL: taskStatusTemplate,
-		APIURL:         apiPublicURL,
-		Name:           "gitlab",
+		APIURL: apiPublicURL,
+		Name:   "gitlab",
 	}
 }
 
-func (v *Provider) SetClient(_ context.Context, run *params.Run, runevent *info.Event, _ *v1alpha1.Repository, _ *events.EventEmitter) error {
+func (v *Provider) SetClient(ctx context.Context, run *params.Run, runevent *info.Event, repo *v1alpha1.Repository, eventEmitter *events.EventEmitter) error {
 	var err error
+	v.repo = repo
+	v.eventEmitter = eventEmitter
 	if runevent.Provider.Token == "" {
 		return fmt.Errorf("no git_provider.secret has been set in the repo crd")
 	}
@@ -134,8 +136,9 @@ func (v *Provider) SetClient(_ context.Context, run *params.Run, runevent *info.
 		apiURL = apiPublicURL
 	}
 	v.apiURL = apiURL
+	v.memberships = memberships
 
-	v.Client, err = gitlab.NewClient(runevent.Provider.Token, gitlab.WithBaseURL(apiURL))
+	v.Client, err = gitlab.NewClient(runevent.Provider.Token, gitlab.WithBaseURL(apiURL), gitlab.WithHTTPClient(provider.HTTPClient(ctx, run, v.GetConfig().Name)))
 	if err != nil {
 		return err
 	}
@@ -161,8 +164,11 @@ func (v *Provider) SetClient(_ context.Context, run *params.Run, runevent *info.
 	return nil
 }
 
-func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts provider.StatusOpts,
+func (v *Provider) CreateStatus(ctx context.Context, event *info.Event, statusOpts provider.StatusOpts,
 ) error {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationStatus)
+	defer done()
+
 	var detailsURL string
 	if v.Client == nil {
 		return fmt.Errorf("no gitlab client has been initialized, " +
@@ -191,12 +197,9 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts
 		detailsURL = statusOpts.DetailsURL
 	}
 
-	onPr := ""
-	if statusOpts.OriginalPipelineRunName != "" {
-		onPr = "/" + statusOpts.OriginalPipelineRunName
-	}
-	body := fmt.Sprintf("**%s%s** has %s\n\n%s\n\n<small>Full log available [here](%s)</small>",
-		v.pacInfo.ApplicationName, onPr, statusOpts.Title, statusOpts.Text, detailsURL)
+	checkName := provider.GetCheckName(statusOpts, v.pacInfo, v.repo, event)
+	body := fmt.Sprintf("**%s** has %s\n\n%s\n\n<small>Full log available [here](%s)</small>",
+		checkName, statusOpts.Title, statusOpts.Text, detailsURL)
 
 	// in case we have access set the commit status, typically on MR from
 	// another users we won't have it but it would work on push or MR from a
@@ -205,62 +208,145 @@ func (v *Provider) CreateStatus(_ context.Context, event *info.Event, statusOpts
 	// if we have an error fallback to send a issue comment
 	opt := &gitlab.SetCommitStatusOptions{
 		State:       gitlab.BuildStateValue(statusOpts.Conclusion),
-		Name:        gitlab.Ptr(v.pacInfo.ApplicationName),
+		Name:        gitlab.Ptr(checkName),
 		TargetURL:   gitlab.Ptr(detailsURL),
 		Description: gitlab.Ptr(statusOpts.Title),
 	}
 	//nolint: dogsled
-	_, _, _ = v.Client.Commits.SetCommitStatus(event.SourceProjectID, event.SHA, opt)
+	_, _, _ = v.Client.Commits.SetCommitStatus(event.SourceProjectID, event.SHA, opt, gitlab.WithContext(ctx))
 
 	// only add a note when we are on a MR
 	if event.EventType == triggertype.PullRequest.String() ||
 		event.EventType == "Merge_Request" || event.EventType == "Merge Request" ||
 		opscomments.IsAnyOpsEventType(event.EventType) {
+		if provider.UpdateStatusComment(v.repo) {
+			return v.createOrUpdateStatusNote(ctx, event, statusOpts.OriginalPipelineRunName, body)
+		}
 		mopt := &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.Ptr(body)}
-		_, _, err := v.Client.Notes.CreateMergeRequestNote(event.TargetProjectID, event.PullRequestNumber, mopt)
+		_, _, err := v.Client.Notes.CreateMergeRequestNote(event.TargetProjectID, event.PullRequestNumber, mopt, gitlab.WithContext(ctx))
 		return err
 	}
 	return nil
 }
 
-func (v *Provider) GetTektonDir(_ context.Context, event *info.Event, path, provenance string) (string, error) {
+// createOrUpdateStatusNote updates the note we have previously posted for the
+// PipelineRun on this SHA, with the previous statuses kept as history, or
+// creates it if there is none yet.
+func (v *Provider) createOrUpdateStatusNote(ctx context.Context, event *info.Event, prName, body string) error {
+	marker := provider.StatusCommentMarker(prName, event.SHA)
+	note, err := v.findStatusNote(ctx, event, marker)
+	if err != nil {
+		return err
+	}
+	if note != nil {
+		uopt := &gitlab.UpdateMergeRequestNoteOptions{Body: gitlab.Ptr(provider.StatusCommentBody(marker, body, note.Body))}
+		_, _, err := v.Client.Notes.UpdateMergeRequestNote(event.TargetProjectID, event.PullRequestNumber, note.ID, uopt, gitlab.WithContext(ctx))
+		return err
+	}
+
+	mopt := &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.Ptr(provider.StatusCommentBody(marker, body, ""))}
+	_, _, err = v.Client.Notes.CreateMergeRequestNote(event.TargetProjectID, event.PullRequestNumber, mopt, gitlab.WithContext(ctx))
+	return err
+}
+
+// findStatusNote returns the note starting with the marker on the merge
+// request, nil if there is none.
+func (v *Provider) findStatusNote(ctx context.Context, event *info.Event, marker string) (*gitlab.Note, error) {
+	notes, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*gitlab.Note, int, error) {
+		notes, resp, err := v.Client.Notes.ListMergeRequestNotes(event.TargetProjectID, event.PullRequestNumber,
+			&gitlab.ListMergeRequestNotesOptions{ListOptions: gitlab.ListOptions{PerPage: 100, Page: page}}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, 0, err
+		}
+		// stop at the note we have posted
+		for _, note := range notes {
+			if strings.HasPrefix(note.Body, marker) {
+				return []*gitlab.Note{note}, 0, nil
+			}
+		}
+		return nil, nextPage(resp), nil
+	})
+	if err != nil || len(notes) == 0 {
+		return nil, err
+	}
+	return notes[0], nil
+}
+
+func (v *Provider) GetTektonDir(ctx context.Context, event *info.Event, path, provenance string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+
 	if v.Client == nil {
 		return "", fmt.Errorf("no gitlab client has been initialized, " +
 			"exiting... (hint: did you forget setting a secret on your repo?)")
 	}
 	// default set provenance from head
 	revision := event.HeadBranch
-	if provenance == "default_branch" {
+	projectID := v.sourceProjectID
+	switch provenance {
+	case "default_branch":
 		revision = event.DefaultBranch
 		v.Logger.Infof("Using PipelineRun definition from default_branch: %s", event.DefaultBranch)
-	} else {
+	case "merge_base":
+		mergeBase, err := v.getMergeBase(ctx, event)
+		if err != nil {
+			return "", err
+		}
+		// the merge base is a commit of the target project
+		v.mergeBaseSHA = mergeBase
+		revision = mergeBase
+		projectID = v.targetProjectID
+		v.Logger.Infof("Using PipelineRun definition from merge base of %s and %s: %s", event.BaseBranch, event.SHA, mergeBase)
+	default:
 		v.Logger.Infof("Using PipelineRun definition from source merge request SHA: %s", event.SHA)
 	}
 
-	opt := &gitlab.ListTreeOptions{
-		Path:      gitlab.Ptr(path),
-		Ref:       gitlab.Ptr(revision),
-		Recursive: gitlab.Ptr(true),
-	}
-
-	objects, resp, err := v.Client.Repositories.ListTree(v.sourceProjectID, opt)
-	if resp != nil && resp.Response.StatusCode == http.StatusNotFound {
+	notFound := false
+	objects, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*gitlab.TreeNode, int, error) {
+		objects, resp, err := v.Client.Repositories.ListTree(projectID, &gitlab.ListTreeOptions{
+			ListOptions: gitlab.ListOptions{PerPage: 100, Page: page},
+			Path:        gitlab.Ptr(path),
+			Ref:         gitlab.Ptr(revision),
+			Recursive:   gitlab.Ptr(true),
+		}, gitlab.WithContext(ctx))
+		if resp != nil && resp.Response.StatusCode == http.StatusNotFound {
+			notFound = true
+			return nil, 0, nil
+		}
+		return objects, nextPage(resp), err
+	})
+	if notFound {
 		return "", nil
 	}
 	if err != nil {
 		return "", fmt.Errorf("failed to list %s dir: %w", path, err)
 	}
 
-	return v.concatAllYamlFiles(objects, event)
+	return v.concatAllYamlFiles(ctx, objects, revision, projectID)
+}
+
+// getMergeBase returns the merge base between the target branch and the SHA
+// of the merge request, on other events there is no merge base and the SHA is
+// returned.
+func (v *Provider) getMergeBase(ctx context.Context, event *info.Event) (string, error) {
+	if event.TriggerTarget != triggertype.PullRequest || event.BaseBranch == "" {
+		return event.SHA, nil
+	}
+	commit, _, err := v.Client.Repositories.MergeBase(v.targetProjectID, &gitlab.MergeBaseOptions{
+		Ref: &[]string{event.BaseBranch, event.SHA},
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("cannot get the merge base of %s and %s: %w", event.BaseBranch, event.SHA, err)
+	}
+	return commit.ID, nil
 }
 
 // concatAllYamlFiles concat all yaml files from a directory as one big multi document yaml string.
-func (v *Provider) concatAllYamlFiles(objects []*gitlab.TreeNode, runevent *info.Event) (string, error) {
+func (v *Provider) concatAllYamlFiles(ctx context.Context, objects []*gitlab.TreeNode, revision string, projectID int) (string, error) {
 	var allTemplates string
 	for _, value := range objects {
-		if strings.HasSuffix(value.Name, ".yaml") ||
-			strings.HasSuffix(value.Name, ".yml") {
-			data, err := v.getObject(value.Path, runevent.HeadBranch, v.sourceProjectID)
+		if provider.IsPipelineRunFile(v.repo, value.Name) {
+			data, err := v.getObject(ctx, value.Path, revision, projectID)
 			if err != nil {
 				return "", err
 			}
@@ -279,11 +365,11 @@ func (v *Provider) concatAllYamlFiles(objects []*gitlab.TreeNode, runevent *info
 	return allTemplates, nil
 }
 
-func (v *Provider) getObject(fname, branch string, pid int) ([]byte, error) {
+func (v *Provider) getObject(ctx context.Context, fname, branch string, pid int) ([]byte, error) {
 	opt := &gitlab.GetRawFileOptions{
 		Ref: gitlab.Ptr(branch),
 	}
-	file, resp, err := v.Client.RepositoryFiles.GetRawFile(pid, fname, opt)
+	file, resp, err := v.Client.RepositoryFiles.GetRawFile(pid, fname, opt, gitlab.WithContext(ctx))
 	if err != nil {
 		return []byte{}, fmt.Errorf("failed to get filename from api %s dir: %w", fname, err)
 	}
@@ -293,15 +379,35 @@ func (v *Provider) getObject(fname, branch string, pid int) ([]byte, error) {
 	return file, nil
 }
 
-func (v *Provider) GetFileInsideRepo(_ context.Context, runevent *info.Event, path, _ string) (string, error) {
-	getobj, err := v.getObject(path, runevent.HeadBranch, v.sourceProjectID)
+func (v *Provider) GetFileInsideRepo(ctx context.Context, runevent *info.Event, path, _ string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+
+	revision, projectID := runevent.HeadBranch, v.sourceProjectID
+	if v.mergeBaseSHA != "" {
+		revision, projectID = v.mergeBaseSHA, v.targetProjectID
+	}
+	getobj, err := v.getObject(ctx, path, revision, projectID)
 	if err != nil {
 		return "", err
 	}
 	return string(getobj), nil
 }
 
-func (v *Provider) GetCommitInfo(_ context.Context, runevent *info.Event) error {
+// GetFileAtRevision returns the content of a file of the source project of the
+// event at a revision.
+func (v *Provider) GetFileAtRevision(ctx context.Context, _ *info.Event, path, revision string) (string, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationFiles)
+	defer done()
+
+	getobj, err := v.getObject(ctx, path, revision, v.sourceProjectID)
+	if err != nil {
+		return "", err
+	}
+	return string(getobj), nil
+}
+
+func (v *Provider) GetCommitInfo(ctx context.Context, runevent *info.Event) error {
 	if v.Client == nil {
 		return fmt.Errorf(noClientErrStr)
 	}
@@ -315,20 +421,97 @@ func (v *Provider) GetCommitInfo(_ context.Context, runevent *info.Event) error
 		}
 		runevent.SHA = branchinfo.ID
 		runevent.SHATitle = branchinfo.Title
+		runevent.SHAMessage = branchinfo.Message
 		runevent.SHAURL = branchinfo.WebURL
 	}
 
+	// a GitOps comment on a commit only runs the PipelineRuns of a branch
+	// when the commit is the head of that branch.
+	if opscomments.IsCommitComment(runevent) {
+		branch, _, err := v.Client.Branches.GetBranch(v.sourceProjectID, runevent.HeadBranch)
+		if err != nil {
+			return err
+		}
+		if branch.Commit == nil || branch.Commit.ID != runevent.SHA {
+			return fmt.Errorf("provided branch %s does not contain sha %s", runevent.HeadBranch, runevent.SHA)
+		}
+	}
+
+	if runevent.SHA != "" {
+		signature, err := v.commitSignature(ctx, runevent.SHA)
+		if err != nil {
+			return err
+		}
+		runevent.SHASignature = signature
+	}
 	return nil
 }
 
-func (v *Provider) GetFiles(_ context.Context, runevent *info.Event) (changedfiles.ChangedFiles, error) {
+// commitSignatureReply is the signature of a commit answered by GitLab, the
+// fields of the key depend on the signature type: GPG, SSH or X.509.
+type commitSignatureReply struct {
+	SignatureType      string `json:"signature_type"`
+	VerificationStatus string `json:"verification_status"`
+	GPGKeyPrimaryKeyID string `json:"gpg_key_primary_keyid"`
+	Key                *struct {
+		Key string `json:"key"`
+	} `json:"key"`
+	X509Certificate *struct {
+		SubjectKeyIdentifier string `json:"subject_key_identifier"`
+	} `json:"x509_certificate"`
+}
+
+// verifiedSignatureStatuses are the verification statuses of GitLab of a
+// signature verified with a key of the user, by GitLab itself or by a trusted
+// certificate authority.
+var verifiedSignatureStatuses = []string{"verified", "verified_system", "verified_ca"}
+
+// commitSignature returns the verification of the signature of a commit, of
+// any type. The key ID is the long ID of a GPG key, the SHA256 fingerprint of
+// an SSH key or the subject key identifier of an X.509 certificate. GitLab
+// answers with a not found when the commit is not signed.
+func (v *Provider) commitSignature(ctx context.Context, sha string) (info.CommitSignature, error) {
+	req, err := v.Client.NewRequest(http.MethodGet,
+		fmt.Sprintf("projects/%d/repository/commits/%s/signature", v.sourceProjectID, url.PathEscape(sha)), nil,
+		[]gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return info.CommitSignature{}, err
+	}
+	signature := &commitSignatureReply{}
+	resp, err := v.Client.Do(req, signature)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return info.CommitSignature{Status: info.SignatureUnsigned}, nil
+	}
+	if err != nil {
+		return info.CommitSignature{}, err
+	}
+	status := info.SignatureUnverified
+	if slices.Contains(verifiedSignatureStatuses, signature.VerificationStatus) {
+		status = info.SignatureVerified
+	}
+	keyID := signature.GPGKeyPrimaryKeyID
+	switch {
+	case signature.Key != nil:
+		if key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(signature.Key.Key)); err == nil {
+			keyID = ssh.FingerprintSHA256(key)
+		}
+	case signature.X509Certificate != nil:
+		keyID = signature.X509Certificate.SubjectKeyIdentifier
+	}
+	return info.CommitSignature{Status: status, KeyID: keyID}, nil
+}
+
+func (v *Provider) GetFiles(ctx context.Context, runevent *info.Event) (changedfiles.ChangedFiles, error) {
+	ctx, done := provider.WithBudget(ctx, v.pacInfo, v.GetConfig().Name, provider.OperationDiff)
+	defer done()
+
 	if v.Client == nil {
 		return changedfiles.ChangedFiles{}, fmt.Errorf("no gitlab client has been initialized, " +
 			"exiting... (hint: did you forget setting a secret on your repo?)")
 	}
 	if runevent.TriggerTarget == triggertype.PullRequest {
 		//nolint: staticcheck
-		mrchanges, _, err := v.Client.MergeRequests.GetMergeRequestChanges(v.sourceProjectID, runevent.PullRequestNumber, &gitlab.GetMergeRequestChangesOptions{})
+		mrchanges, _, err := v.Client.MergeRequests.GetMergeRequestChanges(v.sourceProjectID, runevent.PullRequestNumber, &gitlab.GetMergeRequestChangesOptions{}, gitlab.WithContext(ctx))
 		if err != nil {
 			return changedfiles.ChangedFiles{}, err
 		}
@@ -353,7 +536,11 @@ func (v *Provider) GetFiles(_ context.Context, runevent *info.Event) (changedfil
 	}
 
 	if runevent.TriggerTarget == "push" {
-		pushChanges, _, err := v.Client.Commits.GetCommitDiff(v.sourceProjectID, runevent.SHA, &gitlab.GetCommitDiffOptions{})
+		pushChanges, err := provider.Paginate(ctx, provider.DefaultMaxPages, func(page int) ([]*gitlab.Diff, int, error) {
+			diffs, resp, err := v.Client.Commits.GetCommitDiff(v.sourceProjectID, runevent.SHA,
+				&gitlab.GetCommitDiffOptions{ListOptions: gitlab.ListOptions{PerPage: 100, Page: page}}, gitlab.WithContext(ctx))
+			return diffs, nextPage(resp), err
+		})
 		if err != nil {
 			return changedfiles.ChangedFiles{}, err
 		}
@@ -381,3 +568,11 @@ func (v *Provider) GetFiles(_ context.Context, runevent *info.Event) (changedfil
 func (v *Provider) CreateToken(_ context.Context, _ []string, _ *info.Event) (string, error) {
 	return "", nil
 }
+
+// nextPage returns the next page of a paginated response, 0 on the last page.
+func nextPage(resp *gitlab.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.NextPage
+}
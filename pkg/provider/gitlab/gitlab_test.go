@@ -9,9 +9,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	thelp "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab/test"
 	"github.com/xanzy/go-gitlab"
@@ -196,6 +198,123 @@ func TestCreateStatus(t *testing.T) {
 	}
 }
 
+func TestCreateStatusUpdateComment(t *testing.T) {
+	marker := provider.StatusCommentMarker("pr", "sha")
+	tests := []struct {
+		name        string
+		notes       string
+		wantUpdate  bool
+		wantHistory string
+	}{
+		{
+			name:  "create note when there is none",
+			notes: `[{"id": 1, "body": "hello"}]`,
+		},
+		{
+			name:        "update existing note",
+			notes:       fmt.Sprintf(`[{"id": 1, "body": "hello"}, {"id": 2, "body": %q}]`, marker+"\n**Pipelines as Code CI/pr** has failed"),
+			wantUpdate:  true,
+			wantHistory: "- **Pipelines as Code CI/pr** has failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			client, mux, tearDown := thelp.Setup(t)
+			defer tearDown()
+			v := &Provider{
+				Client: client,
+				run:    params.New(),
+				pacInfo: &info.PacOpts{
+					Settings: settings.Settings{
+						ApplicationName: settings.PACApplicationNameDefaultValue,
+					},
+				},
+				repo: &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{
+					Settings: &v1alpha1.Settings{CommentStrategy: provider.CommentStrategyUpdate},
+				}},
+			}
+			event := &info.Event{
+				TriggerTarget:     triggertype.PullRequest,
+				EventType:         triggertype.PullRequest.String(),
+				PullRequestNumber: 666,
+				TargetProjectID:   10,
+				SHA:               "sha",
+			}
+
+			updated, created := false, false
+			mux.HandleFunc("/projects/10/merge_requests/666/notes", func(rw http.ResponseWriter, r *http.Request) {
+				body := struct {
+					Body string `json:"body"`
+				}{}
+				switch r.Method {
+				case http.MethodGet:
+					fmt.Fprint(rw, tt.notes)
+				case http.MethodPost:
+					created = true
+					assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+					assert.Assert(t, strings.HasPrefix(body.Body, marker))
+					fmt.Fprint(rw, `{}`)
+				}
+			})
+			mux.HandleFunc("/projects/10/merge_requests/666/notes/2", func(rw http.ResponseWriter, r *http.Request) {
+				updated = true
+				body := struct {
+					Body string `json:"body"`
+				}{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Assert(t, strings.HasPrefix(body.Body, marker))
+				assert.Assert(t, strings.Contains(body.Body, "has completed"))
+				assert.Assert(t, strings.Contains(body.Body, tt.wantHistory))
+				fmt.Fprint(rw, `{}`)
+			})
+
+			err := v.CreateStatus(ctx, event, provider.StatusOpts{Conclusion: "completed", OriginalPipelineRunName: "pr"})
+			assert.NilError(t, err)
+			assert.Equal(t, updated, tt.wantUpdate)
+			assert.Equal(t, created, !tt.wantUpdate)
+		})
+	}
+}
+
+func TestCreateStatusCheckName(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	client, mux, tearDown := thelp.Setup(t)
+	defer tearDown()
+	v := &Provider{
+		Client: client,
+		run:    params.New(),
+		pacInfo: &info.PacOpts{
+			Settings: settings.Settings{
+				ApplicationName: settings.PACApplicationNameDefaultValue,
+			},
+		},
+	}
+	event := &info.Event{
+		TriggerTarget:     triggertype.PullRequest,
+		EventType:         triggertype.PullRequest.String(),
+		PullRequestNumber: 666,
+		SourceProjectID:   10,
+		TargetProjectID:   10,
+		SHA:               "sha",
+	}
+	wantName := "Pipelines as Code CI / 1. build / pr"
+	statusName := ""
+	mux.HandleFunc("/projects/10/statuses/sha", func(rw http.ResponseWriter, r *http.Request) {
+		body := struct {
+			Name string `json:"name"`
+		}{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+		statusName = body.Name
+		fmt.Fprint(rw, `{}`)
+	})
+	thelp.MuxNotePost(t, mux, 10, 666, "**"+wantName+"** has completed")
+
+	err := v.CreateStatus(ctx, event, provider.StatusOpts{Conclusion: "completed", OriginalPipelineRunName: "pr", Stage: "build"})
+	assert.NilError(t, err)
+	assert.Equal(t, statusName, wantName)
+}
+
 func TestGetCommitInfo(t *testing.T) {
 	ctx, _ := rtesting.SetupFakeContext(t)
 	client, _, tearDown := thelp.Setup(t)
@@ -208,10 +327,123 @@ func TestGetCommitInfo(t *testing.T) {
 	assert.Assert(t, ncv.GetCommitInfo(ctx, info.NewEvent()) != nil)
 }
 
+func TestGetCommitInfoCommitComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		wantErr string
+	}{
+		{
+			name:   "commit is the head of the branch",
+			branch: "main",
+		},
+		{
+			name:    "commit is not the head of the branch",
+			branch:  "nightly",
+			wantErr: "provided branch nightly does not contain sha sha",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			client, mux, tearDown := thelp.Setup(t)
+			defer tearDown()
+			mux.HandleFunc("/projects/100/repository/branches/main", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, `{"name": "main", "commit": {"id": "sha"}}`)
+			})
+			mux.HandleFunc("/projects/100/repository/branches/nightly", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, `{"name": "nightly", "commit": {"id": "othersha"}}`)
+			})
+			v := &Provider{Client: client, sourceProjectID: 100}
+			event := info.NewEvent()
+			event.SHA = "sha"
+			event.TriggerTarget = triggertype.Push
+			event.TriggerComment = "/retest"
+			event.HeadBranch = tt.branch
+			err := v.GetCommitInfo(ctx, event)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestGetCommitInfoSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		status    int
+		want      info.CommitSignature
+		wantError bool
+	}{
+		{
+			name:  "verified",
+			reply: `{"gpg_key_primary_keyid": "6A0C91B48753C58C", "verification_status": "verified"}`,
+			want:  info.CommitSignature{Status: info.SignatureVerified, KeyID: "6A0C91B48753C58C"},
+		},
+		{
+			name:  "unknown key",
+			reply: `{"gpg_key_primary_keyid": "6A0C91B48753C58C", "verification_status": "unknown_key"}`,
+			want:  info.CommitSignature{Status: info.SignatureUnverified, KeyID: "6A0C91B48753C58C"},
+		},
+		{
+			name:  "verified ssh",
+			reply: `{"signature_type": "SSH", "verification_status": "verified", "key": {"key": "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGC77X1Ef8ldAg0+auqsa4BWxKBXPG4rnKRWB9Qd7kGd"}}`,
+			want:  info.CommitSignature{Status: info.SignatureVerified, KeyID: "SHA256:H7jJ9F+/soOnniCCgxy32rynbNpONWRNFPdCp2swNNo"},
+		},
+		{
+			name:  "verified x509",
+			reply: `{"signature_type": "X509", "verification_status": "verified", "x509_certificate": {"subject_key_identifier": "BC BC BC BC"}}`,
+			want:  info.CommitSignature{Status: info.SignatureVerified, KeyID: "BC BC BC BC"},
+		},
+		{
+			name:  "verified by gitlab",
+			reply: `{"signature_type": "SSH", "verification_status": "verified_system"}`,
+			want:  info.CommitSignature{Status: info.SignatureVerified},
+		},
+		{
+			name:   "unsigned",
+			status: http.StatusNotFound,
+			reply:  `{"message": "404 GPG Signature Not Found"}`,
+			want:   info.CommitSignature{Status: info.SignatureUnsigned},
+		},
+		{
+			name:      "error",
+			status:    http.StatusForbidden,
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			client, mux, tearDown := thelp.Setup(t)
+			defer tearDown()
+			mux.HandleFunc("/projects/100/repository/commits/sha/signature", func(rw http.ResponseWriter, _ *http.Request) {
+				if tt.status != 0 {
+					rw.WriteHeader(tt.status)
+				}
+				fmt.Fprint(rw, tt.reply)
+			})
+			v := &Provider{Client: client, sourceProjectID: 100}
+			event := info.NewEvent()
+			event.SHA = "sha"
+			err := v.GetCommitInfo(ctx, event)
+			if tt.wantError {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, event.SHASignature, tt.want)
+		})
+	}
+}
+
 func TestGetConfig(t *testing.T) {
 	v := &Provider{}
 	assert.Assert(t, v.GetConfig().APIURL != "")
-	assert.Assert(t, v.GetConfig().TaskStatusTMPL != "")
+	assert.Equal(t, v.GetConfig().Name, "gitlab")
 }
 
 func TestSetClient(t *testing.T) {
@@ -336,6 +568,27 @@ func TestGetTektonDir(t *testing.T) {
 			wantClient: true,
 			wantStr:    "kind: PipelineRun",
 		},
+		{
+			name:      "list tekton dir on merge_base",
+			prcontent: string(samplePR),
+			args: args{
+				provenance: "merge_base",
+				path:       ".tekton",
+				event: &info.Event{
+					TriggerTarget: triggertype.PullRequest,
+					BaseBranch:    "main",
+					HeadBranch:    "feature",
+					SHA:           "headsha",
+				},
+			},
+			fields: fields{
+				sourceProjectID: 100,
+				targetProjectID: 200,
+			},
+			wantClient:           true,
+			wantStr:              "kind: PipelineRun",
+			filterMessageSnippet: `Using PipelineRun definition from merge base of main and headsha: mergebasesha`,
+		},
 		{
 			name:      "list tekton dir no --- prefix",
 			prcontent: strings.TrimPrefix(string(samplePR), "---"),
@@ -368,11 +621,20 @@ func TestGetTektonDir(t *testing.T) {
 				client, mux, tearDown := thelp.Setup(t)
 				v.Client = client
 				muxbranch := tt.args.event.HeadBranch
-				if tt.args.provenance == "default_branch" {
+				muxproject := tt.fields.sourceProjectID
+				switch tt.args.provenance {
+				case "default_branch":
 					muxbranch = tt.args.event.DefaultBranch
+				case "merge_base":
+					muxbranch = "mergebasesha"
+					muxproject = tt.fields.targetProjectID
+					mux.HandleFunc(fmt.Sprintf("/projects/%d/repository/merge_base", muxproject), func(rw http.ResponseWriter, r *http.Request) {
+						assert.DeepEqual(t, r.URL.Query()["refs[]"], []string{tt.args.event.BaseBranch, tt.args.event.SHA})
+						fmt.Fprint(rw, `{"id": "mergebasesha"}`)
+					})
 				}
 				if tt.args.path != "" && tt.prcontent != "" {
-					thelp.MuxListTektonDir(t, mux, tt.fields.sourceProjectID, muxbranch, tt.prcontent)
+					thelp.MuxListTektonDir(t, mux, muxproject, muxbranch, tt.prcontent)
 				}
 				defer tearDown()
 			}
@@ -0,0 +1,24 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ provider.PullRequestMergeInterface = (*Provider)(nil)
+
+// MergePullRequest accepts the merge request of the event if its head is still
+// the SHA of the event.
+func (v *Provider) MergePullRequest(_ context.Context, event *info.Event) error {
+	if v.Client == nil {
+		return fmt.Errorf("no gitlab client has been initialized")
+	}
+	_, _, err := v.Client.MergeRequests.AcceptMergeRequest(event.TargetProjectID, event.PullRequestNumber, &gitlab.AcceptMergeRequestOptions{
+		SHA: gitlab.Ptr(event.SHA),
+	})
+	return err
+}
@@ -0,0 +1,32 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	thelp "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestMergePullRequest(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	client, mux, tearDown := thelp.Setup(t)
+	defer tearDown()
+	v := &Provider{Client: client}
+
+	merged := false
+	mux.HandleFunc("/projects/10/merge_requests/42/merge", func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Method, http.MethodPut)
+		body := map[string]any{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, body["sha"], "sha")
+		merged = true
+		fmt.Fprint(rw, `{"iid": 42, "state": "merged"}`)
+	})
+	assert.NilError(t, v.MergePullRequest(ctx, &info.Event{TargetProjectID: 10, PullRequestNumber: 42, SHA: "sha"}))
+	assert.Assert(t, merged)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	"github.com/xanzy/go-gitlab"
 )
 
@@ -37,6 +38,9 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 	processedEvent.Event = eventInt
 	switch gitEvent := eventInt.(type) {
 	case *gitlab.MergeEvent:
+		if gitEvent.User == nil || gitEvent.ObjectAttributes.Source == nil || gitEvent.ObjectAttributes.Target == nil {
+			return nil, fmt.Errorf("invalid %s payload: no user, source or target project", event)
+		}
 		// Organization:  event.GetRepo().GetOwner().GetLogin(),
 		processedEvent.Sender = gitEvent.User.Username
 		processedEvent.DefaultBranch = gitEvent.Project.DefaultBranch
@@ -44,12 +48,20 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.SHA = gitEvent.ObjectAttributes.LastCommit.ID
 		processedEvent.SHAURL = gitEvent.ObjectAttributes.LastCommit.URL
 		processedEvent.SHATitle = gitEvent.ObjectAttributes.Title
+		processedEvent.SHAMessage = gitEvent.ObjectAttributes.LastCommit.Message
 		processedEvent.HeadBranch = gitEvent.ObjectAttributes.SourceBranch
 		processedEvent.BaseBranch = gitEvent.ObjectAttributes.TargetBranch
 		processedEvent.HeadURL = gitEvent.ObjectAttributes.Source.WebURL
 		processedEvent.BaseURL = gitEvent.ObjectAttributes.Target.WebURL
 		processedEvent.PullRequestNumber = gitEvent.ObjectAttributes.IID
 		processedEvent.PullRequestTitle = gitEvent.ObjectAttributes.Title
+		processedEvent.PullRequestClosed = provider.Valid(gitEvent.ObjectAttributes.Action, []string{"close", "merge"})
+		for _, label := range gitEvent.Labels {
+			if label == nil {
+				continue
+			}
+			processedEvent.PullRequestLabel = append(processedEvent.PullRequestLabel, label.Title)
+		}
 		v.targetProjectID = gitEvent.Project.ID
 		v.sourceProjectID = gitEvent.ObjectAttributes.SourceProjectID
 		v.userID = gitEvent.User.ID
@@ -62,14 +74,19 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.EventType = strings.ReplaceAll(event, " Hook", "")
 	case *gitlab.TagEvent:
 		lastCommitIdx := len(gitEvent.Commits) - 1
+		if lastCommitIdx < 0 || gitEvent.Commits[lastCommitIdx] == nil {
+			return nil, fmt.Errorf("no commits attached to this tag event")
+		}
 		processedEvent.Sender = gitEvent.UserUsername
 		processedEvent.DefaultBranch = gitEvent.Project.DefaultBranch
 		processedEvent.URL = gitEvent.Project.WebURL
 		processedEvent.SHA = gitEvent.Commits[lastCommitIdx].ID
 		processedEvent.SHAURL = gitEvent.Commits[lastCommitIdx].URL
 		processedEvent.SHATitle = gitEvent.Commits[lastCommitIdx].Title
+		processedEvent.SHAMessage = gitEvent.Commits[lastCommitIdx].Message
 		processedEvent.HeadBranch = gitEvent.Ref
 		processedEvent.BaseBranch = gitEvent.Ref
+		processedEvent.TagMessage = gitEvent.Message
 		processedEvent.HeadURL = gitEvent.Project.WebURL
 		processedEvent.BaseURL = processedEvent.HeadURL
 		processedEvent.TriggerTarget = "push"
@@ -82,16 +99,24 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.TargetProjectID = gitEvent.ProjectID
 		processedEvent.EventType = strings.ReplaceAll(event, " Hook", "")
 	case *gitlab.PushEvent:
-		if len(gitEvent.Commits) == 0 {
+		switch {
+		case provider.IsDeletedRef(gitEvent.After):
+			// a deleted branch has no commits, the runs of its last commit get cancelled
+			processedEvent.SHA = gitEvent.Before
+			processedEvent.BranchDeleted = true
+			processedEvent.CancelPipelineRuns = true
+		case len(gitEvent.Commits) == 0 || gitEvent.Commits[len(gitEvent.Commits)-1] == nil:
 			return nil, fmt.Errorf("no commits attached to this push event")
+		default:
+			lastCommitIdx := len(gitEvent.Commits) - 1
+			processedEvent.SHA = gitEvent.Commits[lastCommitIdx].ID
+			processedEvent.SHAURL = gitEvent.Commits[lastCommitIdx].URL
+			processedEvent.SHATitle = gitEvent.Commits[lastCommitIdx].Title
+			processedEvent.SHAMessage = gitEvent.Commits[lastCommitIdx].Message
 		}
-		lastCommitIdx := len(gitEvent.Commits) - 1
 		processedEvent.Sender = gitEvent.UserUsername
 		processedEvent.DefaultBranch = gitEvent.Project.DefaultBranch
 		processedEvent.URL = gitEvent.Project.WebURL
-		processedEvent.SHA = gitEvent.Commits[lastCommitIdx].ID
-		processedEvent.SHAURL = gitEvent.Commits[lastCommitIdx].URL
-		processedEvent.SHATitle = gitEvent.Commits[lastCommitIdx].Title
 		processedEvent.HeadBranch = gitEvent.Ref
 		processedEvent.BaseBranch = gitEvent.Ref
 		processedEvent.HeadURL = gitEvent.Project.WebURL
@@ -106,6 +131,9 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.TargetProjectID = gitEvent.ProjectID
 		processedEvent.EventType = strings.ReplaceAll(event, " Hook", "")
 	case *gitlab.MergeCommentEvent:
+		if gitEvent.User == nil || gitEvent.MergeRequest.Source == nil || gitEvent.MergeRequest.Target == nil {
+			return nil, fmt.Errorf("invalid %s payload: no user, source or target project", event)
+		}
 		processedEvent.Sender = gitEvent.User.Username
 		processedEvent.DefaultBranch = gitEvent.Project.DefaultBranch
 		processedEvent.URL = gitEvent.Project.WebURL
@@ -113,12 +141,14 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		processedEvent.SHAURL = gitEvent.MergeRequest.LastCommit.URL
 		// TODO: change this back to Title when we get this pr available merged https://github.com/xanzy/go-gitlab/pull/1406/files
 		processedEvent.SHATitle = gitEvent.MergeRequest.LastCommit.Message
+		processedEvent.SHAMessage = gitEvent.MergeRequest.LastCommit.Message
 		processedEvent.BaseBranch = gitEvent.MergeRequest.TargetBranch
 		processedEvent.HeadBranch = gitEvent.MergeRequest.SourceBranch
 		processedEvent.BaseURL = gitEvent.MergeRequest.Target.WebURL
 		processedEvent.HeadURL = gitEvent.MergeRequest.Source.WebURL
 
 		opscomments.SetEventTypeAndTargetPR(processedEvent, gitEvent.ObjectAttributes.Note)
+		processedEvent.TriggerCommentID = int64(gitEvent.ObjectAttributes.ID)
 		v.pathWithNamespace = gitEvent.Project.PathWithNamespace
 		processedEvent.Organization, processedEvent.Repository = getOrgRepo(v.pathWithNamespace)
 		processedEvent.TriggerTarget = triggertype.PullRequest
@@ -129,6 +159,42 @@ func (v *Provider) ParsePayload(_ context.Context, _ *params.Run, request *http.
 		v.userID = gitEvent.User.ID
 		processedEvent.SourceProjectID = gitEvent.MergeRequest.SourceProjectID
 		processedEvent.TargetProjectID = gitEvent.MergeRequest.TargetProjectID
+	case *gitlab.CommitCommentEvent:
+		if gitEvent.User == nil {
+			return nil, fmt.Errorf("invalid %s payload: no user", event)
+		}
+		processedEvent.Sender = gitEvent.User.Username
+		processedEvent.DefaultBranch = gitEvent.Project.DefaultBranch
+		processedEvent.URL = gitEvent.Project.WebURL
+		processedEvent.SHA = gitEvent.ObjectAttributes.CommitID
+		if gitEvent.Commit != nil {
+			processedEvent.SHAURL = gitEvent.Commit.URL
+			processedEvent.SHATitle = gitEvent.Commit.Title
+			processedEvent.SHAMessage = gitEvent.Commit.Message
+		}
+		processedEvent.HeadURL = gitEvent.Project.WebURL
+		processedEvent.BaseURL = processedEvent.HeadURL
+
+		// the branch of the commit is not in the payload, the default branch
+		// is used unless the comment asks for another one, GetCommitInfo
+		// checks the commit is the head of the branch.
+		branchName, err := opscomments.SetEventTypeAndTargetPush(processedEvent, gitEvent.ObjectAttributes.Note)
+		if err != nil {
+			return nil, err
+		}
+		if branchName == "" {
+			branchName = gitEvent.Project.DefaultBranch
+		}
+		processedEvent.HeadBranch = branchName
+		processedEvent.BaseBranch = branchName
+
+		v.pathWithNamespace = gitEvent.Project.PathWithNamespace
+		processedEvent.Organization, processedEvent.Repository = getOrgRepo(v.pathWithNamespace)
+		v.targetProjectID = gitEvent.ProjectID
+		v.sourceProjectID = gitEvent.ProjectID
+		v.userID = gitEvent.User.ID
+		processedEvent.SourceProjectID = gitEvent.ProjectID
+		processedEvent.TargetProjectID = gitEvent.ProjectID
 	default:
 		return nil, fmt.Errorf("event %s is not supported", event)
 	}
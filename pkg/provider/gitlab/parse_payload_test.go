@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/google/go-github/v61/github"
@@ -84,6 +85,21 @@ func TestParsePayload(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "push event deleting a branch",
+			args: args{
+				event:   gitlab.EventTypePush,
+				payload: strings.TrimSuffix(sample.PushEventAsJSON(false), "}") + `, "before": "beforesha", "after": "0000000000000000000000000000000000000000"}`,
+			},
+			want: &info.Event{
+				EventType:     "Push",
+				TriggerTarget: "push",
+				Organization:  "hello-this-is-me-ze",
+				Repository:    "project",
+				SHA:           "beforesha",
+				State:         info.State{CancelPipelineRuns: true, BranchDeleted: true},
+			},
+		},
 		{
 			name: "push event",
 			args: args{
@@ -110,6 +126,20 @@ func TestParsePayload(t *testing.T) {
 				Repository:    "project",
 			},
 		},
+		{
+			name: "annotated tag event",
+			args: args{
+				event:   gitlab.EventTypeTagPush,
+				payload: strings.Replace(sample.PushEventAsJSON(true), `"user_username"`, `"message": "Release v1.0.0", "user_username"`, 1),
+			},
+			want: &info.Event{
+				EventType:     "Tag Push",
+				TriggerTarget: "push",
+				Organization:  "hello-this-is-me-ze",
+				Repository:    "project",
+				TagMessage:    "Release v1.0.0",
+			},
+		},
 		{
 			name: "note event",
 			args: args{
@@ -164,6 +194,46 @@ func TestParsePayload(t *testing.T) {
 				State:         info.State{TargetCancelPipelineRun: "dummy"},
 			},
 		},
+		{
+			name: "commit note event retest",
+			args: args{
+				event:   gitlab.EventTypeNote,
+				payload: sample.CommitNoteEventAsJSON("/retest dummy"),
+			},
+			want: &info.Event{
+				EventType:     "push",
+				TriggerTarget: "push",
+				Organization:  "hello-this-is-me-ze",
+				Repository:    "project",
+				SHA:           "sha",
+				HeadBranch:    "main",
+				State:         info.State{TargetTestPipelineRun: "dummy"},
+			},
+		},
+		{
+			name: "commit note event cancel on a branch",
+			args: args{
+				event:   gitlab.EventTypeNote,
+				payload: sample.CommitNoteEventAsJSON("/cancel dummy branch:nightly"),
+			},
+			want: &info.Event{
+				EventType:     "push",
+				TriggerTarget: "push",
+				Organization:  "hello-this-is-me-ze",
+				Repository:    "project",
+				SHA:           "sha",
+				HeadBranch:    "nightly",
+				State:         info.State{TargetCancelPipelineRun: "dummy", CancelPipelineRuns: true},
+			},
+		},
+		{
+			name: "commit note event with a bad branch argument",
+			args: args{
+				event:   gitlab.EventTypeNote,
+				payload: sample.CommitNoteEventAsJSON("/test dummy foo:nightly"),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -196,12 +266,27 @@ func TestParsePayload(t *testing.T) {
 				assert.Equal(t, tt.want.EventType, got.EventType)
 				assert.Equal(t, tt.want.Organization, got.Organization)
 				assert.Equal(t, tt.want.Repository, got.Repository)
+				assert.Equal(t, tt.want.BranchDeleted, got.BranchDeleted)
+				assert.Equal(t, tt.want.TagMessage, got.TagMessage)
+				if tt.want.BranchDeleted {
+					assert.Assert(t, got.CancelPipelineRuns)
+				}
+				if tt.want.SHA != "" {
+					assert.Equal(t, tt.want.SHA, got.SHA)
+				}
 				if tt.want.TargetTestPipelineRun != "" {
 					assert.Equal(t, tt.want.TargetTestPipelineRun, got.TargetTestPipelineRun)
 				}
 				if tt.want.TargetCancelPipelineRun != "" {
 					assert.Equal(t, tt.want.TargetCancelPipelineRun, got.TargetCancelPipelineRun)
 				}
+				if tt.want.HeadBranch != "" {
+					assert.Equal(t, tt.want.HeadBranch, got.HeadBranch)
+					assert.Equal(t, tt.want.HeadBranch, got.BaseBranch)
+				}
+				if tt.want.CancelPipelineRuns {
+					assert.Assert(t, got.CancelPipelineRuns)
+				}
 			}
 		})
 	}
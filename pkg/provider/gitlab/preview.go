@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ provider.PreviewEnvironmentInterface = (*Provider)(nil)
+
+// getPreviewEnvironment returns the preview environment of the merge request
+// or nil if it doesn't exist yet.
+func (v *Provider) getPreviewEnvironment(event *info.Event) (*gitlab.Environment, error) {
+	environments, _, err := v.Client.Environments.ListEnvironments(event.TargetProjectID, &gitlab.ListEnvironmentsOptions{
+		Name: gitlab.Ptr(provider.PreviewEnvironmentName(event)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(environments) == 0 {
+		return nil, nil
+	}
+	return environments[0], nil
+}
+
+// CreatePreviewEnvironment creates the preview environment of the merge
+// request, or updates its external URL when it already exists.
+func (v *Provider) CreatePreviewEnvironment(_ context.Context, event *info.Event, url, _ string) error {
+	if v.Client == nil {
+		return fmt.Errorf("no gitlab client has been initialized")
+	}
+	name := provider.PreviewEnvironmentName(event)
+	environment, err := v.getPreviewEnvironment(event)
+	if err != nil {
+		return fmt.Errorf("cannot get environment %s: %w", name, err)
+	}
+	if environment != nil {
+		_, _, err = v.Client.Environments.EditEnvironment(event.TargetProjectID, environment.ID, &gitlab.EditEnvironmentOptions{
+			ExternalURL: gitlab.Ptr(url),
+		})
+	} else {
+		_, _, err = v.Client.Environments.CreateEnvironment(event.TargetProjectID, &gitlab.CreateEnvironmentOptions{
+			Name:        gitlab.Ptr(name),
+			ExternalURL: gitlab.Ptr(url),
+			Tier:        gitlab.Ptr("development"),
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("cannot create environment %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeactivatePreviewEnvironment stops the preview environment of the merge
+// request.
+func (v *Provider) DeactivatePreviewEnvironment(_ context.Context, event *info.Event) error {
+	if v.Client == nil {
+		return fmt.Errorf("no gitlab client has been initialized")
+	}
+	name := provider.PreviewEnvironmentName(event)
+	environment, err := v.getPreviewEnvironment(event)
+	if err != nil {
+		return fmt.Errorf("cannot get environment %s: %w", name, err)
+	}
+	if environment == nil || environment.State == "stopped" {
+		return nil
+	}
+	if _, _, err := v.Client.Environments.StopEnvironment(event.TargetProjectID, environment.ID, &gitlab.StopEnvironmentOptions{}); err != nil {
+		return fmt.Errorf("cannot stop environment %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	thelp "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestCreatePreviewEnvironment(t *testing.T) {
+	tests := []struct {
+		name         string
+		environments string
+		wantEdit     bool
+	}{
+		{
+			name:         "create environment",
+			environments: `[]`,
+		},
+		{
+			name:         "update existing environment",
+			environments: `[{"id": 5, "name": "preview/pr-42"}]`,
+			wantEdit:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			client, mux, tearDown := thelp.Setup(t)
+			defer tearDown()
+			v := &Provider{Client: client}
+			event := &info.Event{TargetProjectID: 10, PullRequestNumber: 42}
+
+			created, edited := false, false
+			mux.HandleFunc("/projects/10/environments", func(rw http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					assert.Equal(t, r.URL.Query().Get("name"), "preview/pr-42")
+					fmt.Fprint(rw, tt.environments)
+					return
+				}
+				created = true
+				body := map[string]string{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, body["name"], "preview/pr-42")
+				assert.Equal(t, body["external_url"], "https://preview.example.com")
+				fmt.Fprint(rw, `{}`)
+			})
+			mux.HandleFunc("/projects/10/environments/5", func(rw http.ResponseWriter, r *http.Request) {
+				edited = true
+				body := map[string]string{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, body["external_url"], "https://preview.example.com")
+				fmt.Fprint(rw, `{}`)
+			})
+
+			assert.NilError(t, v.CreatePreviewEnvironment(ctx, event, "https://preview.example.com", ""))
+			assert.Equal(t, edited, tt.wantEdit)
+			assert.Equal(t, created, !tt.wantEdit)
+		})
+	}
+}
+
+func TestDeactivatePreviewEnvironment(t *testing.T) {
+	tests := []struct {
+		name         string
+		environments string
+		wantStop     bool
+	}{
+		{
+			name:         "no environment",
+			environments: `[]`,
+		},
+		{
+			name:         "already stopped",
+			environments: `[{"id": 5, "name": "preview/pr-42", "state": "stopped"}]`,
+		},
+		{
+			name:         "stop environment",
+			environments: `[{"id": 5, "name": "preview/pr-42", "state": "available"}]`,
+			wantStop:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			client, mux, tearDown := thelp.Setup(t)
+			defer tearDown()
+			v := &Provider{Client: client}
+			event := &info.Event{TargetProjectID: 10, PullRequestNumber: 42}
+
+			stopped := false
+			mux.HandleFunc("/projects/10/environments", func(rw http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(rw, tt.environments)
+			})
+			mux.HandleFunc("/projects/10/environments/5/stop", func(rw http.ResponseWriter, _ *http.Request) {
+				stopped = true
+				fmt.Fprint(rw, `{}`)
+			})
+
+			assert.NilError(t, v.DeactivatePreviewEnvironment(ctx, event))
+			assert.Equal(t, stopped, tt.wantStop)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ provider.OpenPullRequestsInterface = (*Provider)(nil)
+
+// ListOpenPullRequests lists the opened merge requests targeting the base
+// branch, the most recently updated first.
+func (v *Provider) ListOpenPullRequests(_ context.Context, event *info.Event, baseBranch string, limit int) ([]provider.PullRequest, error) {
+	if v.Client == nil {
+		return nil, fmt.Errorf("no gitlab client has been initialized")
+	}
+	opts := &gitlab.ListProjectMergeRequestsOptions{
+		ListOptions:  gitlab.ListOptions{PerPage: 100},
+		State:        gitlab.Ptr("opened"),
+		TargetBranch: gitlab.Ptr(baseBranch),
+		OrderBy:      gitlab.Ptr("updated_at"),
+		Sort:         gitlab.Ptr("desc"),
+	}
+	ret := []provider.PullRequest{}
+	for {
+		mrs, resp, err := v.Client.MergeRequests.ListProjectMergeRequests(event.TargetProjectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, mr := range mrs {
+			pr := provider.PullRequest{
+				Number:          mr.IID,
+				Title:           mr.Title,
+				Labels:          mr.Labels,
+				SHA:             mr.SHA,
+				HeadBranch:      mr.SourceBranch,
+				HeadURL:         event.URL,
+				BaseURL:         event.URL,
+				SourceProjectID: mr.SourceProjectID,
+			}
+			if mr.Author != nil {
+				pr.Author = mr.Author.Username
+			}
+			if mr.UpdatedAt != nil {
+				pr.UpdatedAt = *mr.UpdatedAt
+			}
+			ret = append(ret, pr)
+			if len(ret) == limit {
+				return ret, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return ret, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,34 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	thelp "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestListOpenPullRequests(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	client, mux, tearDown := thelp.Setup(t)
+	defer tearDown()
+	v := &Provider{Client: client}
+
+	mux.HandleFunc("/projects/10/merge_requests", func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("state"), "opened")
+		assert.Equal(t, r.URL.Query().Get("target_branch"), "main")
+		fmt.Fprint(rw, `[{"iid": 42, "title": "fix", "sha": "abc", "source_branch": "fix", "source_project_id": 11,
+			"labels": ["bug"], "author": {"username": "alice"}}]`)
+	})
+	prs, err := v.ListOpenPullRequests(ctx, &info.Event{TargetProjectID: 10, URL: "https://gitlab.com/owner/repo"}, "main", 10)
+	assert.NilError(t, err)
+	assert.Equal(t, len(prs), 1)
+	assert.Equal(t, prs[0].Number, 42)
+	assert.Equal(t, prs[0].SHA, "abc")
+	assert.Equal(t, prs[0].SourceProjectID, 11)
+	assert.Equal(t, prs[0].Author, "alice")
+	assert.Equal(t, prs[0].HeadURL, "https://gitlab.com/owner/repo")
+}
@@ -0,0 +1,26 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ provider.CommentReactionInterface = (*Provider)(nil)
+
+// AddCommentReaction awards an emoji to the note of the merge request of the
+// event, the notes on commits cannot be awarded and are ignored.
+func (v *Provider) AddCommentReaction(_ context.Context, event *info.Event, reaction provider.Reaction) error {
+	if v.Client == nil {
+		return fmt.Errorf("no gitlab client has been initialized")
+	}
+	if event.PullRequestNumber == 0 {
+		return nil
+	}
+	_, _, err := v.Client.AwardEmoji.CreateMergeRequestAwardEmojiOnNote(event.TargetProjectID, event.PullRequestNumber,
+		int(event.TriggerCommentID), &gitlab.CreateAwardEmojiOptions{Name: string(reaction)})
+	return err
+}
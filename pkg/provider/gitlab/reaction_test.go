@@ -0,0 +1,37 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	thelp "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab/test"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestAddCommentReaction(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	client, mux, tearDown := thelp.Setup(t)
+	defer tearDown()
+	v := &Provider{Client: client}
+
+	awarded := false
+	mux.HandleFunc("/projects/10/merge_requests/42/notes/7/award_emoji", func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Method, http.MethodPost)
+		body := map[string]string{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, body["name"], "rocket")
+		awarded = true
+		fmt.Fprint(rw, `{}`)
+	})
+	event := &info.Event{TargetProjectID: 10, PullRequestNumber: 42, TriggerCommentID: 7}
+	assert.NilError(t, v.AddCommentReaction(ctx, event, provider.ReactionRocket))
+	assert.Assert(t, awarded)
+
+	// notes on commits cannot be awarded
+	assert.NilError(t, v.AddCommentReaction(ctx, &info.Event{TargetProjectID: 10, TriggerCommentID: 8}, provider.ReactionThumbsUp))
+}
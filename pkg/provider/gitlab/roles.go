@@ -0,0 +1,58 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+var _ provider.SenderRoleInterface = (*Provider)(nil)
+
+// SenderRole returns the role of the sender from its access level on the
+// project, inherited from its groups: owner, maintainer, or developer for a
+// collaborator.
+func (v *Provider) SenderRole(_ context.Context, event *info.Event) (info.Role, error) {
+	if v.Client == nil {
+		return info.RoleNone, fmt.Errorf("no gitlab client has been initialized")
+	}
+	role, err := v.memberRole(v.userID)
+	if err != nil || role != info.RoleNone {
+		return role, err
+	}
+	authorID := 0
+	switch gitEvent := event.Event.(type) {
+	case *gitlab.MergeEvent:
+		authorID = gitEvent.ObjectAttributes.AuthorID
+	case *gitlab.MergeCommentEvent:
+		authorID = gitEvent.MergeRequest.AuthorID
+	}
+	if authorID != 0 && authorID == v.userID {
+		return info.RoleAuthor, nil
+	}
+	return info.RoleNone, nil
+}
+
+// memberRole returns the role of the user from its access level on the
+// project, none when it is not a member or below the developer access level.
+func (v *Provider) memberRole(userID int) (info.Role, error) {
+	member, resp, err := v.Client.ProjectMembers.GetInheritedProjectMember(v.targetProjectID, userID)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return info.RoleNone, nil
+		}
+		return info.RoleNone, err
+	}
+	switch {
+	case member.AccessLevel >= gitlab.OwnerPermissions:
+		return info.RoleOwner, nil
+	case member.AccessLevel >= gitlab.MaintainerPermissions:
+		return info.RoleMaintainer, nil
+	case member.AccessLevel >= gitlab.DeveloperPermissions:
+		return info.RoleCollaborator, nil
+	}
+	return info.RoleNone, nil
+}
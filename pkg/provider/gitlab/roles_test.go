@@ -0,0 +1,73 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	thelp "github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab/test"
+	"github.com/xanzy/go-gitlab"
+	"gotest.tools/v3/assert"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestSenderRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessLevel int
+		userID      int
+		want        info.Role
+	}{
+		{
+			name:        "owner",
+			accessLevel: 50,
+			userID:      1,
+			want:        info.RoleOwner,
+		},
+		{
+			name:        "maintainer",
+			accessLevel: 40,
+			userID:      1,
+			want:        info.RoleMaintainer,
+		},
+		{
+			name:        "developer",
+			accessLevel: 30,
+			userID:      1,
+			want:        info.RoleCollaborator,
+		},
+		{
+			name:        "reporter authoring the merge request",
+			accessLevel: 20,
+			userID:      2,
+			want:        info.RoleAuthor,
+		},
+		{
+			name:   "not a member",
+			userID: 3,
+			want:   info.RoleNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			client, mux, tearDown := thelp.Setup(t)
+			defer tearDown()
+			v := &Provider{Client: client, targetProjectID: 10, userID: tt.userID}
+			mux.HandleFunc(fmt.Sprintf("/projects/10/members/all/%d", tt.userID), func(rw http.ResponseWriter, _ *http.Request) {
+				if tt.accessLevel == 0 {
+					rw.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(rw, `{"message": "404 Not found"}`)
+					return
+				}
+				fmt.Fprintf(rw, `{"id": %d, "access_level": %d}`, tt.userID, tt.accessLevel)
+			})
+			mr := &gitlab.MergeCommentEvent{}
+			mr.MergeRequest.AuthorID = 2
+			got, err := v.SenderRole(ctx, &info.Event{Event: mr})
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+var _ provider.StatusURLInterface = (*Provider)(nil)
+
+// StatusURL returns the URL of the note of the PipelineRun on the merge
+// request when the notes are updated, the merge request otherwise since a new
+// note is added for each status. The pushes have no note.
+func (v *Provider) StatusURL(ctx context.Context, event *info.Event, pr *tektonv1.PipelineRun) (string, error) {
+	if event.PullRequestNumber == 0 {
+		return "", nil
+	}
+	if v.Client == nil {
+		return "", fmt.Errorf("no gitlab client has been initialized")
+	}
+	mrURL := fmt.Sprintf("%s/-/merge_requests/%d", strings.TrimSuffix(event.URL, "/"), event.PullRequestNumber)
+	if !provider.UpdateStatusComment(v.repo) {
+		return mrURL, nil
+	}
+	note, err := v.findStatusNote(ctx, event, provider.StatusCommentMarker(pr.GetAnnotations()[keys.OriginalPRName], event.SHA))
+	if err != nil || note == nil {
+		return mrURL, err
+	}
+	return fmt.Sprintf("%s#note_%d", mrURL, note.ID), nil
+}
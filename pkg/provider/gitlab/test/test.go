@@ -55,7 +55,7 @@ func MuxNotePost(t *testing.T, mux *http.ServeMux, projectNumber, mrID int, catc
 func MuxAllowUserID(mux *http.ServeMux, projectID, userID int) {
 	path := fmt.Sprintf("/projects/%d/members/all/%d", projectID, userID)
 	mux.HandleFunc(path, func(rw http.ResponseWriter, _ *http.Request) {
-		fmt.Fprintf(rw, `{"id": %d}`, userID)
+		fmt.Fprintf(rw, `{"id": %d, "access_level": 30}`, userID)
 	})
 }
 
@@ -174,6 +174,35 @@ func (t TEvent) NoteEventAsJSON(comment string) string {
 }`, comment, t.Username, t.DefaultBranch, t.URL, t.PathWithNameSpace, t.MRID, t.TargetProjectID, t.SourceProjectID, t.Basebranch, t.Headbranch, t.SHA, t.SHAurl, t.SHAtitle, t.SHAtitle, t.BaseURL, t.HeadURL)
 }
 
+// CommitNoteEventAsJSON returns a JSON string representing a comment made on
+// a commit.
+func (t TEvent) CommitNoteEventAsJSON(comment string) string {
+	return fmt.Sprintf(`{
+	"object_kind": "note",
+	"event_type": "note",
+	"project_id": %d,
+	"object_attributes": {
+		"noteable_type": "Commit",
+		"note": "%s",
+		"commit_id": "%s"
+	},
+	"user": {
+		"id": %d,
+		"username": "%s"
+	},
+	"project": {
+		"default_branch": "%s",
+		"web_url": "%s",
+		"path_with_namespace": "%s"
+	},
+	"commit": {
+		"id": "%s",
+		"url": "%s",
+		"title": "%s"
+	}
+}`, t.TargetProjectID, comment, t.SHA, t.UserID, t.Username, t.DefaultBranch, t.URL, t.PathWithNameSpace, t.SHA, t.SHAurl, t.SHAtitle)
+}
+
 // MREventAsJSON returns a JSON string representing the Merge Request event.
 // It includes information about the user, project, and object attributes such as action, iid, source project id, title, source branch, target branch, last commit, target path with namespace, target web url, and source web url.
 func (t TEvent) MREventAsJSON(action, extraStuff string) string {
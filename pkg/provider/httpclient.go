@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/httpclient"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+)
+
+// HTTPClient returns the http client of the provider API calls, honoring the
+// proxy, the custom CA bundles, the minimal TLS version, the egress allowlist
+// and the retries of the reads of the settings. The requests are counted in
+// the metrics of the provider, and their time in the APITime of the event of
+// ctx. The connections are reused across the events as long as the settings
+// don't change.
+func HTTPClient(ctx context.Context, run *params.Run, providerName string) *http.Client {
+	apiTime, _ := apiTimeFrom(ctx)
+	if run == nil || run.Info.Pac == nil {
+		client := httpclient.NewClient(nil, nil)
+		client.Transport = newMetricsTransport(client.Transport, providerName, apiTime)
+		return client
+	}
+	pacOpts := run.Info.GetPacOpts()
+	client := httpclient.NewClient(&pacOpts.Settings, run.Clients.Log)
+	client.Transport = newRetryTransport(newMetricsTransport(client.Transport, providerName, apiTime), pacOpts.ProviderReadRetries, providerName)
+	return client
+}
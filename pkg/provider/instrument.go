@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+)
+
+// operationOther tags the requests made outside of a budgeted operation.
+const operationOther = "other"
+
+// rateLimitHeaders are the headers of the requests left in the rate limit,
+// GitHub and Gitea send the X- one and GitLab the other one.
+var rateLimitHeaders = []string{"X-RateLimit-Remaining", "RateLimit-Remaining"}
+
+type operationContextKey struct{}
+
+func withOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationContextKey{}, op)
+}
+
+func operationFrom(ctx context.Context) string {
+	if op, ok := ctx.Value(operationContextKey{}).(Operation); ok {
+		return string(op)
+	}
+	return operationOther
+}
+
+// APITime accumulates the time spent in the requests to the provider API made
+// while processing an event, the requests made in parallel are all counted.
+type APITime struct {
+	nanos atomic.Int64
+}
+
+// Duration returns the time spent in the requests to the provider API.
+func (a *APITime) Duration() time.Duration {
+	return time.Duration(a.nanos.Load())
+}
+
+type apiTimeContextKey struct{}
+
+// WithAPITime returns a context accumulating in the APITime the time of the
+// requests to the provider API made with it.
+func WithAPITime(ctx context.Context, apiTime *APITime) context.Context {
+	return context.WithValue(ctx, apiTimeContextKey{}, apiTime)
+}
+
+// WithoutAPITime returns a context whose requests to the provider API are not
+// counted in the APITime of the event anymore, for the work going on after the
+// event has been processed.
+func WithoutAPITime(ctx context.Context) context.Context {
+	return context.WithValue(ctx, apiTimeContextKey{}, (*APITime)(nil))
+}
+
+// apiTimeFrom returns the APITime of the context, ok is true as well when
+// the requests of the context are not counted anymore.
+func apiTimeFrom(ctx context.Context) (*APITime, bool) {
+	apiTime, ok := ctx.Value(apiTimeContextKey{}).(*APITime)
+	return apiTime, ok
+}
+
+// metricsTransport counts the requests to the provider API by operation and
+// status class, records the requests left in the rate limit and the time
+// spent in the requests of an event. The time goes to the APITime of the
+// context of the request, or to the one of the event the client has been
+// created for when the provider client doesn't pass a context.
+type metricsTransport struct {
+	next     http.RoundTripper
+	provider string
+	apiTime  *APITime
+}
+
+func newMetricsTransport(next http.RoundTripper, provider string, apiTime *APITime) http.RoundTripper {
+	return &metricsTransport{next: next, provider: provider, apiTime: apiTime}
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	apiTime, ok := apiTimeFrom(req.Context())
+	if !ok {
+		apiTime = t.apiTime
+	}
+	if apiTime != nil {
+		apiTime.nanos.Add(int64(time.Since(start)))
+	}
+	metrics.CountProviderRequest(t.provider, operationFrom(req.Context()), statusClass(resp, err))
+	if resp != nil {
+		if remaining, ok := rateLimitRemaining(resp.Header); ok {
+			metrics.RecordProviderRateLimit(t.provider, remaining)
+		}
+	}
+	return resp, err
+}
+
+func statusClass(resp *http.Response, err error) string {
+	if err != nil || resp == nil {
+		return "error"
+	}
+	return fmt.Sprintf("%dxx", resp.StatusCode/100)
+}
+
+func rateLimitRemaining(header http.Header) (int, bool) {
+	for _, name := range rateLimitHeaders {
+		if value := header.Get(name); value != "" {
+			remaining, err := strconv.Atoi(value)
+			return remaining, err == nil
+		}
+	}
+	return 0, false
+}
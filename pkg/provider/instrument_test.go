@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestStatusClass(t *testing.T) {
+	assert.Equal(t, statusClass(&http.Response{StatusCode: http.StatusOK}, nil), "2xx")
+	assert.Equal(t, statusClass(&http.Response{StatusCode: http.StatusNotModified}, nil), "3xx")
+	assert.Equal(t, statusClass(&http.Response{StatusCode: http.StatusTooManyRequests}, nil), "4xx")
+	assert.Equal(t, statusClass(&http.Response{StatusCode: http.StatusBadGateway}, nil), "5xx")
+	assert.Equal(t, statusClass(nil, errors.New("connection refused")), "error")
+}
+
+func TestRateLimitRemaining(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+		wantOK bool
+	}{
+		{
+			name:   "github",
+			header: http.Header{"X-Ratelimit-Remaining": []string{"4999"}},
+			want:   4999,
+			wantOK: true,
+		},
+		{
+			name:   "gitlab",
+			header: http.Header{"Ratelimit-Remaining": []string{"0"}},
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name:   "no header",
+			header: http.Header{},
+		},
+		{
+			name:   "not a number",
+			header: http.Header{"X-Ratelimit-Remaining": []string{"many"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rateLimitRemaining(tt.header)
+			assert.Equal(t, ok, tt.wantOK)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestOperationFrom(t *testing.T) {
+	assert.Equal(t, operationFrom(context.Background()), operationOther)
+	ctx, done := WithBudget(context.Background(), nil, "github", OperationDiff)
+	defer done()
+	assert.Equal(t, operationFrom(ctx), string(OperationDiff))
+}
+
+func TestMetricsTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newMetricsTransport(http.DefaultTransport, "github", nil)}
+	resp, err := client.Get(server.URL)
+	assert.NilError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusTeapot)
+
+	next := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	_, err = newMetricsTransport(next, "github", nil).RoundTrip(req)
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestMetricsTransportAPITime(t *testing.T) {
+	next := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		time.Sleep(10 * time.Millisecond)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	apiTime := &APITime{}
+	ctx := WithAPITime(context.Background(), apiTime)
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil).WithContext(ctx)
+		_, err := newMetricsTransport(next, "github", nil).RoundTrip(req)
+		assert.NilError(t, err)
+	}
+	spent := apiTime.Duration()
+	assert.Assert(t, spent >= 20*time.Millisecond, spent)
+
+	// the requests of other events are not counted
+	_, err := newMetricsTransport(next, "github", nil).RoundTrip(httptest.NewRequest(http.MethodGet, "https://example.com/", nil))
+	assert.NilError(t, err)
+	assert.Equal(t, apiTime.Duration(), spent)
+
+	// nor the ones made in the background once the event is processed
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil).WithContext(WithoutAPITime(ctx))
+	_, err = newMetricsTransport(next, "github", nil).RoundTrip(req)
+	assert.NilError(t, err)
+	assert.Equal(t, apiTime.Duration(), spent)
+
+	// the requests of a client created for the event are counted even when
+	// the provider client doesn't pass the context
+	client := HTTPClient(ctx, nil, "gitea")
+	client.Transport.(*metricsTransport).next = next
+	_, err = client.Transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://example.com/", nil))
+	assert.NilError(t, err)
+	assert.Assert(t, apiTime.Duration() >= spent+10*time.Millisecond, apiTime.Duration())
+
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/", nil).WithContext(WithoutAPITime(ctx))
+	spent = apiTime.Duration()
+	_, err = client.Transport.RoundTrip(req)
+	assert.NilError(t, err)
+	assert.Equal(t, apiTime.Duration(), spent)
+}
@@ -17,6 +17,8 @@ type StatusOpts struct {
 	PipelineRun              *v1.PipelineRun
 	PipelineRunName          string
 	OriginalPipelineRunName  string
+	RunID                    string
+	Stage                    string
 	Status                   string
 	Conclusion               string
 	Text                     string
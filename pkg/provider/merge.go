@@ -0,0 +1,15 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// PullRequestMergeInterface is implemented by the providers able to merge the
+// pull request of an event.
+type PullRequestMergeInterface interface {
+	// MergePullRequest merges the pull request of the event, only if its
+	// head is still the SHA of the event, the one that has been tested.
+	MergePullRequest(ctx context.Context, event *info.Event) error
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxPages is the number of pages fetched at most by Paginate when the
+// caller doesn't set a cap.
+const DefaultMaxPages = 100
+
+// ErrTooManyPages is returned by Paginate when a list has more pages than
+// the cap.
+var ErrTooManyPages = errors.New("too many pages")
+
+// PageFunc fetches a page of a list API call, the page 0 is the first page.
+// It returns the items of the page and the page to fetch next, 0 after the
+// last page.
+type PageFunc[T any] func(page int) (items []T, nextPage int, err error)
+
+// Paginate fetches the pages of a list API call until the last one. It stops
+// with the error of the context when it is done between two pages and with
+// ErrTooManyPages after maxPages pages, DefaultMaxPages when maxPages is 0.
+// The items of the pages already fetched are returned with the errors.
+func Paginate[T any](ctx context.Context, maxPages int, fetch PageFunc[T]) ([]T, error) {
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+	all := []T{}
+	page := 0
+	for fetched := 0; ; fetched++ {
+		if fetched == maxPages {
+			return all, fmt.Errorf("stopped after %d pages: %w", maxPages, ErrTooManyPages)
+		}
+		if err := ctx.Err(); err != nil {
+			return all, err
+		}
+		items, nextPage, err := fetch(page)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, items...)
+		// a next page going backward would loop forever
+		if nextPage <= page {
+			return all, nil
+		}
+		page = nextPage
+	}
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	// pages returns a PageFunc serving the pages with the next page of each
+	// page given in next.
+	pages := func(items [][]string, next []int) PageFunc[string] {
+		return func(page int) ([]string, int, error) {
+			if page >= len(items) {
+				return nil, 0, errors.New("no such page")
+			}
+			return items[page], next[page], nil
+		}
+	}
+	tests := []struct {
+		name     string
+		maxPages int
+		fetch    PageFunc[string]
+		cancel   bool
+		want     []string
+		wantErr  string
+	}{
+		{
+			name:  "single page",
+			fetch: pages([][]string{{"a", "b"}}, []int{0}),
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "multiple pages",
+			fetch: pages([][]string{{"a"}, {"b"}, {"c"}}, []int{1, 2, 0}),
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "next page going backward",
+			fetch: pages([][]string{{"a"}, {"b"}}, []int{1, 1}),
+			want:  []string{"a", "b"},
+		},
+		{
+			name:     "too many pages",
+			maxPages: 2,
+			fetch:    pages([][]string{{"a"}, {"b"}, {"c"}}, []int{1, 2, 0}),
+			want:     []string{"a", "b"},
+			wantErr:  "stopped after 2 pages: too many pages",
+		},
+		{
+			name:    "error on a page",
+			fetch:   pages([][]string{{"a"}}, []int{1}),
+			want:    []string{"a"},
+			wantErr: "no such page",
+		},
+		{
+			name:    "context canceled",
+			fetch:   pages([][]string{{"a"}}, []int{0}),
+			cancel:  true,
+			want:    []string{},
+			wantErr: context.Canceled.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			got, err := Paginate(ctx, tt.maxPages, tt.fetch)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestPaginateTooManyPages(t *testing.T) {
+	_, err := Paginate(context.Background(), 1, func(page int) ([]int, int, error) {
+		return []int{page}, page + 1, nil
+	})
+	assert.Assert(t, errors.Is(err, ErrTooManyPages))
+}
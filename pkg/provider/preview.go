@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// PreviewEnvironmentInterface is implemented by the providers able to track
+// the preview environment of a pull request, as a GitHub deployment or a
+// GitLab environment.
+type PreviewEnvironmentInterface interface {
+	// CreatePreviewEnvironment creates or updates the preview environment of
+	// the pull request to point to url.
+	CreatePreviewEnvironment(ctx context.Context, event *info.Event, url, detailsURL string) error
+	// DeactivatePreviewEnvironment marks the preview environment of the pull
+	// request as inactive, when it has been closed.
+	DeactivatePreviewEnvironment(ctx context.Context, event *info.Event) error
+}
+
+// PreviewEnvironmentName returns the name of the preview environment of a
+// pull request.
+func PreviewEnvironmentName(event *info.Event) string {
+	return fmt.Sprintf("preview/pr-%d", event.PullRequestNumber)
+}
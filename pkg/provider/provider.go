@@ -5,6 +5,9 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 )
 
 var (
@@ -13,6 +16,9 @@ var (
 	oktotestRegex         = regexp.MustCompile(`(?m)^/ok-to-test\s*$`)
 	cancelAllRegex        = regexp.MustCompile(`(?m)^(/cancel)\s*$`)
 	cancelSingleRegex     = regexp.MustCompile(`(?m)^(/cancel)[ \t]+\S+`)
+	lintRegex             = regexp.MustCompile(`(?m)^/lint\s*$`)
+	pauseResumeRegex      = regexp.MustCompile(`(?m)^(/pause|/resume)\s*$`)
+	freezeRegex           = regexp.MustCompile(`(?m)^(/freeze|/unfreeze)\s*$`)
 )
 
 const (
@@ -34,6 +40,20 @@ func Valid(value string, validValues []string) bool {
 	return false
 }
 
+// IsDeletedRef returns true if the SHA a ref has been pushed to is the null
+// SHA, which is what the providers send when a branch or a tag is deleted.
+func IsDeletedRef(after string) bool {
+	return after != "" && strings.Trim(after, "0") == ""
+}
+
+// PushedTag returns the name of the tag a push event has created or moved.
+func PushedTag(event *info.Event) (string, bool) {
+	if event.TriggerTarget != triggertype.Push || event.BranchDeleted {
+		return "", false
+	}
+	return strings.CutPrefix(event.BaseBranch, "refs/tags/")
+}
+
 func IsTestRetestComment(comment string) bool {
 	return testRetestSingleRegex.MatchString(comment) || testRetestAllRegex.MatchString(comment)
 }
@@ -46,6 +66,18 @@ func IsCancelComment(comment string) bool {
 	return cancelAllRegex.MatchString(comment) || cancelSingleRegex.MatchString(comment)
 }
 
+func IsLintComment(comment string) bool {
+	return lintRegex.MatchString(comment)
+}
+
+func IsPauseResumeComment(comment string) bool {
+	return pauseResumeRegex.MatchString(comment)
+}
+
+func IsFreezeComment(comment string) bool {
+	return freezeRegex.MatchString(comment)
+}
+
 func GetPipelineRunFromTestComment(comment string) string {
 	if strings.Contains(comment, testComment) {
 		return getNameFromComment(testComment, comment)
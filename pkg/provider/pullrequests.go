@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// MaxRebuildBatchInterval is the longest pause between two batches of pull
+// requests of the rebuild_on_base_update setting.
+const MaxRebuildBatchInterval = 5 * time.Minute
+
+// PullRequest is an open pull request of the repository of an event.
+type PullRequest struct {
+	Number     int
+	Title      string
+	Labels     []string
+	SHA        string
+	HeadBranch string
+	HeadURL    string
+	BaseURL    string
+	Author     string
+	UpdatedAt  time.Time
+	// SourceProjectID is the project of the source branch on GitLab.
+	SourceProjectID int
+}
+
+// OpenPullRequestsInterface is implemented by the providers able to list the
+// open pull requests of a repository.
+type OpenPullRequestsInterface interface {
+	// ListOpenPullRequests lists the open pull requests of the repository of
+	// the event targeting the base branch, the most recently updated first.
+	ListOpenPullRequests(ctx context.Context, event *info.Event, baseBranch string, limit int) ([]PullRequest, error)
+}
+
+// ValidateRebuildOnBaseUpdate validates the rebuild_on_base_update setting of
+// a Repository.
+func ValidateRebuildOnBaseUpdate(settings *v1alpha1.Settings) error {
+	if settings == nil || settings.RebuildOnBaseUpdate == nil {
+		return nil
+	}
+	rebuild := settings.RebuildOnBaseUpdate
+	if len(rebuild.PipelineRuns) == 0 {
+		return fmt.Errorf("rebuild_on_base_update needs the names of the pipelineruns to re-run")
+	}
+	if rebuild.BatchInterval != "" {
+		if d, err := time.ParseDuration(rebuild.BatchInterval); err != nil || d < 0 {
+			return fmt.Errorf("invalid batch_interval %q of rebuild_on_base_update, it needs to be a duration like 30s", rebuild.BatchInterval)
+		} else if d > MaxRebuildBatchInterval {
+			return fmt.Errorf("invalid batch_interval %q of rebuild_on_base_update, it needs to be at most %s", rebuild.BatchInterval, MaxRebuildBatchInterval)
+		}
+	}
+	for _, branch := range rebuild.Branches {
+		// matcher.BranchMatch panics on an invalid glob
+		if _, err := glob.Compile(branch); err != nil {
+			return fmt.Errorf("invalid branch glob %q of rebuild_on_base_update: %w", branch, err)
+		}
+	}
+	return nil
+}
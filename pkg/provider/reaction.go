@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// Reaction is an emoji reaction acknowledging a GitOps comment, each provider
+// maps it to its own reaction name.
+type Reaction string
+
+const (
+	// ReactionRocket acknowledges a comment running PipelineRuns.
+	ReactionRocket Reaction = "rocket"
+	// ReactionThumbsUp acknowledges the other commands.
+	ReactionThumbsUp Reaction = "thumbsup"
+)
+
+// CommentReactionInterface is implemented by the providers able to react to
+// the comment which has triggered the event.
+type CommentReactionInterface interface {
+	// AddCommentReaction adds the reaction to the comment of the event.
+	AddCommentReaction(ctx context.Context, event *info.Event, reaction Reaction) error
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/httpclient"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+)
+
+// retryBackoff is the wait before the first retry, doubled at each retry.
+var retryBackoff = 250 * time.Millisecond
+
+// retryTransport retries the reads of the provider API, the GET and HEAD
+// requests, failing on a network error or on a gateway error of the server.
+// The writes are never retried, they may have been applied.
+type retryTransport struct {
+	next     http.RoundTripper
+	retries  int
+	provider string
+}
+
+func newRetryTransport(next http.RoundTripper, retries int, provider string) http.RoundTripper {
+	if retries <= 0 {
+		return next
+	}
+	return &retryTransport{next: next, retries: retries, provider: provider}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.next.RoundTrip(req)
+	}
+	wait := retryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt == t.retries || !retryable(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		metrics.CountProviderRetry(t.provider, operationFrom(req.Context()))
+		wait *= 2
+	}
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		// a denied host stays denied
+		var denied *httpclient.EgressDeniedError
+		return !errors.As(err, &denied)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/httpclient"
+	"gotest.tools/v3/assert"
+)
+
+func TestRetryTransport(t *testing.T) {
+	retryBackoff = time.Millisecond
+	tests := []struct {
+		name       string
+		method     string
+		retries    int
+		statuses   []int
+		wantStatus int
+		wantCalls  int
+	}{
+		{
+			name:       "read retried until it succeeds",
+			method:     http.MethodGet,
+			retries:    2,
+			statuses:   []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+		},
+		{
+			name:       "read retried until the last retry",
+			method:     http.MethodGet,
+			retries:    1,
+			statuses:   []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusOK},
+			wantStatus: http.StatusGatewayTimeout,
+			wantCalls:  2,
+		},
+		{
+			name:       "client error not retried",
+			method:     http.MethodGet,
+			retries:    2,
+			statuses:   []int{http.StatusNotFound, http.StatusOK},
+			wantStatus: http.StatusNotFound,
+			wantCalls:  1,
+		},
+		{
+			name:       "write not retried",
+			method:     http.MethodPost,
+			retries:    2,
+			statuses:   []int{http.StatusBadGateway, http.StatusOK},
+			wantStatus: http.StatusBadGateway,
+			wantCalls:  1,
+		},
+		{
+			name:       "retries disabled",
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusBadGateway, http.StatusOK},
+			wantStatus: http.StatusBadGateway,
+			wantCalls:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, tt.retries, "github")}
+			req, err := http.NewRequest(tt.method, server.URL, strings.NewReader(""))
+			assert.NilError(t, err)
+			resp, err := client.Do(req)
+			assert.NilError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, resp.StatusCode, tt.wantStatus)
+			assert.Equal(t, calls, tt.wantCalls)
+		})
+	}
+}
+
+func TestRetryTransportEgressDenied(t *testing.T) {
+	calls := 0
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return nil, &httpclient.EgressDeniedError{Host: req.URL.Host}
+	})
+	req := httptest.NewRequest(http.MethodGet, "https://denied.example.com/", nil)
+	_, err := newRetryTransport(next, 3, "github").RoundTrip(req)
+	assert.ErrorContains(t, err, "denied")
+	assert.Equal(t, calls, 1)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
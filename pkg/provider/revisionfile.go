@@ -0,0 +1,16 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// RevisionFileInterface is implemented by the providers able to get a file of
+// the repository of the event at a given revision, unlike GetFileInsideRepo
+// which follows the provenance of the PipelineRun definitions.
+type RevisionFileInterface interface {
+	// GetFileAtRevision returns the content of the file at the revision, a
+	// commit SHA or a branch.
+	GetFileAtRevision(ctx context.Context, event *info.Event, path, revision string) (string, error)
+}
@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+)
+
+// SenderRoleInterface is implemented by the providers able to resolve the
+// role of the sender of an event on its repository.
+type SenderRoleInterface interface {
+	// SenderRole returns the role of the sender of the event on the
+	// repository, on a comment the sender is the commenter.
+	SenderRole(ctx context.Context, event *info.Event) (info.Role, error)
+}
+
+// ResolveSenderRole returns the role of the sender of the event on the
+// repository, resolved once by the provider and kept on the event. The role is
+// none with the providers not able to resolve it.
+func ResolveSenderRole(ctx context.Context, vcx Interface, event *info.Event) (info.Role, error) {
+	if event.SenderRole != "" {
+		return event.SenderRole, nil
+	}
+	resolver, ok := vcx.(SenderRoleInterface)
+	if !ok {
+		return info.RoleNone, nil
+	}
+	role, err := resolver.SenderRole(ctx, event)
+	if err != nil {
+		return info.RoleNone, err
+	}
+	event.SenderRole = role
+	return role, nil
+}
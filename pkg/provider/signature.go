@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	pgpSignaturePacket      = 2
+	pgpIssuerSubpacket      = 16
+	pgpFingerprintSubpacket = 33
+	sshSignatureMagic       = "SSHSIG"
+)
+
+// CommitSignatureFromVerification returns the signature of a commit from the
+// verification of the git provider and the armored signature, the key ID is
+// read from the signature.
+func CommitSignatureFromVerification(verified bool, signature string) info.CommitSignature {
+	if strings.TrimSpace(signature) == "" {
+		return info.CommitSignature{Status: info.SignatureUnsigned}
+	}
+	status := info.SignatureUnverified
+	if verified {
+		status = info.SignatureVerified
+	}
+	return info.CommitSignature{Status: status, KeyID: SignatureKeyID(signature)}
+}
+
+// SignatureKeyID returns the ID of the key of an armored GPG or SSH
+// signature, empty when it cannot be parsed.
+func SignatureKeyID(signature string) string {
+	data, kind := dearmor(signature)
+	switch kind {
+	case "PGP SIGNATURE":
+		return pgpKeyID(data)
+	case "SSH SIGNATURE":
+		return sshKeyID(data)
+	}
+	return ""
+}
+
+// dearmor decodes the first armored block of the text, returning its data
+// and its kind, ie: PGP SIGNATURE.
+func dearmor(text string) ([]byte, string) {
+	kind := ""
+	inHeaders := false
+	body := strings.Builder{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case kind == "":
+			if k, ok := strings.CutPrefix(line, "-----BEGIN "); ok {
+				kind = strings.TrimSuffix(k, "-----")
+				inHeaders = kind == "PGP SIGNATURE"
+			}
+		case strings.HasPrefix(line, "-----END "):
+			data, err := base64.StdEncoding.DecodeString(body.String())
+			if err != nil {
+				return nil, ""
+			}
+			return data, kind
+		case inHeaders:
+			// the armor headers of a PGP block end with an empty line
+			if line == "" || !strings.Contains(line, ": ") {
+				inHeaders = false
+				body.WriteString(line)
+			}
+		case strings.HasPrefix(line, "="):
+			// the checksum of a PGP block
+		default:
+			body.WriteString(line)
+		}
+	}
+	return nil, ""
+}
+
+// pgpKeyID returns the long ID of the key of the first signature packet.
+func pgpKeyID(data []byte) string {
+	for len(data) > 0 {
+		tag, body, rest, err := pgpPacket(data)
+		if err != nil {
+			return ""
+		}
+		if tag == pgpSignaturePacket {
+			return pgpSignatureKeyID(body)
+		}
+		data = rest
+	}
+	return ""
+}
+
+// pgpPacket splits the first packet of the data, returning its tag and body.
+func pgpPacket(data []byte) (byte, []byte, []byte, error) {
+	if len(data) < 2 || data[0]&0x80 == 0 {
+		return 0, nil, nil, fmt.Errorf("invalid packet header")
+	}
+	var tag byte
+	var length, offset int
+	if data[0]&0x40 != 0 {
+		// new format
+		tag = data[0] & 0x3f
+		switch first := int(data[1]); {
+		case first < 192:
+			length, offset = first, 2
+		case first < 224 && len(data) >= 3:
+			length, offset = (first-192)<<8+int(data[2])+192, 3
+		case first == 255 && len(data) >= 6:
+			length, offset = int(binary.BigEndian.Uint32(data[2:6])), 6
+		default:
+			return 0, nil, nil, fmt.Errorf("unsupported packet length")
+		}
+	} else {
+		// old format
+		tag = (data[0] >> 2) & 0x0f
+		switch data[0] & 0x03 {
+		case 0:
+			length, offset = int(data[1]), 2
+		case 1:
+			if len(data) < 3 {
+				return 0, nil, nil, fmt.Errorf("truncated packet header")
+			}
+			length, offset = int(binary.BigEndian.Uint16(data[1:3])), 3
+		case 2:
+			if len(data) < 5 {
+				return 0, nil, nil, fmt.Errorf("truncated packet header")
+			}
+			length, offset = int(binary.BigEndian.Uint32(data[1:5])), 5
+		default:
+			length, offset = len(data)-1, 1
+		}
+	}
+	if length < 0 || offset+length > len(data) {
+		return 0, nil, nil, fmt.Errorf("truncated packet")
+	}
+	return tag, data[offset : offset+length], data[offset+length:], nil
+}
+
+// pgpSignatureKeyID returns the key ID of a signature packet, from the issuer
+// subpacket or the issuer fingerprint one on the version 4 signatures.
+func pgpSignatureKeyID(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	switch body[0] {
+	case 3:
+		if len(body) < 15 {
+			return ""
+		}
+		return fmt.Sprintf("%X", body[7:15])
+	case 4:
+		if len(body) < 6 {
+			return ""
+		}
+		hashedLength := int(binary.BigEndian.Uint16(body[4:6]))
+		if 6+hashedLength+2 > len(body) {
+			return ""
+		}
+		hashed := body[6 : 6+hashedLength]
+		unhashedLength := int(binary.BigEndian.Uint16(body[6+hashedLength:]))
+		unhashed := body[6+hashedLength+2:]
+		if unhashedLength > len(unhashed) {
+			return ""
+		}
+		return pgpIssuer(append(hashed[:len(hashed):len(hashed)], unhashed[:unhashedLength]...))
+	}
+	return ""
+}
+
+// pgpIssuer returns the key ID of the issuer subpackets.
+func pgpIssuer(subpackets []byte) string {
+	keyID := ""
+	for len(subpackets) > 0 {
+		var length, offset int
+		switch first := int(subpackets[0]); {
+		case first < 192:
+			length, offset = first, 1
+		case first < 255 && len(subpackets) >= 2:
+			length, offset = (first-192)<<8+int(subpackets[1])+192, 2
+		case first == 255 && len(subpackets) >= 5:
+			length, offset = int(binary.BigEndian.Uint32(subpackets[1:5])), 5
+		default:
+			return keyID
+		}
+		if length == 0 || offset+length > len(subpackets) {
+			return keyID
+		}
+		kind, data := subpackets[offset]&0x7f, subpackets[offset+1:offset+length]
+		switch {
+		case kind == pgpIssuerSubpacket && len(data) == 8:
+			return fmt.Sprintf("%X", data)
+		case kind == pgpFingerprintSubpacket && len(data) == 21 && data[0] == 4:
+			// the key ID of a version 4 key is the end of its fingerprint
+			keyID = fmt.Sprintf("%X", data[13:])
+		}
+		subpackets = subpackets[offset+length:]
+	}
+	return keyID
+}
+
+// sshKeyID returns the SHA256 fingerprint of the public key of an SSH
+// signature.
+func sshKeyID(data []byte) string {
+	rest, ok := bytes.CutPrefix(data, []byte(sshSignatureMagic))
+	if !ok || len(rest) < 8 {
+		return ""
+	}
+	// the version is followed by the public key
+	rest = rest[4:]
+	length := int(binary.BigEndian.Uint32(rest))
+	if 4+length > len(rest) {
+		return ""
+	}
+	key, err := ssh.ParsePublicKey(rest[4 : 4+length])
+	if err != nil {
+		return ""
+	}
+	return ssh.FingerprintSHA256(key)
+}
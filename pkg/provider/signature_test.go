@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"gotest.tools/v3/assert"
+)
+
+const (
+	gpgSignature = `-----BEGIN PGP SIGNATURE-----
+
+iHUEABYIAB0WIQQCYluRjJCRxvzzld5qDJG0h1PFjAUCatJdBwAKCRBqDJG0h1PF
+jKsOAP9WV1kbtCMzsz+AXNybZ1nBFilkZmwuqW581TeCjzTTsgEAygXkhT4PmseI
+Ea4FQKLq9mT0GTuqIa0O6oSiRQDvnAM=
+=z7Uy
+-----END PGP SIGNATURE-----
+`
+	sshSignature = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAgU0gYuzB/oAJkYLen/kZbg7ZbA0
+Zq9lKa310jG7x8SWAAAAADZ2l0AAAAAAAAAAZzaGE1MTIAAABTAAAAC3NzaC1lZDI1NTE5
+AAAAQHar/0teBLNQJJXs6rIzZyt6TnveUaQZXZ5bcacC5gurLEzd/InQaVLNtK+b1uIXma
+xhjoU+aNGXV7HnsmNRAwM=
+-----END SSH SIGNATURE-----
+`
+)
+
+func TestSignatureKeyID(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+		want      string
+	}{
+		{
+			name:      "gpg signature",
+			signature: gpgSignature,
+			want:      "6A0C91B48753C58C",
+		},
+		{
+			name:      "ssh signature",
+			signature: sshSignature,
+			want:      "SHA256:zNghUkztfISnTg3TcUNzTVNuYbffCNwCuem20+t5VVE",
+		},
+		{
+			name:      "x509 signature",
+			signature: "-----BEGIN SIGNED MESSAGE-----\nMIAGCSqGSIb3DQEHAqCAMIACAQExDTAL\n-----END SIGNED MESSAGE-----\n",
+		},
+		{
+			name:      "truncated signature",
+			signature: "-----BEGIN PGP SIGNATURE-----\n\niHUEABYIAB0WIQQCYluRjJCR\n-----END PGP SIGNATURE-----\n",
+		},
+		{
+			name:      "not a signature",
+			signature: "hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, SignatureKeyID(tt.signature), tt.want)
+		})
+	}
+}
+
+func TestCommitSignatureFromVerification(t *testing.T) {
+	assert.DeepEqual(t, CommitSignatureFromVerification(true, gpgSignature),
+		info.CommitSignature{Status: info.SignatureVerified, KeyID: "6A0C91B48753C58C"})
+	assert.DeepEqual(t, CommitSignatureFromVerification(false, sshSignature),
+		info.CommitSignature{Status: info.SignatureUnverified, KeyID: "SHA256:zNghUkztfISnTg3TcUNzTVNuYbffCNwCuem20+t5VVE"})
+	assert.DeepEqual(t, CommitSignatureFromVerification(false, ""), info.CommitSignature{Status: info.SignatureUnsigned})
+}
@@ -0,0 +1,17 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// StatusURLInterface is implemented by the providers able to link the status
+// they have created for a PipelineRun, like a check run or a merge request
+// note.
+type StatusURLInterface interface {
+	// StatusURL returns the URL of the status created for the PipelineRun,
+	// empty when the provider has no page for it.
+	StatusURL(ctx context.Context, event *info.Event, pr *tektonv1.PipelineRun) (string, error)
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+)
+
+// DefaultTektonDir is the directory of the repository where the PipelineRuns
+// are defined when the Repository doesn't set pipelinerun_dirs.
+const DefaultTektonDir = ".tekton"
+
+// DefaultPipelineRunExtensions are the extensions of the files read as
+// PipelineRuns when the Repository doesn't set pipelinerun_extensions.
+var DefaultPipelineRunExtensions = []string{".yaml", ".yml"}
+
+// PipelineRunDirs returns the directories of the repository where the
+// PipelineRuns of the Repository are defined.
+func PipelineRunDirs(repo *v1alpha1.Repository) []string {
+	if repo == nil || repo.Spec.Settings == nil || len(repo.Spec.Settings.PipelineRunDirs) == 0 {
+		return []string{DefaultTektonDir}
+	}
+	dirs := []string{}
+	for _, dir := range repo.Spec.Settings.PipelineRunDirs {
+		if dir = strings.Trim(path.Clean(dir), "/"); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// PipelineRunExtensions returns the extensions of the files read as
+// PipelineRuns for the Repository.
+func PipelineRunExtensions(repo *v1alpha1.Repository) []string {
+	if repo == nil || repo.Spec.Settings == nil || len(repo.Spec.Settings.PipelineRunExtensions) == 0 {
+		return DefaultPipelineRunExtensions
+	}
+	return repo.Spec.Settings.PipelineRunExtensions
+}
+
+// IsPipelineRunFile returns true if the file has one of the extensions of
+// the files read as PipelineRuns for the Repository.
+func IsPipelineRunFile(repo *v1alpha1.Repository, fname string) bool {
+	for _, ext := range PipelineRunExtensions(repo) {
+		if strings.HasSuffix(fname, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePipelineRunDirs checks the pipelinerun_dirs and the
+// pipelinerun_extensions settings of a Repository.
+func ValidatePipelineRunDirs(settings *v1alpha1.Settings) error {
+	if settings == nil {
+		return nil
+	}
+	for _, dir := range settings.PipelineRunDirs {
+		cleaned := path.Clean(dir)
+		if dir == "" || path.IsAbs(dir) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return fmt.Errorf("invalid pipelinerun_dirs %q, it needs to be a directory relative to the root of the repository", dir)
+		}
+	}
+	for _, ext := range settings.PipelineRunExtensions {
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 || strings.Contains(ext, "/") {
+			return fmt.Errorf("invalid pipelinerun_extensions %q, it needs to start with a dot, ie: .yaml", ext)
+		}
+	}
+	return nil
+}
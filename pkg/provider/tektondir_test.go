@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"gotest.tools/v3/assert"
+)
+
+func TestPipelineRunDirs(t *testing.T) {
+	assert.DeepEqual(t, PipelineRunDirs(nil), []string{".tekton"})
+	assert.DeepEqual(t, PipelineRunDirs(&v1alpha1.Repository{}), []string{".tekton"})
+	repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{
+		PipelineRunDirs: []string{".ci/", "ci/tekton", "./ci/tekton"},
+	}}}
+	assert.DeepEqual(t, PipelineRunDirs(repo), []string{".ci", "ci/tekton"})
+}
+
+func TestIsPipelineRunFile(t *testing.T) {
+	assert.Assert(t, IsPipelineRunFile(nil, ".tekton/pr.yaml"))
+	assert.Assert(t, IsPipelineRunFile(nil, ".tekton/pr.yml"))
+	assert.Assert(t, !IsPipelineRunFile(nil, ".tekton/README.md"))
+	repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{
+		PipelineRunExtensions: []string{".tekton.yaml"},
+	}}}
+	assert.Assert(t, IsPipelineRunFile(repo, "ci/pr.tekton.yaml"))
+	assert.Assert(t, !IsPipelineRunFile(repo, "ci/values.yaml"))
+}
+
+func TestValidatePipelineRunDirs(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *v1alpha1.Settings
+		wantErr  string
+	}{
+		{
+			name: "no settings",
+		},
+		{
+			name:     "valid",
+			settings: &v1alpha1.Settings{PipelineRunDirs: []string{".ci", "ci/tekton/"}, PipelineRunExtensions: []string{".yaml"}},
+		},
+		{
+			name:     "absolute dir",
+			settings: &v1alpha1.Settings{PipelineRunDirs: []string{"/ci"}},
+			wantErr:  `invalid pipelinerun_dirs "/ci", it needs to be a directory relative to the root of the repository`,
+		},
+		{
+			name:     "dir outside of the repository",
+			settings: &v1alpha1.Settings{PipelineRunDirs: []string{"ci/../../tekton"}},
+			wantErr:  `invalid pipelinerun_dirs "ci/../../tekton", it needs to be a directory relative to the root of the repository`,
+		},
+		{
+			name:     "root of the repository",
+			settings: &v1alpha1.Settings{PipelineRunDirs: []string{"."}},
+			wantErr:  `invalid pipelinerun_dirs ".", it needs to be a directory relative to the root of the repository`,
+		},
+		{
+			name:     "extension without a dot",
+			settings: &v1alpha1.Settings{PipelineRunExtensions: []string{"yaml"}},
+			wantErr:  `invalid pipelinerun_extensions "yaml", it needs to start with a dot, ie: .yaml`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePipelineRunDirs(tt.settings)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
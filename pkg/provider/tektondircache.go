@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+const (
+	// TektonDirCacheTTL is how long the PipelineRun files of a commit are
+	// cached, the files of a commit never change, it only bounds the memory.
+	TektonDirCacheTTL = time.Hour
+	// TektonDirCacheMaxCommits is the maximum number of commits cached for a
+	// repository directory, the oldest one is dropped first.
+	TektonDirCacheMaxCommits = 16
+)
+
+type tektonDirEntry struct {
+	files   map[string]string
+	added   time.Time
+	expires time.Time
+}
+
+// TektonDirCache caches the content of the PipelineRun files of a directory
+// of a repository at a commit. A pull request which only changes a few of
+// these files since its merge base can then fetch only the changed ones.
+type TektonDirCache struct {
+	mu         sync.Mutex
+	clock      clockwork.Clock
+	ttl        time.Duration
+	maxCommits int
+	entries    map[string]map[string]tektonDirEntry
+}
+
+func NewTektonDirCache(clock clockwork.Clock, ttl time.Duration, maxCommits int) *TektonDirCache {
+	return &TektonDirCache{
+		clock:      clock,
+		ttl:        ttl,
+		maxCommits: maxCommits,
+		entries:    map[string]map[string]tektonDirEntry{},
+	}
+}
+
+// TektonDirCacheKey builds the key of a repository directory from its parts,
+// ie: the api url, the repository, the directory and the extensions.
+func TektonDirCacheKey(parts ...string) string {
+	return strings.Join(parts, "|")
+}
+
+// Has returns true if some commits of the repository directory are cached. A
+// nil cache never has any entry.
+func (c *TektonDirCache) Has(key string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	for _, entry := range c.entries[key] {
+		if now.Before(entry.expires) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns a copy of the files, by path relative to the directory, of the
+// repository directory at the commit sha.
+func (c *TektonDirCache) Get(key, sha string) (map[string]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key][sha]
+	if !ok || !c.clock.Now().Before(entry.expires) {
+		return nil, false
+	}
+	files := make(map[string]string, len(entry.files))
+	for k, v := range entry.files {
+		files[k] = v
+	}
+	return files, true
+}
+
+// Set caches the files of the repository directory at the commit sha, it does
+// nothing on a nil cache.
+func (c *TektonDirCache) Set(key, sha string, files map[string]string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	// drop the expired entries so the cache doesn't grow forever
+	for k, commits := range c.entries {
+		for s, entry := range commits {
+			if !now.Before(entry.expires) {
+				delete(commits, s)
+			}
+		}
+		if len(commits) == 0 {
+			delete(c.entries, k)
+		}
+	}
+	commits, ok := c.entries[key]
+	if !ok {
+		commits = map[string]tektonDirEntry{}
+		c.entries[key] = commits
+	}
+	if _, ok := commits[sha]; !ok && len(commits) >= c.maxCommits {
+		oldest := ""
+		for s, entry := range commits {
+			if oldest == "" || entry.added.Before(commits[oldest].added) {
+				oldest = s
+			}
+		}
+		delete(commits, oldest)
+	}
+	copied := make(map[string]string, len(files))
+	for k, v := range files {
+		copied[k] = v
+	}
+	commits[sha] = tektonDirEntry{files: copied, added: now, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"gotest.tools/v3/assert"
+)
+
+func TestTektonDirCache(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	cache := NewTektonDirCache(clock, time.Hour, 2)
+	key := TektonDirCacheKey("https://api.github.com", "org/repo", ".tekton", ".yaml,.yml")
+
+	assert.Assert(t, !cache.Has(key))
+	_, ok := cache.Get(key, "sha1")
+	assert.Assert(t, !ok)
+
+	files := map[string]string{"pr.yaml": "kind: PipelineRun"}
+	cache.Set(key, "sha1", files)
+	assert.Assert(t, cache.Has(key))
+	got, ok := cache.Get(key, "sha1")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, got, files)
+
+	// the cached files are not changed by the callers
+	got["push.yaml"] = "kind: PipelineRun"
+	files["other.yaml"] = "kind: PipelineRun"
+	got, _ = cache.Get(key, "sha1")
+	assert.DeepEqual(t, got, map[string]string{"pr.yaml": "kind: PipelineRun"})
+
+	// the oldest commit is dropped when there are too many
+	clock.Advance(time.Minute)
+	cache.Set(key, "sha2", map[string]string{})
+	clock.Advance(time.Minute)
+	cache.Set(key, "sha3", map[string]string{})
+	_, ok = cache.Get(key, "sha1")
+	assert.Assert(t, !ok)
+	_, ok = cache.Get(key, "sha3")
+	assert.Assert(t, ok)
+
+	clock.Advance(time.Hour)
+	assert.Assert(t, !cache.Has(key))
+	_, ok = cache.Get(key, "sha3")
+	assert.Assert(t, !ok)
+}
+
+func TestTektonDirCacheExpiredEntriesDropped(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	cache := NewTektonDirCache(clock, time.Minute, 2)
+	for i := 0; i < 5; i++ {
+		cache.Set(TektonDirCacheKey("repo", fmt.Sprint(i)), "sha", map[string]string{})
+	}
+	clock.Advance(time.Minute)
+	cache.Set(TektonDirCacheKey("repo", "new"), "sha", map[string]string{})
+	assert.Equal(t, len(cache.entries), 1)
+}
+
+func TestTektonDirCacheNil(t *testing.T) {
+	var cache *TektonDirCache
+	cache.Set("key", "sha", map[string]string{})
+	assert.Assert(t, !cache.Has("key"))
+	_, ok := cache.Get("key", "sha")
+	assert.Assert(t, !ok)
+}
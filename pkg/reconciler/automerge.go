@@ -0,0 +1,83 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/policy"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// autoMergeTrustedBot merges the pull request of a trusted bot when the
+// repository policy allows it, the title is a patch or minor version update
+// and the latest PipelineRun of each template for the SHA has succeeded.
+func (r *Reconciler) autoMergeTrustedBot(ctx context.Context, logger *zap.SugaredLogger, repo *v1alpha1.Repository, event *info.Event, vcx provider.Interface) {
+	if event.TriggerTarget != triggertype.PullRequest || event.PullRequestNumber == 0 {
+		return
+	}
+	if !policy.CanAutoMerge(repo, event.Sender, event.SHATitle) {
+		return
+	}
+	merger, ok := vcx.(provider.PullRequestMergeInterface)
+	if !ok {
+		logger.Infof("provider %s cannot merge the pull request of the trusted bot %s", vcx.GetConfig().Name, event.Sender)
+		return
+	}
+
+	succeeded, err := r.allPipelineRunsSucceeded(ctx, repo, event.SHA)
+	if err != nil {
+		logger.Errorf("cannot list the pipelineruns of %s for the auto merge: %v", event.SHA, err)
+		return
+	}
+	if !succeeded {
+		return
+	}
+
+	if err := merger.MergePullRequest(ctx, event); err != nil {
+		r.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "PolicyAutoMergeFailed",
+			fmt.Sprintf("cannot merge the pull request %d of the trusted bot %s: %s", event.PullRequestNumber, event.Sender, err.Error()))
+		return
+	}
+	r.eventEmitter.EmitMessage(repo, zap.InfoLevel, "PolicyAutoMerged",
+		fmt.Sprintf("merged the pull request %d of the trusted bot %s: %s", event.PullRequestNumber, event.Sender, event.SHATitle))
+}
+
+// allPipelineRunsSucceeded returns true if the latest PipelineRun of each
+// template run on the SHA for the repository is done and has succeeded.
+func (r *Reconciler) allPipelineRunsSucceeded(ctx context.Context, repo *v1alpha1.Repository, sha string) (bool, error) {
+	labelSelector := fmt.Sprintf("%s=%s,%s=%s",
+		keys.SHA, formatting.CleanValueKubernetes(sha),
+		keys.Repository, formatting.CleanValueKubernetes(repo.GetName()))
+	pruns, err := r.run.Clients.Tekton.TektonV1().PipelineRuns(repo.GetNamespace()).List(ctx,
+		metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return false, err
+	}
+	if len(pruns.Items) == 0 {
+		return false, nil
+	}
+
+	latest := map[string]tektonv1.PipelineRun{}
+	for _, prun := range pruns.Items {
+		name := prun.GetLabels()[keys.OriginalPRName]
+		if current, ok := latest[name]; ok && !current.CreationTimestamp.Before(&prun.CreationTimestamp) {
+			continue
+		}
+		latest[name] = prun
+	}
+	for _, prun := range latest {
+		if !prun.IsDone() || !prun.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,174 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektontest "github.com/openshift-pipelines/pipelines-as-code/pkg/test/tekton"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+type mergingProvider struct {
+	tprovider.TestProviderImp
+	merged bool
+	err    error
+}
+
+func (m *mergingProvider) MergePullRequest(_ context.Context, _ *info.Event) error {
+	m.merged = true
+	return m.err
+}
+
+func TestAutoMergeTrustedBot(t *testing.T) {
+	ns := "namespace"
+	sha := "sha"
+	clock := clockwork.NewFakeClock()
+	makePR := func(name, original, status string, created int) *tektonv1.PipelineRun {
+		labels := map[string]string{
+			keys.SHA:            sha,
+			keys.Repository:     "repo",
+			keys.OriginalPRName: original,
+		}
+		pr := tektontest.MakePRCompletion(clock, name, ns, status, nil, labels, 0)
+		pr.CreationTimestamp = metav1.Time{Time: clock.Now().Add(time.Duration(created) * time.Minute)}
+		return pr
+	}
+
+	tests := []struct {
+		name         string
+		sender       string
+		title        string
+		autoMerge    bool
+		pipelineRuns []*tektonv1.PipelineRun
+		mergeErr     error
+		wantMerged   bool
+		wantLog      string
+	}{
+		{
+			name:       "all succeeded",
+			sender:     "dependabot[bot]",
+			title:      "Bump golang.org/x/net from 0.22.0 to 0.23.0",
+			autoMerge:  true,
+			wantMerged: true,
+			wantLog:    "merged the pull request 42 of the trusted bot dependabot[bot]",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				makePR("build-1", "build", "", 0),
+				makePR("test-1", "test", "", 0),
+			},
+		},
+		{
+			name:       "latest run of a failed pipelinerun succeeded",
+			sender:     "dependabot[bot]",
+			title:      "Bump golang.org/x/net from 0.22.0 to 0.23.0",
+			autoMerge:  true,
+			wantMerged: true,
+			pipelineRuns: []*tektonv1.PipelineRun{
+				makePR("build-1", "build", "Failed", 0),
+				makePR("build-2", "build", "", 1),
+			},
+		},
+		{
+			name:      "a pipelinerun failed",
+			sender:    "dependabot[bot]",
+			title:     "Bump golang.org/x/net from 0.22.0 to 0.23.0",
+			autoMerge: true,
+			pipelineRuns: []*tektonv1.PipelineRun{
+				makePR("build-1", "build", "", 0),
+				makePR("test-1", "test", "Failed", 0),
+			},
+		},
+		{
+			name:      "major update",
+			sender:    "dependabot[bot]",
+			title:     "Bump github.com/google/go-github from 60.0.0 to 61.0.0",
+			autoMerge: true,
+			pipelineRuns: []*tektonv1.PipelineRun{
+				makePR("build-1", "build", "", 0),
+			},
+		},
+		{
+			name:   "auto merge disabled",
+			sender: "dependabot[bot]",
+			title:  "Bump golang.org/x/net from 0.22.0 to 0.23.0",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				makePR("build-1", "build", "", 0),
+			},
+		},
+		{
+			name:      "not a trusted bot",
+			sender:    "someone",
+			title:     "Bump golang.org/x/net from 0.22.0 to 0.23.0",
+			autoMerge: true,
+			pipelineRuns: []*tektonv1.PipelineRun{
+				makePR("build-1", "build", "", 0),
+			},
+		},
+		{
+			name:       "merge error",
+			sender:     "dependabot[bot]",
+			title:      "Bump golang.org/x/net from 0.22.0 to 0.23.0",
+			autoMerge:  true,
+			mergeErr:   fmt.Errorf("not mergeable"),
+			wantMerged: true,
+			wantLog:    "cannot merge the pull request 42 of the trusted bot dependabot[bot]: not mergeable",
+			pipelineRuns: []*tektonv1.PipelineRun{
+				makePR("build-1", "build", "", 0),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer, logs := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: tt.pipelineRuns})
+			run := params.New()
+			run.Clients = clients.Clients{Kube: stdata.Kube, Tekton: stdata.Pipeline}
+			r := &Reconciler{
+				run:          run,
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: ns},
+				Spec: v1alpha1.RepositorySpec{
+					Settings: &v1alpha1.Settings{
+						Policy: &v1alpha1.Policy{
+							TrustedBots:          []string{"dependabot[bot]"},
+							AutoMergeTrustedBots: tt.autoMerge,
+						},
+					},
+				},
+			}
+			event := &info.Event{
+				TriggerTarget:     triggertype.PullRequest,
+				PullRequestNumber: 42,
+				Sender:            tt.sender,
+				SHA:               sha,
+				SHATitle:          tt.title,
+			}
+			vcx := &mergingProvider{err: tt.mergeErr}
+			r.autoMergeTrustedBot(ctx, logger, repo, event, vcx)
+			assert.Equal(t, vcx.merged, tt.wantMerged)
+			if tt.wantLog != "" {
+				assert.Equal(t, logs.FilterMessageSnippet(tt.wantLog).Len(), 1, logs.All())
+			}
+		})
+	}
+}
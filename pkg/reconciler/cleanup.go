@@ -41,3 +41,18 @@ func (r *Reconciler) cleanupPipelineRuns(ctx context.Context, logger *zap.Sugare
 	}
 	return nil
 }
+
+// deleteCloudCredentials deletes the secret holding the short-lived cloud
+// credentials of the PipelineRun once it is done, they are not needed anymore
+// and may still be valid for a while.
+func (r *Reconciler) deleteCloudCredentials(ctx context.Context, logger *zap.SugaredLogger, pr *tektonv1.PipelineRun) {
+	secretName, ok := pr.GetAnnotations()[keys.CloudCredentialsSecret]
+	if !ok || secretName == "" {
+		return
+	}
+	if err := r.kinteract.DeleteSecret(ctx, logger, pr.GetNamespace(), secretName); err != nil {
+		logger.Warnf("cannot delete the cloud credentials secret %s/%s: %v", pr.GetNamespace(), secretName, err)
+		return
+	}
+	logger.Infof("deleted the cloud credentials secret %s/%s of the done pipelinerun %s", pr.GetNamespace(), secretName, pr.GetName())
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
 	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
 	tektontest "github.com/openshift-pipelines/pipelines-as-code/pkg/test/tekton"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"go.uber.org/zap"
@@ -176,3 +177,30 @@ func TestCleanupPipelineRuns(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteCloudCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+	}{
+		{
+			name:        "credentials deleted",
+			annotations: map[string]string{keys.CloudCredentialsSecret: "pac-cloudcreds-abcdef"},
+			want:        []string{"namespace/pac-cloudcreds-abcdef"},
+		},
+		{
+			name: "no credentials",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			kint := &kitesthelper.KinterfaceTest{}
+			r := &Reconciler{kinteract: kint}
+			pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "namespace", Annotations: tt.annotations}}
+			r.deleteCloudCredentials(ctx, zap.NewNop().Sugar(), pr)
+			assert.DeepEqual(t, kint.DeletedSecrets, tt.want)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secrets"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+)
+
+// mintCloudCredentials mints the cloud credentials of a queued PipelineRun
+// when it starts, they would have expired while it was waiting. The secret is
+// owned by the PipelineRun to be cleaned up if it is deleted before being
+// done, like the ones minted by the controller. A PipelineRun
+// whose credentials cannot be minted is cancelled, its status is reported
+// once it is done and the queue moves on. It returns false when the
+// PipelineRun has been cancelled.
+func (r *Reconciler) mintCloudCredentials(ctx context.Context, logger *zap.SugaredLogger, repo *v1alpha1.Repository, pr *tektonv1.PipelineRun) bool {
+	secretName := pr.GetAnnotations()[keys.CloudCredentialsSecret]
+	if secretName == "" {
+		return true
+	}
+	pacInfo := r.run.Info.GetPacOpts()
+	runevent := &info.Event{
+		Organization: pr.GetAnnotations()[keys.URLOrg],
+		Repository:   pr.GetAnnotations()[keys.URLRepository],
+		SHA:          pr.GetAnnotations()[keys.SHA],
+	}
+	serviceAccount, err := secrets.MintCloudCredentials(ctx, r.run, r.kinteract, &pacInfo.Settings, repo, pr, runevent, secretName)
+	if err == nil {
+		logger.Infof("created the cloud credentials secret %s/%s for the service account %s", pr.GetNamespace(), secretName, serviceAccount)
+		if err := r.kinteract.UpdateSecretWithOwnerRef(ctx, logger, pr.GetNamespace(), secretName, pr); err != nil {
+			logger.Warnf("cannot update the cloud credentials secret %s/%s with the ownerRef of %s: %v", pr.GetNamespace(), secretName, pr.GetName(), err)
+		}
+		return true
+	}
+	r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "PipelineRunCloudCredentialsFailed",
+		fmt.Sprintf("cannot get the cloud credentials, cancelling the PipelineRun: %v", err))
+	if _, err := action.PatchPipelineRun(ctx, logger, "cloud credentials", r.run.Clients.Tekton, pr, map[string]any{
+		"spec": map[string]any{
+			"status": tektonv1.PipelineRunSpecStatusCancelled,
+		},
+	}); err != nil {
+		logger.Warnf("cannot cancel the pipelinerun %s without cloud credentials: %v", pr.GetName(), err)
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package reconciler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestMintCloudCredentials(t *testing.T) {
+	newPR := func(name, secretName string) *tektonv1.PipelineRun {
+		annotations := map[string]string{keys.State: kubeinteraction.StateQueued}
+		if secretName != "" {
+			annotations[keys.CloudCredentialsSecret] = secretName
+		}
+		return &tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "ns",
+				Annotations: annotations,
+			},
+			Spec: tektonv1.PipelineRunSpec{Status: tektonv1.PipelineRunSpecStatusPending},
+		}
+	}
+	ctx, _ := rtesting.SetupFakeContext(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"short-lived","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	withoutCredentials := newPR("without-credentials", "")
+	// the token url of the Repository is not allowed by the settings
+	notAllowed := newPR("not-allowed", "pac-cloudcreds-abcdef")
+	retry := newPR("pull-request-abcde-retry-1", "pac-cloudcreds-ghijkl")
+	retry.Annotations[keys.RetryOf] = "pull-request-abcde"
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: []*tektonv1.PipelineRun{withoutCredentials, notAllowed, retry}})
+	_, err := stdata.Kube.CoreV1().ServiceAccounts("ns").Create(ctx, &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "ns", Annotations: map[string]string{keys.CloudCredentials: "true"}},
+	}, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	logger := zap.NewNop().Sugar()
+	kint := &kubernetestint.KinterfaceTest{ServiceAccountTokens: map[string]string{"ns/default": "token"}}
+	pacInfo := &info.PacOpts{}
+	assert.NilError(t, settings.SyncConfig(logger, &pacInfo.Settings, map[string]string{
+		"cloud-credentials-token-urls": server.URL,
+		"cloud-credentials-audiences":  "pool",
+	}))
+	r := &Reconciler{
+		run: &params.Run{
+			Clients: clients.Clients{Tekton: stdata.Pipeline, Kube: stdata.Kube, Log: logger, HTTP: *server.Client()},
+			Info:    info.Info{Pac: pacInfo},
+		},
+		kinteract:    kint,
+		eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+	}
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+		Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{CloudCredentials: &v1alpha1.CloudCredentials{
+			TokenURL: "https://sts.example.com/token",
+			Audience: "pool",
+		}}},
+	}
+
+	assert.Assert(t, r.mintCloudCredentials(ctx, logger, repo, withoutCredentials))
+
+	assert.Assert(t, !r.mintCloudCredentials(ctx, logger, repo, notAllowed))
+	assert.Equal(t, len(kint.CreatedSecrets), 0)
+	pr, err := stdata.Pipeline.TektonV1().PipelineRuns("ns").Get(ctx, "not-allowed", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, pr.Spec.Status, tektonv1.PipelineRunSpecStatus(tektonv1.PipelineRunSpecStatusCancelled))
+
+	// the credentials of a retry are exchanged again and owned by the retry,
+	// to be garbage collected with it
+	repo.Spec.Settings.CloudCredentials.TokenURL = server.URL
+	assert.Assert(t, r.mintCloudCredentials(ctx, logger, repo, retry))
+	secret, ok := kint.CreatedSecrets["ns/pac-cloudcreds-ghijkl"]
+	assert.Assert(t, ok)
+	assert.Equal(t, secret.StringData["token"], "short-lived")
+	assert.Equal(t, kint.OwnedSecrets["ns/pac-cloudcreds-ghijkl"], "pull-request-abcde-retry-1")
+}
@@ -18,8 +18,11 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection/sharedmain"
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/leaderelection"
 	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
 )
 
@@ -48,6 +51,19 @@ func NewController() func(context.Context, configmap.Watcher) *controller.Impl {
 			log.Fatalf("Failed to create pipeline as code metrics recorder %v", err)
 		}
 
+		// the watcher runs with several replicas for high availability, the
+		// buckets of the leader election shard the PipelineRuns between them
+		leConfig, err := sharedmain.GetLeaderElectionConfig(ctx)
+		if err != nil {
+			log.Fatal("failed to get the leader election config: ", err)
+		}
+		// the hostname is not enough, a restarted pod keeps it but not the
+		// claims of its previous run
+		identity, err := leaderelection.UniqueID()
+		if err != nil {
+			log.Fatal("failed to get the identity of the replica: ", err)
+		}
+
 		r := &Reconciler{
 			run:               run,
 			kinteract:         kinteract,
@@ -56,8 +72,10 @@ func NewController() func(context.Context, configmap.Watcher) *controller.Impl {
 			qm:                sync.NewQueueManager(run.Clients.Log),
 			metrics:           metrics,
 			eventEmitter:      events.NewEventEmitter(run.Clients.Kube, run.Clients.Log),
+			identity:          identity,
+			sharded:           leConfig.Buckets > 1,
 		}
-		impl := tektonPipelineRunReconcilerv1.NewImpl(ctx, r, ctrlOpts())
+		impl := tektonPipelineRunReconcilerv1.NewImpl(ctx, r, ctrlOpts(r.promoted(ctx, log)))
 
 		if err := r.qm.InitQueues(ctx, run.Clients.Tekton, run.Clients.PipelineAsCode); err != nil {
 			log.Fatal("failed to init queues", err)
@@ -85,10 +103,11 @@ func checkStateAndEnqueue(impl *controller.Impl) func(obj interface{}) {
 	}
 }
 
-func ctrlOpts() func(impl *controller.Impl) controller.Options {
+func ctrlOpts(promote func(bkt pkgreconciler.Bucket)) func(impl *controller.Impl) controller.Options {
 	return func(_ *controller.Impl) controller.Options {
 		return controller.Options{
 			FinalizerName: pipelinesascode.GroupName,
+			PromoteFunc:   promote,
 			PromoteFilterFunc: func(obj interface{}) bool {
 				_, exist := obj.(*tektonv1.PipelineRun).GetAnnotations()[keys.State]
 				return exist
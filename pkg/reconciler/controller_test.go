@@ -13,6 +13,7 @@ import (
 	"gotest.tools/v3/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/controller"
+	pkgreconciler "knative.dev/pkg/reconciler"
 )
 
 type fakeReconciler struct{}
@@ -60,11 +61,16 @@ func TestCtrlOpts(t *testing.T) {
 		Logger:        logger.Named("ValidationWebhook"),
 	})
 	// Call the ctrlOpts function to get the controller options.
-	opts := ctrlOpts()(impl)
+	promoted := false
+	opts := ctrlOpts(func(pkgreconciler.Bucket) { promoted = true })(impl)
 
 	// Assert that the finalizer name is set correctly.
 	assert.Equal(t, pipelinesascode.GroupName, opts.FinalizerName)
 
+	// Assert that the promote function is the one passed.
+	opts.PromoteFunc(pkgreconciler.UniversalBucket())
+	assert.Assert(t, promoted)
+
 	// Create a new PipelineRun object with the "started" state label.
 	pr := &pipelinev1.PipelineRun{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,71 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const gibibyte = 1 << 30
+
+// podRequests returns the CPU cores and the GiB of memory requested by the
+// containers of the pod of a TaskRun, the init containers of Tekton only run
+// for a moment and are left out.
+func podRequests(pod *corev1.Pod) (cpuCores, memoryGiB float64) {
+	for _, container := range pod.Spec.Containers {
+		if cpu, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+			cpuCores += float64(cpu.MilliValue()) / 1000
+		}
+		if memory, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+			memoryGiB += float64(memory.Value()) / gibibyte
+		}
+	}
+	return cpuCores, memoryGiB
+}
+
+// estimateCost sums the CPU core hours and the memory GiB hours requested by
+// the pods of the TaskRuns of a finished PipelineRun, records them in the
+// metrics with the cost from the prices of the settings and returns the cost
+// to show in the final status. Nothing is estimated when no price is set.
+func (r *Reconciler) estimateCost(ctx context.Context, logger *zap.SugaredLogger, pacInfo *info.PacOpts, pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.PipelineRunTaskRunStatus, owner metrics.Owner) string {
+	cpuPrice, memoryPrice := pacInfo.CostPrices()
+	if cpuPrice == 0 && memoryPrice == 0 {
+		return ""
+	}
+
+	// trStatus is a map, sort them to always sum them in the same order
+	names := make([]string, 0, len(trStatus))
+	for name := range trStatus {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var cpuCoreHours, memoryGiBHours float64
+	for _, name := range names {
+		tr := trStatus[name]
+		if tr == nil || tr.Status == nil || tr.Status.PodName == "" || tr.Status.StartTime == nil || tr.Status.CompletionTime == nil {
+			continue
+		}
+		pod, err := r.run.Clients.Kube.CoreV1().Pods(pr.GetNamespace()).Get(ctx, tr.Status.PodName, metav1.GetOptions{})
+		if err != nil {
+			logger.Warnf("cannot get the pod %s to estimate the cost of %s: %v", tr.Status.PodName, pr.GetName(), err)
+			continue
+		}
+		hours := tr.Status.CompletionTime.Sub(tr.Status.StartTime.Time).Hours()
+		cpuCores, memoryGiB := podRequests(pod)
+		cpuCoreHours += cpuCores * hours
+		memoryGiBHours += memoryGiB * hours
+	}
+
+	cost := cpuCoreHours*cpuPrice + memoryGiBHours*memoryPrice
+	metrics.RecordCost(owner, pr.GetAnnotations()[keys.OriginalPRName], cpuCoreHours, memoryGiBHours, cost)
+	return fmt.Sprintf("%.2f %s for %.2f CPU core hours and %.2f GiB hours of memory requested", cost, pacInfo.CostCurrency, cpuCoreHours, memoryGiBHours)
+}
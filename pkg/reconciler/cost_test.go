@@ -0,0 +1,104 @@
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func makeCostPod(name string, requests ...corev1.ResourceList) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+	for _, request := range requests {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Resources: corev1.ResourceRequirements{Requests: request}})
+	}
+	return pod
+}
+
+func makeCostTaskRunStatus(pod string, duration time.Duration) *tektonv1.PipelineRunTaskRunStatus {
+	start := metav1.NewTime(time.Now().Add(-duration))
+	end := metav1.NewTime(start.Add(duration))
+	return &tektonv1.PipelineRunTaskRunStatus{
+		Status: &tektonv1.TaskRunStatus{TaskRunStatusFields: tektonv1.TaskRunStatusFields{
+			PodName:        pod,
+			StartTime:      &start,
+			CompletionTime: &end,
+		}},
+	}
+}
+
+func TestPodRequests(t *testing.T) {
+	cpu, memory := podRequests(makeCostPod("pod",
+		corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+		corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+		corev1.ResourceList{},
+	))
+	assert.Equal(t, cpu, 1.5)
+	assert.Equal(t, memory, 1.5)
+}
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		name        string
+		cpuPrice    string
+		memoryPrice string
+		want        string
+	}{
+		{
+			name: "no price",
+		},
+		{
+			name:        "cpu and memory prices",
+			cpuPrice:    "0.04",
+			memoryPrice: "0.01",
+			want:        "0.18 EUR for 3.00 CPU core hours and 6.00 GiB hours of memory requested",
+		},
+		{
+			name:     "cpu price only",
+			cpuPrice: "0.5",
+			want:     "1.50 EUR for 3.00 CPU core hours and 6.00 GiB hours of memory requested",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			for _, pod := range []*corev1.Pod{
+				makeCostPod("build-pod", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")}),
+				makeCostPod("test-pod", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("2Gi")}),
+			} {
+				_, err := stdata.Kube.CoreV1().Pods("ns").Create(ctx, pod, metav1.CreateOptions{})
+				assert.NilError(t, err)
+			}
+			trStatus := map[string]*tektonv1.PipelineRunTaskRunStatus{
+				"pr-build":   makeCostTaskRunStatus("build-pod", time.Hour),
+				"pr-test":    makeCostTaskRunStatus("test-pod", time.Hour),
+				"pr-pruned":  makeCostTaskRunStatus("pruned-pod", time.Hour),
+				"pr-skipped": {},
+			}
+			pacInfo := &info.PacOpts{Settings: settings.DefaultSettings()}
+			assert.NilError(t, settings.SyncConfig(zap.NewNop().Sugar(), &pacInfo.Settings, map[string]string{
+				"cost-cpu-core-hour-price":   tt.cpuPrice,
+				"cost-memory-gib-hour-price": tt.memoryPrice,
+				"cost-currency":              "EUR",
+			}))
+			r := &Reconciler{run: &params.Run{Clients: clients.Clients{Kube: stdata.Kube}}}
+			pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "ns"}}
+
+			got := r.estimateCost(ctx, zap.NewNop().Sugar(), pacInfo, pr, trStatus, metrics.Owner{})
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/apis"
+)
+
+// reviewDeployment approves or rejects the deployment verified by the
+// PipelineRun through the callback of the protection rule of its environment.
+// The deployment is rejected as soon as one of the PipelineRuns verifying it
+// fails and approved once they have all succeeded.
+func (r *Reconciler) reviewDeployment(ctx context.Context, logger *zap.SugaredLogger, vcx provider.Interface, event *info.Event, pr *tektonv1.PipelineRun) {
+	callbackURL := pr.GetAnnotations()[keys.DeploymentCallbackURL]
+	if callbackURL == "" {
+		return
+	}
+	reviewer, ok := vcx.(provider.DeploymentProtectionInterface)
+	if !ok {
+		return
+	}
+	environment := pr.GetAnnotations()[keys.DeploymentEnvironment]
+
+	approved := pr.Status.GetCondition(apis.ConditionSucceeded).IsTrue()
+	comment := fmt.Sprintf("PipelineRun %s/%s has failed", pr.GetNamespace(), pr.GetName())
+	if approved {
+		pending, err := r.pendingDeploymentVerifications(ctx, pr, callbackURL)
+		if err != nil {
+			logger.Warnf("cannot list the PipelineRuns verifying the deployment to %s: %v", environment, err)
+			return
+		}
+		if pending {
+			return
+		}
+		comment = fmt.Sprintf("PipelineRun %s/%s has succeeded", pr.GetNamespace(), pr.GetName())
+	}
+
+	if err := reviewer.ReviewDeployment(ctx, event, callbackURL, environment, approved, comment); err != nil {
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "DeploymentReviewFailed",
+			fmt.Sprintf("cannot review the deployment to %s: %v", environment, err))
+		return
+	}
+	state := "rejected"
+	if approved {
+		state = "approved"
+	}
+	r.eventEmitter.EmitPipelineRunMessage(pr, zap.InfoLevel, "DeploymentReviewed",
+		fmt.Sprintf("deployment to %s %s", environment, state))
+}
+
+// pendingDeploymentVerifications returns true when another PipelineRun
+// verifying the same deployment has not succeeded yet, the last one to
+// succeed approves the deployment.
+func (r *Reconciler) pendingDeploymentVerifications(ctx context.Context, pr *tektonv1.PipelineRun, callbackURL string) (bool, error) {
+	selector := labels.SelectorFromSet(labels.Set{keys.SHA: pr.GetLabels()[keys.SHA]})
+	prs, err := r.run.Clients.Tekton.TektonV1().PipelineRuns(pr.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return false, err
+	}
+	for _, sibling := range prs.Items {
+		if sibling.GetName() == pr.GetName() || sibling.GetAnnotations()[keys.DeploymentCallbackURL] != callbackURL {
+			continue
+		}
+		if !sibling.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
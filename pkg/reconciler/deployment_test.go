@@ -0,0 +1,124 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	testprovider "github.com/openshift-pipelines/pipelines-as-code/pkg/test/provider"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+type deploymentReviewer struct {
+	testprovider.TestProviderImp
+	reviews     int
+	approved    bool
+	environment string
+}
+
+func (d *deploymentReviewer) ReviewDeployment(_ context.Context, _ *info.Event, _, environment string, approved bool, _ string) error {
+	d.reviews++
+	d.approved = approved
+	d.environment = environment
+	return nil
+}
+
+func makeDeploymentPipelineRun(name string, status corev1.ConditionStatus) *tektonv1.PipelineRun {
+	return &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Labels:    map[string]string{keys.SHA: "6dc9a1c"},
+			Annotations: map[string]string{
+				keys.DeploymentEnvironment: "production",
+				keys.DeploymentCallbackURL: "https://api.github.com/repos/owner/repo/actions/runs/1/deployment_protection_rule",
+			},
+		},
+		Status: tektonv1.PipelineRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status}}}},
+	}
+}
+
+func TestReviewDeployment(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       corev1.ConditionStatus
+		sibling      corev1.ConditionStatus
+		noCallback   bool
+		wantReviews  int
+		wantApproved bool
+	}{
+		{
+			name:         "succeeded",
+			status:       corev1.ConditionTrue,
+			wantReviews:  1,
+			wantApproved: true,
+		},
+		{
+			name:        "failed",
+			status:      corev1.ConditionFalse,
+			wantReviews: 1,
+		},
+		{
+			name:         "succeeded with the other verifications succeeded",
+			status:       corev1.ConditionTrue,
+			sibling:      corev1.ConditionTrue,
+			wantReviews:  1,
+			wantApproved: true,
+		},
+		{
+			name:    "succeeded with another verification running",
+			status:  corev1.ConditionTrue,
+			sibling: corev1.ConditionUnknown,
+		},
+		{
+			name:        "failed with another verification running",
+			status:      corev1.ConditionFalse,
+			sibling:     corev1.ConditionUnknown,
+			wantReviews: 1,
+		},
+		{
+			name:       "not a deployment",
+			status:     corev1.ConditionTrue,
+			noCallback: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			pr := makeDeploymentPipelineRun("deploy-check-abcde", tt.status)
+			if tt.noCallback {
+				pr.Annotations = map[string]string{}
+			}
+			prs := []*tektonv1.PipelineRun{pr}
+			if tt.sibling != "" {
+				prs = append(prs, makeDeploymentPipelineRun("smoke-check-fghij", tt.sibling))
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: prs})
+			logger := zap.NewNop().Sugar()
+			r := &Reconciler{
+				run:          &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline}},
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+			vcx := &deploymentReviewer{}
+
+			r.reviewDeployment(ctx, logger, vcx, info.NewEvent(), pr)
+			assert.Equal(t, vcx.reviews, tt.wantReviews)
+			if tt.wantReviews > 0 {
+				assert.Equal(t, vcx.approved, tt.wantApproved)
+				assert.Equal(t, vcx.environment, "production")
+			}
+		})
+	}
+}
@@ -4,10 +4,11 @@ import (
 	"fmt"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 )
 
-func (r *Reconciler) emitMetrics(pr *tektonv1.PipelineRun) error {
+func (r *Reconciler) emitMetrics(pr *tektonv1.PipelineRun, aggregation metrics.Aggregation) error {
 	gitProvider := pr.GetAnnotations()[keys.GitProvider]
 	eventType := pr.GetAnnotations()[keys.EventType]
 
@@ -24,5 +25,6 @@ func (r *Reconciler) emitMetrics(pr *tektonv1.PipelineRun) error {
 		return fmt.Errorf("no supported Git provider")
 	}
 
-	return r.metrics.Count(gitProvider, eventType)
+	owner := aggregation.Owner(pr.GetAnnotations()[keys.URLOrg], pr.GetNamespace(), pr.GetLabels()[keys.Repository])
+	return r.metrics.Count(gitProvider, eventType, owner)
 }
@@ -72,7 +72,7 @@ func TestEmitMetrics(t *testing.T) {
 					Annotations: tt.annotations,
 				},
 			}
-			if err = r.emitMetrics(pr); (err != nil) != tt.wantErr {
+			if err = r.emitMetrics(pr, metrics.NewAggregation("namespace", "")); (err != nil) != tt.wantErr {
 				t.Errorf("emitMetrics() error = %v, wantErr %v", err != nil, tt.wantErr)
 			}
 		})
@@ -69,6 +69,7 @@ func buildEventFromPipelineRun(pr *tektonv1.PipelineRun) *info.Event {
 	event.TriggerTarget = triggertype.StringToType(prAnno[keys.EventType])
 	event.BaseBranch = prAnno[keys.Branch]
 	event.SHA = prAnno[keys.SHA]
+	event.Sender = prAnno[keys.Sender]
 
 	event.SHATitle = prAnno[keys.ShaTitle]
 	event.SHAURL = prAnno[keys.ShaURL]
@@ -0,0 +1,202 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	ktypes "github.com/openshift-pipelines/pipelines-as-code/pkg/secrets/types"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// maxFanOutDepth is the maximum number of repositories in a chain of
+	// PipelineRuns triggering each other on success.
+	maxFanOutDepth = 5
+	// fanOutSecretKey is the key of the incoming webhook secret in the Secret
+	// of the on-success-trigger-secret annotation.
+	fanOutSecretKey = "secret"
+	fanOutTimeout   = 30 * time.Second
+)
+
+// incomingURL returns the URL of the incoming webhooks of the controller, the
+// incoming-webhook-url setting or the in-cluster URL of the controller
+// installed in the namespace.
+func incomingURL(pacInfo *info.PacOpts, ns string) string {
+	if pacInfo.IncomingWebhookURL != "" {
+		return pacInfo.IncomingWebhookURL
+	}
+	return fmt.Sprintf("http://pipelines-as-code-controller.%s.svc.cluster.local:8080/incoming", ns)
+}
+
+// fanOutTarget is a PipelineRun of another repository to trigger on success.
+type fanOutTarget struct {
+	URL         string
+	Branch      string
+	PipelineRun string
+}
+
+func (t fanOutTarget) String() string {
+	return fmt.Sprintf("%s@%s#%s", t.URL, t.Branch, t.PipelineRun)
+}
+
+// parseFanOutTargets parses the on-success-trigger annotation, a list of
+// <repository url>[@<branch>]#<pipelinerun> with the branch of the
+// PipelineRun when the branch is not set.
+func parseFanOutTargets(annotation, branch string) ([]fanOutTarget, error) {
+	annotation = strings.TrimSpace(annotation)
+	annotation = strings.TrimSuffix(strings.TrimPrefix(annotation, "["), "]")
+	targets := []fanOutTarget{}
+	for _, value := range strings.Split(annotation, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		repoURL, pipelineRun, found := strings.Cut(value, "#")
+		if !found || pipelineRun == "" {
+			return nil, fmt.Errorf("no pipelinerun in %s, the format is <repository url>[@<branch>]#<pipelinerun>", value)
+		}
+		target := fanOutTarget{URL: repoURL, Branch: branch, PipelineRun: pipelineRun}
+		// the branch is after the first @ of the path, the @ of the host are credentials
+		if path := fanOutPathIndex(repoURL); path != -1 {
+			if i := strings.Index(repoURL[path:], "@"); i != -1 {
+				target.URL, target.Branch = repoURL[:path+i], repoURL[path+i+1:]
+			}
+		}
+		target.URL = strings.TrimSuffix(target.URL, "/")
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// fanOutPathIndex returns the index of the path of the url, -1 without path.
+func fanOutPathIndex(repoURL string) int {
+	host := 0
+	if i := strings.Index(repoURL, "://"); i != -1 {
+		host = i + len("://")
+	}
+	i := strings.Index(repoURL[host:], "/")
+	if i == -1 {
+		return -1
+	}
+	return host + i
+}
+
+// fanOut triggers the PipelineRuns of the other repositories listed by the
+// on-success-trigger annotation of a succeeded PipelineRun with their incoming
+// webhook. The repositories already in the chain of PipelineRuns triggering
+// each other are not triggered again to avoid the loops, and the chain stops
+// after maxFanOutDepth repositories.
+func (r *Reconciler) fanOut(ctx context.Context, logger *zap.SugaredLogger, repo *v1alpha1.Repository, pr *tektonv1.PipelineRun) {
+	annotation, ok := pr.GetAnnotations()[keys.OnSuccessTrigger]
+	if !ok {
+		return
+	}
+	branch := strings.TrimPrefix(pr.GetAnnotations()[keys.Branch], "refs/heads/")
+	targets, err := parseFanOutTargets(annotation, branch)
+	if err != nil {
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "PipelineRunFanOutFailed",
+			fmt.Sprintf("invalid %s annotation: %s", keys.OnSuccessTrigger, err))
+		return
+	}
+	secretName := pr.GetAnnotations()[keys.OnSuccessTriggerSecret]
+	if secretName == "" {
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "PipelineRunFanOutFailed",
+			fmt.Sprintf("the %s annotation needs the %s annotation", keys.OnSuccessTrigger, keys.OnSuccessTriggerSecret))
+		return
+	}
+	secret, err := r.kinteract.GetSecret(ctx, ktypes.GetSecretOpt{Namespace: pr.GetNamespace(), Name: secretName, Key: fanOutSecretKey})
+	if err != nil || secret == "" {
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "PipelineRunFanOutFailed",
+			fmt.Sprintf("cannot get the incoming webhook secret from the key %s of the secret %s: %v", fanOutSecretKey, secretName, err))
+		return
+	}
+
+	pacInfo := r.run.Info.GetPacOpts()
+	chain := []string{}
+	if value := pr.GetAnnotations()[keys.FanOutChain]; value != "" {
+		chain = strings.Split(value, ",")
+	}
+	chain = append(chain, repo.GetNamespace()+"/"+repo.GetName())
+	if len(chain) > maxFanOutDepth {
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.WarnLevel, "PipelineRunFanOutLoop",
+			fmt.Sprintf("the chain of triggered PipelineRuns %s is longer than %d repositories, not triggering %s",
+				strings.Join(chain, " -> "), maxFanOutDepth, annotation))
+		return
+	}
+
+	for _, target := range targets {
+		downstream := r.fanOutRepository(target.URL)
+		if downstream == nil {
+			r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "PipelineRunFanOutFailed",
+				fmt.Sprintf("cannot find a repository for %s to trigger %s", target.URL, target))
+			continue
+		}
+		if slices.Contains(chain, downstream.GetNamespace()+"/"+downstream.GetName()) {
+			r.eventEmitter.EmitPipelineRunMessage(pr, zap.WarnLevel, "PipelineRunFanOutLoop",
+				fmt.Sprintf("the repository %s/%s is already in the chain of triggered PipelineRuns %s, not triggering %s",
+					downstream.GetNamespace(), downstream.GetName(), strings.Join(chain, " -> "), target))
+			continue
+		}
+		if err := r.triggerIncoming(ctx, incomingURL(&pacInfo, info.GetNS(ctx)), downstream.GetName(), target, secret, chain); err != nil {
+			r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "PipelineRunFanOutFailed",
+				fmt.Sprintf("cannot trigger %s: %s", target, err))
+			continue
+		}
+		logger.Infof("triggered %s on the success of %s", target, pr.GetName())
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.InfoLevel, "PipelineRunFanOut",
+			fmt.Sprintf("triggered %s", target))
+	}
+}
+
+// fanOutRepository returns the Repository of the url.
+func (r *Reconciler) fanOutRepository(repoURL string) *v1alpha1.Repository {
+	repos, err := r.repoLister.List(labels.Everything())
+	if err != nil {
+		return nil
+	}
+	for _, repo := range repos {
+		if strings.TrimSuffix(repo.Spec.URL, "/") == repoURL {
+			return repo
+		}
+	}
+	return nil
+}
+
+// triggerIncoming posts the incoming webhook of the controller for the
+// PipelineRun of the target with the fan-out chain.
+func (r *Reconciler) triggerIncoming(ctx context.Context, incoming, repository string, target fanOutTarget, secret string, chain []string) error {
+	ctx, cancel := context.WithTimeout(ctx, fanOutTimeout)
+	defer cancel()
+	query := url.Values{
+		"repository":  {repository},
+		"branch":      {target.Branch},
+		"pipelinerun": {target.PipelineRun},
+		"secret":      {secret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, incoming+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(keys.FanOutChainHeader, strings.Join(chain, ","))
+	resp, err := r.run.Clients.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("the incoming webhook has replied %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package reconciler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestParseFanOutTargets(t *testing.T) {
+	targets, err := parseFanOutTargets("[https://github.com/org/app#deploy, https://gitlab.example.com:8443/group/lib@release/1.0#build]", "main")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, targets, []fanOutTarget{
+		{URL: "https://github.com/org/app", Branch: "main", PipelineRun: "deploy"},
+		{URL: "https://gitlab.example.com:8443/group/lib", Branch: "release/1.0", PipelineRun: "build"},
+	})
+
+	_, err = parseFanOutTargets("https://github.com/org/app", "main")
+	assert.ErrorContains(t, err, "no pipelinerun in https://github.com/org/app")
+}
+
+func TestIncomingURL(t *testing.T) {
+	pacInfo := &info.PacOpts{}
+	assert.Equal(t, incomingURL(pacInfo, "pac"), "http://pipelines-as-code-controller.pac.svc.cluster.local:8080/incoming")
+	pacInfo.IncomingWebhookURL = "https://pac.example.com/incoming"
+	assert.Equal(t, incomingURL(pacInfo, "pac"), "https://pac.example.com/incoming")
+}
+
+func TestFanOut(t *testing.T) {
+	tests := []struct {
+		name        string
+		chain       string
+		annotations map[string]string
+		wantQuery   string
+		wantChain   string
+	}{
+		{
+			name:      "trigger the downstream repository",
+			wantQuery: "branch=main&pipelinerun=deploy&repository=app&secret=shared",
+			wantChain: "upstream/lib",
+		},
+		{
+			name:      "continue the chain",
+			chain:     "other/base",
+			wantQuery: "branch=main&pipelinerun=deploy&repository=app&secret=shared",
+			wantChain: "other/base,upstream/lib",
+		},
+		{
+			name:  "loop",
+			chain: "downstream/app",
+		},
+		{
+			name:  "chain too long",
+			chain: "a/a,b/b,c/c,d/d,e/e",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQuery, gotChain := "", ""
+			server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.Method, http.MethodPost)
+				gotQuery = r.URL.RawQuery
+				gotChain = r.Header.Get(keys.FanOutChainHeader)
+			}))
+			defer server.Close()
+
+			ctx, _ := rtesting.SetupFakeContext(t)
+			logger := zap.NewNop().Sugar()
+			upstream := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "lib", Namespace: "upstream"},
+				Spec:       v1alpha1.RepositorySpec{URL: "https://github.com/org/lib"},
+			}
+			downstream := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "downstream"},
+				Spec:       v1alpha1.RepositorySpec{URL: "https://github.com/org/app"},
+			}
+			stdata, informers := testclient.SeedTestData(t, ctx, testclient.Data{
+				Repositories: []*v1alpha1.Repository{upstream, downstream},
+			})
+			pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+				Name: "build-abcde", Namespace: "upstream",
+				Annotations: map[string]string{
+					keys.Branch:                 "refs/heads/main",
+					keys.OnSuccessTrigger:       "[https://github.com/org/app#deploy]",
+					keys.OnSuccessTriggerSecret: "fan-out",
+					keys.FanOutChain:            tt.chain,
+				},
+			}}
+			pacInfo := &info.PacOpts{Settings: settings.DefaultSettings()}
+			assert.NilError(t, settings.SyncConfig(logger, &pacInfo.Settings, map[string]string{
+				"incoming-webhook-url": server.URL + "/incoming",
+			}))
+			r := &Reconciler{
+				run: &params.Run{
+					Clients: clients.Clients{HTTP: *server.Client()},
+					Info:    info.Info{Pac: pacInfo},
+				},
+				repoLister:   informers.Repository.Lister(),
+				kinteract:    &kitesthelper.KinterfaceTest{GetSecretResult: map[string]string{"fan-out": "shared"}},
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+
+			r.fanOut(ctx, logger, upstream, pr)
+			assert.Equal(t, gotQuery, tt.wantQuery)
+			assert.Equal(t, gotChain, tt.wantChain)
+		})
+	}
+}
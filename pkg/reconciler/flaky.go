@@ -0,0 +1,128 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	taskPassed = "passed"
+	taskFailed = "failed"
+)
+
+// taskOutcome is the outcome of a task of a finished PipelineRun, the
+// signature of a failure is the reason of the TaskRun with the first failed
+// step and its exit code, like Failed/unit-tests/2.
+type taskOutcome struct {
+	Outcome   string `json:"outcome"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// taskOutcomes returns the outcome of the tasks of the PipelineRun by their
+// name in the pipeline, the tasks which have not run are left out.
+func taskOutcomes(trStatus map[string]*tektonv1.PipelineRunTaskRunStatus) map[string]taskOutcome {
+	outcomes := map[string]taskOutcome{}
+	for _, tr := range trStatus {
+		if tr == nil || tr.Status == nil || tr.PipelineTaskName == "" {
+			continue
+		}
+		cond := tr.Status.GetCondition(apis.ConditionSucceeded)
+		switch {
+		case cond == nil || cond.IsUnknown():
+			continue
+		case cond.IsTrue():
+			outcomes[tr.PipelineTaskName] = taskOutcome{Outcome: taskPassed}
+		default:
+			outcomes[tr.PipelineTaskName] = taskOutcome{Outcome: taskFailed, Signature: failureSignature(cond.Reason, tr.Status.Steps)}
+		}
+	}
+	return outcomes
+}
+
+func failureSignature(reason string, steps []tektonv1.StepState) string {
+	for _, step := range steps {
+		if step.Terminated != nil && step.Terminated.ExitCode != 0 {
+			return fmt.Sprintf("%s/%s/%d", reason, step.Name, step.Terminated.ExitCode)
+		}
+	}
+	return reason
+}
+
+// flakyTasks returns the tasks which have passed in a run and failed in
+// another on the same SHA.
+func flakyTasks(current map[string]taskOutcome, previous []map[string]taskOutcome) []string {
+	flaky := []string{}
+	for task, outcome := range current {
+		for _, prev := range previous {
+			if p, ok := prev[task]; ok && p.Outcome != outcome.Outcome {
+				flaky = append(flaky, task)
+				break
+			}
+		}
+	}
+	sort.Strings(flaky)
+	return flaky
+}
+
+// detectFlakyTasks records the outcome of the tasks on the PipelineRun and
+// compares them with the ones of the previous runs of the same PipelineRun on
+// the same SHA, the retests. The tasks alternating between passing and
+// failing are returned as possibly flaky and counted in the metrics.
+func (r *Reconciler) detectFlakyTasks(ctx context.Context, logger *zap.SugaredLogger, pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.PipelineRunTaskRunStatus, owner metrics.Owner) []string {
+	current := taskOutcomes(trStatus)
+	if len(current) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil
+	}
+	if _, err := action.PatchPipelineRun(ctx, logger, "task outcomes", r.run.Clients.Tekton, pr, map[string]any{
+		"metadata": map[string]any{"annotations": map[string]string{keys.TaskOutcomes: string(data)}},
+	}); err != nil {
+		logger.Warnf("cannot record the task outcomes of %s: %v", pr.GetName(), err)
+	}
+
+	originalName := pr.GetLabels()[keys.OriginalPRName]
+	var previous []map[string]taskOutcome
+	if sha := pr.GetLabels()[keys.SHA]; sha != "" && originalName != "" {
+		selector := labels.SelectorFromSet(labels.Set{keys.SHA: sha, keys.OriginalPRName: originalName})
+		prs, err := r.run.Clients.Tekton.TektonV1().PipelineRuns(pr.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			logger.Warnf("cannot list the previous runs of %s: %v", pr.GetName(), err)
+		} else {
+			for _, sibling := range prs.Items {
+				outcomes := map[string]taskOutcome{}
+				value := sibling.GetAnnotations()[keys.TaskOutcomes]
+				if sibling.GetName() == pr.GetName() || value == "" || json.Unmarshal([]byte(value), &outcomes) != nil {
+					continue
+				}
+				previous = append(previous, outcomes)
+			}
+		}
+	}
+
+	flaky := flakyTasks(current, previous)
+	pipeline := pr.GetAnnotations()[keys.OriginalPRName]
+	for task, outcome := range current {
+		metrics.CountTaskOutcome(owner, pipeline, task, outcome.Outcome, slices.Contains(flaky, task))
+	}
+	if len(flaky) > 0 {
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.WarnLevel, "PipelineRunPossiblyFlaky",
+			fmt.Sprintf("the tasks %s passed and failed on the same commit, they are possibly flaky", strings.Join(flaky, ", ")))
+	}
+	return flaky
+}
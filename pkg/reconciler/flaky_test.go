@@ -0,0 +1,89 @@
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func makeTaskRunStatus(task string, status corev1.ConditionStatus, steps ...tektonv1.StepState) *tektonv1.PipelineRunTaskRunStatus {
+	return &tektonv1.PipelineRunTaskRunStatus{
+		PipelineTaskName: task,
+		Status: &tektonv1.TaskRunStatus{
+			Status:              duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status, Reason: "Failed"}}},
+			TaskRunStatusFields: tektonv1.TaskRunStatusFields{Steps: steps},
+		},
+	}
+}
+
+func TestTaskOutcomes(t *testing.T) {
+	outcomes := taskOutcomes(map[string]*tektonv1.PipelineRunTaskRunStatus{
+		"pr-lint":    makeTaskRunStatus("lint", corev1.ConditionTrue),
+		"pr-unit":    makeTaskRunStatus("unit", corev1.ConditionFalse, tektonv1.StepState{Name: "setup"}, tektonv1.StepState{Name: "test", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}}}),
+		"pr-e2e":     makeTaskRunStatus("e2e", corev1.ConditionFalse),
+		"pr-running": makeTaskRunStatus("running", corev1.ConditionUnknown),
+	})
+	assert.DeepEqual(t, outcomes, map[string]taskOutcome{
+		"lint": {Outcome: taskPassed},
+		"unit": {Outcome: taskFailed, Signature: "Failed/test/2"},
+		"e2e":  {Outcome: taskFailed, Signature: "Failed"},
+	})
+}
+
+func TestDetectFlakyTasks(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	prLabels := map[string]string{keys.SHA: "abc", keys.OriginalPRName: "pull-request"}
+	previous := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pull-request-1", Namespace: "ns", Labels: prLabels,
+			Annotations: map[string]string{keys.TaskOutcomes: `{"lint":{"outcome":"passed"},"unit":{"outcome":"passed"}}`},
+		},
+	}
+	otherSHA := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pull-request-0", Namespace: "ns", Labels: map[string]string{keys.SHA: "def", keys.OriginalPRName: "pull-request"},
+			Annotations: map[string]string{keys.TaskOutcomes: `{"lint":{"outcome":"failed"}}`},
+		},
+	}
+	current := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pull-request-2", Namespace: "ns", Labels: prLabels,
+			Annotations: map[string]string{keys.OriginalPRName: "pull-request"},
+		},
+	}
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: []*tektonv1.PipelineRun{previous, otherSHA, current}})
+	logger := zap.NewNop().Sugar()
+	r := &Reconciler{
+		run:          &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline}},
+		eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+	}
+
+	flaky := r.detectFlakyTasks(ctx, logger, current, map[string]*tektonv1.PipelineRunTaskRunStatus{
+		"pr-lint": makeTaskRunStatus("lint", corev1.ConditionTrue),
+		"pr-unit": makeTaskRunStatus("unit", corev1.ConditionFalse),
+	}, metrics.Owner{})
+	assert.DeepEqual(t, flaky, []string{"unit"})
+
+	got, err := stdata.Pipeline.TektonV1().PipelineRuns("ns").Get(ctx, "pull-request-2", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, got.GetAnnotations()[keys.TaskOutcomes], `{"lint":{"outcome":"passed"},"unit":{"outcome":"failed","signature":"Failed"}}`)
+
+	// the first run on a SHA has nothing to compare with
+	flaky = r.detectFlakyTasks(ctx, logger, otherSHA, map[string]*tektonv1.PipelineRunTaskRunStatus{
+		"pr-lint": makeTaskRunStatus("lint", corev1.ConditionFalse),
+	}, metrics.Owner{})
+	assert.Equal(t, len(flaky), 0)
+}
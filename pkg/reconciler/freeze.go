@@ -0,0 +1,53 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"knative.dev/pkg/controller"
+)
+
+// freezeRecheckInterval is how often a frozen PipelineRun checks if an admin
+// has lifted the freeze windows of its Repository.
+var freezeRecheckInterval = 5 * time.Minute
+
+// thawPipelineRun queues the PipelineRun held by a freeze window once the
+// window has ended or an admin has lifted the freeze windows with /unfreeze,
+// the watcher then starts it like the other queued PipelineRuns.
+func (r *Reconciler) thawPipelineRun(ctx context.Context, logger *zap.SugaredLogger, pr *tektonv1.PipelineRun) error {
+	until, err := time.Parse(time.RFC3339, pr.GetAnnotations()[keys.FrozenUntil])
+	if err == nil && time.Now().Before(until) && !r.freezeLifted(pr) {
+		return controller.NewRequeueAfter(min(time.Until(until), freezeRecheckInterval))
+	}
+
+	annotations := map[string]string{keys.State: kubeinteraction.StateQueued}
+	if _, ok := pr.GetAnnotations()[keys.ExecutionOrder]; !ok {
+		annotations[keys.ExecutionOrder] = pr.GetNamespace() + "/" + pr.GetName()
+	}
+	if _, err := action.PatchPipelineRun(ctx, logger, "thawed", r.run.Clients.Tekton, pr, map[string]any{
+		"metadata": map[string]any{
+			"labels":      map[string]string{keys.State: kubeinteraction.StateQueued},
+			"annotations": annotations,
+		},
+	}); err != nil {
+		return fmt.Errorf("cannot queue the frozen pipelineRun %s: %w", pr.GetName(), err)
+	}
+	r.eventEmitter.EmitPipelineRunMessage(pr, zap.InfoLevel, "PipelineRunThawed", "freeze window over, PipelineRun queued")
+	return nil
+}
+
+// freezeLifted returns true if an admin has lifted the freeze windows of the
+// Repository of the PipelineRun.
+func (r *Reconciler) freezeLifted(pr *tektonv1.PipelineRun) bool {
+	repo, err := r.repoLister.Repositories(pr.GetNamespace()).Get(pr.GetAnnotations()[keys.Repository])
+	if err != nil {
+		return false
+	}
+	return repo.Spec.Settings != nil && repo.Spec.Settings.FreezeOverride
+}
@@ -0,0 +1,90 @@
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/controller"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestThawPipelineRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		until       time.Time
+		override    bool
+		wantRequeue time.Duration
+	}{
+		{
+			name:        "window in progress",
+			until:       time.Now().Add(time.Hour),
+			wantRequeue: freezeRecheckInterval,
+		},
+		{
+			name:  "window over",
+			until: time.Now().Add(-time.Minute),
+		},
+		{
+			name:     "lifted by an admin",
+			until:    time.Now().Add(time.Hour),
+			override: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec:       v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{FreezeOverride: tt.override}},
+			}
+			pr := &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "push-abcde", Namespace: "ns",
+					Labels: map[string]string{keys.State: kubeinteraction.StateFrozen},
+					Annotations: map[string]string{
+						keys.State:       kubeinteraction.StateFrozen,
+						keys.Repository:  "repo",
+						keys.FrozenUntil: tt.until.UTC().Format(time.RFC3339),
+					},
+				},
+				Spec: tektonv1.PipelineRunSpec{Status: tektonv1.PipelineRunSpecStatusPending},
+			}
+			stdata, informers := testclient.SeedTestData(t, ctx, testclient.Data{
+				Repositories: []*v1alpha1.Repository{repo},
+				PipelineRuns: []*tektonv1.PipelineRun{pr},
+			})
+			logger := zap.NewNop().Sugar()
+			r := &Reconciler{
+				run:          &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline}},
+				repoLister:   informers.Repository.Lister(),
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+
+			err := r.thawPipelineRun(ctx, logger, pr)
+			got, gerr := stdata.Pipeline.TektonV1().PipelineRuns("ns").Get(ctx, pr.GetName(), metav1.GetOptions{})
+			assert.NilError(t, gerr)
+			if tt.wantRequeue != 0 {
+				ok, requeue := controller.IsRequeueKey(err)
+				assert.Assert(t, ok)
+				assert.Equal(t, requeue, tt.wantRequeue)
+				assert.Equal(t, got.GetAnnotations()[keys.State], kubeinteraction.StateFrozen)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got.GetLabels()[keys.State], kubeinteraction.StateQueued)
+			assert.Equal(t, got.GetAnnotations()[keys.State], kubeinteraction.StateQueued)
+			assert.Equal(t, got.GetAnnotations()[keys.ExecutionOrder], "ns/push-abcde")
+		})
+	}
+}
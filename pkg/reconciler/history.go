@@ -0,0 +1,97 @@
+package reconciler
+
+import (
+	"context"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/tektonresults"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// maxHistoryRuns is the number of previous runs shown in the final status.
+const maxHistoryRuns = 5
+
+// runResult returns the reason of the Succeeded condition of a run, like
+// Succeeded, Failed or Cancelled.
+func runResult(status duckv1.Status) string {
+	if cond := status.GetCondition(apis.ConditionSucceeded); cond != nil && cond.Reason != "" {
+		return cond.Reason
+	}
+	return "Unknown"
+}
+
+// runHistory returns the previous runs of the same PipelineRun on the same
+// branch, the most recent first. They are read from Tekton Results when the
+// tekton-results-url setting is set, it keeps the runs pruned from the
+// cluster, or else from the last runs in the status of the Repository.
+func (r *Reconciler) runHistory(ctx context.Context, logger *zap.SugaredLogger, pacInfo *info.PacOpts, repo *v1alpha1.Repository, pr *tektonv1.PipelineRun) []formatting.HistoryRun {
+	originalName := pr.GetAnnotations()[keys.OriginalPRName]
+	if originalName == "" {
+		return nil
+	}
+	branch := pr.GetAnnotations()[keys.Branch]
+
+	if pacInfo.TektonResultsURL != "" {
+		client := tektonresults.NewClient(pacInfo.TektonResultsURL, &r.run.Clients.HTTP)
+		// one more in case the PipelineRun itself has already been stored
+		prs, err := client.ListPipelineRuns(ctx, pr.GetNamespace(), map[string]string{
+			keys.Repository:     pr.GetLabels()[keys.Repository],
+			keys.OriginalPRName: pr.GetLabels()[keys.OriginalPRName],
+		}, map[string]string{keys.Branch: branch}, maxHistoryRuns+1)
+		if err == nil {
+			return r.historyFromPipelineRuns(prs, pr.GetName())
+		}
+		logger.Warnf("cannot get the history of %s from Tekton Results, using the Repository status: %v", pr.GetName(), err)
+	}
+
+	if repo == nil {
+		return nil
+	}
+	history := []formatting.HistoryRun{}
+	sanitizedBranch := formatting.SanitizeBranch(branch)
+	for i := len(repo.Status) - 1; i >= 0 && len(history) < maxHistoryRuns; i-- {
+		status := repo.Status[i]
+		if status.PipelineRunName == pr.GetName() || status.StartTime == nil || status.CompletionTime == nil ||
+			status.OriginalPipelineRunName == nil || *status.OriginalPipelineRunName != originalName ||
+			status.TargetBranch == nil || *status.TargetBranch != sanitizedBranch {
+			continue
+		}
+		run := formatting.HistoryRun{
+			Name:     status.PipelineRunName,
+			Result:   runResult(status.Status),
+			Duration: status.CompletionTime.Sub(status.StartTime.Time),
+		}
+		if status.LogURL != nil {
+			run.URL = *status.LogURL
+		}
+		history = append(history, run)
+	}
+	return history
+}
+
+// historyFromPipelineRuns returns the finished PipelineRuns other than the
+// current one, at most maxHistoryRuns of them.
+func (r *Reconciler) historyFromPipelineRuns(prs []*tektonv1.PipelineRun, current string) []formatting.HistoryRun {
+	history := []formatting.HistoryRun{}
+	for _, previous := range prs {
+		if len(history) == maxHistoryRuns {
+			break
+		}
+		if previous.GetName() == current || previous.Status.StartTime == nil || previous.Status.CompletionTime == nil {
+			continue
+		}
+		history = append(history, formatting.HistoryRun{
+			Name:     previous.GetName(),
+			URL:      r.run.Clients.ConsoleUI().DetailURL(previous),
+			Result:   runResult(previous.Status.Status),
+			Duration: previous.Status.CompletionTime.Sub(previous.Status.StartTime.Time),
+		})
+	}
+	return history
+}
@@ -0,0 +1,104 @@
+package reconciler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func succeeded(reason string) duckv1.Status {
+	status := corev1.ConditionTrue
+	if reason != "Succeeded" {
+		status = corev1.ConditionFalse
+	}
+	return duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status, Reason: reason}}}
+}
+
+func repoRunStatus(name, original, branch, reason string, start time.Time, duration time.Duration) v1alpha1.RepositoryRunStatus {
+	logURL := "https://console/" + name
+	return v1alpha1.RepositoryRunStatus{
+		Status:                  succeeded(reason),
+		PipelineRunName:         name,
+		StartTime:               &metav1.Time{Time: start},
+		CompletionTime:          &metav1.Time{Time: start.Add(duration)},
+		LogURL:                  &logURL,
+		TargetBranch:            &branch,
+		OriginalPipelineRunName: &original,
+	}
+}
+
+func TestRunHistory(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Name:        "pull-request-zzzzz",
+		Namespace:   "ns",
+		Labels:      map[string]string{keys.Repository: "repo", keys.OriginalPRName: "pull-request"},
+		Annotations: map[string]string{keys.OriginalPRName: "pull-request", keys.Branch: "refs/heads/main"},
+	}}
+	repo := &v1alpha1.Repository{Status: []v1alpha1.RepositoryRunStatus{
+		repoRunStatus("pull-request-aaaaa", "pull-request", "main", "Succeeded", start, 4*time.Minute),
+		repoRunStatus("push-bbbbb", "push", "main", "Succeeded", start, time.Minute),
+		repoRunStatus("pull-request-ccccc", "pull-request", "feature", "Succeeded", start, time.Minute),
+		repoRunStatus("pull-request-ddddd", "pull-request", "main", "Failed", start, 6*time.Minute),
+		{PipelineRunName: "pull-request-running"},
+	}}
+
+	ctx, _ := rtesting.SetupFakeContext(t)
+	logger := zap.NewNop().Sugar()
+	r := &Reconciler{run: &params.Run{}}
+
+	history := r.runHistory(ctx, logger, &info.PacOpts{}, repo, pr)
+	assert.Equal(t, len(history), 2)
+	assert.Equal(t, history[0].Name, "pull-request-ddddd")
+	assert.Equal(t, history[0].Result, "Failed")
+	assert.Equal(t, history[0].Duration, 6*time.Minute)
+	assert.Equal(t, history[0].URL, "https://console/pull-request-ddddd")
+	assert.Equal(t, history[1].Name, "pull-request-aaaaa")
+
+	// from tekton results when set
+	previous := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-request-eeeee", Namespace: "ns"},
+		Status: tektonv1.PipelineRunStatus{
+			Status: succeeded("Succeeded"),
+			PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+				StartTime:      &metav1.Time{Time: start},
+				CompletionTime: &metav1.Time{Time: start.Add(3 * time.Minute)},
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		records := []map[string]any{}
+		for _, run := range []*tektonv1.PipelineRun{pr, previous} {
+			value, err := json.Marshal(run)
+			assert.NilError(t, err)
+			records = append(records, map[string]any{"name": run.GetName(), "data": map[string]any{"type": "tekton.dev/v1.PipelineRun", "value": value}})
+		}
+		assert.NilError(t, json.NewEncoder(w).Encode(map[string]any{"records": records}))
+	}))
+	defer server.Close()
+	r.run = &params.Run{Clients: clients.Clients{HTTP: *server.Client()}}
+	r.run.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+
+	history = r.runHistory(ctx, logger, &info.PacOpts{Settings: settings.Settings{TektonResultsURL: server.URL}}, repo, pr)
+	assert.Equal(t, len(history), 1)
+	assert.Equal(t, history[0].Name, "pull-request-eeeee")
+	assert.Equal(t, history[0].Duration, 3*time.Minute)
+}
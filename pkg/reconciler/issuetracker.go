@@ -0,0 +1,85 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/issuetracker"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	ktypes "github.com/openshift-pipelines/pipelines-as-code/pkg/secrets/types"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"knative.dev/pkg/apis"
+)
+
+// linkIssues links the finished PipelineRun to the issues found in its event
+// on the issue tracker of the Repository. A failure to link an issue is only
+// reported as an event of the PipelineRun.
+func (r *Reconciler) linkIssues(ctx context.Context, logger *zap.SugaredLogger, pacInfo *info.PacOpts, repo *v1alpha1.Repository, event *info.Event, pr *tektonv1.PipelineRun) {
+	issueKeys := pr.GetAnnotations()[keys.IssueKeys]
+	if issueKeys == "" || repo.Spec.Settings == nil || repo.Spec.Settings.IssueTracker == nil {
+		return
+	}
+	tracker := repo.Spec.Settings.IssueTracker
+	secretKey := tracker.Secret.Key
+	if secretKey == "" {
+		secretKey = issuetracker.DefaultSecretKey
+	}
+	token, err := r.kinteract.GetSecret(ctx, ktypes.GetSecretOpt{Namespace: repo.GetNamespace(), Name: tracker.Secret.Name, Key: secretKey})
+	if err != nil || token == "" {
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "IssueTrackerLinkFailed",
+			fmt.Sprintf("cannot get the token of the issue tracker from the key %s of the secret %s: %v", secretKey, tracker.Secret.Name, err))
+		return
+	}
+	client, err := issuetracker.New(tracker, token, &r.run.Clients.HTTP)
+	if err != nil {
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "IssueTrackerLinkFailed", err.Error())
+		return
+	}
+
+	// the annotation can be set by the PipelineRun of the event, never link
+	// more issues than found by pipelines-as-code
+	linkKeys := strings.Split(issueKeys, ",")
+	if len(linkKeys) > issuetracker.MaxIssueKeys {
+		logger.Warnf("pipelinerun %s has %d issue keys, only the first %d are linked", pr.GetName(), len(linkKeys), issuetracker.MaxIssueKeys)
+		linkKeys = linkKeys[:issuetracker.MaxIssueKeys]
+	}
+
+	run := issueTrackerRun(pacInfo, repo, event, pr, r.run.Clients.ConsoleUI().DetailURL(pr))
+	for _, key := range linkKeys {
+		if err := client.LinkRun(ctx, key, run); err != nil {
+			r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "IssueTrackerLinkFailed",
+				fmt.Sprintf("cannot link the PipelineRun to the issue %s on %s: %v", key, client.GetName(), err))
+			continue
+		}
+		logger.Infof("linked pipelinerun %s to the issue %s on %s", pr.GetName(), key, client.GetName())
+	}
+}
+
+// issueTrackerRun describes the finished PipelineRun for the issue tracker,
+// the runs of the same PipelineRun on the Repository share their ID.
+func issueTrackerRun(pacInfo *info.PacOpts, repo *v1alpha1.Repository, event *info.Event, pr *tektonv1.PipelineRun, consoleURL string) issuetracker.Run {
+	name := pr.GetAnnotations()[keys.OriginalPRName]
+	if name == "" {
+		name = pr.GetName()
+	}
+	succeeded := pr.Status.GetCondition(apis.ConditionSucceeded).IsTrue()
+	state := "failed"
+	if succeeded {
+		state = "succeeded"
+	}
+	return issuetracker.Run{
+		ID:    fmt.Sprintf("%s/%s/%s", repo.GetNamespace(), repo.GetName(), name),
+		Title: fmt.Sprintf("%s/%s %s", event.Organization, event.Repository, name),
+		Summary: fmt.Sprintf("PipelineRun %s has %s on %s of %s", pr.GetName(), state,
+			formatting.ShortSHA(event.SHA), formatting.SanitizeBranch(event.BaseBranch)),
+		URL:         consoleURL,
+		Succeeded:   succeeded,
+		Application: provider.GetApplicationName(pacInfo, repo),
+	}
+}
@@ -0,0 +1,136 @@
+package reconciler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	kitesthelper "github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestLinkIssues(t *testing.T) {
+	tests := []struct {
+		name      string
+		issueKeys string
+		secrets   map[string]string
+		wantPaths []string
+	}{
+		{
+			name:      "link the issues",
+			issueKeys: "PROJ-12,OPS-7",
+			secrets:   map[string]string{"jira": "secret"},
+			wantPaths: []string{"/rest/api/2/issue/PROJ-12/remotelink", "/rest/api/2/issue/OPS-7/remotelink"},
+		},
+		{
+			name:      "too many issue keys",
+			issueKeys: "P-1,P-2,P-3,P-4,P-5,P-6,P-7,P-8,P-9,P-10,P-11,P-12",
+			secrets:   map[string]string{"jira": "secret"},
+			wantPaths: []string{
+				"/rest/api/2/issue/P-1/remotelink", "/rest/api/2/issue/P-2/remotelink", "/rest/api/2/issue/P-3/remotelink",
+				"/rest/api/2/issue/P-4/remotelink", "/rest/api/2/issue/P-5/remotelink", "/rest/api/2/issue/P-6/remotelink",
+				"/rest/api/2/issue/P-7/remotelink", "/rest/api/2/issue/P-8/remotelink", "/rest/api/2/issue/P-9/remotelink",
+				"/rest/api/2/issue/P-10/remotelink",
+			},
+		},
+		{
+			name:    "no issue keys",
+			secrets: map[string]string{"jira": "secret"},
+		},
+		{
+			name:      "no token",
+			issueKeys: "PROJ-12",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPaths := []string{}
+			titles := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPaths = append(gotPaths, r.URL.Path)
+				link := struct {
+					Object struct {
+						Title string `json:"title"`
+					} `json:"object"`
+				}{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&link))
+				titles = append(titles, link.Object.Title)
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			run := params.New()
+			run.Clients = clients.Clients{Kube: stdata.Kube}
+			run.Clients.SetConsoleUI(&consoleui.TektonDashboard{BaseURL: "https://dashboard"})
+			logger := zap.NewNop().Sugar()
+			r := &Reconciler{
+				run:          run,
+				kinteract:    &kitesthelper.KinterfaceTest{GetSecretResult: tt.secrets},
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{
+					IssueTracker: &v1alpha1.IssueTracker{Type: "jira", URL: server.URL, Secret: v1alpha1.Secret{Name: "jira"}},
+				}},
+			}
+			pr := &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "build-abcde", Namespace: "ns",
+					Annotations: map[string]string{keys.OriginalPRName: "build", keys.IssueKeys: tt.issueKeys},
+				},
+				Status: tektonv1.PipelineRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+					{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse},
+				}}},
+			}
+			event := &info.Event{Organization: "org", Repository: "repo", SHA: "1234567890abcdef", BaseBranch: "refs/heads/main"}
+
+			r.linkIssues(ctx, logger, &info.PacOpts{}, repo, event, pr)
+			assert.DeepEqual(t, gotPaths, append([]string{}, tt.wantPaths...))
+			for _, title := range titles {
+				assert.Equal(t, title, "❌ org/repo build")
+			}
+		})
+	}
+}
+
+func TestIssueTrackerRun(t *testing.T) {
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+		Spec:       v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{ApplicationName: "CI"}},
+	}
+	pr := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-abcde", Namespace: "ns", Annotations: map[string]string{keys.OriginalPRName: "build"}},
+		Status: tektonv1.PipelineRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+			{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue},
+		}}},
+	}
+	event := &info.Event{Organization: "org", Repository: "repo", SHA: "1234567890abcdef", BaseBranch: "refs/heads/main"}
+
+	run := issueTrackerRun(&info.PacOpts{}, repo, event, pr, "https://dashboard/build-abcde")
+	assert.Equal(t, run.ID, "ns/repo/build")
+	assert.Equal(t, run.Title, "org/repo build")
+	assert.Equal(t, run.Summary, "PipelineRun build-abcde has succeeded on 1234567 of main")
+	assert.Equal(t, run.URL, "https://dashboard/build-abcde")
+	assert.Assert(t, run.Succeeded)
+	assert.Equal(t, run.Application, "CI")
+}
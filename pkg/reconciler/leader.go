@@ -0,0 +1,127 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/sort"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/controller"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// claimTimeout is how long the claim of a replica on the report of a
+// PipelineRun is honoured by the other replicas, after that the replica is
+// considered gone and another one reports the status.
+var claimTimeout = 5 * time.Minute
+
+// promoted rebuilds the queues from the cluster when the replica becomes the
+// leader of a bucket, the queues built before may be stale since another
+// replica was reconciling the PipelineRuns.
+func (r *Reconciler) promoted(ctx context.Context, logger *zap.SugaredLogger) func(pkgreconciler.Bucket) {
+	return func(bkt pkgreconciler.Bucket) {
+		logger.Infof("promoted as the leader of the bucket %s, rebuilding the queues", bkt.Name())
+		r.qm.ResetQueues()
+		if err := r.qm.InitQueues(ctx, r.run.Clients.Tekton, r.run.Clients.PipelineAsCode); err != nil {
+			logger.Errorf("failed to rebuild the queues on promotion: %v", err)
+		}
+	}
+}
+
+// syncRepositoryQueue rebuilds the queue of the repository from the
+// PipelineRuns of the cluster when the watcher is sharded over several
+// buckets, the PipelineRuns of a repository may then be reconciled by
+// different replicas.
+func (r *Reconciler) syncRepositoryQueue(repo *v1alpha1.Repository) error {
+	if !r.sharded {
+		return nil
+	}
+	selector := labels.SelectorFromSet(labels.Set{keys.Repository: formatting.CleanValueKubernetes(repo.GetName())})
+	prs, err := r.pipelineRunLister.PipelineRuns(repo.GetNamespace()).List(selector)
+	if err != nil {
+		return fmt.Errorf("cannot list the pipelineruns of the repository %s: %w", repo.GetName(), err)
+	}
+	running, queued := []*tektonv1.PipelineRun{}, []*tektonv1.PipelineRun{}
+	for _, pr := range sortByCreationTimestamp(prs) {
+		switch pr.GetAnnotations()[keys.State] {
+		case kubeinteraction.StateStarted:
+			if !pr.IsDone() {
+				running = append(running, pr)
+			}
+		case kubeinteraction.StateQueued:
+			if pr.Spec.Status == tektonv1.PipelineRunSpecStatusPending {
+				queued = append(queued, pr)
+			}
+		}
+	}
+	r.qm.SyncRepository(repo, running, queued)
+	return nil
+}
+
+// claimPipelineRun claims the report of the final status of the PipelineRun
+// for this replica. The claim is a patch with the resource version of the
+// PipelineRun as a precondition so only one replica wins when several of
+// them reconcile it during a change of leader. It returns false when another
+// replica has claimed it, with a requeue while the claim is still valid.
+func (r *Reconciler) claimPipelineRun(ctx context.Context, logger *zap.SugaredLogger, pr *tektonv1.PipelineRun) (bool, error) {
+	if claim, ok := pr.GetAnnotations()[keys.StatusReporter]; ok {
+		holder, claimedAt, _ := strings.Cut(claim, "@")
+		since, err := time.Parse(time.RFC3339, claimedAt)
+		if holder == r.identity {
+			return true, nil
+		}
+		if age := time.Since(since); err == nil && age < claimTimeout {
+			// check again once the claim expired in case the replica is gone
+			logger.Infof("the status of pipelineRun %s/%s is reported by %s, skipping", pr.GetNamespace(), pr.GetName(), holder)
+			return false, controller.NewRequeueAfter(claimTimeout - age)
+		}
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"resourceVersion": pr.GetResourceVersion(),
+			"annotations": map[string]string{
+				keys.StatusReporter: fmt.Sprintf("%s@%s", r.identity, time.Now().UTC().Format(time.RFC3339)),
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if _, err := r.run.Clients.Tekton.TektonV1().PipelineRuns(pr.GetNamespace()).Patch(ctx, pr.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		if errors.IsConflict(err) {
+			logger.Infof("pipelineRun %s/%s has been claimed by another replica, skipping", pr.GetNamespace(), pr.GetName())
+			return false, nil
+		}
+		return false, fmt.Errorf("cannot claim pipelineRun %s/%s: %w", pr.GetNamespace(), pr.GetName(), err)
+	}
+	return true, nil
+}
+
+func sortByCreationTimestamp(prs []*tektonv1.PipelineRun) []*tektonv1.PipelineRun {
+	objs := make([]runtime.Object, 0, len(prs))
+	for _, pr := range prs {
+		objs = append(objs, pr)
+	}
+	sort.ByField("{.metadata.creationTimestamp}", objs)
+	sorted := make([]*tektonv1.PipelineRun, 0, len(objs))
+	for _, obj := range objs {
+		if pr, ok := obj.(*tektonv1.PipelineRun); ok {
+			sorted = append(sorted, pr)
+		}
+	}
+	return sorted
+}
@@ -0,0 +1,142 @@
+package reconciler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/sync"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/controller"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestClaimPipelineRun(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name        string
+		claim       string
+		wantClaimed bool
+		wantRequeue bool
+		wantHolder  string
+	}{
+		{
+			name:        "not claimed",
+			wantClaimed: true,
+			wantHolder:  "watcher-1",
+		},
+		{
+			name:        "claimed by this replica",
+			claim:       "watcher-1@" + now.Format(time.RFC3339),
+			wantClaimed: true,
+			wantHolder:  "watcher-1",
+		},
+		{
+			name:        "claimed by another replica",
+			claim:       "watcher-2@" + now.Format(time.RFC3339),
+			wantRequeue: true,
+			wantHolder:  "watcher-2",
+		},
+		{
+			name:        "expired claim of another replica",
+			claim:       "watcher-2@" + now.Add(-2*claimTimeout).Format(time.RFC3339),
+			wantClaimed: true,
+			wantHolder:  "watcher-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			observer, _ := zapobserver.New(zap.InfoLevel)
+			logger := zap.New(observer).Sugar()
+			pr := &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "ns", Annotations: map[string]string{}},
+			}
+			if tt.claim != "" {
+				pr.Annotations[keys.StatusReporter] = tt.claim
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: []*tektonv1.PipelineRun{pr}})
+			r := &Reconciler{
+				run:      &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline}},
+				identity: "watcher-1",
+			}
+
+			claimed, err := r.claimPipelineRun(ctx, logger, pr)
+			assert.Equal(t, claimed, tt.wantClaimed)
+			if tt.wantRequeue {
+				ok, after := controller.IsRequeueKey(err)
+				assert.Assert(t, ok, err)
+				assert.Assert(t, after > 0 && after <= claimTimeout)
+			} else {
+				assert.NilError(t, err)
+			}
+
+			got, err := stdata.Pipeline.TektonV1().PipelineRuns("ns").Get(ctx, "pr", metav1.GetOptions{})
+			assert.NilError(t, err)
+			holder, _, _ := strings.Cut(got.GetAnnotations()[keys.StatusReporter], "@")
+			assert.Equal(t, holder, tt.wantHolder)
+		})
+	}
+}
+
+func TestSyncRepositoryQueue(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observer).Sugar()
+	concurrency := 1
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+		Spec:       v1alpha1.RepositorySpec{ConcurrencyLimit: &concurrency},
+	}
+	newPR := func(name, state string, created time.Time) *tektonv1.PipelineRun {
+		pr := &tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "ns",
+				CreationTimestamp: metav1.Time{Time: created},
+				Labels:            map[string]string{keys.Repository: "repo", keys.State: state},
+				Annotations:       map[string]string{keys.State: state},
+			},
+		}
+		if state == kubeinteraction.StateQueued {
+			pr.Spec.Status = tektonv1.PipelineRunSpecStatusPending
+		}
+		return pr
+	}
+	now := time.Now()
+	prs := []*tektonv1.PipelineRun{
+		newPR("third", kubeinteraction.StateQueued, now.Add(2*time.Second)),
+		newPR("first", kubeinteraction.StateStarted, now),
+		newPR("second", kubeinteraction.StateQueued, now.Add(time.Second)),
+		newPR("done", kubeinteraction.StateCompleted, now.Add(-time.Minute)),
+	}
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: prs})
+
+	for _, sharded := range []bool{false, true} {
+		t.Run(fmt.Sprintf("sharded %v", sharded), func(t *testing.T) {
+			r := &Reconciler{
+				pipelineRunLister: stdata.PipelineLister,
+				qm:                sync.NewQueueManager(logger),
+				sharded:           sharded,
+			}
+			assert.NilError(t, r.syncRepositoryQueue(repo))
+			if !sharded {
+				assert.Equal(t, len(r.qm.RunningPipelineRuns(repo)), 0)
+				return
+			}
+			assert.DeepEqual(t, r.qm.RunningPipelineRuns(repo), []string{"ns/first"})
+			assert.DeepEqual(t, r.qm.QueuedPipelineRuns(repo), []string{"ns/second", "ns/third"})
+		})
+	}
+}
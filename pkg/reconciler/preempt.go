@@ -0,0 +1,95 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/sync"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// preemptQueued cancels the queued PipelineRuns of the Repository with a lower
+// priority than the queued PipelineRun, when its priority from the
+// queue_preemption setting reaches its min_priority. The PipelineRuns of the same
+// event and the running ones are never preempted.
+func (r *Reconciler) preemptQueued(ctx context.Context, logger *zap.SugaredLogger, repo *v1alpha1.Repository, pr *tektonv1.PipelineRun) {
+	if repo.Spec.Settings == nil || repo.Spec.Settings.QueuePreemption == nil {
+		return
+	}
+	preemption := repo.Spec.Settings.QueuePreemption
+	priority := sync.PipelineRunPriority(preemption, pr)
+	if priority < preemption.MinPriority {
+		return
+	}
+	selector := labels.SelectorFromSet(labels.Set{
+		keys.Repository: formatting.CleanValueKubernetes(repo.GetName()),
+		keys.State:      kubeinteraction.StateQueued,
+	})
+	queued, err := r.pipelineRunLister.PipelineRuns(repo.GetNamespace()).List(selector)
+	if err != nil {
+		logger.Warnf("cannot list the queued pipelineruns of the repository %s to preempt: %v", repo.GetName(), err)
+		return
+	}
+	siblings := strings.Split(pr.GetAnnotations()[keys.ExecutionOrder], ",")
+	pacInfo := r.run.Info.GetPacOpts()
+	owner := pacInfo.MetricsAggregation().Owner(pr.GetAnnotations()[keys.URLOrg], repo.GetNamespace(), repo.GetName())
+	for _, victim := range queued {
+		if victim.Spec.Status != tektonv1.PipelineRunSpecStatusPending ||
+			sync.PipelineRunPriority(preemption, victim) >= priority ||
+			slices.Contains(siblings, victim.GetNamespace()+"/"+victim.GetName()) {
+			continue
+		}
+		if _, err := action.PatchPipelineRun(ctx, logger, "preemption", r.run.Clients.Tekton, victim, map[string]any{
+			"metadata": map[string]any{
+				"annotations": map[string]string{keys.PreemptedBy: pr.GetName()},
+			},
+			"spec": map[string]any{
+				"status": tektonv1.PipelineRunSpecStatusCancelled,
+			},
+		}); err != nil {
+			logger.Warnf("cannot preempt the queued pipelinerun %s: %v", victim.GetName(), err)
+			continue
+		}
+		r.qm.DropFromQueue(repo, victim)
+		metrics.CountQueuePreemption(owner)
+		r.eventEmitter.EmitPipelineRunMessage(victim, zap.InfoLevel, "PipelineRunPreempted",
+			fmt.Sprintf("queued PipelineRun cancelled by the PipelineRun %s with the higher priority %d", pr.GetName(), priority))
+	}
+}
+
+// withoutPreempted removes the preempted PipelineRuns from the execution
+// order of an event, they are not queued again with the other PipelineRuns of
+// the event.
+func (r *Reconciler) withoutPreempted(order []string) []string {
+	kept := []string{}
+	for _, key := range order {
+		ns, name, _ := strings.Cut(key, "/")
+		if pr, err := r.pipelineRunLister.PipelineRuns(ns).Get(name); err == nil {
+			if _, ok := pr.GetAnnotations()[keys.PreemptedBy]; ok {
+				continue
+			}
+		}
+		kept = append(kept, key)
+	}
+	return kept
+}
+
+// preemptedStatusText is the status of a PipelineRun cancelled in the queue
+// by a higher-priority one.
+func preemptedStatusText(pr *tektonv1.PipelineRun, messages formatting.Messages) string {
+	by, ok := pr.GetAnnotations()[keys.PreemptedBy]
+	if !ok {
+		return ""
+	}
+	return messages.T("status.preempted", by)
+}
@@ -0,0 +1,103 @@
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/sync"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestPreemptQueued(t *testing.T) {
+	newPR := func(name, priority, order string) *tektonv1.PipelineRun {
+		annotations := map[string]string{keys.State: kubeinteraction.StateQueued, keys.ExecutionOrder: order}
+		if priority != "" {
+			annotations[keys.Priority] = priority
+		}
+		return &tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "ns",
+				Labels:      map[string]string{keys.Repository: "repo", keys.State: kubeinteraction.StateQueued},
+				Annotations: annotations,
+			},
+			Spec: tektonv1.PipelineRunSpec{Status: tektonv1.PipelineRunSpecStatusPending},
+		}
+	}
+	tests := []struct {
+		name          string
+		preemption    *v1alpha1.QueuePreemption
+		wantPreempted []string
+	}{
+		{
+			name:          "lower priorities preempted",
+			preemption:    &v1alpha1.QueuePreemption{MinPriority: 100},
+			wantPreempted: []string{"lint", "docs"},
+		},
+		{
+			name:       "below the min priority",
+			preemption: &v1alpha1.QueuePreemption{MinPriority: 200},
+		},
+		{
+			name: "no preemption",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			hotfix := newPR("hotfix", "100", "ns/hotfix,ns/hotfix-e2e")
+			prs := []*tektonv1.PipelineRun{
+				hotfix,
+				// the PipelineRuns of the same event are not preempted
+				newPR("hotfix-e2e", "", "ns/hotfix,ns/hotfix-e2e"),
+				newPR("lint", "", "ns/lint,ns/docs"),
+				newPR("docs", "10", "ns/lint,ns/docs"),
+				newPR("release", "100", "ns/release"),
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: prs})
+			logger := zap.NewNop().Sugar()
+			r := &Reconciler{
+				pipelineRunLister: stdata.PipelineLister,
+				qm:                sync.NewQueueManager(logger),
+				run: &params.Run{
+					Clients: clients.Clients{Tekton: stdata.Pipeline, Kube: stdata.Kube},
+					Info:    info.Info{Pac: &info.PacOpts{}},
+				},
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec:       v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{QueuePreemption: tt.preemption}},
+			}
+			r.preemptQueued(ctx, logger, repo, hotfix)
+
+			preempted := []string{}
+			for _, pr := range prs {
+				got, err := stdata.Pipeline.TektonV1().PipelineRuns("ns").Get(ctx, pr.GetName(), metav1.GetOptions{})
+				assert.NilError(t, err)
+				if by, ok := got.GetAnnotations()[keys.PreemptedBy]; ok {
+					assert.Equal(t, by, "hotfix")
+					assert.Equal(t, got.Spec.Status, tektonv1.PipelineRunSpecStatus(tektonv1.PipelineRunSpecStatusCancelled))
+					assert.Assert(t, preemptedStatusText(got, formatting.MessagesFor(formatting.DefaultLocale)) != "")
+					preempted = append(preempted, got.GetName())
+				}
+			}
+			if tt.wantPreempted == nil {
+				tt.wantPreempted = []string{}
+			}
+			assert.DeepEqual(t, preempted, tt.wantPreempted)
+		})
+	}
+}
@@ -34,6 +34,8 @@ func (r *Reconciler) queuePipelineRun(ctx context.Context, logger *zap.SugaredLo
 		return fmt.Errorf("updateError: %w", err)
 	}
 
+	repo = r.mergeRepositoryDefaults(repo)
+
 	// if concurrency was set and later removed or changed to zero
 	// then remove pipelineRun from Queue and update pending state to running
 	if repo.Spec.ConcurrencyLimit != nil && *repo.Spec.ConcurrencyLimit == 0 {
@@ -44,22 +46,53 @@ func (r *Reconciler) queuePipelineRun(ctx context.Context, logger *zap.SugaredLo
 		return nil
 	}
 
-	orderedList := strings.Split(order, ",")
+	if err := r.syncRepositoryQueue(repo); err != nil {
+		return err
+	}
+	r.preemptQueued(ctx, logger, repo, pr)
+	orderedList := r.withoutPreempted(strings.Split(order, ","))
 	acquired, err := r.qm.AddListToQueue(repo, orderedList)
 	if err != nil {
-		return fmt.Errorf("failed to add to queue: %s: %w", pr.GetName(), err)
+		err = fmt.Errorf("failed to add to queue: %s: %w", pr.GetName(), err)
+		r.setQueueCondition(ctx, logger, repo, err)
+		return err
 	}
 
 	for _, prKeys := range acquired {
-		nsName := strings.Split(prKeys, "/")
-		pr, err = r.run.Clients.Tekton.TektonV1().PipelineRuns(nsName[0]).Get(ctx, nsName[1], metav1.GetOptions{})
-		if err != nil {
-			logger.Info("failed to get pr with namespace and name: ", nsName[0], nsName[1])
+		if err := r.startQueuedPipelineRun(ctx, logger, repo, prKeys); err != nil {
+			err = fmt.Errorf("failed to update pipelineRun to in_progress: %w", err)
+			r.setQueueCondition(ctx, logger, repo, err)
 			return err
 		}
-		if err := r.updatePipelineRunToInProgress(ctx, logger, repo, pr); err != nil {
-			return fmt.Errorf("failed to update pipelineRun to in_progress: %w", err)
-		}
 	}
+	r.setQueueCondition(ctx, logger, repo, nil)
 	return nil
 }
+
+// setQueueCondition sets the QueueHealthy condition of the Repository after
+// an operation on its queue.
+func (r *Reconciler) setQueueCondition(ctx context.Context, logger *zap.SugaredLogger, repo *v1alpha1.Repository, err error) {
+	if uerr := updateRepositoryConditions(ctx, r.run, repo, queueCondition(err)); uerr != nil {
+		logger.Errorf("cannot update the conditions of the repository %s: %v", repo.GetName(), uerr)
+	}
+}
+
+// startQueuedPipelineRun moves the queued PipelineRun of the key to running,
+// with a global concurrency limit it may belong to another Repository than
+// the one reconciled.
+func (r *Reconciler) startQueuedPipelineRun(ctx context.Context, logger *zap.SugaredLogger, repo *v1alpha1.Repository, key string) error {
+	nsName := strings.Split(key, "/")
+	pr, err := r.run.Clients.Tekton.TektonV1().PipelineRuns(nsName[0]).Get(ctx, nsName[1], metav1.GetOptions{})
+	if err != nil {
+		logger.Info("failed to get pr with namespace and name: ", nsName[0], nsName[1])
+		return err
+	}
+	if repoName := pr.GetAnnotations()[keys.Repository]; pr.GetNamespace() != repo.GetNamespace() || repoName != repo.GetName() {
+		other, err := r.repoLister.Repositories(pr.GetNamespace()).Get(repoName)
+		if err != nil {
+			return fmt.Errorf("cannot get the repository %s/%s of the queued pipelineRun %s: %w", pr.GetNamespace(), repoName, pr.GetName(), err)
+		}
+		repo = r.mergeRepositoryDefaults(other)
+	}
+	return r.updatePipelineRunToInProgress(ctx, logger, repo, pr)
+}
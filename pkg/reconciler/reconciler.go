@@ -4,13 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"time"
 
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	pipelinerunreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1/pipelinerun"
 	tektonv1lister "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/apis"
 	"knative.dev/pkg/logging"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/system"
@@ -23,10 +25,12 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 	pacapi "github.com/openshift-pipelines/pipelines-as-code/pkg/generated/listers/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	kstatus "github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction/status"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
 	pac "github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/sync"
@@ -43,6 +47,11 @@ type Reconciler struct {
 	eventEmitter      *events.EventEmitter
 	globalRepo        *v1alpha1.Repository
 	secretNS          string
+	// identity is the name of the replica claiming the reports
+	identity string
+	// sharded is set when the PipelineRuns are spread over several buckets
+	// of the leader election, thus over several replicas
+	sharded bool
 }
 
 var (
@@ -53,7 +62,7 @@ var (
 // ReconcileKind is the main entry point for reconciling PipelineRun resources.
 func (r *Reconciler) ReconcileKind(ctx context.Context, pr *tektonv1.PipelineRun) pkgreconciler.Event {
 	ctx = info.StoreNS(ctx, system.Namespace())
-	logger := logging.FromContext(ctx).With("namespace", pr.GetNamespace())
+	logger := logging.FromContext(ctx).With("namespace", pr.GetNamespace(), "run-id", pr.GetAnnotations()[keys.RunID])
 	// if pipelineRun is in completed or failed state then return
 	state, exist := pr.GetAnnotations()[keys.State]
 	if exist && (state == kubeinteraction.StateCompleted || state == kubeinteraction.StateFailed) {
@@ -67,6 +76,13 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pr *tektonv1.PipelineRun
 		}
 	}
 
+	r.qm.SetGlobalLimit(r.run.Info.GetPacOpts().GlobalConcurrencyLimit)
+
+	// pipelines held by a freeze window are queued once it ends
+	if state == kubeinteraction.StateFrozen && pr.Spec.Status == tektonv1.PipelineRunSpecStatusPending {
+		return r.thawPipelineRun(ctx, logger, pr)
+	}
+
 	// queue pipelines which are in queued state and pending status
 	// if status is not pending, it could be canceled so let it be reported, even if state is queued
 	if state == kubeinteraction.StateQueued && pr.Spec.Status == tektonv1.PipelineRunSpecStatusPending {
@@ -87,6 +103,13 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pr *tektonv1.PipelineRun
 		return nil
 	}
 
+	// when several replicas are running, make sure only one reports the status
+	if r.sharded {
+		if claimed, err := r.claimPipelineRun(ctx, logger, lpr); err != nil || !claimed {
+			return err
+		}
+	}
+
 	// If we have a controllerInfo annotation, then we need to get the
 	// configmap configuration for it
 	//
@@ -127,7 +150,7 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, pr *tektonv1.PipelineRun
 
 	if repo, err := r.reportFinalStatus(ctx, logger, &pacInfo, event, pr, detectedProvider); err != nil {
 		msg := fmt.Sprintf("report status: %v", err)
-		r.eventEmitter.EmitMessage(repo, zap.ErrorLevel, "RepositoryReportFinalStatus", msg)
+		r.eventEmitter.EmitMessage(repo, zap.ErrorLevel, events.ReasonForError(err, "RepositoryReportFinalStatus"), msg)
 		return err
 	}
 	return nil
@@ -140,13 +163,7 @@ func (r *Reconciler) reportFinalStatus(ctx context.Context, logger *zap.SugaredL
 		return nil, fmt.Errorf("reportFinalStatus: %w", err)
 	}
 
-	r.secretNS = repo.GetNamespace()
-	if r.globalRepo, err = r.repoLister.Repositories(r.run.Info.Kube.Namespace).Get(r.run.Info.Controller.GlobalRepository); err == nil && r.globalRepo != nil {
-		if repo.Spec.GitProvider != nil && repo.Spec.GitProvider.Secret == nil && r.globalRepo.Spec.GitProvider != nil && r.globalRepo.Spec.GitProvider.Secret != nil {
-			r.secretNS = r.globalRepo.GetNamespace()
-		}
-		repo.Spec.Merge(r.globalRepo.Spec)
-	}
+	repo = r.mergeRepositoryDefaults(repo)
 
 	cp := customparams.NewCustomParams(event, repo, r.run, r.kinteract, r.eventEmitter, nil)
 	maptemplate, _, err := cp.GetParams(ctx)
@@ -179,10 +196,15 @@ func (r *Reconciler) reportFinalStatus(ctx context.Context, logger *zap.SugaredL
 	}
 
 	finalState := kubeinteraction.StateCompleted
-	newPr, err := r.postFinalStatus(ctx, logger, pacInfo, provider, event, pr)
+	newPr, err := r.postFinalStatus(ctx, logger, pacInfo, provider, event, repo, pr)
 	if err != nil {
-		logger.Errorf("failed to post final status, moving on: %v", err)
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, events.ReasonForError(err, "PipelineRunReportFailed"),
+			fmt.Sprintf("failed to post final status, moving on: %v", err))
 		finalState = kubeinteraction.StateFailed
+	} else {
+		r.eventEmitter.EmitPipelineRunMessage(newPr, zap.InfoLevel, "PipelineRunStatusReported",
+			"final status of the PipelineRun reported to the git provider")
+		r.autoMergeTrustedBot(ctx, logger, repo, event, provider)
 	}
 
 	if err := r.updateRepoRunStatus(ctx, logger, newPr, repo, event); err != nil {
@@ -193,24 +215,40 @@ func (r *Reconciler) reportFinalStatus(ctx context.Context, logger *zap.SugaredL
 		return repo, fmt.Errorf("cannot update state: %w", err)
 	}
 
-	if err := r.emitMetrics(pr); err != nil {
+	if repo.Spec.Settings != nil && repo.Spec.Settings.AutoRetry != nil {
+		trStatus := kstatus.GetStatusFromTaskStatusOrFromAsking(ctx, pr, r.run)
+		if _, err := r.autoRetry(ctx, logger, repo, pr, trStatus); err != nil {
+			r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, "PipelineRunAutoRetryFailed", err.Error())
+		}
+	}
+	// a retry has its own cloud credentials
+	r.deleteCloudCredentials(ctx, logger, pr)
+
+	if event.TriggerTarget == triggertype.Push && pr.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+		r.fanOut(ctx, logger, repo, pr)
+	}
+
+	r.linkIssues(ctx, logger, pacInfo, repo, event, pr)
+
+	if err := r.emitMetrics(pr, pacInfo.MetricsAggregation()); err != nil {
 		logger.Error("failed to emit metrics: ", err)
 	}
 
 	// remove pipelineRun from Queue and start the next one
+	if err := r.syncRepositoryQueue(repo); err != nil {
+		return repo, err
+	}
 	next := r.qm.RemoveFromQueue(repo, pr)
 	if next != "" {
-		key := strings.Split(next, "/")
-		pr, err := r.run.Clients.Tekton.TektonV1().PipelineRuns(key[0]).Get(ctx, key[1], metav1.GetOptions{})
+		err := r.startQueuedPipelineRun(ctx, logger, repo, next)
+		r.setQueueCondition(ctx, logger, repo, err)
 		if err != nil {
-			return repo, fmt.Errorf("cannot get pipeline for next in queue: %w", err)
-		}
-
-		if err := r.updatePipelineRunToInProgress(ctx, logger, repo, pr); err != nil {
 			return repo, fmt.Errorf("failed to update status: %w", err)
 		}
 	}
 
+	r.storeResultsRecord(ctx, logger, pacInfo, pr)
+
 	if err := r.cleanupPipelineRuns(ctx, logger, pacInfo, repo, pr); err != nil {
 		return repo, fmt.Errorf("error cleaning pipelineruns: %w", err)
 	}
@@ -219,11 +257,18 @@ func (r *Reconciler) reportFinalStatus(ctx context.Context, logger *zap.SugaredL
 }
 
 func (r *Reconciler) updatePipelineRunToInProgress(ctx context.Context, logger *zap.SugaredLogger, repo *v1alpha1.Repository, pr *tektonv1.PipelineRun) error {
+	if !r.mintCloudCredentials(ctx, logger, repo, pr) {
+		return nil
+	}
 	pr, err := r.updatePipelineRunState(ctx, logger, pr, kubeinteraction.StateStarted)
 	if err != nil {
 		return fmt.Errorf("cannot update state: %w", err)
 	}
+	r.eventEmitter.EmitPipelineRunMessage(pr, zap.InfoLevel, "PipelineRunStarted", "PipelineRun started from the queue of the Repository")
 	pacInfo := r.run.Info.GetPacOpts()
+	owner := pacInfo.MetricsAggregation().Owner(pr.GetAnnotations()[keys.URLOrg], repo.GetNamespace(), repo.GetName())
+	metrics.RecordQueueWait(owner, time.Since(pr.GetCreationTimestamp().Time))
+
 	detectedProvider, event, err := r.detectProvider(ctx, logger, pr)
 	if err != nil {
 		logger.Error(err)
@@ -262,8 +307,13 @@ func (r *Reconciler) updatePipelineRunToInProgress(ctx context.Context, logger *
 		ConsoleURL:      consoleURL,
 		TknBinary:       settings.TknBinaryName,
 		TknBinaryURL:    settings.TknBinaryURL,
+		Profile:         formatting.ProfileFor(detectedProvider.GetConfig().Name, repo.Spec.Settings),
 	}
-	msg, err := mt.MakeTemplate(formatting.StartingPipelineRunText)
+	startingTemplate := ""
+	if repo.Spec.Settings != nil && repo.Spec.Settings.StatusTemplates != nil {
+		startingTemplate = repo.Spec.Settings.StatusTemplates.Starting
+	}
+	msg, err := mt.MakeCustomTemplate(startingTemplate, mt.Profile.StartingTemplate())
 	if err != nil {
 		return fmt.Errorf("cannot create message template: %w", err)
 	}
@@ -275,12 +325,15 @@ func (r *Reconciler) updatePipelineRunToInProgress(ctx context.Context, logger *
 		PipelineRunName:         pr.GetName(),
 		PipelineRun:             pr,
 		OriginalPipelineRunName: pr.GetAnnotations()[keys.OriginalPRName],
+		RunID:                   pr.GetAnnotations()[keys.RunID],
+		Stage:                   pr.GetAnnotations()[keys.Stage],
 	}
 
 	if err := createStatusWithRetry(ctx, logger, detectedProvider, event, status); err != nil {
 		// if failed to report status for running state, let the pipelineRun continue,
 		// pipelineRun is already started so we will try again once it completes
-		logger.Errorf("failed to report status to running on provider continuing! error: %v", err)
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.ErrorLevel, events.ReasonForError(err, "PipelineRunReportFailed"),
+			fmt.Sprintf("failed to report status to running on provider continuing! error: %v", err))
 		return nil
 	}
 
@@ -312,3 +365,27 @@ func (r *Reconciler) updatePipelineRunState(ctx context.Context, logger *zap.Sug
 	}
 	return patchedPR, nil
 }
+
+// mergeRepositoryDefaults returns a copy of the Repository with the settings
+// of the most specific org defaults and then of the global repository, it
+// sets the namespace of the git provider secret.
+func (r *Reconciler) mergeRepositoryDefaults(repo *v1alpha1.Repository) *v1alpha1.Repository {
+	repo, r.globalRepo, r.secretNS = mergeDefaults(r.run, r.repoLister, repo)
+	return repo
+}
+
+// mergeDefaults returns a copy of the Repository merged with its defaults,
+// the global repository if any and the namespace of the git provider secret.
+func mergeDefaults(run *params.Run, repoLister pacapi.RepositoryLister, repo *v1alpha1.Repository) (*v1alpha1.Repository, *v1alpha1.Repository, string) {
+	repo = repo.DeepCopy()
+	defaults := []*v1alpha1.Repository{}
+	selector := labels.SelectorFromSet(labels.Set{keys.OrgDefaults: "true"})
+	if orgDefaults, err := repoLister.Repositories(run.Info.Kube.Namespace).List(selector); err == nil {
+		defaults = v1alpha1.OrgDefaultsFor(repo.Spec.URL, repo.GetNamespace(), orgDefaults)
+	}
+	globalRepo, err := repoLister.Repositories(run.Info.Kube.Namespace).Get(run.Info.Controller.GlobalRepository)
+	if err == nil && globalRepo != nil {
+		defaults = append(defaults, globalRepo)
+	}
+	return repo, globalRepo, repo.MergeDefaults(defaults...)
+}
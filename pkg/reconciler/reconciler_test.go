@@ -13,6 +13,7 @@ import (
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/metrics"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
@@ -208,7 +209,8 @@ func TestReconciler_ReconcileKind(t *testing.T) {
 						},
 					},
 				},
-				metrics: metrics,
+				metrics:      metrics,
+				eventEmitter: events.NewEventEmitter(stdata.Kube, fakelogger),
 			}
 			r.run.Clients.SetConsoleUI(consoleui.FallBackConsole{})
 			pacInfo := &info.PacOpts{
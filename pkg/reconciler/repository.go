@@ -0,0 +1,218 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/generated/injection/informers/pipelinesascode/v1alpha1/repository"
+	pacapi "github.com/openshift-pipelines/pipelines-as-code/pkg/generated/listers/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+)
+
+// secretRecheckInterval is how often the secrets of a Repository which are
+// missing or invalid are checked again, the Secrets are not watched.
+var secretRecheckInterval = time.Minute
+
+// repositoryReconciler sets the conditions of the Repositories depending on
+// their configuration, the QueueHealthy condition is set by the PipelineRun
+// reconciler managing the queues.
+type repositoryReconciler struct {
+	pkgreconciler.LeaderAwareFuncs
+
+	run        *params.Run
+	repoLister pacapi.RepositoryLister
+}
+
+var (
+	_ controller.Reconciler     = (*repositoryReconciler)(nil)
+	_ pkgreconciler.LeaderAware = (*repositoryReconciler)(nil)
+)
+
+// NewRepositoryController constructs the controller setting the conditions
+// of the Repositories.
+func NewRepositoryController() func(context.Context, configmap.Watcher) *controller.Impl {
+	return func(ctx context.Context, _ configmap.Watcher) *controller.Impl {
+		ctx = info.StoreNS(ctx, system.Namespace())
+		log := logging.FromContext(ctx)
+
+		run := params.New()
+		if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+			log.Fatal("failed to init clients : ", err)
+		}
+		go params.StartConfigSync(ctx, run)
+
+		repoInformer := repository.Get(ctx)
+		repoLister := repoInformer.Lister()
+		rr := &repositoryReconciler{
+			LeaderAwareFuncs: pkgreconciler.LeaderAwareFuncs{
+				PromoteFunc: func(bkt pkgreconciler.Bucket, enq func(pkgreconciler.Bucket, types.NamespacedName)) error {
+					repos, err := repoLister.List(labels.Everything())
+					if err != nil {
+						return err
+					}
+					for _, repo := range repos {
+						enq(bkt, types.NamespacedName{Namespace: repo.GetNamespace(), Name: repo.GetName()})
+					}
+					return nil
+				},
+			},
+			run:        run,
+			repoLister: repoLister,
+		}
+		c := controller.NewContext(ctx, rr, controller.ControllerOptions{WorkQueueName: "RepositoryConditions", Logger: log.Named("RepositoryConditions")})
+
+		if _, err := repoInformer.Informer().AddEventHandler(controller.HandleAll(c.Enqueue)); err != nil {
+			log.Panicf("Couldn't register Repository informer event handler: %w", err)
+		}
+		return c
+	}
+}
+
+// Reconcile implements controller.Reconciler.
+func (rr *repositoryReconciler) Reconcile(ctx context.Context, key string) error {
+	logger := logging.FromContext(ctx)
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		logger.Errorf("invalid resource key: %s", key)
+		return nil
+	}
+	if !rr.IsLeaderFor(types.NamespacedName{Namespace: namespace, Name: name}) {
+		return nil
+	}
+	repo, err := rr.repoLister.Repositories(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	merged, _, secretNS := mergeDefaults(rr.run, rr.repoLister, repo)
+	conditions := []metav1.Condition{
+		rr.secretCondition(ctx, merged, secretNS),
+		rr.webhookCondition(ctx, merged, secretNS),
+	}
+	if repo.GetCondition(v1alpha1.RepositoryConditionQueueHealthy) == nil {
+		conditions = append(conditions, queueCondition(nil))
+	}
+	if err := updateRepositoryConditions(ctx, rr.run, repo, conditions...); err != nil {
+		return err
+	}
+	for _, condition := range conditions {
+		if condition.Status != metav1.ConditionTrue {
+			return controller.NewRequeueAfter(secretRecheckInterval)
+		}
+	}
+	return nil
+}
+
+// secretCondition checks that the git provider token can be read, the
+// Repositories without one are using the GitHub App.
+func (rr *repositoryReconciler) secretCondition(ctx context.Context, repo *v1alpha1.Repository, secretNS string) metav1.Condition {
+	condition := metav1.Condition{Type: v1alpha1.RepositoryConditionSecretValid}
+	if repo.Spec.GitProvider == nil || repo.Spec.GitProvider.Secret == nil || repo.Spec.GitProvider.Secret.Name == "" {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "GitHubApp"
+		condition.Message = "no git provider secret, the GitHub App token is used"
+		return condition
+	}
+	return rr.checkSecret(ctx, condition, "git provider", repo.Spec.GitProvider.Secret, secretNS, pipelineascode.DefaultGitProviderSecretKey)
+}
+
+// webhookCondition checks that the webhook secret validating the payloads
+// can be read, the GitHub App payloads are validated with the secret of the
+// controller.
+func (rr *repositoryReconciler) webhookCondition(ctx context.Context, repo *v1alpha1.Repository, secretNS string) metav1.Condition {
+	condition := metav1.Condition{Type: v1alpha1.RepositoryConditionWebhookConfigured}
+	switch {
+	case repo.Spec.GitProvider == nil || repo.Spec.GitProvider.Secret == nil || repo.Spec.GitProvider.Secret.Name == "":
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "GitHubApp"
+		condition.Message = "the payloads are validated with the webhook secret of the GitHub App"
+		return condition
+	case repo.Spec.GitProvider.WebhookSecret == nil || repo.Spec.GitProvider.WebhookSecret.Name == "":
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NoWebhookSecret"
+		condition.Message = "no webhook secret, the payloads of the git provider are not validated"
+		return condition
+	}
+	return rr.checkSecret(ctx, condition, "webhook", repo.Spec.GitProvider.WebhookSecret, secretNS, pipelineascode.DefaultGitProviderWebhookSecretKey)
+}
+
+// checkSecret sets the condition to true when the key of the secret exists.
+func (rr *repositoryReconciler) checkSecret(ctx context.Context, condition metav1.Condition, kind string, ref *v1alpha1.Secret, namespace, defaultKey string) metav1.Condition {
+	key := ref.Key
+	if key == "" {
+		key = defaultKey
+	}
+	secret, err := rr.run.Clients.Kube.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SecretNotFound"
+		condition.Message = fmt.Sprintf("the %s secret %s does not exist in namespace %s", kind, ref.Name, namespace)
+	case err != nil:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "SecretError"
+		condition.Message = fmt.Sprintf("cannot get the %s secret %s: %v", kind, ref.Name, err)
+	case len(secret.Data[key]) == 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SecretKeyNotFound"
+		condition.Message = fmt.Sprintf("the %s secret %s has no key %s", kind, ref.Name, key)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "SecretFound"
+		condition.Message = fmt.Sprintf("the %s secret %s has the key %s", kind, ref.Name, key)
+	}
+	return condition
+}
+
+// queueCondition returns the QueueHealthy condition after an operation on
+// the queue of the Repository.
+func queueCondition(err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:    v1alpha1.RepositoryConditionQueueHealthy,
+			Status:  metav1.ConditionFalse,
+			Reason:  "QueueFailed",
+			Message: err.Error(),
+		}
+	}
+	return metav1.Condition{
+		Type:   v1alpha1.RepositoryConditionQueueHealthy,
+		Status: metav1.ConditionTrue,
+		Reason: "QueueRunning",
+	}
+}
+
+// updateRepositoryConditions sets the conditions on the latest version of
+// the Repository, the status is only updated when a condition changed.
+func updateRepositoryConditions(ctx context.Context, run *params.Run, repo *v1alpha1.Repository, conditions ...metav1.Condition) error {
+	repos := run.Clients.PipelineAsCode.PipelinesascodeV1alpha1().Repositories(repo.GetNamespace())
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := repos.Get(ctx, repo.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !latest.SetConditions(conditions...) {
+			return nil
+		}
+		_, err = repos.UpdateStatus(ctx, latest, metav1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,153 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/logger"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/controller"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestRepositoryReconcile(t *testing.T) {
+	tests := []struct {
+		name          string
+		gitProvider   *v1alpha1.GitProvider
+		secretData    map[string][]byte
+		wantSecret    string
+		wantWebhook   string
+		wantReady     metav1.ConditionStatus
+		wantRequeue   bool
+		notLeader     bool
+		wantNoChanges bool
+	}{
+		{
+			name:        "github app",
+			wantSecret:  "GitHubApp",
+			wantWebhook: "GitHubApp",
+			wantReady:   metav1.ConditionTrue,
+		},
+		{
+			name: "valid secrets",
+			gitProvider: &v1alpha1.GitProvider{
+				Secret:        &v1alpha1.Secret{Name: "token"},
+				WebhookSecret: &v1alpha1.Secret{Name: "token", Key: "hook"},
+			},
+			secretData:  map[string][]byte{"provider.token": []byte("t"), "hook": []byte("h")},
+			wantSecret:  "SecretFound",
+			wantWebhook: "SecretFound",
+			wantReady:   metav1.ConditionTrue,
+		},
+		{
+			name:        "no webhook secret",
+			gitProvider: &v1alpha1.GitProvider{Secret: &v1alpha1.Secret{Name: "token"}},
+			secretData:  map[string][]byte{"provider.token": []byte("t")},
+			wantSecret:  "SecretFound",
+			wantWebhook: "NoWebhookSecret",
+			wantReady:   metav1.ConditionTrue,
+		},
+		{
+			name: "missing key",
+			gitProvider: &v1alpha1.GitProvider{
+				Secret:        &v1alpha1.Secret{Name: "token"},
+				WebhookSecret: &v1alpha1.Secret{Name: "token"},
+			},
+			secretData:  map[string][]byte{"provider.token": []byte("t")},
+			wantSecret:  "SecretFound",
+			wantWebhook: "SecretKeyNotFound",
+			wantReady:   metav1.ConditionFalse,
+			wantRequeue: true,
+		},
+		{
+			name:        "missing secret",
+			gitProvider: &v1alpha1.GitProvider{Secret: &v1alpha1.Secret{Name: "token"}},
+			wantSecret:  "SecretNotFound",
+			wantWebhook: "NoWebhookSecret",
+			wantReady:   metav1.ConditionFalse,
+			wantRequeue: true,
+		},
+		{
+			name:          "not the leader",
+			notLeader:     true,
+			wantNoChanges: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			repo := &v1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"},
+				Spec:       v1alpha1.RepositorySpec{URL: "https://github.com/owner/repo", GitProvider: tt.gitProvider},
+			}
+			data := testclient.Data{Repositories: []*v1alpha1.Repository{repo}}
+			if tt.secretData != nil {
+				data.Secret = []*corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "ns"}, Data: tt.secretData}}
+			}
+			stdata, informers := testclient.SeedTestData(t, ctx, data)
+
+			rr := &repositoryReconciler{
+				run: &params.Run{
+					Clients: clients.Clients{PipelineAsCode: stdata.PipelineAsCode, Kube: stdata.Kube},
+					Info: info.Info{
+						Kube:       &info.KubeOpts{Namespace: "pipelines-as-code"},
+						Controller: &info.ControllerInfo{GlobalRepository: info.DefaultGlobalRepoName},
+					},
+				},
+				repoLister: informers.Repository.Lister(),
+			}
+			if !tt.notLeader {
+				assert.NilError(t, rr.Promote(pkgreconciler.UniversalBucket(), func(pkgreconciler.Bucket, types.NamespacedName) {}))
+			}
+
+			err := rr.Reconcile(ctx, "ns/repo")
+			if tt.wantRequeue {
+				ok, _ := controller.IsRequeueKey(err)
+				assert.Assert(t, ok, "expected a requeue, got %v", err)
+			} else {
+				assert.NilError(t, err)
+			}
+
+			got, err := stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("ns").Get(context.Background(), "repo", metav1.GetOptions{})
+			assert.NilError(t, err)
+			if tt.wantNoChanges {
+				assert.Equal(t, len(got.RepositoryStatus.Conditions), 0)
+				return
+			}
+			assert.Equal(t, got.GetCondition(v1alpha1.RepositoryConditionSecretValid).Reason, tt.wantSecret)
+			assert.Equal(t, got.GetCondition(v1alpha1.RepositoryConditionWebhookConfigured).Reason, tt.wantWebhook)
+			assert.Equal(t, got.GetCondition(v1alpha1.RepositoryConditionQueueHealthy).Status, metav1.ConditionTrue)
+			assert.Equal(t, got.GetCondition(v1alpha1.RepositoryConditionReady).Status, tt.wantReady)
+		})
+	}
+}
+
+func TestSetQueueCondition(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	repo := &v1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "ns"}}
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{Repositories: []*v1alpha1.Repository{repo}})
+	r := &Reconciler{run: &params.Run{Clients: clients.Clients{PipelineAsCode: stdata.PipelineAsCode}}}
+	repos := stdata.PipelineAsCode.PipelinesascodeV1alpha1().Repositories("ns")
+	logger, _ := logger.GetLogger()
+
+	r.setQueueCondition(ctx, logger, repo, fmt.Errorf("cannot start"))
+	got, err := repos.Get(ctx, "repo", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, got.GetCondition(v1alpha1.RepositoryConditionQueueHealthy).Status, metav1.ConditionFalse)
+	assert.Equal(t, got.GetCondition(v1alpha1.RepositoryConditionReady).Message, "cannot start")
+
+	r.setQueueCondition(ctx, logger, repo, nil)
+	got, err = repos.Get(ctx, "repo", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, got.GetCondition(v1alpha1.RepositoryConditionQueueHealthy).Status, metav1.ConditionTrue)
+}
@@ -0,0 +1,28 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/tektonresults"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+)
+
+// storeResultsRecord writes the finished PipelineRun to Tekton Results when
+// the tekton-results-url setting is set, it is done before the cleanup so its
+// history is kept once pruned from the cluster. A failure is only reported
+// as an event, the PipelineRun is still pruned.
+func (r *Reconciler) storeResultsRecord(ctx context.Context, logger *zap.SugaredLogger, pacInfo *info.PacOpts, pr *tektonv1.PipelineRun) {
+	if pacInfo.TektonResultsURL == "" {
+		return
+	}
+	client := tektonresults.NewClient(pacInfo.TektonResultsURL, &r.run.Clients.HTTP)
+	if err := client.StoreRecord(ctx, pr); err != nil {
+		r.eventEmitter.EmitPipelineRunMessage(pr, zap.WarnLevel, "TektonResultsStoreFailed",
+			fmt.Sprintf("cannot store the PipelineRun in Tekton Results: %v", err))
+		return
+	}
+	logger.Debugf("PipelineRun %s stored in Tekton Results", pr.GetName())
+}
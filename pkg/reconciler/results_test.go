@@ -0,0 +1,74 @@
+package reconciler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestStoreResultsRecord(t *testing.T) {
+	tests := []struct {
+		name      string
+		noURL     bool
+		status    int
+		wantCalls int
+		wantEvent bool
+	}{
+		{
+			name:  "no tekton results",
+			noURL: true,
+		},
+		{
+			name:      "stored",
+			status:    http.StatusOK,
+			wantCalls: 2,
+		},
+		{
+			name:      "failed",
+			status:    http.StatusUnauthorized,
+			wantCalls: 1,
+			wantEvent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls++
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			ctx, _ := rtesting.SetupFakeContext(t)
+			stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{})
+			logger := zap.NewNop().Sugar()
+			r := &Reconciler{
+				run:          &params.Run{Clients: clients.Clients{Kube: stdata.Kube, HTTP: *server.Client()}},
+				eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+			}
+			pacInfo := &info.PacOpts{Settings: settings.Settings{TektonResultsURL: server.URL}}
+			if tt.noURL {
+				pacInfo.TektonResultsURL = ""
+			}
+			pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pr", Namespace: "ns", UID: "1234"}}
+
+			r.storeResultsRecord(ctx, logger, pacInfo, pr)
+			assert.Equal(t, calls, tt.wantCalls)
+			evs, err := stdata.Kube.CoreV1().Events("ns").List(ctx, metav1.ListOptions{})
+			assert.NilError(t, err)
+			assert.Equal(t, len(evs.Items) > 0, tt.wantEvent)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/action"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secrets"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// the classes of infrastructure failures retried automatically.
+const (
+	failureImagePull    = "ImagePullFailed"
+	failureNodeEviction = "NodeEviction"
+	failureWebhook      = "WebhookTimeout"
+)
+
+// infrastructureFailure returns the class of the infrastructure failure of
+// the failed PipelineRun, the failures of the tasks themselves are not
+// classified.
+func infrastructureFailure(pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.PipelineRunTaskRunStatus) (string, bool) {
+	cond := pr.Status.GetCondition(apis.ConditionSucceeded)
+	if cond == nil || !cond.IsFalse() {
+		return "", false
+	}
+	if class, ok := classifyFailure(cond.Reason, cond.Message); ok {
+		return class, true
+	}
+	for _, tr := range trStatus {
+		if tr == nil || tr.Status == nil {
+			continue
+		}
+		trCond := tr.Status.GetCondition(apis.ConditionSucceeded)
+		if trCond == nil || !trCond.IsFalse() {
+			continue
+		}
+		if class, ok := classifyFailure(trCond.Reason, trCond.Message); ok {
+			return class, true
+		}
+		for _, step := range tr.Status.Steps {
+			if step.Terminated != nil && step.Terminated.Reason == "Evicted" {
+				return failureNodeEviction, true
+			}
+		}
+	}
+	return "", false
+}
+
+func classifyFailure(reason, message string) (string, bool) {
+	message = strings.ToLower(message)
+	switch {
+	case reason == string(tektonv1.TaskRunReasonImagePullFailed):
+		return failureImagePull, true
+	case strings.Contains(message, "evicted"), strings.Contains(message, "the node was low on resource"):
+		return failureNodeEviction, true
+	case strings.Contains(message, "failed calling webhook"):
+		return failureWebhook, true
+	}
+	return "", false
+}
+
+// retryName returns the name of the retry number count of the PipelineRun,
+// the name of the original run with a -retry-N suffix kept short enough for a
+// label.
+func retryName(pr *tektonv1.PipelineRun, count int) string {
+	base := strings.TrimSuffix(pr.GetName(), fmt.Sprintf("-retry-%d", count-1))
+	suffix := fmt.Sprintf("-retry-%d", count)
+	if len(base)+len(suffix) > validation.DNS1123LabelMaxLength {
+		base = strings.TrimRight(base[:validation.DNS1123LabelMaxLength-len(suffix)], "-.")
+	}
+	return base + suffix
+}
+
+// autoRetry re-runs the failed PipelineRun when it has failed on an
+// infrastructure error and the auto_retry setting of the Repository allows
+// another retry. The retry is a copy of the PipelineRun created as queued, it
+// is started or queued by the watcher like the runs of the controller.
+func (r *Reconciler) autoRetry(ctx context.Context, logger *zap.SugaredLogger, repo *v1alpha1.Repository, pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.PipelineRunTaskRunStatus) (*tektonv1.PipelineRun, error) {
+	if repo.Spec.Settings == nil || repo.Spec.Settings.AutoRetry == nil || repo.Spec.Settings.AutoRetry.MaxRetries <= 0 {
+		return nil, nil
+	}
+	if _, ok := pr.GetAnnotations()[keys.RetriedBy]; ok {
+		return nil, nil
+	}
+	count, _ := strconv.Atoi(pr.GetAnnotations()[keys.RetryCount])
+	if count >= repo.Spec.Settings.AutoRetry.MaxRetries {
+		return nil, nil
+	}
+	class, ok := infrastructureFailure(pr, trStatus)
+	if !ok {
+		return nil, nil
+	}
+
+	retry := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        retryName(pr, count+1),
+			Namespace:   pr.GetNamespace(),
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Spec: *pr.Spec.DeepCopy(),
+	}
+	for k, v := range pr.GetLabels() {
+		if !strings.HasPrefix(k, "tekton.dev/") {
+			retry.Labels[k] = v
+		}
+	}
+	for k, v := range pr.GetAnnotations() {
+		if !strings.HasPrefix(k, "tekton.dev/") && !strings.HasPrefix(k, "results.tekton.dev/") {
+			retry.Annotations[k] = v
+		}
+	}
+	for _, k := range []string{keys.LogURL, keys.TaskOutcomes, keys.StatusReporter, keys.RetriedBy} {
+		delete(retry.Annotations, k)
+	}
+	// a new ID for the retry, it is another PipelineRun
+	runID := uuid.NewString()
+	retry.Labels[keys.RunID] = runID
+	retry.Annotations[keys.RunID] = runID
+	if _, ok := retry.Annotations[keys.RunAttempt]; ok {
+		attempt, err := r.nextRunAttempt(ctx, pr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the retry of %s: %w", pr.GetName(), err)
+		}
+		retry.Annotations[keys.RunAttempt] = strconv.Itoa(attempt)
+	}
+	retry.Labels[keys.State] = kubeinteraction.StateQueued
+	retry.Annotations[keys.State] = kubeinteraction.StateQueued
+	retry.Annotations[keys.ExecutionOrder] = retry.GetNamespace() + "/" + retry.GetName()
+	retry.Annotations[keys.RetryOf] = pr.GetName()
+	retry.Annotations[keys.RetryCount] = strconv.Itoa(count + 1)
+	retry.Annotations[keys.LogURL] = r.run.Clients.ConsoleUI().DetailURL(retry)
+	retry.Spec.Status = tektonv1.PipelineRunSpecStatusPending
+	if err := renameCloudCredentialsSecret(retry); err != nil {
+		return nil, fmt.Errorf("cannot create the retry of %s: %w", pr.GetName(), err)
+	}
+
+	retry, err := r.run.Clients.Tekton.TektonV1().PipelineRuns(pr.GetNamespace()).Create(ctx, retry, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the retry of %s: %w", pr.GetName(), err)
+	}
+
+	// the git auth and the signed event secrets move to the retry, for the
+	// cleanup of the failed run to keep them
+	annotations := map[string]any{keys.RetriedBy: retry.GetName()}
+	for _, key := range []string{keys.GitAuthSecret, keys.SignedEventSecret} {
+		secretName, ok := pr.GetAnnotations()[key]
+		if !ok {
+			continue
+		}
+		annotations[key] = nil
+		if err := r.kinteract.UpdateSecretWithOwnerRef(ctx, logger, pr.GetNamespace(), secretName, retry); err != nil {
+			logger.Warnf("cannot move the secret %s to the retry %s: %v", secretName, retry.GetName(), err)
+		}
+	}
+	if _, err := action.PatchPipelineRun(ctx, logger, "retried by", r.run.Clients.Tekton, pr, map[string]any{
+		"metadata": map[string]any{"annotations": annotations},
+	}); err != nil {
+		logger.Warnf("cannot link %s to its retry %s: %v", pr.GetName(), retry.GetName(), err)
+	}
+
+	r.eventEmitter.EmitPipelineRunMessage(pr, zap.InfoLevel, "PipelineRunAutoRetried",
+		fmt.Sprintf("PipelineRun failed on %s, retried as %s (%d/%d)", class, retry.GetName(), count+1, repo.Spec.Settings.AutoRetry.MaxRetries))
+	return retry, nil
+}
+
+// nextRunAttempt returns the attempt of the retry of the PipelineRun named by
+// the deterministic pipelinerun-naming setting, counted like the attempts
+// created by the controller: after all the PipelineRuns of the same commit.
+func (r *Reconciler) nextRunAttempt(ctx context.Context, pr *tektonv1.PipelineRun) (int, error) {
+	prLabels := pr.GetLabels()
+	selector := labels.SelectorFromSet(labels.Set{
+		keys.Repository:     prLabels[keys.Repository],
+		keys.OriginalPRName: prLabels[keys.OriginalPRName],
+		keys.SHA:            prLabels[keys.SHA],
+	})
+	previous, err := r.run.Clients.Tekton.TektonV1().PipelineRuns(pr.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return 0, fmt.Errorf("cannot list the previous attempts: %w", err)
+	}
+	return len(previous.Items) + 1, nil
+}
+
+// renameCloudCredentialsSecret gives the retry its own cloud credentials
+// secret, the short-lived credentials of the failed run may have expired and
+// are exchanged again when the retry starts.
+func renameCloudCredentialsSecret(retry *tektonv1.PipelineRun) error {
+	previous := retry.GetAnnotations()[keys.CloudCredentialsSecret]
+	if previous == "" {
+		return nil
+	}
+	b, err := json.Marshal(retry.Spec)
+	if err != nil {
+		return err
+	}
+	name := secrets.GenerateCloudCredentialsSecretName()
+	spec := tektonv1.PipelineRunSpec{}
+	if err := json.Unmarshal([]byte(strings.ReplaceAll(string(b), previous, name)), &spec); err != nil {
+		return err
+	}
+	retry.Spec = spec
+	retry.Annotations[keys.CloudCredentialsSecret] = name
+	return nil
+}
@@ -0,0 +1,176 @@
+package reconciler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/events"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/test/kubernetestint"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func failedPipelineRun(name, reason, message string) *tektonv1.PipelineRun {
+	return &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+		Status: tektonv1.PipelineRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+			{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: reason, Message: message},
+		}}},
+	}
+}
+
+func TestInfrastructureFailure(t *testing.T) {
+	imagePull := makeTaskRunStatus("build", corev1.ConditionFalse)
+	imagePull.Status.Conditions[0].Reason = string(tektonv1.TaskRunReasonImagePullFailed)
+	evicted := makeTaskRunStatus("build", corev1.ConditionFalse)
+	evicted.Status.Conditions[0].Message = "The node was low on resource: ephemeral-storage."
+	evictedStep := makeTaskRunStatus("build", corev1.ConditionFalse, tektonv1.StepState{ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Evicted"}}})
+
+	tests := []struct {
+		name     string
+		pr       *tektonv1.PipelineRun
+		trStatus map[string]*tektonv1.PipelineRunTaskRunStatus
+		want     string
+	}{
+		{
+			name:     "image pull",
+			pr:       failedPipelineRun("pr", "Failed", "Tasks Completed: 1 (Failed: 1)"),
+			trStatus: map[string]*tektonv1.PipelineRunTaskRunStatus{"pr-build": imagePull},
+			want:     failureImagePull,
+		},
+		{
+			name:     "node eviction",
+			pr:       failedPipelineRun("pr", "Failed", "Tasks Completed: 1 (Failed: 1)"),
+			trStatus: map[string]*tektonv1.PipelineRunTaskRunStatus{"pr-build": evicted},
+			want:     failureNodeEviction,
+		},
+		{
+			name:     "evicted step",
+			pr:       failedPipelineRun("pr", "Failed", "Tasks Completed: 1 (Failed: 1)"),
+			trStatus: map[string]*tektonv1.PipelineRunTaskRunStatus{"pr-build": evictedStep},
+			want:     failureNodeEviction,
+		},
+		{
+			name: "webhook timeout",
+			pr: failedPipelineRun("pr", string(tektonv1.PipelineRunReasonCreateRunFailed),
+				`failed to create TaskRun: Internal error occurred: failed calling webhook "validation.webhook.pipeline.tekton.dev": context deadline exceeded`),
+			want: failureWebhook,
+		},
+		{
+			name:     "task failure",
+			pr:       failedPipelineRun("pr", "Failed", "Tasks Completed: 1 (Failed: 1)"),
+			trStatus: map[string]*tektonv1.PipelineRunTaskRunStatus{"pr-build": makeTaskRunStatus("build", corev1.ConditionFalse)},
+		},
+		{
+			name: "succeeded",
+			pr: &tektonv1.PipelineRun{Status: tektonv1.PipelineRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+				{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue},
+			}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class, ok := infrastructureFailure(tt.pr, tt.trStatus)
+			assert.Equal(t, ok, tt.want != "")
+			assert.Equal(t, class, tt.want)
+		})
+	}
+}
+
+func TestRetryName(t *testing.T) {
+	pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pull-request-abcde"}}
+	assert.Equal(t, retryName(pr, 1), "pull-request-abcde-retry-1")
+	pr.Name = "pull-request-abcde-retry-1"
+	assert.Equal(t, retryName(pr, 2), "pull-request-abcde-retry-2")
+	pr.Name = strings.Repeat("a", 70)
+	assert.Equal(t, retryName(pr, 1), strings.Repeat("a", 55)+"-retry-1")
+}
+
+func TestAutoRetry(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	pr := failedPipelineRun("pull-request-abcde", string(tektonv1.PipelineRunReasonCreateRunFailed), "failed calling webhook")
+	pr.Labels = map[string]string{
+		keys.State:            kubeinteraction.StateCompleted,
+		keys.Repository:       "repo",
+		keys.OriginalPRName:   "pull-request",
+		keys.SHA:              "abc",
+		keys.RunID:            "first-run-id",
+		"tekton.dev/pipeline": "pull-request-abcde",
+	}
+	pr.Annotations = map[string]string{
+		keys.State:                  kubeinteraction.StateCompleted,
+		keys.SHA:                    "abc",
+		keys.GitAuthSecret:          "pac-gitauth-abcde",
+		keys.CloudCredentialsSecret: "pac-cloud-abcde",
+		keys.TaskOutcomes:           "{}",
+		keys.OriginalPRName:         "pull-request",
+		keys.RunID:                  "first-run-id",
+		keys.RunAttempt:             "1",
+	}
+	pr.Spec.PipelineRef = &tektonv1.PipelineRef{Name: "pipeline"}
+	pr.Spec.Workspaces = []tektonv1.WorkspaceBinding{{Name: "cloud", Secret: &corev1.SecretVolumeSource{SecretName: "pac-cloud-abcde"}}}
+	stdata, _ := testclient.SeedTestData(t, ctx, testclient.Data{PipelineRuns: []*tektonv1.PipelineRun{pr}})
+	logger := zap.NewNop().Sugar()
+	r := &Reconciler{
+		run:          &params.Run{Clients: clients.Clients{Tekton: stdata.Pipeline}},
+		kinteract:    &kubernetestint.KinterfaceTest{},
+		eventEmitter: events.NewEventEmitter(stdata.Kube, logger),
+	}
+	r.run.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+	repo := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{Settings: &v1alpha1.Settings{AutoRetry: &v1alpha1.AutoRetry{MaxRetries: 1}}}}
+
+	retry, err := r.autoRetry(ctx, logger, repo, pr, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, retry.GetName(), "pull-request-abcde-retry-1")
+	assert.Equal(t, retry.Spec.Status, tektonv1.PipelineRunSpecStatus(tektonv1.PipelineRunSpecStatusPending))
+	assert.Equal(t, retry.Spec.PipelineRef.Name, "pipeline")
+	assert.Equal(t, retry.GetLabels()[keys.State], kubeinteraction.StateQueued)
+	assert.Equal(t, retry.GetLabels()["tekton.dev/pipeline"], "")
+	assert.Equal(t, retry.GetAnnotations()[keys.ExecutionOrder], "ns/pull-request-abcde-retry-1")
+	assert.Equal(t, retry.GetAnnotations()[keys.RetryOf], "pull-request-abcde")
+	assert.Equal(t, retry.GetAnnotations()[keys.RetryCount], "1")
+	assert.Equal(t, retry.GetAnnotations()[keys.GitAuthSecret], "pac-gitauth-abcde")
+	_, ok := retry.GetAnnotations()[keys.TaskOutcomes]
+	assert.Assert(t, !ok)
+	// the retry is another run, the next attempt on the commit
+	runID := retry.GetAnnotations()[keys.RunID]
+	assert.Assert(t, runID != "first-run-id" && runID != "")
+	assert.Equal(t, retry.GetLabels()[keys.RunID], runID)
+	assert.Equal(t, retry.GetAnnotations()[keys.RunAttempt], "2")
+	// the cloud credentials of the retry are exchanged again when it starts
+	cloudSecret := retry.GetAnnotations()[keys.CloudCredentialsSecret]
+	assert.Assert(t, cloudSecret != "pac-cloud-abcde" && cloudSecret != "")
+	assert.Equal(t, retry.Spec.Workspaces[0].Secret.SecretName, cloudSecret)
+
+	got, err := stdata.Pipeline.TektonV1().PipelineRuns("ns").Get(ctx, pr.GetName(), metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, got.GetAnnotations()[keys.RetriedBy], "pull-request-abcde-retry-1")
+	_, ok = got.GetAnnotations()[keys.GitAuthSecret]
+	assert.Assert(t, !ok)
+	assert.Equal(t, got.GetAnnotations()[keys.CloudCredentialsSecret], "pac-cloud-abcde")
+
+	// the retry has used all the retries of the setting
+	retry.Status = pr.Status
+	again, err := r.autoRetry(ctx, logger, repo, retry, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, again == nil)
+
+	// a failure of the tasks is not retried
+	taskFailure := failedPipelineRun("push", "Failed", "Tasks Completed: 1 (Failed: 1)")
+	again, err = r.autoRetry(ctx, logger, repo, taskFailure, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, again == nil)
+}
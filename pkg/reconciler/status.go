@@ -37,16 +37,17 @@ var backoffSchedule = []time.Duration{
 func (r *Reconciler) updateRepoRunStatus(ctx context.Context, logger *zap.SugaredLogger, pr *tektonv1.PipelineRun, repo *pacv1a1.Repository, event *info.Event) error {
 	refsanitized := formatting.SanitizeBranch(event.BaseBranch)
 	repoStatus := pacv1a1.RepositoryRunStatus{
-		Status:          pr.Status.Status,
-		PipelineRunName: pr.Name,
-		StartTime:       pr.Status.StartTime,
-		CompletionTime:  pr.Status.CompletionTime,
-		SHA:             &event.SHA,
-		SHAURL:          &event.SHAURL,
-		Title:           &event.SHATitle,
-		LogURL:          github.String(r.run.Clients.ConsoleUI().DetailURL(pr)),
-		EventType:       &event.EventType,
-		TargetBranch:    &refsanitized,
+		Status:                  pr.Status.Status,
+		PipelineRunName:         pr.Name,
+		StartTime:               pr.Status.StartTime,
+		CompletionTime:          pr.Status.CompletionTime,
+		SHA:                     &event.SHA,
+		SHAURL:                  &event.SHAURL,
+		Title:                   &event.SHATitle,
+		LogURL:                  github.String(r.run.Clients.ConsoleUI().DetailURL(pr)),
+		EventType:               &event.EventType,
+		TargetBranch:            &refsanitized,
+		OriginalPipelineRunName: github.String(pr.GetAnnotations()[apipac.OriginalPRName]),
 	}
 
 	// Get repository again in case it was updated while we were running the CI
@@ -80,7 +81,7 @@ func (r *Reconciler) updateRepoRunStatus(ctx context.Context, logger *zap.Sugare
 	return fmt.Errorf("cannot update %s", repo.Name)
 }
 
-func (r *Reconciler) getFailureSnippet(ctx context.Context, pr *tektonv1.PipelineRun) string {
+func (r *Reconciler) getFailureSnippet(ctx context.Context, pr *tektonv1.PipelineRun, profile formatting.Profile) string {
 	taskinfos := kstatus.CollectFailedTasksLogSnippet(ctx, r.run, r.kinteract, pr, logSnippetNumLines)
 	if len(taskinfos) == 0 {
 		return ""
@@ -94,10 +95,10 @@ func (r *Reconciler) getFailureSnippet(ctx context.Context, pr *tektonv1.Pipelin
 	if sortedTaskInfos[0].DisplayName != "" {
 		name = strings.ToLower(sortedTaskInfos[0].DisplayName)
 	}
-	return fmt.Sprintf("task <b>%s</b> has the status <b>\"%s\"</b>:\n<pre>%s</pre>", name, sortedTaskInfos[0].Reason, text)
+	return profile.FailureSnippet(name, sortedTaskInfos[0].Reason, text)
 }
 
-func (r *Reconciler) postFinalStatus(ctx context.Context, logger *zap.SugaredLogger, pacInfo *info.PacOpts, vcx provider.Interface, event *info.Event, createdPR *tektonv1.PipelineRun) (*tektonv1.PipelineRun, error) {
+func (r *Reconciler) postFinalStatus(ctx context.Context, logger *zap.SugaredLogger, pacInfo *info.PacOpts, vcx provider.Interface, event *info.Event, repo *pacv1a1.Repository, createdPR *tektonv1.PipelineRun) (*tektonv1.PipelineRun, error) {
 	pr, err := r.run.Clients.Tekton.TektonV1().PipelineRuns(createdPR.GetNamespace()).Get(
 		ctx, createdPR.GetName(), metav1.GetOptions{},
 	)
@@ -105,17 +106,26 @@ func (r *Reconciler) postFinalStatus(ctx context.Context, logger *zap.SugaredLog
 		return pr, err
 	}
 
+	var repoSettings *pacv1a1.Settings
+	if repo != nil {
+		repoSettings = repo.Spec.Settings
+	}
+	profile := formatting.ProfileFor(vcx.GetConfig().Name, repoSettings)
+
 	trStatus := kstatus.GetStatusFromTaskStatusOrFromAsking(ctx, pr, r.run)
 	var taskStatusText string
 	if len(trStatus) > 0 {
 		var err error
-		taskStatusText, err = sort.TaskStatusTmpl(pr, trStatus, r.run, vcx.GetConfig())
+		taskStatusText, err = sort.TaskStatusTmpl(pr, trStatus, r.run, profile)
 		if err != nil {
 			return pr, err
 		}
 	} else {
 		taskStatusText = pr.Status.GetCondition(apis.ConditionSucceeded).Message
 	}
+	if text := preemptedStatusText(pr, profile.Messages()); text != "" {
+		taskStatusText = text
+	}
 
 	namespaceURL := r.run.Clients.ConsoleUI().NamespaceURL(pr)
 	consoleURL := r.run.Clients.ConsoleUI().DetailURL(pr)
@@ -128,9 +138,18 @@ func (r *Reconciler) postFinalStatus(ctx context.Context, logger *zap.SugaredLog
 		TknBinary:       settings.TknBinaryName,
 		TknBinaryURL:    settings.TknBinaryURL,
 		TaskStatus:      taskStatusText,
+		Artifacts:       formatting.CollectArtifacts(pr, trStatus),
+		Profile:         profile,
+	}
+	owner := pacInfo.MetricsAggregation().Owner(pr.GetAnnotations()[apipac.URLOrg], pr.GetNamespace(), pr.GetLabels()[apipac.Repository])
+	mt.FlakyTasks = r.detectFlakyTasks(ctx, logger, pr, trStatus, owner)
+	mt.Cost = r.estimateCost(ctx, logger, pacInfo, pr, trStatus, owner)
+	mt.History = r.runHistory(ctx, logger, pacInfo, repo, pr)
+	if pr.Status.StartTime != nil && pr.Status.CompletionTime != nil {
+		mt.DurationTrend = formatting.DurationTrend(pr.Status.CompletionTime.Sub(pr.Status.StartTime.Time), mt.History, profile.Messages())
 	}
 	if pacInfo.ErrorLogSnippet {
-		failures := r.getFailureSnippet(ctx, pr)
+		failures := r.getFailureSnippet(ctx, pr, profile)
 		if failures != "" {
 			secretValues := secrets.GetSecretsAttachedToPipelineRun(ctx, r.kinteract, pr)
 			failures = secrets.ReplaceSecretsInText(failures, secretValues)
@@ -138,7 +157,11 @@ func (r *Reconciler) postFinalStatus(ctx context.Context, logger *zap.SugaredLog
 		}
 	}
 	var tmplStatusText string
-	if tmplStatusText, err = mt.MakeTemplate(formatting.PipelineRunStatusText); err != nil {
+	finishedTemplate := ""
+	if repoSettings != nil && repoSettings.StatusTemplates != nil {
+		finishedTemplate = repoSettings.StatusTemplates.Finished
+	}
+	if tmplStatusText, err = mt.MakeCustomTemplate(finishedTemplate, profile.FinishedTemplate()); err != nil {
 		return nil, fmt.Errorf("cannot create message template: %w", err)
 	}
 
@@ -150,10 +173,21 @@ func (r *Reconciler) postFinalStatus(ctx context.Context, logger *zap.SugaredLog
 		PipelineRunName:         pr.Name,
 		DetailsURL:              r.run.Clients.ConsoleUI().DetailURL(pr),
 		OriginalPipelineRunName: pr.GetAnnotations()[apipac.OriginalPRName],
+		RunID:                   pr.GetAnnotations()[apipac.RunID],
+		Stage:                   pr.GetAnnotations()[apipac.Stage],
 	}
 
 	err = createStatusWithRetry(ctx, logger, vcx, event, status)
 	logger.Infof("pipelinerun %s has a status of '%s'", pr.Name, status.Conclusion)
+	r.reviewDeployment(ctx, logger, vcx, event, pr)
+
+	if previewURL := formatting.PreviewURL(pr, trStatus); pacInfo.PreviewEnvironments && previewURL != "" && event.PullRequestNumber != 0 {
+		if previewer, ok := vcx.(provider.PreviewEnvironmentInterface); ok {
+			if perr := previewer.CreatePreviewEnvironment(ctx, event, previewURL, consoleURL); perr != nil {
+				logger.Errorf("cannot create preview environment for pipelinerun %s: %v", pr.Name, perr)
+			}
+		}
+	}
 	return pr, err
 }
 
@@ -0,0 +1,38 @@
+package resolve
+
+import (
+	"slices"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// skipAllEnv skips all the injected environment variables.
+const skipAllEnv = "*"
+
+// InjectEnv adds the environment variables to the pod template of the
+// TaskRuns of the PipelineRuns, Tekton sets them on all the steps and the
+// sidecars including the ones of the remote tasks. The variables set in the
+// pod template of the PipelineRun or in a step keep their value, the names in
+// skip are not injected.
+func InjectEnv(prs []*tektonv1.PipelineRun, env []corev1.EnvVar, skip []string) {
+	if slices.Contains(skip, skipAllEnv) {
+		return
+	}
+	for _, pr := range prs {
+		for _, e := range env {
+			if slices.Contains(skip, e.Name) {
+				continue
+			}
+			if pr.Spec.TaskRunTemplate.PodTemplate == nil {
+				pr.Spec.TaskRunTemplate.PodTemplate = &pod.Template{}
+			}
+			tpl := pr.Spec.TaskRunTemplate.PodTemplate
+			if slices.ContainsFunc(tpl.Env, func(existing corev1.EnvVar) bool { return existing.Name == e.Name }) {
+				continue
+			}
+			tpl.Env = append(tpl.Env, e)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package resolve
+
+import (
+	"testing"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInjectEnv(t *testing.T) {
+	env := []corev1.EnvVar{
+		{Name: "HTTPS_PROXY", Value: "http://proxy:3128"},
+		{Name: "NO_PROXY", Value: ".svc,.cluster.local"},
+		{Name: "SSL_CERT_FILE", Value: "/etc/pki/ca.crt"},
+	}
+	newPipelineRuns := func() []*tektonv1.PipelineRun {
+		return []*tektonv1.PipelineRun{
+			{ObjectMeta: metav1.ObjectMeta{Name: "first"}},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "second"},
+				Spec: tektonv1.PipelineRunSpec{TaskRunTemplate: tektonv1.PipelineTaskRunTemplate{
+					PodTemplate: &pod.Template{Env: []corev1.EnvVar{{Name: "NO_PROXY", Value: "set-in-template"}}},
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		skip []string
+		want [][]corev1.EnvVar
+	}{
+		{
+			name: "injected",
+			want: [][]corev1.EnvVar{
+				env,
+				{{Name: "NO_PROXY", Value: "set-in-template"}, env[0], env[2]},
+			},
+		},
+		{
+			name: "skip some",
+			skip: []string{"SSL_CERT_FILE"},
+			want: [][]corev1.EnvVar{
+				env[:2],
+				{{Name: "NO_PROXY", Value: "set-in-template"}, env[0]},
+			},
+		},
+		{
+			name: "skip all",
+			skip: []string{"*"},
+			want: [][]corev1.EnvVar{
+				nil,
+				{{Name: "NO_PROXY", Value: "set-in-template"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prs := newPipelineRuns()
+			InjectEnv(prs, env, tt.skip)
+			for i, pr := range prs {
+				var got []corev1.EnvVar
+				if pr.Spec.TaskRunTemplate.PodTemplate != nil {
+					got = pr.Spec.TaskRunTemplate.PodTemplate.Env
+				}
+				assert.DeepEqual(t, got, tt.want[i])
+			}
+		})
+	}
+}
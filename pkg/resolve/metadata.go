@@ -0,0 +1,108 @@
+package resolve
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// ValidateMetadata checks the keys of the pipelinerun_labels and
+// pipelinerun_annotations settings of a Repository, the keys of Pipelines as
+// Code are reserved.
+func ValidateMetadata(settings *v1alpha1.Settings) error {
+	if settings == nil {
+		return nil
+	}
+	for setting, metadata := range map[string]map[string]string{
+		"pipelinerun_labels":      settings.PipelineRunLabels,
+		"pipelinerun_annotations": settings.PipelineRunAnnotations,
+	} {
+		for _, key := range sortedKeys(metadata) {
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				return fmt.Errorf("invalid %s key %q: %s", setting, key, strings.Join(errs, ", "))
+			}
+			if isReservedKey(key) {
+				return fmt.Errorf("invalid %s key %q: the %s keys are reserved", setting, key, pipelinesascode.GroupName)
+			}
+		}
+	}
+	return nil
+}
+
+// PropagateMetadata stamps the labels and the annotations, with their
+// placeholders already replaced, on the PipelineRuns. They override the labels
+// and the annotations of the same key set in the PipelineRuns, a pull request
+// can't change the metadata the tools selecting the PipelineRuns rely on. The
+// label values are sanitized to be valid label values. The entries with an
+// invalid key or a placeholder left are skipped and returned in the error.
+func PropagateMetadata(prs []*tektonv1.PipelineRun, labels, annotations map[string]string) error {
+	skipped := []string{}
+	apply := func(metadata map[string]string, isLabel bool, get func(*tektonv1.PipelineRun) map[string]string) {
+		for _, key := range sortedKeys(metadata) {
+			value := metadata[key]
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 || isReservedKey(key) {
+				skipped = append(skipped, fmt.Sprintf("%s: invalid key", key))
+				continue
+			}
+			if keys.ParamsRe.MatchString(value) {
+				skipped = append(skipped, fmt.Sprintf("%s: unresolved placeholder in %q", key, value))
+				continue
+			}
+			if isLabel {
+				value = SanitizeLabelValue(value)
+			}
+			for _, pr := range prs {
+				get(pr)[key] = value
+			}
+		}
+	}
+	for _, pr := range prs {
+		if pr.GetLabels() == nil {
+			pr.Labels = map[string]string{}
+		}
+		if pr.GetAnnotations() == nil {
+			pr.Annotations = map[string]string{}
+		}
+	}
+	apply(labels, true, func(pr *tektonv1.PipelineRun) map[string]string { return pr.Labels })
+	apply(annotations, false, func(pr *tektonv1.PipelineRun) map[string]string { return pr.Annotations })
+	if len(skipped) > 0 {
+		return fmt.Errorf("skipped the pipelinerun metadata %s", strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+// SanitizeLabelValue turns a value in a valid label value: at most 63
+// alphanumeric, '-', '_' or '.' characters starting and ending with an
+// alphanumeric character.
+func SanitizeLabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(formatting.CleanValueKubernetes(value), "_")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(value, "-_.")
+}
+
+func isReservedKey(key string) bool {
+	prefix, _, found := strings.Cut(key, "/")
+	return found && (prefix == pipelinesascode.GroupName || strings.HasSuffix(prefix, "."+pipelinesascode.GroupName))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,84 @@
+package resolve
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPropagateMetadata(t *testing.T) {
+	prs := []*tektonv1.PipelineRun{
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "first",
+			Labels:      map[string]string{"team": "set-in-template", "other": "kept"},
+			Annotations: map[string]string{"example.com/sender": "set-in-template"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "second"}},
+	}
+	err := PropagateMetadata(prs,
+		map[string]string{
+			"team":                 "backend",
+			"example.com/sender":   "dependabot[bot]",
+			"pull-request":         "42",
+			keys.State:             "started",
+			"example.com/approver": "{{ sender_team }}",
+		},
+		map[string]string{
+			"example.com/sender": "dependabot[bot]",
+		})
+	assert.ErrorContains(t, err, keys.State+": invalid key")
+	assert.ErrorContains(t, err, `example.com/approver: unresolved placeholder in "{{ sender_team }}"`)
+
+	// the labels of the Repository override the ones of the template
+	for _, pr := range prs {
+		assert.Equal(t, pr.Labels["team"], "backend")
+		assert.Equal(t, pr.Labels["example.com/sender"], "dependabot__bot")
+		assert.Equal(t, pr.Labels["pull-request"], "42")
+		assert.Equal(t, pr.Annotations["example.com/sender"], "dependabot[bot]")
+		_, ok := pr.Labels[keys.State]
+		assert.Assert(t, !ok)
+		_, ok = pr.Labels["example.com/approver"]
+		assert.Assert(t, !ok)
+	}
+
+	assert.Equal(t, prs[0].Labels["other"], "kept")
+
+	assert.NilError(t, PropagateMetadata(prs, nil, nil))
+}
+
+func TestSanitizeLabelValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "simple", want: "simple"},
+		{value: "feature/branch", want: "feature-branch"},
+		{value: "John Doe <john@example.com>", want: "John_Doe__john_example.com"},
+		{value: "-trimmed.", want: "trimmed"},
+		{value: strings.Repeat("a", 70), want: strings.Repeat("a", 62)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, SanitizeLabelValue(tt.value), tt.want)
+		})
+	}
+}
+
+func TestValidateMetadata(t *testing.T) {
+	assert.NilError(t, ValidateMetadata(nil))
+	assert.NilError(t, ValidateMetadata(&v1alpha1.Settings{
+		PipelineRunLabels:      map[string]string{"example.com/team": "{{ sender_team }}"},
+		PipelineRunAnnotations: map[string]string{"owner": "ci"},
+	}))
+	assert.ErrorContains(t, ValidateMetadata(&v1alpha1.Settings{
+		PipelineRunAnnotations: map[string]string{"not a key": "ci"},
+	}), `invalid pipelinerun_annotations key "not a key"`)
+	assert.ErrorContains(t, ValidateMetadata(&v1alpha1.Settings{
+		PipelineRunLabels: map[string]string{"foo.pipelinesascode.tekton.dev/bar": "ci"},
+	}), "are reserved")
+}
@@ -2,9 +2,12 @@ package resolve
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 )
 
 type NamedItem interface {
@@ -20,6 +23,37 @@ func alreadySeen[T NamedItem](items []T, item T) bool {
 	return false
 }
 
+// maxParallelRemotes bounds the number of PipelineRuns, or remote Pipelines,
+// whose remote resources are fetched at the same time.
+const maxParallelRemotes = 8
+
+// remoteResources are the resources fetched from the annotations of a
+// PipelineRun or a remote Pipeline.
+type remoteResources struct {
+	tasks    []*tektonv1.Task
+	pipeline *tektonv1.Pipeline
+}
+
+// forEachParallel calls fn for the indexes from 0 to n, at most
+// maxParallelRemotes at a time, and returns all the errors in the order of the
+// indexes.
+func forEachParallel(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	sem := make(chan struct{}, maxParallelRemotes)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
 // getRemotes will get remote tasks or Pipelines from annotations.
 //
 // It already has some tasks or pipeline coming from the tekton directory stored in [types]
@@ -32,49 +66,70 @@ func alreadySeen[T NamedItem](items []T, item T) bool {
 //
 // The precedence logic for Pipeline is first from PipelineRun annotations and
 // then from Tekton directory.
+//
+// The annotations of the PipelineRuns, and then of the remote Pipelines, are
+// fetched in parallel and merged in their order so the result doesn't depend
+// on which one is fetched first. The errors of all of them are reported.
 func getRemotes(ctx context.Context, rt *matcher.RemoteTasks, types TektonTypes) (TektonTypes, error) {
-	remoteType := &TektonTypes{}
-	for _, pipelinerun := range types.PipelineRuns {
-		if len(pipelinerun.GetObjectMeta().GetAnnotations()) == 0 {
-			continue
+	fetched := make([]remoteResources, len(types.PipelineRuns))
+	err := forEachParallel(len(types.PipelineRuns), func(i int) error {
+		annotations := types.PipelineRuns[i].GetObjectMeta().GetAnnotations()
+		if len(annotations) == 0 {
+			return nil
 		}
 
 		// get first all the tasks from the pipelinerun annotations
-		remoteTasks, err := rt.GetTaskFromAnnotations(ctx, pipelinerun.GetObjectMeta().GetAnnotations())
+		remoteTasks, err := rt.GetTaskFromAnnotations(ctx, annotations)
 		if err != nil {
-			return TektonTypes{}, fmt.Errorf("error getting remote task from pipelinerun annotations: %w", err)
+			return fmt.Errorf("error getting remote task from pipelinerun annotations: %w", err)
 		}
+		fetched[i].tasks = remoteTasks
 
-		for _, task := range remoteTasks {
+		// get the pipeline from the remote annotation if any
+		remotePipeline, err := rt.GetPipelineFromAnnotations(ctx, annotations)
+		if err != nil {
+			return fmt.Errorf("error getting remote pipeline from pipelinerun annotation: %w", err)
+		}
+		fetched[i].pipeline = remotePipeline
+		return nil
+	})
+	if err != nil {
+		return TektonTypes{}, err
+	}
+
+	remoteType := &TektonTypes{}
+	for i, pipelinerun := range types.PipelineRuns {
+		for _, task := range fetched[i].tasks {
 			if alreadySeen(remoteType.Tasks, task) {
 				rt.Logger.Debugf("skipping already fetched task %s in annotations on pipelinerun %s", task.GetName(), pipelinerun.GetName())
 				continue
 			}
 			remoteType.Tasks = append(remoteType.Tasks, task)
 		}
-
-		// get the pipeline from the remote annotation if any
-		remotePipeline, err := rt.GetPipelineFromAnnotations(ctx, pipelinerun.GetObjectMeta().GetAnnotations())
-		if err != nil {
-			return TektonTypes{}, fmt.Errorf("error getting remote pipeline from pipelinerun annotation: %w", err)
-		}
-
-		if remotePipeline != nil {
-			remoteType.Pipelines = append(remoteType.Pipelines, remotePipeline)
+		if fetched[i].pipeline != nil {
+			remoteType.Pipelines = append(remoteType.Pipelines, fetched[i].pipeline)
 		}
 	}
 
 	// grab the tasks from the remote pipeline
-	for _, pipeline := range remoteType.Pipelines {
+	pipelineTasks := make([][]*tektonv1.Task, len(remoteType.Pipelines))
+	err = forEachParallel(len(remoteType.Pipelines), func(i int) error {
+		pipeline := remoteType.Pipelines[i]
 		if pipeline.GetObjectMeta().GetAnnotations() == nil {
-			continue
+			return nil
 		}
 		remoteTasks, err := rt.GetTaskFromAnnotations(ctx, pipeline.GetObjectMeta().GetAnnotations())
 		if err != nil {
-			return TektonTypes{}, fmt.Errorf("error getting remote tasks from remote pipeline %s: %w", pipeline.GetName(), err)
+			return fmt.Errorf("error getting remote tasks from remote pipeline %s: %w", pipeline.GetName(), err)
 		}
-
-		for _, remoteTask := range remoteTasks {
+		pipelineTasks[i] = remoteTasks
+		return nil
+	})
+	if err != nil {
+		return TektonTypes{}, err
+	}
+	for i, pipeline := range remoteType.Pipelines {
+		for _, remoteTask := range pipelineTasks[i] {
 			if alreadySeen(remoteType.Tasks, remoteTask) {
 				rt.Logger.Infof("skipping remote task %s from remote pipeline %s as already defined in pipelinerun", remoteTask.GetName(), pipeline.GetName())
 				continue
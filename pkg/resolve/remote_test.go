@@ -3,7 +3,9 @@ package resolve
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
@@ -352,3 +354,91 @@ func TestRemote(t *testing.T) {
 		})
 	}
 }
+
+func TestForEachParallel(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	err := forEachParallel(3*maxParallelRemotes, func(i int) error {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if i%10 == 1 {
+			return fmt.Errorf("error %d", i)
+		}
+		return nil
+	})
+	assert.Assert(t, maxRunning <= maxParallelRemotes, maxRunning)
+	assert.Error(t, err, "error 1\nerror 11\nerror 21")
+	assert.NilError(t, forEachParallel(0, func(int) error { return nil }))
+}
+
+func TestRemoteParallelOrder(t *testing.T) {
+	taskSpec := tektonv1.TaskSpec{Steps: []tektonv1.Step{{Name: "step1", Image: "scratch"}}}
+	remoteURLS := map[string]map[string]string{}
+	pipelineruns := []*tektonv1.PipelineRun{}
+	wantTasks := []string{}
+	for i := 0; i < 3*maxParallelRemotes; i++ {
+		name := fmt.Sprintf("task-%02d", i)
+		taskB, err := yaml.Marshal(ttkn.MakeTask(name, taskSpec))
+		assert.NilError(t, err)
+		remoteURLS["http://remote/"+name] = map[string]string{"body": string(taskB), "code": "200"}
+		// every PipelineRun also references the task of the first one
+		pipelineruns = append(pipelineruns, ttkn.MakePR(fmt.Sprintf("pr-%02d", i), map[string]string{
+			apipac.Task: fmt.Sprintf("[http://remote/%s, http://remote/task-00]", name),
+		}, tektonv1.PipelineRunSpec{}))
+		wantTasks = append(wantTasks, name)
+	}
+
+	tests := []struct {
+		name    string
+		broken  []int
+		wantErr []string
+	}{
+		{
+			name: "ordered",
+		},
+		{
+			name:   "all errors reported",
+			broken: []int{3, 17},
+			wantErr: []string{
+				`error getting remote task "http://remote/task-03"`,
+				`error getting remote task "http://remote/task-17"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls := map[string]map[string]string{}
+			for k, v := range remoteURLS {
+				urls[k] = v
+			}
+			for _, i := range tt.broken {
+				urls[fmt.Sprintf("http://remote/task-%02d", i)] = map[string]string{"code": "404"}
+			}
+			ctx, _ := rtesting.SetupFakeContext(t)
+			rt := &matcher.RemoteTasks{
+				ProviderInterface: &testprovider.TestProviderImp{},
+				Logger:            zap.NewNop().Sugar(),
+				Run:               &params.Run{Clients: clients.Clients{HTTP: *httptesthelper.MakeHTTPTestClient(urls)}},
+			}
+			ret, err := getRemotes(ctx, rt, TektonTypes{PipelineRuns: pipelineruns})
+			if len(tt.wantErr) > 0 {
+				for _, wantErr := range tt.wantErr {
+					assert.ErrorContains(t, err, wantErr)
+				}
+				return
+			}
+			assert.NilError(t, err)
+			gotTasks := []string{}
+			for _, task := range ret.Tasks {
+				gotTasks = append(gotTasks, task.GetName())
+			}
+			assert.DeepEqual(t, gotTasks, wantTasks)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package resolve
+
+import (
+	"fmt"
+	"time"
+
+	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplyTimeout sets the timeout of the PipelineRun from its timeout
+// annotation and clamps it, or the timeout of its spec, to maxTimeout. It
+// returns the timeout requested and whether it has been clamped. A maxTimeout
+// of 0 doesn't clamp anything, a requested timeout of 0, which never times out
+// with Tekton, is clamped. With a maxTimeout, the PipelineRuns without a
+// timeout get defaultTimeout, the default of Tekton, clamped the same way. An
+// invalid annotation is ignored and returned as an error, the timeout of the
+// PipelineRun is clamped all the same.
+func ApplyTimeout(pr *tektonv1.PipelineRun, maxTimeout, defaultTimeout time.Duration) (time.Duration, bool, error) {
+	var err error
+	if value, ok := pr.GetAnnotations()[apipac.Timeout]; ok {
+		d, perr := time.ParseDuration(value)
+		if perr != nil || d < 0 {
+			err = fmt.Errorf("invalid %s annotation %q on the PipelineRun %s, needs to be a duration like 30m or 1h30m", apipac.Timeout, value, pr.GetName())
+		} else {
+			setPipelineTimeout(pr, d)
+		}
+	}
+	if maxTimeout <= 0 {
+		return 0, false, err
+	}
+	if pr.Spec.Timeouts == nil || pr.Spec.Timeouts.Pipeline == nil {
+		setPipelineTimeout(pr, defaultTimeout)
+	}
+	requested := pr.Spec.Timeouts.Pipeline.Duration
+	if requested != 0 && requested <= maxTimeout {
+		return requested, false, err
+	}
+	setPipelineTimeout(pr, maxTimeout)
+	return requested, true, err
+}
+
+// setPipelineTimeout sets the timeout of the whole PipelineRun, dropping the
+// timeouts of the tasks or of the finally tasks not fitting in it as Tekton
+// would reject them.
+func setPipelineTimeout(pr *tektonv1.PipelineRun, d time.Duration) {
+	if pr.Spec.Timeouts == nil {
+		pr.Spec.Timeouts = &tektonv1.TimeoutFields{}
+	}
+	timeouts := pr.Spec.Timeouts
+	timeouts.Pipeline = &metav1.Duration{Duration: d}
+	if d == 0 {
+		return
+	}
+	tasks, finally := time.Duration(0), time.Duration(0)
+	if timeouts.Tasks != nil {
+		tasks = timeouts.Tasks.Duration
+	}
+	if timeouts.Finally != nil {
+		finally = timeouts.Finally.Duration
+	}
+	if tasks+finally > d {
+		timeouts.Tasks, timeouts.Finally = nil, nil
+	}
+}
@@ -0,0 +1,123 @@
+package resolve
+
+import (
+	"testing"
+	"time"
+
+	apipac "github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyTimeout(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	tests := []struct {
+		name           string
+		annotation     string
+		timeouts       *tektonv1.TimeoutFields
+		maxTimeout     time.Duration
+		defaultTimeout time.Duration
+		wantTimeouts   *tektonv1.TimeoutFields
+		wantRequested  time.Duration
+		wantClamped    bool
+		wantErr        string
+	}{
+		{
+			name: "no timeout",
+		},
+		{
+			name:          "annotation",
+			annotation:    "30m",
+			maxTimeout:    time.Hour,
+			wantTimeouts:  &tektonv1.TimeoutFields{Pipeline: duration(30 * time.Minute)},
+			wantRequested: 30 * time.Minute,
+		},
+		{
+			name:          "annotation over the maximum",
+			annotation:    "3h",
+			maxTimeout:    time.Hour,
+			wantTimeouts:  &tektonv1.TimeoutFields{Pipeline: duration(time.Hour)},
+			wantRequested: 3 * time.Hour,
+			wantClamped:   true,
+		},
+		{
+			name:         "annotation overriding the spec",
+			annotation:   "20m",
+			timeouts:     &tektonv1.TimeoutFields{Pipeline: duration(time.Hour), Tasks: duration(30 * time.Minute)},
+			wantTimeouts: &tektonv1.TimeoutFields{Pipeline: duration(20 * time.Minute)},
+		},
+		{
+			name:         "tasks timeout fitting in the annotation",
+			annotation:   "1h",
+			timeouts:     &tektonv1.TimeoutFields{Tasks: duration(40 * time.Minute), Finally: duration(10 * time.Minute)},
+			wantTimeouts: &tektonv1.TimeoutFields{Pipeline: duration(time.Hour), Tasks: duration(40 * time.Minute), Finally: duration(10 * time.Minute)},
+		},
+		{
+			name:          "spec over the maximum",
+			timeouts:      &tektonv1.TimeoutFields{Pipeline: duration(2 * time.Hour)},
+			maxTimeout:    time.Hour,
+			wantTimeouts:  &tektonv1.TimeoutFields{Pipeline: duration(time.Hour)},
+			wantRequested: 2 * time.Hour,
+			wantClamped:   true,
+		},
+		{
+			name:         "never timing out",
+			annotation:   "0",
+			maxTimeout:   time.Hour,
+			wantTimeouts: &tektonv1.TimeoutFields{Pipeline: duration(time.Hour)},
+			wantClamped:  true,
+		},
+		{
+			name:           "default under the maximum",
+			maxTimeout:     2 * time.Hour,
+			defaultTimeout: time.Hour,
+			wantTimeouts:   &tektonv1.TimeoutFields{Pipeline: duration(time.Hour)},
+			wantRequested:  time.Hour,
+		},
+		{
+			name:           "default over the maximum",
+			timeouts:       &tektonv1.TimeoutFields{Tasks: duration(50 * time.Minute)},
+			maxTimeout:     30 * time.Minute,
+			defaultTimeout: time.Hour,
+			wantTimeouts:   &tektonv1.TimeoutFields{Pipeline: duration(30 * time.Minute)},
+			wantRequested:  time.Hour,
+			wantClamped:    true,
+		},
+		{
+			name:         "no maximum",
+			annotation:   "0",
+			wantTimeouts: &tektonv1.TimeoutFields{Pipeline: duration(0)},
+		},
+		{
+			name:           "invalid annotation",
+			annotation:     "half an hour",
+			maxTimeout:     time.Hour,
+			defaultTimeout: 2 * time.Hour,
+			wantTimeouts:   &tektonv1.TimeoutFields{Pipeline: duration(time.Hour)},
+			wantRequested:  2 * time.Hour,
+			wantClamped:    true,
+			wantErr:        `invalid pipelinesascode.tekton.dev/timeout annotation "half an hour" on the PipelineRun pr, needs to be a duration like 30m or 1h30m`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "pr", Annotations: map[string]string{}},
+				Spec:       tektonv1.PipelineRunSpec{Timeouts: tt.timeouts},
+			}
+			if tt.annotation != "" {
+				pr.Annotations[apipac.Timeout] = tt.annotation
+			}
+			requested, clamped, err := ApplyTimeout(pr, tt.maxTimeout, tt.defaultTimeout)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			assert.DeepEqual(t, pr.Spec.Timeouts, tt.wantTimeouts)
+			assert.Equal(t, requested, tt.wantRequested)
+			assert.Equal(t, clamped, tt.wantClamped)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Package sdk exposes the parsing of the Git provider events, the matching of
+// the PipelineRuns of a repository on these events and their resolution, so
+// other controllers, CLIs or webhook gateways can embed Pipelines-as-Code
+// without importing its internal packages.
+//
+// The exported functions and types of this package are kept compatible
+// across the releases: fields and functions are only added, the behaviors
+// they expose follow the ones of the Pipelines-as-Code controller. The other
+// packages of this module may change at any release.
+package sdk
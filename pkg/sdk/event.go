@@ -0,0 +1,179 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/triggertype"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketcloud"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/bitbucketserver"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitea"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider/gitlab"
+)
+
+// The Git providers of an Event.
+const (
+	GitHub          = "github"
+	GitLab          = "gitlab"
+	Gitea           = "gitea"
+	BitbucketCloud  = "bitbucket-cloud"
+	BitbucketServer = "bitbucket-server"
+)
+
+// Event is an event of a Git provider. The events built by the callers, for
+// example by a webhook gateway, only need the fields used by the annotations
+// of the PipelineRuns they match.
+type Event struct {
+	// GitProvider is the Git provider of the event, one of GitHub, GitLab,
+	// Gitea, BitbucketCloud or BitbucketServer.
+	GitProvider string
+	// EventType is the event type as sent by the Git provider, i.e
+	// pull_request on GitHub or Merge Request Hook on GitLab.
+	EventType string
+	// TriggerTarget is the event type stable across the Git providers:
+	// pull_request, push, incoming...
+	TriggerTarget string
+
+	URL           string
+	Organization  string
+	Repository    string
+	SHA           string
+	BaseBranch    string
+	HeadBranch    string
+	DefaultBranch string
+	Sender        string
+
+	PullRequestNumber int
+	PullRequestTitle  string
+	PullRequestLabels []string
+	TriggerComment    string
+
+	// TargetPipelineRun is the PipelineRun targeted by an incoming webhook.
+	TargetPipelineRun string
+
+	event    *info.Event
+	provider provider.Interface
+}
+
+// ParseEvent detects the Git provider of the request and parses its
+// payload. The signature of the payload is not checked, see ValidatePayload.
+func (c *Client) ParseEvent(ctx context.Context, req *http.Request, payload []byte) (*Event, error) {
+	var body map[string]interface{}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid event body format: %w", err)
+	}
+
+	gitHub := github.New()
+	gitHub.Run = c.run
+	for _, candidate := range []struct {
+		name     string
+		provider provider.Interface
+	}{
+		{GitHub, gitHub},
+		{Gitea, &gitea.Provider{}},
+		{BitbucketServer, &bitbucketserver.Provider{}},
+		{GitLab, &gitlab.Provider{}},
+		{BitbucketCloud, &bitbucketcloud.Provider{}},
+	} {
+		detected, process, logger, reason, err := candidate.provider.Detect(req, string(payload), c.logger)
+		if !detected {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !process {
+			return nil, fmt.Errorf("skipping non supported %s event: %s", candidate.name, reason)
+		}
+		if _, ok := body["installation"]; ok && candidate.name == GitHub && c.run.Clients.Kube == nil {
+			return nil, fmt.Errorf("a Kubernetes client is needed to parse the events of a GitHub App")
+		}
+		candidate.provider.SetLogger(logger)
+		pacInfo := c.run.Info.GetPacOpts()
+		candidate.provider.SetPacInfo(&pacInfo)
+		event, err := candidate.provider.ParsePayload(info.StoreNS(ctx, c.namespace), c.run, req, string(payload))
+		if err != nil {
+			return nil, err
+		}
+		event.Request = &info.Request{Header: req.Header, Payload: bytes.TrimSpace(payload)}
+		e := newEvent(event)
+		e.GitProvider = candidate.name
+		e.provider = candidate.provider
+		return e, nil
+	}
+	return nil, fmt.Errorf("no supported Git provider has been detected")
+}
+
+// ValidatePayload checks the signature of the payload of an event parsed
+// by ParseEvent with the webhook secret of the repository.
+func (c *Client) ValidatePayload(ctx context.Context, event *Event, webhookSecret string) error {
+	if event.provider == nil {
+		return fmt.Errorf("only the payload of the events parsed by ParseEvent can be validated")
+	}
+	ev := event.info()
+	ev.Provider.WebhookSecret = webhookSecret
+	return event.provider.Validate(ctx, c.run, ev)
+}
+
+func newEvent(event *info.Event) *Event {
+	return &Event{
+		EventType:         event.EventType,
+		TriggerTarget:     event.TriggerTarget.String(),
+		URL:               event.URL,
+		Organization:      event.Organization,
+		Repository:        event.Repository,
+		SHA:               event.SHA,
+		BaseBranch:        event.BaseBranch,
+		HeadBranch:        event.HeadBranch,
+		DefaultBranch:     event.DefaultBranch,
+		Sender:            event.Sender,
+		PullRequestNumber: event.PullRequestNumber,
+		PullRequestTitle:  event.PullRequestTitle,
+		PullRequestLabels: event.PullRequestLabel,
+		TriggerComment:    event.TriggerComment,
+		TargetPipelineRun: event.TargetPipelineRun,
+		event:             event,
+	}
+}
+
+// info returns a copy of the internal event of a parsed Event with the
+// fields of the Event applied, or a new internal event for the ones built by
+// the callers.
+func (e *Event) info() *info.Event {
+	ev := info.NewEvent()
+	if e.event != nil {
+		e.event.DeepCopyInto(ev)
+		ev.Provider = &info.Provider{}
+		if e.event.Provider != nil {
+			*ev.Provider = *e.event.Provider
+		}
+	}
+	if ev.Request == nil {
+		ev.Request = &info.Request{}
+	}
+	if ev.Request.Header == nil {
+		ev.Request = &info.Request{Header: http.Header{}, Payload: ev.Request.Payload}
+	}
+	ev.EventType = e.EventType
+	ev.TriggerTarget = triggertype.Trigger(e.TriggerTarget)
+	ev.URL = e.URL
+	ev.Organization = e.Organization
+	ev.Repository = e.Repository
+	ev.SHA = e.SHA
+	ev.BaseBranch = e.BaseBranch
+	ev.HeadBranch = e.HeadBranch
+	ev.DefaultBranch = e.DefaultBranch
+	ev.Sender = e.Sender
+	ev.PullRequestNumber = e.PullRequestNumber
+	ev.PullRequestTitle = e.PullRequestTitle
+	ev.PullRequestLabel = e.PullRequestLabels
+	ev.TriggerComment = e.TriggerComment
+	ev.TargetPipelineRun = e.TargetPipelineRun
+	return ev
+}
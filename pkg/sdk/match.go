@@ -0,0 +1,53 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// Match is a PipelineRun matching an event.
+type Match struct {
+	PipelineRun *tektonv1.PipelineRun
+	// Repository is the Repository of the target-namespace annotation of the
+	// PipelineRun, nil without the annotation.
+	Repository *v1alpha1.Repository
+	// TargetBranch and TargetEvent are the on-target-branch and on-event
+	// annotations of the PipelineRun, empty when it matched on a CEL
+	// expression, a comment or its name.
+	TargetBranch string
+	TargetEvent  string
+}
+
+// MatchPipelineRuns returns the PipelineRuns matching the event on their
+// on-event, on-target-branch, on-cel-expression and on-comment annotations,
+// an error listing the annotations of the PipelineRuns when none match. The
+// CEL expressions using the changed files only match the events parsed by
+// ParseEvent.
+func (c *Client) MatchPipelineRuns(ctx context.Context, event *Event, prs []*tektonv1.PipelineRun) ([]Match, error) {
+	if c.run.Clients.PipelineAsCode == nil {
+		for _, pr := range prs {
+			if _, ok := pr.GetAnnotations()[keys.TargetNamespace]; ok {
+				return nil, fmt.Errorf("a Pipelines-as-Code client is needed to match the target-namespace annotation of the PipelineRun %s", pr.GetName())
+			}
+		}
+	}
+	matched, err := matcher.MatchPipelinerunByAnnotation(ctx, c.logger, prs, c.run, event.info(), event.provider)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]Match, 0, len(matched))
+	for _, m := range matched {
+		matches = append(matches, Match{
+			PipelineRun:  m.PipelineRun,
+			Repository:   m.Repo,
+			TargetBranch: m.Config["target-branch"],
+			TargetEvent:  m.Config["target-event"],
+		})
+	}
+	return matches, nil
+}
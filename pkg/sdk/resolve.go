@@ -0,0 +1,43 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// ResolveOptions are the options of ResolvePipelineRuns.
+type ResolveOptions struct {
+	// GenerateName turns the names of the PipelineRuns into generateNames.
+	GenerateName bool
+	// SkipInlining are the names of the Tasks left as references.
+	SkipInlining []string
+}
+
+// Resolution is the result of ResolvePipelineRuns.
+type Resolution struct {
+	PipelineRuns []*tektonv1.PipelineRun
+	// ValidationErrors are the errors of the documents which are not valid
+	// Tekton resources, by the name of the resource.
+	ValidationErrors map[string]string
+}
+
+// ResolvePipelineRuns reads the PipelineRuns, Pipelines and Tasks of the
+// YAML documents of data, the content of a .tekton directory, and embeds the
+// Pipelines and Tasks they reference in the PipelineRuns. The tasks of the
+// remote task annotations are not fetched.
+func (c *Client) ResolvePipelineRuns(ctx context.Context, event *Event, data string, opts ResolveOptions) (*Resolution, error) {
+	types, err := resolve.ReadTektonTypes(ctx, c.logger, data)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := resolve.Resolve(ctx, c.run, c.logger, event.provider, types, event.info(), &resolve.Opts{
+		GenerateName: opts.GenerateName,
+		SkipInlining: opts.SkipInlining,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Resolution{PipelineRuns: prs, ValidationErrors: types.ValidationErrors}, nil
+}
@@ -0,0 +1,59 @@
+package sdk
+
+import (
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/generated/clientset/versioned"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/clients"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Config configures a Client, all the fields are optional.
+type Config struct {
+	// Logger is the logger of the Client, nothing is logged when unset.
+	Logger *zap.SugaredLogger
+	// Kube is the client used to get the GitHub App secret of Namespace when
+	// parsing the events of a GitHub App.
+	Kube kubernetes.Interface
+	// PipelinesAsCode is the client used to get the Repository targeted by
+	// the target-namespace annotation when matching the PipelineRuns.
+	PipelinesAsCode versioned.Interface
+	// Namespace is the namespace where Pipelines-as-Code is installed.
+	Namespace string
+	// Settings are the settings of the pipelines-as-code ConfigMap, the
+	// default settings are used for the missing ones.
+	Settings map[string]string
+}
+
+// Client parses, matches and resolves the PipelineRuns like the
+// Pipelines-as-Code controller.
+type Client struct {
+	run       *params.Run
+	logger    *zap.SugaredLogger
+	namespace string
+}
+
+// NewClient returns a Client configured with cfg.
+func NewClient(cfg Config) (*Client, error) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	run := &params.Run{
+		Clients: clients.Clients{
+			Kube:           cfg.Kube,
+			PipelineAsCode: cfg.PipelinesAsCode,
+			Log:            logger,
+		},
+		Info: info.NewInfo(),
+	}
+	config := map[string]string{}
+	for key, value := range cfg.Settings {
+		config[key] = value
+	}
+	if _, err := run.Info.UpdatePacOpts(logger, config); err != nil {
+		return nil, err
+	}
+	return &Client{run: run, logger: logger, namespace: cfg.Namespace}, nil
+}
@@ -0,0 +1,162 @@
+package sdk
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtesting "knative.dev/pkg/reconciler/testing"
+)
+
+func TestParseEvent(t *testing.T) {
+	pushPayload := `{
+		"ref": "refs/heads/main",
+		"head_commit": {"id": "abc123", "message": "fix the build"},
+		"sender": {"login": "linda"},
+		"pusher": {"name": "linda"},
+		"repository": {"name": "repo", "owner": {"login": "owner"}, "html_url": "https://github.com/owner/repo", "default_branch": "main"}
+	}`
+	tests := []struct {
+		name    string
+		headers map[string]string
+		payload string
+		want    *Event
+		wantErr string
+	}{
+		{
+			name:    "github push",
+			headers: map[string]string{"X-Github-Event": "push"},
+			payload: pushPayload,
+			want: &Event{
+				GitProvider:   GitHub,
+				EventType:     "push",
+				TriggerTarget: "push",
+				URL:           "https://github.com/owner/repo",
+				Organization:  "owner",
+				Repository:    "repo",
+				SHA:           "abc123",
+				BaseBranch:    "refs/heads/main",
+				HeadBranch:    "refs/heads/main",
+				DefaultBranch: "main",
+				Sender:        "linda",
+			},
+		},
+		{
+			name:    "github app without kube client",
+			headers: map[string]string{"X-Github-Event": "push"},
+			payload: strings.Replace(pushPayload, `"ref"`, `"installation": {"id": 1}, "ref"`, 1),
+			wantErr: "a Kubernetes client is needed",
+		},
+		{
+			name:    "unknown provider",
+			payload: pushPayload,
+			wantErr: "no supported Git provider has been detected",
+		},
+		{
+			name:    "invalid payload",
+			headers: map[string]string{"X-Github-Event": "push"},
+			payload: "not json",
+			wantErr: "invalid event body format",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+			client, err := NewClient(Config{})
+			assert.NilError(t, err)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", strings.NewReader(tt.payload))
+			assert.NilError(t, err)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			event, err := client.ParseEvent(ctx, req, []byte(tt.payload))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, event.provider != nil)
+			assert.DeepEqual(t, event, tt.want, cmpopts.IgnoreUnexported(Event{}))
+		})
+	}
+}
+
+func TestMatchPipelineRuns(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	client, err := NewClient(Config{})
+	assert.NilError(t, err)
+	newPR := func(name string, annotations map[string]string) *tektonv1.PipelineRun {
+		return &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+	event := &Event{
+		EventType:     "pull_request",
+		TriggerTarget: "pull_request",
+		URL:           "https://github.com/owner/repo",
+		BaseBranch:    "main",
+		HeadBranch:    "feature",
+	}
+
+	matches, err := client.MatchPipelineRuns(ctx, event, []*tektonv1.PipelineRun{
+		newPR("pull-request", map[string]string{keys.OnEvent: "[pull_request]", keys.OnTargetBranch: "[main]"}),
+		newPR("push", map[string]string{keys.OnEvent: "[push]", keys.OnTargetBranch: "[main]"}),
+		newPR("cel", map[string]string{keys.OnCelExpression: `event == "pull_request" && source_branch == "feature"`}),
+		newPR("cel-files", map[string]string{keys.OnCelExpression: `files.all.exists(x, x.matches("docs/"))`}),
+	})
+	assert.NilError(t, err)
+	names := []string{}
+	for _, m := range matches {
+		names = append(names, m.PipelineRun.GetName())
+	}
+	assert.DeepEqual(t, names, []string{"pull-request", "cel"})
+	assert.Equal(t, matches[0].TargetBranch, "[main]")
+	assert.Equal(t, matches[0].TargetEvent, "[pull_request]")
+
+	_, err = client.MatchPipelineRuns(ctx, event, []*tektonv1.PipelineRun{
+		newPR("other-namespace", map[string]string{keys.OnEvent: "[pull_request]", keys.OnTargetBranch: "[main]", keys.TargetNamespace: "ns"}),
+	})
+	assert.ErrorContains(t, err, "a Pipelines-as-Code client is needed")
+}
+
+func TestResolvePipelineRuns(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	client, err := NewClient(Config{})
+	assert.NilError(t, err)
+	data := `---
+apiVersion: tekton.dev/v1
+kind: Task
+metadata:
+  name: task
+spec:
+  steps:
+    - name: step
+      image: alpine
+---
+apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: pr
+spec:
+  pipelineSpec:
+    tasks:
+      - name: task
+        taskRef:
+          name: task
+`
+
+	resolution, err := client.ResolvePipelineRuns(ctx, &Event{}, data, ResolveOptions{GenerateName: true})
+	assert.NilError(t, err)
+	assert.Equal(t, len(resolution.PipelineRuns), 1)
+	pr := resolution.PipelineRuns[0]
+	assert.Equal(t, pr.GetGenerateName(), "pr-")
+	assert.Assert(t, pr.Spec.PipelineSpec.Tasks[0].TaskRef == nil)
+	assert.Equal(t, pr.Spec.PipelineSpec.Tasks[0].TaskSpec.Steps[0].Image, "alpine")
+
+	_, err = client.ResolvePipelineRuns(ctx, &Event{}, "", ResolveOptions{})
+	assert.ErrorContains(t, err, "could not find any PipelineRun")
+}
@@ -0,0 +1,262 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/random"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	//nolint:gosec
+	cloudCredentialsSecretName = `pac-cloudcreds-%s`
+	// DefaultCloudCredentialsExpiration is the lifetime in seconds of the
+	// service account tokens exchanged for the cloud credentials.
+	DefaultCloudCredentialsExpiration = int64(3600)
+	// minCloudCredentialsExpiration is the shortest lifetime of a token
+	// accepted by the TokenRequest API.
+	minCloudCredentialsExpiration = int64(600)
+
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	//nolint:gosec
+	tokenTypeJWT = "urn:ietf:params:oauth:token-type:jwt"
+	//nolint:gosec
+	tokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// inClusterTokenPath is the token of the service account of the controller,
+// its audiences are the ones of the Kubernetes API server of the cluster.
+//
+//nolint:gosec
+var inClusterTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// CloudToken is the short-lived access token answered by the token exchange.
+type CloudToken struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	// ExpiresAt is computed from ExpiresIn when the token is received.
+	ExpiresAt time.Time `json:"-"`
+}
+
+// ValidateCloudCredentials checks the cloud_credentials setting of a
+// Repository.
+func ValidateCloudCredentials(repoSettings *v1alpha1.Settings) error {
+	if repoSettings == nil || repoSettings.CloudCredentials == nil {
+		return nil
+	}
+	cc := repoSettings.CloudCredentials
+	u, err := url.Parse(cc.TokenURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("the token_url %q of the cloud credentials must be an https url", cc.TokenURL)
+	}
+	if cc.Audience == "" {
+		return fmt.Errorf("the cloud credentials need an audience")
+	}
+	if settings.IsKubernetesAudience(cc.Audience) {
+		return fmt.Errorf("the audience %q of the cloud credentials is an audience of the Kubernetes API server", cc.Audience)
+	}
+	if cc.ExpirationSeconds != 0 && cc.ExpirationSeconds < minCloudCredentialsExpiration {
+		return fmt.Errorf("the expiration_seconds of the cloud credentials must be at least %d", minCloudCredentialsExpiration)
+	}
+	return nil
+}
+
+// CloudCredentialsServiceAccount returns the service account whose token is
+// exchanged for the cloud credentials of the PipelineRun.
+func CloudCredentialsServiceAccount(cc *v1alpha1.CloudCredentials, pr *tektonv1.PipelineRun) string {
+	if cc.ServiceAccount != "" {
+		return cc.ServiceAccount
+	}
+	if pr.Spec.TaskRunTemplate.ServiceAccountName != "" {
+		return pr.Spec.TaskRunTemplate.ServiceAccountName
+	}
+	return "default"
+}
+
+// MintCloudCredentials exchanges a token of the service account of the
+// cloud_credentials setting of the Repository for a short-lived cloud access
+// token and stores it in the secret of the PipelineRun, replacing the one of
+// a previous attempt. The token exchange endpoint and the audience must be
+// allowed by the settings and the service account must have opted in with
+// the cloud-credentials annotation. It returns the service account.
+func MintCloudCredentials(ctx context.Context, run *params.Run, kint kubeinteraction.Interface, s *settings.Settings, repo *v1alpha1.Repository, pr *tektonv1.PipelineRun, runevent *info.Event, secretName string) (string, error) {
+	if repo.Spec.Settings == nil || repo.Spec.Settings.CloudCredentials == nil {
+		return "", fmt.Errorf("the PipelineRun uses the cloud_credentials_secret variable without the cloud_credentials setting on the Repository")
+	}
+	cc := repo.Spec.Settings.CloudCredentials
+	if err := s.CloudCredentialsAllowed(cc.TokenURL, cc.Audience); err != nil {
+		return "", err
+	}
+	if settings.IsKubernetesAudience(cc.Audience) || slices.Contains(tokenAudiences(inClusterTokenPath), cc.Audience) {
+		return "", fmt.Errorf("the audience %s of the cloud credentials is an audience of the Kubernetes API server", cc.Audience)
+	}
+
+	ns := repo.GetNamespace()
+	serviceAccount := CloudCredentialsServiceAccount(cc, pr)
+	sa, err := run.Clients.Kube.CoreV1().ServiceAccounts(ns).Get(ctx, serviceAccount, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("cannot get the service account %s/%s: %w", ns, serviceAccount, err)
+	}
+	if sa.GetAnnotations()[keys.CloudCredentials] != "true" {
+		return "", fmt.Errorf("the service account %s/%s has not opted in the cloud credentials with the %s: \"true\" annotation", ns, serviceAccount, keys.CloudCredentials)
+	}
+	expiration := cc.ExpirationSeconds
+	if expiration == 0 {
+		expiration = DefaultCloudCredentialsExpiration
+	}
+
+	subjectToken, err := kint.CreateServiceAccountToken(ctx, ns, serviceAccount, cc.Audience, expiration)
+	if err != nil {
+		return "", err
+	}
+	token, err := ExchangeCloudToken(ctx, &run.Clients.HTTP, cc, subjectToken)
+	if err != nil {
+		return "", err
+	}
+	// the secret may be left by a previous start of the PipelineRun
+	if err := kint.DeleteSecret(ctx, run.Clients.Log, ns, secretName); err != nil {
+		return "", fmt.Errorf("cannot delete the previous cloud credentials secret %s: %w", secretName, err)
+	}
+	if err := kint.CreateSecret(ctx, ns, MakeCloudCredentialsSecret(runevent, secretName, token)); err != nil {
+		return "", fmt.Errorf("creating cloud credentials secret: %s has failed: %w", secretName, err)
+	}
+	return serviceAccount, nil
+}
+
+// tokenAudiences returns the audiences of the JWT of the file, none when it
+// cannot be read.
+func tokenAudiences(path string) []string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	parts := strings.Split(strings.TrimSpace(string(b)), ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	claims := struct {
+		Audience json.RawMessage `json:"aud"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+	audiences := []string{}
+	if err := json.Unmarshal(claims.Audience, &audiences); err == nil {
+		return audiences
+	}
+	audience := ""
+	if err := json.Unmarshal(claims.Audience, &audience); err == nil {
+		return []string{audience}
+	}
+	return nil
+}
+
+// ExchangeCloudToken exchanges the token of a service account for a cloud
+// access token with the OAuth 2.0 token exchange of the cloud credentials.
+func ExchangeCloudToken(ctx context.Context, client *http.Client, cc *v1alpha1.CloudCredentials, subjectToken string) (*CloudToken, error) {
+	form := url.Values{
+		"grant_type":           {tokenExchangeGrantType},
+		"audience":             {cc.Audience},
+		"subject_token":        {subjectToken},
+		"subject_token_type":   {tokenTypeJWT},
+		"requested_token_type": {tokenTypeAccessToken},
+	}
+	if cc.Scope != "" {
+		form.Set("scope", cc.Scope)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot exchange the token with %s: %w", cc.TokenURL, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// the error of the endpoint never carries the token, it is safe to
+		// report
+		return nil, fmt.Errorf("the token exchange %s answered with the status %d: %s", cc.TokenURL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	token := &CloudToken{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("cannot parse the answer of the token exchange %s: %w", cc.TokenURL, err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("the token exchange %s has answered without an access token", cc.TokenURL)
+	}
+	if token.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// MakeCloudCredentialsSecret makes the Secret holding the cloud access token
+// for the PipelineRun.
+func MakeCloudCredentialsSecret(runevent *info.Event, secretName string, token *CloudToken) *corev1.Secret {
+	secretData := map[string]string{
+		"token":      token.AccessToken,
+		"token_type": token.TokenType,
+	}
+	if !token.ExpiresAt.IsZero() {
+		secretData["expires_at"] = token.ExpiresAt.UTC().Format(time.RFC3339)
+		secretData["expires_in"] = strconv.FormatInt(token.ExpiresIn, 10)
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": pipelinesascode.GroupName,
+		keys.URLOrg:                    formatting.CleanValueKubernetes(runevent.Organization),
+		keys.URLRepository:             formatting.CleanValueKubernetes(runevent.Repository),
+	}
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   secretName,
+			Labels: labels,
+			Annotations: map[string]string{
+				keys.SHA: runevent.SHA,
+			},
+		},
+		StringData: secretData,
+	}
+}
+
+func GenerateCloudCredentialsSecretName() string {
+	return strings.ToLower(
+		fmt.Sprintf(cloudCredentialsSecretName, random.AlphaString(ranStringSeedLen)))
+}
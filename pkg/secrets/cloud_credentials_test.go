@@ -0,0 +1,150 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"gotest.tools/v3/assert"
+)
+
+func TestExchangeCloudToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		answer    string
+		wantToken string
+		wantErr   string
+	}{
+		{
+			name:      "exchanged",
+			status:    http.StatusOK,
+			answer:    `{"access_token":"short-lived","token_type":"Bearer","expires_in":3600}`,
+			wantToken: "short-lived",
+		},
+		{
+			name:    "refused",
+			status:  http.StatusBadRequest,
+			answer:  `{"error":"invalid_grant"}`,
+			wantErr: "answered with the status 400: {\"error\":\"invalid_grant\"}",
+		},
+		{
+			name:    "no access token",
+			status:  http.StatusOK,
+			answer:  `{"token_type":"Bearer"}`,
+			wantErr: "without an access token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NilError(t, r.ParseForm())
+				assert.Equal(t, r.Form.Get("grant_type"), tokenExchangeGrantType)
+				assert.Equal(t, r.Form.Get("subject_token"), "sa-token")
+				assert.Equal(t, r.Form.Get("audience"), "//iam.googleapis.com/pool")
+				assert.Equal(t, r.Form.Get("scope"), "cloud-platform")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.answer))
+			}))
+			defer server.Close()
+
+			cc := &v1alpha1.CloudCredentials{TokenURL: server.URL, Audience: "//iam.googleapis.com/pool", Scope: "cloud-platform"}
+			token, err := ExchangeCloudToken(context.Background(), server.Client(), cc, "sa-token")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, token.AccessToken, tt.wantToken)
+			assert.Assert(t, !token.ExpiresAt.IsZero())
+
+			secret := MakeCloudCredentialsSecret(&info.Event{Organization: "owner", Repository: "repo"}, "pac-cloudcreds-abcdef", token)
+			assert.Equal(t, secret.StringData["token"], tt.wantToken)
+			assert.Equal(t, secret.StringData["expires_in"], "3600")
+		})
+	}
+}
+
+func TestValidateCloudCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		cc      *v1alpha1.CloudCredentials
+		wantErr string
+	}{
+		{name: "not set"},
+		{
+			name: "valid",
+			cc:   &v1alpha1.CloudCredentials{TokenURL: "https://sts.googleapis.com/v1/token", Audience: "pool"},
+		},
+		{
+			name:    "plain http",
+			cc:      &v1alpha1.CloudCredentials{TokenURL: "http://sts.example.com/token", Audience: "pool"},
+			wantErr: "must be an https url",
+		},
+		{
+			name:    "no audience",
+			cc:      &v1alpha1.CloudCredentials{TokenURL: "https://sts.googleapis.com/v1/token"},
+			wantErr: "need an audience",
+		},
+		{
+			name:    "kubernetes audience",
+			cc:      &v1alpha1.CloudCredentials{TokenURL: "https://sts.googleapis.com/v1/token", Audience: "https://kubernetes.default.svc/"},
+			wantErr: "audience of the Kubernetes API server",
+		},
+		{
+			name:    "expiration too short",
+			cc:      &v1alpha1.CloudCredentials{TokenURL: "https://sts.googleapis.com/v1/token", Audience: "pool", ExpirationSeconds: 60},
+			wantErr: "at least 600",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCloudCredentials(&v1alpha1.Settings{CloudCredentials: tt.cc})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestTokenAudiences(t *testing.T) {
+	jwt := func(claims string) string {
+		return "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+	}
+	tests := []struct {
+		name  string
+		token string
+		want  []string
+	}{
+		{
+			name:  "list of audiences",
+			token: jwt(`{"aud":["https://kubernetes.default.svc.cluster.local","k3s"]}`),
+			want:  []string{"https://kubernetes.default.svc.cluster.local", "k3s"},
+		},
+		{
+			name:  "single audience",
+			token: jwt(`{"aud":"https://oidc.example.com"}`),
+			want:  []string{"https://oidc.example.com"},
+		},
+		{
+			name:  "not a jwt",
+			token: "token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "token")
+			assert.NilError(t, os.WriteFile(path, []byte(tt.token+"\n"), 0o600))
+			assert.DeepEqual(t, tokenAudiences(path), tt.want)
+		})
+	}
+	assert.Assert(t, tokenAudiences(filepath.Join(t.TempDir(), "missing")) == nil)
+}
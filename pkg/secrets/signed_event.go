@@ -0,0 +1,141 @@
+package secrets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/random"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	//nolint:gosec
+	signedEventSecretName = `pac-signedevent-%s`
+
+	// EventSigningKey is the key of the HMAC key in the secret of the
+	// event-signing-secret setting.
+	EventSigningKey = "signing-key"
+	// SignedEventPayloadKey is the key of the JSON metadata of the event in
+	// the signed event secret of a PipelineRun.
+	SignedEventPayloadKey = "event.json"
+	// SignedEventSignatureKey is the key of the signature of the metadata in
+	// the signed event secret of a PipelineRun.
+	SignedEventSignatureKey = "signature"
+
+	signaturePrefix = "sha256="
+)
+
+// SignedEvent is the metadata of the event triggering a PipelineRun, signed
+// by the controller for the tasks to check that they have not been changed by
+// an edit of the PipelineRun.
+type SignedEvent struct {
+	Provider          string `json:"provider"`
+	EventType         string `json:"event_type"`
+	TriggerTarget     string `json:"trigger_target"`
+	RepoURL           string `json:"repo_url"`
+	RepoOwner         string `json:"repo_owner"`
+	RepoName          string `json:"repo_name"`
+	Revision          string `json:"revision"`
+	SourceBranch      string `json:"source_branch"`
+	TargetBranch      string `json:"target_branch"`
+	SourceURL         string `json:"source_url"`
+	PullRequestNumber int    `json:"pull_request_number,omitempty"`
+	Sender            string `json:"sender"`
+	Namespace         string `json:"namespace"`
+	Repository        string `json:"repository"`
+	PipelineRun       string `json:"pipelinerun"`
+	IssuedAt          string `json:"issued_at"`
+}
+
+// NewSignedEvent returns the metadata of the event to sign for a PipelineRun
+// of a Repository.
+func NewSignedEvent(runevent *info.Event, providerName, namespace, repository, pipelineRun string) SignedEvent {
+	return SignedEvent{
+		Provider:          providerName,
+		EventType:         runevent.EventType,
+		TriggerTarget:     string(runevent.TriggerTarget),
+		RepoURL:           runevent.URL,
+		RepoOwner:         runevent.Organization,
+		RepoName:          runevent.Repository,
+		Revision:          runevent.SHA,
+		SourceBranch:      runevent.HeadBranch,
+		TargetBranch:      runevent.BaseBranch,
+		SourceURL:         runevent.HeadURL,
+		PullRequestNumber: runevent.PullRequestNumber,
+		Sender:            runevent.Sender,
+		Namespace:         namespace,
+		Repository:        repository,
+		PipelineRun:       pipelineRun,
+		IssuedAt:          time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// SignEvent returns the HMAC-SHA256 signature of the payload with the key, in
+// the sha256=<hex> format of the webhooks of GitHub.
+func SignEvent(payload, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignedEvent checks the signature of the payload with the key and
+// returns its metadata.
+func VerifySignedEvent(payload []byte, signature string, key []byte) (*SignedEvent, error) {
+	if !strings.HasPrefix(strings.TrimSpace(signature), signaturePrefix) {
+		return nil, fmt.Errorf("the signature is not a %s HMAC", strings.TrimSuffix(signaturePrefix, "="))
+	}
+	if !hmac.Equal([]byte(SignEvent(payload, key)), []byte(strings.TrimSpace(signature))) {
+		return nil, fmt.Errorf("the signature does not match the event")
+	}
+	event := &SignedEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("cannot parse the signed event: %w", err)
+	}
+	return event, nil
+}
+
+// MakeSignedEventSecret makes the Secret holding the signed metadata of the
+// event for the PipelineRun.
+func MakeSignedEventSecret(runevent *info.Event, secretName string, event SignedEvent, key []byte) (*corev1.Secret, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": pipelinesascode.GroupName,
+		keys.URLOrg:                    formatting.CleanValueKubernetes(runevent.Organization),
+		keys.URLRepository:             formatting.CleanValueKubernetes(runevent.Repository),
+	}
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   secretName,
+			Labels: labels,
+			Annotations: map[string]string{
+				keys.SHA: runevent.SHA,
+			},
+		},
+		StringData: map[string]string{
+			SignedEventPayloadKey:   string(payload),
+			SignedEventSignatureKey: SignEvent(payload, key),
+		},
+	}, nil
+}
+
+func GenerateSignedEventSecretName() string {
+	return strings.ToLower(
+		fmt.Sprintf(signedEventSecretName, random.AlphaString(ranStringSeedLen)))
+}
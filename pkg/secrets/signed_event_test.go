@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"gotest.tools/v3/assert"
+)
+
+func TestVerifySignedEvent(t *testing.T) {
+	runevent := &info.Event{EventType: "push", Organization: "owner", Repository: "repo", SHA: "123", HeadBranch: "main", BaseBranch: "main"}
+	secret, err := MakeSignedEventSecret(runevent, "pac-signedevent-abcdef", NewSignedEvent(runevent, "github", "ns", "repo", "build"), []byte("key"))
+	assert.NilError(t, err)
+	payload := secret.StringData[SignedEventPayloadKey]
+	signature := secret.StringData[SignedEventSignatureKey]
+	assert.Assert(t, strings.HasPrefix(signature, "sha256="))
+
+	tests := []struct {
+		name      string
+		payload   string
+		signature string
+		key       string
+		wantErr   string
+	}{
+		{
+			name:      "valid",
+			payload:   payload,
+			signature: signature + "\n",
+			key:       "key",
+		},
+		{
+			name:      "tampered revision",
+			payload:   strings.Replace(payload, `"revision":"123"`, `"revision":"456"`, 1),
+			signature: signature,
+			key:       "key",
+			wantErr:   "does not match",
+		},
+		{
+			name:      "other key",
+			payload:   payload,
+			signature: signature,
+			key:       "other",
+			wantErr:   "does not match",
+		},
+		{
+			name:      "not a sha256 signature",
+			payload:   payload,
+			signature: strings.TrimPrefix(signature, "sha256="),
+			key:       "key",
+			wantErr:   "not a sha256 HMAC",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := VerifySignedEvent([]byte(tt.payload), tt.signature, []byte(tt.key))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, event.Revision, "123")
+			assert.Equal(t, event.Provider, "github")
+			assert.Equal(t, event.PipelineRun, "build")
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package secretscan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Rule detects a kind of credential in the content of a file.
+type Rule struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// Finding is a credential detected in a file, the value detected is never
+// kept so it doesn't leak in the statuses or the logs.
+type Finding struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Rule string `json:"rule"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.Path, f.Line, f.Rule)
+}
+
+// DefaultRules are the well known credentials detected by the gitleaks
+// default ruleset, the rules of the settings are added to them.
+var DefaultRules = []Rule{
+	{Name: "aws-access-key-id", Regexp: regexp.MustCompile(`\b(?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`)},
+	{Name: "github-token", Regexp: regexp.MustCompile(`\bgh[pousr]_[0-9A-Za-z]{36}\b`)},
+	{Name: "github-fine-grained-pat", Regexp: regexp.MustCompile(`\bgithub_pat_[0-9A-Za-z_]{82}\b`)},
+	{Name: "gitlab-pat", Regexp: regexp.MustCompile(`\bglpat-[0-9A-Za-z_\-]{20}\b`)},
+	{Name: "slack-token", Regexp: regexp.MustCompile(`\bxox[baprs]-[0-9A-Za-z-]{10,}`)},
+	{Name: "google-api-key", Regexp: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{Name: "stripe-secret-key", Regexp: regexp.MustCompile(`\b(?:sk|rk)_live_[0-9A-Za-z]{24,}\b`)},
+	{Name: "private-key", Regexp: regexp.MustCompile(`-----BEGIN[ A-Z0-9_-]{0,100}PRIVATE KEY(?: BLOCK)?-----`)},
+}
+
+// ParseRules parses the name=regexp rules, one per line, of the
+// secret-scanning-rules setting. The lines starting with # are comments.
+func ParseRules(s string) ([]Rule, error) {
+	rules := []Rule{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, expr, ok := strings.Cut(line, "=")
+		name, expr = strings.TrimSpace(name), strings.TrimSpace(expr)
+		if !ok || name == "" || expr == "" {
+			return nil, fmt.Errorf("invalid secret scanning rule %q, needs to be of format name=regexp", line)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("secret scanning rule %q is set more than once", name)
+		}
+		seen[name] = true
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp of the secret scanning rule %s: %w", name, err)
+		}
+		rules = append(rules, Rule{Name: name, Regexp: re})
+	}
+	return rules, nil
+}
+
+// Scan returns the credentials detected by the rules in the content of the
+// file, one finding per line and rule.
+func Scan(rules []Rule, path, content string) []Finding {
+	findings := []Finding{}
+	for i, line := range strings.Split(content, "\n") {
+		for _, rule := range rules {
+			if rule.Regexp.MatchString(line) {
+				findings = append(findings, Finding{Path: path, Line: i + 1, Rule: rule.Name})
+			}
+		}
+	}
+	return findings
+}
+
+// ConsumesSecrets returns whether the PipelineRun gets some secrets: a
+// workspace bound to a secret, or a step, a sidecar or a volume of its
+// embedded tasks using a secret. The PipelineRuns referencing a Pipeline of
+// the cluster cannot be checked.
+func ConsumesSecrets(pr *tektonv1.PipelineRun) bool {
+	for _, ws := range pr.Spec.Workspaces {
+		if ws.Secret != nil || (ws.Projected != nil && projectsSecret(ws.Projected.Sources)) {
+			return true
+		}
+	}
+	if pr.Spec.PipelineSpec == nil {
+		return false
+	}
+	for _, tasks := range [][]tektonv1.PipelineTask{pr.Spec.PipelineSpec.Tasks, pr.Spec.PipelineSpec.Finally} {
+		for _, task := range tasks {
+			if task.TaskSpec != nil && taskConsumesSecrets(&task.TaskSpec.TaskSpec) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func taskConsumesSecrets(spec *tektonv1.TaskSpec) bool {
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil || (volume.Projected != nil && projectsSecret(volume.Projected.Sources)) {
+			return true
+		}
+	}
+	if spec.StepTemplate != nil && envUsesSecret(spec.StepTemplate.Env, spec.StepTemplate.EnvFrom) {
+		return true
+	}
+	for _, step := range spec.Steps {
+		if envUsesSecret(step.Env, step.EnvFrom) {
+			return true
+		}
+	}
+	for _, sidecar := range spec.Sidecars {
+		if envUsesSecret(sidecar.Env, sidecar.EnvFrom) {
+			return true
+		}
+	}
+	return false
+}
+
+func projectsSecret(sources []corev1.VolumeProjection) bool {
+	for _, source := range sources {
+		if source.Secret != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func envUsesSecret(env []corev1.EnvVar, envFrom []corev1.EnvFromSource) bool {
+	for _, e := range env {
+		if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+			return true
+		}
+	}
+	for _, e := range envFrom {
+		if e.SecretRef != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -8,8 +8,8 @@ import (
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
-	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	knative1 "knative.dev/pkg/apis/duck/v1"
 )
 
 type tkr struct {
@@ -41,13 +41,18 @@ func (trs taskrunList) Less(i, j int) bool {
 	return trs[j].Status.StartTime.Before(trs[i].Status.StartTime)
 }
 
-// TaskStatusTmpl generate a template of all status of a TaskRuns sorted to a statusTemplate as defined by the git provider.
-func TaskStatusTmpl(pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.PipelineRunTaskRunStatus, runs *params.Run, config *info.ProviderConfig) (string, error) {
+// TaskStatusTmpl generate a template of all status of a TaskRuns sorted to the task status template of the formatting profile of the git provider.
+func TaskStatusTmpl(pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.PipelineRunTaskRunStatus, runs *params.Run, profile formatting.Profile) (string, error) {
+	return taskStatusTmpl(pr, trStatus, runs, profile, profile.TaskStatusTemplate())
+}
+
+func taskStatusTmpl(pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.PipelineRunTaskRunStatus, runs *params.Run, profile formatting.Profile, tmpl string) (string, error) {
 	trl := taskrunList{}
 	outputBuffer := bytes.Buffer{}
 
+	messages := profile.Messages()
 	if len(trStatus) == 0 {
-		return "PipelineRun has no taskruns", nil
+		return messages.T("status.no_taskruns"), nil
 	}
 
 	for _, taskrunStatus := range trStatus {
@@ -58,17 +63,14 @@ func TaskStatusTmpl(pr *tektonv1.PipelineRun, trStatus map[string]*tektonv1.Pipe
 	}
 	sort.Sort(sort.Reverse(trl))
 
-	funcMap := template.FuncMap{
-		"formatDuration":  formatting.Duration,
-		"formatCondition": formatting.ConditionEmoji,
-	}
-
-	if config.SkipEmoji {
-		funcMap["formatCondition"] = formatting.ConditionSad
+	funcMap := messages.FuncMap()
+	funcMap["formatDuration"] = messages.DurationBetween
+	funcMap["formatCondition"] = func(c knative1.Conditions) string {
+		return messages.Condition(c, profile.Emoji)
 	}
 
 	data := struct{ TaskRunList taskrunList }{TaskRunList: trl}
-	t := template.Must(template.New("Task Status").Funcs(funcMap).Parse(config.TaskStatusTMPL))
+	t := template.Must(template.New("Task Status").Funcs(funcMap).Parse(tmpl))
 	if err := t.Execute(&outputBuffer, data); err != nil {
 		_, _ = fmt.Fprintf(&outputBuffer, "failed to execute template: ")
 		return "", err
@@ -4,9 +4,10 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/consoleui"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
-	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
 	tektontest "github.com/openshift-pipelines/pipelines-as-code/pkg/test/tekton"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"gotest.tools/v3/assert"
@@ -70,13 +71,10 @@ func TestStatusTmpl(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			config := &info.ProviderConfig{
-				TaskStatusTMPL: tt.tmpl,
-			}
 			runs := params.New()
 			runs.Clients.SetConsoleUI(consoleui.FallBackConsole{})
 			pr := &tektonv1.PipelineRun{}
-			output, err := TaskStatusTmpl(pr, tt.prTaskRunStatus, runs, config)
+			output, err := taskStatusTmpl(pr, tt.prTaskRunStatus, runs, formatting.Profile{Emoji: true}, tt.tmpl)
 			if tt.wantErr {
 				assert.Assert(t, err != nil)
 				return
@@ -88,3 +86,49 @@ func TestStatusTmpl(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusTmplProfiles(t *testing.T) {
+	trStatus := map[string]*tektonv1.PipelineRunTaskRunStatus{
+		"first": tektontest.MakePrTrStatus("first", "", 5),
+	}
+	tests := []struct {
+		name       string
+		profile    formatting.Profile
+		wantRegexp *regexp.Regexp
+	}{
+		{
+			name:       "html table with emoji",
+			profile:    formatting.ProfileFor("github", nil),
+			wantRegexp: regexp.MustCompile(`(?s)<table>.*<td>✅ Succeeded</td>.*\[first\]`),
+		},
+		{
+			name:       "html table without emoji",
+			profile:    formatting.ProfileFor("gitea", nil),
+			wantRegexp: regexp.MustCompile(`(?s)<table>.*<td>Succeeded</td>`),
+		},
+		{
+			name:       "markdown table",
+			profile:    formatting.ProfileFor("bitbucket-cloud", nil),
+			wantRegexp: regexp.MustCompile(`(?s)^\| \*\*Status\*\* \|.*\|✅ Succeeded\|`),
+		},
+		{
+			name:       "list",
+			profile:    formatting.ProfileFor(formatting.PlainProfile, nil),
+			wantRegexp: regexp.MustCompile(`(?m)^\* \*\*Succeeded\*\*  \[first\]`),
+		},
+		{
+			name:       "french",
+			profile:    formatting.ProfileFor("bitbucket-cloud", &v1alpha1.Settings{Locale: "fr"}),
+			wantRegexp: regexp.MustCompile(`(?s)^\| \*\*Statut\*\* \| \*\*Durée\*\* \| \*\*Nom\*\* \|.*\|✅ Réussite\|`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := params.New()
+			runs.Clients.SetConsoleUI(consoleui.FallBackConsole{})
+			output, err := TaskStatusTmpl(&tektonv1.PipelineRun{}, trStatus, runs, tt.profile)
+			assert.NilError(t, err)
+			assert.Assert(t, tt.wantRegexp.MatchString(output), "%s != %s", output, tt.wantRegexp.String())
+		})
+	}
+}
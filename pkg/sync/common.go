@@ -16,4 +16,6 @@ type Semaphore interface {
 	getLimit() int
 	getCurrentRunning() []string
 	getCurrentPending() []string
+	canAcquire() bool
+	oldestPending() int64
 }
@@ -0,0 +1,30 @@
+package sync
+
+import "time"
+
+// faultInjector injects failures in the operations of the QueueManager. It
+// is only set by the tests to check the queues keep their guarantees when the
+// callers lose the responses or the releases come late, it is nil otherwise.
+type faultInjector interface {
+	// dropResponse returns true if the PipelineRun moved to running is not
+	// returned to the caller, as if the response had been lost. It holds a
+	// slot until the queue is rebuilt from the cluster.
+	dropResponse(key string) bool
+	// delayRelease returns how long the release of the PipelineRun waits
+	// before taking the lock of the queues.
+	delayRelease(key string) time.Duration
+}
+
+// responses returns the PipelineRuns moved to running the caller gets back.
+func (qm *QueueManager) responses(acquired []string) []string {
+	if qm.faults == nil {
+		return acquired
+	}
+	kept := []string{}
+	for _, key := range acquired {
+		if !qm.faults.dropResponse(key) {
+			kept = append(kept, key)
+		}
+	}
+	return kept
+}
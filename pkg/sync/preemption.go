@@ -0,0 +1,79 @@
+package sync
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/matcher"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+const (
+	// PriorityEventPush is the event type of the PipelineRuns of the pushes
+	// in the priorities of the queue_preemption setting.
+	PriorityEventPush = "push"
+	// PriorityEventPullRequest is the event type of the PipelineRuns of the
+	// pull requests in the priorities of the queue_preemption setting.
+	PriorityEventPullRequest = "pull_request"
+)
+
+// ValidateQueuePreemption checks the priorities of the queue_preemption
+// setting of a Repository.
+func ValidateQueuePreemption(settings *v1alpha1.Settings) error {
+	if settings == nil || settings.QueuePreemption == nil {
+		return nil
+	}
+	for _, p := range settings.QueuePreemption.Priorities {
+		for _, branch := range p.Branches {
+			if err := matcher.ValidateBranchGlob(branch); err != nil {
+				return fmt.Errorf("invalid queue_preemption priority %d: %w", p.Priority, err)
+			}
+		}
+		for _, eventType := range p.EventTypes {
+			if eventType != PriorityEventPush && eventType != PriorityEventPullRequest {
+				return fmt.Errorf("invalid queue_preemption priority %d: event type %q needs to be %s or %s", p.Priority, eventType, PriorityEventPush, PriorityEventPullRequest)
+			}
+		}
+	}
+	return nil
+}
+
+// PipelineRunPriority returns the priority of the PipelineRun in the queue of
+// its Repository, the highest of the priorities of the queue_preemption
+// setting matching its target branch and its event. The priority annotation
+// comes from the .tekton directory of the event, it is only honoured for the
+// push events, a pull request could raise its own priority with it. It is 0
+// by default.
+func PipelineRunPriority(preemption *v1alpha1.QueuePreemption, pr *tektonv1.PipelineRun) int {
+	eventType := PriorityEventPush
+	if pr.GetAnnotations()[keys.PullRequest] != "" {
+		eventType = PriorityEventPullRequest
+	}
+	branch := pr.GetAnnotations()[keys.Branch]
+	priority, matched := 0, false
+	if preemption != nil {
+		for _, p := range preemption.Priorities {
+			if len(p.EventTypes) > 0 && !slices.Contains(p.EventTypes, eventType) {
+				continue
+			}
+			if len(p.Branches) > 0 && !slices.ContainsFunc(p.Branches, func(glob string) bool {
+				return matcher.BranchMatch(glob, branch)
+			}) {
+				continue
+			}
+			if !matched || p.Priority > priority {
+				priority, matched = p.Priority, true
+			}
+		}
+	}
+	if matched || eventType != PriorityEventPush {
+		return priority
+	}
+	if annotated, err := strconv.Atoi(pr.GetAnnotations()[keys.Priority]); err == nil {
+		return annotated
+	}
+	return 0
+}
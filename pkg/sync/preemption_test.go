@@ -0,0 +1,124 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPipelineRunPriority(t *testing.T) {
+	preemption := &v1alpha1.QueuePreemption{
+		MinPriority: 100,
+		Priorities: []v1alpha1.QueuePriority{
+			{Priority: 100, Branches: []string{"main", "release-*"}, EventTypes: []string{PriorityEventPush}},
+			{Priority: 50, Branches: []string{"main"}},
+			{Priority: 10, EventTypes: []string{PriorityEventPullRequest}},
+		},
+	}
+	tests := []struct {
+		name        string
+		preemption  *v1alpha1.QueuePreemption
+		branch      string
+		pullRequest string
+		annotation  string
+		want        int
+	}{
+		{
+			name:       "push on a release branch",
+			preemption: preemption,
+			branch:     "refs/heads/release-1.0",
+			want:       100,
+		},
+		{
+			name:        "pull request on main",
+			preemption:  preemption,
+			branch:      "main",
+			pullRequest: "1",
+			want:        50,
+		},
+		{
+			name:        "pull request raising its own priority",
+			preemption:  preemption,
+			branch:      "feature",
+			pullRequest: "1",
+			annotation:  "1000",
+			want:        10,
+		},
+		{
+			name:        "annotation ignored on a pull request",
+			branch:      "main",
+			pullRequest: "1",
+			annotation:  "1000",
+			want:        0,
+		},
+		{
+			name:       "annotation of a push without a matching priority",
+			preemption: preemption,
+			branch:     "feature",
+			annotation: "20",
+			want:       20,
+		},
+		{
+			name:       "priority of the setting over the annotation of a push",
+			preemption: preemption,
+			branch:     "main",
+			annotation: "1000",
+			want:       100,
+		},
+		{
+			name:       "invalid annotation",
+			annotation: "high",
+			want:       0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{keys.Branch: tt.branch}
+			if tt.pullRequest != "" {
+				annotations[keys.PullRequest] = tt.pullRequest
+			}
+			if tt.annotation != "" {
+				annotations[keys.Priority] = tt.annotation
+			}
+			pr := &tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pr", Annotations: annotations}}
+			assert.Equal(t, PipelineRunPriority(tt.preemption, pr), tt.want)
+		})
+	}
+}
+
+func TestValidateQueuePreemption(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities []v1alpha1.QueuePriority
+		wantErr    string
+	}{
+		{
+			name:       "valid",
+			priorities: []v1alpha1.QueuePriority{{Priority: 100, Branches: []string{"release-*"}, EventTypes: []string{"push", "pull_request"}}},
+		},
+		{
+			name:       "invalid branch glob",
+			priorities: []v1alpha1.QueuePriority{{Priority: 100, Branches: []string{"release-[1"}}},
+			wantErr:    `invalid queue_preemption priority 100: invalid branch glob "release-[1"`,
+		},
+		{
+			name:       "invalid event type",
+			priorities: []v1alpha1.QueuePriority{{Priority: 100, EventTypes: []string{"tag"}}},
+			wantErr:    `event type "tag" needs to be push or pull_request`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateQueuePreemption(&v1alpha1.Settings{QueuePreemption: &v1alpha1.QueuePreemption{MinPriority: 100, Priorities: tt.priorities}})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
@@ -25,24 +25,42 @@ const (
 
 type QueueManager struct {
 	queueMap map[string]Semaphore
-	lock     *sync.Mutex
-	logger   *zap.SugaredLogger
+	// weights are the queue weights of the repositories, their share of the
+	// global limit.
+	weights map[string]int
+	// globalLimit is the number of PipelineRuns running at once in all the
+	// queues, 0 when only the limits of the repositories apply.
+	globalLimit int
+	lock        *sync.Mutex
+	logger      *zap.SugaredLogger
+	faults      faultInjector
 }
 
 func NewQueueManager(logger *zap.SugaredLogger) *QueueManager {
 	return &QueueManager{
 		queueMap: make(map[string]Semaphore),
+		weights:  make(map[string]int),
 		lock:     &sync.Mutex{},
 		logger:   logger,
 	}
 }
 
+// SetGlobalLimit sets the number of PipelineRuns running at once in all the
+// queues, 0 for no limit.
+func (qm *QueueManager) SetGlobalLimit(limit int) {
+	qm.lock.Lock()
+	defer qm.lock.Unlock()
+
+	qm.globalLimit = limit
+}
+
 // getSemaphore returns existing semaphore created for repository or create
 // a new one with limit provided in repository
 // Semaphore: nothing but a waiting and a running queue for a repository
 // with limit deciding how many should be running at a time.
 func (qm *QueueManager) getSemaphore(repo *v1alpha1.Repository) (Semaphore, error) {
 	repoKey := repoKey(repo)
+	qm.weights[repoKey] = queueWeight(repo)
 
 	if sema, found := qm.queueMap[repoKey]; found {
 		if err := qm.checkAndUpdateSemaphoreSize(repo, sema); err != nil {
@@ -65,6 +83,65 @@ func repoKey(repo *v1alpha1.Repository) string {
 	return fmt.Sprintf("%s/%s", repo.Namespace, repo.Name)
 }
 
+func queueWeight(repo *v1alpha1.Repository) int {
+	if repo.Spec.Settings == nil || repo.Spec.Settings.QueueWeight <= 0 {
+		return 1
+	}
+	return repo.Spec.Settings.QueueWeight
+}
+
+// acquireNext moves the next PipelineRun to running and returns its key, or
+// "" when none can start. Without a global limit it is the next one of the
+// queue of the repository, with a global limit it is the next one of the
+// queue with the fewest PipelineRuns running for its weight, so a repository
+// with a lot of PipelineRuns queued doesn't take all the slots.
+func (qm *QueueManager) acquireNext(repoKey string) string {
+	if qm.globalLimit == 0 {
+		if sema, ok := qm.queueMap[repoKey]; ok {
+			return sema.acquireLatest()
+		}
+		return ""
+	}
+
+	running := 0
+	for _, sema := range qm.queueMap {
+		running += len(sema.getCurrentRunning())
+	}
+	if running >= qm.globalLimit {
+		return ""
+	}
+	var next Semaphore
+	nextKey := ""
+	for key, sema := range qm.queueMap {
+		if !sema.canAcquire() {
+			continue
+		}
+		if next == nil || qm.fairer(key, sema, nextKey, next) {
+			next, nextKey = sema, key
+		}
+	}
+	if next == nil {
+		return ""
+	}
+	return next.acquireLatest()
+}
+
+// fairer returns true if the queue a gets the next slot before the queue b:
+// it has fewer PipelineRuns running for its weight or, on a tie, its next
+// PipelineRun has been waiting for longer.
+func (qm *QueueManager) fairer(aKey string, a Semaphore, bKey string, b Semaphore) bool {
+	// (running(a)+1)/weight(a) < (running(b)+1)/weight(b)
+	aShare := (len(a.getCurrentRunning()) + 1) * qm.weights[bKey]
+	bShare := (len(b.getCurrentRunning()) + 1) * qm.weights[aKey]
+	if aShare != bShare {
+		return aShare < bShare
+	}
+	if a.oldestPending() != b.oldestPending() {
+		return a.oldestPending() < b.oldestPending()
+	}
+	return aKey < bKey
+}
+
 func (qm *QueueManager) checkAndUpdateSemaphoreSize(repo *v1alpha1.Repository, semaphore Semaphore) error {
 	limit := *repo.Spec.ConcurrencyLimit
 	if limit != semaphore.getLimit() {
@@ -79,7 +156,8 @@ func (qm *QueueManager) checkAndUpdateSemaphoreSize(repo *v1alpha1.Repository, s
 // AddListToQueue adds the pipelineRun to the waiting queue of the repository
 // and if it is at the top and ready to run which means currently running pipelineRun < limit
 // then move it to running queue
-// This adds the pipelineRuns in the same order as in the list.
+// This adds the pipelineRuns in the same order as in the list. With a global
+// limit, the PipelineRuns moved to running may belong to other repositories.
 func (qm *QueueManager) AddListToQueue(repo *v1alpha1.Repository, list []string) ([]string, error) {
 	qm.lock.Lock()
 	defer qm.lock.Unlock()
@@ -103,21 +181,34 @@ func (qm *QueueManager) AddListToQueue(repo *v1alpha1.Repository, list []string)
 	}
 
 	acquiredList := []string{}
-	for i := 0; i < *repo.Spec.ConcurrencyLimit; i++ {
-		acquired := sema.acquireLatest()
-		if acquired != "" {
-			qm.logger.Infof("moved (%s) to running for repository (%s)", acquired, repoKey(repo))
-			acquiredList = append(acquiredList, acquired)
+	if qm.globalLimit == 0 {
+		for i := 0; i < *repo.Spec.ConcurrencyLimit; i++ {
+			acquired := sema.acquireLatest()
+			if acquired != "" {
+				qm.logger.Infof("moved (%s) to running for repository (%s)", acquired, repoKey(repo))
+				acquiredList = append(acquiredList, acquired)
+			}
 		}
+		return qm.responses(acquiredList), nil
 	}
 
-	return acquiredList, nil
+	for acquired := qm.acquireNext(repoKey(repo)); acquired != ""; acquired = qm.acquireNext(repoKey(repo)) {
+		qm.logger.Infof("moved (%s) to running within the global limit of %d", acquired, qm.globalLimit)
+		acquiredList = append(acquiredList, acquired)
+	}
+	return qm.responses(acquiredList), nil
 }
 
 // RemoveFromQueue removes the pipelineRun from the queues of the repository
 // It also start the next one which is on top of the waiting queue and return its name
-// if started or returns "".
+// if started or returns "". With a global limit, the next one may belong to
+// another repository.
 func (qm *QueueManager) RemoveFromQueue(repo *v1alpha1.Repository, run *tektonv1.PipelineRun) string {
+	qKey := getQueueKey(run)
+	if qm.faults != nil {
+		time.Sleep(qm.faults.delayRelease(qKey))
+	}
+
 	qm.lock.Lock()
 	defer qm.lock.Unlock()
 
@@ -127,18 +218,31 @@ func (qm *QueueManager) RemoveFromQueue(repo *v1alpha1.Repository, run *tektonv1
 		return ""
 	}
 
-	qKey := getQueueKey(run)
 	sema.release(qKey)
 	sema.removeFromQueue(qKey)
 	qm.logger.Infof("removed (%s) for repository (%s)", qKey, repoKey)
 
-	if next := sema.acquireLatest(); next != "" {
-		qm.logger.Infof("moved (%s) to running for repository (%s)", next, repoKey)
+	if next := qm.acquireNext(repoKey); next != "" {
+		qm.logger.Infof("moved (%s) to running after (%s) of repository (%s)", next, qKey, repoKey)
+		if len(qm.responses([]string{next})) == 0 {
+			return ""
+		}
 		return next
 	}
 	return ""
 }
 
+// DropFromQueue removes the queued PipelineRun from the waiting queue of the
+// repository without starting another one, as it was not holding a slot.
+func (qm *QueueManager) DropFromQueue(repo *v1alpha1.Repository, run *tektonv1.PipelineRun) {
+	qm.lock.Lock()
+	defer qm.lock.Unlock()
+
+	if sema, found := qm.queueMap[repoKey(repo)]; found {
+		sema.removeFromQueue(getQueueKey(run))
+	}
+}
+
 func getQueueKey(run *tektonv1.PipelineRun) string {
 	return fmt.Sprintf("%s/%s", run.Namespace, run.Name)
 }
@@ -217,12 +321,50 @@ func (qm *QueueManager) InitQueues(ctx context.Context, tekton versioned2.Interf
 	return nil
 }
 
+// ResetQueues drops all the queues, they are rebuilt with InitQueues.
+func (qm *QueueManager) ResetQueues() {
+	qm.lock.Lock()
+	defer qm.lock.Unlock()
+
+	qm.queueMap = make(map[string]Semaphore)
+	qm.weights = make(map[string]int)
+}
+
+// SyncRepository rebuilds the queue of the repository from the state of its
+// PipelineRuns in the cluster, the running ones and the queued ones in their
+// creation order. It is used when the PipelineRuns of a repository are
+// reconciled by several watcher replicas and the queue of a replica may miss
+// the changes of the others.
+func (qm *QueueManager) SyncRepository(repo *v1alpha1.Repository, running, queued []*tektonv1.PipelineRun) {
+	qm.lock.Lock()
+	defer qm.lock.Unlock()
+
+	limit := 0
+	if repo.Spec.ConcurrencyLimit != nil {
+		limit = *repo.Spec.ConcurrencyLimit
+	}
+	sema := newSemaphore(repoKey(repo), limit)
+	for _, pr := range running {
+		key := getQueueKey(pr)
+		if !sema.acquire(key) {
+			// the limit has been lowered, keep it as running until it is done
+			sema.running[key] = true
+		}
+	}
+	for _, pr := range queued {
+		sema.addToQueue(getQueueKey(pr), pr.GetCreationTimestamp().Time)
+	}
+	qm.queueMap[repoKey(repo)] = sema
+	qm.weights[repoKey(repo)] = queueWeight(repo)
+}
+
 func (qm *QueueManager) RemoveRepository(repo *v1alpha1.Repository) {
 	qm.lock.Lock()
 	defer qm.lock.Unlock()
 
 	repoKey := repoKey(repo)
 	delete(qm.queueMap, repoKey)
+	delete(qm.weights, repoKey)
 }
 
 func (qm *QueueManager) QueuedPipelineRuns(repo *v1alpha1.Repository) []string {
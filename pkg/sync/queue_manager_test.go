@@ -1,6 +1,7 @@
 package sync
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -146,6 +147,37 @@ func TestNewQueueManagerReListing(t *testing.T) {
 	assert.Equal(t, len(qm.QueuedPipelineRuns(repo)), 4)
 }
 
+func TestSyncRepository(t *testing.T) {
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observer).Sugar()
+
+	qm := NewQueueManager(logger)
+	repo := newTestRepo(1)
+
+	// another replica started the second one, the queue of this one is stale
+	prFirst := newTestPR("first", time.Now(), nil, nil)
+	prSecond := newTestPR("second", time.Now().Add(1*time.Second), nil, nil)
+	prThird := newTestPR("third", time.Now().Add(2*time.Second), nil, nil)
+	started, err := qm.AddListToQueue(repo, []string{getQueueKey(prFirst), getQueueKey(prSecond), getQueueKey(prThird)})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, started, []string{getQueueKey(prFirst)})
+
+	qm.SyncRepository(repo, []*tektonv1.PipelineRun{prSecond}, []*tektonv1.PipelineRun{prThird})
+	assert.DeepEqual(t, qm.RunningPipelineRuns(repo), []string{getQueueKey(prSecond)})
+	assert.DeepEqual(t, qm.QueuedPipelineRuns(repo), []string{getQueueKey(prThird)})
+
+	// the third one starts when the second one is done
+	assert.Equal(t, qm.RemoveFromQueue(repo, prSecond), getQueueKey(prThird))
+
+	// the running ones above a lowered limit are kept running
+	qm.SyncRepository(repo, []*tektonv1.PipelineRun{prFirst, prSecond}, nil)
+	assert.Equal(t, len(qm.RunningPipelineRuns(repo)), 2)
+	assert.Equal(t, qm.RemoveFromQueue(repo, prFirst), "")
+
+	qm.ResetQueues()
+	assert.Equal(t, len(qm.RunningPipelineRuns(repo)), 0)
+}
+
 func newTestRepo(limit int) *v1alpha1.Repository {
 	return &v1alpha1.Repository{
 		ObjectMeta: metav1.ObjectMeta{
@@ -231,3 +263,89 @@ func TestQueueManager_InitQueues(t *testing.T) {
 	runs = qm.QueuedPipelineRuns(repo)
 	assert.Equal(t, len(runs), 1)
 }
+
+func TestQueueManagerGlobalLimitFairness(t *testing.T) {
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observer).Sugar()
+
+	qm := NewQueueManager(logger)
+	qm.SetGlobalLimit(3)
+
+	noisy := newTestRepo(10)
+	noisy.Name = "noisy"
+	quiet := newTestRepo(10)
+	quiet.Name = "quiet"
+	quiet.Spec.Settings = &v1alpha1.Settings{QueueWeight: 2}
+
+	noisyPRs := []*tektonv1.PipelineRun{}
+	noisyKeys := []string{}
+	for i := 0; i < 6; i++ {
+		pr := newTestPR(fmt.Sprintf("noisy-%d", i), time.Now(), nil, nil)
+		noisyPRs = append(noisyPRs, pr)
+		noisyKeys = append(noisyKeys, getQueueKey(pr))
+	}
+	started, err := qm.AddListToQueue(noisy, noisyKeys)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, started, noisyKeys[:3])
+
+	// the global limit is reached, the quiet repository waits
+	started, err = qm.AddListToQueue(quiet, []string{"test-ns/quiet-0", "test-ns/quiet-1", "test-ns/quiet-2"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(started), 0)
+
+	// the slots freed by the noisy repository go to the quiet one until it
+	// runs twice as many PipelineRuns as its weight is twice bigger
+	assert.Equal(t, qm.RemoveFromQueue(noisy, noisyPRs[0]), "test-ns/quiet-0")
+	assert.Equal(t, qm.RemoveFromQueue(noisy, noisyPRs[1]), "test-ns/quiet-1")
+	assert.Equal(t, qm.RemoveFromQueue(noisy, noisyPRs[2]), "test-ns/noisy-3")
+	assert.Equal(t, len(qm.RunningPipelineRuns(quiet)), 2)
+	assert.Equal(t, len(qm.RunningPipelineRuns(noisy)), 1)
+
+	// without a global limit only the limit of the repository applies
+	qm.SetGlobalLimit(0)
+	assert.Equal(t, qm.RemoveFromQueue(noisy, noisyPRs[3]), "test-ns/noisy-4")
+	started, err = qm.AddListToQueue(quiet, []string{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, started, []string{"test-ns/quiet-2"})
+}
+
+// dropFaults drops the responses of the keys and delays all the releases.
+type dropFaults struct {
+	drop  map[string]bool
+	delay time.Duration
+}
+
+func (f *dropFaults) dropResponse(key string) bool      { return f.drop[key] }
+func (f *dropFaults) delayRelease(string) time.Duration { return f.delay }
+
+func TestQueueManagerFaults(t *testing.T) {
+	observer, _ := zapobserver.New(zap.InfoLevel)
+	logger := zap.New(observer).Sugar()
+
+	qm := NewQueueManager(logger)
+	repo := newTestRepo(1)
+	prFirst := newTestPR("first", time.Now(), nil, nil)
+	prSecond := newTestPR("second", time.Now().Add(1*time.Second), nil, nil)
+	prThird := newTestPR("third", time.Now().Add(2*time.Second), nil, nil)
+	qm.faults = &dropFaults{drop: map[string]bool{getQueueKey(prFirst): true}, delay: time.Millisecond}
+
+	// the response is lost, the first one holds the slot without being started
+	started, err := qm.AddListToQueue(repo, []string{getQueueKey(prFirst), getQueueKey(prSecond), getQueueKey(prThird)})
+	assert.NilError(t, err)
+	assert.Equal(t, len(started), 0)
+	assert.DeepEqual(t, qm.RunningPipelineRuns(repo), []string{getQueueKey(prFirst)})
+
+	// releasing twice a PipelineRun not running drops it from the queue and
+	// starts nothing
+	assert.Equal(t, qm.RemoveFromQueue(repo, prThird), "")
+	assert.Equal(t, qm.RemoveFromQueue(repo, prThird), "")
+	assert.DeepEqual(t, qm.QueuedPipelineRuns(repo), []string{getQueueKey(prSecond)})
+
+	// the slot is given back when the queue is rebuilt from the cluster
+	qm.faults = &dropFaults{}
+	qm.SyncRepository(repo, nil, []*tektonv1.PipelineRun{prFirst, prSecond})
+	started, err = qm.AddListToQueue(repo, []string{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, started, []string{getQueueKey(prFirst)})
+	assert.Equal(t, qm.RemoveFromQueue(repo, prFirst), getQueueKey(prSecond))
+}
@@ -54,6 +54,27 @@ func (s *prioritySemaphore) getCurrentRunning() []string {
 	return keys
 }
 
+// canAcquire returns true if a PipelineRun is waiting and the queue has a
+// free slot for it.
+func (s *prioritySemaphore) canAcquire() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.pending.Len() > 0 && len(s.running) < s.limit
+}
+
+// oldestPending returns the priority, the time it has been queued at, of
+// the PipelineRun waiting first.
+func (s *prioritySemaphore) oldestPending() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.pending.Len() == 0 {
+		return 0
+	}
+	return s.pending.peek().priority
+}
+
 func (s *prioritySemaphore) resize(n int) bool {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -0,0 +1,390 @@
+//go:build soak
+// +build soak
+
+package sync
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"go.uber.org/zap"
+)
+
+var soakTime = flag.Duration("soak-time", 10*time.Second, "how long each soak test runs")
+
+const (
+	soakRound      = 200 * time.Millisecond
+	soakSubmitters = 4
+)
+
+// chaos drops the responses and delays the releases of the QueueManager at
+// random, the soak cluster duplicates the callbacks with duplicateRate.
+type chaos struct {
+	mutex         sync.Mutex
+	rand          *rand.Rand
+	dropRate      float64
+	duplicateRate float64
+	maxDelay      time.Duration
+	// lost are the PipelineRuns moved to running whose response has been
+	// dropped, they hold a slot until the queue is rebuilt.
+	lost                         map[string]bool
+	dropped, delayed, duplicated int
+}
+
+func (c *chaos) dropResponse(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.rand.Float64() >= c.dropRate {
+		return false
+	}
+	c.dropped++
+	c.lost[key] = true
+	return true
+}
+
+func (c *chaos) delayRelease(string) time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.maxDelay == 0 {
+		return 0
+	}
+	c.delayed++
+	return time.Duration(c.rand.Int63n(int64(c.maxDelay)))
+}
+
+func (c *chaos) duplicate() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.rand.Float64() >= c.duplicateRate {
+		return false
+	}
+	c.duplicated++
+	return true
+}
+
+func (c *chaos) isLost(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lost[key]
+}
+
+func (c *chaos) recovered() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.lost = map[string]bool{}
+}
+
+func (c *chaos) sleep(maxSleep time.Duration) {
+	c.mutex.Lock()
+	d := time.Duration(c.rand.Int63n(int64(maxSleep)))
+	c.mutex.Unlock()
+	time.Sleep(d)
+}
+
+const (
+	soakQueued  = "queued"
+	soakStarted = "started"
+	soakDone    = "done"
+)
+
+type soakRun struct {
+	pr    *tektonv1.PipelineRun
+	repo  *v1alpha1.Repository
+	state string
+	// seq is the order the PipelineRun has been added to the queue of its
+	// repository.
+	seq int
+}
+
+// soakCluster plays the reconciler and the PipelineRuns of the cluster: it
+// queues new PipelineRuns, starts the ones the QueueManager moves to running
+// and releases them when they are done, rebuilding the queues from the
+// cluster between the rounds as the reconciler does.
+type soakCluster struct {
+	mutex       sync.Mutex
+	qm          *QueueManager
+	chaos       *chaos
+	repos       []*v1alpha1.Repository
+	globalLimit int
+	runs        map[string]*soakRun
+	seq         int
+	done        int
+	running     map[*v1alpha1.Repository]int
+	inflight    sync.WaitGroup
+	violations  []string
+}
+
+func newSoakCluster(qm *QueueManager, faults *chaos, limits []int, globalLimit int) *soakCluster {
+	c := &soakCluster{
+		qm:          qm,
+		chaos:       faults,
+		globalLimit: globalLimit,
+		runs:        map[string]*soakRun{},
+		running:     map[*v1alpha1.Repository]int{},
+	}
+	for i, limit := range limits {
+		repo := newTestRepo(limit)
+		repo.Namespace = fmt.Sprintf("soak-%d", i)
+		repo.Spec.Settings = &v1alpha1.Settings{QueueWeight: i + 1}
+		c.repos = append(c.repos, repo)
+	}
+	qm.SetGlobalLimit(globalLimit)
+	return c
+}
+
+func (c *soakCluster) violation(format string, args ...any) {
+	c.violations = append(c.violations, fmt.Sprintf(format, args...))
+}
+
+// submit creates a PipelineRun on the repository and queues it.
+func (c *soakCluster) submit(repo *v1alpha1.Repository) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.seq++
+	run := &soakRun{
+		pr:    newTestPR(fmt.Sprintf("run-%d", c.seq), time.Now(), nil, nil),
+		repo:  repo,
+		state: soakQueued,
+		seq:   c.seq,
+	}
+	run.pr.Namespace = repo.Namespace
+	key := getQueueKey(run.pr)
+	c.runs[key] = run
+
+	c.queue(repo, []string{key})
+	// the reconciler only queues the PipelineRuns still in the queued state
+	if c.chaos.duplicate() && run.state == soakQueued {
+		c.queue(repo, []string{key})
+	}
+}
+
+func (c *soakCluster) queue(repo *v1alpha1.Repository, keys []string) {
+	acquired, err := c.qm.AddListToQueue(repo, keys)
+	if err != nil {
+		c.violation("cannot queue %v: %v", keys, err)
+		return
+	}
+	for _, key := range acquired {
+		c.start(key)
+	}
+}
+
+// start starts the PipelineRun moved to running by the QueueManager, it must
+// be queued, the first one of its repository and within the limits.
+func (c *soakCluster) start(key string) {
+	run, ok := c.runs[key]
+	if !ok {
+		c.violation("unknown pipelineRun %s moved to running", key)
+		return
+	}
+	if run.state != soakQueued {
+		c.violation("pipelineRun %s moved to running while %s", key, run.state)
+		return
+	}
+	for _, pending := range c.qm.QueuedPipelineRuns(run.repo) {
+		if c.runs[pending].seq < run.seq {
+			c.violation("pipelineRun %s moved to running before %s queued earlier", key, pending)
+		}
+	}
+	run.state = soakStarted
+	c.running[run.repo]++
+
+	running := 0
+	for _, n := range c.running {
+		running += n
+	}
+	if limit := *run.repo.Spec.ConcurrencyLimit; c.running[run.repo] > limit {
+		c.violation("%d pipelineRuns running on %s over its limit of %d", c.running[run.repo], run.repo.Namespace, limit)
+	}
+	if c.globalLimit > 0 && running > c.globalLimit {
+		c.violation("%d pipelineRuns running over the global limit of %d", running, c.globalLimit)
+	}
+
+	c.inflight.Add(1)
+	go c.complete(run)
+}
+
+// complete finishes the PipelineRun after a while and releases its slot.
+func (c *soakCluster) complete(run *soakRun) {
+	defer c.inflight.Done()
+	c.chaos.sleep(5 * time.Millisecond)
+
+	c.mutex.Lock()
+	run.state = soakDone
+	c.running[run.repo]--
+	c.mutex.Unlock()
+
+	releases := 1
+	if c.chaos.duplicate() {
+		releases = 2
+	}
+	for i := 0; i < releases; i++ {
+		next := c.qm.RemoveFromQueue(run.repo, run.pr)
+		if next == "" {
+			continue
+		}
+		c.mutex.Lock()
+		c.start(next)
+		c.mutex.Unlock()
+	}
+}
+
+// check verifies the queues match the cluster once nothing is in flight:
+// only the PipelineRuns with a lost response hold a slot and the queued
+// ones are all waiting in the queues. The PipelineRuns done are forgotten,
+// moving them to running again shows as an unknown PipelineRun.
+func (c *soakCluster) check() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key, run := range c.runs {
+		if run.state == soakDone {
+			delete(c.runs, key)
+			c.done++
+		}
+	}
+
+	for _, repo := range c.repos {
+		for _, key := range c.qm.RunningPipelineRuns(repo) {
+			if !c.chaos.isLost(key) {
+				c.violation("pipelineRun %s holds a slot of %s without being started", key, repo.Namespace)
+			}
+		}
+		pending := map[string]bool{}
+		for _, key := range c.qm.QueuedPipelineRuns(repo) {
+			pending[key] = true
+		}
+		for key, run := range c.runs {
+			if run.repo == repo && run.state == soakQueued && !pending[key] && !c.chaos.isLost(key) {
+				c.violation("queued pipelineRun %s is missing from the queue of %s", key, repo.Namespace)
+			}
+		}
+	}
+}
+
+// recover rebuilds the queues from the cluster and queues again the
+// PipelineRuns still queued, it returns false once they are all done.
+func (c *soakCluster) recover() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	queuedByRepo := map[*v1alpha1.Repository][]*soakRun{}
+	for _, run := range c.runs {
+		if run.state == soakQueued {
+			queuedByRepo[run.repo] = append(queuedByRepo[run.repo], run)
+		}
+	}
+	for _, repo := range c.repos {
+		queued := queuedByRepo[repo]
+		sort.Slice(queued, func(i, j int) bool { return queued[i].seq < queued[j].seq })
+		prs := []*tektonv1.PipelineRun{}
+		for _, run := range queued {
+			prs = append(prs, run.pr)
+		}
+		c.qm.SyncRepository(repo, nil, prs)
+	}
+	c.chaos.recovered()
+
+	for _, repo := range c.repos {
+		keys := []string{}
+		for _, run := range queuedByRepo[repo] {
+			keys = append(keys, getQueueKey(run.pr))
+		}
+		c.queue(repo, keys)
+	}
+	return len(queuedByRepo) > 0
+}
+
+// round queues PipelineRuns from several submitters for a while and waits
+// for the ones started to be done.
+func (c *soakCluster) round() {
+	deadline := time.Now().Add(soakRound)
+	submitters := sync.WaitGroup{}
+	for i := 0; i < soakSubmitters; i++ {
+		submitters.Add(1)
+		go func(i int) {
+			defer submitters.Done()
+			for n := i; time.Now().Before(deadline); n++ {
+				c.submit(c.repos[n%len(c.repos)])
+				c.chaos.sleep(2 * time.Millisecond)
+			}
+		}(i)
+	}
+	submitters.Wait()
+	c.inflight.Wait()
+}
+
+func TestSoakQueueManager(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      []int
+		globalLimit int
+		faults      *chaos
+	}{
+		{
+			name:   "without faults",
+			limits: []int{1, 2, 3},
+			faults: &chaos{},
+		},
+		{
+			name:   "dropped responses, delayed releases and duplicated callbacks",
+			limits: []int{1, 2, 3},
+			faults: &chaos{dropRate: 0.05, duplicateRate: 0.1, maxDelay: 2 * time.Millisecond},
+		},
+		{
+			name:        "global limit with faults",
+			limits:      []int{1, 2, 3, 4},
+			globalLimit: 4,
+			faults:      &chaos{dropRate: 0.05, duplicateRate: 0.1, maxDelay: 2 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed := time.Now().UnixNano()
+			t.Logf("seed %d", seed)
+			tt.faults.rand = rand.New(rand.NewSource(seed)) //nolint: gosec
+			tt.faults.lost = map[string]bool{}
+
+			qm := NewQueueManager(zap.NewNop().Sugar())
+			qm.faults = tt.faults
+			c := newSoakCluster(qm, tt.faults, tt.limits, tt.globalLimit)
+
+			for deadline := time.Now().Add(*soakTime); time.Now().Before(deadline); {
+				c.round()
+				c.check()
+				// the queues catch up with the lost responses once rebuilt
+				for c.recover() {
+					c.inflight.Wait()
+					c.check()
+				}
+			}
+
+			c.check()
+			for key, run := range c.runs {
+				c.violation("pipelineRun %s is %s at the end of the soak", key, run.state)
+			}
+			for i, v := range c.violations {
+				if i == 20 {
+					t.Errorf("... and %d more violations", len(c.violations)-i)
+					break
+				}
+				t.Error(v)
+			}
+			t.Logf("%d pipelineRuns: %d responses dropped, %d releases delayed, %d callbacks duplicated",
+				c.done, tt.faults.dropped, tt.faults.delayed, tt.faults.duplicated)
+		})
+	}
+}
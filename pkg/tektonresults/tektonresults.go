@@ -0,0 +1,275 @@
+package tektonresults
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// apiPath is the path of the REST API of Tekton Results.
+	apiPath = "/apis/results.tekton.dev/v1alpha2"
+	// PipelineRunType is the type of the records of the PipelineRuns.
+	PipelineRunType = "tekton.dev/v1.PipelineRun"
+	// RecordAnnotation is set by the Tekton Results watcher on the
+	// PipelineRuns it has already stored.
+	RecordAnnotation = "results.tekton.dev/record"
+	// ServiceAccountTokenPath is where the token of the service account of
+	// the controller is mounted, it is used to authenticate to Tekton Results.
+	ServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec
+)
+
+// ErrNotFound is returned when Tekton Results has no record of a PipelineRun.
+var ErrNotFound = errors.New("no record found in Tekton Results")
+
+// Client talks to the REST API of Tekton Results.
+type Client struct {
+	URL   string
+	Token string
+	HTTP  *http.Client
+}
+
+type recordData struct {
+	Type  string `json:"type"`
+	Value []byte `json:"value"`
+}
+
+type record struct {
+	Name string     `json:"name"`
+	Data recordData `json:"data"`
+}
+
+type recordSummary struct {
+	Record string `json:"record"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+type result struct {
+	Name    string        `json:"name"`
+	Summary recordSummary `json:"summary"`
+}
+
+type listRecordsResponse struct {
+	Records []record `json:"records"`
+}
+
+// NewClient returns a client of the Tekton Results API at baseURL authenticating
+// with the token of the service account of the controller when mounted.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	token, _ := os.ReadFile(ServiceAccountTokenPath)
+	return &Client{
+		URL:   strings.TrimSuffix(baseURL, "/"),
+		Token: strings.TrimSpace(string(token)),
+		HTTP:  httpClient,
+	}
+}
+
+// recordStatus maps the condition of the PipelineRun to the status of the
+// summary of a result.
+func recordStatus(pr *tektonv1.PipelineRun) string {
+	cond := pr.Status.GetCondition(apis.ConditionSucceeded)
+	switch {
+	case cond == nil:
+		return "UNKNOWN"
+	case cond.IsTrue():
+		return "SUCCESS"
+	case cond.Reason == tektonv1.PipelineRunReasonTimedOut.String():
+		return "TIMEOUT"
+	case cond.Reason == tektonv1.PipelineRunReasonCancelled.String():
+		return "CANCELLED"
+	case cond.IsFalse():
+		return "FAILURE"
+	}
+	return "UNKNOWN"
+}
+
+// StoreRecord writes the finished PipelineRun as a record of Tekton Results,
+// with its status, its annotations and labels holding the event metadata and
+// the URL of its logs. A PipelineRun already stored is left as is.
+func (c *Client) StoreRecord(ctx context.Context, pr *tektonv1.PipelineRun) error {
+	if _, ok := pr.GetAnnotations()[RecordAnnotation]; ok {
+		return nil
+	}
+	uid := string(pr.GetUID())
+	if uid == "" {
+		return fmt.Errorf("the PipelineRun %s has no uid", pr.GetName())
+	}
+	parent := pr.GetNamespace()
+	resultName := fmt.Sprintf("%s/results/%s", parent, uid)
+	recordName := fmt.Sprintf("%s/records/%s", resultName, uid)
+
+	// the kind and the apiVersion are usually empty on the objects of the
+	// informers and needed to read the record back
+	stored := pr.DeepCopy()
+	stored.APIVersion = tektonv1.SchemeGroupVersion.String()
+	stored.Kind = "PipelineRun"
+	stored.ManagedFields = nil
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	if err := c.post(ctx, fmt.Sprintf("/parents/%s/results", parent), result{
+		Name:    resultName,
+		Summary: recordSummary{Record: recordName, Type: PipelineRunType, Status: recordStatus(pr)},
+	}); err != nil {
+		return fmt.Errorf("cannot create the result of %s: %w", pr.GetName(), err)
+	}
+	if err := c.post(ctx, fmt.Sprintf("/parents/%s/results/%s/records", parent, uid), record{
+		Name: recordName,
+		Data: recordData{Type: PipelineRunType, Value: value},
+	}); err != nil {
+		return fmt.Errorf("cannot create the record of %s: %w", pr.GetName(), err)
+	}
+	return nil
+}
+
+// GetPipelineRun returns the last PipelineRun stored in Tekton Results with
+// this name in the namespace.
+func (c *Client) GetPipelineRun(ctx context.Context, namespace, name string) (*tektonv1.PipelineRun, error) {
+	query := url.Values{}
+	query.Set("filter", fmt.Sprintf(`data_type == %q && data.metadata.name == %q`, PipelineRunType, name))
+	query.Set("order_by", "create_time desc")
+	query.Set("page_size", "1")
+	req, err := c.newRequest(ctx, http.MethodGet, fmt.Sprintf("/parents/%s/results/-/records?%s", namespace, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	response := listRecordsResponse{}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("cannot parse the records of Tekton Results: %w", err)
+	}
+	if len(response.Records) == 0 {
+		return nil, ErrNotFound
+	}
+	pr := &tektonv1.PipelineRun{}
+	if err := json.Unmarshal(response.Records[0].Data.Value, pr); err != nil {
+		return nil, fmt.Errorf("cannot parse the record %s: %w", response.Records[0].Name, err)
+	}
+	return pr, nil
+}
+
+// ListPipelineRuns returns the last PipelineRuns stored in Tekton Results in
+// the namespace with these labels and annotations, the most recent first and
+// at most limit of them.
+func (c *Client) ListPipelineRuns(ctx context.Context, namespace string, labels, annotations map[string]string, limit int) ([]*tektonv1.PipelineRun, error) {
+	filters := []string{fmt.Sprintf("data_type == %q", PipelineRunType)}
+	for _, key := range sortedKeys(labels) {
+		filters = append(filters, fmt.Sprintf("data.metadata.labels[%q] == %q", key, labels[key]))
+	}
+	for _, key := range sortedKeys(annotations) {
+		filters = append(filters, fmt.Sprintf("data.metadata.annotations[%q] == %q", key, annotations[key]))
+	}
+	query := url.Values{}
+	query.Set("filter", strings.Join(filters, " && "))
+	query.Set("order_by", "create_time desc")
+	query.Set("page_size", strconv.Itoa(limit))
+	req, err := c.newRequest(ctx, http.MethodGet, fmt.Sprintf("/parents/%s/results/-/records?%s", namespace, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := listRecordsResponse{}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("cannot parse the records of Tekton Results: %w", err)
+	}
+	prs := make([]*tektonv1.PipelineRun, 0, len(response.Records))
+	for _, record := range response.Records {
+		pr := &tektonv1.PipelineRun{}
+		if err := json.Unmarshal(record.Data.Value, pr); err != nil {
+			return nil, fmt.Errorf("cannot parse the record %s: %w", record.Name, err)
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+apiPath+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return req, nil
+}
+
+// post creates an object, an object already existing is not an error.
+func (c *Client) post(ctx context.Context, path string, object any) error {
+	body, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	_, err = c.do(req)
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.code == http.StatusConflict {
+		return nil
+	}
+	return err
+}
+
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("tekton results answered with the status %d: %s", e.code, e.body)
+}
+
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(data))}
+	}
+	return data, nil
+}
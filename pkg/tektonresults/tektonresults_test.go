@@ -0,0 +1,167 @@
+package tektonresults
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func makePipelineRun(status corev1.ConditionStatus, reason string) *tektonv1.PipelineRun {
+	return &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pr-abcde",
+			Namespace:   "ns",
+			UID:         "1234",
+			Annotations: map[string]string{"pipelinesascode.tekton.dev/log-url": "https://console/pr-abcde"},
+		},
+		Status: tektonv1.PipelineRunStatus{Status: duckv1.Status{Conditions: duckv1.Conditions{
+			{Type: apis.ConditionSucceeded, Status: status, Reason: reason},
+		}}},
+	}
+}
+
+func TestRecordStatus(t *testing.T) {
+	assert.Equal(t, recordStatus(makePipelineRun(corev1.ConditionTrue, "Succeeded")), "SUCCESS")
+	assert.Equal(t, recordStatus(makePipelineRun(corev1.ConditionFalse, "Failed")), "FAILURE")
+	assert.Equal(t, recordStatus(makePipelineRun(corev1.ConditionFalse, "PipelineRunTimeout")), "TIMEOUT")
+	assert.Equal(t, recordStatus(makePipelineRun(corev1.ConditionFalse, "Cancelled")), "CANCELLED")
+	assert.Equal(t, recordStatus(&tektonv1.PipelineRun{}), "UNKNOWN")
+}
+
+func TestStoreRecord(t *testing.T) {
+	tests := []struct {
+		name         string
+		stored       bool
+		resultStatus int
+		recordStatus int
+		wantErr      string
+		wantCalls    int
+	}{
+		{
+			name:         "stored",
+			resultStatus: http.StatusOK,
+			recordStatus: http.StatusOK,
+			wantCalls:    2,
+		},
+		{
+			name:         "result already existing",
+			resultStatus: http.StatusConflict,
+			recordStatus: http.StatusOK,
+			wantCalls:    2,
+		},
+		{
+			name:      "already stored by the watcher",
+			stored:    true,
+			wantCalls: 0,
+		},
+		{
+			name:         "error",
+			resultStatus: http.StatusOK,
+			recordStatus: http.StatusForbidden,
+			wantErr:      "cannot create the record of pr-abcde: tekton results answered with the status 403: denied",
+			wantCalls:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			mux := http.NewServeMux()
+			mux.HandleFunc("/apis/results.tekton.dev/v1alpha2/parents/ns/results", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				assert.Equal(t, r.Method, http.MethodPost)
+				assert.Equal(t, r.Header.Get("Authorization"), "Bearer token")
+				got := result{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&got))
+				assert.Equal(t, got.Name, "ns/results/1234")
+				assert.Equal(t, got.Summary.Record, "ns/results/1234/records/1234")
+				assert.Equal(t, got.Summary.Status, "SUCCESS")
+				w.WriteHeader(tt.resultStatus)
+			})
+			mux.HandleFunc("/apis/results.tekton.dev/v1alpha2/parents/ns/results/1234/records", func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				got := record{}
+				assert.NilError(t, json.NewDecoder(r.Body).Decode(&got))
+				assert.Equal(t, got.Data.Type, PipelineRunType)
+				pr := &tektonv1.PipelineRun{}
+				assert.NilError(t, json.Unmarshal(got.Data.Value, pr))
+				assert.Equal(t, pr.Kind, "PipelineRun")
+				assert.Equal(t, pr.GetAnnotations()["pipelinesascode.tekton.dev/log-url"], "https://console/pr-abcde")
+				w.WriteHeader(tt.recordStatus)
+				_, _ = w.Write([]byte("denied"))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			pr := makePipelineRun(corev1.ConditionTrue, "Succeeded")
+			if tt.stored {
+				pr.Annotations[RecordAnnotation] = "ns/results/1234/records/1234"
+			}
+			client := NewClient(server.URL+"/", server.Client())
+			client.Token = "token"
+			err := client.StoreRecord(context.Background(), pr)
+			if tt.wantErr != "" {
+				assert.Error(t, err, tt.wantErr)
+			} else {
+				assert.NilError(t, err)
+			}
+			assert.Equal(t, calls, tt.wantCalls)
+		})
+	}
+}
+
+func TestGetPipelineRun(t *testing.T) {
+	value, err := json.Marshal(makePipelineRun(corev1.ConditionTrue, "Succeeded"))
+	assert.NilError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/apis/results.tekton.dev/v1alpha2/parents/ns/results/-/records")
+		if r.URL.Query().Get("filter") != `data_type == "tekton.dev/v1.PipelineRun" && data.metadata.name == "pr-abcde"` {
+			_, _ = w.Write([]byte(`{"records": []}`))
+			return
+		}
+		assert.NilError(t, json.NewEncoder(w).Encode(listRecordsResponse{Records: []record{
+			{Name: "ns/results/1234/records/1234", Data: recordData{Type: PipelineRunType, Value: value}},
+		}}))
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL, HTTP: server.Client()}
+	pr, err := client.GetPipelineRun(context.Background(), "ns", "pr-abcde")
+	assert.NilError(t, err)
+	assert.Equal(t, pr.GetName(), "pr-abcde")
+	assert.Assert(t, pr.Status.GetCondition(apis.ConditionSucceeded).IsTrue())
+
+	_, err = client.GetPipelineRun(context.Background(), "ns", "pr-missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestListPipelineRuns(t *testing.T) {
+	value, err := json.Marshal(makePipelineRun(corev1.ConditionTrue, "Succeeded"))
+	assert.NilError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/apis/results.tekton.dev/v1alpha2/parents/ns/results/-/records")
+		assert.Equal(t, r.URL.Query().Get("filter"), `data_type == "tekton.dev/v1.PipelineRun" && `+
+			`data.metadata.labels["a"] == "1" && data.metadata.labels["b"] == "2" && `+
+			`data.metadata.annotations["branch"] == "main"`)
+		assert.Equal(t, r.URL.Query().Get("order_by"), "create_time desc")
+		assert.Equal(t, r.URL.Query().Get("page_size"), "5")
+		assert.NilError(t, json.NewEncoder(w).Encode(listRecordsResponse{Records: []record{
+			{Name: "ns/results/1234/records/1234", Data: recordData{Type: PipelineRunType, Value: value}},
+		}}))
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL, HTTP: server.Client()}
+	prs, err := client.ListPipelineRuns(context.Background(), "ns", map[string]string{"b": "2", "a": "1"}, map[string]string{"branch": "main"}, 5)
+	assert.NilError(t, err)
+	assert.Equal(t, len(prs), 1)
+	assert.Equal(t, prs[0].GetName(), "pr-abcde")
+}
@@ -18,6 +18,16 @@ type KinterfaceTest struct {
 	ExpectedNumberofCleanups int
 	GetSecretResult          map[string]string
 	GetPodLogsOutput         map[string]string
+	// SyncedRegistrySecrets records the registry secrets synced by namespace/serviceaccount
+	SyncedRegistrySecrets map[string][]string
+	// ServiceAccountTokens are the tokens returned by namespace/serviceaccount
+	ServiceAccountTokens map[string]string
+	// CreatedSecrets records the secrets created by namespace/name
+	CreatedSecrets map[string]*corev1.Secret
+	// DeletedSecrets records the secrets deleted as namespace/name
+	DeletedSecrets []string
+	// OwnedSecrets records the PipelineRun owning the secrets by namespace/name
+	OwnedSecrets map[string]string
 }
 
 var _ kubeinteraction.Interface = (*KinterfaceTest)(nil)
@@ -36,7 +46,11 @@ func (k *KinterfaceTest) GetPodLogs(_ context.Context, _, pod, _ string, _ int64
 	return "", nil
 }
 
-func (k *KinterfaceTest) UpdateSecretWithOwnerRef(_ context.Context, _ *zap.SugaredLogger, _, _ string, _ *tektonv1.PipelineRun) error {
+func (k *KinterfaceTest) UpdateSecretWithOwnerRef(_ context.Context, _ *zap.SugaredLogger, ns, name string, pr *tektonv1.PipelineRun) error {
+	if k.OwnedSecrets == nil {
+		k.OwnedSecrets = map[string]string{}
+	}
+	k.OwnedSecrets[ns+"/"+name] = pr.GetName()
 	return nil
 }
 
@@ -56,10 +70,31 @@ func (k *KinterfaceTest) CleanupPipelines(_ context.Context, _ *zap.SugaredLogge
 	return nil
 }
 
-func (k *KinterfaceTest) CreateSecret(_ context.Context, _ string, _ *corev1.Secret) error {
+func (k *KinterfaceTest) CreateSecret(_ context.Context, ns string, secret *corev1.Secret) error {
+	if k.CreatedSecrets == nil {
+		k.CreatedSecrets = map[string]*corev1.Secret{}
+	}
+	k.CreatedSecrets[ns+"/"+secret.GetName()] = secret
 	return nil
 }
 
-func (k *KinterfaceTest) DeleteSecret(_ context.Context, _ *zap.SugaredLogger, _, _ string) error {
+func (k *KinterfaceTest) SyncRegistrySecrets(_ context.Context, _ *zap.SugaredLogger, _, targetNS, serviceAccount string, names []string) error {
+	if k.SyncedRegistrySecrets == nil {
+		k.SyncedRegistrySecrets = map[string][]string{}
+	}
+	k.SyncedRegistrySecrets[targetNS+"/"+serviceAccount] = names
 	return nil
 }
+
+func (k *KinterfaceTest) DeleteSecret(_ context.Context, _ *zap.SugaredLogger, ns, name string) error {
+	k.DeletedSecrets = append(k.DeletedSecrets, ns+"/"+name)
+	return nil
+}
+
+func (k *KinterfaceTest) CreateServiceAccountToken(_ context.Context, ns, serviceAccount, _ string, _ int64) (string, error) {
+	token, ok := k.ServiceAccountTokens[ns+"/"+serviceAccount]
+	if !ok {
+		return "", fmt.Errorf("service account %s/%s does not exist", ns, serviceAccount)
+	}
+	return token, nil
+}
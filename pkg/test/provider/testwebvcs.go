@@ -25,18 +25,29 @@ type TestProviderImp struct {
 	WantProviderRemoteTask bool
 	PolicyDisallowing      bool
 	AllowedInOwnersFile    bool
+	SenderRoleReply        info.Role
 	WantAllChangedFiles    []string
 	WantAddedFiles         []string
 	WantDeletedFiles       []string
 	WantModifiedFiles      []string
 	WantRenamedFiles       []string
-	pacInfo                *info.PacOpts
+	GetFilesErroring       bool
+	// CreatedStatuses records the statuses created
+	CreatedStatuses []provider.StatusOpts
+	pacInfo         *info.PacOpts
 }
 
 func (v *TestProviderImp) SetPacInfo(pacInfo *info.PacOpts) {
 	v.pacInfo = pacInfo
 }
 
+func (v *TestProviderImp) SenderRole(_ context.Context, _ *info.Event) (info.Role, error) {
+	if v.SenderRoleReply == "" {
+		return info.RoleNone, nil
+	}
+	return v.SenderRoleReply, nil
+}
+
 func (v *TestProviderImp) CheckPolicyAllowing(_ context.Context, _ *info.Event, _ []string) (bool, string) {
 	if v.PolicyDisallowing {
 		return false, "policy disallowing"
@@ -86,10 +97,11 @@ func (v *TestProviderImp) GetTaskURI(_ context.Context, _ *info.Event, _ string)
 	return v.WantProviderRemoteTask, "", nil
 }
 
-func (v *TestProviderImp) CreateStatus(_ context.Context, _ *info.Event, _ provider.StatusOpts) error {
+func (v *TestProviderImp) CreateStatus(_ context.Context, _ *info.Event, opts provider.StatusOpts) error {
 	if v.CreateStatusErorring {
 		return fmt.Errorf("some provider error occurred while reporting status")
 	}
+	v.CreatedStatuses = append(v.CreatedStatuses, opts)
 	return nil
 }
 
@@ -104,10 +116,17 @@ func (v *TestProviderImp) GetFileInsideRepo(_ context.Context, _ *info.Event, fi
 	return "", fmt.Errorf("could not find %s in tests", file)
 }
 
+func (v *TestProviderImp) GetFileAtRevision(ctx context.Context, event *info.Event, file, _ string) (string, error) {
+	return v.GetFileInsideRepo(ctx, event, file, "")
+}
+
 func (v *TestProviderImp) GetFiles(_ context.Context, _ *info.Event) (changedfiles.ChangedFiles, error) {
 	if v == nil {
 		return changedfiles.ChangedFiles{}, nil
 	}
+	if v.GetFilesErroring {
+		return changedfiles.ChangedFiles{}, fmt.Errorf("cannot get the changed files")
+	}
 	return changedfiles.ChangedFiles{
 		All:      v.WantAllChangedFiles,
 		Added:    v.WantAddedFiles,
@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1beta1"
+	"go.uber.org/zap"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	secretinformer "knative.dev/pkg/injection/clients/namespacedkube/informers/core/v1/secret"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+	"knative.dev/pkg/webhook"
+	certresources "knative.dev/pkg/webhook/certificates/resources"
+)
+
+// RepositoryCRDName is the name of the CustomResourceDefinition of the
+// Repositories, its conversion webhook is served by the conversion controller.
+const RepositoryCRDName = "repositories.pipelinesascode.tekton.dev"
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+type conversionReconciler struct {
+	pkgreconciler.LeaderAwareFuncs
+
+	key        types.NamespacedName
+	path       string
+	secretName string
+
+	secretlister corelisters.SecretLister
+	dynamic      dynamic.Interface
+}
+
+var (
+	_ controller.Reconciler        = (*conversionReconciler)(nil)
+	_ pkgreconciler.LeaderAware    = (*conversionReconciler)(nil)
+	_ webhook.ConversionController = (*conversionReconciler)(nil)
+)
+
+// NewConversionController constructs the controller converting the
+// Repositories between their served versions, it keeps the CA bundle of the
+// conversion webhook of the CustomResourceDefinition up to date.
+func NewConversionController(ctx context.Context, path string) *controller.Impl {
+	secretInformer := secretinformer.Get(ctx)
+	options := webhook.GetOptions(ctx)
+	key := types.NamespacedName{Name: RepositoryCRDName}
+
+	cr := &conversionReconciler{
+		LeaderAwareFuncs: pkgreconciler.LeaderAwareFuncs{
+			PromoteFunc: func(bkt pkgreconciler.Bucket, enq func(pkgreconciler.Bucket, types.NamespacedName)) error {
+				enq(bkt, key)
+				return nil
+			},
+		},
+		key:          key,
+		path:         path,
+		secretName:   options.SecretName,
+		secretlister: secretInformer.Lister(),
+		dynamic:      dynamic.NewForConfigOrDie(injection.GetConfig(ctx)),
+	}
+
+	logger := logging.FromContext(ctx)
+	c := controller.NewContext(ctx, cr, controller.ControllerOptions{WorkQueueName: "ConversionWebhook", Logger: logger.Named("ConversionWebhook")})
+
+	// Reconcile when the cert bundle changes.
+	if _, err := secretInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterWithNameAndNamespace(system.Namespace(), cr.secretName),
+		Handler:    controller.HandleAll(c.Enqueue),
+	}); err != nil {
+		logger.Panicf("Couldn't register Secret informer event handler: %w", err)
+	}
+	return c
+}
+
+// Path implements webhook.ConversionController.
+func (cr *conversionReconciler) Path() string {
+	return cr.path
+}
+
+// Reconcile implements controller.Reconciler, it sets the CA bundle of the
+// conversion webhook in the CustomResourceDefinition.
+func (cr *conversionReconciler) Reconcile(ctx context.Context, _ string) error {
+	logger := logging.FromContext(ctx)
+	if !cr.IsLeaderFor(cr.key) {
+		logger.Debugf("Skipping key %q, not the leader.", cr.key)
+		return nil
+	}
+
+	secret, err := cr.secretlister.Secrets(system.Namespace()).Get(cr.secretName)
+	if err != nil {
+		logger.Errorw("Error fetching secret", zap.Error(err))
+		return err
+	}
+	caCert, ok := secret.Data[certresources.CACert]
+	if !ok {
+		return fmt.Errorf("secret %q is missing %q key", cr.secretName, certresources.CACert)
+	}
+
+	crds := cr.dynamic.Resource(crdGVR)
+	crd, err := crds.Get(ctx, RepositoryCRDName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error retrieving the %s crd: %w", RepositoryCRDName, err)
+	}
+	current, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	currentPath, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service", "path")
+	caBundle := base64.StdEncoding.EncodeToString(caCert)
+	if current == caBundle && currentPath == cr.path {
+		logger.Info("Conversion webhook is valid")
+		return nil
+	}
+	if _, found, _ := unstructured.NestedMap(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service"); !found {
+		return fmt.Errorf("missing service reference for the conversion webhook of the %s crd", RepositoryCRDName)
+	}
+	if err := unstructured.SetNestedField(crd.Object, caBundle, "spec", "conversion", "webhook", "clientConfig", "caBundle"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(crd.Object, cr.path, "spec", "conversion", "webhook", "clientConfig", "service", "path"); err != nil {
+		return err
+	}
+	logger.Info("Updating conversion webhook")
+	if _, err := crds.Update(ctx, crd, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update the conversion webhook: %w", err)
+	}
+	return nil
+}
+
+// Convert implements webhook.ConversionController, converting the
+// Repositories to the desired version through v1alpha1, the storage version.
+func (cr *conversionReconciler) Convert(ctx context.Context, req *apixv1.ConversionRequest) *apixv1.ConversionResponse {
+	response := &apixv1.ConversionResponse{UID: req.UID}
+	for _, obj := range req.Objects {
+		converted, err := ConvertRepository(ctx, obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			logging.FromContext(ctx).Errorf("cannot convert the repository to %s: %v", req.DesiredAPIVersion, err)
+			response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			return response
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	response.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return response
+}
+
+// ConvertRepository converts the JSON of a Repository to the desired
+// apiVersion.
+func ConvertRepository(ctx context.Context, raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	hub := &v1alpha1.Repository{}
+	switch typeMeta.APIVersion {
+	case v1alpha1.SchemeGroupVersion.String():
+		if err := json.Unmarshal(raw, hub); err != nil {
+			return nil, err
+		}
+	case v1beta1.SchemeGroupVersion.String():
+		source := &v1beta1.Repository{}
+		if err := json.Unmarshal(raw, source); err != nil {
+			return nil, err
+		}
+		if err := source.ConvertTo(ctx, hub); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown apiVersion %q", typeMeta.APIVersion)
+	}
+
+	var sink runtime.Object
+	switch desiredAPIVersion {
+	case v1alpha1.SchemeGroupVersion.String():
+		sink = hub
+	case v1beta1.SchemeGroupVersion.String():
+		beta := &v1beta1.Repository{}
+		if err := beta.ConvertFrom(ctx, hub); err != nil {
+			return nil, err
+		}
+		sink = beta
+	default:
+		return nil, fmt.Errorf("unknown desired apiVersion %q", desiredAPIVersion)
+	}
+	sink.GetObjectKind().SetGroupVersionKind(schema.FromAPIVersionAndKind(desiredAPIVersion, typeMeta.Kind))
+	return json.Marshal(sink)
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1beta1"
+	"gotest.tools/v3/assert"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+	alpha := `{"apiVersion": "pipelinesascode.tekton.dev/v1alpha1", "kind": "Repository",
+		"metadata": {"name": "repo", "namespace": "ns"},
+		"spec": {"url": "https://github.com/owner/repo", "concurrency_limit": 2,
+			"settings": {"queue_weight": 3, "policy": {"ok_to_test": ["admins"]}, "pipelinerun_dirs": [".ci"]}}}`
+	cr := &conversionReconciler{}
+
+	response := cr.Convert(ctx, &apixv1.ConversionRequest{
+		UID:               "uid",
+		DesiredAPIVersion: v1beta1.SchemeGroupVersion.String(),
+		Objects:           []runtime.RawExtension{{Raw: []byte(alpha)}},
+	})
+	assert.Equal(t, response.Result.Status, metav1.StatusSuccess, response.Result.Message)
+	assert.Equal(t, string(response.UID), "uid")
+	assert.Equal(t, len(response.ConvertedObjects), 1)
+	beta := &v1beta1.Repository{}
+	assert.NilError(t, json.Unmarshal(response.ConvertedObjects[0].Raw, beta))
+	assert.Equal(t, beta.APIVersion, "pipelinesascode.tekton.dev/v1beta1")
+	assert.Equal(t, beta.Kind, "Repository")
+	assert.Equal(t, beta.GetName(), "repo")
+	assert.Equal(t, *beta.Spec.Concurrency.Limit, 2)
+	assert.Equal(t, beta.Spec.Concurrency.QueueWeight, 3)
+	assert.DeepEqual(t, beta.Spec.Policies.OkToTest, []string{"admins"})
+	assert.DeepEqual(t, beta.Spec.Settings.PipelineRunDirs, []string{".ci"})
+
+	// and back to the storage version
+	raw, err := ConvertRepository(ctx, response.ConvertedObjects[0].Raw, v1alpha1.SchemeGroupVersion.String())
+	assert.NilError(t, err)
+	back := &v1alpha1.Repository{}
+	assert.NilError(t, json.Unmarshal(raw, back))
+	assert.Equal(t, back.APIVersion, "pipelinesascode.tekton.dev/v1alpha1")
+	assert.Equal(t, *back.Spec.ConcurrencyLimit, 2)
+	assert.Equal(t, back.Spec.Settings.QueueWeight, 3)
+	assert.DeepEqual(t, back.Spec.Settings.Policy.OkToTest, []string{"admins"})
+
+	// the same version is not converted
+	raw, err = ConvertRepository(ctx, []byte(alpha), v1alpha1.SchemeGroupVersion.String())
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), alpha)
+
+	response = cr.Convert(ctx, &apixv1.ConversionRequest{
+		DesiredAPIVersion: "pipelinesascode.tekton.dev/v2",
+		Objects:           []runtime.RawExtension{{Raw: []byte(alpha)}},
+	})
+	assert.Equal(t, response.Result.Status, metav1.StatusFailure)
+	assert.Assert(t, strings.Contains(response.Result.Message, "unknown desired apiVersion"), response.Result.Message)
+}
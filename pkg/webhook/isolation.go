@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+)
+
+// checkNamespaceIsolation makes sure the namespace isolation policy set in the
+// Pipelines-as-Code configmap allows the Repository namespace to claim its url.
+func (ac *reconciler) checkNamespaceIsolation(ctx context.Context, repo *v1alpha1.Repository) error {
+	data, err := ac.getConfigMapData(ctx)
+	if err != nil {
+		return err
+	}
+	policy, err := settings.ParseNamespaceIsolationPolicy(data["namespace-isolation-policy"])
+	if err != nil {
+		return err
+	}
+	if !policy.Allowed(repo.Spec.URL, repo.Namespace) {
+		return fmt.Errorf("namespace %s is not allowed to claim the repository url %s by the namespace isolation policy", repo.Namespace, repo.Spec.URL)
+	}
+	return nil
+}
+
+// getConfigMapData returns the data of the Pipelines-as-Code configmap, empty
+// when there is no configmap.
+func (ac *reconciler) getConfigMapData(ctx context.Context) (map[string]string, error) {
+	if ac.client == nil {
+		return nil, nil
+	}
+	cmName := info.GetControllerInfoFromEnvOrDefault().Configmap
+	cm, err := ac.client.CoreV1().ConfigMaps(system.Namespace()).Get(ctx, cmName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the %s configmap: %w", cmName, err)
+	}
+	return cm.Data, nil
+}
@@ -3,6 +3,7 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode"
 	pac "github.com/openshift-pipelines/pipelines-as-code/pkg/generated/listers/pipelinesascode/v1alpha1"
@@ -40,6 +41,8 @@ type reconciler struct {
 	secretName            string
 
 	pacLister pac.RepositoryLister
+
+	httpClient *http.Client
 }
 
 var (
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/pipelineascode"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// secretWarning is returned when a secret referenced by the git_provider
+// section does not exist yet, the secret and the Repository are often applied
+// together and the secret may land after the Repository.
+const secretWarning = "git_provider %s secret %s does not exist in namespace %s"
+
+// checkGitProviderSecrets makes sure the secrets referenced by the
+// git_provider section have the key the controller is going to read. A missing
+// secret is only a warning, a missing key in an existing secret is an error.
+func (ac *reconciler) checkGitProviderSecrets(ctx context.Context, repo *v1alpha1.Repository) ([]string, error) {
+	if ac.client == nil || repo.Spec.GitProvider == nil {
+		return nil, nil
+	}
+
+	warnings := []string{}
+	for _, ref := range []struct {
+		name       string
+		secret     *v1alpha1.Secret
+		defaultKey string
+	}{
+		{"token", repo.Spec.GitProvider.Secret, pipelineascode.DefaultGitProviderSecretKey},
+		{"webhook_secret", repo.Spec.GitProvider.WebhookSecret, pipelineascode.DefaultGitProviderWebhookSecretKey},
+	} {
+		name := ref.name
+		if ref.secret == nil || ref.secret.Name == "" {
+			continue
+		}
+		key := ref.secret.Key
+		if key == "" {
+			key = ref.defaultKey
+		}
+		secret, err := ac.client.CoreV1().Secrets(repo.Namespace).Get(ctx, ref.secret.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			warnings = append(warnings, fmt.Sprintf(secretWarning, name, ref.secret.Name, repo.Namespace))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot get git_provider %s secret %s: %w", name, ref.secret.Name, err)
+		}
+		if _, ok := secret.Data[key]; !ok {
+			return nil, fmt.Errorf("git_provider %s secret %s has no key %s", name, ref.secret.Name, key)
+		}
+	}
+	return warnings, nil
+}
+
+// getGitProviderToken returns the token referenced by the git_provider section.
+func (ac *reconciler) getGitProviderToken(ctx context.Context, repo *v1alpha1.Repository) (string, error) {
+	ref := repo.Spec.GitProvider.Secret
+	key := ref.Key
+	if key == "" {
+		key = pipelineascode.DefaultGitProviderSecretKey
+	}
+	secret, err := ac.client.CoreV1().Secrets(repo.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data[key]), nil
+}
@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/httpclient"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/settings"
+	"knative.dev/pkg/logging"
+)
+
+// tokenCheckTimeout keeps the live token check well below the admission
+// webhook timeout.
+const tokenCheckTimeout = 5 * time.Second
+
+// tokenCheck describes how to validate a token against a provider API.
+type tokenCheck struct {
+	defaultAPIURL string
+	path          string
+	header        func(token string) (string, string)
+	// scopes returns an error when the token does not have the scopes needed
+	// by Pipelines-as-Code.
+	scopes func(res *http.Response) error
+}
+
+var tokenChecks = map[string]tokenCheck{
+	"github": {
+		defaultAPIURL: keys.PublicGithubAPIURL,
+		path:          "/user",
+		header:        func(token string) (string, string) { return "Authorization", "Bearer " + token },
+		scopes: func(res *http.Response) error {
+			// only classic personal access tokens report their scopes
+			header := res.Header.Get("X-OAuth-Scopes")
+			if header == "" {
+				return nil
+			}
+			for _, scope := range strings.Split(header, ",") {
+				if s := strings.TrimSpace(scope); s == "repo" || s == "public_repo" {
+					return nil
+				}
+			}
+			return fmt.Errorf("token is missing the repo scope, it has: %s", header)
+		},
+	},
+	"gitlab": {
+		defaultAPIURL: "https://gitlab.com",
+		path:          "/api/v4/personal_access_tokens/self",
+		header:        func(token string) (string, string) { return "PRIVATE-TOKEN", token },
+		scopes: func(res *http.Response) error {
+			pat := struct {
+				Scopes []string `json:"scopes"`
+			}{}
+			if err := json.NewDecoder(res.Body).Decode(&pat); err != nil {
+				return fmt.Errorf("cannot decode token information: %w", err)
+			}
+			if !slices.Contains(pat.Scopes, "api") {
+				return fmt.Errorf("token is missing the api scope, it has: %s", strings.Join(pat.Scopes, ","))
+			}
+			return nil
+		},
+	},
+	"gitea": {
+		path:   "/api/v1/user",
+		header: func(token string) (string, string) { return "Authorization", "token " + token },
+		scopes: func(_ *http.Response) error { return nil },
+	},
+}
+
+// validateTokenRequested returns true when the Repository asks the webhook
+// to check its git_provider token against the provider API.
+func validateTokenRequested(repo *v1alpha1.Repository) bool {
+	return repo.GetAnnotations()[keys.ValidateToken] == "true" &&
+		repo.Spec.GitProvider != nil && repo.Spec.GitProvider.Secret != nil && repo.Spec.GitProvider.Secret.Name != ""
+}
+
+// providerType returns the git_provider type or guess it from the well known
+// public hosts.
+func providerType(repo *v1alpha1.Repository) string {
+	if repo.Spec.GitProvider.Type != "" {
+		return repo.Spec.GitProvider.Type
+	}
+	u, err := url.Parse(repo.Spec.URL)
+	if err != nil {
+		return ""
+	}
+	switch u.Host {
+	case "github.com":
+		return "github"
+	case "gitlab.com":
+		return "gitlab"
+	}
+	return ""
+}
+
+// mountedCABundles returns the CA bundles of the setting mounted in the
+// webhook, the bundles are often only mounted in the controller and the
+// watcher and the hosts of the others are checked with the system
+// certificates.
+func mountedCABundles(value string) string {
+	bundles, err := settings.ParseCABundles(value)
+	if err != nil {
+		return ""
+	}
+	mounted := []string{}
+	for host, bundle := range bundles {
+		if _, err := os.Stat(bundle); err == nil {
+			mounted = append(mounted, host+"="+bundle)
+		}
+	}
+	sort.Strings(mounted)
+	return strings.Join(mounted, ",")
+}
+
+// checkToken does a live request with the git_provider token to the provider
+// API. An invalid token or a token without the needed scopes is an error, an
+// unreachable provider is only a warning.
+func (ac *reconciler) checkToken(ctx context.Context, repo *v1alpha1.Repository, token string) ([]string, error) {
+	ptype := providerType(repo)
+	check, ok := tokenChecks[ptype]
+	if !ok {
+		return []string{fmt.Sprintf("cannot validate the git_provider token of provider type %q", ptype)}, nil
+	}
+
+	apiURL := repo.Spec.GitProvider.URL
+	if apiURL == "" {
+		apiURL = check.defaultAPIURL
+	}
+	if apiURL == "" {
+		u, err := url.Parse(repo.Spec.URL)
+		if err != nil {
+			return nil, err
+		}
+		apiURL = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+	}
+	apiURL = strings.TrimSuffix(strings.TrimSuffix(apiURL, "/"), "/api/v4")
+
+	// the token check goes through the proxy, trusts the ca bundles and
+	// follows the egress allowlist of the configmap like the controller, and
+	// only sends the token to the hosts trusted by the admin
+	data, err := ac.getConfigMapData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := settings.DefaultSettings()
+	if err := settings.SyncConfig(logging.FromContext(ctx), &s, data); err != nil {
+		return []string{fmt.Sprintf("cannot validate the git_provider token, invalid settings: %v", err)}, nil
+	}
+	s.CABundles = mountedCABundles(s.CABundles)
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	if !s.TokenValidationAllowed(u.Host) {
+		return []string{fmt.Sprintf("the git_provider token is not validated on %s, its host is not in the token-validation-hosts setting", apiURL)}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tokenCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+check.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(check.header(token))
+
+	client := ac.httpClient
+	if client == nil {
+		client = httpclient.NewClient(&s, logging.FromContext(ctx))
+	}
+	// don't follow the redirects, they could send the token to another host
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	client = &noRedirect
+	res, err := client.Do(req)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot reach %s to validate the git_provider token: %v", apiURL, err)}, nil
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("git_provider token has been rejected by %s: %s", apiURL, res.Status)
+	case res.StatusCode >= http.StatusMultipleChoices:
+		return []string{fmt.Sprintf("cannot validate the git_provider token on %s: %s", apiURL, res.Status)}, nil
+	}
+	if err := check.scopes(res); err != nil {
+		return nil, fmt.Errorf("git_provider %w", err)
+	}
+	return nil, nil
+}
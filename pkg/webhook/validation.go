@@ -3,9 +3,18 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/freeze"
 	pac "github.com/openshift-pipelines/pipelines-as-code/pkg/generated/listers/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/issuetracker"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/policy"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/provider"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/resolve"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/secrets"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/sync"
 	v1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -21,13 +30,17 @@ func (ac *reconciler) Path() string {
 }
 
 // Admit implements AdmissionController.
-func (ac *reconciler) Admit(_ context.Context, request *v1.AdmissionRequest) *v1.AdmissionResponse {
+func (ac *reconciler) Admit(ctx context.Context, request *v1.AdmissionRequest) *v1.AdmissionResponse {
 	raw := request.Object.Raw
 	repo := v1alpha1.Repository{}
 	if _, _, err := universalDeserializer.Decode(raw, nil, &repo); err != nil {
 		return webhook.MakeErrorStatus("validation failed: %v", err)
 	}
 
+	if err := validateURL(repo.Spec.URL); err != nil {
+		return webhook.MakeErrorStatus("invalid repository url: %v", err)
+	}
+
 	exist, err := checkIfRepoExist(ac.pacLister, &repo, "")
 	if err != nil {
 		return webhook.MakeErrorStatus("validation failed: %v", err)
@@ -37,11 +50,109 @@ func (ac *reconciler) Admit(_ context.Context, request *v1.AdmissionRequest) *v1
 		return webhook.MakeErrorStatus(fmt.Sprintf("repository already exist with url: %s", repo.Spec.URL))
 	}
 
-	if repo.Spec.ConcurrencyLimit != nil && *repo.Spec.ConcurrencyLimit == 0 {
+	if repo.Spec.ConcurrencyLimit != nil && *repo.Spec.ConcurrencyLimit < 1 {
 		return webhook.MakeErrorStatus("concurrency limit must be greater than 0")
 	}
 
-	return &v1.AdmissionResponse{Allowed: true}
+	if repo.Spec.Settings != nil && repo.Spec.Settings.StatusTemplates != nil {
+		for name, tmpl := range map[string]string{
+			"starting": repo.Spec.Settings.StatusTemplates.Starting,
+			"queued":   repo.Spec.Settings.StatusTemplates.Queued,
+			"finished": repo.Spec.Settings.StatusTemplates.Finished,
+		} {
+			if err := formatting.ValidateCustomTemplate(tmpl); err != nil {
+				return webhook.MakeErrorStatus(fmt.Sprintf("invalid %s status template: %v", name, err))
+			}
+		}
+	}
+
+	if err := formatting.ValidateProfile(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	if err := formatting.ValidateLocale(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	if err := provider.ValidatePipelineRunDirs(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	if err := resolve.ValidateMetadata(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	if err := freeze.Validate(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	if err := secrets.ValidateCloudCredentials(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	if err := provider.ValidateRebuildOnBaseUpdate(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	if err := issuetracker.Validate(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	if err := policy.Validate(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	if err := sync.ValidateQueuePreemption(repo.Spec.Settings); err != nil {
+		return webhook.MakeErrorStatus(err.Error())
+	}
+
+	// the controller updates the status on every run, only check the
+	// isolation policy, the secrets and the token when the Repository itself
+	// changes
+	if request.SubResource != "" {
+		return &v1.AdmissionResponse{Allowed: true}
+	}
+
+	if err := ac.checkNamespaceIsolation(ctx, &repo); err != nil {
+		return webhook.MakeErrorStatus("validation failed: %v", err)
+	}
+
+	warnings, err := ac.checkGitProviderSecrets(ctx, &repo)
+	if err != nil {
+		return webhook.MakeErrorStatus("validation failed: %v", err)
+	}
+
+	if len(warnings) == 0 && validateTokenRequested(&repo) {
+		token, err := ac.getGitProviderToken(ctx, &repo)
+		if err != nil {
+			return webhook.MakeErrorStatus("validation failed: %v", err)
+		}
+		tokenWarnings, err := ac.checkToken(ctx, &repo, token)
+		if err != nil {
+			return webhook.MakeErrorStatus("validation failed: %v", err)
+		}
+		warnings = append(warnings, tokenWarnings...)
+	}
+
+	return &v1.AdmissionResponse{Allowed: true, Warnings: warnings}
+}
+
+// validateURL makes sure the repository url is an absolute http(s) url.
+func validateURL(repoURL string) error {
+	if repoURL == "" {
+		return nil
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%s must use the http or https scheme", repoURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%s has no host", repoURL)
+	}
+	return nil
 }
 
 func checkIfRepoExist(pac pac.RepositoryLister, repo *v1alpha1.Repository, ns string) (bool, error) {
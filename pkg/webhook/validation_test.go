@@ -2,15 +2,27 @@ package webhook
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
 	testclient "github.com/openshift-pipelines/pipelines-as-code/pkg/test/clients"
 	testnewrepo "github.com/openshift-pipelines/pipelines-as-code/pkg/test/repository"
 	"gotest.tools/v3/assert"
 	v1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	rtesting "knative.dev/pkg/reconciler/testing"
+	"knative.dev/pkg/system"
 )
 
 func TestReconciler_Admit(t *testing.T) {
@@ -59,6 +71,253 @@ func TestReconciler_Admit(t *testing.T) {
 			allowed: false,
 			result:  "repository already exist with url: https://pac.test/already/installed",
 		},
+		{
+			name: "reject invalid status template",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					StatusTemplates: &v1alpha1.StatusTemplates{Finished: "{{ .Default "},
+				},
+			}),
+			allowed: false,
+			result:  "invalid finished status template: cannot parse custom template: template: Custom:1: unclosed action",
+		},
+		{
+			name: "allow valid status template",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					StatusTemplates: &v1alpha1.StatusTemplates{Finished: "{{ .Default }} [runbook](https://runbook/{{ .Mt.Namespace }})"},
+				},
+			}),
+			allowed: true,
+		},
+		{
+			name: "reject unknown formatting profile",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					Formatting: &v1alpha1.Formatting{Profile: "jira"},
+				},
+			}),
+			allowed: false,
+			result:  "unknown formatting profile jira, it should be one of: bitbucket-cloud, bitbucket-server, gitea, github, gitlab, plain",
+		},
+		{
+			name: "reject unknown locale",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					Locale: "klingon",
+				},
+			}),
+			allowed: false,
+			result:  "unknown locale klingon, it should be one of: en, fr",
+		},
+		{
+			name: "reject pipelinerun dir outside of the repository",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					PipelineRunDirs: []string{".ci", "../other"},
+				},
+			}),
+			allowed: false,
+			result:  `invalid pipelinerun_dirs "../other", it needs to be a directory relative to the root of the repository`,
+		},
+		{
+			name: "allow pipelinerun dirs and extensions",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					PipelineRunDirs:       []string{".ci", "ci/tekton"},
+					PipelineRunExtensions: []string{".yaml"},
+				},
+			}),
+			allowed: true,
+		},
+		{
+			name: "reject pipelinerun label with a reserved key",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					PipelineRunLabels: map[string]string{"pipelinesascode.tekton.dev/sender": "{{ sender }}"},
+				},
+			}),
+			allowed: false,
+			result:  `invalid pipelinerun_labels key "pipelinesascode.tekton.dev/sender": the pipelinesascode.tekton.dev keys are reserved`,
+		},
+		{
+			name: "allow pipelinerun labels and annotations",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					PipelineRunLabels:      map[string]string{"cost-center": "ci", "example.com/team": "{{ sender_team }}"},
+					PipelineRunAnnotations: map[string]string{"example.com/sender": "{{ sender }}"},
+				},
+			}),
+			allowed: true,
+		},
+		{
+			name: "reject freeze window with an invalid schedule",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					FreezeWindows: []v1alpha1.FreezeWindow{{Name: "weekend", Schedule: "0 18 * fri", Duration: "62h"}},
+				},
+			}),
+			allowed: false,
+			result:  `invalid freeze window weekend: invalid schedule "0 18 * fri", needs five fields: minute hour day-of-month month day-of-week`,
+		},
+		{
+			name: "reject rebuild on base update with an invalid batch interval",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					RebuildOnBaseUpdate: &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}, BatchInterval: "soon"},
+				},
+			}),
+			allowed: false,
+			result:  `invalid batch_interval "soon" of rebuild_on_base_update, it needs to be a duration like 30s`,
+		},
+		{
+			name: "reject rebuild on base update with a too long batch interval",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					RebuildOnBaseUpdate: &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}, BatchInterval: "1h"},
+				},
+			}),
+			allowed: false,
+			result:  `invalid batch_interval "1h" of rebuild_on_base_update, it needs to be at most 5m0s`,
+		},
+		{
+			name: "reject rebuild on base update with an invalid branch glob",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					RebuildOnBaseUpdate: &v1alpha1.RebuildOnBaseUpdate{PipelineRuns: []string{"e2e"}, Branches: []string{"release-[1"}},
+				},
+			}),
+			allowed: false,
+			result:  `invalid branch glob "release-[1" of rebuild_on_base_update: unexpected end of input`,
+		},
+		{
+			name: "reject an unknown role in the policy",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					Policy: &v1alpha1.Policy{OkToTest: []string{"ci-admins", "role:admin"}},
+				},
+			}),
+			allowed: false,
+			result:  `invalid role "admin" in the ok_to_test policy, it needs to be one of owner, maintainer, collaborator, author or none`,
+		},
+		{
+			name: "reject an invalid branch glob in the signed commits policy",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					Policy: &v1alpha1.Policy{RequireSignedCommits: []string{"main", "release-[1"}},
+				},
+			}),
+			allowed: false,
+			result:  `invalid branch glob "release-[1" of require_signed_commits: unexpected end of input`,
+		},
+		{
+			name: "reject cloud credentials with a plain http token url",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					CloudCredentials: &v1alpha1.CloudCredentials{TokenURL: "http://sts.example.com/token", Audience: "pool"},
+				},
+			}),
+			allowed: false,
+			result:  `the token_url "http://sts.example.com/token" of the cloud credentials must be an https url`,
+		},
+		{
+			name: "reject cloud credentials for the kubernetes api server",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					CloudCredentials: &v1alpha1.CloudCredentials{TokenURL: "https://sts.example.com/token", Audience: "https://kubernetes.default.svc"},
+				},
+			}),
+			allowed: false,
+			result:  `the audience "https://kubernetes.default.svc" of the cloud credentials is an audience of the Kubernetes API server`,
+		},
+		{
+			name: "reject an issue tracker with an invalid key pattern",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				Settings: &v1alpha1.Settings{
+					IssueTracker: &v1alpha1.IssueTracker{
+						Type: "jira", URL: "https://example.atlassian.net", Secret: v1alpha1.Secret{Name: "jira"}, KeyPattern: "PROJ-[0-9+",
+					},
+				},
+			}),
+			allowed: false,
+			result:  "invalid key_pattern of the issue tracker: error parsing regexp: missing closing ]: `[0-9+`",
+		},
+		{
+			name: "reject url without scheme",
+			repo: testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "github.com/openshift-pipelines/pipelines-as-code",
+			}),
+			allowed: false,
+			result:  "invalid repository url: github.com/openshift-pipelines/pipelines-as-code must use the http or https scheme",
+		},
+		{
+			name: "reject negative concurrency limit",
+			repo: func() *v1alpha1.Repository {
+				repo := testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+					Name:             "test-run",
+					InstallNamespace: "namespace",
+					URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				})
+				limit := -1
+				repo.Spec.ConcurrencyLimit = &limit
+				return repo
+			}(),
+			allowed: false,
+			result:  "concurrency limit must be greater than 0",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -88,3 +347,225 @@ func TestReconciler_Admit(t *testing.T) {
 		})
 	}
 }
+
+func TestReconciler_AdmitGitProvider(t *testing.T) {
+	tests := []struct {
+		name       string
+		secretData map[string][]byte
+		validate   bool
+		scopes     string
+		statusCode int
+		allowed    bool
+		result     string
+		warnings   []string
+		// untrustedHost leaves the test server out of token-validation-hosts
+		untrustedHost bool
+	}{
+		{
+			name:       "allow existing secret",
+			secretData: map[string][]byte{"provider.token": []byte("token")},
+			allowed:    true,
+		},
+		{
+			name:     "allow missing secret with a warning",
+			allowed:  true,
+			warnings: []string{"git_provider token secret repo-secret does not exist in namespace namespace"},
+		},
+		{
+			name:       "reject secret without key",
+			secretData: map[string][]byte{"other": []byte("token")},
+			allowed:    false,
+			result:     "validation failed: git_provider token secret repo-secret has no key provider.token",
+		},
+		{
+			name:       "allow valid token",
+			secretData: map[string][]byte{"provider.token": []byte("token")},
+			validate:   true,
+			scopes:     "repo, read:org",
+			statusCode: http.StatusOK,
+			allowed:    true,
+		},
+		{
+			name:       "reject token rejected by provider",
+			secretData: map[string][]byte{"provider.token": []byte("token")},
+			validate:   true,
+			statusCode: http.StatusUnauthorized,
+			allowed:    false,
+			result:     "validation failed: git_provider token has been rejected by %s: 401 Unauthorized",
+		},
+		{
+			name:       "reject token without repo scope",
+			secretData: map[string][]byte{"provider.token": []byte("token")},
+			validate:   true,
+			scopes:     "read:org",
+			statusCode: http.StatusOK,
+			allowed:    false,
+			result:     "validation failed: git_provider token is missing the repo scope, it has: read:org",
+		},
+		{
+			name:          "warn token of a host not trusted by the admin",
+			secretData:    map[string][]byte{"provider.token": []byte("token")},
+			validate:      true,
+			untrustedHost: true,
+			allowed:       true,
+			warnings:      []string{"the git_provider token is not validated on %s, its host is not in the token-validation-hosts setting"},
+		},
+		{
+			name:       "warn on a redirect",
+			secretData: map[string][]byte{"provider.token": []byte("token")},
+			validate:   true,
+			statusCode: http.StatusFound,
+			allowed:    true,
+			warnings:   []string{"cannot validate the git_provider token on %s: 302 Found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.URL.Path, "/user")
+				assert.Equal(t, r.Header.Get("Authorization"), "Bearer token")
+				if tt.scopes != "" {
+					w.Header().Set("X-OAuth-Scopes", tt.scopes)
+				}
+				if tt.statusCode == http.StatusFound {
+					w.Header().Set("Location", "http://attacker.example.com/")
+				}
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			tokenHosts := "127.0.0.1"
+			if tt.untrustedHost {
+				tokenHosts = "api.github.com"
+			}
+			tdata := testclient.Data{
+				ConfigMap: []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: "pipelines-as-code", Namespace: system.Namespace()},
+					Data:       map[string]string{"token-validation-hosts": tokenHosts},
+				}},
+			}
+			if tt.secretData != nil {
+				tdata.Secret = []*corev1.Secret{{
+					ObjectMeta: metav1.ObjectMeta{Name: "repo-secret", Namespace: "namespace"},
+					Data:       tt.secretData,
+				}}
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, tdata)
+
+			r := reconciler{
+				pacLister:  stdata.RepositoryLister,
+				client:     stdata.Kube,
+				httpClient: server.Client(),
+			}
+
+			repo := testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: "namespace",
+				URL:              "https://github.com/openshift-pipelines/pipelines-as-code",
+				SecretName:       "repo-secret",
+				ProviderURL:      server.URL,
+			})
+			repo.Spec.GitProvider.Type = "github"
+			if tt.validate {
+				repo.Annotations = map[string]string{keys.ValidateToken: "true"}
+			}
+
+			userRepo, err := json.Marshal(repo)
+			assert.NilError(t, err)
+			req := &v1.AdmissionRequest{Object: runtime.RawExtension{Raw: userRepo}}
+			res := r.Admit(ctx, req)
+
+			assert.Equal(t, res.Allowed, tt.allowed)
+			if !res.Allowed {
+				result := tt.result
+				if strings.Contains(result, "%s") {
+					result = fmt.Sprintf(result, server.URL)
+				}
+				assert.Equal(t, res.Result.Message, result)
+				return
+			}
+			warnings := []string{}
+			for _, warning := range tt.warnings {
+				if strings.Contains(warning, "%s") {
+					warning = fmt.Sprintf(warning, server.URL)
+				}
+				warnings = append(warnings, warning)
+			}
+			assert.DeepEqual(t, res.Warnings, warnings, cmpopts.EquateEmpty())
+		})
+	}
+}
+
+func TestReconciler_AdmitNamespaceIsolation(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		url       string
+		allowed   bool
+		result    string
+	}{
+		{
+			name:      "allow namespace matching the policy",
+			namespace: "team-a-ci",
+			url:       "https://github.com/team-a/app",
+			allowed:   true,
+		},
+		{
+			name:      "reject namespace not matching the policy",
+			namespace: "team-b",
+			url:       "https://github.com/team-a/app",
+			allowed:   false,
+			result:    "validation failed: namespace team-b is not allowed to claim the repository url https://github.com/team-a/app by the namespace isolation policy",
+		},
+		{
+			name:      "allow org not in the policy",
+			namespace: "team-b",
+			url:       "https://github.com/team-b/app",
+			allowed:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := rtesting.SetupFakeContext(t)
+
+			tdata := testclient.Data{
+				ConfigMap: []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: "pipelines-as-code", Namespace: system.Namespace()},
+					Data:       map[string]string{"namespace-isolation-policy": "github.com/team-a:team-a-*"},
+				}},
+			}
+			stdata, _ := testclient.SeedTestData(t, ctx, tdata)
+
+			r := reconciler{
+				pacLister: stdata.RepositoryLister,
+				client:    stdata.Kube,
+			}
+
+			repo := testnewrepo.NewRepo(testnewrepo.RepoTestcreationOpts{
+				Name:             "test-run",
+				InstallNamespace: tt.namespace,
+				URL:              tt.url,
+			})
+			userRepo, err := json.Marshal(repo)
+			assert.NilError(t, err)
+			req := &v1.AdmissionRequest{Object: runtime.RawExtension{Raw: userRepo}}
+			res := r.Admit(ctx, req)
+
+			assert.Equal(t, res.Allowed, tt.allowed)
+			if !res.Allowed {
+				assert.Equal(t, res.Result.Message, tt.result)
+			}
+		})
+	}
+}
+
+func TestMountedCABundles(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "ca.crt")
+	assert.NilError(t, os.WriteFile(bundle, []byte("bundle"), 0o600))
+
+	value := fmt.Sprintf("ghe.example.com=%s, gitlab.example.com=/etc/pac/ca/gitlab/ca.crt", bundle)
+	assert.Equal(t, mountedCABundles(value), "ghe.example.com="+bundle)
+	assert.Equal(t, mountedCABundles("invalid"), "")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/simulator"
+)
+
+func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	// the URL of the simulator as seen from the controller
+	url := os.Getenv("SIMULATOR_URL")
+	if url == "" {
+		url = "http://provider-simulator.pac-simulator.svc.cluster.local:" + port
+	}
+	controllerURL := os.Getenv("SIMULATOR_CONTROLLER_URL")
+	if controllerURL == "" {
+		controllerURL = "http://pipelines-as-code-controller.pipelines-as-code.svc.cluster.local:8080"
+	}
+
+	server := simulator.NewServer(url, controllerURL)
+	if seedFile := os.Getenv("SIMULATOR_SEED"); seedFile != "" {
+		data, err := os.ReadFile(seedFile)
+		if err != nil {
+			log.Fatalf("cannot read the seed: %v", err)
+		}
+		seed, err := simulator.ParseSeed(data)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := server.Load(seed); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("provider simulator listening on :%s as %s, sending the webhooks to %s", port, url, controllerURL)
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Fatal(srv.ListenAndServe())
+}
@@ -0,0 +1,179 @@
+//go:build e2e
+// +build e2e
+
+package test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/keys"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/kubeinteraction"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/hermetic"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/options"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/repository"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/simulator"
+	twait "github.com/openshift-pipelines/pipelines-as-code/test/pkg/wait"
+	"github.com/tektoncd/pipeline/pkg/names"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const hermeticSeed = "testdata/hermetic/seed.yaml"
+
+func setupHermetic(ctx context.Context, t *testing.T) (*params.Run, *simulator.Client, *github.Client) {
+	if os.Getenv(hermetic.SimulatorURLEnv) == "" {
+		t.Skipf("Skipping test since %s is not set, the provider simulator is not deployed", hermetic.SimulatorURLEnv)
+	}
+	runcnx, sim, err := hermetic.Setup(ctx)
+	assert.NilError(t, err)
+	ghcnx, err := sim.GitHub()
+	assert.NilError(t, err)
+	return runcnx, sim, ghcnx
+}
+
+func tearDownHermetic(ctx context.Context, t *testing.T, runcnx *params.Run, targetNS string) {
+	if os.Getenv("TEST_NOCLEANUP") == "true" {
+		runcnx.Clients.Log.Infof("Not cleaning up since TEST_NOCLEANUP is set")
+		return
+	}
+	repository.NSTearDown(ctx, t, runcnx, targetNS)
+}
+
+// untilStatuses waits until the simulator has recorded the statuses on the
+// SHA, it returns the latest state of each context.
+func untilStatuses(ctx context.Context, t *testing.T, ghcnx *github.Client, seeded simulator.Repository, sha string, done func(map[string]*github.RepoStatus) bool) map[string]*github.RepoStatus {
+	var latest map[string]*github.RepoStatus
+	err := kubeinteraction.PollImmediateWithContext(ctx, twait.DefaultTimeout, func() (bool, error) {
+		statuses, _, err := ghcnx.Repositories.ListStatuses(ctx, seeded.Owner, seeded.Name, sha, nil)
+		if err != nil {
+			return true, err
+		}
+		// the statuses are listed the most recent first
+		latest = map[string]*github.RepoStatus{}
+		for i := len(statuses) - 1; i >= 0; i-- {
+			latest[statuses[i].GetContext()] = statuses[i]
+		}
+		return done(latest), nil
+	})
+	assert.NilError(t, err)
+	return latest
+}
+
+func countState(statuses map[string]*github.RepoStatus, state string) int {
+	count := 0
+	for _, status := range statuses {
+		if status.GetState() == state {
+			count++
+		}
+	}
+	return count
+}
+
+func TestHermeticPullRequest(t *testing.T) {
+	ctx := context.Background()
+	runcnx, sim, ghcnx := setupHermetic(ctx, t)
+	targetNS := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix("pac-e2e-hermetic")
+	opts := options.E2E{Concurrency: 1}
+	seeded := hermetic.CreateCRD(ctx, t, runcnx, sim, opts, hermeticSeed, targetNS)
+	defer tearDownHermetic(ctx, t, runcnx, targetNS)
+	opts.Organization, opts.Repo = seeded.Owner, seeded.Name
+
+	pr, _, err := ghcnx.PullRequests.Get(ctx, seeded.Owner, seeded.Name, 1)
+	assert.NilError(t, err)
+	sha := pr.GetHead().GetSHA()
+
+	err = sim.SendWebhook(ctx, &simulator.Webhook{Repository: seeded.FullName(), Event: "pull_request", PullRequest: 1})
+	assert.NilError(t, err)
+
+	// both PipelineRuns match, one of them waits in the queue of the
+	// concurrency limit
+	err = twait.UntilMinPRAppeared(ctx, runcnx.Clients, twait.Opts{Namespace: targetNS, PollTimeout: twait.DefaultTimeout}, 2)
+	assert.NilError(t, err)
+	prs, err := runcnx.Clients.Tekton.TektonV1().PipelineRuns(targetNS).List(ctx, metav1.ListOptions{})
+	assert.NilError(t, err)
+	queued := 0
+	for _, pr := range prs.Items {
+		if pr.GetLabels()[keys.State] == kubeinteraction.StateQueued {
+			queued++
+		}
+	}
+	assert.Assert(t, queued >= 1, "no PipelineRun has been queued with a concurrency limit of 1")
+
+	twait.Succeeded(ctx, t, runcnx, opts, twait.SuccessOpt{
+		TargetNS:        targetNS,
+		OnEvent:         "pull_request",
+		SHA:             sha,
+		Title:           "Add the pull request PipelineRuns",
+		MinNumberStatus: 2,
+	})
+
+	statuses := untilStatuses(ctx, t, ghcnx, seeded, sha, func(latest map[string]*github.RepoStatus) bool {
+		return countState(latest, "success") == 2
+	})
+	assert.Equal(t, len(statuses), 2)
+	runcnx.Clients.Log.Infof("The statuses of the PipelineRuns have been reported as succeeded on %s", sha)
+}
+
+func TestHermeticPush(t *testing.T) {
+	ctx := context.Background()
+	runcnx, sim, ghcnx := setupHermetic(ctx, t)
+	targetNS := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix("pac-e2e-hermetic-push")
+	opts := options.E2E{}
+	seeded := hermetic.CreateCRD(ctx, t, runcnx, sim, opts, hermeticSeed, targetNS)
+	defer tearDownHermetic(ctx, t, runcnx, targetNS)
+	opts.Organization, opts.Repo = seeded.Owner, seeded.Name
+
+	branch, _, err := ghcnx.Repositories.GetBranch(ctx, seeded.Owner, seeded.Name, seeded.DefaultBranch, 1)
+	assert.NilError(t, err)
+	sha := branch.GetCommit().GetSHA()
+
+	err = sim.SendWebhook(ctx, &simulator.Webhook{Repository: seeded.FullName(), Event: "push", Branch: seeded.DefaultBranch})
+	assert.NilError(t, err)
+
+	twait.Succeeded(ctx, t, runcnx, opts, twait.SuccessOpt{
+		TargetNS:        targetNS,
+		OnEvent:         "push",
+		SHA:             sha,
+		Title:           "Add the push PipelineRun",
+		MinNumberStatus: 1,
+	})
+	untilStatuses(ctx, t, ghcnx, seeded, sha, func(latest map[string]*github.RepoStatus) bool {
+		return len(latest) == 1 && countState(latest, "success") == 1
+	})
+}
+
+func TestHermeticPullRequestNotAllowed(t *testing.T) {
+	ctx := context.Background()
+	runcnx, sim, ghcnx := setupHermetic(ctx, t)
+	targetNS := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix("pac-e2e-hermetic-acl")
+	seeded := hermetic.CreateCRD(ctx, t, runcnx, sim, options.E2E{}, hermeticSeed, targetNS)
+	defer tearDownHermetic(ctx, t, runcnx, targetNS)
+
+	pr, _, err := ghcnx.PullRequests.Get(ctx, seeded.Owner, seeded.Name, 2)
+	assert.NilError(t, err)
+
+	err = sim.SendWebhook(ctx, &simulator.Webhook{Repository: seeded.FullName(), Event: "pull_request", PullRequest: 2})
+	assert.NilError(t, err)
+
+	untilStatuses(ctx, t, ghcnx, seeded, pr.GetHead().GetSHA(), func(latest map[string]*github.RepoStatus) bool {
+		for _, status := range latest {
+			if status.GetState() == "pending" && status.GetDescription() == "Pending approval" {
+				return true
+			}
+		}
+		return false
+	})
+
+	comments, _, err := ghcnx.Issues.ListComments(ctx, seeded.Owner, seeded.Name, 2, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, len(comments) == 1 && strings.Contains(comments[0].GetBody(), "external-contributor is not allowed"))
+
+	prs, err := runcnx.Clients.Tekton.TektonV1().PipelineRuns(targetNS).List(ctx, metav1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(prs.Items), 0, "a PipelineRun has been created for a user who is not allowed")
+}
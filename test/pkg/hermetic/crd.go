@@ -0,0 +1,71 @@
+package hermetic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/apis/pipelinesascode/v1alpha1"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/random"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/options"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/payload"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/repository"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/secret"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/simulator"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateCRD seeds the simulator with the seed file templated for the target
+// namespace, then creates the namespace with a Repository CR on the first
+// repository of the seed. The seed file gets the TargetNamespace and the
+// random WebhookSecret as template parameters.
+func CreateCRD(ctx context.Context, t *testing.T, run *params.Run, sim *simulator.Client, opts options.E2E, seedFile, targetNS string) simulator.Repository {
+	webhookSecret := strings.ToLower(random.AlphaString(16))
+	content, err := payload.ApplyTemplate(seedFile, map[string]string{
+		"TargetNamespace": targetNS,
+		"WebhookSecret":   webhookSecret,
+	})
+	assert.NilError(t, err)
+	seed, err := simulator.ParseSeed([]byte(content))
+	assert.NilError(t, err)
+	assert.Assert(t, len(seed.Repositories) > 0, "no repository in the seed %s", seedFile)
+	simulatorURL, err := sim.Seed(ctx, seed)
+	assert.NilError(t, err)
+	seeded := seed.Repositories[0]
+
+	err = repository.CreateNS(ctx, targetNS, run)
+	assert.NilError(t, err)
+	err = secret.Create(ctx, run, map[string]string{
+		"webhook-secret": webhookSecret,
+		"token":          strings.ToLower(random.AlphaString(16)),
+	}, targetNS, "webhook-token")
+	assert.NilError(t, err)
+
+	repo := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: targetNS,
+		},
+		Spec: v1alpha1.RepositorySpec{
+			URL: simulatorURL + "/" + seeded.FullName(),
+			GitProvider: &v1alpha1.GitProvider{
+				URL: simulatorURL,
+				Secret: &v1alpha1.Secret{
+					Name: "webhook-token",
+					Key:  "token",
+				},
+				WebhookSecret: &v1alpha1.Secret{
+					Name: "webhook-token",
+					Key:  "webhook-secret",
+				},
+			},
+		},
+	}
+	if opts.Concurrency != 0 {
+		repo.Spec.ConcurrencyLimit = &opts.Concurrency
+	}
+	err = repository.CreateRepo(ctx, targetNS, run, repo)
+	assert.NilError(t, err)
+	return seeded
+}
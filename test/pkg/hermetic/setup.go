@@ -0,0 +1,31 @@
+package hermetic
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params"
+	"github.com/openshift-pipelines/pipelines-as-code/pkg/params/info"
+	"github.com/openshift-pipelines/pipelines-as-code/test/pkg/simulator"
+)
+
+// SimulatorURLEnv is the env variable of the URL of the provider simulator,
+// as seen from the e2e tests. The hermetic tests are skipped without it.
+const SimulatorURLEnv = "TEST_PROVIDER_SIMULATOR_URL"
+
+// Setup returns the clients of the cluster and a client of the provider
+// simulator deployed in it.
+func Setup(ctx context.Context) (*params.Run, *simulator.Client, error) {
+	simulatorURL := os.Getenv(SimulatorURLEnv)
+	if simulatorURL == "" {
+		return nil, nil, fmt.Errorf("\"%s\" env variable is required, cannot continue", SimulatorURLEnv)
+	}
+
+	run := params.New()
+	if err := run.Clients.NewClients(ctx, &run.Info); err != nil {
+		return nil, nil, err
+	}
+	run.Info.Controller = info.GetControllerInfoFromEnvOrDefault()
+	return run, simulator.NewClient(simulatorURL), nil
+}
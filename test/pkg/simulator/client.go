@@ -0,0 +1,81 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// Client drives a simulator from the e2e tests.
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client of the simulator on the URL, as seen from the
+// e2e tests.
+func NewClient(url string) *Client {
+	return &Client{URL: strings.TrimSuffix(url, "/"), HTTPClient: &http.Client{Timeout: time.Minute}}
+}
+
+// Seed seeds the simulator, it returns the URL of the simulator as seen from
+// the controller: the API URL of the Repository CRs and the base of their URL.
+func (c *Client) Seed(ctx context.Context, seed *Seed) (string, error) {
+	body, err := json.Marshal(seed)
+	if err != nil {
+		return "", err
+	}
+	result := struct {
+		URL string `json:"url"`
+	}{}
+	if err := c.do(ctx, http.MethodPut, "seed", body, &result); err != nil {
+		return "", err
+	}
+	return result.URL, nil
+}
+
+// SendWebhook makes the simulator send a webhook to the controller.
+func (c *Client) SendWebhook(ctx context.Context, hook *Webhook) error {
+	body, err := json.Marshal(hook)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "webhooks", body, nil)
+}
+
+// GitHub returns a GitHub client on the simulator, to read what the
+// controller has recorded like the statuses or the comments.
+func (c *Client) GitHub() (*github.Client, error) {
+	return github.NewClient(c.HTTPClient).WithEnterpriseURLs(c.URL, c.URL)
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string, body []byte, result any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+controlPrefix+"/"+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("simulator replied %d on %s: %s", resp.StatusCode, endpoint, strings.TrimSpace(string(data)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
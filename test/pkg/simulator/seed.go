@@ -0,0 +1,292 @@
+package simulator
+
+import (
+	//nolint:gosec
+	"crypto/sha1"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Seed is the declarative test data served by the simulator: the
+// repositories with their branches, pull requests and users.
+type Seed struct {
+	Repositories []Repository `json:"repositories"`
+}
+
+// Repository is a simulated repository.
+type Repository struct {
+	Owner         string `json:"owner"`
+	Name          string `json:"name"`
+	DefaultBranch string `json:"defaultBranch,omitempty"`
+	// WebhookSecret signs the webhooks sent for the repository, it needs to
+	// be the webhook secret of the Repository CR.
+	WebhookSecret string `json:"webhookSecret,omitempty"`
+	// WebhookURL is where the webhooks of the repository are sent, the
+	// controller URL of the simulator when empty.
+	WebhookURL string `json:"webhookURL,omitempty"`
+	// Members are the members of the organization owning the repository.
+	Members []string `json:"members,omitempty"`
+	// Collaborators are the collaborators of the repository and their
+	// permission: admin, maintain, write or read.
+	Collaborators map[string]string `json:"collaborators,omitempty"`
+	Branches      []Branch          `json:"branches,omitempty"`
+	PullRequests  []PullRequest     `json:"pullRequests,omitempty"`
+}
+
+// Branch is a branch of a simulated repository with a single commit on top
+// of the branch it has been created from.
+type Branch struct {
+	Name string `json:"name"`
+	// From is the branch this branch has been created from, its files are
+	// inherited and its commit is the parent of the commit of this branch.
+	From    string `json:"from,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Files are the files added or modified by the commit of the branch, by
+	// their path.
+	Files map[string]string `json:"files,omitempty"`
+	// Deleted are the paths of the files deleted by the commit of the branch.
+	Deleted []string `json:"deleted,omitempty"`
+}
+
+// PullRequest is a pull request of a simulated repository, from the Head
+// branch to the Base branch.
+type PullRequest struct {
+	Number int      `json:"number"`
+	Title  string   `json:"title,omitempty"`
+	Author string   `json:"author"`
+	Head   string   `json:"head"`
+	Base   string   `json:"base,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// ParseSeed parses a YAML or JSON seed.
+func ParseSeed(data []byte) (*Seed, error) {
+	seed := &Seed{}
+	if err := yaml.UnmarshalStrict(data, seed); err != nil {
+		return nil, fmt.Errorf("cannot parse the seed: %w", err)
+	}
+	for i := range seed.Repositories {
+		if seed.Repositories[i].Owner == "" || seed.Repositories[i].Name == "" {
+			return nil, fmt.Errorf("repository %d of the seed has no owner or name", i)
+		}
+	}
+	return seed, nil
+}
+
+// FullName returns the owner/name of the repository.
+func (r *Repository) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// treeEntry is an entry of a simulated git tree.
+type treeEntry struct {
+	name, sha string
+	tree      bool
+}
+
+// object is a simulated git object, a tree or a blob.
+type object struct {
+	tree    bool
+	entries []treeEntry
+	content string
+}
+
+// commit is the simulated commit of a branch.
+type commit struct {
+	sha, tree, parent, message string
+	files                      map[string]string
+	added, modified, removed   []string
+}
+
+// repository is the state of a seeded repository.
+type repository struct {
+	Repository
+	id       int64
+	objects  map[string]*object
+	commits  map[string]*commit
+	branches map[string]string
+	pulls    map[int]*PullRequest
+}
+
+func gitSHA(kind, content string) string {
+	//nolint:gosec
+	return fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("%s %d\x00%s", kind, len(content), content))))
+}
+
+// newRepository builds the commits and the git objects of a seeded
+// repository, the branches are created from their parent branch first.
+func newRepository(seed Repository) (*repository, error) {
+	if seed.DefaultBranch == "" {
+		seed.DefaultBranch = "main"
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(seed.FullName()))
+	repo := &repository{
+		Repository: seed,
+		id:         int64(hash.Sum32()),
+		objects:    map[string]*object{},
+		commits:    map[string]*commit{},
+		branches:   map[string]string{},
+		pulls:      map[int]*PullRequest{},
+	}
+
+	byName := map[string]Branch{}
+	for _, branch := range seed.Branches {
+		byName[branch.Name] = branch
+	}
+	var create func(name string, seen map[string]bool) error
+	create = func(name string, seen map[string]bool) error {
+		if _, ok := repo.branches[name]; ok {
+			return nil
+		}
+		branch, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("branch %s of repository %s is not in the seed", name, seed.FullName())
+		}
+		if seen[name] {
+			return fmt.Errorf("branch %s of repository %s is created from itself", name, seed.FullName())
+		}
+		seen[name] = true
+		var parent *commit
+		if branch.From != "" {
+			if err := create(branch.From, seen); err != nil {
+				return err
+			}
+			parent = repo.commits[repo.branches[branch.From]]
+		}
+		repo.branches[name] = repo.addCommit(branch, parent).sha
+		return nil
+	}
+	for _, branch := range seed.Branches {
+		if err := create(branch.Name, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := repo.branches[seed.DefaultBranch]; !ok {
+		repo.branches[seed.DefaultBranch] = repo.addCommit(Branch{Name: seed.DefaultBranch, Message: "Initial commit"}, nil).sha
+	}
+
+	for i := range seed.PullRequests {
+		pr := seed.PullRequests[i]
+		if pr.Base == "" {
+			pr.Base = seed.DefaultBranch
+		}
+		if pr.Title == "" {
+			pr.Title = fmt.Sprintf("Merge %s into %s", pr.Head, pr.Base)
+		}
+		for _, branch := range []string{pr.Head, pr.Base} {
+			if _, ok := repo.branches[branch]; !ok {
+				return nil, fmt.Errorf("branch %s of pull request %d is not in the seed", branch, pr.Number)
+			}
+		}
+		repo.pulls[pr.Number] = &pr
+	}
+	return repo, nil
+}
+
+// addCommit adds the commit of a branch on top of its parent with its tree.
+func (r *repository) addCommit(branch Branch, parent *commit) *commit {
+	files := map[string]string{}
+	c := &commit{message: branch.Message, files: files}
+	if c.message == "" {
+		c.message = "Update " + branch.Name
+	}
+	if parent != nil {
+		c.parent = parent.sha
+		for fpath, content := range parent.files {
+			files[fpath] = content
+		}
+	}
+	for fpath, content := range branch.Files {
+		fpath = strings.Trim(fpath, "/")
+		if _, ok := files[fpath]; ok {
+			c.modified = append(c.modified, fpath)
+		} else {
+			c.added = append(c.added, fpath)
+		}
+		files[fpath] = content
+	}
+	for _, fpath := range branch.Deleted {
+		fpath = strings.Trim(fpath, "/")
+		if _, ok := files[fpath]; ok {
+			c.removed = append(c.removed, fpath)
+			delete(files, fpath)
+		}
+	}
+	sort.Strings(c.added)
+	sort.Strings(c.modified)
+	sort.Strings(c.removed)
+
+	c.tree = r.addTree(files, "")
+	c.sha = gitSHA("commit", fmt.Sprintf("tree %s\nparent %s\nbranch %s\n\n%s", c.tree, c.parent, branch.Name, c.message))
+	r.commits[c.sha] = c
+	return c
+}
+
+// addTree adds the tree of a directory and its subtrees, it returns its SHA.
+func (r *repository) addTree(files map[string]string, dir string) string {
+	blobs := map[string]string{}
+	subdirs := map[string]bool{}
+	for fpath, content := range files {
+		if dir != "" {
+			if !strings.HasPrefix(fpath, dir+"/") {
+				continue
+			}
+			fpath = strings.TrimPrefix(fpath, dir+"/")
+		}
+		if first, _, nested := strings.Cut(fpath, "/"); nested {
+			subdirs[first] = true
+		} else {
+			blobs[fpath] = content
+		}
+	}
+
+	tree := &object{tree: true}
+	for name, content := range blobs {
+		sha := gitSHA("blob", content)
+		r.objects[sha] = &object{content: content}
+		tree.entries = append(tree.entries, treeEntry{name: name, sha: sha})
+	}
+	for name := range subdirs {
+		tree.entries = append(tree.entries, treeEntry{name: name, sha: r.addTree(files, path.Join(dir, name)), tree: true})
+	}
+	sort.Slice(tree.entries, func(i, j int) bool { return tree.entries[i].name < tree.entries[j].name })
+
+	listing := ""
+	for _, entry := range tree.entries {
+		listing += fmt.Sprintf("%s %t %s\n", entry.name, entry.tree, entry.sha)
+	}
+	sha := gitSHA("tree", listing)
+	r.objects[sha] = tree
+	return sha
+}
+
+// resolveCommit returns the commit of a SHA or a branch, the default branch
+// when the ref is empty.
+func (r *repository) resolveCommit(ref string) *commit {
+	ref = strings.TrimPrefix(ref, "refs/heads/")
+	if ref == "" {
+		ref = r.DefaultBranch
+	}
+	if sha, ok := r.branches[ref]; ok {
+		ref = sha
+	}
+	return r.commits[ref]
+}
+
+// resolveTree returns the SHA of a tree, from its SHA or the SHA or branch of
+// a commit.
+func (r *repository) resolveTree(ref string) (string, *object) {
+	if obj, ok := r.objects[ref]; ok && obj.tree {
+		return ref, obj
+	}
+	if c := r.resolveCommit(ref); c != nil {
+		return c.tree, r.objects[c.tree]
+	}
+	return "", nil
+}
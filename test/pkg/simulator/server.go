@@ -0,0 +1,764 @@
+package simulator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+const (
+	// apiPrefix is the prefix of the API of a GitHub Enterprise server, the
+	// one the GitHub provider uses on a custom API URL.
+	apiPrefix = "/api/v3"
+	// controlPrefix is the prefix of the endpoints seeding the simulator and
+	// sending the webhooks.
+	controlPrefix = "/_simulator"
+	// botLogin is the login of the user of the token of the controller.
+	botLogin = "pipelines-as-code[bot]"
+)
+
+// Server simulates a GitHub server: it serves the API used by the GitHub
+// provider from the seeded repositories, records the statuses, check runs
+// and comments and sends the webhooks to the controller.
+type Server struct {
+	// URL is the URL of the simulator as seen from the controller, it is
+	// the URL of the API and the base of the URLs of the repositories.
+	URL string
+	// ControllerURL is where the webhooks are sent by default.
+	ControllerURL string
+	HTTPClient    *http.Client
+	Logger        *log.Logger
+
+	mutex     sync.Mutex
+	repos     map[string]*repository
+	statuses  map[string][]*github.RepoStatus
+	checkRuns map[string][]*github.CheckRun
+	comments  map[string][]*github.IssueComment
+	lastID    int64
+}
+
+// NewServer returns a simulator with no repository.
+func NewServer(url, controllerURL string) *Server {
+	return &Server{
+		URL:           strings.TrimSuffix(url, "/"),
+		ControllerURL: controllerURL,
+		HTTPClient:    &http.Client{Timeout: 30 * time.Second},
+		Logger:        log.Default(),
+		repos:         map[string]*repository{},
+		statuses:      map[string][]*github.RepoStatus{},
+		checkRuns:     map[string][]*github.CheckRun{},
+		comments:      map[string][]*github.IssueComment{},
+	}
+}
+
+// Load seeds the repositories, a repository already seeded is replaced and
+// what has been recorded on it is forgotten.
+func (s *Server) Load(seed *Seed) error {
+	repos := []*repository{}
+	for _, seeded := range seed.Repositories {
+		repo, err := newRepository(seeded)
+		if err != nil {
+			return err
+		}
+		repos = append(repos, repo)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, repo := range repos {
+		name := repo.FullName()
+		s.repos[name] = repo
+		for key := range s.statuses {
+			if strings.HasPrefix(key, name+"@") {
+				delete(s.statuses, key)
+			}
+		}
+		for key := range s.checkRuns {
+			if strings.HasPrefix(key, name+"@") {
+				delete(s.checkRuns, key)
+			}
+		}
+		for key := range s.comments {
+			if strings.HasPrefix(key, name+"#") {
+				delete(s.comments, key)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case req.URL.Path == "/healthz":
+		w.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(req.URL.Path, controlPrefix+"/"):
+		s.serveControl(w, req, strings.TrimPrefix(req.URL.Path, controlPrefix+"/"))
+	case strings.HasPrefix(req.URL.Path, apiPrefix+"/"):
+		s.serveAPI(w, req, strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, apiPrefix), "/"), "/"))
+	default:
+		s.notFound(w, req)
+	}
+}
+
+func (s *Server) serveControl(w http.ResponseWriter, req *http.Request, endpoint string) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	switch {
+	case endpoint == "seed" && req.Method == http.MethodPut:
+		seed, err := ParseSeed(body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.Load(seed); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"url": s.URL})
+	case endpoint == "webhooks" && req.Method == http.MethodPost:
+		hook := &Webhook{}
+		if err := json.Unmarshal(body, hook); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.SendWebhook(req.Context(), hook); err != nil {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.notFound(w, req)
+	}
+}
+
+// serveAPI serves the endpoints of the GitHub API used by the provider.
+func (s *Server) serveAPI(w http.ResponseWriter, req *http.Request, segments []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch {
+	case len(segments) == 1 && segments[0] == "rate_limit":
+		reset := time.Now().Add(time.Hour).Unix()
+		limit := map[string]int64{"limit": 5000, "remaining": 5000, "reset": reset}
+		writeJSON(w, http.StatusOK, map[string]any{"resources": map[string]any{"core": limit, "scim": limit}})
+		return
+	case len(segments) == 3 && segments[0] == "orgs" && segments[2] == "members":
+		members := []*github.User{}
+		for _, repo := range s.repos {
+			if repo.Owner != segments[1] {
+				continue
+			}
+			for _, member := range repo.Members {
+				members = append(members, &github.User{Login: github.String(member)})
+			}
+		}
+		writeJSON(w, http.StatusOK, members)
+		return
+	case len(segments) < 3 || segments[0] != "repos":
+		s.notFound(w, req)
+		return
+	}
+
+	repo, ok := s.repos[segments[1]+"/"+segments[2]]
+	if !ok {
+		s.notFound(w, req)
+		return
+	}
+	route := segments[3:]
+	resource := ""
+	if len(route) > 0 {
+		resource = route[0]
+	}
+
+	var handled bool
+	switch resource {
+	case "":
+		handled = req.Method == http.MethodGet
+		if handled {
+			writeJSON(w, http.StatusOK, s.ghRepository(repo))
+		}
+	case "branches":
+		handled = s.serveBranches(w, req, repo, route[1:])
+	case "collaborators":
+		handled = s.serveCollaborators(w, req, repo, route[1:])
+	case "commits":
+		handled = s.serveCommits(w, req, repo, route[1:])
+	case "compare":
+		handled = s.serveCompare(w, req, repo, route[1:])
+	case "contents":
+		handled = s.serveContents(w, req, repo, path.Join(route[1:]...))
+	case "git":
+		handled = s.serveGit(w, req, repo, route[1:])
+	case "pulls":
+		handled = s.servePulls(w, req, repo, route[1:])
+	case "issues":
+		handled = s.serveIssues(w, req, repo, route[1:])
+	case "statuses":
+		handled = s.serveStatuses(w, req, repo, route[1:])
+	case "check-runs":
+		handled = s.serveCheckRuns(w, req, repo, route[1:])
+	}
+	if !handled {
+		s.notFound(w, req)
+	}
+}
+
+func (s *Server) serveBranches(w http.ResponseWriter, req *http.Request, repo *repository, route []string) bool {
+	if len(route) == 0 || req.Method != http.MethodGet {
+		return false
+	}
+	name := strings.Join(route, "/")
+	sha, ok := repo.branches[name]
+	if !ok {
+		return false
+	}
+	writeJSON(w, http.StatusOK, &github.Branch{
+		Name:   github.String(name),
+		Commit: s.ghRepositoryCommit(repo, repo.commits[sha], false),
+	})
+	return true
+}
+
+func (s *Server) serveCollaborators(w http.ResponseWriter, req *http.Request, repo *repository, route []string) bool {
+	if len(route) == 0 || req.Method != http.MethodGet {
+		return false
+	}
+	permission, ok := repo.Collaborators[route[0]]
+	switch {
+	case len(route) == 1:
+		if !ok {
+			return false
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(route) == 2 && route[1] == "permission":
+		level := map[string]string{"permission": "none", "role_name": ""}
+		if ok {
+			level["role_name"] = permission
+			level["permission"] = permission
+			if permission == "maintain" {
+				level["permission"] = "write"
+			}
+		}
+		writeJSON(w, http.StatusOK, level)
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveCommits(w http.ResponseWriter, req *http.Request, repo *repository, route []string) bool {
+	if len(route) == 0 || req.Method != http.MethodGet {
+		return false
+	}
+	c := repo.resolveCommit(route[0])
+	if c == nil {
+		return false
+	}
+	switch {
+	case len(route) == 1:
+		writeJSON(w, http.StatusOK, s.ghRepositoryCommit(repo, c, true))
+	case len(route) == 2 && route[1] == "statuses":
+		statuses := s.statuses[repo.FullName()+"@"+c.sha]
+		if statuses == nil {
+			statuses = []*github.RepoStatus{}
+		}
+		writeJSON(w, http.StatusOK, statuses)
+	case len(route) == 2 && route[1] == "check-runs":
+		runs := s.checkRuns[repo.FullName()+"@"+c.sha]
+		if runs == nil {
+			runs = []*github.CheckRun{}
+		}
+		writeJSON(w, http.StatusOK, &github.ListCheckRunsResults{Total: github.Int(len(runs)), CheckRuns: runs})
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveCompare(w http.ResponseWriter, req *http.Request, repo *repository, route []string) bool {
+	if len(route) != 1 || req.Method != http.MethodGet {
+		return false
+	}
+	baseRef, headRef, ok := strings.Cut(route[0], "...")
+	if !ok {
+		return false
+	}
+	base, head := repo.resolveCommit(baseRef), repo.resolveCommit(headRef)
+	if base == nil || head == nil {
+		return false
+	}
+	comparison := &github.CommitsComparison{
+		BaseCommit: s.ghRepositoryCommit(repo, base, false),
+		Status:     github.String("ahead"),
+	}
+	if mergeBase := repo.mergeBase(base, head); mergeBase != nil {
+		comparison.MergeBaseCommit = s.ghRepositoryCommit(repo, mergeBase, false)
+		comparison.Files = diffFiles(mergeBase.files, head.files)
+	}
+	writeJSON(w, http.StatusOK, comparison)
+	return true
+}
+
+func (s *Server) serveContents(w http.ResponseWriter, req *http.Request, repo *repository, fpath string) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	c := repo.resolveCommit(req.URL.Query().Get("ref"))
+	if c == nil {
+		return false
+	}
+	if content, ok := c.files[fpath]; ok {
+		writeJSON(w, http.StatusOK, &github.RepositoryContent{
+			Type:     github.String("file"),
+			Name:     github.String(path.Base(fpath)),
+			Path:     github.String(fpath),
+			SHA:      github.String(gitSHA("blob", content)),
+			Size:     github.Int(len(content)),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+		})
+		return true
+	}
+
+	// a directory is listed
+	entries := []*github.RepositoryContent{}
+	sha := c.tree
+	for _, segment := range strings.Split(fpath, "/") {
+		if segment == "" {
+			continue
+		}
+		found := ""
+		for _, entry := range repo.objects[sha].entries {
+			if entry.name == segment && entry.tree {
+				found = entry.sha
+			}
+		}
+		if found == "" {
+			return false
+		}
+		sha = found
+	}
+	for _, entry := range repo.objects[sha].entries {
+		kind := "file"
+		if entry.tree {
+			kind = "dir"
+		}
+		entries = append(entries, &github.RepositoryContent{
+			Type: github.String(kind),
+			Name: github.String(entry.name),
+			Path: github.String(path.Join(fpath, entry.name)),
+			SHA:  github.String(entry.sha),
+		})
+	}
+	writeJSON(w, http.StatusOK, entries)
+	return true
+}
+
+func (s *Server) serveGit(w http.ResponseWriter, req *http.Request, repo *repository, route []string) bool {
+	if len(route) != 2 || req.Method != http.MethodGet {
+		return false
+	}
+	switch route[0] {
+	case "trees":
+		sha, tree := repo.resolveTree(route[1])
+		if tree == nil {
+			return false
+		}
+		entries := repo.treeEntries(tree, "", req.URL.Query().Get("recursive") != "")
+		writeJSON(w, http.StatusOK, &github.Tree{SHA: github.String(sha), Entries: entries, Truncated: github.Bool(false)})
+	case "blobs":
+		blob, ok := repo.objects[route[1]]
+		if !ok || blob.tree {
+			return false
+		}
+		writeJSON(w, http.StatusOK, &github.Blob{
+			SHA:      github.String(route[1]),
+			Size:     github.Int(len(blob.content)),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte(blob.content))),
+		})
+	case "commits":
+		c := repo.resolveCommit(route[1])
+		if c == nil {
+			return false
+		}
+		writeJSON(w, http.StatusOK, s.ghCommit(repo, c))
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) servePulls(w http.ResponseWriter, req *http.Request, repo *repository, route []string) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	if len(route) == 0 {
+		pulls := []*github.PullRequest{}
+		for _, number := range repo.pullNumbers() {
+			pulls = append(pulls, s.ghPullRequest(repo, repo.pulls[number]))
+		}
+		writeJSON(w, http.StatusOK, pulls)
+		return true
+	}
+	number, err := strconv.Atoi(route[0])
+	if err != nil {
+		return false
+	}
+	pr, ok := repo.pulls[number]
+	if !ok {
+		return false
+	}
+	switch {
+	case len(route) == 1:
+		writeJSON(w, http.StatusOK, s.ghPullRequest(repo, pr))
+	case len(route) == 2 && route[1] == "files":
+		base, head := repo.resolveCommit(pr.Base), repo.resolveCommit(pr.Head)
+		files := []*github.CommitFile{}
+		if mergeBase := repo.mergeBase(base, head); mergeBase != nil {
+			files = diffFiles(mergeBase.files, head.files)
+		}
+		writeJSON(w, http.StatusOK, files)
+	case len(route) == 2 && route[1] == "reviews":
+		writeJSON(w, http.StatusOK, []*github.PullRequestReview{})
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveIssues(w http.ResponseWriter, req *http.Request, repo *repository, route []string) bool {
+	// the reactions to the comments are accepted and ignored
+	if len(route) > 0 && route[len(route)-1] == "reactions" && req.Method == http.MethodPost {
+		writeJSON(w, http.StatusCreated, &github.Reaction{})
+		return true
+	}
+	if len(route) == 3 && route[0] == "comments" && req.Method == http.MethodPatch {
+		id, err := strconv.ParseInt(route[1], 10, 64)
+		if err != nil {
+			return false
+		}
+		for key, comments := range s.comments {
+			if !strings.HasPrefix(key, repo.FullName()+"#") {
+				continue
+			}
+			for _, comment := range comments {
+				if comment.GetID() == id {
+					update := &github.IssueComment{}
+					if err := json.NewDecoder(req.Body).Decode(update); err != nil {
+						writeError(w, http.StatusBadRequest, err)
+						return true
+					}
+					comment.Body = update.Body
+					comment.UpdatedAt = &github.Timestamp{Time: time.Now()}
+					writeJSON(w, http.StatusOK, comment)
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if len(route) != 2 || route[1] != "comments" {
+		return false
+	}
+	key := repo.FullName() + "#" + route[0]
+	switch req.Method {
+	case http.MethodGet:
+		comments := s.comments[key]
+		if comments == nil {
+			comments = []*github.IssueComment{}
+		}
+		writeJSON(w, http.StatusOK, comments)
+	case http.MethodPost:
+		comment := &github.IssueComment{}
+		if err := json.NewDecoder(req.Body).Decode(comment); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return true
+		}
+		s.lastID++
+		comment.ID = github.Int64(s.lastID)
+		comment.User = &github.User{Login: github.String(botLogin), Type: github.String("Bot")}
+		comment.HTMLURL = github.String(fmt.Sprintf("%s/pull/%s#issuecomment-%d", s.htmlURL(repo), route[0], s.lastID))
+		comment.CreatedAt = &github.Timestamp{Time: time.Now()}
+		s.comments[key] = append(s.comments[key], comment)
+		writeJSON(w, http.StatusCreated, comment)
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveStatuses(w http.ResponseWriter, req *http.Request, repo *repository, route []string) bool {
+	if len(route) != 1 || req.Method != http.MethodPost {
+		return false
+	}
+	status := &github.RepoStatus{}
+	if err := json.NewDecoder(req.Body).Decode(status); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return true
+	}
+	s.lastID++
+	status.ID = github.Int64(s.lastID)
+	status.CreatedAt = &github.Timestamp{Time: time.Now()}
+	status.Creator = &github.User{Login: github.String(botLogin)}
+	// the most recent first, like GitHub lists them
+	key := repo.FullName() + "@" + route[0]
+	s.statuses[key] = append([]*github.RepoStatus{status}, s.statuses[key]...)
+	writeJSON(w, http.StatusCreated, status)
+	return true
+}
+
+func (s *Server) serveCheckRuns(w http.ResponseWriter, req *http.Request, repo *repository, route []string) bool {
+	switch {
+	case len(route) == 0 && req.Method == http.MethodPost:
+		opts := &github.CreateCheckRunOptions{}
+		if err := json.NewDecoder(req.Body).Decode(opts); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return true
+		}
+		s.lastID++
+		run := &github.CheckRun{
+			ID:          github.Int64(s.lastID),
+			Name:        github.String(opts.Name),
+			HeadSHA:     github.String(opts.HeadSHA),
+			ExternalID:  opts.ExternalID,
+			DetailsURL:  opts.DetailsURL,
+			Status:      opts.Status,
+			Conclusion:  opts.Conclusion,
+			StartedAt:   opts.StartedAt,
+			CompletedAt: opts.CompletedAt,
+			Output:      opts.Output,
+		}
+		key := repo.FullName() + "@" + opts.HeadSHA
+		s.checkRuns[key] = append(s.checkRuns[key], run)
+		writeJSON(w, http.StatusCreated, run)
+	case len(route) == 1 && req.Method == http.MethodPatch:
+		id, err := strconv.ParseInt(route[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		opts := &github.UpdateCheckRunOptions{}
+		if err := json.NewDecoder(req.Body).Decode(opts); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return true
+		}
+		for key, runs := range s.checkRuns {
+			if !strings.HasPrefix(key, repo.FullName()+"@") {
+				continue
+			}
+			for _, run := range runs {
+				if run.GetID() != id {
+					continue
+				}
+				if opts.Name != "" {
+					run.Name = github.String(opts.Name)
+				}
+				for _, field := range []struct{ from, to **string }{
+					{&opts.ExternalID, &run.ExternalID},
+					{&opts.DetailsURL, &run.DetailsURL},
+					{&opts.Status, &run.Status},
+					{&opts.Conclusion, &run.Conclusion},
+				} {
+					if *field.from != nil {
+						*field.to = *field.from
+					}
+				}
+				if opts.CompletedAt != nil {
+					run.CompletedAt = opts.CompletedAt
+				}
+				if opts.Output != nil {
+					run.Output = opts.Output
+				}
+				writeJSON(w, http.StatusOK, run)
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+	return true
+}
+
+// mergeBase returns the first ancestor of head which is an ancestor of base.
+func (r *repository) mergeBase(base, head *commit) *commit {
+	if base == nil || head == nil {
+		return nil
+	}
+	ancestors := map[string]bool{}
+	for c := base; c != nil; c = r.commits[c.parent] {
+		ancestors[c.sha] = true
+	}
+	for c := head; c != nil; c = r.commits[c.parent] {
+		if ancestors[c.sha] {
+			return c
+		}
+	}
+	return nil
+}
+
+// treeEntries returns the entries of a tree, with the entries of its subtrees
+// when recursive.
+func (r *repository) treeEntries(tree *object, prefix string, recursive bool) []*github.TreeEntry {
+	entries := []*github.TreeEntry{}
+	for _, entry := range tree.entries {
+		fpath := path.Join(prefix, entry.name)
+		ghEntry := &github.TreeEntry{Path: github.String(fpath), SHA: github.String(entry.sha)}
+		if entry.tree {
+			ghEntry.Type, ghEntry.Mode = github.String("tree"), github.String("040000")
+		} else {
+			ghEntry.Type, ghEntry.Mode = github.String("blob"), github.String("100644")
+			ghEntry.Size = github.Int(len(r.objects[entry.sha].content))
+		}
+		entries = append(entries, ghEntry)
+		if entry.tree && recursive {
+			entries = append(entries, r.treeEntries(r.objects[entry.sha], fpath, true)...)
+		}
+	}
+	return entries
+}
+
+func (r *repository) pullNumbers() []int {
+	numbers := []int{}
+	for number := range r.pulls {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// diffFiles returns the files changed between two commits.
+func diffFiles(from, to map[string]string) []*github.CommitFile {
+	files := []*github.CommitFile{}
+	for fpath, content := range to {
+		previous, ok := from[fpath]
+		switch {
+		case !ok:
+			files = append(files, &github.CommitFile{Filename: github.String(fpath), Status: github.String("added")})
+		case previous != content:
+			files = append(files, &github.CommitFile{Filename: github.String(fpath), Status: github.String("modified")})
+		}
+	}
+	for fpath := range from {
+		if _, ok := to[fpath]; !ok {
+			files = append(files, &github.CommitFile{Filename: github.String(fpath), Status: github.String("removed")})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].GetFilename() < files[j].GetFilename() })
+	return files
+}
+
+func (s *Server) htmlURL(repo *repository) string {
+	return s.URL + "/" + repo.FullName()
+}
+
+func (s *Server) ghRepository(repo *repository) *github.Repository {
+	return &github.Repository{
+		ID:            github.Int64(repo.id),
+		Name:          github.String(repo.Name),
+		FullName:      github.String(repo.FullName()),
+		Owner:         &github.User{Login: github.String(repo.Owner)},
+		HTMLURL:       github.String(s.htmlURL(repo)),
+		CloneURL:      github.String(s.htmlURL(repo) + ".git"),
+		DefaultBranch: github.String(repo.DefaultBranch),
+		Private:       github.Bool(false),
+	}
+}
+
+func (s *Server) ghCommit(repo *repository, c *commit) *github.Commit {
+	author := &github.CommitAuthor{
+		Name:  github.String("Simulator"),
+		Email: github.String("simulator@example.com"),
+		Date:  &github.Timestamp{Time: time.Now()},
+	}
+	commit := &github.Commit{
+		SHA:       github.String(c.sha),
+		Message:   github.String(c.message),
+		HTMLURL:   github.String(s.htmlURL(repo) + "/commit/" + c.sha),
+		Tree:      &github.Tree{SHA: github.String(c.tree)},
+		Author:    author,
+		Committer: author,
+	}
+	if c.parent != "" {
+		commit.Parents = []*github.Commit{{SHA: github.String(c.parent)}}
+	}
+	return commit
+}
+
+func (s *Server) ghRepositoryCommit(repo *repository, c *commit, withFiles bool) *github.RepositoryCommit {
+	rc := &github.RepositoryCommit{
+		SHA:     github.String(c.sha),
+		Commit:  s.ghCommit(repo, c),
+		HTMLURL: github.String(s.htmlURL(repo) + "/commit/" + c.sha),
+	}
+	if !withFiles {
+		return rc
+	}
+	rc.Files = []*github.CommitFile{}
+	for _, change := range []struct {
+		status string
+		files  []string
+	}{{"added", c.added}, {"modified", c.modified}, {"removed", c.removed}} {
+		for _, fpath := range change.files {
+			rc.Files = append(rc.Files, &github.CommitFile{Filename: github.String(fpath), Status: github.String(change.status)})
+		}
+	}
+	return rc
+}
+
+func (s *Server) ghPullRequestBranch(repo *repository, branch string) *github.PullRequestBranch {
+	return &github.PullRequestBranch{
+		Label: github.String(repo.Owner + ":" + branch),
+		Ref:   github.String(branch),
+		SHA:   github.String(repo.branches[branch]),
+		Repo:  s.ghRepository(repo),
+		User:  &github.User{Login: github.String(repo.Owner)},
+	}
+}
+
+func (s *Server) ghPullRequest(repo *repository, pr *PullRequest) *github.PullRequest {
+	labels := []*github.Label{}
+	for _, label := range pr.Labels {
+		labels = append(labels, &github.Label{Name: github.String(label)})
+	}
+	return &github.PullRequest{
+		ID:      github.Int64(repo.id*1000 + int64(pr.Number)),
+		Number:  github.Int(pr.Number),
+		State:   github.String("open"),
+		Title:   github.String(pr.Title),
+		HTMLURL: github.String(fmt.Sprintf("%s/pull/%d", s.htmlURL(repo), pr.Number)),
+		User:    &github.User{Login: github.String(pr.Author), Type: github.String("User")},
+		Labels:  labels,
+		Head:    s.ghPullRequestBranch(repo, pr.Head),
+		Base:    s.ghPullRequestBranch(repo, pr.Base),
+	}
+}
+
+func (s *Server) notFound(w http.ResponseWriter, req *http.Request) {
+	s.Logger.Printf("%s %s: not found", req.Method, req.URL.String())
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"message": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(value)
+}
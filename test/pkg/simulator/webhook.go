@@ -0,0 +1,145 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// Webhook is a webhook to send to the controller for a seeded repository.
+type Webhook struct {
+	// Repository is the owner/name of the repository.
+	Repository string `json:"repository"`
+	// Event is the GitHub event: pull_request or push.
+	Event string `json:"event"`
+	// Action is the action of a pull_request event, opened when empty.
+	Action string `json:"action,omitempty"`
+	// PullRequest is the number of the pull request of a pull_request event.
+	PullRequest int `json:"pullRequest,omitempty"`
+	// Branch is the branch pushed on a push event.
+	Branch string `json:"branch,omitempty"`
+	// Sender is the login of the user sending the event, the author of the
+	// pull request or the owner of the repository when empty.
+	Sender string `json:"sender,omitempty"`
+}
+
+// SendWebhook builds the payload of the webhook from the seeded repository
+// and sends it signed to the controller, like GitHub would.
+func (s *Server) SendWebhook(ctx context.Context, hook *Webhook) error {
+	payload, target, secret, err := s.webhookPayload(hook)
+	if err != nil {
+		return err
+	}
+	jeez, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(jeez)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewBuffer(jeez))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", hook.Event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	if parsed, err := url.Parse(s.URL); err == nil {
+		req.Header.Set("X-GitHub-Enterprise-Host", parsed.Host)
+	}
+
+	s.Logger.Printf("sending a %s webhook of %s to %s", hook.Event, hook.Repository, target)
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the controller replied %d to the %s webhook: %s", resp.StatusCode, hook.Event, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// webhookPayload returns the payload of a webhook with the URL it is sent to
+// and the secret signing it.
+func (s *Server) webhookPayload(hook *Webhook) (any, string, string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	repo, ok := s.repos[hook.Repository]
+	if !ok {
+		return nil, "", "", fmt.Errorf("repository %s has not been seeded", hook.Repository)
+	}
+	target := repo.WebhookURL
+	if target == "" {
+		target = s.ControllerURL
+	}
+	sender := hook.Sender
+
+	switch hook.Event {
+	case "pull_request":
+		pr, ok := repo.pulls[hook.PullRequest]
+		if !ok {
+			return nil, "", "", fmt.Errorf("pull request %d of %s has not been seeded", hook.PullRequest, hook.Repository)
+		}
+		if sender == "" {
+			sender = pr.Author
+		}
+		action := hook.Action
+		if action == "" {
+			action = "opened"
+		}
+		return &github.PullRequestEvent{
+			Action:      github.String(action),
+			Number:      github.Int(pr.Number),
+			PullRequest: s.ghPullRequest(repo, pr),
+			Repo:        s.ghRepository(repo),
+			Sender:      &github.User{Login: github.String(sender), Type: github.String("User")},
+		}, target, repo.WebhookSecret, nil
+	case "push":
+		branch := hook.Branch
+		if branch == "" {
+			branch = repo.DefaultBranch
+		}
+		c := repo.resolveCommit(branch)
+		if _, ok := repo.branches[branch]; !ok || c == nil {
+			return nil, "", "", fmt.Errorf("branch %s of %s has not been seeded", branch, hook.Repository)
+		}
+		if sender == "" {
+			sender = repo.Owner
+		}
+		return &github.PushEvent{
+			Ref:    github.String("refs/heads/" + branch),
+			Before: github.String(c.parent),
+			After:  github.String(c.sha),
+			HeadCommit: &github.HeadCommit{
+				ID:      github.String(c.sha),
+				Message: github.String(c.message),
+				URL:     github.String(s.htmlURL(repo) + "/commit/" + c.sha),
+			},
+			Repo: &github.PushEventRepository{
+				ID:            github.Int64(repo.id),
+				Name:          github.String(repo.Name),
+				FullName:      github.String(repo.FullName()),
+				Owner:         &github.User{Login: github.String(repo.Owner)},
+				HTMLURL:       github.String(s.htmlURL(repo)),
+				CloneURL:      github.String(s.htmlURL(repo) + ".git"),
+				DefaultBranch: github.String(repo.DefaultBranch),
+			},
+			Sender: &github.User{Login: github.String(sender), Type: github.String("User")},
+		}, target, repo.WebhookSecret, nil
+	default:
+		return nil, "", "", fmt.Errorf("the %s event is not supported by the simulator", hook.Event)
+	}
+}